@@ -10,7 +10,9 @@
 // container.resolve(...)     service := NewUserService(repo, notif, log)
 //
 // The mental model is IDENTICAL. This is the "golden cage" - you learn
-// one pattern that works across all three languages.
+// one pattern that works across all three languages. The shared
+// scenarios in conformance/scenarios check it for Python and Go on every
+// test run.
 
 package main
 
@@ -39,8 +41,8 @@ func NewDatabase(connectionString string) *Database {
 	return &Database{connectionString: connectionString}
 }
 
-func (db *Database) Query(sql string) []map[string]interface{} {
-	fmt.Printf("[Database] Executing: %s\n", sql)
+func (db *Database) Query(sql string, args ...interface{}) []map[string]interface{} {
+	fmt.Printf("[Database] Executing: %s %v\n", sql, args)
 	// Mock implementation
 	return []map[string]interface{}{
 		{"id": 1, "name": "Alice", "email": "alice@example.com"},
@@ -99,7 +101,7 @@ func NewUserRepository(db *Database, logger *Logger) *UserRepository {
 
 func (r *UserRepository) FindByID(userID int) *User {
 	r.logger.Info(fmt.Sprintf("Finding user %d", userID))
-	rows := r.db.Query(fmt.Sprintf("SELECT * FROM users WHERE id = %d", userID))
+	rows := r.db.Query("SELECT * FROM users WHERE id = $1", userID)
 	if len(rows) == 0 {
 		return nil
 	}
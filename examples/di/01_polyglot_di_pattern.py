@@ -13,6 +13,9 @@ Compare this file with:
 - 01_polyglot_di_pattern.go (Go version)
 - 01_polyglot_di_pattern.rs (Rust version)
 
+The shared scenarios in conformance/scenarios check the Python and Go
+behavior against each other on every test run.
+
 The structure is identical:
 1. Define services with explicit constructor dependencies
 2. Create Composition Root (main function)
@@ -50,9 +53,9 @@ class Database:
         self.connection_string = connection_string
         print(f"[Database] Connected to {connection_string}")
 
-    def query(self, sql: str) -> list[dict[str, object]]:
-        """Execute a SQL query."""
-        print(f"[Database] Executing: {sql}")
+    def query(self, sql: str, *params: object) -> list[dict[str, object]]:
+        """Execute a SQL query with bound parameters."""
+        print(f"[Database] Executing: {sql} {params}")
         # Mock implementation
         return [{"id": 1, "name": "Alice", "email": "alice@example.com"}]
 
@@ -111,7 +114,7 @@ class UserRepository:
     def find_by_id(self, user_id: int) -> User | None:
         """Find user by ID."""
         self.logger.info(f"Finding user {user_id}")
-        rows = self.db.query(f"SELECT * FROM users WHERE id = {user_id}")
+        rows = self.db.query("SELECT * FROM users WHERE id = $1", user_id)
         if not rows:
             return None
         row = rows[0]
@@ -38,8 +38,8 @@ impl Database {
         Self { connection_string }
     }
 
-    fn query(&self, sql: &str) -> Vec<HashMap<String, String>> {
-        println!("[Database] Executing: {}", sql);
+    fn query(&self, sql: &str, params: &[&dyn std::fmt::Debug]) -> Vec<HashMap<String, String>> {
+        println!("[Database] Executing: {} {:?}", sql, params);
         // Mock implementation
         let mut row = HashMap::new();
         row.insert("id".to_string(), "1".to_string());
@@ -108,7 +108,7 @@ impl<'a> UserRepository<'a> {
 
     fn find_by_id(&self, user_id: i32) -> Option<User> {
         self.logger.info(&format!("Finding user {}", user_id));
-        let rows = self.db.query(&format!("SELECT * FROM users WHERE id = {}", user_id));
+        let rows = self.db.query("SELECT * FROM users WHERE id = $1", &[&user_id]);
         if rows.is_empty() {
             return None;
         }
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package archive creates and extracts tar, tar.gz and zip archives, the
+// Go port of the Python archive module, safe to use on archives from
+// untrusted sources:
+//
+//	err := archive.Create("build/", "dist/app.tar.gz")
+//	err = archive.Extract("download.zip", "vendor/", archive.WithProgress(report))
+//
+// Extraction refuses entries and links that would land outside the
+// destination ("zip slip") and stops at the limits of Limits, so a
+// decompression bomb cannot fill the disk. The format follows from the
+// file extension.
+//
+// Archives are deterministic by default, as on the Python side: entries
+// are sorted and carry no timestamps or owners, so the same tree always
+// produces the same bytes.
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Format is an archive format.
+type Format string
+
+// Formats.
+const (
+	Tar   Format = "tar"
+	TarGz Format = "tar.gz"
+	Zip   Format = "zip"
+)
+
+// FormatOf returns the format of an archive path from its extension:
+// .tar, .tar.gz or .tgz, and .zip.
+func FormatOf(path string) (Format, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return TarGz, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return Tar, nil
+	case strings.HasSuffix(lower, ".zip"):
+		return Zip, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+}
+
+var (
+	// ErrUnknownFormat is matched by errors.Is for a path whose extension
+	// names no supported format.
+	ErrUnknownFormat = errors.New("archive: unknown format")
+	// ErrUnsafePath is matched by errors.Is for an entry or link target
+	// that is absolute or leaves the destination directory, or an entry
+	// inside a symbolic link of the archive.
+	ErrUnsafePath = errors.New("archive: unsafe path")
+	// ErrLimitExceeded is matched by errors.Is when extraction stops at
+	// one of the Limits.
+	ErrLimitExceeded = errors.New("archive: limit exceeded")
+)
+
+// Limits bounds an extraction. A zero field is unlimited.
+type Limits struct {
+	// MaxTotalSize bounds the bytes extracted from the whole archive.
+	MaxTotalSize int64
+	// MaxFileSize bounds the bytes extracted for one entry.
+	MaxFileSize int64
+	// MaxFiles bounds the number of entries.
+	MaxFiles int
+	// MaxRatio bounds the extracted size divided by the archive size.
+	MaxRatio float64
+}
+
+// DefaultLimits are the limits of the Python side: 1 GB in total, 100 MB
+// per file, 10,000 entries and a compression ratio of 100.
+var DefaultLimits = Limits{
+	MaxTotalSize: 1_000_000_000,
+	MaxFileSize:  100_000_000,
+	MaxFiles:     10_000,
+	MaxRatio:     100,
+}
+
+// Progress reports an archive operation after each entry.
+type Progress struct {
+	// Entry is the name of the entry just processed.
+	Entry string
+	// Entries is the number processed so far.
+	Entries int
+	// Bytes is the uncompressed content processed so far.
+	Bytes int64
+}
+
+// Option configures Create and Extract.
+type Option func(*options)
+
+type options struct {
+	limits        Limits
+	progress      func(Progress)
+	deterministic bool
+	permissions   bool
+	level         int
+}
+
+func newOptions(opts []Option) options {
+	o := options{limits: DefaultLimits, deterministic: true, permissions: true, level: 6}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithLimits replaces DefaultLimits for Extract.
+func WithLimits(l Limits) Option {
+	return func(o *options) { o.limits = l }
+}
+
+// WithProgress calls fn after each entry is written or extracted.
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) { o.progress = fn }
+}
+
+// WithDeterministic sets whether Create drops modification times and
+// owners. The default is true.
+func WithDeterministic(on bool) Option {
+	return func(o *options) { o.deterministic = on }
+}
+
+// WithPermissions sets whether file modes are kept. When false, Create
+// stores and Extract writes 0644 for files and 0755 for directories. The
+// default is true.
+func WithPermissions(on bool) Option {
+	return func(o *options) { o.permissions = on }
+}
+
+// WithCompressionLevel sets the gzip or deflate level of Create, from 1,
+// fastest, to 9, smallest. The default is 6.
+func WithCompressionLevel(level int) Option {
+	return func(o *options) { o.level = level }
+}
+
+func (o *options) report(p *Progress, name string, n int64) {
+	p.Entry = name
+	p.Entries++
+	p.Bytes += n
+	if o.progress != nil {
+		o.progress(*p)
+	}
+}
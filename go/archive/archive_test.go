@@ -0,0 +1,239 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func tree(t *testing.T) string {
+	t.Helper()
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "bin"), 0o755)
+	os.WriteFile(filepath.Join(src, "README"), []byte("readme"), 0o644)
+	os.WriteFile(filepath.Join(src, "bin", "run"), []byte("#!/bin/sh\n"), 0o755)
+	return src
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, ext := range []string{".tar", ".tar.gz", ".zip"} {
+		t.Run(ext, func(t *testing.T) {
+			src := tree(t)
+			dst := filepath.Join(t.TempDir(), "out"+ext)
+			var created []string
+			err := Create(src, dst, WithProgress(func(p Progress) { created = append(created, p.Entry) }))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"README", "bin/run"}; !reflect.DeepEqual(created, want) {
+				t.Errorf("created %v, want %v", created, want)
+			}
+
+			out := t.TempDir()
+			var last Progress
+			if err := Extract(dst, out, WithProgress(func(p Progress) { last = p })); err != nil {
+				t.Fatal(err)
+			}
+			if last.Entries != 2 || last.Bytes != int64(len("readme")+len("#!/bin/sh\n")) {
+				t.Errorf("final progress = %+v", last)
+			}
+			data, _ := os.ReadFile(filepath.Join(out, "README"))
+			if string(data) != "readme" {
+				t.Errorf("README = %q", data)
+			}
+			fi, err := os.Stat(filepath.Join(out, "bin", "run"))
+			if err != nil || fi.Mode().Perm() != 0o755 {
+				t.Errorf("bin/run mode = %v, %v; want 0755 preserved", fi.Mode(), err)
+			}
+		})
+	}
+}
+
+func TestCreateDeterministic(t *testing.T) {
+	src := tree(t)
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.tar.gz"), filepath.Join(dir, "b.tar.gz")
+	if err := Create(src, a); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(src, "README"), later, later)
+	if err := Create(src, b); err != nil {
+		t.Fatal(err)
+	}
+	da, _ := os.ReadFile(a)
+	db, _ := os.ReadFile(b)
+	if !bytes.Equal(da, db) {
+		t.Error("archives of the same tree differ")
+	}
+}
+
+func TestCreateUnknownFormat(t *testing.T) {
+	if err := Create(tree(t), filepath.Join(t.TempDir(), "out.rar")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Create(.rar) = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func tarFile(t *testing.T, hdrs ...*tar.Header) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "evil.tar")
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, h := range hdrs {
+		if h.Typeflag == 0 {
+			h.Typeflag = tar.TypeReg
+		}
+		if h.Mode == 0 {
+			h.Mode = 0o644
+		}
+		tw.WriteHeader(h)
+		tw.Write(make([]byte, h.Size))
+	}
+	tw.Close()
+	os.WriteFile(path, buf.Bytes(), 0o644)
+	return path
+}
+
+func TestExtractRejectsTraversal(t *testing.T) {
+	tests := map[string]string{
+		"dot-dot":       tarFile(t, &tar.Header{Name: "../evil", Size: 1}),
+		"absolute":      tarFile(t, &tar.Header{Name: "/tmp/evil", Size: 1}),
+		"symlink out":   tarFile(t, &tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../etc"}),
+		"symlink abs":   tarFile(t, &tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}),
+		"hardlink out":  tarFile(t, &tar.Header{Name: "link", Typeflag: tar.TypeLink, Linkname: "../secret"}),
+		"nested dotdot": tarFile(t, &tar.Header{Name: "a/../../evil", Size: 1}),
+	}
+	for name, path := range tests {
+		out := filepath.Join(t.TempDir(), "out")
+		if err := Extract(path, out); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("%s: Extract = %v, want ErrUnsafePath", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(out), "evil")); err == nil {
+			t.Errorf("%s: file written outside the destination", name)
+		}
+	}
+}
+
+func TestExtractZipSlip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "evil.zip")
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("../../evil.txt")
+	w.Write([]byte("x"))
+	zw.Close()
+	os.WriteFile(path, buf.Bytes(), 0o644)
+	if err := Extract(path, t.TempDir()); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("Extract = %v, want ErrUnsafePath", err)
+	}
+}
+
+func TestExtractThroughSymlinks(t *testing.T) {
+	path := tarFile(t,
+		&tar.Header{Name: "data/", Typeflag: tar.TypeDir, Mode: 0o755},
+		&tar.Header{Name: "data/file", Size: 3},
+		&tar.Header{Name: "current", Typeflag: tar.TypeSymlink, Linkname: "data"},
+		&tar.Header{Name: "hard", Typeflag: tar.TypeLink, Linkname: "current/file"},
+	)
+	out := t.TempDir()
+	if err := Extract(path, out); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Lstat(filepath.Join(out, "hard")); err != nil || !fi.Mode().IsRegular() {
+		t.Errorf("hard link through a link inside the destination: %v", err)
+	}
+
+	// Writing inside a link is refused, even one staying inside.
+	path = tarFile(t,
+		&tar.Header{Name: "data/", Typeflag: tar.TypeDir, Mode: 0o755},
+		&tar.Header{Name: "current", Typeflag: tar.TypeSymlink, Linkname: "data"},
+		&tar.Header{Name: "current/file", Size: 3},
+	)
+	if err := Extract(path, t.TempDir()); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("entry inside a link: Extract = %v, want ErrUnsafePath", err)
+	}
+}
+
+func TestExtractLinkEscapes(t *testing.T) {
+	tests := map[string]string{
+		"chained links": tarFile(t,
+			&tar.Header{Name: "a/b", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			&tar.Header{Name: "a/b/c", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			&tar.Header{Name: "h", Typeflag: tar.TypeLink, Linkname: "a/b/c/secret.txt"},
+			&tar.Header{Name: "h", Size: 8},
+		),
+		"symlink through link": tarFile(t,
+			&tar.Header{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "."},
+			&tar.Header{Name: "y", Typeflag: tar.TypeSymlink, Linkname: "x/../secret.txt"},
+		),
+		"hardlink through link": tarFile(t,
+			&tar.Header{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "."},
+			&tar.Header{Name: "h", Typeflag: tar.TypeLink, Linkname: "x/../secret.txt"},
+			&tar.Header{Name: "h", Size: 8},
+		),
+	}
+	for name, path := range tests {
+		parent := t.TempDir()
+		secret := filepath.Join(parent, "secret.txt")
+		os.WriteFile(secret, []byte("original"), 0o644)
+		out := filepath.Join(parent, "out")
+		if err := Extract(path, out); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("%s: Extract = %v, want ErrUnsafePath", name, err)
+		}
+		if b, _ := os.ReadFile(secret); string(b) != "original" {
+			t.Errorf("%s: file outside the destination overwritten: %q", name, b)
+		}
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	many := tarFile(t, &tar.Header{Name: "a", Size: 1}, &tar.Header{Name: "b", Size: 1}, &tar.Header{Name: "c", Size: 1})
+	if err := Extract(many, t.TempDir(), WithLimits(Limits{MaxFiles: 2})); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("MaxFiles: Extract = %v, want ErrLimitExceeded", err)
+	}
+	big := tarFile(t, &tar.Header{Name: "big", Size: 1 << 20})
+	if err := Extract(big, t.TempDir(), WithLimits(Limits{MaxFileSize: 1 << 10})); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("MaxFileSize: Extract = %v, want ErrLimitExceeded", err)
+	}
+	if err := Extract(many, t.TempDir(), WithLimits(Limits{MaxTotalSize: 2})); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("MaxTotalSize: Extract = %v, want ErrLimitExceeded", err)
+	}
+
+	// 10 MB of zeros compresses about a thousandfold.
+	src := t.TempDir()
+	os.WriteFile(filepath.Join(src, "zeros"), make([]byte, 10<<20), 0o644)
+	bomb := filepath.Join(t.TempDir(), "bomb.tar.gz")
+	if err := Create(src, bomb, WithCompressionLevel(9)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Extract(bomb, t.TempDir()); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("MaxRatio: Extract = %v, want ErrLimitExceeded", err)
+	}
+	if err := Extract(bomb, t.TempDir(), WithLimits(Limits{})); err != nil {
+		t.Errorf("unlimited: Extract = %v", err)
+	}
+}
+
+func TestExtractWithoutPermissions(t *testing.T) {
+	src := tree(t)
+	dst := filepath.Join(t.TempDir(), "out.zip")
+	if err := Create(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	if err := Extract(dst, out, WithPermissions(false)); err != nil {
+		t.Fatal(err)
+	}
+	fi, _ := os.Stat(filepath.Join(out, "bin", "run"))
+	if fi.Mode().Perm() != 0o644 {
+		t.Errorf("bin/run mode = %v, want 0644", fi.Mode().Perm())
+	}
+}
@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// zipEpoch is the earliest time a zip entry can hold, used for
+// deterministic archives.
+var zipEpoch = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type entry struct {
+	name string // slash-separated name in the archive
+	path string
+	info fs.FileInfo
+}
+
+// Create archives src, a file or a directory, to dst in the format of its
+// extension. A directory's files are stored under their path relative to
+// it; symbolic links to files are stored as the files they point to, and
+// empty directories are left out, as on the Python side. dst is removed
+// if Create fails.
+func Create(src, dst string, opts ...Option) (err error) {
+	format, err := FormatOf(dst)
+	if err != nil {
+		return err
+	}
+	o := newOptions(opts)
+	entries, err := collect(src)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("archive: %w", cerr)
+		}
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+	if format == Zip {
+		return writeZip(f, entries, &o)
+	}
+	var w io.Writer = f
+	var gz *gzip.Writer
+	if format == TarGz {
+		if gz, err = gzip.NewWriterLevel(f, o.level); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+		w = gz
+	}
+	if err := writeTar(w, entries, &o); err != nil {
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+	}
+	return nil
+}
+
+// collect lists the files to archive in lexical order.
+func collect(src string) ([]entry, error) {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []entry{{name: filepath.Base(src), path: src, info: fi}}, nil
+	}
+	var entries []entry
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{name: filepath.ToSlash(rel), path: path, info: fi})
+		return nil
+	})
+	return entries, err
+}
+
+func (o *options) mode(fi fs.FileInfo) fs.FileMode {
+	if o.permissions {
+		return fi.Mode().Perm()
+	}
+	return 0o644
+}
+
+func writeTar(w io.Writer, entries []entry, o *options) error {
+	tw := tar.NewWriter(w)
+	var p Progress
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     e.name,
+			Size:     e.info.Size(),
+			Mode:     int64(o.mode(e.info)),
+			ModTime:  e.info.ModTime(),
+			Format:   tar.FormatPAX,
+		}
+		if o.deterministic {
+			hdr.ModTime = time.Unix(0, 0)
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("archive: %s: %w", e.name, err)
+		}
+		if err := copyFile(tw, e.path); err != nil {
+			return fmt.Errorf("archive: %s: %w", e.name, err)
+		}
+		o.report(&p, e.name, e.info.Size())
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	return nil
+}
+
+func writeZip(w io.Writer, entries []entry, o *options) error {
+	zw := zip.NewWriter(w)
+	level := o.level
+	zw.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, level)
+	})
+	var p Progress
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: e.info.ModTime()}
+		if o.deterministic {
+			hdr.Modified = zipEpoch
+		}
+		hdr.SetMode(o.mode(e.info))
+		fw, err := zw.CreateHeader(hdr)
+		if err == nil {
+			err = copyFile(fw, e.path)
+		}
+		if err != nil {
+			return fmt.Errorf("archive: %s: %w", e.name, err)
+		}
+		o.report(&p, e.name, e.info.Size())
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	return nil
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return errors.Join(err, f.Close())
+}
@@ -0,0 +1,336 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Extract extracts the archive at src, in the format of its extension,
+// into the directory dir, creating it if needed. It fails with an error
+// matching ErrUnsafePath for an entry or link that would leave dir, or an
+// entry inside a symbolic link the archive created, and
+// ErrLimitExceeded at the first limit exceeded; entries extracted until
+// then are left in place. Regular files, directories and links are
+// extracted; other entry types, such as devices, are skipped.
+func Extract(src, dir string, opts ...Option) error {
+	format, err := FormatOf(src)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	// The root confines every file and link written to dir, even through
+	// symbolic links created by earlier entries.
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	defer root.Close()
+	x := &extractor{o: newOptions(opts), root: root, size: fi.Size()}
+	switch format {
+	case Zip:
+		zr, zerr := zip.NewReader(f, fi.Size())
+		if zerr != nil {
+			return fmt.Errorf("archive: %s: %w", src, zerr)
+		}
+		err = x.zip(zr)
+	case TarGz:
+		gz, gerr := gzip.NewReader(f)
+		if gerr != nil {
+			return fmt.Errorf("archive: %s: %w", src, gerr)
+		}
+		err = x.tar(gz)
+	default:
+		err = x.tar(f)
+	}
+	return errors.Join(err, x.finish())
+}
+
+type extractor struct {
+	o        options
+	root     *os.Root
+	size     int64 // of the archive, for the compression ratio
+	progress Progress
+	dirModes map[string]fs.FileMode // applied last, so read-only directories can be filled
+	links    map[string]string      // targets of the symbolic links extracted
+}
+
+func (x *extractor) tar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+		name, err := x.entry(hdr.Name)
+		if err != nil {
+			return err
+		}
+		mode := fs.FileMode(hdr.Mode).Perm()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.mkdir(name, mode)
+		case tar.TypeReg:
+			err = x.file(name, tr, hdr.Size, mode)
+		case tar.TypeSymlink:
+			err = x.symlink(name, hdr.Linkname)
+		case tar.TypeLink:
+			err = x.link(name, hdr.Linkname)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (x *extractor) zip(zr *zip.Reader) error {
+	for _, zf := range zr.File {
+		name, err := x.entry(zf.Name)
+		if err != nil {
+			return err
+		}
+		mode := zf.Mode()
+		switch {
+		case mode.IsDir() || strings.HasSuffix(zf.Name, "/"):
+			err = x.mkdir(name, mode.Perm()|0o700)
+		case mode&fs.ModeSymlink != 0:
+			var target []byte
+			target, err = readZip(zf, 4096)
+			if err == nil {
+				err = x.symlink(name, string(target))
+			}
+		case mode.IsRegular():
+			var r io.ReadCloser
+			if r, err = zf.Open(); err == nil {
+				err = x.file(name, r, int64(zf.UncompressedSize64), mode.Perm())
+				r.Close()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readZip(zf *zip.File, limit int64) ([]byte, error) {
+	r, err := zf.Open()
+	if err != nil {
+		return nil, fmt.Errorf("archive: %s: %w", zf.Name, err)
+	}
+	defer r.Close()
+	return io.ReadAll(io.LimitReader(r, limit))
+}
+
+// entry validates an entry name and counts the entry against MaxFiles.
+// An entry inside a symbolic link extracted before is rejected, as
+// writing through it could reach a path the link checks did not see.
+func (x *extractor) entry(name string) (string, error) {
+	clean := filepath.FromSlash(strings.TrimSuffix(name, "/"))
+	if !filepath.IsLocal(clean) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	for dir := filepath.Dir(clean); dir != "."; dir = filepath.Dir(dir) {
+		if _, ok := x.links[dir]; ok {
+			return "", fmt.Errorf("%w: %q is inside link %q", ErrUnsafePath, name, dir)
+		}
+	}
+	if max := x.o.limits.MaxFiles; max > 0 && x.progress.Entries >= max {
+		return "", fmt.Errorf("%w: more than %d entries", ErrLimitExceeded, max)
+	}
+	return clean, nil
+}
+
+func (x *extractor) perm(mode, fallback fs.FileMode) fs.FileMode {
+	if x.o.permissions {
+		return mode
+	}
+	return fallback
+}
+
+func (x *extractor) mkdir(name string, mode fs.FileMode) error {
+	if err := x.mkdirAll(name); err != nil {
+		return err
+	}
+	if x.dirModes == nil {
+		x.dirModes = make(map[string]fs.FileMode)
+	}
+	x.dirModes[name] = x.perm(mode, 0o755)
+	x.o.report(&x.progress, name, 0)
+	return nil
+}
+
+// mkdirAll creates name and its parents inside the root.
+func (x *extractor) mkdirAll(name string) error {
+	if name == "." {
+		return nil
+	}
+	if err := x.mkdirAll(filepath.Dir(name)); err != nil {
+		return err
+	}
+	if err := x.root.Mkdir(name, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("archive: %w", err)
+	}
+	return nil
+}
+
+// file writes the content of an entry, stopping at the size limits
+// whatever size the header claims.
+func (x *extractor) file(name string, r io.Reader, size int64, mode fs.FileMode) error {
+	l := x.o.limits
+	limit := int64(-1)
+	if l.MaxFileSize > 0 {
+		limit = l.MaxFileSize
+	}
+	if l.MaxTotalSize > 0 {
+		if rest := l.MaxTotalSize - x.progress.Bytes; limit < 0 || rest < limit {
+			limit = rest
+		}
+	}
+	if limit >= 0 && size > limit {
+		return fmt.Errorf("%w: %s is %d bytes", ErrLimitExceeded, name, size)
+	}
+	if err := x.mkdirAll(filepath.Dir(name)); err != nil {
+		return err
+	}
+	f, err := x.root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	if limit >= 0 {
+		r = io.LimitReader(r, limit+1)
+	}
+	n, err := io.Copy(f, r)
+	if err == nil {
+		err = f.Chmod(x.perm(mode, 0o644))
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("archive: %s: %w", name, err)
+	}
+	if limit >= 0 && n > limit {
+		return fmt.Errorf("%w: %s exceeds the size limit", ErrLimitExceeded, name)
+	}
+	x.o.report(&x.progress, name, n)
+	if l.MaxRatio > 0 && x.size > 0 && float64(x.progress.Bytes)/float64(x.size) > l.MaxRatio {
+		return fmt.Errorf("%w: compression ratio above %g", ErrLimitExceeded, l.MaxRatio)
+	}
+	return nil
+}
+
+// symlink creates a symbolic link whose target, resolved from the link's
+// directory through the links extracted before, stays inside the
+// destination.
+func (x *extractor) symlink(name, target string) error {
+	t := filepath.FromSlash(target)
+	if _, ok := x.resolve(filepath.Dir(name), t, 0); !ok {
+		return fmt.Errorf("%w: link %q -> %q", ErrUnsafePath, name, target)
+	}
+	if err := x.mkdirAll(filepath.Dir(name)); err != nil {
+		return err
+	}
+	if err := x.root.Symlink(t, name); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	if x.links == nil {
+		x.links = make(map[string]string)
+	}
+	x.links[name] = t
+	x.o.report(&x.progress, name, 0)
+	return nil
+}
+
+// link creates a hard link to an entry extracted earlier, resolved
+// through the links extracted before.
+func (x *extractor) link(name, target string) error {
+	t, ok := x.resolve(".", filepath.FromSlash(target), 0)
+	if !ok {
+		return fmt.Errorf("%w: link %q -> %q", ErrUnsafePath, name, target)
+	}
+	if err := x.mkdirAll(filepath.Dir(name)); err != nil {
+		return err
+	}
+	if err := x.root.Link(t, name); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	x.o.report(&x.progress, name, 0)
+	return nil
+}
+
+// maxLinkDepth bounds the symbolic links followed by resolve, as the
+// kernel does.
+const maxLinkDepth = 40
+
+// resolve returns the path in the destination that rel names relative to
+// the directory dir, which holds no symbolic links, following the links
+// extracted so far as the kernel would, or false if it leads outside.
+func (x *extractor) resolve(dir, rel string, depth int) (string, bool) {
+	if filepath.IsAbs(rel) || depth > maxLinkDepth {
+		return "", false
+	}
+	cur := dir
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		switch part {
+		case "", ".":
+		case "..":
+			if cur == "." {
+				return "", false
+			}
+			cur = filepath.Dir(cur)
+		default:
+			next := filepath.Join(cur, part)
+			if t, ok := x.links[next]; ok {
+				if next, ok = x.resolve(cur, t, depth+1); !ok {
+					return "", false
+				}
+			}
+			cur = next
+		}
+	}
+	return cur, true
+}
+
+// finish applies directory modes, deepest first.
+func (x *extractor) finish() error {
+	var errs []error
+	for _, name := range slices.Backward(slices.Sorted(maps.Keys(x.dirModes))) {
+		d, err := x.root.Open(name)
+		if err == nil {
+			err = errors.Join(d.Chmod(x.dirModes[name]), d.Close())
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("archive: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
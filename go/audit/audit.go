@@ -0,0 +1,277 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package audit records who did what to which resource, and with what
+// outcome, in an append-only trail kept apart from diagnostic logs. An
+// entry is built in a fixed order, so one lacking an actor, action,
+// resource or outcome does not compile:
+//
+//	sink, err := audit.OpenFile("/var/log/app/audit.jsonl")
+//	auditor := audit.New([]audit.Sink{sink})
+//	err = auditor.Log(ctx, audit.By(user.ID).Did("user.delete").On("user:42").Succeeded().
+//		With("reason", "account closed"))
+//
+// Each record carries the hash of the one before it, so editing, removing
+// or reordering records of a trail is detected by Verify. Records go to
+// every sink of the Logger: a file, a database table or a webhook are
+// built in.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+// Outcome is how an audited action ended.
+type Outcome string
+
+// Outcomes.
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+	OutcomeDenied  Outcome = "denied"
+)
+
+// Record is an entry of the trail as written to sinks.
+type Record struct {
+	// Seq numbers the records of a trail from 1.
+	Seq       uint64         `json:"seq"`
+	Time      time.Time      `json:"time"`
+	Actor     string         `json:"actor"`
+	Action    string         `json:"action"`
+	Resource  string         `json:"resource"`
+	Outcome   Outcome        `json:"outcome"`
+	Reason    string         `json:"reason,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Tenant    string         `json:"tenant,omitempty"`
+	TraceID   string         `json:"trace_id,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	// PrevHash is the Hash of the record before, empty for the first.
+	PrevHash string `json:"prev_hash,omitempty"`
+	// Hash is the hex SHA-256 of the JSON of the record with Hash empty.
+	Hash string `json:"hash"`
+}
+
+// ComputeHash returns the Hash r should have.
+func (r Record) ComputeHash() string {
+	r.Hash = ""
+	b, _ := json.Marshal(r)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Entry is a complete audit entry, built starting with By.
+type Entry struct{ r Record }
+
+// ActorStep is an entry with its actor, awaiting the action.
+type ActorStep struct{ r Record }
+
+// ActionStep is an entry with its action, awaiting the resource.
+type ActionStep struct{ r Record }
+
+// ResourceStep is an entry with its resource, awaiting the outcome.
+type ResourceStep struct{ r Record }
+
+// By starts an entry for an action taken by actor, such as a user ID or
+// service name.
+func By(actor string) ActorStep { return ActorStep{Record{Actor: actor}} }
+
+// Did sets the action, such as "user.delete".
+func (s ActorStep) Did(action string) ActionStep {
+	s.r.Action = action
+	return ActionStep(s)
+}
+
+// On sets the resource acted on, such as "user:42".
+func (s ActionStep) On(resource string) ResourceStep {
+	s.r.Resource = resource
+	return ResourceStep(s)
+}
+
+// Succeeded completes the entry with OutcomeSuccess.
+func (s ResourceStep) Succeeded() Entry {
+	s.r.Outcome = OutcomeSuccess
+	return Entry(s)
+}
+
+// Failed completes the entry with OutcomeFailure and err as the reason.
+func (s ResourceStep) Failed(err error) Entry {
+	s.r.Outcome = OutcomeFailure
+	if err != nil {
+		s.r.Reason = err.Error()
+	}
+	return Entry(s)
+}
+
+// Denied completes the entry with OutcomeDenied and why the action was
+// not allowed.
+func (s ResourceStep) Denied(reason string) Entry {
+	s.r.Outcome = OutcomeDenied
+	s.r.Reason = reason
+	return Entry(s)
+}
+
+// With returns the entry with a detail added. Values must encode to JSON.
+func (e Entry) With(key string, value any) Entry {
+	d := make(map[string]any, len(e.r.Details)+1)
+	maps.Copy(d, e.r.Details)
+	d[key] = value
+	e.r.Details = d
+	return e
+}
+
+// Sink stores audit records. Write is called for one record at a time,
+// in order.
+type Sink interface {
+	Write(ctx context.Context, r Record) error
+}
+
+// SinkFunc adapts a function to Sink.
+type SinkFunc func(ctx context.Context, r Record) error
+
+// Write calls f.
+func (f SinkFunc) Write(ctx context.Context, r Record) error { return f(ctx, r) }
+
+// Option configures New.
+type Option func(*Logger)
+
+// WithClock sets the clock records are timed by. The default is
+// clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(l *Logger) { l.clock = c }
+}
+
+// WithHead continues the trail ending with last, such as the last record
+// read back with ReadFile, instead of starting a new one. The zero Record
+// starts a new trail.
+func WithHead(last Record) Option {
+	return func(l *Logger) { l.seq, l.last = last.Seq, last.Hash }
+}
+
+// Logger appends entries to the trail. It is safe for concurrent use;
+// entries are written one at a time, in the order of the trail.
+type Logger struct {
+	sinks []Sink
+	clock clock.Clock
+
+	mu   sync.Mutex
+	seq  uint64
+	last string // hash of the record seq
+}
+
+// New returns a logger writing to sinks.
+func New(sinks []Sink, opts ...Option) *Logger {
+	l := &Logger{sinks: sinks, clock: clock.Real()}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Log appends e to the trail, with the request ID, tenant and trace ID of
+// ctx, and writes it to every sink. The time is kept to the microsecond,
+// as databases store it. A failing sink does not keep the others from
+// writing; the record keeps its place in the trail, so the trail of that
+// sink shows a gap. An incomplete entry, such as the zero Entry, is an
+// error.
+func (l *Logger) Log(ctx context.Context, e Entry) error {
+	r := e.r
+	if r.Actor == "" || r.Action == "" || r.Resource == "" || r.Outcome == "" {
+		return errors.New("audit: entry lacks an actor, action, resource or outcome")
+	}
+	if r.Details != nil {
+		// Store details as they read back from JSON, for the hash to match.
+		d, err := normalize(r.Details)
+		if err != nil {
+			return fmt.Errorf("audit: details: %w", err)
+		}
+		r.Details = d
+	}
+	meta := ctxmeta.FromContext(ctx)
+	r.RequestID, r.Tenant = meta.RequestID, meta.Tenant
+	if sc := trace.SpanContextFromContext(ctx); sc.TraceID.IsValid() {
+		r.TraceID = sc.TraceID.String()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r.Time = l.clock.Now().UTC().Truncate(time.Microsecond)
+	r.Seq = l.seq + 1
+	r.PrevHash = l.last
+	r.Hash = r.ComputeHash()
+	l.seq, l.last = r.Seq, r.Hash
+
+	var errs []error
+	for _, s := range l.sinks {
+		if err := s.Write(ctx, r); err != nil {
+			errs = append(errs, fmt.Errorf("audit: %T: %w", s, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the sinks that are io.Closers.
+func (l *Logger) Close() error {
+	var errs []error
+	for _, s := range l.sinks {
+		if c, ok := s.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func normalize(d map[string]any) (map[string]any, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err = dec.Decode(&out)
+	return out, err
+}
+
+// ChainError reports the first record of a trail that does not belong.
+type ChainError struct {
+	Seq    uint64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit: record %d: %s", e.Seq, e.Reason)
+}
+
+// Verify checks that records are an unbroken part of a trail: numbered
+// one after another, each with the hash of its contents and linked to the
+// one before. It returns a *ChainError for the first record that is not.
+// A trail read from a database must keep the time to the microsecond.
+func Verify(records []Record) error {
+	for i, r := range records {
+		switch {
+		case r.Hash != r.ComputeHash():
+			return &ChainError{r.Seq, "hash does not match contents"}
+		case i == 0 && r.Seq == 1 && r.PrevHash != "":
+			return &ChainError{r.Seq, "first record links to another"}
+		case i > 0 && r.Seq != records[i-1].Seq+1:
+			return &ChainError{r.Seq, fmt.Sprintf("follows record %d", records[i-1].Seq)}
+		case i > 0 && r.PrevHash != records[i-1].Hash:
+			return &ChainError{r.Seq, "does not link to the record before"}
+		}
+	}
+	return nil
+}
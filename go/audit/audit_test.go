@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+)
+
+// memory collects the records written to it.
+type memory struct{ records []Record }
+
+func (m *memory) Write(_ context.Context, r Record) error {
+	m.records = append(m.records, r)
+	return nil
+}
+
+func TestLog(t *testing.T) {
+	clk := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC))
+	var m memory
+	auditor := New([]Sink{&m}, WithClock(clk))
+	ctx := ctxmeta.WithRequestID(context.Background(), "req-1")
+
+	entries := []Entry{
+		By("alice").Did("user.delete").On("user:42").Succeeded().With("count", 3).With("reason", "closed"),
+		By("bob").Did("invoice.read").On("invoice:7").Denied("not the owner"),
+		By("svc-billing").Did("charge.create").On("account:9").Failed(errors.New("card declined")),
+	}
+	for _, e := range entries {
+		if err := auditor.Log(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(m.records) != 3 {
+		t.Fatalf("records = %+v", m.records)
+	}
+	first := m.records[0]
+	if first.Seq != 1 || first.Actor != "alice" || first.Outcome != OutcomeSuccess || first.RequestID != "req-1" ||
+		!first.Time.Equal(time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC)) ||
+		first.Details["reason"] != "closed" || first.PrevHash != "" || first.Hash != first.ComputeHash() {
+		t.Errorf("first record = %+v", first)
+	}
+	if r := m.records[1]; r.Outcome != OutcomeDenied || r.Reason != "not the owner" || r.PrevHash != first.Hash {
+		t.Errorf("second record = %+v", r)
+	}
+	if r := m.records[2]; r.Outcome != OutcomeFailure || r.Reason != "card declined" || r.Seq != 3 {
+		t.Errorf("third record = %+v", r)
+	}
+	if err := Verify(m.records); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+
+	if err := auditor.Log(ctx, Entry{}); err == nil {
+		t.Error("Log accepted the zero Entry")
+	}
+	if err := auditor.Log(ctx, By("").Did("x").On("y").Succeeded()); err == nil {
+		t.Error("Log accepted an entry without an actor")
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	var m memory
+	auditor := New([]Sink{&m})
+	for _, actor := range []string{"a", "b", "c", "d"} {
+		auditor.Log(context.Background(), By(actor).Did("login").On("session").Succeeded())
+	}
+	edited := append([]Record(nil), m.records...)
+	edited[1].Actor = "mallory"
+	rehashed := append([]Record(nil), m.records...)
+	rehashed[1].Actor = "mallory"
+	rehashed[1].Hash = rehashed[1].ComputeHash()
+
+	for _, tt := range []struct {
+		name    string
+		records []Record
+		seq     uint64
+	}{
+		{"edited", edited, 2},
+		{"rehashed", rehashed, 3},
+		{"removed", []Record{m.records[0], m.records[2], m.records[3]}, 3},
+		{"reordered", []Record{m.records[0], m.records[2], m.records[1]}, 3},
+	} {
+		var ce *ChainError
+		if err := Verify(tt.records); !errors.As(err, &ce) || ce.Seq != tt.seq {
+			t.Errorf("%s: Verify = %v, want an error at record %d", tt.name, err, tt.seq)
+		}
+	}
+	if err := Verify(m.records[2:]); err != nil {
+		t.Errorf("Verify of the tail = %v", err)
+	}
+}
+
+func TestLogSinkFailure(t *testing.T) {
+	var m memory
+	fail := true
+	flaky := SinkFunc(func(context.Context, Record) error {
+		if fail {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+	auditor := New([]Sink{flaky, &m})
+	if err := auditor.Log(context.Background(), By("a").Did("login").On("session").Succeeded()); err == nil {
+		t.Error("sink failure not returned")
+	}
+	fail = false
+	auditor.Log(context.Background(), By("a").Did("logout").On("session").Succeeded())
+	if len(m.records) != 2 || m.records[1].Seq != 2 || Verify(m.records) != nil {
+		t.Errorf("other sink got %+v", m.records)
+	}
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/provide-io/provide-foundation/go/db"
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/notify"
+)
+
+// FileSink appends records to a file as JSON lines, syncing each to disk
+// before Write returns.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenFile returns a sink appending to the file at path, created with
+// mode 0600 if it does not exist.
+func OpenFile(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	return &FileSink{file: f}, nil
+}
+
+// Write implements Sink.
+func (s *FileSink) Write(_ context.Context, r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error { return s.file.Close() }
+
+// ReadFile returns the records of a file written by a FileSink, for
+// Verify and WithHead.
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	defer f.Close()
+	var records []Record
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 16<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var r Record
+		dec := json.NewDecoder(bytes.NewReader(sc.Bytes()))
+		dec.UseNumber()
+		if err := dec.Decode(&r); err != nil {
+			return nil, fmt.Errorf("audit: %s:%d: %w", path, line, err)
+		}
+		records = append(records, r)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	return records, nil
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DBSink inserts records into a database table with the columns seq,
+// time, actor, action, resource, outcome, reason, request_id, tenant,
+// trace_id, details (the JSON of Details, empty without any), prev_hash
+// and hash. Grant the service INSERT on the table but not UPDATE or
+// DELETE, to keep the trail append-only.
+type DBSink struct {
+	db     db.Database
+	insert string
+}
+
+// NewDBSink returns a sink inserting into table, optionally
+// schema-qualified, of database.
+func NewDBSink(database db.Database, table string) (*DBSink, error) {
+	for _, part := range strings.Split(table, ".") {
+		if !identifier.MatchString(part) {
+			return nil, fmt.Errorf("audit: invalid table name %q", table)
+		}
+	}
+	return &DBSink{db: database, insert: "INSERT INTO " + table +
+		" (seq, time, actor, action, resource, outcome, reason, request_id, tenant, trace_id, details, prev_hash, hash)" +
+		" VALUES (:seq, :time, :actor, :action, :resource, :outcome, :reason, :request_id, :tenant, :trace_id, :details, :prev_hash, :hash)"}, nil
+}
+
+// Write implements Sink.
+func (s *DBSink) Write(ctx context.Context, r Record) error {
+	var details string
+	if len(r.Details) > 0 {
+		b, err := json.Marshal(r.Details)
+		if err != nil {
+			return err
+		}
+		details = string(b)
+	}
+	_, err := s.db.NamedExec(ctx, s.insert, map[string]any{
+		"seq": int64(r.Seq), "time": r.Time, "actor": r.Actor, "action": r.Action,
+		"resource": r.Resource, "outcome": string(r.Outcome), "reason": r.Reason,
+		"request_id": r.RequestID, "tenant": r.Tenant, "trace_id": r.TraceID,
+		"details": details, "prev_hash": r.PrevHash, "hash": r.Hash,
+	})
+	return err
+}
+
+// HeaderSignature carries "sha256=" and the hex HMAC-SHA256 of the body
+// of a webhook delivery, as notify.Sign computes it.
+const HeaderSignature = "X-Audit-Signature-256"
+
+// WebhookSink POSTs each record as JSON to a URL, such as the collector
+// of a SIEM.
+type WebhookSink struct {
+	client *httpx.Client
+	secret []byte
+}
+
+// NewWebhookSink returns a sink POSTing to url, signing each body with
+// secret unless it is empty. opts configure its HTTP client, e.g. to add
+// retries.
+func NewWebhookSink(url, secret string, opts ...httpx.Option) (*WebhookSink, error) {
+	if url == "" {
+		return nil, errors.New("audit: webhook: no URL")
+	}
+	client, err := httpx.New(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookSink{client: client, secret: []byte(secret)}, nil
+}
+
+// Write implements Sink. A 4xx or 5xx response fails with an
+// *httpx.StatusError.
+func (s *WebhookSink) Write(ctx context.Context, r Record) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	opts := []httpx.RequestOption{httpx.WithHeader("Content-Type", "application/json")}
+	if len(s.secret) > 0 {
+		opts = append(opts, httpx.WithHeader(HeaderSignature, notify.Sign(s.secret, body)))
+	}
+	_, err = s.client.Post(ctx, "", bytes.NewReader(body), opts...)
+	return err
+}
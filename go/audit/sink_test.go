@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/db/dbtest"
+	"github.com/provide-io/provide-foundation/go/notify"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditor := New([]Sink{sink})
+	ctx := context.Background()
+	auditor.Log(ctx, By("alice").Did("role.grant").On("user:7").Succeeded().With("role", "admin").With("level", 2.5))
+	auditor.Log(ctx, By("alice").Did("role.revoke").On("user:7").Succeeded())
+	if err := auditor.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadFile(path)
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ReadFile = %+v, %v", records, err)
+	}
+	if err := Verify(records); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+
+	// A new logger continues the trail in the file.
+	sink, _ = OpenFile(path)
+	auditor = New([]Sink{sink}, WithHead(records[len(records)-1]))
+	auditor.Log(ctx, By("bob").Did("role.grant").On("user:8").Succeeded())
+	auditor.Close()
+	records, _ = ReadFile(path)
+	if len(records) != 3 || records[2].Seq != 3 || Verify(records) != nil {
+		t.Errorf("continued trail = %+v", records)
+	}
+}
+
+func TestDBSink(t *testing.T) {
+	fake := dbtest.New(t)
+	fake.Table("audit_log").Columns("seq", "time", "actor", "action", "resource", "outcome", "reason",
+		"request_id", "tenant", "trace_id", "details", "prev_hash", "hash")
+	sink, err := NewDBSink(fake, "audit_log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditor := New([]Sink{sink})
+	if err := auditor.Log(context.Background(), By("alice").Did("user.delete").On("user:42").Succeeded().With("hard", true)); err != nil {
+		t.Fatal(err)
+	}
+	rows := fake.Rows("audit_log")
+	if len(rows) != 1 || rows[0]["actor"] != "alice" || rows[0]["outcome"] != "success" ||
+		rows[0]["details"] != `{"hard":true}` || rows[0]["seq"] != int64(1) {
+		t.Errorf("rows = %v", rows)
+	}
+
+	if _, err := NewDBSink(fake, "audit; DROP TABLE users"); err == nil {
+		t.Error("invalid table name accepted")
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Record
+	var signed bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signed = r.Header.Get(HeaderSignature) == notify.Sign([]byte("s3cret"), body)
+		json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	sink, err := NewWebhookSink(srv.URL, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditor := New([]Sink{sink})
+	if err := auditor.Log(context.Background(), By("alice").Did("export").On("report:q3").Succeeded()); err != nil {
+		t.Fatal(err)
+	}
+	if got.Action != "export" || got.Hash == "" || !signed {
+		t.Errorf("received %+v, signed %v", got, signed)
+	}
+
+	if _, err := NewWebhookSink("", ""); err == nil {
+		t.Error("empty URL accepted")
+	}
+}
@@ -0,0 +1,328 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package batch accumulates items and hands them on in batches, the engine
+// of the OTLP exporters and of notification batching:
+//
+//	p := batch.New(func(ctx context.Context, events []Event) error {
+//		return warehouse.Insert(ctx, events)
+//	}, batch.WithMaxSize(500), batch.WithMaxWait(2*time.Second))
+//	defer p.Close(ctx)
+//	err := p.Add(ctx, Event{Name: "user.viewed", UserID: id})
+//
+// A batch is flushed once it holds MaxSize items, once its oldest item has
+// waited MaxWait, or when Flush is called. Items wait in a queue of
+// bounded size; what happens when it is full is the Overflow policy, from
+// dropping the item to making the caller wait. Flushes run one at a time
+// in the background, and their errors go to the error handler.
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+var (
+	// ErrFull is returned by Add when the queue is full and the item is
+	// dropped.
+	ErrFull = errorsx.New(errorsx.Unavailable, "batch: queue is full")
+	// ErrClosed is returned by Add once the processor is closed.
+	ErrClosed = errorsx.New(errorsx.Unavailable, "batch: processor is closed")
+)
+
+// Defaults follow the OpenTelemetry batch processors.
+const (
+	DefaultMaxSize   = 512
+	DefaultMaxWait   = time.Second
+	DefaultQueueSize = 2048
+)
+
+// Overflow decides what Add does when the queue is full.
+type Overflow int
+
+const (
+	// DropNewest drops the item being added and returns ErrFull. It never
+	// blocks the caller and is the default.
+	DropNewest Overflow = iota
+	// DropOldest drops the oldest queued item to make room.
+	DropOldest
+	// Block makes the caller wait for room until its context is done,
+	// pushing back on producers faster than the flush.
+	Block
+)
+
+// Option configures a Processor.
+type Option func(*options)
+
+type options struct {
+	maxSize   int
+	maxWait   time.Duration
+	queueSize int
+	overflow  Overflow
+	onError   func(err error)
+	clock     clock.Clock
+}
+
+// WithMaxSize sets the most items a flush is given. The default is
+// DefaultMaxSize.
+func WithMaxSize(n int) Option {
+	return func(o *options) { o.maxSize = n }
+}
+
+// WithMaxWait sets how long an item waits for its batch to fill before
+// the batch is flushed anyway. The default is DefaultMaxWait.
+func WithMaxWait(d time.Duration) Option {
+	return func(o *options) { o.maxWait = d }
+}
+
+// WithQueueSize bounds the items waiting to be flushed. The default is
+// DefaultQueueSize. A queue smaller than MaxSize is flushed after MaxWait
+// only.
+func WithQueueSize(n int) Option {
+	return func(o *options) { o.queueSize = n }
+}
+
+// WithOverflow sets what happens to items added to a full queue.
+func WithOverflow(policy Overflow) Option {
+	return func(o *options) { o.overflow = policy }
+}
+
+// WithErrorHandler calls fn with the error of each failed background
+// flush. By default it is logged with log.Default() as
+// batch_flush_failed; exporters of the logs themselves must set their
+// own.
+func WithErrorHandler(fn func(err error)) Option {
+	return func(o *options) { o.onError = fn }
+}
+
+// WithClock sets the clock MaxWait is measured by. The default is
+// clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// Processor accumulates items of type T and flushes them in batches. It
+// is safe for concurrent use.
+type Processor[T any] struct {
+	flush func(ctx context.Context, items []T) error
+	options
+	dropped atomic.Uint64
+
+	mu     sync.Mutex
+	queue  []T
+	since  time.Time     // when an item was last added to the empty queue
+	space  chan struct{} // closed when items leave the queue
+	closed bool
+
+	flushMu sync.Mutex
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	ctx     context.Context // of background flushes, cancelled when Close gives up
+	cancel  context.CancelFunc
+}
+
+// New returns a processor handing batches to flush and starts its
+// background goroutine. A batch is only retried if flush retries it.
+func New[T any](flush func(ctx context.Context, items []T) error, opts ...Option) *Processor[T] {
+	p := &Processor[T]{
+		flush: flush,
+		options: options{
+			maxSize:   DefaultMaxSize,
+			maxWait:   DefaultMaxWait,
+			queueSize: DefaultQueueSize,
+			clock:     clock.Real(),
+		},
+		space: make(chan struct{}),
+		kick:  make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&p.options)
+	}
+	p.maxSize = max(p.maxSize, 1)
+	p.queueSize = max(p.queueSize, 1)
+	if p.maxWait <= 0 {
+		p.maxWait = DefaultMaxWait
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	go p.run()
+	return p
+}
+
+// Add queues item. When the queue is full it follows the overflow
+// policy: it returns ErrFull, drops the oldest item, or waits for room
+// and returns the error of ctx if it ends first.
+func (p *Processor[T]) Add(ctx context.Context, item T) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for !p.closed && len(p.queue) >= p.queueSize {
+		switch p.overflow {
+		case DropOldest:
+			var zero T
+			p.queue[0] = zero
+			p.queue = p.queue[1:]
+			p.dropped.Add(1)
+		case Block:
+			space := p.space
+			p.mu.Unlock()
+			select {
+			case <-space:
+			case <-ctx.Done():
+				p.mu.Lock()
+				return ctx.Err()
+			}
+			p.mu.Lock()
+		default:
+			p.dropped.Add(1)
+			return ErrFull
+		}
+	}
+	if p.closed {
+		return ErrClosed
+	}
+	if len(p.queue) == 0 {
+		p.since = p.clock.Now()
+		p.signal()
+	}
+	p.queue = append(p.queue, item)
+	if len(p.queue) == p.maxSize {
+		p.signal()
+	}
+	return nil
+}
+
+// signal wakes the background goroutine. Callers hold p.mu.
+func (p *Processor[T]) signal() {
+	select {
+	case p.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Len returns the number of queued items.
+func (p *Processor[T]) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue)
+}
+
+// Dropped returns how many items the overflow policy discarded.
+func (p *Processor[T]) Dropped() uint64 { return p.dropped.Load() }
+
+// Flush flushes every queued item now, in batches of at most MaxSize, and
+// returns their errors. It stops early if ctx ends.
+func (p *Processor[T]) Flush(ctx context.Context) error {
+	return p.drain(ctx, 1)
+}
+
+// drain flushes batches while at least atLeast items are queued.
+func (p *Processor[T]) drain(ctx context.Context, atLeast int) error {
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+	var errs []error
+	for {
+		items := p.take(atLeast)
+		if len(items) == 0 {
+			return errors.Join(errs...)
+		}
+		if err := p.flush(ctx, items); err != nil {
+			errs = append(errs, fmt.Errorf("batch: flushing %d items: %w", len(items), err))
+			if ctx.Err() != nil {
+				return errors.Join(errs...)
+			}
+		}
+	}
+}
+
+// take removes a batch from the queue if at least atLeast items are
+// queued.
+func (p *Processor[T]) take(atLeast int) []T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.queue) == 0 || len(p.queue) < atLeast {
+		return nil
+	}
+	n := min(len(p.queue), p.maxSize)
+	items := p.queue[:n:n]
+	p.queue = p.queue[n:]
+	if len(p.queue) == 0 {
+		p.queue = nil
+	}
+	close(p.space)
+	p.space = make(chan struct{})
+	return items
+}
+
+// Close stops taking items, flushes the queued ones and stops the
+// background goroutine. If ctx ends first, the flush in progress is
+// cancelled.
+func (p *Processor[T]) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.space)
+	p.space = make(chan struct{})
+	p.mu.Unlock()
+
+	close(p.stop)
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		p.cancel()
+		<-p.done
+	}
+	defer p.cancel()
+	return p.Flush(ctx)
+}
+
+func (p *Processor[T]) run() {
+	defer close(p.done)
+	timer := p.clock.NewTimer(p.maxWait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-p.kick:
+		case <-timer.C():
+		}
+		if err := p.drain(p.ctx, p.maxSize); err != nil {
+			p.report(err)
+		}
+		p.mu.Lock()
+		n, wait := len(p.queue), p.maxWait-p.clock.Now().Sub(p.since)
+		p.mu.Unlock()
+		if n == 0 {
+			timer.Stop()
+			continue
+		}
+		if wait > 0 {
+			timer.Reset(wait)
+			continue
+		}
+		if err := p.Flush(p.ctx); err != nil {
+			p.report(err)
+		}
+	}
+}
+
+func (p *Processor[T]) report(err error) {
+	if p.onError != nil {
+		p.onError(err)
+		return
+	}
+	log.Default().Warn("batch_flush_failed", log.Err(err))
+}
@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+)
+
+// sink records the batches flushed to it.
+type sink struct {
+	mu      sync.Mutex
+	batches [][]int
+	flushed chan struct{}
+	err     error
+	block   chan struct{}
+}
+
+func newSink() *sink { return &sink{flushed: make(chan struct{}, 100)} }
+
+func (s *sink) flush(ctx context.Context, items []int) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	s.batches = append(s.batches, slices.Clone(items))
+	s.mu.Unlock()
+	s.flushed <- struct{}{}
+	return s.err
+}
+
+func (s *sink) got() [][]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.batches)
+}
+
+func TestFlushOnSize(t *testing.T) {
+	s := newSink()
+	p := New(s.flush, WithMaxSize(3), WithMaxWait(time.Hour))
+	defer p.Close(context.Background())
+	for i := range 7 {
+		p.Add(context.Background(), i)
+	}
+	<-s.flushed
+	<-s.flushed
+	if got := s.got(); !slices.EqualFunc(got, [][]int{{0, 1, 2}, {3, 4, 5}}, slices.Equal) {
+		t.Errorf("batches = %v", got)
+	}
+	if p.Len() != 1 {
+		t.Errorf("Len = %d, want 1", p.Len())
+	}
+}
+
+func TestFlushOnMaxWait(t *testing.T) {
+	clk := clock.NewFake(time.Unix(0, 0))
+	s := newSink()
+	p := New(s.flush, WithMaxWait(time.Second), WithClock(clk))
+	defer p.Close(context.Background())
+	p.Add(context.Background(), 1)
+	clk.BlockUntil(1)
+	clk.Advance(500 * time.Millisecond)
+	p.Add(context.Background(), 2)
+	select {
+	case <-s.flushed:
+		t.Fatal("flushed before MaxWait")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clk.Advance(500 * time.Millisecond)
+	<-s.flushed
+	if got := s.got(); !slices.EqualFunc(got, [][]int{{1, 2}}, slices.Equal) {
+		t.Errorf("batches = %v", got)
+	}
+}
+
+func TestFlushAndClose(t *testing.T) {
+	s := newSink()
+	p := New(s.flush, WithMaxSize(2), WithMaxWait(time.Hour))
+	p.Add(context.Background(), 1)
+	if err := p.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	p.Add(context.Background(), 2)
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.got(); !slices.EqualFunc(got, [][]int{{1}, {2}}, slices.Equal) {
+		t.Errorf("batches = %v", got)
+	}
+	if err := p.Add(context.Background(), 3); !errors.Is(err, ErrClosed) {
+		t.Errorf("Add after Close = %v", err)
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	for _, tt := range []struct {
+		policy Overflow
+		err    error
+		kept   []int
+	}{
+		{DropNewest, ErrFull, []int{2, 3}},
+		{DropOldest, nil, []int{3, 4}},
+	} {
+		s := newSink()
+		s.block = make(chan struct{})
+		p := New(s.flush, WithMaxSize(2), WithQueueSize(2), WithMaxWait(time.Hour), WithOverflow(tt.policy))
+		p.Add(context.Background(), 0)
+		p.Add(context.Background(), 1) // taken by a flush that is held up
+		for p.Len() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		p.Add(context.Background(), 2)
+		p.Add(context.Background(), 3)
+		if err := p.Add(context.Background(), 4); !errors.Is(err, tt.err) {
+			t.Errorf("policy %d: Add = %v, want %v", tt.policy, err, tt.err)
+		}
+		close(s.block)
+		p.Close(context.Background())
+		if got := s.got(); len(got) != 2 || !slices.Equal(got[1], tt.kept) {
+			t.Errorf("policy %d: batches = %v", tt.policy, got)
+		}
+		if p.Dropped() != 1 {
+			t.Errorf("policy %d: dropped = %d", tt.policy, p.Dropped())
+		}
+	}
+}
+
+func TestBlock(t *testing.T) {
+	s := newSink()
+	s.block = make(chan struct{})
+	p := New(s.flush, WithMaxSize(1), WithQueueSize(1), WithOverflow(Block))
+	defer p.Close(context.Background())
+	p.Add(context.Background(), 1) // taken by a flush that is held up
+	for p.Len() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	p.Add(context.Background(), 2) // fills the queue
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Add(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Add to a full queue = %v", err)
+	}
+	added := make(chan error)
+	go func() { added <- p.Add(context.Background(), 3) }()
+	close(s.block)
+	if err := <-added; err != nil {
+		t.Fatal(err)
+	}
+	if p.Dropped() != 0 {
+		t.Errorf("dropped = %d", p.Dropped())
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	s := newSink()
+	s.err = errors.New("collector down")
+	errc := make(chan error, 1)
+	p := New(s.flush, WithMaxSize(1), WithErrorHandler(func(err error) { errc <- err }))
+	defer p.Close(context.Background())
+	p.Add(context.Background(), 1)
+	if err := <-errc; !errors.Is(err, s.err) {
+		t.Errorf("handler got %v", err)
+	}
+}
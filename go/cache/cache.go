@@ -0,0 +1,316 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cache is an in-memory cache of values by key, with expiry after
+// a time to live and eviction of the least recently used entries beyond a
+// maximum count:
+//
+//	users := cache.New[int64, User](cache.WithTTL(time.Minute), cache.WithMaxEntries(10_000))
+//	u, err := users.GetOrLoad(ctx, id, func(ctx context.Context, id int64) (User, error) {
+//		return repo.FindByID(ctx, id)
+//	})
+//
+// GetOrLoad runs one load per key at a time, through a syncx.Singleflight:
+// concurrent misses on the same key wait for the first one's result
+// instead of all reaching the database. Errors are returned to every
+// waiter and not cached.
+//
+// Hits, misses, loads and evictions are counted in Stats and, with
+// WithMetrics, in a metrics registry.
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/internal/lru"
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/syncx"
+)
+
+// Metric names recorded with WithMetrics, labeled by cache name.
+const (
+	MetricRequests  = "cache_requests_total"  // and result: hit or miss
+	MetricLoads     = "cache_loads_total"     // and result: ok or error
+	MetricEvictions = "cache_evictions_total" // and reason: capacity or expired
+	MetricEntries   = "cache_entries"
+)
+
+// Option configures New.
+type Option func(*options)
+
+type options struct {
+	ttl        time.Duration
+	maxEntries int
+	clock      clock.Clock
+	registry   *metrics.Registry
+	name       string
+}
+
+// WithTTL sets how long an entry lives after it is set. The default, 0,
+// keeps entries until they are evicted or deleted.
+func WithTTL(d time.Duration) Option {
+	return func(o *options) { o.ttl = d }
+}
+
+// WithMaxEntries bounds the number of entries; setting one more evicts
+// the least recently used. The default, 0, is unbounded.
+func WithMaxEntries(n int) Option {
+	return func(o *options) { o.maxEntries = n }
+}
+
+// WithClock sets the clock entries expire by. The default is
+// clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// WithMetrics records the cache's requests, loads, evictions and size in
+// reg, or metrics.Default when reg is nil, labeled with name, and its
+// coalesced loads as syncx.WithMetrics does.
+func WithMetrics(reg *metrics.Registry, name string) Option {
+	return func(o *options) {
+		if reg == nil {
+			reg = metrics.Default
+		}
+		o.registry, o.name = reg, name
+	}
+}
+
+// Stats are the counts of a cache since it was created.
+type Stats struct {
+	Hits       uint64
+	Misses     uint64
+	Loads      uint64 // loader calls, successful or not
+	LoadErrors uint64
+	Evictions  uint64 // by capacity or expiry, not by Delete
+	Entries    int
+}
+
+// HitRatio returns the share of requests that were hits, or 0 without
+// requests.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Cache holds values of type V by keys of type K. It is safe for
+// concurrent use.
+type Cache[K comparable, V any] struct {
+	ttl    time.Duration
+	m      *instruments
+	flight *syncx.Singleflight[K, V]
+
+	mu    sync.Mutex
+	store *lru.Store[K, V]
+
+	hits, misses, loads, loadErrors, evictions atomic.Uint64
+}
+
+// instruments are the metrics of a named cache.
+type instruments struct {
+	name      string
+	requests  *metrics.Counter
+	loads     *metrics.Counter
+	evictions *metrics.Counter
+	entries   *metrics.Gauge
+}
+
+// New returns an empty cache.
+func New[K comparable, V any](opts ...Option) *Cache[K, V] {
+	o := options{clock: clock.Real()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c := &Cache[K, V]{ttl: o.ttl}
+	c.store = lru.New[K, V](o.maxEntries, o.clock, c.evicted)
+	var fopts []syncx.Option
+	if o.registry != nil {
+		fopts = append(fopts, syncx.WithMetrics(o.registry, o.name))
+		c.m = &instruments{
+			name:      o.name,
+			requests:  o.registry.Counter(MetricRequests, "Cache lookups.", "cache", "result"),
+			loads:     o.registry.Counter(MetricLoads, "Cache loader calls.", "cache", "result"),
+			evictions: o.registry.Counter(MetricEvictions, "Cache entries evicted.", "cache", "reason"),
+			entries:   o.registry.Gauge(MetricEntries, "Cache entries held.", "cache"),
+		}
+	}
+	c.flight = syncx.NewSingleflight[K, V](fopts...)
+	return c
+}
+
+// Get returns the value of key and whether it was present and live.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	v, ok := c.get(key)
+	c.mu.Unlock()
+	c.count(ok)
+	return v, ok
+}
+
+// get looks key up, dropping it if expired. c.mu is held.
+func (c *Cache[K, V]) get(key K) (V, bool) {
+	v, ok := c.store.Get(key)
+	if !ok {
+		c.gauge()
+	}
+	return v, ok
+}
+
+func (c *Cache[K, V]) count(hit bool) {
+	result := "miss"
+	if hit {
+		c.hits.Add(1)
+		result = "hit"
+	} else {
+		c.misses.Add(1)
+	}
+	if c.m != nil {
+		c.m.requests.Inc(c.m.name, result)
+	}
+}
+
+// Set stores value under key with the cache's time to live.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores value under key for ttl, or without expiry when ttl
+// is 0. A load of key in progress is not stored when it completes.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.flight.Forget(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value, ttl)
+}
+
+// set stores an entry and evicts beyond the maximum. c.mu is held.
+func (c *Cache[K, V]) set(key K, value V, ttl time.Duration) {
+	c.store.Set(key, value, ttl)
+	c.gauge()
+}
+
+// evicted counts an entry the store dropped for reason. c.mu is held.
+func (c *Cache[K, V]) evicted(reason string) {
+	c.evictions.Add(1)
+	if c.m != nil {
+		c.m.evictions.Inc(c.m.name, reason)
+	}
+}
+
+func (c *Cache[K, V]) gauge() {
+	if c.m != nil {
+		c.m.entries.Set(float64(c.store.Len()), c.m.name)
+	}
+}
+
+// Delete removes key. A load of key in progress is not stored when it
+// completes, so a value changed while it was read is not cached stale.
+func (c *Cache[K, V]) Delete(key K) {
+	c.flight.Forget(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store.Delete(key)
+	c.gauge()
+}
+
+// Purge removes every entry.
+func (c *Cache[K, V]) Purge() {
+	c.flight.ForgetAll()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store.Purge()
+	c.gauge()
+}
+
+// Prune removes the expired entries and returns how many there were.
+// Expired entries are otherwise only removed when looked up or evicted.
+func (c *Cache[K, V]) Prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.store.Prune()
+	c.gauge()
+	return n
+}
+
+// Len returns the number of entries, including expired ones not yet
+// removed.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.store.Len()
+}
+
+// Stats returns the counts of the cache.
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Loads:      c.loads.Load(),
+		LoadErrors: c.loadErrors.Load(),
+		Evictions:  c.evictions.Load(),
+		Entries:    c.Len(),
+	}
+}
+
+// Loader loads the value of a key missing from the cache.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// GetOrLoad returns the value of key, calling load and storing its result
+// on a miss. Callers missing the same key while a load is in progress
+// wait for it rather than loading again. The load runs without the
+// cancellation of ctx, as other callers may be waiting on it; a caller
+// whose ctx is done stops waiting with ctx.Err(). A panic in load is
+// returned as a *recovery.PanicError.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, load Loader[K, V]) (V, error) {
+	c.mu.Lock()
+	v, ok := c.get(key)
+	c.mu.Unlock()
+	c.count(ok)
+	if ok {
+		return v, nil
+	}
+	// Set and Delete forget the load of their key before writing, so a
+	// load they overtook is not stored.
+	loaded := false
+	v, _, err := c.flight.DoStore(ctx, key, func(ctx context.Context) (v V, err error) {
+		// A load completing since the lookup above has stored its value.
+		c.mu.Lock()
+		v, ok := c.get(key)
+		c.mu.Unlock()
+		if ok {
+			return v, nil
+		}
+		loaded = true
+		defer func() { c.countLoad(ok) }()
+		v, err = load(ctx, key)
+		ok = err == nil
+		return v, err
+	}, func(v V) {
+		if !loaded {
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.set(key, v, c.ttl)
+	})
+	return v, err
+}
+
+// countLoad counts a completed load, failed unless ok.
+func (c *Cache[K, V]) countLoad(ok bool) {
+	result := "ok"
+	c.loads.Add(1)
+	if !ok {
+		result = "error"
+		c.loadErrors.Add(1)
+	}
+	if c.m != nil {
+		c.m.loads.Inc(c.m.name, result)
+	}
+}
@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/recovery"
+)
+
+func TestTTL(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	c := New[string, int](WithTTL(time.Minute), WithClock(clk))
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, 0)
+	clk.Advance(59 * time.Second)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Get before expiry = %d, %v", v, ok)
+	}
+	clk.Advance(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error("entry outlived its TTL")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Error("entry without TTL expired")
+	}
+	s := c.Stats()
+	if s.Hits != 2 || s.Misses != 1 || s.Evictions != 1 || s.Entries != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestLRU(t *testing.T) {
+	c := New[int, int](WithMaxEntries(2))
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Get(1) // 2 is now the least recently used
+	c.Set(3, 3)
+	if _, ok := c.Get(2); ok {
+		t.Error("least recently used entry kept")
+	}
+	for _, k := range []int{1, 3} {
+		if _, ok := c.Get(k); !ok {
+			t.Errorf("entry %d evicted", k)
+		}
+	}
+	c.Set(1, 10) // replacing does not grow the cache
+	if c.Len() != 2 || c.Stats().Evictions != 1 {
+		t.Errorf("len %d, stats %+v", c.Len(), c.Stats())
+	}
+}
+
+func TestPruneAndPurge(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	c := New[int, int](WithTTL(time.Second), WithClock(clk))
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.SetWithTTL(3, 3, time.Hour)
+	clk.Advance(time.Second)
+	if n := c.Prune(); n != 2 || c.Len() != 1 {
+		t.Errorf("pruned %d, left %d", n, c.Len())
+	}
+	c.Purge()
+	if c.Len() != 0 {
+		t.Errorf("len %d after Purge", c.Len())
+	}
+}
+
+func TestGetOrLoadDeduplicates(t *testing.T) {
+	c := New[string, string]()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(_ context.Context, k string) (string, error) {
+		calls.Add(1)
+		<-release
+		return strings.ToUpper(k), nil
+	}
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = c.GetOrLoad(context.Background(), "k", load)
+		}()
+	}
+	for c.Stats().Misses < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Errorf("loaded %d times", calls.Load())
+	}
+	for _, r := range results {
+		if r != "K" {
+			t.Errorf("result %q", r)
+		}
+	}
+	if v, err := c.GetOrLoad(context.Background(), "k", load); v != "K" || err != nil || calls.Load() != 1 {
+		t.Errorf("cached GetOrLoad = %q, %v after %d loads", v, err, calls.Load())
+	}
+}
+
+func TestGetOrLoadErrors(t *testing.T) {
+	c := New[int, int]()
+	boom := errors.New("boom")
+	if _, err := c.GetOrLoad(context.Background(), 1, func(context.Context, int) (int, error) { return 0, boom }); !errors.Is(err, boom) {
+		t.Errorf("err = %v", err)
+	}
+	if _, ok := c.Get(1); ok {
+		t.Error("error cached")
+	}
+	_, err := c.GetOrLoad(context.Background(), 2, func(context.Context, int) (int, error) { panic("bad") })
+	if !errors.Is(err, recovery.ErrPanic) {
+		t.Errorf("panic err = %v", err)
+	}
+	if s := c.Stats(); s.Loads != 2 || s.LoadErrors != 2 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestGetOrLoadCancel(t *testing.T) {
+	c := New[int, int]()
+	release := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.GetOrLoad(ctx, 1, func(ctx context.Context, k int) (int, error) {
+		<-release
+		return k, ctx.Err() // the load outlives the caller
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v", err)
+	}
+	close(release)
+	v, err := c.GetOrLoad(context.Background(), 1, func(context.Context, int) (int, error) { return 2, nil })
+	if err != nil || (v != 1 && v != 2) {
+		t.Errorf("GetOrLoad = %d, %v", v, err)
+	}
+}
+
+func TestDeleteDuringLoad(t *testing.T) {
+	c := New[int, string]()
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan string)
+	go func() {
+		v, _ := c.GetOrLoad(context.Background(), 1, func(context.Context, int) (string, error) {
+			close(started)
+			<-release
+			return "stale", nil
+		})
+		done <- v
+	}()
+	<-started
+	c.Delete(1) // the row changed while it was read
+	close(release)
+	if v := <-done; v != "stale" {
+		t.Errorf("loader result %q", v)
+	}
+	if _, ok := c.Get(1); ok {
+		t.Error("load completed after Delete was cached")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	c := New[int, int](WithMetrics(reg, "users"), WithMaxEntries(1))
+	c.Set(1, 1)
+	c.Get(1)
+	c.Get(2)
+	c.GetOrLoad(context.Background(), 3, func(context.Context, int) (int, error) { return 3, nil })
+	want := map[string]float64{
+		MetricRequests + "{hit}":       1,
+		MetricRequests + "{miss}":      2,
+		MetricLoads + "{ok}":           1,
+		MetricEvictions + "{capacity}": 1,
+		MetricEntries + "{}":           1,
+	}
+	got := map[string]float64{}
+	for _, f := range reg.Collect() {
+		for _, s := range f.Series {
+			if s.LabelValues[0] != "users" {
+				t.Errorf("%s labeled %v", f.Name, s.LabelValues)
+			}
+			got[f.Name+"{"+strings.Join(s.LabelValues[1:], ",")+"}"] = s.Value
+		}
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+	if r := c.Stats().HitRatio(); r != 1.0/3 {
+		t.Errorf("hit ratio %v", r)
+	}
+}
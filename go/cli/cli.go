@@ -0,0 +1,393 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cli builds command-line tools from commands registered in the
+// hub, the Go counterpart of the Python register_command decorator.
+// Commands register from init functions under dotted names, which nest:
+//
+//	func init() {
+//		var steps int
+//		cli.MustRegister(hub.Default(), &cli.Command{
+//			Name:        "db.migrate.down",
+//			Description: "Roll back migrations",
+//			Flags:       func(fs *flag.FlagSet) { fs.IntVar(&steps, "steps", 1, "migrations to roll back") },
+//			Run: func(ctx context.Context, args []string) error {
+//				return rollback(ctx, steps)
+//			},
+//		})
+//	}
+//
+// Parents such as "db" and "db.migrate" need no registration; they are
+// groups listing their subcommands, and may be registered without Run
+// to give them a description. An App runs the tree:
+//
+//	func main() {
+//		(&cli.App{Name: "app", Version: version}).Main()
+//	}
+//
+// Help is generated for every command and group from the registrations
+// and flags, and is shown by -h, --help or "app help db migrate".
+// Commands print with Pout and Perr, which write JSON lines instead of
+// text when the App runs with --json, so every tool is scriptable, list
+// resources with Render, as a table, JSON or YAML chosen with --output
+// on commands registered with Lists, and ask with Confirm, Select,
+// MultiSelect, Input and Secret, which fall back to reading lines when
+// the input is not a terminal and take their defaults with --yes. Help
+// and errors are written in the locale of the context or process, with
+// the messages of package i18n.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/provide-io/provide-foundation/go/hub"
+	"github.com/provide-io/provide-foundation/go/i18n"
+)
+
+// Command is a registered command, or a group when Run is nil.
+type Command struct {
+	// Name is the dotted path of the command, such as "db.migrate".
+	Name string
+	// Description is one line, shown in the parent's listing and at the
+	// top of the command's help.
+	Description string
+	// Help is shown in the command's help after the description.
+	Help string
+	// Args describes the positional arguments in the usage line, such as
+	// "<file>...".
+	Args string
+	// Aliases are other names of the command within its parent.
+	Aliases []string
+	// Hidden commands run but are left out of listings.
+	Hidden bool
+	// Flags defines the command's flags on fs before its arguments are
+	// parsed.
+	Flags func(fs *flag.FlagSet)
+	// Options, if set, points to a struct that is filled before Run. Its
+	// fields are declared with the config tags (config, env, default,
+	// desc, validate), and each gets a flag named after its key, such as
+	// --http-timeout for "http.timeout", or by a flag tag; flag:"-"
+	// leaves a field to the environment and file. A field takes its value
+	// from the flag, then its environment variable, then App.ConfigFile,
+	// then its default. The variable is named by the env tag, or derived
+	// from App.EnvPrefix and the key, and is shown in the help.
+	Options any
+	// Output gives the command -o/--output, which chooses the format
+	// Output returns.
+	Output bool
+	// Lists gives the command --fields and --sort as well as --output,
+	// for commands writing lists with Render.
+	Lists bool
+	// Run executes the command with the arguments left after its flags.
+	Run func(ctx context.Context, args []string) error
+}
+
+// MetaHidden is the hub metadata key set on hidden commands.
+const MetaHidden = "hidden"
+
+// Register adds cmd to h in the hub.Command dimension. Its aliases are
+// registered qualified by its parent, so "st" for "container.status"
+// denotes "container.st".
+func Register(h *hub.Hub, cmd *Command) error {
+	if err := validName(cmd.Name); err != nil {
+		return err
+	}
+	parent, _ := split(cmd.Name)
+	opts := []hub.Option{hub.WithDescription(cmd.Description)}
+	for _, a := range cmd.Aliases {
+		if err := validName(a); err != nil || strings.Contains(a, ".") {
+			return fmt.Errorf("cli: command %q: invalid alias %q", cmd.Name, a)
+		}
+		opts = append(opts, hub.WithAliases(join(parent, a)))
+	}
+	if cmd.Hidden {
+		opts = append(opts, hub.WithMetadata(MetaHidden, true))
+	}
+	if cmd.Options != nil {
+		if _, _, err := optionFields(cmd.Options); err != nil {
+			return fmt.Errorf("cli: command %q: %w", cmd.Name, err)
+		}
+	}
+	return h.Register(hub.Command, cmd.Name, cmd, opts...)
+}
+
+// MustRegister is like Register but panics on error. It is intended for
+// init functions.
+func MustRegister(h *hub.Hub, cmd *Command) {
+	if err := Register(h, cmd); err != nil {
+		panic(err)
+	}
+}
+
+func validName(name string) error {
+	for _, seg := range strings.Split(name, ".") {
+		if seg == "" || strings.HasPrefix(seg, "-") || strings.ContainsAny(seg, " \t\n") {
+			return fmt.Errorf("cli: invalid command name %q", name)
+		}
+	}
+	return nil
+}
+
+// split returns the parent path and the last segment of name.
+func split(name string) (parent, last string) {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+func join(parent, seg string) string {
+	if parent == "" {
+		return seg
+	}
+	return parent + "." + seg
+}
+
+// App runs the commands registered in a hub.
+type App struct {
+	// Name is used in usage lines and messages. It defaults to the base
+	// name of os.Args[0].
+	Name string
+	// Version is printed by --version and the version command. It
+	// defaults to the version the build was stamped with, from the
+	// version package; without either, neither is offered.
+	Version string
+	// Description is shown at the top of the top-level help.
+	Description string
+	// EnvPrefix prefixes the environment variables of command Options.
+	// It defaults to the Name, upper-cased, so the "http.timeout" option
+	// of "my-app" reads MY_APP_HTTP_TIMEOUT.
+	EnvPrefix string
+	// ConfigFile, if set, is an optional YAML, TOML or JSON file that
+	// command Options are read from, below flags and the environment.
+	ConfigFile string
+	// JSON forces JSON mode, as if --json were always given.
+	JSON bool
+	// Hub holds the commands. It defaults to hub.Default().
+	Hub *hub.Hub
+	// Stdin, Stdout and Stderr default to os.Stdin, os.Stdout and
+	// os.Stderr.
+	Stdin          io.Reader
+	Stdout, Stderr io.Writer
+}
+
+// Main runs the command named by os.Args until SIGINT or SIGTERM,
+// closes the hub's components and exits with the command's exit code.
+func (a *App) Main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := a.Run(ctx, os.Args[1:])
+	stop()
+	if err := a.hub().Close(context.Background()); err != nil {
+		fmt.Fprintf(a.stderr(), "%s: %v\n", a.name(), err)
+	}
+	os.Exit(code)
+}
+
+// Run loads the hub's plugins, runs the command named by args and
+// returns its exit code. Help requested with -h, --help or the help
+// command is written to Stdout; errors are written to Stderr.
+//
+// The global flags are accepted anywhere before a "--": --json, or
+// PROVIDE_JSON_OUTPUT, switches Pout, Perr and the App's own errors to
+// JSON lines, --no-color, or PROVIDE_NO_COLOR, disables colors, and
+// --yes, or FOUNDATION_ASSUME_YES, answers prompts with their defaults.
+func (a *App) Run(ctx context.Context, args []string) int {
+	args, c := a.globals(args)
+	ctx = context.WithValue(ctx, consoleKey{}, c)
+	if err := a.hub().LoadPlugins(); err != nil {
+		Perr(ctx, fmt.Errorf("%s: %w", a.name(), err))
+		return ExitFailure
+	}
+
+	if len(args) > 0 && args[0] == "completion" && !a.exists("completion") {
+		return a.completion(ctx, args[1:])
+	}
+	if len(args) > 0 && args[0] == "version" && a.version() != "" && !a.exists("version") {
+		return a.versionCommand(ctx, args[1:])
+	}
+	if len(args) > 0 && args[0] == "help" && !a.exists("help") {
+		path, rest := a.resolve(args[1:])
+		if len(rest) > 0 {
+			return a.usageError(ctx, path, i18n.NewError("cli.unknown_command", "command", strconv.Quote(rest[0])))
+		}
+		a.help(ctx, a.stdout(), path)
+		return ExitOK
+	}
+
+	path, rest := a.resolve(args)
+	cmd, err := a.command(ctx, path)
+	if err != nil {
+		Perr(ctx, fmt.Errorf("%s: %w", a.name(), err))
+		return ExitFailure
+	}
+	if cmd == nil || cmd.Run == nil {
+		switch {
+		case len(rest) == 0:
+			a.help(ctx, a.stderr(), path)
+			return ExitUsage
+		case isHelp(rest[0]):
+			a.help(ctx, a.stdout(), path)
+			return ExitOK
+		case path == "" && rest[0] == "--version" && a.version() != "":
+			fmt.Fprintf(a.stdout(), "%s %s\n", a.name(), a.version())
+			return ExitOK
+		case strings.HasPrefix(rest[0], "-"):
+			return a.usageError(ctx, path, i18n.NewError("cli.unknown_flag", "flag", rest[0]))
+		}
+		return a.usageError(ctx, path, i18n.NewError("cli.unknown_command", "command", strconv.Quote(rest[0])))
+	}
+
+	fs := a.flagSet(cmd)
+	if cmd.Output || cmd.Lists {
+		c.defineListFlags(fs, cmd.Lists)
+	}
+	if err := fs.Parse(rest); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			a.help(ctx, a.stdout(), path)
+			return ExitOK
+		}
+		return a.usageError(ctx, path, err)
+	}
+	if cmd.Options != nil {
+		if err := a.bindOptions(cmd, fs); err != nil {
+			return a.fail(ctx, path, err)
+		}
+	}
+	return a.fail(ctx, path, cmd.Run(ctx, fs.Args()))
+}
+
+// fail reports err from the command at path and returns its exit code.
+func (a *App) fail(ctx context.Context, path string, err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var usage *UsageError
+	if errors.As(err, &usage) {
+		return a.usageError(ctx, path, err)
+	}
+	code := ExitCode(err)
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		code = ExitInterrupt
+	}
+	if err.Error() != "" {
+		Perr(ctx, fmt.Errorf("%s: %w", a.title(path), err))
+	}
+	return code
+}
+
+// resolve consumes the leading arguments that name a command or group
+// and returns its path and the remaining arguments.
+func (a *App) resolve(args []string) (path string, rest []string) {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			return path, args[i:]
+		}
+		next := join(path, arg)
+		if e, ok := a.hub().Entry(hub.Command, next); ok {
+			path = e.Name
+			continue
+		}
+		if !a.exists(next) {
+			return path, args[i:]
+		}
+		path = next
+	}
+	return path, nil
+}
+
+// exists reports whether path is a command or has subcommands.
+func (a *App) exists(path string) bool {
+	if _, ok := a.hub().Entry(hub.Command, path); ok {
+		return true
+	}
+	return len(a.hub().Find(func(e hub.Entry) bool {
+		return e.Dimension == hub.Command && strings.HasPrefix(e.Name, path+".")
+	})) > 0
+}
+
+// command returns the command registered at path, or nil for the root
+// and implicit groups.
+func (a *App) command(ctx context.Context, path string) (*Command, error) {
+	if _, ok := a.hub().Entry(hub.Command, path); !ok {
+		return nil, nil
+	}
+	return hub.Get[*Command](ctx, a.hub(), hub.Command, path)
+}
+
+func (a *App) flagSet(cmd *Command) *flag.FlagSet {
+	fs := flag.NewFlagSet(a.title(cmd.Name), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+	if cmd.Options != nil {
+		a.defineOptions(fs, cmd)
+	}
+	return fs
+}
+
+func (a *App) usageError(ctx context.Context, path string, err error) int {
+	Perr(ctx, fmt.Errorf("%s: %w", a.title(path), err))
+	if !JSONMode(ctx) {
+		Perr(ctx, i18n.T(ctx, "cli.run_help", "command", a.title(path)))
+	}
+	return ExitUsage
+}
+
+// title returns the command line that invokes path, such as "app db
+// migrate".
+func (a *App) title(path string) string {
+	if path == "" {
+		return a.name()
+	}
+	return a.name() + " " + strings.ReplaceAll(path, ".", " ")
+}
+
+func isHelp(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+func (a *App) hub() *hub.Hub {
+	if a.Hub != nil {
+		return a.Hub
+	}
+	return hub.Default()
+}
+
+func (a *App) name() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return filepath.Base(os.Args[0])
+}
+
+func (a *App) stdin() io.Reader {
+	if a.Stdin != nil {
+		return a.Stdin
+	}
+	return os.Stdin
+}
+
+func (a *App) stdout() io.Writer {
+	if a.Stdout != nil {
+		return a.Stdout
+	}
+	return os.Stdout
+}
+
+func (a *App) stderr() io.Writer {
+	if a.Stderr != nil {
+		return a.Stderr
+	}
+	return os.Stderr
+}
@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/hub"
+	"github.com/provide-io/provide-foundation/go/i18n"
+	"github.com/provide-io/provide-foundation/go/version"
+)
+
+func TestMain(m *testing.M) {
+	// Expected output is English whatever the locale of the developer.
+	i18n.SetLocale(i18n.DefaultLocale)
+	os.Exit(m.Run())
+}
+
+func testApp(t *testing.T) (*App, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	h := hub.New()
+	var steps int
+	var verbose bool
+	for _, cmd := range []*Command{
+		{
+			Name:        "db.migrate.down",
+			Description: "Roll back migrations",
+			Args:        "[target]",
+			Aliases:     []string{"rollback"},
+			Flags: func(fs *flag.FlagSet) {
+				fs.IntVar(&steps, "steps", 1, "migrations to roll back")
+				fs.BoolVar(&verbose, "v", false, "verbose output")
+			},
+			Run: func(ctx context.Context, args []string) error {
+				fmt.Fprintf(Stdout(ctx), "down steps=%d v=%t args=%v\n", steps, verbose, args)
+				return nil
+			},
+		},
+		{Name: "db.migrate.up", Description: "Apply migrations", Run: func(ctx context.Context, args []string) error {
+			if len(args) > 1 {
+				return Usagef("too many arguments")
+			}
+			return nil
+		}},
+		{Name: "db", Description: "Database commands"},
+		{Name: "fail", Run: func(ctx context.Context, args []string) error { return errors.New("boom") }},
+		{Name: "exit", Run: func(ctx context.Context, args []string) error { return Exit(3, nil) }},
+		{Name: "wait", Run: func(ctx context.Context, args []string) error { return ctx.Err() }},
+		{Name: "debug.dump", Hidden: true, Run: func(ctx context.Context, args []string) error { return nil }},
+	} {
+		if err := Register(h, cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	return &App{Name: "app", Version: "1.2.3", Hub: h, Stdout: &stdout, Stderr: &stderr}, &stdout, &stderr
+}
+
+func TestRun(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		ctx    context.Context
+		args   string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{nil, "db migrate down --steps 3 -v v42", ExitOK, "down steps=3 v=true args=[v42]", ""},
+		{nil, "db migrate rollback", ExitOK, "down steps=1 v=false args=[]", ""},
+		{nil, "db migrate down --nope", ExitUsage, "", "app db migrate down: flag provided but not defined: -nope\nRun 'app db migrate down --help'"},
+		{nil, "db migrate up a b", ExitUsage, "", "app db migrate up: too many arguments"},
+		{nil, "db migrate sideways", ExitUsage, "", `app db migrate: unknown command "sideways"`},
+		{nil, "db", ExitUsage, "", "Usage:\n  app db <command>"},
+		{nil, "fail", ExitFailure, "", "app fail: boom\n"},
+		{nil, "exit", 3, "", ""},
+		{canceled, "wait", ExitInterrupt, "", "app wait: context canceled"},
+		{nil, "debug dump", ExitOK, "", ""},
+		{nil, "--version", ExitOK, "app 1.2.3\n", ""},
+		{nil, "version", ExitOK, "app 1.2.3 (", ""},
+		{nil, "version extra", ExitUsage, "", "app version: too many arguments"},
+		{nil, "version --help", ExitOK, "Usage:\n  app version\n", ""},
+		{nil, "--verbose", ExitUsage, "", "app: unknown flag --verbose"},
+		{nil, "help db migrate down", ExitOK, "Usage:\n  app db migrate down [flags] [target]", ""},
+		{nil, "help db nope", ExitUsage, "", `app db: unknown command "nope"`},
+		{nil, "db migrate -h", ExitOK, "Commands:\n  down  Roll back migrations\n  up    Apply migrations", ""},
+	}
+	for _, tt := range tests {
+		app, stdout, stderr := testApp(t)
+		ctx := tt.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		code := app.Run(ctx, strings.Fields(tt.args))
+		if code != tt.code || !strings.Contains(stdout.String(), tt.stdout) || !strings.Contains(stderr.String(), tt.stderr) ||
+			(tt.stdout == "" && tt.code != ExitOK && stdout.Len() > 0) {
+			t.Errorf("%q = %d\nstdout: %s\nstderr: %s", tt.args, code, stdout, stderr)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	app, stdout, _ := testApp(t)
+	app.Description = "The app."
+	if code := app.Run(context.Background(), []string{"--help"}); code != ExitOK {
+		t.Fatalf("code = %d", code)
+	}
+	want := `The app.
+
+Usage:
+  app <command>
+
+Commands:
+  completion  Generate a shell completion script
+  db          Database commands
+  exit
+  fail
+  help        Show help for a command
+  version     Print the version
+  wait
+
+Flags:
+  -h, --help  show help
+  --version   print the version
+  --json      write output as JSON lines
+  --no-color  disable colored output
+  -y, --yes   answer prompts with their defaults
+
+Run 'app <command> --help' for more information on a command.
+`
+	if stdout.String() != want {
+		t.Errorf("root help =\n%s\nwant\n%s", stdout, want)
+	}
+
+	stdout.Reset()
+	app.Run(context.Background(), []string{"db", "migrate", "down", "--help"})
+	want = `Roll back migrations
+
+Usage:
+  app db migrate down [flags] [target]
+
+Aliases: rollback
+
+Flags:
+  -h, --help   show help
+  --json       write output as JSON lines
+  --no-color   disable colored output
+  -y, --yes    answer prompts with their defaults
+  --steps int  migrations to roll back (default 1)
+  --v          verbose output
+`
+	if stdout.String() != want {
+		t.Errorf("command help =\n%s\nwant\n%s", stdout, want)
+	}
+}
+
+func TestLocalized(t *testing.T) {
+	app, stdout, stderr := testApp(t)
+	ctx := i18n.WithLocale(context.Background(), "de_AT.UTF-8")
+	if code := app.Run(ctx, []string{"db", "nope"}); code != ExitUsage {
+		t.Errorf("code = %d", code)
+	}
+	if want := "app db: unbekannter Befehl \"nope\"\nHilfe mit 'app db --help'.\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+	app.Run(ctx, []string{"--help"})
+	for _, want := range []string{"Aufruf:\n", "\nBefehle:\n", "  -h, --help  Hilfe anzeigen\n"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("help lacks %q:\n%s", want, stdout)
+		}
+	}
+
+	// JSON output stays in English, for machines.
+	stderr.Reset()
+	app.Run(ctx, []string{"--json", "db", "nope"})
+	if !strings.Contains(stderr.String(), `unknown command \"nope\"`) {
+		t.Errorf("JSON stderr = %q", stderr)
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	app, stdout, _ := testApp(t)
+	if code := app.Run(context.Background(), []string{"version", "--json"}); code != ExitOK {
+		t.Fatalf("code = %d", code)
+	}
+	var info version.Info
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "1.2.3" || info.GoVersion != runtime.Version() {
+		t.Errorf("version --json = %+v", info)
+	}
+
+	// Test binaries are not stamped, so without a Version the App has
+	// neither --version nor the version command.
+	app, stdout, stderr := testApp(t)
+	app.Version = ""
+	if code := app.Run(context.Background(), []string{"version"}); code != ExitUsage || !strings.Contains(stderr.String(), `unknown command "version"`) {
+		t.Errorf("version without a version = %d, %s", code, stderr)
+	}
+	app.Run(context.Background(), []string{"--help"})
+	if strings.Contains(stdout.String(), "version") {
+		t.Errorf("help offers the version:\n%s", stdout)
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+	h := hub.New()
+	for _, cmd := range []*Command{
+		{Name: ""},
+		{Name: "db..migrate"},
+		{Name: "has space"},
+		{Name: "-flag"},
+		{Name: "db.status", Aliases: []string{"a.b"}},
+	} {
+		if err := Register(h, cmd); err == nil {
+			t.Errorf("Register(%q) succeeded", cmd.Name)
+		}
+	}
+	MustRegister(h, &Command{Name: "db.status", Aliases: []string{"st"}})
+	if err := Register(h, &Command{Name: "db.st"}); !errors.Is(err, hub.ErrExists) {
+		t.Errorf("alias clash = %v", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("x"), ExitFailure},
+		{fmt.Errorf("wrapped: %w", Exit(4, errors.New("x"))), 4},
+		{Usagef("bad"), ExitUsage},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Shells lists the shells the completion command generates scripts for.
+var Shells = []string{"bash", "zsh", "fish"}
+
+// builtins are the commands every App has unless commands of the same
+// name are registered; version only when the App has a version.
+var builtins = []child{
+	{name: "completion", description: "Generate a shell completion script"},
+	{name: "help", description: "Show help for a command"},
+	{name: "version", description: "Print the version"},
+}
+
+// completion runs the built-in completion command.
+func (a *App) completion(ctx context.Context, args []string) int {
+	if len(args) == 1 && isHelp(args[0]) {
+		a.completionHelp(a.stdout())
+		return ExitOK
+	}
+	if len(args) != 1 {
+		return a.usageError(ctx, "completion", fmt.Errorf("expected one of %s", strings.Join(Shells, ", ")))
+	}
+	if err := a.WriteCompletion(a.stdout(), args[0]); err != nil {
+		return a.usageError(ctx, "completion", err)
+	}
+	return ExitOK
+}
+
+func (a *App) completionHelp(w io.Writer) {
+	fmt.Fprintf(w, `Generate a shell completion script
+
+Usage:
+  %[1]s completion bash|zsh|fish
+
+Load the script in the current shell with
+  source <(%[1]s completion bash)
+  source <(%[1]s completion zsh)
+  %[1]s completion fish | source
+or save it where the shell loads completions from.
+`, a.name())
+}
+
+// WriteCompletion writes the completion script for shell, one of Shells,
+// covering every visible command, its aliases and its flags.
+func (a *App) WriteCompletion(w io.Writer, shell string) error {
+	nodes := a.completionTree()
+	var s string
+	switch shell {
+	case "bash":
+		s = a.bashCompletion(nodes)
+	case "zsh":
+		s = a.zshCompletion(nodes)
+	case "fish":
+		s = a.fishCompletion(nodes)
+	default:
+		return fmt.Errorf("unsupported shell %q", shell)
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+// compNode is a command or group in a completion script.
+type compNode struct {
+	path     string
+	children []child
+	flags    []flagDoc
+}
+
+// completionTree lists the visible commands and groups, parents first.
+func (a *App) completionTree() []compNode {
+	var nodes []compNode
+	var walk func(path string)
+	walk = func(path string) {
+		cmd, _ := a.command(context.Background(), path)
+		n := compNode{path: path, children: a.children(path), flags: a.flagDocs(context.Background(), path, cmd)}
+		if path == "completion" && cmd == nil {
+			for _, shell := range Shells {
+				n.children = append(n.children, child{name: shell})
+			}
+			nodes = append(nodes, n)
+			return
+		}
+		nodes = append(nodes, n)
+		for _, c := range n.children {
+			walk(join(path, c.name))
+		}
+	}
+	walk("")
+	return nodes
+}
+
+// transitions returns the shell case arms that move from a path to a
+// child path, covering the child's aliases.
+func transitions(nodes []compNode, arm func(patterns []string, target string) string) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		for _, c := range n.children {
+			var patterns []string
+			for _, word := range append([]string{c.name}, c.aliases...) {
+				patterns = append(patterns, n.path+"|"+word)
+			}
+			b.WriteString(arm(patterns, join(n.path, c.name)))
+		}
+	}
+	return b.String()
+}
+
+var nonIdent = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// funcName returns a shell function name for the App.
+func (a *App) funcName() string {
+	return "_" + nonIdent.ReplaceAllString(a.name(), "_") + "_complete"
+}
+
+// shellQuote quotes s for bash, zsh and fish, where ' ends a single
+// quoted string.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteAll(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = shellQuote(w)
+	}
+	return strings.Join(quoted, "|")
+}
+
+func (a *App) bashCompletion(nodes []compNode) string {
+	var b strings.Builder
+	fn := a.funcName()
+	fmt.Fprintf(&b, "# bash completion for %[1]s, generated by \"%[1]s completion bash\".\n", a.name())
+	fmt.Fprintf(&b, "# Load it with: source <(%s completion bash)\n", a.name())
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" path=\"\" word i commands=\"\" flags=\"\"\n")
+	b.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("        word=\"${COMP_WORDS[i]}\"\n")
+	b.WriteString("        case \"$path|$word\" in\n")
+	b.WriteString(transitions(nodes, func(patterns []string, target string) string {
+		return fmt.Sprintf("            %s) path=%s ;;\n", quoteAll(patterns), shellQuote(target))
+	}))
+	b.WriteString("        esac\n    done\n")
+	b.WriteString("    case \"$path\" in\n")
+	for _, n := range nodes {
+		var commands, flags []string
+		for _, c := range n.children {
+			commands = append(commands, c.name)
+		}
+		for _, f := range n.flags {
+			flags = append(flags, f.names...)
+		}
+		fmt.Fprintf(&b, "        %s) commands=%s flags=%s ;;\n",
+			shellQuote(n.path), shellQuote(strings.Join(commands, " ")), shellQuote(strings.Join(flags, " ")))
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n")
+	b.WriteString("        COMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n")
+	b.WriteString("    else\n")
+	b.WriteString("        COMPREPLY=($(compgen -W \"$commands\" -- \"$cur\"))\n")
+	b.WriteString("    fi\n}\n")
+	fmt.Fprintf(&b, "complete -o default -F %s %s\n", fn, shellQuote(a.name()))
+	return b.String()
+}
+
+// zshItem formats a _describe item, escaping colons in the name.
+func zshItem(name, description string) string {
+	name = strings.ReplaceAll(name, ":", `\:`)
+	if description == "" {
+		return shellQuote(name)
+	}
+	return shellQuote(name + ":" + description)
+}
+
+func (a *App) zshCompletion(nodes []compNode) string {
+	var b strings.Builder
+	fn := a.funcName()
+	fmt.Fprintf(&b, "#compdef %s\n", a.name())
+	fmt.Fprintf(&b, "# zsh completion for %[1]s, generated by \"%[1]s completion zsh\".\n", a.name())
+	fmt.Fprintf(&b, "# Load it with: source <(%s completion zsh)\n", a.name())
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local cmdpath=\"\" word\n")
+	b.WriteString("    local -a commands flags\n")
+	b.WriteString("    for word in \"${(@)words[2,CURRENT-1]}\"; do\n")
+	b.WriteString("        case \"$cmdpath|$word\" in\n")
+	b.WriteString(transitions(nodes, func(patterns []string, target string) string {
+		return fmt.Sprintf("            %s) cmdpath=%s ;;\n", quoteAll(patterns), shellQuote(target))
+	}))
+	b.WriteString("        esac\n    done\n")
+	b.WriteString("    case \"$cmdpath\" in\n")
+	for _, n := range nodes {
+		var commands, flags []string
+		for _, c := range n.children {
+			commands = append(commands, zshItem(c.name, c.description))
+		}
+		for _, f := range n.flags {
+			for _, name := range f.names {
+				flags = append(flags, zshItem(name, f.usage))
+			}
+		}
+		fmt.Fprintf(&b, "        %s)\n", shellQuote(n.path))
+		fmt.Fprintf(&b, "            commands=(%s)\n", strings.Join(commands, " "))
+		fmt.Fprintf(&b, "            flags=(%s)\n", strings.Join(flags, " "))
+		b.WriteString("            ;;\n")
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ \"$PREFIX\" == -* ]]; then\n")
+	b.WriteString("        _describe -t flags 'flag' flags\n")
+	b.WriteString("    elif (( ${#commands} )); then\n")
+	b.WriteString("        _describe -t commands 'command' commands\n")
+	b.WriteString("    else\n")
+	b.WriteString("        _files\n")
+	b.WriteString("    fi\n}\n")
+	fmt.Fprintf(&b, "compdef %s %s\n", fn, shellQuote(a.name()))
+	return b.String()
+}
+
+// fishQuote quotes s for fish, where backslashes escape inside single
+// quotes.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func (a *App) fishCompletion(nodes []compNode) string {
+	var b strings.Builder
+	fn := a.funcName()
+	name := fishQuote(a.name())
+	fmt.Fprintf(&b, "# fish completion for %[1]s, generated by \"%[1]s completion fish\".\n", a.name())
+	fmt.Fprintf(&b, "# Load it with: %s completion fish | source\n", a.name())
+	fmt.Fprintf(&b, "function %s_at\n", fn)
+	b.WriteString("    set -l path ''\n")
+	b.WriteString("    for word in (commandline -opc)[2..-1]\n")
+	b.WriteString("        switch \"$path|$word\"\n")
+	b.WriteString(transitions(nodes, func(patterns []string, target string) string {
+		quoted := make([]string, len(patterns))
+		for i, p := range patterns {
+			quoted[i] = fishQuote(p)
+		}
+		return fmt.Sprintf("            case %s\n                set path %s\n", strings.Join(quoted, " "), fishQuote(target))
+	}))
+	b.WriteString("        end\n    end\n")
+	b.WriteString("    test \"$path\" = \"$argv[1]\"\nend\n")
+	for _, n := range nodes {
+		cond := fishQuote(fn + "_at " + fishQuote(n.path))
+		if len(n.children) > 0 {
+			fmt.Fprintf(&b, "complete -c %s -n %s -f\n", name, cond)
+		}
+		for _, c := range n.children {
+			fmt.Fprintf(&b, "complete -c %s -n %s -a %s", name, cond, fishQuote(c.name))
+			if c.description != "" {
+				fmt.Fprintf(&b, " -d %s", fishQuote(c.description))
+			}
+			b.WriteString("\n")
+		}
+		for _, f := range n.flags {
+			fmt.Fprintf(&b, "complete -c %s -n %s", name, cond)
+			for _, flagName := range f.names {
+				if long, ok := strings.CutPrefix(flagName, "--"); ok {
+					fmt.Fprintf(&b, " -l %s", long)
+				} else {
+					fmt.Fprintf(&b, " -s %s", strings.TrimPrefix(flagName, "-"))
+				}
+			}
+			if f.arg != "" {
+				b.WriteString(" -r")
+			}
+			fmt.Fprintf(&b, " -d %s\n", fishQuote(f.usage))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range Shells {
+		app, stdout, stderr := testApp(t)
+		if code := app.Run(context.Background(), []string{"completion", shell}); code != ExitOK {
+			t.Fatalf("%s: code = %d: %s", shell, code, stderr)
+		}
+		script := stdout.String()
+		for _, want := range []string{"db.migrate|rollback", "steps", "completion", "Roll back migrations"} {
+			if shell == "bash" && want == "Roll back migrations" {
+				continue
+			}
+			if !strings.Contains(script, want) {
+				t.Errorf("%s script lacks %q", shell, want)
+			}
+		}
+		if strings.Contains(script, "dump") {
+			t.Errorf("%s script completes a hidden command", shell)
+		}
+	}
+
+	app, _, stderr := testApp(t)
+	if code := app.Run(context.Background(), []string{"completion", "tcsh"}); code != ExitUsage || !strings.Contains(stderr.String(), `unsupported shell "tcsh"`) {
+		t.Errorf("tcsh: code = %d: %s", code, stderr)
+	}
+}
+
+func TestBashCompletion(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	app, _, _ := testApp(t)
+	var script bytes.Buffer
+	if err := app.WriteCompletion(&script, "bash"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"app ", "completion db exit fail help version wait"},
+		{"app d", "db"},
+		{"app db migrate ", "down up"},
+		{"app db migrate rollback --s", "--steps"},
+		{"app --json db mi", "migrate"},
+		{"app completion ", "bash zsh fish"},
+		{"app db migrate down v1 ", ""},
+	}
+	for _, tt := range tests {
+		words := strings.Fields(tt.line)
+		if strings.HasSuffix(tt.line, " ") {
+			words = append(words, "")
+		}
+		var quoted []string
+		for _, w := range words {
+			quoted = append(quoted, shellQuote(w))
+		}
+		cmd := exec.Command(bash, "--norc", "-c", script.String()+
+			"COMP_WORDS=("+strings.Join(quoted, " ")+"); COMP_CWORD=$((${#COMP_WORDS[@]} - 1)); _app_complete; echo \"${COMPREPLY[*]}\"")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%q: %v: %s", tt.line, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != tt.want {
+			t.Errorf("%q completes %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
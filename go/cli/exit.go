@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exit codes returned by App.Run, matching the Python foundation.
+const (
+	ExitOK        = 0
+	ExitFailure   = 1
+	ExitUsage     = 2   // invalid flags, arguments or command names
+	ExitInterrupt = 130 // the command was canceled by SIGINT or SIGTERM
+)
+
+// ExitError makes a command exit with Code. Err, if not nil, is printed.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Exit returns an error that makes the command exit with code, printing
+// err unless it is nil.
+func Exit(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// UsageError reports invalid arguments. The command exits with ExitUsage
+// and a pointer to its help.
+type UsageError struct {
+	Msg string
+}
+
+// Usagef returns a UsageError with a formatted message.
+func Usagef(format string, args ...any) error {
+	return &UsageError{Msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *UsageError) Error() string { return e.Msg }
+
+// ExitCode returns the exit code for a command's error: 0 for nil, the
+// code of an ExitError, ExitUsage for a UsageError and ExitFailure
+// otherwise.
+func ExitCode(err error) int {
+	var exit *ExitError
+	var usage *UsageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &exit):
+		return exit.Code
+	case errors.As(err, &usage):
+		return ExitUsage
+	}
+	return ExitFailure
+}
@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/hub"
+	"github.com/provide-io/provide-foundation/go/i18n"
+)
+
+// help writes the help of the command or group at path.
+func (a *App) help(ctx context.Context, w io.Writer, path string) {
+	if path == "completion" && !a.exists(path) {
+		a.completionHelp(w)
+		return
+	}
+	if path == "version" && !a.exists(path) {
+		a.versionHelp(w)
+		return
+	}
+	cmd, _ := a.command(ctx, path)
+	title := a.title(path)
+	children := a.children(path)
+	runnable := cmd != nil && cmd.Run != nil
+
+	desc := a.Description
+	if cmd != nil {
+		desc = cmd.Description
+	}
+	if desc != "" {
+		fmt.Fprintf(w, "%s\n\n", desc)
+	}
+	fmt.Fprintln(w, i18n.T(ctx, "cli.help.usage"))
+	if runnable {
+		fmt.Fprintf(w, "  %s\n", strings.TrimSpace(title+" [flags] "+cmd.Args))
+	}
+	if len(children) > 0 || !runnable {
+		fmt.Fprintf(w, "  %s <command>\n", title)
+	}
+	if cmd != nil && cmd.Help != "" {
+		fmt.Fprintf(w, "\n%s\n", strings.TrimSpace(cmd.Help))
+	}
+	if cmd != nil && len(cmd.Aliases) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T(ctx, "cli.help.aliases", "aliases", strings.Join(cmd.Aliases, ", ")))
+	}
+
+	if len(children) > 0 {
+		rows := make([][2]string, len(children))
+		for i, c := range children {
+			rows[i] = [2]string{c.name, c.description}
+		}
+		fmt.Fprintf(w, "\n%s\n", i18n.T(ctx, "cli.help.commands"))
+		table(w, rows)
+	}
+	var flags [][2]string
+	for _, d := range a.flagDocs(ctx, path, cmd) {
+		flags = append(flags, [2]string{strings.TrimSpace(strings.Join(d.names, ", ") + " " + d.arg), d.usage})
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T(ctx, "cli.help.flags"))
+	table(w, flags)
+	if len(children) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T(ctx, "cli.help.more", "command", title))
+	}
+}
+
+// table writes indented rows with their second column aligned.
+func table(w io.Writer, rows [][2]string) {
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r[0]))
+	}
+	for _, r := range rows {
+		if r[1] == "" {
+			fmt.Fprintf(w, "  %s\n", r[0])
+			continue
+		}
+		fmt.Fprintf(w, "  %-*s  %s\n", width, r[0], r[1])
+	}
+}
+
+// flagDoc documents a flag for the help and completion scripts.
+type flagDoc struct {
+	names []string // with dashes, such as "-h" and "--help"
+	arg   string   // value placeholder, empty for boolean flags
+	usage string
+}
+
+// flagDocs lists the flags accepted by the command or group at path: the
+// global flags, then those of cmd.
+func (a *App) flagDocs(ctx context.Context, path string, cmd *Command) []flagDoc {
+	docs := []flagDoc{{names: []string{"-h", "--help"}, usage: i18n.T(ctx, "cli.flag.help")}}
+	if path == "" && a.version() != "" {
+		docs = append(docs, flagDoc{names: []string{"--version"}, usage: i18n.T(ctx, "cli.flag.version")})
+	}
+	docs = append(docs,
+		flagDoc{names: []string{"--json"}, usage: i18n.T(ctx, "cli.flag.json")},
+		flagDoc{names: []string{"--no-color"}, usage: i18n.T(ctx, "cli.flag.no_color")},
+		flagDoc{names: []string{"-y", "--yes"}, usage: i18n.T(ctx, "cli.flag.yes")},
+	)
+	if cmd != nil && (cmd.Output || cmd.Lists) {
+		docs = append(docs, flagDoc{names: []string{"-o", "--output"}, arg: "format", usage: i18n.T(ctx, "cli.flag.output")})
+	}
+	if cmd != nil && cmd.Lists {
+		docs = append(docs,
+			flagDoc{names: []string{"--fields"}, arg: "list", usage: i18n.T(ctx, "cli.flag.fields")},
+			flagDoc{names: []string{"--sort"}, arg: "field", usage: i18n.T(ctx, "cli.flag.sort")},
+		)
+	}
+	if cmd != nil && cmd.Run != nil {
+		a.flagSet(cmd).VisitAll(func(f *flag.Flag) {
+			docs = append(docs, flagDoc{names: []string{"--" + f.Name}, arg: flagArg(f), usage: flagUsage(f)})
+		})
+	}
+	return docs
+}
+
+func flagArg(f *flag.Flag) string {
+	if o, ok := f.Value.(*optionFlag); ok {
+		typ, list := strings.CutPrefix(o.field.Type, "list of ")
+		switch {
+		case typ == "bool" && !list:
+			return ""
+		case list:
+			return typ + ",..."
+		}
+		return typ
+	}
+	name, _ := flag.UnquoteUsage(f)
+	return name
+}
+
+func flagUsage(f *flag.Flag) string {
+	if o, ok := f.Value.(*optionFlag); ok {
+		usage := o.field.Description
+		switch {
+		case o.field.Required:
+			usage += " (required)"
+		case o.field.Default != "":
+			usage += fmt.Sprintf(" (default %s)", o.field.Default)
+		}
+		return strings.TrimSpace(usage + " [$" + o.env + "]")
+	}
+	_, usage := flag.UnquoteUsage(f)
+	switch f.DefValue {
+	case "", "0", "false", "[]":
+		return usage
+	}
+	return fmt.Sprintf("%s (default %s)", usage, f.DefValue)
+}
+
+type child struct {
+	name, description string
+	aliases           []string
+}
+
+// children returns the visible direct subcommands of path, sorted by
+// name, with the builtins at the top level. A group without a
+// registration of its own is visible when one of its descendants is.
+func (a *App) children(path string) []child {
+	prefix := ""
+	if path != "" {
+		prefix = path + "."
+	}
+	byName := map[string]*child{}
+	hidden := map[string]bool{}
+	for _, e := range a.hub().List(hub.Command) {
+		rest, ok := strings.CutPrefix(e.Name, prefix)
+		if !ok {
+			continue
+		}
+		seg, _, nested := strings.Cut(rest, ".")
+		if e.Metadata[MetaHidden] == true {
+			if !nested {
+				hidden[seg] = true
+			}
+			continue
+		}
+		c := byName[seg]
+		if c == nil {
+			c = &child{name: seg}
+			byName[seg] = c
+		}
+		if !nested {
+			c.description = e.Description
+			for _, alias := range e.Aliases {
+				_, last := split(alias)
+				c.aliases = append(c.aliases, last)
+			}
+		}
+	}
+	if path == "" {
+		for _, b := range builtins {
+			if b.name == "version" && a.version() == "" {
+				continue
+			}
+			if _, ok := byName[b.name]; !ok && !hidden[b.name] {
+				byName[b.name] = &b
+			}
+		}
+	}
+	var out []child
+	for seg, c := range byName {
+		if !hidden[seg] {
+			out = append(out, *c)
+		}
+	}
+	slices.SortFunc(out, func(x, y child) int { return strings.Compare(x.name, y.name) })
+	return out
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/config"
+)
+
+// optionFlag is the flag generated for a field of a command's Options.
+// It keeps the raw text, which config converts when binding, so flags
+// accept exactly what the environment and files accept.
+type optionFlag struct {
+	field config.FieldSchema
+	env   string
+	value string
+}
+
+func (f *optionFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+// Set records the flag. A repeated list flag appends, as the
+// comma-separated form would.
+func (f *optionFlag) Set(s string) error {
+	if f.value != "" && strings.HasPrefix(f.field.Type, "list of ") {
+		s = f.value + "," + s
+	}
+	f.value = s
+	return nil
+}
+
+func (f *optionFlag) IsBoolFlag() bool { return f.field.Type == "bool" }
+
+// optionFields describes the fields of opts, which must point to a
+// struct, with the flag name of each, "-" for fields without a flag.
+func optionFields(opts any) ([]config.FieldSchema, []string, error) {
+	t := reflect.TypeOf(opts)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("options must be a pointer to a struct, got %T", opts)
+	}
+	fields, err := config.Schema(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		name := flagTag(t.Elem(), f.Field)
+		if name == "" {
+			name = strings.NewReplacer(".", "-", "_", "-").Replace(f.Key)
+		}
+		names = append(names, name)
+	}
+	return fields, names, nil
+}
+
+// flagTag returns the flag tag of the field at the dotted Go path.
+func flagTag(t reflect.Type, path string) string {
+	var sf reflect.StructField
+	for _, name := range strings.Split(path, ".") {
+		sf, _ = t.FieldByName(name)
+		t = sf.Type
+	}
+	return sf.Tag.Get("flag")
+}
+
+// defineOptions adds a flag to fs for every field of cmd.Options not
+// tagged flag:"-".
+func (a *App) defineOptions(fs *flag.FlagSet, cmd *Command) {
+	fields, names, _ := optionFields(cmd.Options)
+	for i, f := range fields {
+		if names[i] == "-" {
+			continue
+		}
+		env := f.Env
+		if env == "" {
+			env = config.EnvName(a.envPrefix(), f.Key)
+		}
+		fs.Var(&optionFlag{field: f, env: env}, names[i], f.Description)
+	}
+}
+
+// bindOptions fills cmd.Options from the flags set in fs, the
+// environment, App.ConfigFile and the default tags, in that order of
+// precedence. Invalid values are reported as a UsageError.
+func (a *App) bindOptions(cmd *Command, fs *flag.FlagSet) error {
+	opts := []config.Option{config.WithEnvPrefix(a.envPrefix())}
+	if a.ConfigFile != "" {
+		opts = append(opts, config.WithOptionalFile(a.ConfigFile))
+	}
+	cfg, err := config.Load(opts...)
+	if err != nil {
+		return err
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if o, ok := f.Value.(*optionFlag); ok {
+			cfg.Set(o.field.Key, o.value)
+		}
+	})
+	reflect.ValueOf(cmd.Options).Elem().SetZero()
+	if err := cfg.Bind(cmd.Options); err != nil {
+		return &UsageError{Msg: err.Error()}
+	}
+	return nil
+}
+
+// envPrefix returns App.EnvPrefix, or the App name as an environment
+// variable prefix.
+func (a *App) envPrefix() string {
+	if a.EnvPrefix != "" {
+		return a.EnvPrefix
+	}
+	return config.EnvName("", a.name())
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/hub"
+)
+
+type serveOptions struct {
+	Addr    string   `default:":8080" desc:"listen address"`
+	Token   string   `env:"SERVE_TOKEN" validate:"required" desc:"API token"`
+	Verbose bool     `desc:"log requests"`
+	Hosts   []string `desc:"allowed hosts"`
+	HTTP    struct {
+		Timeout time.Duration `default:"30s" desc:"request timeout"`
+	}
+	Secret string `flag:"-"`
+}
+
+func optionsApp(t *testing.T) (*App, *serveOptions, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	h := hub.New()
+	opts := new(serveOptions)
+	MustRegister(h, &Command{Name: "serve", Description: "Serve the API", Options: opts, Run: func(context.Context, []string) error { return nil }})
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(file, []byte("addr: :9000\nhttp:\n  timeout: 5s\nsecret: s3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	return &App{Name: "my-app", ConfigFile: file, Hub: h, Stdout: &stdout, Stderr: &stderr}, opts, &stdout, &stderr
+}
+
+func TestOptionsPrecedence(t *testing.T) {
+	t.Setenv("SERVE_TOKEN", "tok")
+	t.Setenv("MY_APP_HTTP_TIMEOUT", "1m")
+	t.Setenv("MY_APP_ADDR", ":7000")
+	app, opts, _, stderr := optionsApp(t)
+
+	if code := app.Run(context.Background(), strings.Fields("serve --addr :6000 --verbose --hosts a --hosts b,c")); code != ExitOK {
+		t.Fatalf("code = %d: %s", code, stderr)
+	}
+	want := serveOptions{Addr: ":6000", Token: "tok", Verbose: true, Hosts: []string{"a", "b", "c"}, Secret: "s3"}
+	want.HTTP.Timeout = time.Minute
+	if !equalOptions(*opts, want) {
+		t.Errorf("options = %+v, want %+v", *opts, want)
+	}
+
+	os.Unsetenv("MY_APP_ADDR")
+	os.Unsetenv("MY_APP_HTTP_TIMEOUT")
+	if code := app.Run(context.Background(), []string{"serve"}); code != ExitOK {
+		t.Fatalf("code = %d: %s", code, stderr)
+	}
+	want = serveOptions{Addr: ":9000", Token: "tok", Secret: "s3"}
+	want.HTTP.Timeout = 5 * time.Second
+	if !equalOptions(*opts, want) {
+		t.Errorf("options from file = %+v, want %+v", *opts, want)
+	}
+}
+
+func equalOptions(a, b serveOptions) bool {
+	return a.Addr == b.Addr && a.Token == b.Token && a.Verbose == b.Verbose && strings.Join(a.Hosts, ",") == strings.Join(b.Hosts, ",") &&
+		a.HTTP.Timeout == b.HTTP.Timeout && a.Secret == b.Secret
+}
+
+func TestOptionsErrors(t *testing.T) {
+	app, _, _, stderr := optionsApp(t)
+	if code := app.Run(context.Background(), strings.Fields("serve --http-timeout soon")); code != ExitUsage {
+		t.Errorf("code = %d", code)
+	}
+	for _, want := range []string{"Token (token): required", "HTTP.Timeout (http.timeout)", "Run 'my-app serve --help'"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr %q lacks %q", stderr, want)
+		}
+	}
+	if err := Register(hub.New(), &Command{Name: "x", Options: serveOptions{}}); err == nil {
+		t.Error("Register accepted a non-pointer Options")
+	}
+}
+
+func TestOptionsHelp(t *testing.T) {
+	app, _, stdout, _ := optionsApp(t)
+	app.Run(context.Background(), []string{"serve", "-h"})
+	want := `Flags:
+  -h, --help               show help
+  --json                   write output as JSON lines
+  --no-color               disable colored output
+  -y, --yes                answer prompts with their defaults
+  --addr string            listen address (default :8080) [$MY_APP_ADDR]
+  --hosts string,...       allowed hosts [$MY_APP_HOSTS]
+  --http-timeout duration  request timeout (default 30s) [$MY_APP_HTTP_TIMEOUT]
+  --token string           API token (required) [$SERVE_TOKEN]
+  --verbose                log requests [$MY_APP_VERBOSE]
+`
+	if !strings.HasSuffix(stdout.String(), want) {
+		t.Errorf("help =\n%s\nwant suffix\n%s", stdout, want)
+	}
+}
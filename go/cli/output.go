@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/provide-io/provide-foundation/go/i18n"
+	"github.com/provide-io/provide-foundation/go/serde"
+	"github.com/provide-io/provide-foundation/go/term"
+)
+
+// Environment variables read by App.Run, shared with the Python CLI.
+const (
+	EnvJSONOutput = "PROVIDE_JSON_OUTPUT"
+	EnvNoColor    = "PROVIDE_NO_COLOR"
+)
+
+// EnvAssumeYes is read by App.Run as if --yes were given.
+const EnvAssumeYes = "FOUNDATION_ASSUME_YES"
+
+// Color is a terminal foreground color for Pout and Perr.
+type Color = term.Color
+
+// Colors, as ANSI codes.
+const (
+	Red     = term.Red
+	Green   = term.Green
+	Yellow  = term.Yellow
+	Blue    = term.Blue
+	Magenta = term.Magenta
+	Cyan    = term.Cyan
+	White   = term.White
+)
+
+// OutputOption formats one Pout or Perr message.
+type OutputOption func(*message)
+
+type message struct {
+	style     term.Style
+	noNewline bool
+	jsonKey   string
+	prefix    string
+}
+
+// WithColor colors the message when the stream is a terminal.
+func WithColor(c Color) OutputOption { return func(m *message) { m.style.Color = c } }
+
+// Bold renders the message in bold when the stream is a terminal.
+func Bold() OutputOption { return func(m *message) { m.style.Bold = true } }
+
+// Dim renders the message dimmed when the stream is a terminal.
+func Dim() OutputOption { return func(m *message) { m.style.Dim = true } }
+
+// NoNewline leaves the line open, for progress and prompts. It has no
+// effect in JSON mode.
+func NoNewline() OutputOption { return func(m *message) { m.noNewline = true } }
+
+// Prefix puts s and a space before the message in text mode.
+func Prefix(s string) OutputOption { return func(m *message) { m.prefix = s } }
+
+// JSONKey wraps the value as {key: value} in JSON mode.
+func JSONKey(key string) OutputOption { return func(m *message) { m.jsonKey = key } }
+
+// console is the console state of a running App.
+type console struct {
+	stdout, stderr     io.Writer
+	json               bool
+	colorOut, colorErr bool
+
+	output OutputFormat // --output of a command with Output or Lists
+	fields []string     // --fields of a command with Lists
+	sort   string       // --sort of a command with Lists
+
+	readMu      sync.Mutex // serializes prompt reads
+	stdin       *bufio.Reader
+	interactive bool // stdin is a terminal
+	yes         bool // --yes: prompts take their defaults
+	stdinFile   *os.File
+}
+
+type consoleKey struct{}
+
+func consoleFrom(ctx context.Context) *console {
+	if c, ok := ctx.Value(consoleKey{}).(*console); ok {
+		return c
+	}
+	return stdConsole
+}
+
+// stdConsole serves Pout, Perr and the prompts outside an App.
+var stdConsole = &console{
+	stdout:      os.Stdout,
+	stderr:      os.Stderr,
+	colorOut:    useColor(os.Stdout, false),
+	colorErr:    useColor(os.Stderr, false),
+	stdin:       bufio.NewReader(os.Stdin),
+	interactive: term.IsTerminal(os.Stdin),
+	stdinFile:   os.Stdin,
+}
+
+// Stdout returns the standard output of the App running the command in
+// ctx, or os.Stdout outside one. Commands write to it so tests can
+// capture their output.
+func Stdout(ctx context.Context) io.Writer { return consoleFrom(ctx).stdout }
+
+// Stderr is like Stdout for the standard error.
+func Stderr(ctx context.Context) io.Writer { return consoleFrom(ctx).stderr }
+
+// JSONMode reports whether the command in ctx was run with --json.
+func JSONMode(ctx context.Context) bool { return consoleFrom(ctx).json }
+
+// Pout writes v to the command's standard output, the counterpart of the
+// Python pout. In text mode v is printed as with fmt.Print and a newline.
+// In JSON mode each call writes one JSON line: strings become
+// {"message": v}, errors {"error": v}, and other values are encoded as
+// they are, so scripts read the same data the user sees.
+func Pout(ctx context.Context, v any, opts ...OutputOption) {
+	c := consoleFrom(ctx)
+	write(c.stdout, c.json, c.colorOut, v, opts)
+}
+
+// Perr is like Pout for the standard error, for diagnostics that must not
+// mix with a command's data. Outside JSON mode, errors are written in the
+// locale of ctx, see i18n.Localize.
+func Perr(ctx context.Context, v any, opts ...OutputOption) {
+	c := consoleFrom(ctx)
+	if err, ok := v.(error); ok && !c.json {
+		v = i18n.Localize(ctx, err)
+	}
+	write(c.stderr, c.json, c.colorErr, v, opts)
+}
+
+func write(w io.Writer, jsonMode, color bool, v any, opts []OutputOption) {
+	var m message
+	for _, opt := range opts {
+		opt(&m)
+	}
+	if jsonMode {
+		b, err := serde.Marshal(serde.JSON, jsonValue(v, m.jsonKey))
+		if err != nil {
+			b, _ = json.Marshal(map[string]string{"error": err.Error()})
+		}
+		w.Write(append(b, '\n'))
+		return
+	}
+	s := fmt.Sprint(v)
+	if m.prefix != "" {
+		s = m.prefix + " " + s
+	}
+	if color {
+		s = m.style.Render(s)
+	}
+	if !m.noNewline {
+		s += "\n"
+	}
+	io.WriteString(w, s)
+}
+
+func jsonValue(v any, key string) any {
+	if err, ok := v.(error); ok {
+		if key == "" {
+			return map[string]string{"error": err.Error()}
+		}
+		v = err.Error()
+	}
+	if key != "" {
+		return map[string]any{key: v}
+	}
+	if s, ok := v.(string); ok {
+		return map[string]string{"message": s}
+	}
+	return v
+}
+
+// useColor reports whether styles are written to w: as term.Detect
+// decides, and never with noColor unless colors are forced.
+func useColor(w io.Writer, noColor bool) bool {
+	if noColor && !term.Forced() {
+		return false
+	}
+	return term.Detect(w).Color
+}
+
+// globals removes the global flags --json, --no-color and --yes from
+// args, up to a "--", and returns the console state they and the
+// environment select.
+func (a *App) globals(args []string) ([]string, *console) {
+	jsonMode, _ := strconv.ParseBool(os.Getenv(EnvJSONOutput))
+	noColor, _ := strconv.ParseBool(os.Getenv(EnvNoColor))
+	yes, _ := strconv.ParseBool(os.Getenv(EnvAssumeYes))
+	jsonMode = jsonMode || a.JSON
+	rest := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		switch arg {
+		case "--json", "-json":
+			jsonMode = true
+		case "--no-color", "-no-color":
+			noColor = true
+		case "--yes", "-yes", "-y":
+			yes = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	stdin := a.stdin()
+	f, _ := stdin.(*os.File)
+	return rest, &console{
+		stdout:      a.stdout(),
+		stderr:      a.stderr(),
+		json:        jsonMode,
+		colorOut:    useColor(a.stdout(), noColor),
+		colorErr:    useColor(a.stderr(), noColor),
+		stdin:       bufio.NewReader(stdin),
+		interactive: term.IsTerminal(stdin),
+		yes:         yes,
+		stdinFile:   f,
+	}
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/hub"
+)
+
+func outputApp(t *testing.T) (*App, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	h := hub.New()
+	MustRegister(h, &Command{Name: "report", Run: func(ctx context.Context, args []string) error {
+		Pout(ctx, "3 items", WithColor(Green), Bold())
+		Pout(ctx, map[string]int{"count": 3})
+		Pout(ctx, []string{"a", "b"}, JSONKey("items"), Prefix("items:"))
+		Perr(ctx, "slow query", WithColor(Yellow))
+		if len(args) > 0 {
+			return errors.New(args[0])
+		}
+		return nil
+	}})
+	var stdout, stderr bytes.Buffer
+	return &App{Name: "app", Hub: h, Stdout: &stdout, Stderr: &stderr}, &stdout, &stderr
+}
+
+func TestOutputText(t *testing.T) {
+	app, stdout, stderr := outputApp(t)
+	if code := app.Run(context.Background(), []string{"report", "boom"}); code != ExitFailure {
+		t.Errorf("code = %d", code)
+	}
+	if want := "3 items\nmap[count:3]\nitems: [a b]\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if want := "slow query\napp report: boom\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+
+	t.Setenv("FORCE_COLOR", "1")
+	stdout.Reset()
+	app.Run(context.Background(), []string{"report"})
+	if want := "\x1b[1;32m3 items\x1b[0m\n"; !strings.HasPrefix(stdout.String(), want) {
+		t.Errorf("colored stdout = %q, want prefix %q", stdout, want)
+	}
+}
+
+func TestOutputJSON(t *testing.T) {
+	app, stdout, stderr := outputApp(t)
+	if code := app.Run(context.Background(), []string{"report", "--json", "boom"}); code != ExitFailure {
+		t.Errorf("code = %d", code)
+	}
+	if want := "{\"message\":\"3 items\"}\n{\"count\":3}\n{\"items\":[\"a\",\"b\"]}\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if want := "{\"message\":\"slow query\"}\n{\"error\":\"app report: boom\"}\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+
+	t.Setenv(EnvJSONOutput, "true")
+	stderr.Reset()
+	if code := app.Run(context.Background(), []string{"nope"}); code != ExitUsage {
+		t.Errorf("code = %d", code)
+	}
+	if want := "{\"error\":\"app: unknown command \\\"nope\\\"\"}\n"; stderr.String() != want {
+		t.Errorf("usage error = %q, want %q", stderr, want)
+	}
+}
+
+func TestGlobalFlagsStopAtDoubleDash(t *testing.T) {
+	app, _, _ := outputApp(t)
+	args, out := app.globals([]string{"--no-color", "report", "--", "--json"})
+	if strings.Join(args, " ") != "report -- --json" || out.json || out.colorOut {
+		t.Errorf("args = %q, output = %+v", args, out)
+	}
+}
@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNoInput is matched by errors.Is when a prompt needs an answer that
+// cannot be had: the input ended, or --yes was given for a prompt without
+// a default.
+var ErrNoInput = errors.New("cli: no input for prompt")
+
+// The prompts write their question to the command's standard error and
+// read the answer from its standard input, so they work in pipelines and
+// leave standard output to data. When the input is not a terminal, they
+// read one line without re-asking and fail on an invalid answer, and an
+// empty line or the end of the input takes the default.
+
+// Input asks for a line of text. An empty answer takes def. With --yes it
+// returns def without asking.
+func Input(ctx context.Context, prompt, def string) (string, error) {
+	if consoleFrom(ctx).yes {
+		return def, nil
+	}
+	label := prompt + ": "
+	if def != "" {
+		label = fmt.Sprintf("%s [%s]: ", prompt, def)
+	}
+	return ask(ctx, prompt, label, func(answer string) (string, error) {
+		return cmp.Or(answer, def), nil
+	})
+}
+
+// Confirm asks a yes or no question. An empty answer takes def. With
+// --yes it returns def without asking.
+func Confirm(ctx context.Context, question string, def bool) (bool, error) {
+	if consoleFrom(ctx).yes {
+		return def, nil
+	}
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+	return ask(ctx, question, question+" "+hint+" ", func(answer string) (bool, error) {
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		return false, errors.New("please answer y or n")
+	})
+}
+
+// Select asks for one of choices, by number or by name, and returns its
+// index. An empty answer takes the choice at def; a negative def means
+// there is no default. With --yes it returns def without asking.
+func Select(ctx context.Context, question string, choices []string, def int) (int, error) {
+	if consoleFrom(ctx).yes {
+		if def < 0 || def >= len(choices) {
+			return -1, fmt.Errorf("%w: %s: --yes needs a default", ErrNoInput, question)
+		}
+		return def, nil
+	}
+	listChoices(ctx, question, choices, []int{def})
+	label := "Choice"
+	if def >= 0 {
+		label += fmt.Sprintf(" [%d]", def+1)
+	}
+	return ask(ctx, question, label+": ", func(answer string) (int, error) {
+		if answer == "" {
+			if def < 0 || def >= len(choices) {
+				return -1, errors.New("please choose one")
+			}
+			return def, nil
+		}
+		return choiceIndex(choices, answer)
+	})
+}
+
+// MultiSelect asks for any of choices, as numbers or names separated by
+// commas or spaces, and returns their indexes in order. An empty answer
+// takes defaults and "none" selects nothing. With --yes it returns
+// defaults without asking.
+func MultiSelect(ctx context.Context, question string, choices []string, defaults []int) ([]int, error) {
+	if consoleFrom(ctx).yes {
+		return slices.Clone(defaults), nil
+	}
+	listChoices(ctx, question, choices, defaults)
+	label := "Choices (comma-separated"
+	if len(defaults) > 0 {
+		var nums []string
+		for _, d := range defaults {
+			nums = append(nums, strconv.Itoa(d+1))
+		}
+		label += ", default " + strings.Join(nums, ",")
+	}
+	return ask(ctx, question, label+"): ", func(answer string) ([]int, error) {
+		switch strings.ToLower(answer) {
+		case "":
+			return slices.Clone(defaults), nil
+		case "none":
+			return []int{}, nil
+		}
+		var out []int
+		for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+			i, err := choiceIndex(choices, field)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, i)
+		}
+		slices.Sort(out)
+		return slices.Compact(out), nil
+	})
+}
+
+// Secret asks for a password or token without echoing it when the input
+// is a terminal. Where the terminal cannot be switched, as on Windows,
+// the answer is echoed. --yes does not apply: a secret has no default.
+func Secret(ctx context.Context, prompt string) (string, error) {
+	c := consoleFrom(ctx)
+	if c.interactive && c.stdinFile != nil && setEcho(c.stdinFile, false) == nil {
+		defer func() {
+			setEcho(c.stdinFile, true)
+			io.WriteString(c.stderr, "\n")
+		}()
+	}
+	return ask(ctx, prompt, prompt+": ", func(answer string) (string, error) {
+		if answer == "" {
+			return "", errors.New("a value is required")
+		}
+		return answer, nil
+	})
+}
+
+// listChoices writes the question and numbered choices, marking those in
+// marked.
+func listChoices(ctx context.Context, question string, choices []string, marked []int) {
+	c := consoleFrom(ctx)
+	fmt.Fprintln(c.stderr, question)
+	for i, choice := range choices {
+		mark := " "
+		if slices.Contains(marked, i) {
+			mark = "*"
+		}
+		fmt.Fprintf(c.stderr, " %s %d) %s\n", mark, i+1, choice)
+	}
+}
+
+// choiceIndex resolves an answer given as a 1-based number or as a
+// choice, ignoring case.
+func choiceIndex(choices []string, answer string) (int, error) {
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n < 1 || n > len(choices) {
+			return -1, fmt.Errorf("%d is not between 1 and %d", n, len(choices))
+		}
+		return n - 1, nil
+	}
+	for i, choice := range choices {
+		if strings.EqualFold(choice, answer) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%q is not a choice", answer)
+}
+
+// ask writes label and reads answers to question until parse accepts
+// one. Input that is not a terminal gets a single try.
+func ask[T any](ctx context.Context, question, label string, parse func(answer string) (T, error)) (T, error) {
+	c := consoleFrom(ctx)
+	var zero T
+	for {
+		io.WriteString(c.stderr, label)
+		answer, err := c.readLine(ctx)
+		if !c.interactive {
+			io.WriteString(c.stderr, "\n")
+		}
+		switch {
+		case errors.Is(err, io.EOF):
+			v, perr := parse("")
+			if perr != nil {
+				return zero, fmt.Errorf("%w: %s", ErrNoInput, question)
+			}
+			return v, nil
+		case err != nil:
+			return zero, err
+		}
+		v, err := parse(strings.TrimSpace(answer))
+		if err == nil {
+			return v, nil
+		}
+		if !c.interactive {
+			return zero, fmt.Errorf("cli: %s: %w", question, err)
+		}
+		fmt.Fprintln(c.stderr, err)
+	}
+}
+
+// readLine reads a line from the input, or returns when ctx is done. A
+// read abandoned on cancellation finishes in the background and its line
+// is dropped.
+func (c *console) readLine(ctx context.Context) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		c.readMu.Lock()
+		defer c.readMu.Unlock()
+		line, err := c.stdin.ReadString('\n')
+		if errors.Is(err, io.EOF) && line != "" {
+			err = nil
+		}
+		ch <- result{strings.TrimRight(line, "\r\n"), err}
+	}()
+	select {
+	case r := <-ch:
+		return r.line, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
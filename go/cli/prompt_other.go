@@ -0,0 +1,16 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !unix
+
+package cli
+
+import (
+	"errors"
+	"os"
+)
+
+// setEcho cannot switch the terminal here, so Secret input is echoed.
+func setEcho(f *os.File, on bool) error {
+	return errors.New("cli: terminal echo cannot be switched")
+}
@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// promptContext returns a context whose prompts read input and write to
+// the returned buffer, as an App run with args would.
+func promptContext(input string, interactive bool, args ...string) (context.Context, *bytes.Buffer) {
+	var stderr bytes.Buffer
+	app := &App{Name: "app", Stdin: strings.NewReader(input), Stdout: &bytes.Buffer{}, Stderr: &stderr}
+	_, c := app.globals(args)
+	c.interactive = interactive
+	return context.WithValue(context.Background(), consoleKey{}, c), &stderr
+}
+
+func TestPrompts(t *testing.T) {
+	colors := []string{"red", "green", "blue"}
+	tests := []struct {
+		name        string
+		input       string
+		interactive bool
+		args        []string
+		run         func(ctx context.Context) (any, error)
+		want        string
+	}{
+		{"confirm yes", "y\n", false, nil, func(ctx context.Context) (any, error) { return Confirm(ctx, "Drop?", false) }, "true"},
+		{"confirm default", "\n", false, nil, func(ctx context.Context) (any, error) { return Confirm(ctx, "Drop?", true) }, "true"},
+		{"confirm eof", "", false, nil, func(ctx context.Context) (any, error) { return Confirm(ctx, "Drop?", false) }, "false"},
+		{"confirm invalid", "maybe\n", false, nil, func(ctx context.Context) (any, error) { return Confirm(ctx, "Drop?", false) }, "error"},
+		{"confirm reasks", "maybe\nno\n", true, nil, func(ctx context.Context) (any, error) { return Confirm(ctx, "Drop?", true) }, "false"},
+		{"confirm --yes", "", false, []string{"--yes"}, func(ctx context.Context) (any, error) { return Confirm(ctx, "Drop?", true) }, "true"},
+		{"confirm --yes default no", "", false, []string{"--yes"}, func(ctx context.Context) (any, error) { return Confirm(ctx, "Drop?", false) }, "false"},
+		{"input default", "\n", false, nil, func(ctx context.Context) (any, error) { return Input(ctx, "Name", "app") }, "app"},
+		{"select number", "2\n", false, nil, func(ctx context.Context) (any, error) { return Select(ctx, "Color?", colors, -1) }, "1"},
+		{"select name", "BLUE\n", false, nil, func(ctx context.Context) (any, error) { return Select(ctx, "Color?", colors, 0) }, "2"},
+		{"select out of range", "4\n", false, nil, func(ctx context.Context) (any, error) { return Select(ctx, "Color?", colors, 0) }, "error"},
+		{"select no default", "", false, nil, func(ctx context.Context) (any, error) { return Select(ctx, "Color?", colors, -1) }, "no input"},
+		{"select --yes", "", false, []string{"-y"}, func(ctx context.Context) (any, error) { return Select(ctx, "Color?", colors, 1) }, "1"},
+		{"select --yes no default", "", false, []string{"-y"}, func(ctx context.Context) (any, error) { return Select(ctx, "Color?", colors, -1) }, "no input"},
+		{"multi", "3, red 3\n", false, nil, func(ctx context.Context) (any, error) { return MultiSelect(ctx, "Colors?", colors, nil) }, "[0 2]"},
+		{"multi default", "\n", false, nil, func(ctx context.Context) (any, error) { return MultiSelect(ctx, "Colors?", colors, []int{1}) }, "[1]"},
+		{"multi none", "none\n", false, nil, func(ctx context.Context) (any, error) { return MultiSelect(ctx, "Colors?", colors, []int{1}) }, "[]"},
+		{"secret", "hunter2\n", false, nil, func(ctx context.Context) (any, error) { return Secret(ctx, "Password") }, "hunter2"},
+		{"secret eof", "", false, []string{"--yes"}, func(ctx context.Context) (any, error) { return Secret(ctx, "Password") }, "no input"},
+	}
+	for _, tt := range tests {
+		ctx, _ := promptContext(tt.input, tt.interactive, tt.args...)
+		v, err := tt.run(ctx)
+		got := fmt.Sprint(v)
+		switch {
+		case errors.Is(err, ErrNoInput):
+			got = "no input"
+		case err != nil:
+			got = "error"
+		}
+		if got != tt.want {
+			t.Errorf("%s = %s (%v), want %s", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestSelectWritesChoicesToStderr(t *testing.T) {
+	ctx, stderr := promptContext("1\n", false)
+	if _, err := Select(ctx, "Color?", []string{"red", "green"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Color?\n   1) red\n * 2) green\nChoice [2]: \n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr, want)
+	}
+}
+
+func TestPromptCanceled(t *testing.T) {
+	ctx, _ := promptContext("", true)
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	c := consoleFrom(ctx)
+	c.stdin.Reset(blockingReader{})
+	if _, err := Confirm(ctx, "Drop?", false); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+type blockingReader struct{}
+
+func (blockingReader) Read([]byte) (int, error) { select {} }
@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unix
+
+package cli
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setEcho switches the echo of the terminal f with stty.
+func setEcho(f *os.File, on bool) error {
+	arg := "-echo"
+	if on {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = f
+	return cmd.Run()
+}
@@ -0,0 +1,404 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/provide-io/provide-foundation/go/serde"
+	"github.com/provide-io/provide-foundation/go/term"
+)
+
+// OutputFormat is how Render writes a list, chosen with --output.
+type OutputFormat string
+
+// Output formats.
+const (
+	OutputTable OutputFormat = "table"
+	OutputJSON  OutputFormat = "json"
+	OutputYAML  OutputFormat = "yaml"
+)
+
+// parseOutput returns the format named name.
+func parseOutput(name string) (OutputFormat, error) {
+	switch f := OutputFormat(strings.ToLower(name)); f {
+	case OutputTable, OutputJSON, OutputYAML:
+		return f, nil
+	case "yml":
+		return OutputYAML, nil
+	}
+	return "", errors.New("want table, json or yaml")
+}
+
+// defineListFlags defines --output, and -o, on fs, and with lists
+// --fields and --sort, setting them on c.
+func (c *console) defineListFlags(fs *flag.FlagSet, lists bool) {
+	output := func(name string) error {
+		f, err := parseOutput(name)
+		c.output = f
+		return err
+	}
+	fs.Func("output", "", output)
+	fs.Func("o", "", output)
+	if lists {
+		fs.Func("fields", "", func(v string) error {
+			c.fields = strings.Split(v, ",")
+			return nil
+		})
+		fs.Func("sort", "", func(v string) error {
+			c.sort = v
+			return nil
+		})
+	}
+}
+
+// Output returns the format Render uses for the command in ctx: the one
+// given with --output, if the command takes it, else JSON in JSON mode,
+// else a table.
+func Output(ctx context.Context) OutputFormat {
+	c := consoleFrom(ctx)
+	switch {
+	case c.output != "":
+		return c.output
+	case c.json:
+		return OutputJSON
+	}
+	return OutputTable
+}
+
+// A RenderOption configures Render.
+type RenderOption func(*renderConfig)
+
+type renderConfig struct {
+	fields []string
+	sort   string
+}
+
+// DefaultFields selects the columns shown when --fields is not given, in
+// order. Without it every field is shown.
+func DefaultFields(names ...string) RenderOption {
+	return func(c *renderConfig) { c.fields = names }
+}
+
+// DefaultSort sorts the items by the field name when --sort is not given,
+// descending if name starts with "-". Without it the items keep their
+// order.
+func DefaultSort(name string) RenderOption {
+	return func(c *renderConfig) { c.sort = name }
+}
+
+// Render writes items, a slice of structs or of pointers to structs, to
+// the command's standard output in the format Output returns, so every
+// command lists its resources the same way:
+//
+//	return cli.Render(ctx, services, cli.DefaultFields("name", "status"), cli.DefaultSort("name"))
+//
+// Fields are named as in JSON, by their json tag or else their Go name,
+// and embedded structs are flattened. A table has a column per field,
+// headed by its upper-cased name and aligned with spaces. JSON is one
+// line holding an array and YAML a sequence, both of the items as they
+// encode when every field is shown, and of objects of the selected
+// fields otherwise.
+//
+// On a command registered with Lists, --fields, a comma-separated list,
+// selects the fields and their order, and --sort sorts the items by a
+// field, descending with a leading "-"; both override the options. An
+// unknown field is an error.
+func Render(ctx context.Context, items any, opts ...RenderOption) error {
+	var cfg renderConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	c := consoleFrom(ctx)
+	if c.fields != nil {
+		cfg.fields = c.fields
+	}
+	if c.sort != "" {
+		cfg.sort = c.sort
+	}
+
+	list := reflect.ValueOf(items)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return fmt.Errorf("cli: Render: %T is not a slice", items)
+	}
+	elem := list.Type().Elem()
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return fmt.Errorf("cli: Render: %T is not a slice of structs", items)
+	}
+	all := structFields(elem, nil)
+	cols := all
+	if len(cfg.fields) > 0 {
+		cols = make([]field, 0, len(cfg.fields))
+		for _, name := range cfg.fields {
+			f, err := lookupField(all, strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			cols = append(cols, f)
+		}
+	}
+
+	rows := make([]reflect.Value, list.Len())
+	for i := range rows {
+		rows[i] = list.Index(i)
+	}
+	if cfg.sort != "" {
+		name, desc := strings.CutPrefix(cfg.sort, "-")
+		f, err := lookupField(all, name)
+		if err != nil {
+			return err
+		}
+		slices.SortStableFunc(rows, func(a, b reflect.Value) int {
+			n := compareValues(f.value(a), f.value(b))
+			if desc {
+				return -n
+			}
+			return n
+		})
+	}
+
+	switch Output(ctx) {
+	case OutputJSON, OutputYAML:
+		var v any
+		if len(cfg.fields) == 0 {
+			sorted := make([]any, len(rows))
+			for i, r := range rows {
+				sorted[i] = r.Interface()
+			}
+			v = sorted
+		} else {
+			objs := make([]map[string]any, len(rows))
+			for i, r := range rows {
+				obj := make(map[string]any, len(cols))
+				for _, f := range cols {
+					if fv := f.value(r); fv.IsValid() {
+						obj[f.name] = fv.Interface()
+					} else {
+						obj[f.name] = nil
+					}
+				}
+				objs[i] = obj
+			}
+			v = objs
+		}
+		format := serde.JSON
+		if Output(ctx) == OutputYAML {
+			format = serde.YAML
+		}
+		data, err := serde.Marshal(format, v)
+		if err != nil {
+			return fmt.Errorf("cli: Render: %w", err)
+		}
+		if format == serde.JSON {
+			data = append(data, '\n')
+		}
+		_, err = c.stdout.Write(data)
+		return err
+	}
+
+	cells := make([][]string, 0, len(rows)+1)
+	header := make([]string, len(cols))
+	for i, f := range cols {
+		header[i] = strings.ToUpper(f.name)
+	}
+	cells = append(cells, header)
+	for _, r := range rows {
+		line := make([]string, len(cols))
+		for i, f := range cols {
+			line[i] = cell(f.value(r))
+		}
+		cells = append(cells, line)
+	}
+	return writeTable(c.stdout, cells, c.colorOut)
+}
+
+// field is a column of Render: a struct field reached through index.
+type field struct {
+	name  string
+	index []int
+}
+
+// value returns the field of row, a struct or a pointer to one, or the
+// zero Value if a nil pointer is in the way.
+func (f field) value(row reflect.Value) reflect.Value {
+	for _, i := range f.index {
+		for row.Kind() == reflect.Pointer {
+			if row.IsNil() {
+				return reflect.Value{}
+			}
+			row = row.Elem()
+		}
+		row = row.Field(i)
+	}
+	return row
+}
+
+// structFields lists the fields of t named as encoding/json names them,
+// with exported embedded structs without a name flattened.
+func structFields(t reflect.Type, index []int) []field {
+	var fields []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		idx := append(slices.Clip(index), i)
+		if sf.Anonymous && name == "" {
+			if !sf.IsExported() {
+				continue
+			}
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, structFields(ft, idx)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: idx})
+	}
+	return fields
+}
+
+// lookupField returns the field named name, in any case.
+func lookupField(fields []field, name string) (field, error) {
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	return field{}, fmt.Errorf("cli: unknown field %q, want one of %s", name, strings.Join(names, ", "))
+}
+
+// indirect follows pointers and interfaces to the value they hold, and
+// returns false for nil.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// cell formats v for a table: empty for nil, times in RFC 3339, and
+// anything else as with fmt.Print.
+func cell(v reflect.Value) string {
+	v, ok := indirect(v)
+	if !ok {
+		return ""
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	if v.CanInterface() {
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// compareValues orders field values for --sort: nil first, numbers by
+// value, times chronologically, false before true, and anything else by
+// its table cell.
+func compareValues(a, b reflect.Value) int {
+	a, aok := indirect(a)
+	b, bok := indirect(b)
+	switch {
+	case !aok || !bok:
+		return compareBool(aok, bok)
+	case a.Type() == timeType && b.Type() == timeType:
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	}
+	switch {
+	case a.CanInt() && b.CanInt():
+		return cmp.Compare(a.Int(), b.Int())
+	case a.CanUint() && b.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint())
+	case a.CanFloat() && b.CanFloat():
+		return cmp.Compare(a.Float(), b.Float())
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		return compareBool(a.Bool(), b.Bool())
+	}
+	return strings.Compare(cell(a), cell(b))
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+// writeTable writes cells with their columns aligned, the first row
+// bold when color is on.
+func writeTable(w io.Writer, cells [][]string, color bool) error {
+	var widths []int
+	for _, row := range cells {
+		for i, s := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(s))
+		}
+	}
+	var b strings.Builder
+	for r, row := range cells {
+		var line strings.Builder
+		for i, s := range row {
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(s)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(s)))
+			}
+		}
+		s := strings.TrimRight(line.String(), " ")
+		if r == 0 && color {
+			s = term.Style{Bold: true}.Render(s)
+		}
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
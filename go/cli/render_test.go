@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/hub"
+)
+
+type service struct {
+	Name     string    `json:"name"`
+	Replicas int       `json:"replicas"`
+	Started  time.Time `json:"started"`
+	Owner    *string   `json:"owner,omitempty"`
+	secret   string
+	Internal string `json:"-"`
+	Meta
+}
+
+type Meta struct {
+	Tier string `json:"tier"`
+}
+
+func renderApp(t *testing.T, opts ...RenderOption) (*App, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	owner := "ops"
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	services := []*service{
+		{Name: "api", Replicas: 3, Started: start, Owner: &owner, Meta: Meta{Tier: "web"}},
+		{Name: "worker", Replicas: 10, Started: start.Add(time.Hour), Meta: Meta{Tier: "batch"}},
+		{Name: "cache", Replicas: 1, Meta: Meta{Tier: "data"}},
+	}
+	h := hub.New()
+	MustRegister(h, &Command{Name: "list", Lists: true, Run: func(ctx context.Context, args []string) error {
+		return Render(ctx, services, opts...)
+	}})
+	MustRegister(h, &Command{Name: "show", Output: true, Run: func(ctx context.Context, args []string) error {
+		return Render(ctx, services[:1], opts...)
+	}})
+	var stdout, stderr bytes.Buffer
+	return &App{Name: "app", Hub: h, Stdout: &stdout, Stderr: &stderr}, &stdout, &stderr
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []RenderOption
+		args []string
+		want string
+	}{
+		{"table", nil, nil, `NAME    REPLICAS  STARTED               OWNER  TIER
+api     3         2026-10-16T09:00:00Z  ops    web
+worker  10        2026-10-16T10:00:00Z         batch
+cache   1                                      data
+`},
+		{"fields", nil, []string{"--fields", "tier,NAME"}, "TIER   NAME\nweb    api\nbatch  worker\ndata   cache\n"},
+		{"sort numbers", nil, []string{"--fields=name", "--sort", "-replicas"}, "NAME\nworker\napi\ncache\n"},
+		{"sort times", nil, []string{"--fields=name", "--sort=started"}, "NAME\ncache\napi\nworker\n"},
+		{"defaults", []RenderOption{DefaultFields("name", "replicas"), DefaultSort("name")}, nil, "NAME    REPLICAS\napi     3\ncache   1\nworker  10\n"},
+		{"flags override defaults", []RenderOption{DefaultFields("name", "replicas"), DefaultSort("name")}, []string{"--fields", "name", "--sort", "-name"}, "NAME\nworker\ncache\napi\n"},
+		{"json fields", nil, []string{"-o", "json", "--fields", "name,owner", "--sort", "name"},
+			`[{"name":"api","owner":"ops"},{"name":"cache","owner":null},{"name":"worker","owner":null}]` + "\n"},
+		{"json mode", nil, []string{"--json", "--fields", "name"}, `[{"name":"api"},{"name":"worker"},{"name":"cache"}]` + "\n"},
+		{"yaml", nil, []string{"--output=yaml", "--fields", "name,replicas"}, `- name: api
+  replicas: 3
+- name: worker
+  replicas: 10
+- name: cache
+  replicas: 1
+`},
+		{"output wins over json", nil, []string{"--json", "-o", "table", "--fields", "name"}, "NAME\napi\nworker\ncache\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, stdout, stderr := renderApp(t, tt.opts...)
+			if code := app.Run(context.Background(), append([]string{"list"}, tt.args...)); code != ExitOK {
+				t.Fatalf("code = %d, stderr = %s", code, stderr)
+			}
+			if stdout.String() != tt.want {
+				t.Errorf("stdout =\n%s\nwant\n%s", stdout, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderJSONEveryField(t *testing.T) {
+	app, stdout, _ := renderApp(t)
+	if code := app.Run(context.Background(), []string{"list", "-o", "json", "--sort", "tier"}); code != ExitOK {
+		t.Fatalf("code = %d", code)
+	}
+	want := `[{"name":"worker","replicas":10,"started":"2026-10-16T10:00:00Z","tier":"batch"},` +
+		`{"name":"cache","replicas":1,"started":"0001-01-01T00:00:00Z","tier":"data"},` +
+		`{"name":"api","replicas":3,"started":"2026-10-16T09:00:00Z","owner":"ops","tier":"web"}]` + "\n"
+	if stdout.String() != want {
+		t.Errorf("stdout =\n%s\nwant\n%s", stdout, want)
+	}
+}
+
+func TestRenderOutputOnly(t *testing.T) {
+	app, stdout, stderr := renderApp(t)
+	if code := app.Run(context.Background(), []string{"show", "--output", "yaml"}); code != ExitOK {
+		t.Fatalf("code = %d, stderr = %s", code, stderr)
+	}
+	if !strings.HasPrefix(stdout.String(), "- name: api\n") {
+		t.Errorf("stdout =\n%s", stdout)
+	}
+	app.Run(context.Background(), []string{"show", "--help"})
+	if help := stdout.String(); !strings.Contains(help, "-o, --output format") || strings.Contains(help, "--sort") {
+		t.Errorf("help =\n%s", help)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+		want string
+	}{
+		{[]string{"list", "--fields", "name,size"}, ExitFailure, `app list: cli: unknown field "size", want one of name, replicas, started, owner, tier`},
+		{[]string{"list", "--sort", "secret"}, ExitFailure, `unknown field "secret"`},
+		{[]string{"list", "-o", "xml"}, ExitUsage, `app list: invalid value "xml" for flag -o: want table, json or yaml`},
+		{[]string{"list", "--sort"}, ExitUsage, "app list: flag needs an argument: -sort"},
+		{[]string{"show", "--fields", "name"}, ExitUsage, "app show: flag provided but not defined: -fields"},
+	}
+	for _, tt := range tests {
+		app, _, stderr := renderApp(t)
+		if code := app.Run(context.Background(), tt.args); code != tt.code {
+			t.Errorf("%v: code = %d, want %d", tt.args, code, tt.code)
+		}
+		if !strings.Contains(stderr.String(), tt.want) {
+			t.Errorf("%v: stderr = %q, want %q", tt.args, stderr, tt.want)
+		}
+	}
+
+	if err := Render(context.Background(), []string{"a"}); err == nil || err.Error() != "cli: Render: []string is not a slice of structs" {
+		t.Errorf("Render([]string) = %v", err)
+	}
+}
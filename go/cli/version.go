@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/provide-io/provide-foundation/go/version"
+)
+
+// version returns the version of the App, or "" if it has none.
+func (a *App) version() string {
+	if a.Version != "" {
+		return a.Version
+	}
+	if info := version.Get(); info.Stamped() {
+		return info.Version
+	}
+	return ""
+}
+
+// versionCommand runs the built-in version command, which prints the
+// version with the commit, build time and Go version of the build.
+func (a *App) versionCommand(ctx context.Context, args []string) int {
+	if len(args) == 1 && isHelp(args[0]) {
+		a.versionHelp(a.stdout())
+		return ExitOK
+	}
+	if len(args) > 0 {
+		return a.usageError(ctx, "version", errors.New("too many arguments"))
+	}
+	info := version.Get()
+	info.Version = a.version()
+	if JSONMode(ctx) {
+		Pout(ctx, info)
+		return ExitOK
+	}
+	Pout(ctx, a.name()+" "+info.String())
+	return ExitOK
+}
+
+func (a *App) versionHelp(w io.Writer) {
+	fmt.Fprintf(w, `Print the version
+
+Usage:
+  %[1]s version
+
+The version is followed by the commit, build time and Go version of the
+build, as far as they are known. With --json they are written as one
+JSON object.
+`, a.name())
+}
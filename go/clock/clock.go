@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package clock abstracts the passage of time, so code that waits, expires
+// or refills can be tested without real sleeps. Production code takes a
+// Clock and defaults to Real; tests pass a Fake and move it forward:
+//
+//	clk := clock.NewFake(time.Unix(1000, 0))
+//	limiter := resilience.NewRateLimiter(2, 1, resilience.WithLimiterClock(clk))
+//	limiter.Allow()
+//	clk.Advance(500 * time.Millisecond) // one token refilled
+//
+// The retry policy, the rate limiter and the circuit breaker take a Clock.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and waits for it to pass.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that receives the time once d has passed.
+	After(d time.Duration) <-chan time.Time
+	// Sleep blocks until d has passed.
+	Sleep(d time.Duration)
+	// NewTimer returns a timer that fires once d has passed.
+	NewTimer(d time.Duration) Timer
+	// NewTicker returns a ticker that fires every d. It panics if d is not
+	// positive, like time.NewTicker.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a time.Timer of a Clock.
+type Timer interface {
+	// C returns the channel the timer fires on.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It reports whether it stopped
+	// the timer, as opposed to finding it fired or stopped already.
+	Stop() bool
+	// Reset makes the timer fire once d has passed from now. It reports
+	// whether the timer was active.
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a time.Ticker of a Clock.
+type Ticker interface {
+	// C returns the channel the ticker fires on.
+	C() <-chan time.Time
+	// Stop turns the ticker off.
+	Stop()
+	// Reset changes the period of the ticker to d.
+	Reset(d time.Duration)
+}
+
+// Real returns the Clock of the time package.
+func Real() Clock { return realClock{} }
+
+// Or returns c, or Real if c is nil, for types whose zero value has no
+// clock.
+func Or(c Clock) Clock {
+	if c == nil {
+		return realClock{}
+	}
+	return c
+}
+
+// Sleep waits on c for d to pass or ctx to be done, and returns ctx.Err()
+// in the latter case. It returns at once if d is not positive.
+func Sleep(ctx context.Context, c Clock, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := c.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C():
+		return nil
+	}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time   { return t.t.C }
+func (t realTicker) Stop()                 { t.t.Stop() }
+func (t realTicker) Reset(d time.Duration) { t.t.Reset(d) }
@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clock
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var epoch = time.Unix(1000, 0)
+
+func fired(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeTimer(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	f.Advance(999 * time.Millisecond)
+	if _, ok := fired(timer.C()); ok {
+		t.Fatal("timer fired early")
+	}
+	f.Advance(time.Hour)
+	if at, ok := fired(timer.C()); !ok || !at.Equal(epoch.Add(time.Second)) {
+		t.Errorf("fired = %v, %v; want the due time", at, ok)
+	}
+	if !f.Now().Equal(epoch.Add(time.Hour + 999*time.Millisecond)) {
+		t.Errorf("Now = %v", f.Now())
+	}
+	if timer.Stop() {
+		t.Error("Stop of a fired timer reported true")
+	}
+	if timer.Reset(time.Second) {
+		t.Error("Reset of a fired timer reported true")
+	}
+	if !timer.Stop() {
+		t.Error("Stop of an active timer reported false")
+	}
+	f.Advance(time.Hour)
+	if _, ok := fired(timer.C()); ok {
+		t.Error("stopped timer fired")
+	}
+	if _, ok := fired(f.After(0)); !ok {
+		t.Error("After(0) did not fire at once")
+	}
+}
+
+func TestFakeTickerFiresInOrder(t *testing.T) {
+	f := NewFake(epoch)
+	tick := f.NewTicker(time.Second)
+	defer tick.Stop()
+	var order []string
+	timer := f.NewTimer(1500 * time.Millisecond)
+	for range 3 {
+		f.Advance(time.Second)
+		if _, ok := fired(tick.C()); ok {
+			order = append(order, "tick")
+		}
+		if _, ok := fired(timer.C()); ok {
+			order = append(order, "timer")
+		}
+	}
+	if want := "tick tick timer tick"; strings.Join(order, " ") != want {
+		t.Errorf("order = %v, want %s", order, want)
+	}
+
+	// A slow receiver misses ticks rather than queueing them.
+	f.Advance(10 * time.Second)
+	if _, ok := fired(tick.C()); !ok {
+		t.Fatal("no tick")
+	}
+	if _, ok := fired(tick.C()); ok {
+		t.Error("ticks queued up")
+	}
+	tick.Reset(time.Minute)
+	f.Advance(59 * time.Second)
+	if _, ok := fired(tick.C()); ok {
+		t.Error("tick before the new period")
+	}
+}
+
+func TestFakeSleepAndBlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		f.Sleep(time.Minute)
+		close(done)
+	}()
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sleeper not woken")
+	}
+}
+
+func TestSleep(t *testing.T) {
+	f := NewFake(epoch)
+	errc := make(chan error, 1)
+	go func() { errc <- Sleep(context.Background(), f, time.Hour) }()
+	f.BlockUntil(1)
+	f.Advance(time.Hour)
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { errc <- Sleep(ctx, f, time.Hour) }()
+	f.BlockUntil(1)
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep = %v, want Canceled", err)
+	}
+	if err := Sleep(ctx, Real(), 0); err != nil {
+		t.Errorf("Sleep(0) = %v", err)
+	}
+}
+
+func TestReal(t *testing.T) {
+	c := Or(nil)
+	if time.Since(c.Now()) > time.Minute {
+		t.Error("Real().Now() is not the time")
+	}
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	tick := c.NewTicker(time.Millisecond)
+	<-tick.C()
+	tick.Stop()
+}
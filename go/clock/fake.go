@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to. Timers, tickers and
+// sleepers fire as Advance passes their deadlines, in deadline order. It
+// is safe for concurrent use, so a test can advance it while the code
+// under test waits in another goroutine; BlockUntil synchronizes the two.
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond // broadcast when waiters change
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// NewFake returns a fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	f := &Fake{now: start}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the time of the fake clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the clock forward by d, firing every timer and ticker due
+// on the way with the time it was due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	end := f.now.Add(d)
+	for {
+		next := f.next()
+		if next == nil || next.when.After(end) {
+			break
+		}
+		f.now = next.when
+		next.fire()
+	}
+	f.now = end
+}
+
+// BlockUntil blocks until at least n timers, tickers or sleepers are
+// waiting on the clock, so a test advances it only once the code under
+// test is waiting.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.changed.Wait()
+	}
+}
+
+// After returns a channel that receives the time once the clock has
+// advanced by d.
+func (f *Fake) After(d time.Duration) <-chan time.Time { return f.NewTimer(d).C() }
+
+// Sleep blocks until the clock has advanced by d.
+func (f *Fake) Sleep(d time.Duration) { <-f.After(d) }
+
+// NewTimer returns a timer that fires once the clock has advanced by d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{f: f, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// NewTicker returns a ticker that fires each time the clock advances by
+// d.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	t := &fakeTimer{f: f, c: make(chan time.Time, 1), period: d}
+	t.Reset(d)
+	return fakeTicker{t}
+}
+
+// next returns the waiter due first. Callers hold f.mu.
+func (f *Fake) next() *fakeTimer {
+	var next *fakeTimer
+	for _, t := range f.waiters {
+		if next == nil || t.when.Before(next.when) {
+			next = t
+		}
+	}
+	return next
+}
+
+func (f *Fake) add(t *fakeTimer) {
+	f.waiters = append(f.waiters, t)
+	f.changed.Broadcast()
+}
+
+// remove reports whether t was waiting. Callers hold f.mu.
+func (f *Fake) remove(t *fakeTimer) bool {
+	for i, w := range f.waiters {
+		if w == t {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer is the Timer and Ticker of a Fake; period is zero for timers.
+type fakeTimer struct {
+	f      *Fake
+	c      chan time.Time
+	when   time.Time
+	period time.Duration
+}
+
+// fire sends the time without blocking, dropping it if the last one was
+// not received, as time.Ticker does. Callers hold f.mu.
+func (t *fakeTimer) fire() {
+	select {
+	case t.c <- t.when:
+	default:
+	}
+	if t.period > 0 {
+		t.when = t.when.Add(t.period)
+		return
+	}
+	t.f.remove(t)
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	return t.f.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	active := t.f.remove(t)
+	if t.period > 0 {
+		t.period = d
+	}
+	t.when = t.f.now.Add(d)
+	if d <= 0 && t.period == 0 {
+		t.fire()
+		return active
+	}
+	t.f.add(t)
+	return active
+}
+
+// fakeTicker adapts a periodic fakeTimer to Ticker.
+type fakeTicker struct{ *fakeTimer }
+
+func (t fakeTicker) Stop()                 { t.fakeTimer.Stop() }
+func (t fakeTicker) Reset(d time.Duration) { t.fakeTimer.Reset(d) }
@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Command foundation holds the development tools of the foundation:
+//
+//	go run github.com/provide-io/provide-foundation/go/cmd/foundation help
+//
+// "foundation gen wire" generates the constructor calls behind container
+// registrations, usually from a go:generate directive next to them:
+//
+//	//go:generate go run github.com/provide-io/provide-foundation/go/cmd/foundation gen wire -func setup
+//
+// "foundation gen openapi" generates a typed client for an API from its
+// OpenAPI document:
+//
+//	//go:generate go run github.com/provide-io/provide-foundation/go/cmd/foundation gen openapi -spec openapi.yaml
+//
+// "foundation doctor" reports the build, platform, profile and telemetry
+// settings the foundation detects in the current environment.
+package main
+
+import (
+	"github.com/provide-io/provide-foundation/go/cli"
+	"github.com/provide-io/provide-foundation/go/diag"
+	"github.com/provide-io/provide-foundation/go/hub"
+	"github.com/provide-io/provide-foundation/go/telemetry"
+)
+
+func init() {
+	cli.MustRegister(hub.Default(), &cli.Command{
+		Name:        "gen",
+		Description: "Generate Go code",
+	})
+	cli.MustRegister(hub.Default(), diag.Command(diag.WithSettings("telemetry", telemetry.Current())))
+}
+
+func main() {
+	(&cli.App{Name: "foundation", Description: "Development tools of the provide foundation"}).Main()
+}
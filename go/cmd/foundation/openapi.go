@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/provide-io/provide-foundation/go/cli"
+	"github.com/provide-io/provide-foundation/go/httpx/openapigen"
+	"github.com/provide-io/provide-foundation/go/hub"
+)
+
+func init() {
+	var cfg openapigen.Config
+	var spec, out string
+	cli.MustRegister(hub.Default(), &cli.Command{
+		Name:        "gen.openapi",
+		Description: "Generate a typed API client from an OpenAPI document",
+		Help: `Reads an OpenAPI 3 document in YAML or JSON and writes a client with a
+method for each operation, sending its requests through an httpx.Client
+and so through its middleware. The package name defaults to the name of
+the output directory.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&spec, "spec", "", "OpenAPI document (required)")
+			fs.StringVar(&cfg.Package, "package", "", "package name of the client")
+			fs.StringVar(&cfg.Client, "client", openapigen.DefaultClient, "name of the client type")
+			fs.StringVar(&out, "out", "client_gen.go", "output file, or - for standard output")
+		},
+		Run: func(ctx context.Context, args []string) error {
+			if len(args) > 0 {
+				return cli.Usagef("unexpected arguments %q", args)
+			}
+			if spec == "" {
+				return cli.Usagef("-spec is required")
+			}
+			if cfg.Package == "" {
+				dir, err := filepath.Abs(filepath.Dir(out))
+				if err != nil {
+					return err
+				}
+				cfg.Package = filepath.Base(dir)
+			}
+			data, err := os.ReadFile(spec)
+			if err != nil {
+				return err
+			}
+			src, err := openapigen.Generate(data, cfg)
+			if err != nil {
+				return err
+			}
+			if out == "-" {
+				_, err := cli.Stdout(ctx).Write(src)
+				return err
+			}
+			return os.WriteFile(out, src, 0o644)
+		},
+	})
+}
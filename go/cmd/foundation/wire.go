@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/provide-io/provide-foundation/go/cli"
+	"github.com/provide-io/provide-foundation/go/container/wiregen"
+	"github.com/provide-io/provide-foundation/go/hub"
+)
+
+func init() {
+	var cfg wiregen.Config
+	var out string
+	cli.MustRegister(hub.Default(), &cli.Command{
+		Name:        "gen.wire",
+		Description: "Generate the constructor calls of container registrations",
+		Help: `Reads the Provide, MustProvide and Register calls of the function named
+by -func and writes a function calling the constructors in dependency
+order. Registered instances become its parameters; it returns the types
+no constructor depends on, or those named by -type. Output files are
+skipped when reading the package, so the command can be rerun.`,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&cfg.Dir, "dir", ".", "directory of the package")
+			fs.StringVar(&cfg.Func, "func", "", "function holding the registrations (required)")
+			fs.StringVar(&cfg.Name, "name", wiregen.DefaultName, "name of the generated function")
+			fs.Func("type", "type to return, such as *Server; repeatable", func(s string) error {
+				cfg.Roots = append(cfg.Roots, s)
+				return nil
+			})
+			fs.StringVar(&out, "out", "wire_gen.go", "output file in the package directory, or - for standard output")
+		},
+		Run: func(ctx context.Context, args []string) error {
+			if len(args) > 0 {
+				return cli.Usagef("unexpected arguments %q", args)
+			}
+			if cfg.Func == "" {
+				return cli.Usagef("-func is required")
+			}
+			src, err := wiregen.Generate(cfg)
+			if err != nil {
+				return err
+			}
+			if out == "-" {
+				_, err := cli.Stdout(ctx).Write(src)
+				return err
+			}
+			return os.WriteFile(filepath.Join(cfg.Dir, out), src, 0o644)
+		},
+	})
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Command sqlcheck reports SQL built by string interpolation, in the
+// format of go vet:
+//
+//	go run github.com/provide-io/provide-foundation/go/cmd/sqlcheck ./...
+//
+// Arguments are directories, checked recursively; a trailing /... is
+// accepted. It exits with status 1 when it finds anything.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/db/sqlcheck"
+)
+
+func main() {
+	dirs := os.Args[1:]
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	found := false
+	for _, dir := range dirs {
+		dir = strings.TrimSuffix(strings.TrimSuffix(dir, "..."), "/")
+		if dir == "" {
+			dir = "."
+		}
+		findings, err := sqlcheck.CheckDir(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sqlcheck: %v\n", err)
+			os.Exit(2)
+		}
+		for _, f := range findings {
+			fmt.Fprintln(os.Stderr, f)
+			found = true
+		}
+	}
+	if found {
+		os.Exit(1)
+	}
+}
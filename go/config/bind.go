@@ -0,0 +1,398 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FieldError reports a struct field that could not be bound or failed
+// validation.
+type FieldError struct {
+	Field string // Go field path, e.g. "HTTP.Timeout"
+	Key   string // configuration key, e.g. "http.timeout"
+	Rule  string // failed validate rule, empty for conversion errors
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	if e.Rule != "" {
+		return fmt.Sprintf("config: %s (%s): %s: %v", e.Field, e.Key, e.Rule, e.Err)
+	}
+	return fmt.Sprintf("config: %s (%s): %v", e.Field, e.Key, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// Bind fills the struct pointed to by dst from struct tags and the
+// environment alone; see Config.Bind.
+func Bind(dst any) error {
+	c := &Config{opts: options{lookupEnv: os.LookupEnv}, values: map[string]value{}, runtime: map[string]any{}}
+	return c.Bind(dst)
+}
+
+// Bind fills the struct pointed to by dst. Each exported field is bound
+// to a key, taken from its config tag or derived from the field name in
+// snake case; nested structs add a dotted section, and config:"-" skips a
+// field:
+//
+//	type Settings struct {
+//		Database struct {
+//			URL string `env:"DATABASE_URL" default:"postgresql://localhost/myapp" validate:"required"`
+//		}
+//		Timeout time.Duration `config:"http.timeout" env:"HTTP_TIMEOUT" default:"30s" validate:"min=1s,max=5m"`
+//	}
+//
+// A field takes the first value found in: the variable named by its env
+// tag, the loaded configuration, and its default tag. With WithEnvPrefix,
+// a field without an env tag reads the variable EnvName gives for its
+// key. Runtime values set with Set still win over the environment.
+//
+// The validate tag holds comma-separated rules: required, min=, max= and
+// oneof= with space-separated choices. For strings and slices min and max
+// bound the length.
+//
+// A desc tag documents the field for Schema.
+//
+// Every field is checked, and all conversion and validation failures are
+// returned together as FieldErrors, so a misconfigured service fails at
+// startup with the full list.
+func (c *Config) Bind(dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: Bind needs a pointer to a struct, got %T", dst)
+	}
+	specs, err := structFields(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, f := range specs {
+		if err := c.bindField(rv.Elem().FieldByIndex(f.index), f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) bindField(fv reflect.Value, f fieldSpec) error {
+	raw, found := any(nil), false
+	if f.def != "" {
+		raw, found = f.def, true
+	}
+	v, err := c.lookup(f.key)
+	if err == nil {
+		raw, found = v.raw, true
+	}
+	env := f.env
+	if env == "" && c.opts.envPrefix != nil {
+		env = EnvName(*c.opts.envPrefix, f.key)
+	}
+	if env != "" && (err != nil || v.source < SourceRuntime) {
+		if s, ok := c.opts.lookupEnv(env); ok {
+			raw, found = s, true
+		}
+	}
+	if found {
+		raw, err := c.opts.resolveSecret(raw)
+		if err != nil {
+			return &FieldError{Field: f.field, Key: f.key, Err: err}
+		}
+		if err := assign(fv, raw); err != nil {
+			return &FieldError{Field: f.field, Key: f.key, Err: err}
+		}
+	}
+	var errs []error
+	for _, rule := range f.rules {
+		if err := checkRule(fv, rule); err != nil {
+			errs = append(errs, &FieldError{Field: f.field, Key: f.key, Rule: rule, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fieldSpec describes one bindable struct field.
+type fieldSpec struct {
+	index []int
+	field string
+	key   string
+	env   string
+	def   string
+	rules []string
+	desc  string
+	typ   reflect.Type
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// structFields lists the bindable fields of t, descending into nested
+// structs that are not themselves values.
+func structFields(t reflect.Type) ([]fieldSpec, error) {
+	var specs []fieldSpec
+	var walk func(t reflect.Type, index []int, field, key string) error
+	walk = func(t reflect.Type, index []int, field, key string) error {
+		for i := range t.NumField() {
+			sf := t.Field(i)
+			if !sf.IsExported() || sf.Tag.Get("config") == "-" {
+				continue
+			}
+			name := sf.Tag.Get("config")
+			if name == "" {
+				name = snakeCase(sf.Name)
+			}
+			if key != "" {
+				name = key + "." + name
+			}
+			path := sf.Name
+			if field != "" {
+				path = field + "." + sf.Name
+			}
+			idx := append(append([]int(nil), index...), i)
+			if isSection(sf.Type) {
+				if err := walk(sf.Type, idx, path, name); err != nil {
+					return err
+				}
+				continue
+			}
+			if !bindable(sf.Type) {
+				return fmt.Errorf("config: field %s has unsupported type %s", path, sf.Type)
+			}
+			var rules []string
+			if tag := sf.Tag.Get("validate"); tag != "" {
+				rules = strings.Split(tag, ",")
+			}
+			specs = append(specs, fieldSpec{
+				index: idx,
+				field: path,
+				key:   name,
+				env:   sf.Tag.Get("env"),
+				def:   sf.Tag.Get("default"),
+				rules: rules,
+				desc:  sf.Tag.Get("desc"),
+				typ:   sf.Type,
+			})
+		}
+		return nil
+	}
+	return specs, walk(t, nil, "", "")
+}
+
+// isSection reports whether a struct field groups nested settings rather
+// than holding a single value.
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func bindable(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Slice && bindable(t.Elem())
+	}
+	return false
+}
+
+// assign converts raw into fv's type and stores it.
+func assign(fv reflect.Value, raw any) error {
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(fmt.Sprint(raw)))
+	}
+	if fv.Type() == durationType {
+		d, err := toDuration(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(fmt.Sprint(raw))
+	case reflect.Bool:
+		b, err := toBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt(raw)
+		if err != nil {
+			return err
+		}
+		if fv.OverflowInt(int64(n)) {
+			return fmt.Errorf("%d overflows %s", n, fv.Type())
+		}
+		fv.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toInt(raw)
+		if err != nil {
+			return err
+		}
+		if n < 0 || fv.OverflowUint(uint64(n)) {
+			return fmt.Errorf("%d overflows %s", n, fv.Type())
+		}
+		fv.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		items, err := toList(raw)
+		if err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(fv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assign(s.Index(i), item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		fv.Set(s)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+// toList splits comma-separated strings, as used in environment values,
+// and accepts lists decoded from files.
+func toList(raw any) ([]any, error) {
+	switch v := raw.(type) {
+	case []any:
+		return v, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		var items []any
+		for _, s := range strings.Split(v, ",") {
+			items = append(items, strings.TrimSpace(s))
+		}
+		return items, nil
+	}
+	rv := reflect.ValueOf(raw)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cannot use %T as a list", raw)
+	}
+	items := make([]any, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// checkRule applies one validate rule to a bound field.
+func checkRule(fv reflect.Value, rule string) error {
+	name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+	switch name {
+	case "required":
+		if fv.IsZero() {
+			return errors.New("value is required")
+		}
+		return nil
+	case "min", "max":
+		cmp, err := compare(fv, arg)
+		if err != nil {
+			return err
+		}
+		if name == "min" && cmp < 0 {
+			return fmt.Errorf("%s is below the minimum %s", describe(fv), arg)
+		}
+		if name == "max" && cmp > 0 {
+			return fmt.Errorf("%s is above the maximum %s", describe(fv), arg)
+		}
+		return nil
+	case "oneof":
+		got := fmt.Sprint(fv.Interface())
+		for _, choice := range strings.Fields(arg) {
+			if got == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %s", got, strings.Join(strings.Fields(arg), ", "))
+	}
+	return fmt.Errorf("unknown rule %q", rule)
+}
+
+// compare returns the sign of fv minus the bound. Strings and slices are
+// compared by length.
+func compare(fv reflect.Value, bound string) (int, error) {
+	switch fv.Kind() {
+	case reflect.String, reflect.Slice:
+		n, err := strconv.Atoi(bound)
+		if err != nil {
+			return 0, fmt.Errorf("invalid length bound %q", bound)
+		}
+		return sign(float64(fv.Len() - n)), nil
+	}
+	b := reflect.New(fv.Type()).Elem()
+	if err := assign(b, bound); err != nil {
+		return 0, fmt.Errorf("invalid bound %q: %w", bound, err)
+	}
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return sign(float64(fv.Int() - b.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return sign(float64(fv.Uint()) - float64(b.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return sign(fv.Float() - b.Float()), nil
+	}
+	return 0, fmt.Errorf("min and max do not apply to %s", fv.Type())
+}
+
+func sign(f float64) int {
+	switch {
+	case f < 0:
+		return -1
+	case f > 0:
+		return 1
+	}
+	return 0
+}
+
+func describe(fv reflect.Value) string {
+	switch fv.Kind() {
+	case reflect.String, reflect.Slice:
+		return fmt.Sprintf("length %d", fv.Len())
+	}
+	return fmt.Sprint(fv.Interface())
+}
+
+// snakeCase converts a Go field name to a key segment: "PoolSize" is
+// "pool_size" and "HTTPTimeout" is "http_timeout".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type settings struct {
+	Database struct {
+		URL      string `env:"DATABASE_URL" default:"postgresql://localhost/myapp" validate:"required"`
+		PoolSize int    `default:"4" validate:"min=1,max=64"`
+	}
+	HTTP struct {
+		BaseURL string        `config:"base_url" default:"https://api.example.com"`
+		Timeout time.Duration `env:"HTTP_TIMEOUT" default:"30s" validate:"min=1s"`
+		Hosts   []string
+	}
+	LogLevel string     `env:"LOG_LEVEL" default:"INFO" validate:"oneof=DEBUG INFO WARNING ERROR"`
+	Bind     netip.Addr `default:"127.0.0.1"`
+	internal string
+	Ignored  string `config:"-" default:"x"`
+}
+
+func TestBindLayers(t *testing.T) {
+	t.Setenv("HTTP_TIMEOUT", "45s")
+	cfg, err := Load(
+		WithDefaults(map[string]any{"database.pool_size": 8}),
+		WithFile(writeFile(t, "app.yaml", "database:\n  url: postgresql://db/myapp\nhttp:\n  hosts: [a, b]\n  timeout: 10\n")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s settings
+	if err := cfg.Bind(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Database.URL != "postgresql://db/myapp" || s.Database.PoolSize != 8 {
+		t.Errorf("database = %+v", s.Database)
+	}
+	if s.HTTP.Timeout != 45*time.Second || s.HTTP.BaseURL != "https://api.example.com" {
+		t.Errorf("http = %+v", s.HTTP)
+	}
+	if !reflect.DeepEqual(s.HTTP.Hosts, []string{"a", "b"}) {
+		t.Errorf("hosts = %v", s.HTTP.Hosts)
+	}
+	if s.LogLevel != "INFO" || s.Bind != netip.MustParseAddr("127.0.0.1") || s.Ignored != "" {
+		t.Errorf("settings = %+v", s)
+	}
+
+	cfg.Set("http.timeout", "2s")
+	if err := cfg.Bind(&s); err != nil || s.HTTP.Timeout != 2*time.Second {
+		t.Errorf("runtime value not preferred over env tag: %v, %v", s.HTTP.Timeout, err)
+	}
+}
+
+func TestBindEnvPrefix(t *testing.T) {
+	t.Setenv("APP_DATABASE_POOL_SIZE", "16")
+	t.Setenv("APP_HTTP_TIMEOUT", "1m")
+	t.Setenv("HTTP_TIMEOUT", "45s")
+	cfg, err := Load(WithEnvPrefix("APP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s settings
+	if err := cfg.Bind(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Database.PoolSize != 16 || s.HTTP.Timeout != 45*time.Second {
+		t.Errorf("settings = %+v", s)
+	}
+	cfg.Set("database.pool_size", 2)
+	if err := cfg.Bind(&s); err != nil || s.Database.PoolSize != 2 {
+		t.Errorf("runtime value not preferred over prefixed env: %d, %v", s.Database.PoolSize, err)
+	}
+}
+
+func TestBindAggregatesErrors(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("HTTP_TIMEOUT", "10ms")
+	t.Setenv("LOG_LEVEL", "LOUD")
+	var s settings
+	err := Bind(&s)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var fe *FieldError
+		if !errors.As(e, &fe) {
+			t.Fatalf("unexpected error %T: %v", e, e)
+		}
+		fields = append(fields, fe.Field+":"+fe.Rule)
+	}
+	want := []string{"Database.URL:required", "HTTP.Timeout:min=1s", "LogLevel:oneof=DEBUG INFO WARNING ERROR"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("errors = %v, want %v", fields, want)
+	}
+	if !strings.Contains(err.Error(), "config: HTTP.Timeout (http.timeout): min=1s: 10ms is below the minimum 1s") {
+		t.Errorf("message = %v", err)
+	}
+}
+
+func TestBindConversionError(t *testing.T) {
+	var s struct {
+		Port uint16 `env:"PORT"`
+	}
+	t.Setenv("PORT", "70000")
+	var fe *FieldError
+	if err := Bind(&s); !errors.As(err, &fe) || fe.Key != "port" {
+		t.Errorf("err = %v", err)
+	}
+	if err := Bind(s); err == nil {
+		t.Error("expected an error for a non-pointer")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{"PoolSize": "pool_size", "HTTPTimeout": "http_timeout", "URL": "url", "OAuth2Token": "o_auth2_token"} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
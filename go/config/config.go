@@ -0,0 +1,301 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package config loads application settings from layered sources, the Go
+// counterpart of the Python foundation config loaders. Values are merged in
+// a fixed order, each layer overriding the ones before it:
+//
+//  1. defaults passed to WithDefaults
+//  2. files passed to WithFile or WithOptionalFile, in the order given
+//  3. environment variables, when WithEnvPrefix is used
+//  4. values set at runtime with Set
+//
+// Any loaded value of the form "secret://name" is then replaced by the
+// secret resolved through the provider given to WithSecrets, so
+// credentials need not live in plain configuration.
+//
+// Keys are lower-case and dotted; nested file sections are flattened, so
+// the YAML document
+//
+//	database:
+//	  url: postgresql://db/myapp
+//
+// provides "database.url". With WithEnvPrefix("APP") that key is
+// overridden by APP_DATABASE_URL. The composition root reads its settings
+// once instead of hard-coding them:
+//
+//	cfg, err := config.Load(
+//		config.WithDefaults(map[string]any{
+//			"database.url": "postgresql://localhost/myapp",
+//			"http.timeout": "30s",
+//		}),
+//		config.WithOptionalFile("config.yaml"),
+//		config.WithEnvPrefix("APP"),
+//	)
+//	database := NewDatabase(cfg.String("database.url"))
+//	timeout, err := cfg.Duration("http.timeout")
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Source identifies the layer a value came from. The numeric values match
+// the Python ConfigSource so precedence compares the same way.
+type Source int
+
+// Configuration sources, lowest precedence first.
+const (
+	SourceDefault Source = 0
+	SourceFile    Source = 10
+	SourceEnv     Source = 20
+	SourceRuntime Source = 30
+)
+
+// String returns the lower-case source name.
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceRuntime:
+		return "runtime"
+	}
+	return fmt.Sprintf("source(%d)", int(s))
+}
+
+// ErrNotFound is matched by errors.Is when a key has no value.
+var ErrNotFound = errors.New("config: key not found")
+
+// Option configures Load.
+type Option func(*options)
+
+type file struct {
+	path     string
+	optional bool
+}
+
+type options struct {
+	defaults  map[string]any
+	files     []file
+	envPrefix *string
+	lookupEnv func(string) (string, bool)
+	secrets   SecretsProvider
+}
+
+// WithDefaults sets the lowest-precedence values. Keys may be dotted or
+// nested maps; both forms are flattened the same way. Only keys that have
+// a default or appear in a file are read from the environment, so declare
+// every setting here.
+func WithDefaults(values map[string]any) Option {
+	return func(o *options) {
+		if o.defaults == nil {
+			o.defaults = make(map[string]any)
+		}
+		flatten("", values, o.defaults)
+	}
+}
+
+// WithFile adds a YAML, TOML or JSON file, chosen by its extension. Files
+// are applied in the order they are added, and Load fails if the file
+// does not exist.
+func WithFile(path string) Option {
+	return func(o *options) { o.files = append(o.files, file{path: path}) }
+}
+
+// WithOptionalFile is WithFile for a file that may be absent, such as a
+// local override next to the shipped configuration.
+func WithOptionalFile(path string) Option {
+	return func(o *options) { o.files = append(o.files, file{path: path, optional: true}) }
+}
+
+// WithEnvPrefix enables the environment layer. A key is read from the
+// variable named by EnvName, e.g. APP_HTTP_TIMEOUT for "http.timeout" with
+// prefix "APP". An empty prefix uses the bare name, HTTP_TIMEOUT.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) { o.envPrefix = &prefix }
+}
+
+// EnvName returns the environment variable that overrides key: the prefix
+// and the key joined with underscores, upper-cased, with dots and dashes
+// replaced by underscores.
+func EnvName(prefix, key string) string {
+	name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+	if prefix == "" {
+		return name
+	}
+	return strings.ToUpper(strings.TrimSuffix(prefix, "_")) + "_" + name
+}
+
+// value is one resolved setting and where it came from.
+type value struct {
+	raw    any
+	source Source
+	origin string // file path or environment variable, if any
+	secret bool   // resolved from a secret reference
+}
+
+// Config holds merged settings. It is safe for concurrent use.
+type Config struct {
+	opts options
+
+	writeMu     sync.Mutex // serializes Set and Reload
+	loaded      string     // source fingerprint of the last load
+	mu          sync.RWMutex
+	values      map[string]value
+	runtime     map[string]any // Set values, kept across reloads
+	subscribers map[int]func(old, new *Config)
+	nextSub     int
+}
+
+// Load reads every configured source and merges them. Errors from all
+// files are reported together.
+func Load(opts ...Option) (*Config, error) {
+	o := options{lookupEnv: os.LookupEnv}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	values, err := o.load()
+	if err != nil {
+		return nil, err
+	}
+	return &Config{opts: o, values: values, runtime: map[string]any{}, loaded: o.fingerprint(values)}, nil
+}
+
+// load merges the defaults, file and environment layers.
+func (o *options) load() (map[string]value, error) {
+	values := make(map[string]value, len(o.defaults))
+	for k, v := range o.defaults {
+		values[k] = value{raw: v, source: SourceDefault}
+	}
+	var errs []error
+	for _, f := range o.files {
+		data, err := ReadFile(f.path)
+		if err != nil {
+			if f.optional && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			errs = append(errs, err)
+			continue
+		}
+		for k, v := range data {
+			values[k] = value{raw: v, source: SourceFile, origin: f.path}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if o.envPrefix != nil {
+		for k := range values {
+			name := EnvName(*o.envPrefix, k)
+			if v, ok := o.lookupEnv(name); ok {
+				values[k] = value{raw: v, source: SourceEnv, origin: name}
+			}
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(values)) {
+		v := values[k]
+		raw, err := o.resolveSecret(v.raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config: %s: %w", k, err))
+			continue
+		}
+		v.secret = isSecretRef(v.raw)
+		v.raw = raw
+		values[k] = v
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Get returns the raw value of key. Environment values are strings; file
+// values keep the type their decoder produced.
+func (c *Config) Get(key string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v, ok := c.values[key]
+	return v.raw, ok
+}
+
+// Has reports whether key has a value.
+func (c *Config) Has(key string) bool {
+	_, ok := c.Get(key)
+	return ok
+}
+
+// Source reports the layer that supplied key and, for files and the
+// environment, the file path or variable name.
+func (c *Config) Source(key string) (src Source, origin string, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v, ok := c.values[key]
+	return v.source, v.origin, ok
+}
+
+// FromSecret reports whether the value of key was resolved from a
+// "secret://" reference, so it can be kept out of dumps and logs.
+func (c *Config) FromSecret(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.values[key].secret
+}
+
+// Keys returns every key in sorted order.
+func (c *Config) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.values))
+}
+
+// Set overrides key at runtime. Runtime values take precedence over every
+// loaded source, survive reloads, and are reported to OnChange
+// subscribers.
+func (c *Config) Set(key string, v any) {
+	c.writeMu.Lock()
+	c.mu.RLock()
+	values := maps.Clone(c.values)
+	c.mu.RUnlock()
+	values[key] = value{raw: v, source: SourceRuntime}
+	c.runtime[key] = v
+	notify := c.swap(values)
+	c.writeMu.Unlock()
+	notify()
+}
+
+// lookup returns the full value of key, or an ErrNotFound error.
+func (c *Config) lookup(key string) (value, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v, ok := c.values[key]
+	if !ok {
+		return value{}, fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	return v, nil
+}
+
+// flatten copies src into dst with nested maps turned into dotted,
+// lower-case keys.
+func flatten(prefix string, src map[string]any, dst map[string]any) {
+	for k, v := range src {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if m, ok := v.(map[string]any); ok {
+			flatten(key, m, dst)
+			continue
+		}
+		dst[key] = v
+	}
+}
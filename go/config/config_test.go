@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	base := writeFile(t, "base.yaml", "database:\n  url: postgresql://db/base\n  pool_size: 5\nhttp:\n  timeout: 10s\n")
+	local := writeFile(t, "local.json", `{"database": {"pool_size": 8}}`)
+	t.Setenv("APP_HTTP_TIMEOUT", "45s")
+
+	cfg, err := Load(
+		WithDefaults(map[string]any{
+			"database.url":       "postgresql://localhost/myapp",
+			"http":               map[string]any{"timeout": 30, "base_url": "https://api.example.com"},
+			"database.pool_size": 1,
+		}),
+		WithFile(base),
+		WithFile(local),
+		WithOptionalFile(filepath.Join(t.TempDir(), "missing.toml")),
+		WithEnvPrefix("APP"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cfg.String("database.url"); got != "postgresql://db/base" {
+		t.Errorf("database.url = %q", got)
+	}
+	if got, _ := cfg.Int("database.pool_size"); got != 8 {
+		t.Errorf("database.pool_size = %d", got)
+	}
+	if got, _ := cfg.Duration("http.timeout"); got != 45*time.Second {
+		t.Errorf("http.timeout = %v", got)
+	}
+	if got := cfg.String("http.base_url"); got != "https://api.example.com" {
+		t.Errorf("http.base_url = %q", got)
+	}
+
+	for key, want := range map[string]Source{
+		"http.base_url":      SourceDefault,
+		"database.url":       SourceFile,
+		"database.pool_size": SourceFile,
+		"http.timeout":       SourceEnv,
+	} {
+		if src, _, _ := cfg.Source(key); src != want {
+			t.Errorf("source of %s = %v, want %v", key, src, want)
+		}
+	}
+	if _, origin, _ := cfg.Source("database.pool_size"); origin != local {
+		t.Errorf("origin = %q, want %q", origin, local)
+	}
+
+	cfg.Set("http.timeout", "5s")
+	if got, _ := cfg.Duration("http.timeout"); got != 5*time.Second {
+		t.Errorf("runtime http.timeout = %v", got)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	bad := writeFile(t, "bad.json", "{")
+	_, err := Load(WithFile(bad), WithFile(filepath.Join(t.TempDir(), "missing.yaml")))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file not reported: %v", err)
+	}
+	if _, err := Load(WithFile(writeFile(t, "app.ini", ""))); err == nil {
+		t.Error("expected an unsupported format error")
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for _, tc := range []struct{ prefix, key, want string }{
+		{"APP", "database.url", "APP_DATABASE_URL"},
+		{"app_", "http.base-url", "APP_HTTP_BASE_URL"},
+		{"", "http.timeout", "HTTP_TIMEOUT"},
+	} {
+		if got := EnvName(tc.prefix, tc.key); got != tc.want {
+			t.Errorf("EnvName(%q, %q) = %q, want %q", tc.prefix, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestConversions(t *testing.T) {
+	t.Setenv("POOL_SIZE", "many")
+	cfg, err := Load(
+		WithDefaults(map[string]any{
+			"timeout":   30,
+			"ratio":     "0.5",
+			"debug":     "on",
+			"pool_size": 4,
+		}),
+		WithEnvPrefix(""),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := cfg.Duration("timeout"); err != nil || d != 30*time.Second {
+		t.Errorf("timeout = %v, %v", d, err)
+	}
+	if f, err := cfg.Float64("ratio"); err != nil || f != 0.5 {
+		t.Errorf("ratio = %v, %v", f, err)
+	}
+	if b, err := cfg.Bool("debug"); err != nil || !b {
+		t.Errorf("debug = %v, %v", b, err)
+	}
+
+	_, err = cfg.Int("pool_size")
+	var verr *ValueError
+	if !errors.As(err, &verr) || verr.Source != SourceEnv || verr.Origin != "POOL_SIZE" {
+		t.Errorf("pool_size error = %v", err)
+	}
+	if _, err := cfg.Int("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing key error = %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueError reports a value that cannot be converted to the requested
+// type, together with where the value came from.
+type ValueError struct {
+	Key    string
+	Source Source
+	Origin string
+	Err    error
+}
+
+func (e *ValueError) Error() string {
+	from := e.Source.String()
+	if e.Origin != "" {
+		from += " " + e.Origin
+	}
+	return fmt.Sprintf("config: %s (from %s): %v", e.Key, from, e.Err)
+}
+
+func (e *ValueError) Unwrap() error { return e.Err }
+
+// String returns key formatted as a string, or "" if it has no value.
+func (c *Config) String(key string) string {
+	v, ok := c.Get(key)
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// Int returns key as an int.
+func (c *Config) Int(key string) (int, error) {
+	return convert(c, key, toInt)
+}
+
+// Float64 returns key as a float64.
+func (c *Config) Float64(key string) (float64, error) {
+	return convert(c, key, toFloat)
+}
+
+// Bool returns key as a bool. Strings accept the same words as the Python
+// strict parser: true/yes/1/on/enabled and false/no/0/off/disabled.
+func (c *Config) Bool(key string) (bool, error) {
+	return convert(c, key, toBool)
+}
+
+// Duration returns key as a duration. Strings use time.ParseDuration
+// syntax ("30s", "1m30s"); bare numbers are seconds, as timeouts are in
+// the Python configuration.
+func (c *Config) Duration(key string) (time.Duration, error) {
+	return convert(c, key, toDuration)
+}
+
+func convert[T any](c *Config, key string, fn func(any) (T, error)) (T, error) {
+	v, err := c.lookup(key)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	out, err := fn(v.raw)
+	if err != nil {
+		return out, &ValueError{Key: key, Source: v.source, Origin: v.origin, Err: err}
+	}
+	return out, nil
+}
+
+func toInt(v any) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("%v is not an integer", n)
+		}
+		return int(n), nil
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", n)
+		}
+		return i, nil
+	}
+	return 0, fmt.Errorf("cannot use %T as an integer", v)
+}
+
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", n)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("cannot use %T as a number", v)
+}
+
+func toBool(v any) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case int, int64, float64:
+		f, _ := toFloat(b)
+		switch f {
+		case 1:
+			return true, nil
+		case 0:
+			return false, nil
+		}
+		return false, fmt.Errorf("invalid boolean %v, want 0 or 1", v)
+	case string:
+		switch strings.ToLower(strings.TrimSpace(b)) {
+		case "true", "yes", "1", "on", "enabled":
+			return true, nil
+		case "false", "no", "0", "off", "disabled":
+			return false, nil
+		}
+		return false, fmt.Errorf("invalid boolean %q", b)
+	}
+	return false, fmt.Errorf("cannot use %T as a boolean", v)
+}
+
+func toDuration(v any) (time.Duration, error) {
+	switch d := v.(type) {
+	case time.Duration:
+		return d, nil
+	case string:
+		s := strings.TrimSpace(d)
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return time.Duration(f * float64(time.Second)), nil
+		}
+		dur, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", d)
+		}
+		return dur, nil
+	}
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, fmt.Errorf("cannot use %T as a duration", v)
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/provide-io/provide-foundation/go/serde"
+)
+
+// ReadFile decodes a YAML (.yaml, .yml), TOML (.toml) or JSON (.json) file
+// into flattened, dotted keys. Errors wrap the underlying cause, so a
+// missing file matches os.ErrNotExist.
+func ReadFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	format, err := serde.FormatOf(path)
+	if err != nil || format == serde.MsgPack {
+		return nil, fmt.Errorf("config: %s: unsupported file format %q", path, filepath.Ext(path))
+	}
+	var doc map[string]any
+	if err := serde.Unmarshal(format, data, &doc); err != nil {
+		return nil, fmt.Errorf("config: parsing %s: %w", path, err)
+	}
+	values := make(map[string]any, len(doc))
+	flatten("", doc, values)
+	return values, nil
+}
@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadFileFormats(t *testing.T) {
+	want := map[string]any{"database.url": "postgresql://db/myapp", "http.retries": 3, "http.verbose": true}
+	for name, content := range map[string]string{
+		"app.yaml": "database:\n  url: postgresql://db/myapp\nhttp:\n  retries: 3\n  verbose: true\n",
+		"app.toml": "[database]\nurl = \"postgresql://db/myapp\"\n\n[http]\nretries = 3\nverbose = true\n",
+		"app.json": `{"database": {"url": "postgresql://db/myapp"}, "HTTP": {"retries": 3, "verbose": true}}`,
+	} {
+		got, err := ReadFile(writeFile(t, name, content))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		retries, err := toInt(got["http.retries"])
+		if err != nil || retries != 3 {
+			t.Errorf("%s: http.retries = %v", name, got["http.retries"])
+		}
+		got["http.retries"] = retries
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", name, got, want)
+		}
+	}
+}
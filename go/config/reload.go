@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/filex"
+)
+
+// OnChange registers fn to be called after the configuration changes,
+// through Reload, Watch or Set. old and new are read-only snapshots taken
+// before and after the change; use ChangedKeys to see what moved, e.g.
+//
+//	cfg.OnChange(func(old, new *config.Config) {
+//		if level, err := log.ParseLevel(new.String("log.level")); err == nil {
+//			logger.SetLevel(level)
+//		}
+//	})
+//
+// Subscribers run in registration order on the goroutine that made the
+// change. The returned function unsubscribes fn.
+func (c *Config) OnChange(fn func(old, new *Config)) (cancel func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subscribers == nil {
+		c.subscribers = make(map[int]func(old, new *Config))
+	}
+	id := c.nextSub
+	c.nextSub++
+	c.subscribers[id] = fn
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.subscribers, id)
+	}
+}
+
+// Reload re-reads every file and the environment. Values set with Set are
+// kept. If a file cannot be read or parsed the current values stay in
+// place and the error is returned; subscribers are only called when a
+// value actually changed.
+func (c *Config) Reload() error {
+	c.writeMu.Lock()
+	values, err := c.opts.load()
+	if err != nil {
+		c.writeMu.Unlock()
+		return err
+	}
+	for k, v := range c.runtime {
+		values[k] = value{raw: v, source: SourceRuntime}
+	}
+	c.loaded = c.opts.fingerprint(values)
+	notify := c.swap(values)
+	c.writeMu.Unlock()
+	notify()
+	return nil
+}
+
+// swap installs values and returns a function that notifies subscribers,
+// to be called once c.writeMu is released so subscribers may call Set.
+// Callers hold c.writeMu.
+func (c *Config) swap(values map[string]value) (notify func()) {
+	c.mu.Lock()
+	prev := c.values
+	c.values = values
+	subs := make([]func(old, new *Config), 0, len(c.subscribers))
+	for _, id := range slices.Sorted(maps.Keys(c.subscribers)) {
+		subs = append(subs, c.subscribers[id])
+	}
+	c.mu.Unlock()
+	if len(subs) == 0 || maps.EqualFunc(prev, values, sameValue) {
+		return func() {}
+	}
+	before, after := c.snapshot(prev), c.snapshot(values)
+	return func() {
+		for _, fn := range subs {
+			fn(before, after)
+		}
+	}
+}
+
+// snapshot returns a detached Config holding values.
+func (c *Config) snapshot(values map[string]value) *Config {
+	return &Config{opts: c.opts, values: values, runtime: map[string]any{}}
+}
+
+func sameValue(a, b value) bool {
+	return a.source == b.source && a.origin == b.origin && reflect.DeepEqual(a.raw, b.raw)
+}
+
+// ChangedKeys returns the sorted keys whose value differs between old and
+// new, including keys present in only one of them.
+func ChangedKeys(old, new *Config) []string {
+	old.mu.RLock()
+	defer old.mu.RUnlock()
+	new.mu.RLock()
+	defer new.mu.RUnlock()
+	var keys []string
+	for k, v := range old.values {
+		if nv, ok := new.values[k]; !ok || !reflect.DeepEqual(v.raw, nv.raw) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range new.values {
+		if _, ok := old.values[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// DefaultWatchInterval is how often Watch checks its sources.
+const DefaultWatchInterval = 2 * time.Second
+
+// WatchOption configures Watch.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	interval time.Duration
+	onError  func(error)
+}
+
+// WithWatchInterval sets how often files and the environment are checked.
+func WithWatchInterval(d time.Duration) WatchOption {
+	return func(o *watchOptions) { o.interval = d }
+}
+
+// WithReloadErrorHandler sets the function called when a reload fails.
+// The default writes the error to stderr.
+func WithReloadErrorHandler(fn func(error)) WatchOption {
+	return func(o *watchOptions) { o.onError = fn }
+}
+
+// Watch reloads until ctx is done whenever a configured file's size or
+// modification time, or a watched environment variable, changes. Files
+// are watched with filex.Watch, so edits apply as soon as they settle;
+// the environment, and files on systems that cannot notify, are polled.
+// A failed reload keeps the previous values and is retried on the next
+// change.
+func (c *Config) Watch(ctx context.Context, opts ...WatchOption) {
+	o := watchOptions{
+		interval: DefaultWatchInterval,
+		onError: func(err error) {
+			fmt.Fprintf(os.Stderr, "config: reload: %v\n", err)
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+	var changes <-chan []filex.Event
+	if paths := c.opts.paths(); len(paths) > 0 {
+		// Without notifications, polling still picks up file changes.
+		changes, _ = filex.Watch(ctx, paths)
+	}
+	var failed string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case _, ok := <-changes:
+			if !ok {
+				return
+			}
+		}
+		c.mu.RLock()
+		fp := c.opts.fingerprint(c.values)
+		c.mu.RUnlock()
+		c.writeMu.Lock()
+		stale := fp != c.loaded && fp != failed
+		c.writeMu.Unlock()
+		if !stale {
+			continue
+		}
+		if err := c.Reload(); err != nil {
+			failed = fp
+			o.onError(err)
+		}
+	}
+}
+
+func (o *options) paths() []string {
+	paths := make([]string, len(o.files))
+	for i, f := range o.files {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// fingerprint summarizes the state of every file and of the environment
+// variables of the keys in values, so Watch can tell when a reload is
+// needed without parsing files.
+func (o *options) fingerprint(values map[string]value) string {
+	var b strings.Builder
+	for _, f := range o.files {
+		if fi, err := os.Stat(f.path); err == nil {
+			fmt.Fprintf(&b, "%s %d %d\n", f.path, fi.Size(), fi.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(&b, "%s missing\n", f.path)
+		}
+	}
+	if o.envPrefix != nil {
+		for _, k := range slices.Sorted(maps.Keys(values)) {
+			name := EnvName(*o.envPrefix, k)
+			if v, ok := o.lookupEnv(name); ok {
+				fmt.Fprintf(&b, "%s=%s\n", name, v)
+			}
+		}
+	}
+	return b.String()
+}
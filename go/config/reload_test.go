@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/filex"
+)
+
+func TestReloadNotifiesSubscribers(t *testing.T) {
+	path := writeFile(t, "app.yaml", "log:\n  level: INFO\nhttp:\n  timeout: 30s\n")
+	cfg, err := Load(WithFile(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes [][]string
+	cancel := cfg.OnChange(func(old, new *Config) {
+		changes = append(changes, ChangedKeys(old, new))
+	})
+
+	cfg.Set("http.timeout", "5s")
+	os.WriteFile(path, []byte("log:\n  level: DEBUG\nhttp:\n  timeout: 10s\n"), 0o600)
+	if err := cfg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.String("http.timeout"); got != "5s" {
+		t.Errorf("runtime value lost on reload: %q", got)
+	}
+	if err := cfg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(path, []byte("log: [broken"), 0o600)
+	if err := cfg.Reload(); err == nil {
+		t.Error("expected a parse error")
+	}
+	if got := cfg.String("log.level"); got != "DEBUG" {
+		t.Errorf("failed reload replaced values: %q", got)
+	}
+
+	cancel()
+	cfg.Set("log.level", "ERROR")
+	want := [][]string{{"http.timeout"}, {"log.level"}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+}
+
+func TestWatchReloadsOnFileChange(t *testing.T) {
+	path := writeFile(t, "app.json", `{"log": {"level": "INFO"}}`)
+	t.Setenv("APP_HTTP_TIMEOUT", "30s")
+	cfg, err := Load(WithFile(path), WithDefaults(map[string]any{"http.timeout": "1s"}), WithEnvPrefix("APP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan []string, 4)
+	cfg.OnChange(func(old, new *Config) { changed <- ChangedKeys(old, new) })
+
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		cfg.Watch(ctx, WithWatchInterval(5*time.Millisecond), WithReloadErrorHandler(func(err error) { t.Error(err) }))
+		close(done)
+	}()
+	defer func() { stop(); <-done }()
+
+	os.WriteFile(path, []byte(`{"log": {"level": "WARNING"}}`), 0o600)
+	if keys := wait(t, changed); !reflect.DeepEqual(keys, []string{"log.level"}) {
+		t.Errorf("file change keys = %v", keys)
+	}
+	os.Setenv("APP_HTTP_TIMEOUT", "45s")
+	if keys := wait(t, changed); !reflect.DeepEqual(keys, []string{"http.timeout"}) {
+		t.Errorf("env change keys = %v", keys)
+	}
+}
+
+func TestWatchNotifiedOfFileChange(t *testing.T) {
+	path := writeFile(t, "app.json", `{"log": {"level": "INFO"}}`)
+	cfg, err := Load(WithFile(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan []string, 4)
+	cfg.OnChange(func(old, new *Config) { changed <- ChangedKeys(old, new) })
+
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		// Polling alone would not notice within the test.
+		cfg.Watch(ctx, WithWatchInterval(time.Hour), WithReloadErrorHandler(func(err error) { t.Error(err) }))
+		close(done)
+	}()
+	defer func() { stop(); <-done }()
+
+	time.Sleep(50 * time.Millisecond) // let Watch subscribe
+	if err := filex.WriteAtomic(path, []byte(`{"log": {"level": "DEBUG"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if keys := wait(t, changed); !reflect.DeepEqual(keys, []string{"log.level"}) {
+		t.Errorf("changed keys = %v", keys)
+	}
+}
+
+func wait(t *testing.T, ch <-chan []string) []string {
+	t.Helper()
+	select {
+	case keys := <-ch:
+		return keys
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change notification")
+		return nil
+	}
+}
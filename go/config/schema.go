@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FieldSchema documents one setting of a struct used with Bind.
+type FieldSchema struct {
+	Key         string   `json:"key"`
+	Field       string   `json:"field"`
+	Type        string   `json:"type"`
+	Env         string   `json:"env,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Required    bool     `json:"required"`
+	Rules       []string `json:"rules,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// Schema describes every setting of the struct v, or of the struct v
+// points to, from the same tags Bind reads plus desc. Fields are listed in
+// declaration order, so generated references follow the Go definition.
+func Schema(v any) ([]FieldSchema, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: Schema needs a struct, got %T", v)
+	}
+	specs, err := structFields(t)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]FieldSchema, len(specs))
+	for i, f := range specs {
+		fields[i] = FieldSchema{
+			Key:         f.key,
+			Field:       f.field,
+			Type:        typeName(f.typ),
+			Env:         f.env,
+			Default:     f.def,
+			Required:    slices.Contains(f.rules, "required"),
+			Rules:       slices.DeleteFunc(slices.Clone(f.rules), func(r string) bool { return r == "required" }),
+			Description: f.desc,
+		}
+	}
+	return fields, nil
+}
+
+// typeName returns the name a setting's type is documented under.
+func typeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t.Kind() == reflect.Slice:
+		return "list of " + typeName(t.Elem())
+	case t.PkgPath() == "":
+		return t.Kind().String()
+	}
+	return t.String()
+}
+
+// WriteJSON writes fields as an indented JSON array.
+func WriteJSON(w io.Writer, fields []FieldSchema) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fields)
+}
+
+// WriteMarkdown writes fields as a Markdown table suitable for a
+// configuration reference page.
+func WriteMarkdown(w io.Writer, fields []FieldSchema) error {
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Environment | Constraints | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, f := range fields {
+		constraints := f.Rules
+		if f.Required {
+			constraints = append([]string{"required"}, constraints...)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			code(f.Key), f.Type, code(f.Default), code(f.Env),
+			cell(strings.Join(constraints, ", ")), cell(f.Description))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// code formats a non-empty value as inline code.
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + cell(s) + "`"
+}
+
+// cell escapes characters that would break a table row.
+func cell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
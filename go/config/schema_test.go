@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type documented struct {
+	Database struct {
+		URL string `env:"DATABASE_URL" validate:"required" desc:"Connection string; use secret:// in production."`
+	}
+	Timeout time.Duration `config:"http.timeout" env:"HTTP_TIMEOUT" default:"30s" validate:"min=1s,max=5m" desc:"Outbound request timeout."`
+	Hosts   []string      `desc:"Hosts, a|b separated by commas."`
+}
+
+func TestSchema(t *testing.T) {
+	fields, err := Schema(&documented{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 3 {
+		t.Fatalf("fields = %+v", fields)
+	}
+	url, timeout, hosts := fields[0], fields[1], fields[2]
+	if url.Key != "database.url" || url.Type != "string" || !url.Required || len(url.Rules) != 0 {
+		t.Errorf("url = %+v", url)
+	}
+	if timeout.Key != "http.timeout" || timeout.Type != "duration" || timeout.Default != "30s" ||
+		strings.Join(timeout.Rules, ",") != "min=1s,max=5m" || timeout.Field != "Timeout" {
+		t.Errorf("timeout = %+v", timeout)
+	}
+	if hosts.Type != "list of string" {
+		t.Errorf("hosts = %+v", hosts)
+	}
+	if _, err := Schema(42); err == nil {
+		t.Error("expected an error for a non-struct")
+	}
+}
+
+func TestWriteSchema(t *testing.T) {
+	fields, _ := Schema(documented{})
+
+	var md bytes.Buffer
+	if err := WriteMarkdown(&md, fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| Key | Type | Default | Environment | Constraints | Description |\n",
+		"| `http.timeout` | duration | `30s` | `HTTP_TIMEOUT` | min=1s, max=5m | Outbound request timeout. |\n",
+		"| `database.url` | string |  | `DATABASE_URL` | required |",
+		`Hosts, a\|b separated`,
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := WriteJSON(&js, fields); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded[1]["key"] != "http.timeout" || decoded[1]["env"] != "HTTP_TIMEOUT" {
+		t.Errorf("json = %s", js.String())
+	}
+}
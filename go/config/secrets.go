@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SecretPrefix marks a configuration value as a reference to a secret:
+// "secret://db-password" is replaced by the secret named "db-password"
+// when the configuration is loaded.
+const SecretPrefix = "secret://"
+
+// ErrSecretNotFound is matched by errors.Is when a provider has no secret
+// of the requested name.
+var ErrSecretNotFound = errors.New("config: secret not found")
+
+// SecretsProvider resolves secrets by name. Implementations backed by a
+// secret manager such as Vault or AWS Secrets Manager satisfy it directly
+// and are combined with the built-in providers through a SecretsMux.
+type SecretsProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// SecretsProviderFunc adapts a function to SecretsProvider.
+type SecretsProviderFunc func(ctx context.Context, name string) (string, error)
+
+// Secret calls f.
+func (f SecretsProviderFunc) Secret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// WithSecrets resolves values that start with SecretPrefix through p, in
+// every layer and in Bind env tags. Secrets are fetched when the
+// configuration is loaded or reloaded, never on access. Without a
+// provider such values fail to load.
+func WithSecrets(p SecretsProvider) Option {
+	return func(o *options) { o.secrets = p }
+}
+
+// isSecretRef reports whether raw is a secret reference.
+func isSecretRef(raw any) bool {
+	s, ok := raw.(string)
+	return ok && strings.HasPrefix(s, SecretPrefix)
+}
+
+// resolveSecret replaces a secret reference with its value and returns any
+// other value unchanged.
+func (o *options) resolveSecret(raw any) (any, error) {
+	if !isSecretRef(raw) {
+		return raw, nil
+	}
+	name := strings.TrimPrefix(raw.(string), SecretPrefix)
+	if o.secrets == nil {
+		return nil, fmt.Errorf("secret %q referenced but no secrets provider is configured", name)
+	}
+	v, err := o.secrets.Secret(context.Background(), name)
+	if err != nil {
+		return nil, fmt.Errorf("resolving secret %q: %w", name, err)
+	}
+	return v, nil
+}
+
+// EnvSecrets reads secrets from environment variables named prefix plus
+// the upper-cased secret name, so EnvSecrets("APP_")'s "db-password" is
+// APP_DB_PASSWORD. As in the Python foundation, a value of the form
+// file:///run/secrets/db is replaced by the trimmed contents of that file.
+func EnvSecrets(prefix string) SecretsProvider {
+	return SecretsProviderFunc(func(_ context.Context, name string) (string, error) {
+		key := prefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_", "/", "_").Replace(name))
+		v, ok := os.LookupEnv(key)
+		if !ok {
+			return "", fmt.Errorf("%w: environment variable %s is not set", ErrSecretNotFound, key)
+		}
+		if path, ok := strings.CutPrefix(v, "file://"); ok {
+			return readSecretFile(path)
+		}
+		return v, nil
+	})
+}
+
+// FileSecrets reads secrets from files in dir, one file per secret, as
+// mounted by Kubernetes and Docker secrets. Trailing whitespace is
+// trimmed. Names may contain slashes for nested directories but cannot
+// leave dir.
+func FileSecrets(dir string) SecretsProvider {
+	return SecretsProviderFunc(func(_ context.Context, name string) (string, error) {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return "", fmt.Errorf("config: invalid secret name %q", name)
+		}
+		return readSecretFile(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s does not exist", ErrSecretNotFound, path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("config: reading secret: %w", err)
+	}
+	v := strings.TrimRight(string(data), " \t\r\n")
+	if v == "" {
+		return "", fmt.Errorf("config: secret file %s is empty", path)
+	}
+	return v, nil
+}
+
+// ChainSecrets tries each provider in order and returns the first secret
+// found. Errors other than ErrSecretNotFound stop the search.
+func ChainSecrets(providers ...SecretsProvider) SecretsProvider {
+	return SecretsProviderFunc(func(ctx context.Context, name string) (string, error) {
+		var errs []error
+		for _, p := range providers {
+			v, err := p.Secret(ctx, name)
+			if err == nil {
+				return v, nil
+			}
+			if !errors.Is(err, ErrSecretNotFound) {
+				return "", err
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return "", fmt.Errorf("%w: %q", ErrSecretNotFound, name)
+		}
+		return "", errors.Join(errs...)
+	})
+}
+
+// SecretsMux routes secret names of the form "scheme:name" to the provider
+// registered for scheme, so one configuration can mix secret stores:
+//
+//	mux := config.NewSecretsMux(config.FileSecrets("/run/secrets"))
+//	mux.Handle("vault", vaultProvider)
+//	// "secret://vault:db/password" is looked up in Vault as "db/password",
+//	// "secret://api-token" is read from /run/secrets/api-token.
+type SecretsMux struct {
+	mu        sync.RWMutex
+	providers map[string]SecretsProvider
+	fallback  SecretsProvider
+}
+
+// NewSecretsMux returns a mux that sends names without a registered scheme
+// to fallback, which may be nil.
+func NewSecretsMux(fallback SecretsProvider) *SecretsMux {
+	return &SecretsMux{providers: make(map[string]SecretsProvider), fallback: fallback}
+}
+
+// Handle registers p for scheme, replacing any earlier registration.
+func (m *SecretsMux) Handle(scheme string, p SecretsProvider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.providers[scheme] = p
+}
+
+// Secret resolves name through the provider of its scheme.
+func (m *SecretsMux) Secret(ctx context.Context, name string) (string, error) {
+	m.mu.RLock()
+	p := m.fallback
+	if scheme, rest, ok := strings.Cut(name, ":"); ok {
+		if sp, ok := m.providers[scheme]; ok {
+			p, name = sp, rest
+		}
+	}
+	m.mu.RUnlock()
+	if p == nil {
+		return "", fmt.Errorf("%w: no provider for %q", ErrSecretNotFound, name)
+	}
+	return p.Secret(ctx, name)
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretsResolvedAtLoad(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "db-url"), []byte("postgresql://app:s3cret@db/myapp\n"), 0o600)
+	t.Setenv("APP_API_TOKEN", "secret://vault:tokens/api")
+
+	mux := NewSecretsMux(FileSecrets(dir))
+	var asked []string
+	mux.Handle("vault", SecretsProviderFunc(func(_ context.Context, name string) (string, error) {
+		asked = append(asked, name)
+		return "tok-123", nil
+	}))
+	cfg, err := Load(
+		WithDefaults(map[string]any{"database.url": "secret://db-url", "api.token": ""}),
+		WithEnvPrefix("APP"),
+		WithSecrets(mux),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.String("database.url"); got != "postgresql://app:s3cret@db/myapp" {
+		t.Errorf("database.url = %q", got)
+	}
+	if got := cfg.String("api.token"); got != "tok-123" || len(asked) != 1 || asked[0] != "tokens/api" {
+		t.Errorf("api.token = %q, asked %v", got, asked)
+	}
+	if !cfg.FromSecret("database.url") || !cfg.FromSecret("api.token") {
+		t.Error("resolved values not reported as secrets")
+	}
+	cfg.Set("database.url", "postgresql://localhost/myapp")
+	if cfg.FromSecret("database.url") || cfg.FromSecret("missing") {
+		t.Error("plain values reported as secrets")
+	}
+
+	var s struct {
+		Token string `env:"APP_API_TOKEN"`
+	}
+	if err := cfg.Bind(&s); err != nil || s.Token != "tok-123" {
+		t.Errorf("bound token = %q, %v", s.Token, err)
+	}
+}
+
+func TestSecretsErrors(t *testing.T) {
+	_, err := Load(WithDefaults(map[string]any{"database.url": "secret://db-url"}))
+	if err == nil || !strings.Contains(err.Error(), "no secrets provider") {
+		t.Errorf("err = %v", err)
+	}
+	_, err = Load(
+		WithDefaults(map[string]any{"database.url": "secret://db-url"}),
+		WithSecrets(FileSecrets(t.TempDir())),
+	)
+	if !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := FileSecrets(t.TempDir()).Secret(context.Background(), "../etc/passwd"); err == nil {
+		t.Error("expected an error for a name outside the directory")
+	}
+}
+
+func TestEnvAndChainSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(path, []byte("from-file\n"), 0o600)
+	t.Setenv("SECRET_API_TOKEN", "file://"+path)
+	t.Setenv("SECRET_DB_PASSWORD", "hunter2")
+
+	chain := ChainSecrets(FileSecrets(t.TempDir()), EnvSecrets("SECRET_"))
+	ctx := context.Background()
+	if v, err := chain.Secret(ctx, "api-token"); err != nil || v != "from-file" {
+		t.Errorf("api-token = %q, %v", v, err)
+	}
+	if v, err := chain.Secret(ctx, "db.password"); err != nil || v != "hunter2" {
+		t.Errorf("db.password = %q, %v", v, err)
+	}
+	if _, err := chain.Secret(ctx, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("missing = %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package conformance loads the scenarios the Go and Python foundations
+// both run to check that they behave alike. The scenarios live in the
+// conformance directory at the root of the repository, as JSON files
+// described in its README; the tests of this package run them against
+// the Go packages.
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+)
+
+// Suite is the scenarios of one area, from one file.
+type Suite struct {
+	Area        string `json:"area"`
+	Description string `json:"description"`
+	Cases       []Case `json:"cases"`
+	// File is the path the suite was loaded from.
+	File string `json:"-"`
+}
+
+// Case is one scenario: op applied to Input must produce Expect.
+type Case struct {
+	Name   string          `json:"name"`
+	Op     string          `json:"op"`
+	Input  json.RawMessage `json:"input"`
+	Expect json.RawMessage `json:"expect"`
+}
+
+// Error is the output of a case that fails, with Kind one of the error
+// kinds of the scenarios.
+type Error struct {
+	Kind string `json:"error"`
+}
+
+// Error kinds.
+const (
+	KindInvalid  = "invalid"
+	KindNotFound = "not_found"
+)
+
+// Check compares got, encoded as JSON, with the expected output of c.
+func (c Case) Check(got any) error {
+	data, err := json.Marshal(got)
+	if err != nil {
+		return fmt.Errorf("conformance: encoding output: %w", err)
+	}
+	var g, w any
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(c.Expect, &w); err != nil {
+		return fmt.Errorf("conformance: case %s: expect: %w", c.Name, err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		return fmt.Errorf("got %s, want %s", data, bytes.TrimSpace(c.Expect))
+	}
+	return nil
+}
+
+// Dir returns the scenario directory of the repository the package was
+// built from.
+func Dir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "conformance", "scenarios")
+}
+
+// Load reads the suites in dir, in file name order.
+func Load(dir string) ([]Suite, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+	suites := make([]Suite, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("conformance: %w", err)
+		}
+		var s Suite
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&s); err != nil {
+			return nil, fmt.Errorf("conformance: %s: %w", file, err)
+		}
+		s.File = file
+		suites = append(suites, s)
+	}
+	return suites, nil
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package conformance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/config"
+	"github.com/provide-io/provide-foundation/go/container"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// runners perform the operations of the scenarios on their input and
+// return the output to compare.
+var runners = map[string]func(input json.RawMessage) (any, error){
+	"parse_bool": func(input json.RawMessage) (any, error) {
+		var v any
+		if err := json.Unmarshal(input, &v); err != nil {
+			return nil, err
+		}
+		cfg, err := config.Load(config.WithDefaults(map[string]any{"value": v}))
+		if err != nil {
+			return nil, err
+		}
+		b, err := cfg.Bool("value")
+		if err != nil {
+			return Error{KindInvalid}, nil
+		}
+		return b, nil
+	},
+	"parse_log_level": func(input json.RawMessage) (any, error) {
+		var s string
+		if err := json.Unmarshal(input, &s); err != nil {
+			return nil, err
+		}
+		level, err := log.ParseLevel(s)
+		if err != nil {
+			return Error{KindInvalid}, nil
+		}
+		return strings.ToUpper(level.String()), nil
+	},
+	"parse_module_levels": func(input json.RawMessage) (any, error) {
+		var s string
+		if err := json.Unmarshal(input, &s); err != nil {
+			return nil, err
+		}
+		// Invalid entries are reported but skipped, as log.WithEnv does.
+		levels, _ := log.ParseModuleLevels(s)
+		out := map[string]string{}
+		for module, level := range levels {
+			out[module] = strings.ToUpper(level.String())
+		}
+		return out, nil
+	},
+	"di_resolve": func(input json.RawMessage) (any, error) {
+		var in struct {
+			Register map[string]string `json:"register"`
+			Resolve  string            `json:"resolve"`
+		}
+		if err := json.Unmarshal(input, &in); err != nil {
+			return nil, err
+		}
+		c, err := wire(in.Register)
+		if err != nil {
+			return nil, err
+		}
+		var injected map[string]string
+		switch in.Resolve {
+		case "UserService":
+			s, err := container.Resolve[*UserService](c)
+			if err != nil {
+				return diError(err)
+			}
+			injected = map[string]string{"Database": s.DB.Name, "Logger": s.Logger.Name}
+		case "CachedUserService":
+			s, err := container.Resolve[*CachedUserService](c)
+			if err != nil {
+				return diError(err)
+			}
+			injected = map[string]string{"Database": s.DB.Name, "Cache": s.Cache.Name}
+		default:
+			return nil, fmt.Errorf("unknown service %q", in.Resolve)
+		}
+		return injected, nil
+	},
+	"di_has": func(input json.RawMessage) (any, error) {
+		var in struct {
+			Register map[string]string `json:"register"`
+			Has      string            `json:"has"`
+		}
+		if err := json.Unmarshal(input, &in); err != nil {
+			return nil, err
+		}
+		c, err := wire(in.Register)
+		if err != nil {
+			return nil, err
+		}
+		switch in.Has {
+		case "Database":
+			return container.Has[*Database](c), nil
+		case "Logger":
+			return container.Has[*Logger](c), nil
+		case "Cache":
+			return container.Has[*Cache](c), nil
+		}
+		return nil, fmt.Errorf("unknown component %q", in.Has)
+	},
+}
+
+// The DI types of the scenarios.
+type (
+	Database struct{ Name string }
+	Logger   struct{ Name string }
+	Cache    struct{ Name string }
+
+	UserService struct {
+		DB     *Database
+		Logger *Logger
+	}
+	CachedUserService struct {
+		DB    *Database
+		Cache *Cache
+	}
+)
+
+// wire returns a container with the components of register and the
+// constructors of the services.
+func wire(register map[string]string) (*container.Container, error) {
+	c := container.New()
+	for _, component := range slices.Sorted(maps.Keys(register)) {
+		name := register[component]
+		var err error
+		switch component {
+		case "Database":
+			err = container.Register(c, &Database{name})
+		case "Logger":
+			err = container.Register(c, &Logger{name})
+		case "Cache":
+			err = container.Register(c, &Cache{name})
+		default:
+			err = fmt.Errorf("unknown component %q", component)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := errors.Join(
+		c.Provide(func(db *Database, l *Logger) *UserService { return &UserService{db, l} }),
+		c.Provide(func(db *Database, cache *Cache) *CachedUserService { return &CachedUserService{db, cache} }),
+	)
+	return c, err
+}
+
+func diError(err error) (any, error) {
+	if errors.Is(err, container.ErrNotFound) {
+		return Error{KindNotFound}, nil
+	}
+	return nil, err
+}
+
+func TestScenarios(t *testing.T) {
+	suites, err := Load(Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suites) == 0 {
+		t.Fatalf("no scenarios in %s", Dir())
+	}
+	for _, s := range suites {
+		t.Run(s.Area, func(t *testing.T) {
+			for _, c := range s.Cases {
+				run, ok := runners[c.Op]
+				if !ok {
+					t.Errorf("%s: no runner for op %q", c.Name, c.Op)
+					continue
+				}
+				got, err := run(c.Input)
+				if err != nil {
+					t.Errorf("%s: %v", c.Name, err)
+					continue
+				}
+				if err := c.Check(got); err != nil {
+					t.Errorf("%s: %s(%s): %v", c.Name, c.Op, c.Input, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package container is the Go counterpart of the Python foundation DI
+// Container: dependencies are registered by type at the composition root
+// and resolved through plain constructor functions.
+//
+//	c := container.New()
+//	c.Provide(NewDatabase)
+//	c.Provide(NewUserRepository)
+//	repo, err := container.Resolve[*UserRepository](c)
+//
+// Constructors take their dependencies as parameters and return the
+// provided value, optionally followed by an error. Every provided value is
+// a singleton within its container.
+package container
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// provider describes how to obtain a single registered type.
+type provider struct {
+	key  reflect.Type
+	fn   reflect.Value // zero for instances registered directly
+	deps []reflect.Type
+}
+
+// Container holds providers and the singletons built from them.
+type Container struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*provider
+	instances map[reflect.Type]reflect.Value
+	keys      []reflect.Type // registration order
+	order     []reflect.Type // instantiation order, dependencies first
+}
+
+// New creates an empty container.
+func New() *Container {
+	return &Container{
+		providers: make(map[reflect.Type]*provider),
+		instances: make(map[reflect.Type]reflect.Value),
+	}
+}
+
+// Provide registers a constructor function. Its parameters are resolved
+// from the container when the result is first requested. Provide fails if
+// the constructor's type is already registered or if adding it would close
+// a dependency cycle.
+func (c *Container) Provide(constructor any) error {
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func {
+		return fmt.Errorf("container: constructor must be a function, got %T", constructor)
+	}
+	ft := fn.Type()
+	switch {
+	case ft.NumOut() == 1:
+	case ft.NumOut() == 2 && ft.Out(1) == errorType:
+	default:
+		return fmt.Errorf("container: constructor %s must return T or (T, error)", ft)
+	}
+	if ft.IsVariadic() {
+		return fmt.Errorf("container: constructor %s must not be variadic", ft)
+	}
+
+	p := &provider{key: ft.Out(0), fn: fn}
+	for i := range ft.NumIn() {
+		p.deps = append(p.deps, ft.In(i))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.add(p)
+}
+
+// MustProvide is like Provide but panics on error. It is intended for
+// composition roots where a wiring mistake is a programming error.
+func (c *Container) MustProvide(constructor any) {
+	if err := c.Provide(constructor); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds an already constructed instance under type T.
+func Register[T any](c *Container, instance T) error {
+	key := reflect.TypeFor[T]()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.add(&provider{key: key}); err != nil {
+		return err
+	}
+	c.instances[key] = reflect.ValueOf(&instance).Elem()
+	c.order = append(c.order, key)
+	return nil
+}
+
+// Resolve returns the singleton of type T, constructing it and its
+// dependencies on first use.
+func Resolve[T any](c *Container) (T, error) {
+	var zero T
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := reflect.TypeFor[T]()
+	v, err := c.resolve(t, nil)
+	if err != nil {
+		return zero, err
+	}
+	// A nil interface asserts to no type.
+	x, ok := v.Interface().(T)
+	if !ok {
+		return zero, &ConstructorError{Type: t, Err: errors.New("constructor returned nil")}
+	}
+	return x, nil
+}
+
+// MustResolve is like Resolve but panics on error.
+func MustResolve[T any](c *Container) T {
+	v, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Has reports whether a provider or instance is registered for T.
+func Has[T any](c *Container) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.providers[reflect.TypeFor[T]()]
+	return ok
+}
+
+func (c *Container) add(p *provider) error {
+	if _, exists := c.providers[p.key]; exists {
+		return fmt.Errorf("container: %s is already registered", p.key)
+	}
+	c.providers[p.key] = p
+	// The graph was acyclic before this provider was added, so any cycle
+	// must pass through it.
+	if path := c.findCycle(p.key); path != nil {
+		delete(c.providers, p.key)
+		return &CycleError{Path: path}
+	}
+	c.keys = append(c.keys, p.key)
+	return nil
+}
+
+// findCycle walks the registered providers depth-first from start and
+// returns the path back to start, if there is one.
+func (c *Container) findCycle(start reflect.Type) []reflect.Type {
+	visited := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type, path []reflect.Type) []reflect.Type
+	walk = func(t reflect.Type, path []reflect.Type) []reflect.Type {
+		p, ok := c.providers[t]
+		if !ok {
+			return nil
+		}
+		path = append(path, t)
+		for _, dep := range p.deps {
+			if dep == start {
+				return append(path, start)
+			}
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			if cycle := walk(dep, path); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return walk(start, nil)
+}
+
+// resolve builds t, recording the chain of dependents so failures can
+// explain why t was needed.
+func (c *Container) resolve(t reflect.Type, chain []reflect.Type) (reflect.Value, error) {
+	if v, ok := c.instances[t]; ok {
+		return v, nil
+	}
+	for _, seen := range chain {
+		if seen == t {
+			return reflect.Value{}, &CycleError{Path: append(append([]reflect.Type(nil), chain...), t)}
+		}
+	}
+	p, ok := c.providers[t]
+	if !ok {
+		return reflect.Value{}, &MissingError{Type: t, RequiredBy: chain}
+	}
+
+	chain = append(chain, t)
+	args := make([]reflect.Value, len(p.deps))
+	for i, dep := range p.deps {
+		v, err := c.resolve(dep, chain)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		args[i] = v
+	}
+
+	out := p.fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, &ConstructorError{Type: t, Err: out[1].Interface().(error)}
+	}
+	c.instances[t] = out[0]
+	c.order = append(c.order, t)
+	return out[0], nil
+}
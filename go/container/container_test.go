@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type database struct{ dsn string }
+type repository struct{ db *database }
+type service struct{ repo *repository }
+
+func newDatabase() *database                 { return &database{dsn: "memory"} }
+func newRepository(db *database) *repository { return &repository{db: db} }
+func newService(repo *repository) *service   { return &service{repo: repo} }
+
+func TestResolveBuildsDependencies(t *testing.T) {
+	c := New()
+	c.MustProvide(newService)
+	c.MustProvide(newRepository)
+	c.MustProvide(newDatabase)
+
+	svc, err := Resolve[*service](c)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if svc.repo.db.dsn != "memory" {
+		t.Fatalf("unexpected wiring: %+v", svc.repo.db)
+	}
+	if again := MustResolve[*service](c); again != svc {
+		t.Fatal("expected singleton instance")
+	}
+}
+
+func TestRegisterInstance(t *testing.T) {
+	c := New()
+	db := &database{dsn: "postgres://test"}
+	if err := Register(c, db); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	c.MustProvide(newRepository)
+	if got := MustResolve[*repository](c).db; got != db {
+		t.Fatalf("got %p, want %p", got, db)
+	}
+	if err := Register(c, db); err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+}
+
+type cycleA struct{}
+type cycleB struct{}
+type cycleC struct{}
+
+func TestProvideDetectsCycle(t *testing.T) {
+	c := New()
+	c.MustProvide(func(*cycleB) *cycleA { return nil })
+	c.MustProvide(func(*cycleC) *cycleB { return nil })
+
+	err := c.Provide(func(*cycleA) *cycleC { return nil })
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("expected CycleError, got %v", err)
+	}
+	want := "*container.cycleC -> *container.cycleA -> *container.cycleB -> *container.cycleC"
+	if !strings.HasSuffix(err.Error(), want) {
+		t.Fatalf("got %q, want path %q", err, want)
+	}
+	if Has[*cycleC](c) {
+		t.Fatal("rejected provider must not stay registered")
+	}
+}
+
+func TestProvideDetectsSelfCycle(t *testing.T) {
+	c := New()
+	err := c.Provide(func(*cycleA) *cycleA { return nil })
+	var cycle *CycleError
+	if !errors.As(err, &cycle) || len(cycle.Path) != 2 {
+		t.Fatalf("expected self cycle, got %v", err)
+	}
+}
+
+func TestResolveMissingDependencyReportsChain(t *testing.T) {
+	c := New()
+	c.MustProvide(newService)
+	c.MustProvide(newRepository)
+
+	_, err := Resolve[*service](c)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	want := "no provider for *container.database (required by *container.service -> *container.repository)"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("got %q, want %q", err, want)
+	}
+}
+
+func TestResolveWrapsConstructorError(t *testing.T) {
+	boom := errors.New("boom")
+	c := New()
+	c.MustProvide(func() (*database, error) { return nil, boom })
+
+	_, err := Resolve[*database](c)
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected wrapped constructor error, got %v", err)
+	}
+}
+
+func TestResolveNilInterface(t *testing.T) {
+	c := New()
+	c.MustProvide(func() (fmt.Stringer, error) { return nil, nil })
+
+	_, err := Resolve[fmt.Stringer](c)
+	var ce *ConstructorError
+	if !errors.As(err, &ce) || ce.Type != reflect.TypeFor[fmt.Stringer]() {
+		t.Fatalf("expected constructor error, got %v", err)
+	}
+}
+
+func TestProvideRejectsInvalidConstructors(t *testing.T) {
+	c := New()
+	for _, fn := range []any{
+		42,
+		func() {},
+		func() (*database, *repository) { return nil, nil },
+		func(...int) *database { return nil },
+	} {
+		if err := c.Provide(fn); err == nil {
+			t.Errorf("Provide(%T) succeeded, want error", fn)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrNotFound is matched by errors.Is when a dependency has no provider.
+var ErrNotFound = errors.New("container: dependency not found")
+
+// CycleError reports a dependency cycle. Path starts and ends with the
+// same type, e.g. A -> B -> C -> A.
+type CycleError struct {
+	Path []reflect.Type
+}
+
+func (e *CycleError) Error() string {
+	return "container: dependency cycle detected: " + formatChain(e.Path)
+}
+
+// MissingError reports a dependency without a provider, together with the
+// chain of types that required it.
+type MissingError struct {
+	Type       reflect.Type
+	RequiredBy []reflect.Type
+}
+
+func (e *MissingError) Error() string {
+	if len(e.RequiredBy) == 0 {
+		return fmt.Sprintf("container: no provider for %s", e.Type)
+	}
+	return fmt.Sprintf("container: no provider for %s (required by %s)", e.Type, formatChain(e.RequiredBy))
+}
+
+func (e *MissingError) Is(target error) bool { return target == ErrNotFound }
+
+// ConstructorError wraps an error returned by a constructor.
+type ConstructorError struct {
+	Type reflect.Type
+	Err  error
+}
+
+func (e *ConstructorError) Error() string {
+	return fmt.Sprintf("container: constructing %s: %v", e.Type, e.Err)
+}
+
+func (e *ConstructorError) Unwrap() error { return e.Err }
+
+func formatChain(types []reflect.Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Node kinds reported in a Graph.
+const (
+	KindConstructor = "constructor"
+	KindInstance    = "instance"
+	KindMissing     = "missing"
+)
+
+// Graph is a snapshot of the container's wiring.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a registered type, or a dependency nobody provides.
+type GraphNode struct {
+	Type     string `json:"type"`
+	Kind     string `json:"kind"`
+	Resolved bool   `json:"resolved"`
+}
+
+// GraphEdge states that From depends on To.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph returns the dependency graph of every registered type in
+// registration order. Dependencies without a provider are included as
+// KindMissing nodes so unintended requirements show up in the rendering.
+func (c *Container) Graph() Graph {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var g Graph
+	seen := make(map[reflect.Type]bool)
+	var missing []reflect.Type
+	for _, key := range c.keys {
+		p := c.providers[key]
+		kind := KindConstructor
+		if !p.fn.IsValid() {
+			kind = KindInstance
+		}
+		_, resolved := c.instances[key]
+		g.Nodes = append(g.Nodes, GraphNode{Type: key.String(), Kind: kind, Resolved: resolved})
+		seen[key] = true
+		for _, dep := range p.deps {
+			g.Edges = append(g.Edges, GraphEdge{From: key.String(), To: dep.String()})
+			if _, ok := c.providers[dep]; !ok {
+				missing = append(missing, dep)
+			}
+		}
+	}
+	for _, dep := range missing {
+		if !seen[dep] {
+			seen[dep] = true
+			g.Nodes = append(g.Nodes, GraphNode{Type: dep.String(), Kind: KindMissing})
+		}
+	}
+	return g
+}
+
+// DOT renders the graph in Graphviz format. Unresolved nodes are dashed
+// and missing dependencies are drawn in red.
+func (g Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph container {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, n := range g.Nodes {
+		var attrs []string
+		switch {
+		case n.Kind == KindMissing:
+			attrs = append(attrs, "color=red", "style=dashed")
+		case !n.Resolved:
+			attrs = append(attrs, "style=dashed")
+		}
+		if n.Kind == KindInstance {
+			attrs = append(attrs, "shape=ellipse")
+		}
+		if len(attrs) == 0 {
+			fmt.Fprintf(&b, "\t%s;\n", strconv.Quote(n.Type))
+		} else {
+			fmt.Fprintf(&b, "\t%s [%s];\n", strconv.Quote(n.Type), strings.Join(attrs, ", "))
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s;\n", strconv.Quote(e.From), strconv.Quote(e.To))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// JSON renders the graph as indented JSON.
+func (g Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// GraphDOT is shorthand for c.Graph().DOT().
+func (c *Container) GraphDOT() string {
+	return c.Graph().DOT()
+}
+
+// GraphJSON is shorthand for c.Graph().JSON().
+func (c *Container) GraphJSON() ([]byte, error) {
+	return c.Graph().JSON()
+}
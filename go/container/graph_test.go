@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGraphReportsEdgesAndMissingDependencies(t *testing.T) {
+	c := New()
+	c.MustProvide(newService)
+	c.MustProvide(newRepository)
+
+	g := c.Graph()
+	if len(g.Nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %+v", g.Nodes)
+	}
+	last := g.Nodes[2]
+	if last.Type != "*container.database" || last.Kind != KindMissing {
+		t.Fatalf("expected missing database node, got %+v", last)
+	}
+	want := []GraphEdge{
+		{From: "*container.service", To: "*container.repository"},
+		{From: "*container.repository", To: "*container.database"},
+	}
+	if len(g.Edges) != len(want) || g.Edges[0] != want[0] || g.Edges[1] != want[1] {
+		t.Fatalf("got edges %+v, want %+v", g.Edges, want)
+	}
+}
+
+func TestGraphDOTAndJSON(t *testing.T) {
+	c := New()
+	if err := Register(c, newDatabase()); err != nil {
+		t.Fatal(err)
+	}
+	c.MustProvide(newRepository)
+	MustResolve[*repository](c)
+
+	dot := c.GraphDOT()
+	for _, want := range []string{
+		`digraph container {`,
+		`"*container.database" [shape=ellipse];`,
+		`"*container.repository";`,
+		`"*container.repository" -> "*container.database";`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, dot)
+		}
+	}
+
+	data, err := c.GraphJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g Graph
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 2 || !g.Nodes[1].Resolved || g.Nodes[0].Kind != KindInstance {
+		t.Fatalf("unexpected JSON graph: %s", data)
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+)
+
+// Starter is implemented by components that need to do work, such as
+// opening connections, before the application serves traffic.
+type Starter interface {
+	OnStart(ctx context.Context) error
+}
+
+// Stopper is implemented by components that hold resources which must be
+// released on shutdown.
+type Stopper interface {
+	OnStop(ctx context.Context) error
+}
+
+// Default timeouts applied to each component's hook.
+const (
+	DefaultStartTimeout = 15 * time.Second
+	DefaultStopTimeout  = 15 * time.Second
+)
+
+// component is a resolved value together with its registered type.
+type component struct {
+	key   reflect.Type
+	value any
+}
+
+// components resolves every registered type and returns them in
+// instantiation order, so each component appears after its dependencies.
+func (c *Container) components() ([]component, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range c.keys {
+		if _, err := c.resolve(key, nil); err != nil {
+			return nil, err
+		}
+	}
+	out := make([]component, 0, len(c.order))
+	for _, key := range c.order {
+		out = append(out, component{key: key, value: c.instances[key].Interface()})
+	}
+	return out, nil
+}
+
+// AppOption configures an App.
+type AppOption func(*App)
+
+// WithStartTimeout bounds how long each component's OnStart may run.
+func WithStartTimeout(d time.Duration) AppOption {
+	return func(a *App) { a.startTimeout = d }
+}
+
+// WithStopTimeout bounds how long each component's OnStop may run.
+func WithStopTimeout(d time.Duration) AppOption {
+	return func(a *App) { a.stopTimeout = d }
+}
+
+// WithSignals replaces the signals that trigger shutdown in Run.
+func WithSignals(sig ...os.Signal) AppOption {
+	return func(a *App) { a.signals = sig }
+}
+
+// App runs the components of a container: it starts them in dependency
+// order and stops them in reverse.
+type App struct {
+	container    *Container
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+	signals      []os.Signal
+	started      []component
+}
+
+// NewApp creates an App for the given container.
+func NewApp(c *Container, opts ...AppOption) *App {
+	a := &App{
+		container:    c,
+		startTimeout: DefaultStartTimeout,
+		stopTimeout:  DefaultStopTimeout,
+		signals:      []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Start resolves every registered component and calls OnStart on those
+// implementing Starter. If a component fails to start, the components
+// already started are stopped before the error is returned.
+func (a *App) Start(ctx context.Context) error {
+	comps, err := a.container.components()
+	if err != nil {
+		return err
+	}
+	for _, comp := range comps {
+		if s, ok := comp.value.(Starter); ok {
+			if err := a.runHook(ctx, a.startTimeout, s.OnStart); err != nil {
+				startErr := fmt.Errorf("container: starting %s: %w", comp.key, err)
+				return errors.Join(startErr, a.Stop(context.WithoutCancel(ctx)))
+			}
+		}
+		a.started = append(a.started, comp)
+	}
+	return nil
+}
+
+// Stop calls OnStop on started components in reverse start order. Every
+// component is given its own timeout; failures are collected rather than
+// aborting the shutdown.
+func (a *App) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(a.started) - 1; i >= 0; i-- {
+		comp := a.started[i]
+		if s, ok := comp.value.(Stopper); ok {
+			if err := a.runHook(ctx, a.stopTimeout, s.OnStop); err != nil {
+				errs = append(errs, fmt.Errorf("container: stopping %s: %w", comp.key, err))
+			}
+		}
+	}
+	a.started = nil
+	return errors.Join(errs...)
+}
+
+// Run starts the application, blocks until ctx is cancelled or one of the
+// configured signals arrives, and then stops it.
+func (a *App) Run(ctx context.Context) error {
+	if err := a.Start(ctx); err != nil {
+		return err
+	}
+	sigCtx, cancel := signal.NotifyContext(ctx, a.signals...)
+	defer cancel()
+	<-sigCtx.Done()
+	return a.Stop(context.WithoutCancel(ctx))
+}
+
+// runHook calls hook with a deadline and returns early if the hook ignores
+// its context.
+func (a *App) runHook(ctx context.Context, timeout time.Duration, hook func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- hook(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type recorder struct{ events []string }
+
+type hooked struct {
+	name     string
+	rec      *recorder
+	startErr error
+	block    bool
+}
+
+func (h *hooked) OnStart(context.Context) error {
+	h.rec.events = append(h.rec.events, "start "+h.name)
+	return h.startErr
+}
+
+func (h *hooked) OnStop(ctx context.Context) error {
+	if h.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	h.rec.events = append(h.rec.events, "stop "+h.name)
+	return nil
+}
+
+type hookedDB struct{ *hooked }
+type hookedRepo struct{ *hooked }
+type hookedService struct{ *hooked }
+
+func newHookedContainer(rec *recorder, startErr error) *Container {
+	c := New()
+	c.MustProvide(func(r *hookedRepo) *hookedService {
+		return &hookedService{&hooked{name: "service", rec: rec, startErr: startErr}}
+	})
+	c.MustProvide(func(db *hookedDB) *hookedRepo {
+		return &hookedRepo{&hooked{name: "repo", rec: rec}}
+	})
+	c.MustProvide(func() *hookedDB { return &hookedDB{&hooked{name: "db", rec: rec}} })
+	return c
+}
+
+func TestAppStartsInDependencyOrderAndStopsInReverse(t *testing.T) {
+	rec := &recorder{}
+	app := NewApp(newHookedContainer(rec, nil))
+
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := app.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	want := []string{"start db", "start repo", "start service", "stop service", "stop repo", "stop db"}
+	if !slices.Equal(rec.events, want) {
+		t.Fatalf("got %v, want %v", rec.events, want)
+	}
+}
+
+func TestAppStartFailureStopsStartedComponents(t *testing.T) {
+	rec := &recorder{}
+	boom := errors.New("boom")
+	app := NewApp(newHookedContainer(rec, boom))
+
+	err := app.Start(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected start error, got %v", err)
+	}
+	want := []string{"start db", "start repo", "start service", "stop repo", "stop db"}
+	if !slices.Equal(rec.events, want) {
+		t.Fatalf("got %v, want %v", rec.events, want)
+	}
+}
+
+func TestAppStopAppliesPerComponentTimeout(t *testing.T) {
+	rec := &recorder{}
+	c := New()
+	if err := Register(c, &hooked{name: "stuck", rec: rec, block: true}); err != nil {
+		t.Fatal(err)
+	}
+	app := NewApp(c, WithStopTimeout(10*time.Millisecond))
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Stop(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+}
+
+func TestAppRunStopsWhenContextIsCancelled(t *testing.T) {
+	rec := &recorder{}
+	app := NewApp(newHookedContainer(rec, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	if err := app.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(rec.events) != 6 {
+		t.Fatalf("expected full start/stop cycle, got %v", rec.events)
+	}
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Override replaces whatever is registered for T with instance. Cached
+// singletons that depend on T, directly or transitively, are discarded so
+// they are rebuilt against the override on next resolution. Override is
+// meant for tests; see the testcontainer package for automatic restore.
+func Override[T any](c *Container, instance T) {
+	key := reflect.TypeFor[T]()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.providers[key]; !exists {
+		c.keys = append(c.keys, key)
+	}
+	c.providers[key] = &provider{key: key}
+	c.invalidate(key)
+	c.instances[key] = reflect.ValueOf(&instance).Elem()
+	c.order = append(c.order, key)
+}
+
+// invalidate drops the cached instance of key and of every type that
+// depends on it.
+func (c *Container) invalidate(key reflect.Type) {
+	stale := map[reflect.Type]bool{key: true}
+	for changed := true; changed; {
+		changed = false
+		for t, p := range c.providers {
+			if stale[t] {
+				continue
+			}
+			for _, dep := range p.deps {
+				if stale[dep] {
+					stale[t] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	for t := range stale {
+		delete(c.instances, t)
+	}
+	c.order = slices.DeleteFunc(c.order, func(t reflect.Type) bool { return stale[t] })
+}
+
+// Snapshot captures the registrations and singletons of a container.
+type Snapshot struct {
+	providers map[reflect.Type]*provider
+	instances map[reflect.Type]reflect.Value
+	keys      []reflect.Type
+	order     []reflect.Type
+}
+
+// Snapshot records the current state of the container for a later Restore.
+func (c *Container) Snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Snapshot{
+		providers: maps.Clone(c.providers),
+		instances: maps.Clone(c.instances),
+		keys:      slices.Clone(c.keys),
+		order:     slices.Clone(c.order),
+	}
+}
+
+// Restore returns the container to the state captured by s, undoing any
+// registrations and overrides made since.
+func (c *Container) Restore(s Snapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.providers = maps.Clone(s.providers)
+	c.instances = maps.Clone(s.instances)
+	c.keys = slices.Clone(s.keys)
+	c.order = slices.Clone(s.order)
+}
@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import "testing"
+
+func TestOverrideRebuildsDependents(t *testing.T) {
+	c := New()
+	c.MustProvide(newService)
+	c.MustProvide(newRepository)
+	c.MustProvide(newDatabase)
+	before := MustResolve[*service](c)
+
+	fake := &database{dsn: "fake"}
+	Override(c, fake)
+
+	after := MustResolve[*service](c)
+	if after == before {
+		t.Fatal("expected dependents of the override to be rebuilt")
+	}
+	if after.repo.db != fake {
+		t.Fatalf("got %+v, want override", after.repo.db)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	c := New()
+	c.MustProvide(newRepository)
+	c.MustProvide(newDatabase)
+	snap := c.Snapshot()
+
+	Override(c, &database{dsn: "fake"})
+	c.MustProvide(newService)
+	c.Restore(snap)
+
+	if Has[*service](c) {
+		t.Fatal("registration after snapshot survived Restore")
+	}
+	if got := MustResolve[*repository](c).db.dsn; got != "memory" {
+		t.Fatalf("got dsn %q after Restore, want original provider", got)
+	}
+}
@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package testcontainer scopes container registrations to a single test,
+// so a shared composition root can be reused with fakes swapped in.
+//
+//	func TestUserService(t *testing.T) {
+//		c := testcontainer.New(t, app.Wire())
+//		container.Override[*Database](c, fakeDB)
+//		svc := container.MustResolve[*UserService](c)
+//		...
+//	}
+package testcontainer
+
+import (
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/container"
+)
+
+// New snapshots c and restores it when the test and its subtests finish,
+// discarding any overrides or registrations made in between. A nil c
+// yields a fresh empty container.
+func New(t testing.TB, c *container.Container) *container.Container {
+	t.Helper()
+	if c == nil {
+		return container.New()
+	}
+	snap := c.Snapshot()
+	t.Cleanup(func() { c.Restore(snap) })
+	return c
+}
+
+// Override replaces T in c for the duration of the test only.
+func Override[T any](t testing.TB, c *container.Container, instance T) {
+	t.Helper()
+	snap := c.Snapshot()
+	t.Cleanup(func() { c.Restore(snap) })
+	container.Override(c, instance)
+}
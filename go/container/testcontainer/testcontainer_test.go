@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testcontainer_test
+
+import (
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/container"
+	"github.com/provide-io/provide-foundation/go/container/testcontainer"
+)
+
+type database struct{ dsn string }
+
+func TestOverrideIsRestoredAfterSubtest(t *testing.T) {
+	shared := container.New()
+	shared.MustProvide(func() *database { return &database{dsn: "postgres://prod"} })
+
+	t.Run("with fake", func(t *testing.T) {
+		c := testcontainer.New(t, shared)
+		testcontainer.Override(t, c, &database{dsn: "fake"})
+		if got := container.MustResolve[*database](c).dsn; got != "fake" {
+			t.Fatalf("got %q, want fake", got)
+		}
+	})
+
+	if got := container.MustResolve[*database](shared).dsn; got != "postgres://prod" {
+		t.Fatalf("override leaked out of subtest: %q", got)
+	}
+}
+
+func TestNewWithNilReturnsEmptyContainer(t *testing.T) {
+	c := testcontainer.New(t, nil)
+	if container.Has[*database](c) {
+		t.Fatal("expected empty container")
+	}
+}
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package app is wired by the wiregen tests.
+package app
+
+import (
+	"errors"
+	"io"
+
+	"github.com/provide-io/provide-foundation/go/container"
+)
+
+type Config struct{ DSN string }
+
+type Database struct{ cfg Config }
+
+type UserRepository struct{ db *Database }
+
+type Server struct {
+	users *UserRepository
+	out   io.Writer
+}
+
+func NewDatabase(cfg Config) (*Database, error) {
+	if cfg.DSN == "" {
+		return nil, errors.New("no DSN")
+	}
+	return &Database{cfg: cfg}, nil
+}
+
+func NewUserRepository(db *Database) *UserRepository { return &UserRepository{db: db} }
+
+func NewServer(users *UserRepository, out io.Writer) *Server {
+	return &Server{users: users, out: out}
+}
+
+func setup(cfg Config, out io.Writer) *container.Container {
+	c := container.New()
+	container.Register(c, cfg)
+	container.Register[io.Writer](c, out)
+	c.MustProvide(NewDatabase)
+	c.MustProvide(NewUserRepository)
+	c.MustProvide(NewServer)
+	return c
+}
@@ -0,0 +1,25 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cycle registers constructors that depend on each other.
+package cycle
+
+import "github.com/provide-io/provide-foundation/go/container"
+
+type A struct{}
+
+type B struct{}
+
+type Root struct{}
+
+func NewA(*B) *A       { return &A{} }
+func NewB(*A) *B       { return &B{} }
+func NewRoot(*A) *Root { return &Root{} }
+
+func setup() *container.Container {
+	c := container.New()
+	c.MustProvide(NewA)
+	c.MustProvide(NewB)
+	c.MustProvide(NewRoot)
+	return c
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package missing registers constructors the wiregen tests reject.
+package missing
+
+import "github.com/provide-io/provide-foundation/go/container"
+
+type Config struct{}
+
+type Service struct{}
+
+func NewService(Config) *Service { return &Service{} }
+
+func NewServices(...Config) []*Service { return nil }
+
+func setup() *container.Container {
+	c := container.New()
+	c.MustProvide(NewService)
+	return c
+}
+
+func literal() *container.Container {
+	c := container.New()
+	c.MustProvide(func() Config { return Config{} })
+	return c
+}
+
+func variadic() *container.Container {
+	c := container.New()
+	c.MustProvide(NewServices)
+	return c
+}
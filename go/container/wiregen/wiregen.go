@@ -0,0 +1,592 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wiregen turns container registrations into the constructor
+// calls they stand for. Given the function of a package that registers
+// constructors and instances,
+//
+//	func setup(cfg Config) *container.Container {
+//		c := container.New()
+//		container.Register(c, cfg)
+//		c.MustProvide(NewDatabase)
+//		c.MustProvide(NewUserRepository)
+//		c.MustProvide(NewServer)
+//		return c
+//	}
+//
+// it generates the same graph as plain Go, dependencies first:
+//
+//	func Build(config Config) (*Server, error) {
+//		database, err := NewDatabase(config)
+//		if err != nil {
+//			return nil, fmt.Errorf("constructing *Database: %w", err)
+//		}
+//		userRepository := NewUserRepository(database)
+//		server := NewServer(userRepository)
+//		return server, nil
+//	}
+//
+// Registered instances become parameters, and the results are the roots
+// of the graph: the types nothing else depends on, or the ones asked for.
+// Constructors must be named functions; the graph is checked like the
+// container checks it, for missing dependencies and cycles. The generated
+// code does not run OnStart and OnStop hooks; that is left to the caller.
+// cmd/foundation runs the generator as "foundation gen wire".
+package wiregen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// containerPath is the import path of the container package.
+const containerPath = "github.com/provide-io/provide-foundation/go/container"
+
+// DefaultName is the name of the generated function.
+const DefaultName = "Build"
+
+// Header starts the files written by the generator; files starting with
+// it are skipped when reading a package, so output can be regenerated.
+const Header = "// Code generated by foundation gen wire. DO NOT EDIT."
+
+// Config selects the registrations to generate code for.
+type Config struct {
+	// Dir is the directory of the package; the current one when empty.
+	Dir string
+	// Func is the top-level function holding the registrations.
+	Func string
+	// Name is the name of the generated function; DefaultName when empty.
+	Name string
+	// Roots are the types the generated function returns, written as in
+	// the package, such as "*Server" or "*db.DB". By default they are the
+	// provided types no other constructor depends on.
+	Roots []string
+}
+
+// node is a registered type: built by a constructor, or passed in as an
+// instance.
+type node struct {
+	typ      types.Type
+	fn       *types.Func // nil for instances
+	deps     []types.Type
+	fallible bool // fn returns (T, error)
+	pos      token.Position
+}
+
+// Generate returns the source of a file in the package of cfg.Dir
+// declaring the function that builds the graph registered in cfg.Func.
+func Generate(cfg Config) ([]byte, error) {
+	if cfg.Func == "" {
+		return nil, errors.New("wiregen: no function given")
+	}
+	if cfg.Name == "" {
+		cfg.Name = DefaultName
+	}
+	pkg, files, info, fset, err := load(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	decl := findFunc(files, cfg.Func)
+	if decl == nil {
+		return nil, fmt.Errorf("wiregen: function %s not found in package %s", cfg.Func, pkg.Name())
+	}
+	nodes, err := registrations(decl, info, fset)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{pkg: pkg, nodes: nodes, imports: map[string]string{}}
+	roots, err := g.roots(cfg.Roots)
+	if err != nil {
+		return nil, err
+	}
+	order, err := g.sort(roots)
+	if err != nil {
+		return nil, err
+	}
+	return g.emit(cfg, roots, order)
+}
+
+// load parses and type-checks the package in dir.
+func load(dir string) (*types.Package, []*ast.File, *types.Info, *token.FileSet, error) {
+	if dir == "" {
+		dir = "."
+	}
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("wiregen: %w", err)
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("wiregen: %w", err)
+		}
+		if generated(f) {
+			continue
+		}
+		files = append(files, f)
+	}
+	info := &types.Info{
+		Types:     map[ast.Expr]types.TypeAndValue{},
+		Uses:      map[*ast.Ident]types.Object{},
+		Instances: map[*ast.Ident]types.Instance{},
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(bp.ImportPath, fset, files, info)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("wiregen: %w", err)
+	}
+	return pkg, files, info, fset, nil
+}
+
+// generated reports whether f was written by the generator.
+func generated(f *ast.File) bool {
+	return len(f.Comments) > 0 && f.Comments[0].Pos() < f.Package &&
+		strings.HasPrefix(f.Comments[0].List[0].Text, Header)
+}
+
+func findFunc(files []*ast.File, name string) *ast.FuncDecl {
+	for _, f := range files {
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == name {
+				return fd
+			}
+		}
+	}
+	return nil
+}
+
+// registrations collects the Provide, MustProvide and Register calls in
+// decl, in order.
+func registrations(decl *ast.FuncDecl, info *types.Info, fset *token.FileSet) ([]*node, error) {
+	var nodes []*node
+	var errs []error
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		pos := fset.Position(call.Pos())
+		switch {
+		case isProvide(call, info):
+			fn := funcOf(call.Args[0], info)
+			if fn == nil {
+				errs = append(errs, fmt.Errorf("%s: wiregen: constructor must be a named function", pos))
+				return true
+			}
+			nd, err := constructor(fn, pos)
+			if err != nil {
+				errs = append(errs, err)
+				return true
+			}
+			nodes = append(nodes, nd)
+		case isRegister(call, info):
+			id := calleeIdent(call.Fun)
+			inst, ok := info.Instances[id]
+			if !ok || inst.TypeArgs.Len() != 1 {
+				errs = append(errs, fmt.Errorf("%s: wiregen: cannot tell the type of the registered instance", pos))
+				return true
+			}
+			nodes = append(nodes, &node{typ: inst.TypeArgs.At(0), pos: pos})
+		}
+		return true
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("wiregen: %s registers nothing", decl.Name.Name)
+	}
+	for i, nd := range nodes {
+		for _, prev := range nodes[:i] {
+			if types.Identical(prev.typ, nd.typ) {
+				return nil, fmt.Errorf("%s: wiregen: %s is already registered at %s", nd.pos, nd.typ, prev.pos)
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// isProvide reports whether call is c.Provide(f) or c.MustProvide(f) on
+// a *container.Container.
+func isProvide(call *ast.CallExpr, info *types.Info) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 1 {
+		return false
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	if !ok || (fn.Name() != "Provide" && fn.Name() != "MustProvide") {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	return recv != nil && isContainerType(recv.Type(), "Container")
+}
+
+// isRegister reports whether call is container.Register(c, v).
+func isRegister(call *ast.CallExpr, info *types.Info) bool {
+	id := calleeIdent(call.Fun)
+	if id == nil {
+		return false
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	return ok && fn.Name() == "Register" && fn.Pkg() != nil && fn.Pkg().Path() == containerPath
+}
+
+func isContainerType(t types.Type, name string) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Name() == name && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == containerPath
+}
+
+// calleeIdent returns the identifier naming the called function, through
+// a package selector and explicit type arguments.
+func calleeIdent(e ast.Expr) *ast.Ident {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.IndexExpr:
+		return calleeIdent(e.X)
+	case *ast.IndexListExpr:
+		return calleeIdent(e.X)
+	}
+	return nil
+}
+
+// funcOf returns the package-level function e names, or nil.
+func funcOf(e ast.Expr, info *types.Info) *types.Func {
+	id := calleeIdent(e)
+	if id == nil {
+		return nil
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Type().(*types.Signature).Recv() != nil {
+		return nil
+	}
+	return fn
+}
+
+// constructor checks fn the way Container.Provide does.
+func constructor(fn *types.Func, pos token.Position) (*node, error) {
+	sig := fn.Type().(*types.Signature)
+	res := sig.Results()
+	nd := &node{fn: fn, pos: pos}
+	switch {
+	case res.Len() == 1:
+	case res.Len() == 2 && types.Identical(res.At(1).Type(), types.Universe.Lookup("error").Type()):
+		nd.fallible = true
+	default:
+		return nil, fmt.Errorf("%s: wiregen: constructor %s must return T or (T, error)", pos, fn.Name())
+	}
+	if sig.Variadic() {
+		return nil, fmt.Errorf("%s: wiregen: constructor %s must not be variadic", pos, fn.Name())
+	}
+	if sig.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("%s: wiregen: constructor %s must not be generic", pos, fn.Name())
+	}
+	nd.typ = res.At(0).Type()
+	for i := range sig.Params().Len() {
+		nd.deps = append(nd.deps, sig.Params().At(i).Type())
+	}
+	return nd, nil
+}
+
+type generator struct {
+	pkg     *types.Package
+	nodes   []*node
+	imports map[string]string // path -> name
+	names   map[string]bool   // identifiers in use in the function
+}
+
+func (g *generator) lookup(t types.Type) *node {
+	for _, nd := range g.nodes {
+		if types.Identical(nd.typ, t) {
+			return nd
+		}
+	}
+	return nil
+}
+
+// roots returns the nodes named by want, or the constructed nodes no
+// other node depends on.
+func (g *generator) roots(want []string) ([]*node, error) {
+	var roots []*node
+	if len(want) > 0 {
+		for _, name := range want {
+			var found *node
+			for _, nd := range g.nodes {
+				if types.TypeString(nd.typ, types.RelativeTo(g.pkg)) == name {
+					found = nd
+				}
+			}
+			if found == nil {
+				return nil, fmt.Errorf("wiregen: %s is not registered", name)
+			}
+			roots = append(roots, found)
+		}
+		return roots, nil
+	}
+	for _, nd := range g.nodes {
+		if nd.fn == nil {
+			continue
+		}
+		used := false
+		for _, other := range g.nodes {
+			if slices.ContainsFunc(other.deps, func(t types.Type) bool { return types.Identical(t, nd.typ) }) {
+				used = true
+			}
+		}
+		if !used {
+			roots = append(roots, nd)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("wiregen: every constructed type is a dependency; name the roots")
+	}
+	return roots, nil
+}
+
+// sort returns the nodes the roots need, dependencies first, failing on
+// missing dependencies and cycles.
+func (g *generator) sort(roots []*node) ([]*node, error) {
+	var order []*node
+	done := map[*node]bool{}
+	var visit func(nd *node, chain []*node) error
+	visit = func(nd *node, chain []*node) error {
+		if done[nd] {
+			return nil
+		}
+		if i := slices.Index(chain, nd); i >= 0 {
+			return fmt.Errorf("wiregen: dependency cycle detected: %s", g.chain(append(chain[i:], nd)))
+		}
+		chain = append(chain, nd)
+		for _, dep := range nd.deps {
+			d := g.lookup(dep)
+			if d == nil {
+				return fmt.Errorf("wiregen: no provider for %s (required by %s)",
+					types.TypeString(dep, types.RelativeTo(g.pkg)), g.chain(chain))
+			}
+			if err := visit(d, chain); err != nil {
+				return err
+			}
+		}
+		done[nd] = true
+		order = append(order, nd)
+		return nil
+	}
+	for _, r := range roots {
+		if err := visit(r, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func (g *generator) chain(nodes []*node) string {
+	names := make([]string, len(nodes))
+	for i, nd := range nodes {
+		names[i] = types.TypeString(nd.typ, types.RelativeTo(g.pkg))
+	}
+	return strings.Join(names, " -> ")
+}
+
+// qualifier names the packages of types in the generated file, importing
+// them under their own name or, on a clash, a numbered one.
+func (g *generator) qualifier(p *types.Package) string {
+	if p == g.pkg {
+		return ""
+	}
+	return g.use(p.Path(), p.Name())
+}
+
+func (g *generator) use(path, name string) string {
+	if n, ok := g.imports[path]; ok {
+		return n
+	}
+	taken := func(n string) bool {
+		for _, other := range g.imports {
+			if other == n {
+				return true
+			}
+		}
+		return g.pkg.Scope().Lookup(n) != nil
+	}
+	n := name
+	for i := 2; taken(n); i++ {
+		n = name + strconv.Itoa(i)
+	}
+	g.imports[path] = n
+	return n
+}
+
+// varName returns an unused identifier for a value of type t, after its
+// type name: database for *Database, userRepository for UserRepository.
+func (g *generator) varName(t types.Type) string {
+	for {
+		if p, ok := t.(*types.Pointer); ok {
+			t = p.Elem()
+			continue
+		}
+		break
+	}
+	base := "v"
+	if n, ok := t.(*types.Named); ok {
+		r := []rune(n.Obj().Name())
+		i := 0
+		for i < len(r) && unicode.IsUpper(r[i]) {
+			i++
+		}
+		if i > 1 && i < len(r) {
+			i-- // keep the start of the next word: HTTPClient -> httpClient
+		}
+		base = strings.ToLower(string(r[:i])) + string(r[i:])
+	}
+	if token.IsKeyword(base) || types.Universe.Lookup(base) != nil {
+		base += "_"
+	}
+	name := base
+	for i := 2; g.names[name] || g.pkg.Scope().Lookup(name) != nil || slices.Contains(g.importNames(), name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.names[name] = true
+	return name
+}
+
+func (g *generator) importNames() []string {
+	names := make([]string, 0, len(g.imports))
+	for _, n := range g.imports {
+		names = append(names, n)
+	}
+	return names
+}
+
+// zero returns the zero value of t as an expression.
+func (g *generator) zero(t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		}
+	case *types.Struct, *types.Array:
+		return types.TypeString(t, g.qualifier) + "{}"
+	}
+	return "nil"
+}
+
+func (g *generator) emit(cfg Config, roots, order []*node) ([]byte, error) {
+	g.names = map[string]bool{"err": true}
+	fallible := slices.ContainsFunc(order, func(nd *node) bool { return nd.fallible })
+	if fallible {
+		g.use("fmt", "fmt")
+	}
+	// Import every package first, so variables do not take their names.
+	for _, nd := range order {
+		types.TypeString(nd.typ, g.qualifier)
+		if nd.fn != nil && nd.fn.Pkg() != g.pkg {
+			g.use(nd.fn.Pkg().Path(), nd.fn.Pkg().Name())
+		}
+	}
+	vars := map[*node]string{}
+	var params, results, zeros, values []string
+	for _, nd := range order {
+		if nd.fn == nil {
+			vars[nd] = g.varName(nd.typ)
+			params = append(params, vars[nd]+" "+types.TypeString(nd.typ, g.qualifier))
+		}
+	}
+	for _, r := range roots {
+		results = append(results, types.TypeString(r.typ, g.qualifier))
+		zeros = append(zeros, g.zero(r.typ))
+	}
+
+	var body bytes.Buffer
+	for _, nd := range order {
+		if nd.fn == nil {
+			continue
+		}
+		vars[nd] = g.varName(nd.typ)
+		args := make([]string, len(nd.deps))
+		for i, dep := range nd.deps {
+			args[i] = vars[g.lookup(dep)]
+		}
+		call := nd.fn.Name()
+		if nd.fn.Pkg() != g.pkg {
+			call = g.use(nd.fn.Pkg().Path(), nd.fn.Pkg().Name()) + "." + call
+		}
+		call += "(" + strings.Join(args, ", ") + ")"
+		if !nd.fallible {
+			fmt.Fprintf(&body, "\t%s := %s\n", vars[nd], call)
+			continue
+		}
+		fmt.Fprintf(&body, "\t%s, err := %s\n\tif err != nil {\n", vars[nd], call)
+		fmt.Fprintf(&body, "\t\treturn %sfmt.Errorf(%q, err)\n\t}\n",
+			strings.Join(append(slices.Clone(zeros), ""), ", "),
+			"constructing "+types.TypeString(nd.typ, types.RelativeTo(g.pkg))+": %w")
+	}
+	for _, r := range roots {
+		values = append(values, vars[r])
+	}
+	if fallible {
+		results = append(results, "error")
+		values = append(values, "nil")
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s\n\npackage %s\n\n", Header, g.pkg.Name())
+	switch paths := slices.Sorted(maps.Keys(g.imports)); len(paths) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&out, "import %s\n\n", g.importSpec(paths[0]))
+	default:
+		out.WriteString("import (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&out, "\t%s\n", g.importSpec(path))
+		}
+		out.WriteString(")\n\n")
+	}
+	fmt.Fprintf(&out, "// %s constructs the components registered in %s with plain\n// constructor calls, dependencies first.\n", cfg.Name, cfg.Func)
+	resultList := strings.Join(results, ", ")
+	if len(results) > 1 {
+		resultList = "(" + resultList + ")"
+	}
+	fmt.Fprintf(&out, "func %s(%s) %s {\n", cfg.Name, strings.Join(params, ", "), resultList)
+	out.Write(body.Bytes())
+	fmt.Fprintf(&out, "\treturn %s\n}\n", strings.Join(values, ", "))
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("wiregen: formatting output: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// importSpec returns the import declaration of path, naming it only if
+// it is imported under another name than its own.
+func (g *generator) importSpec(path string) string {
+	if name := g.imports[path]; name != filepath.Base(path) {
+		return name + " " + strconv.Quote(path)
+	}
+	return strconv.Quote(path)
+}
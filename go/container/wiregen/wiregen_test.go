@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package wiregen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/testkit"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(Config{Dir: "testdata/app", Func: "setup"})
+	testkit.Require(t).NoError(err)
+	testkit.GoldenString(t, "app", string(src))
+	typeCheck(t, "testdata/app", src)
+}
+
+func TestGenerateRoots(t *testing.T) {
+	src, err := Generate(Config{Dir: "testdata/app", Func: "setup", Name: "NewRepository", Roots: []string{"*UserRepository", "Config"}})
+	testkit.Require(t).NoError(err)
+	testkit.GoldenString(t, "roots", string(src))
+	typeCheck(t, "testdata/app", src)
+
+	_, err = Generate(Config{Dir: "testdata/app", Func: "setup", Roots: []string{"*Cache"}})
+	testkit.ErrorContains(t, err, "*Cache is not registered")
+}
+
+// TestGenerateSkipsOutput checks that a previous output in the package
+// does not stop it from being regenerated.
+func TestGenerateSkipsOutput(t *testing.T) {
+	dir := t.TempDir()
+	app, err := os.ReadFile("testdata/app/app.go")
+	testkit.Require(t).NoError(err)
+	src, err := Generate(Config{Dir: "testdata/app", Func: "setup"})
+	testkit.Require(t).NoError(err)
+	testkit.Require(t).NoError(os.WriteFile(filepath.Join(dir, "app.go"), app, 0o644))
+	testkit.Require(t).NoError(os.WriteFile(filepath.Join(dir, "wire_gen.go"), src, 0o644))
+
+	again, err := Generate(Config{Dir: dir, Func: "setup"})
+	testkit.Require(t).NoError(err)
+	testkit.Equal(t, string(again), string(src))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		dir, fn, want string
+	}{
+		{"testdata/cycle", "setup", "dependency cycle detected: *A -> *B -> *A"},
+		{"testdata/missing", "setup", "no provider for Config (required by *Service)"},
+		{"testdata/missing", "literal", "constructor must be a named function"},
+		{"testdata/missing", "variadic", "constructor NewServices must not be variadic"},
+		{"testdata/missing", "absent", "function absent not found in package missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			_, err := Generate(Config{Dir: tt.dir, Func: tt.fn})
+			testkit.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+// typeCheck checks that src compiles as part of the package in dir.
+func typeCheck(t *testing.T, dir string, src []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, 0)
+	testkit.Require(t).NoError(err)
+	gen, err := parser.ParseFile(fset, "wire_gen.go", src, 0)
+	testkit.Require(t).NoError(err)
+	files := []*ast.File{gen}
+	for _, p := range pkgs {
+		for _, f := range p.Files {
+			files = append(files, f)
+		}
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("app", fset, files, nil)
+	testkit.NoError(t, err)
+}
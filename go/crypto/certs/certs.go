@@ -0,0 +1,472 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package certs creates and manages X.509 certificates, the Go port of the
+// Python crypto.certificates module: self-signed CAs, leaf certificates
+// issued by them, subject alternative names, expiry checks, and PEM and
+// PKCS#12 serialization. It bootstraps development TLS and internal PKI:
+//
+//	ca, err := certs.NewCA("Internal CA", certs.WithOrganization("provide.io"))
+//	server, err := ca.Issue("api.internal", certs.WithAltNames("api.internal", "10.0.0.7"))
+//	err = server.WriteFiles("tls.crt", "tls.key")
+//
+// The defaults follow the Python side: ECDSA P-384 keys, one year for
+// leaf certificates and two for CAs.
+package certs
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// KeyType is the algorithm of a generated key.
+type KeyType string
+
+// Key types.
+const (
+	KeyECDSA   KeyType = "ecdsa"
+	KeyRSA     KeyType = "rsa"
+	KeyEd25519 KeyType = "ed25519"
+)
+
+// Defaults, matching the Python crypto defaults.
+const (
+	DefaultKeyType      = KeyECDSA
+	DefaultRSAKeySize   = 2048
+	DefaultValidity     = 365 * 24 * time.Hour
+	DefaultCAValidity   = 2 * DefaultValidity
+	DefaultOrganization = "Default Organization"
+)
+
+var (
+	// ErrNoKey is returned when an operation needs the private key of a
+	// certificate loaded without one.
+	ErrNoKey = errors.New("certs: private key not available")
+	// ErrNotCA is returned by Issue for a certificate that is not a CA.
+	ErrNotCA = errors.New("certs: certificate is not a CA")
+	// ErrExpired is matched by errors.Is for a certificate past its
+	// NotAfter time.
+	ErrExpired = errors.New("certs: certificate has expired")
+	// ErrNotYetValid is matched by errors.Is for a certificate before its
+	// NotBefore time.
+	ErrNotYetValid = errors.New("certs: certificate is not yet valid")
+)
+
+// Option configures a generated certificate.
+type Option func(*options)
+
+type options struct {
+	keyType      KeyType
+	rsaBits      int
+	curve        elliptic.Curve
+	validity     time.Duration
+	notBefore    time.Time
+	organization string
+	altNames     []string
+	client       bool
+}
+
+// WithKeyType sets the key algorithm. The default is ECDSA.
+func WithKeyType(t KeyType) Option {
+	return func(o *options) { o.keyType = t }
+}
+
+// WithRSAKeySize sets the size of RSA keys. The default is 2048 bits.
+func WithRSAKeySize(bits int) Option {
+	return func(o *options) { o.rsaBits = bits }
+}
+
+// WithCurve sets the curve of ECDSA keys. The default is P-384.
+func WithCurve(c elliptic.Curve) Option {
+	return func(o *options) { o.curve = c }
+}
+
+// WithValidity sets how long the certificate is valid.
+func WithValidity(d time.Duration) Option {
+	return func(o *options) { o.validity = d }
+}
+
+// WithNotBefore sets the start of the validity period. The default is a
+// minute ago, to tolerate clock skew.
+func WithNotBefore(t time.Time) Option {
+	return func(o *options) { o.notBefore = t }
+}
+
+// WithOrganization sets the subject organization.
+func WithOrganization(org string) Option {
+	return func(o *options) { o.organization = org }
+}
+
+// WithAltNames sets the subject alternative names. IP addresses, URIs
+// and email addresses are recognized; other names are DNS names. The
+// default for leaf certificates is the common name.
+func WithAltNames(names ...string) Option {
+	return func(o *options) { o.altNames = append(o.altNames, names...) }
+}
+
+// ForClient makes a certificate for TLS client authentication instead of
+// a server.
+func ForClient() Option {
+	return func(o *options) { o.client = true }
+}
+
+// Certificate is a certificate with its private key, if known, and the
+// chain of issuers that vouch for it.
+type Certificate struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+	// Chain holds the intermediate and root certificates above Cert,
+	// nearest first.
+	Chain []*x509.Certificate
+}
+
+// NewCA creates a self-signed CA certificate.
+func NewCA(commonName string, opts ...Option) (*Certificate, error) {
+	o := newOptions(DefaultCAValidity, opts)
+	key, err := generateKey(o)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template(commonName, o)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	return sign(tmpl, key, tmpl, key, nil)
+}
+
+// SelfSigned creates a self-signed leaf certificate, for a server unless
+// ForClient is given.
+func SelfSigned(commonName string, opts ...Option) (*Certificate, error) {
+	o := newOptions(DefaultValidity, opts)
+	key, err := generateKey(o)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := leafTemplate(commonName, key, o)
+	if err != nil {
+		return nil, err
+	}
+	return sign(tmpl, key, tmpl, key, nil)
+}
+
+// Issue creates a leaf certificate signed by c, for a server unless
+// ForClient is given. Its chain is c and c's chain.
+func (c *Certificate) Issue(commonName string, opts ...Option) (*Certificate, error) {
+	if c.Key == nil {
+		return nil, ErrNoKey
+	}
+	if !c.IsCA() {
+		return nil, fmt.Errorf("%w: %s", ErrNotCA, c.Cert.Subject)
+	}
+	o := newOptions(DefaultValidity, opts)
+	key, err := generateKey(o)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := leafTemplate(commonName, key, o)
+	if err != nil {
+		return nil, err
+	}
+	return sign(tmpl, key, c.Cert, c.Key, append([]*x509.Certificate{c.Cert}, c.Chain...))
+}
+
+func newOptions(validity time.Duration, opts []Option) options {
+	o := options{
+		keyType:      DefaultKeyType,
+		rsaBits:      DefaultRSAKeySize,
+		curve:        elliptic.P384(),
+		validity:     validity,
+		notBefore:    time.Now().Add(-time.Minute),
+		organization: DefaultOrganization,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func generateKey(o options) (crypto.Signer, error) {
+	switch o.keyType {
+	case KeyECDSA:
+		return ecdsa.GenerateKey(o.curve, rand.Reader)
+	case KeyRSA:
+		return rsa.GenerateKey(rand.Reader, o.rsaBits)
+	case KeyEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, fmt.Errorf("certs: unsupported key type %q", o.keyType)
+}
+
+// template returns the fields common to every certificate.
+func template(commonName string, o options) (*x509.Certificate, error) {
+	if o.validity <= 0 {
+		return nil, fmt.Errorf("certs: validity must be positive, got %s", o.validity)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return nil, fmt.Errorf("certs: serial number: %w", err)
+	}
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{o.organization}},
+		NotBefore:    o.notBefore,
+		NotAfter:     o.notBefore.Add(o.validity),
+	}, nil
+}
+
+// leafTemplate returns an end-entity template with the key usages the
+// Python side sets: key encipherment for RSA server keys and key
+// agreement for ECDSA keys.
+func leafTemplate(commonName string, key crypto.Signer, o options) (*x509.Certificate, error) {
+	tmpl, err := template(commonName, o)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		if !o.client {
+			tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+		}
+	case *ecdsa.PrivateKey:
+		tmpl.KeyUsage |= x509.KeyUsageKeyAgreement
+	}
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	if o.client {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	names := o.altNames
+	if len(names) == 0 {
+		names = []string{commonName}
+	}
+	for _, name := range names {
+		switch {
+		case net.ParseIP(name) != nil:
+			tmpl.IPAddresses = append(tmpl.IPAddresses, net.ParseIP(name))
+		case strings.Contains(name, "://"):
+			u, err := url.Parse(name)
+			if err != nil {
+				return nil, fmt.Errorf("certs: alt name %q: %w", name, err)
+			}
+			tmpl.URIs = append(tmpl.URIs, u)
+		case strings.Contains(name, "@"):
+			tmpl.EmailAddresses = append(tmpl.EmailAddresses, name)
+		case name != "":
+			tmpl.DNSNames = append(tmpl.DNSNames, name)
+		}
+	}
+	return tmpl, nil
+}
+
+func sign(tmpl *x509.Certificate, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer, chain []*x509.Certificate) (*Certificate, error) {
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, fmt.Errorf("certs: signing %s: %w", tmpl.Subject.CommonName, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	return &Certificate{Cert: cert, Key: key, Chain: chain}, nil
+}
+
+// IsCA reports whether the certificate may issue others.
+func (c *Certificate) IsCA() bool {
+	return c.Cert.BasicConstraintsValid && c.Cert.IsCA
+}
+
+// CheckValidity returns an error matching ErrNotYetValid or ErrExpired
+// when now is outside the validity period.
+func (c *Certificate) CheckValidity(now time.Time) error {
+	switch {
+	case now.Before(c.Cert.NotBefore):
+		return fmt.Errorf("%w: %s until %s", ErrNotYetValid, c.Cert.Subject, c.Cert.NotBefore.UTC().Format(time.RFC3339))
+	case now.After(c.Cert.NotAfter):
+		return fmt.Errorf("%w: %s on %s", ErrExpired, c.Cert.Subject, c.Cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ExpiresWithin reports whether the certificate expires within d, for
+// renewing it ahead of time.
+func (c *Certificate) ExpiresWithin(d time.Duration) bool {
+	return time.Until(c.Cert.NotAfter) <= d
+}
+
+// Verify checks that the certificate chains up to one of roots through
+// its own chain, and is valid now. Any extended key usage is accepted.
+func (c *Certificate) Verify(roots ...*x509.Certificate) error {
+	pool := x509.NewCertPool()
+	for _, r := range roots {
+		pool.AddCert(r)
+	}
+	inter := x509.NewCertPool()
+	for _, ic := range c.Chain {
+		inter.AddCert(ic)
+	}
+	_, err := c.Cert.Verify(x509.VerifyOptions{Roots: pool, Intermediates: inter, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	if err != nil {
+		return fmt.Errorf("certs: verifying %s: %w", c.Cert.Subject, err)
+	}
+	return nil
+}
+
+// CertPEM returns the certificate followed by its chain, PEM encoded.
+func (c *Certificate) CertPEM() []byte {
+	var buf bytes.Buffer
+	for _, cert := range append([]*x509.Certificate{c.Cert}, c.Chain...) {
+		pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.Bytes()
+}
+
+// KeyPEM returns the private key as a PEM encoded PKCS#8 block.
+func (c *Certificate) KeyPEM() ([]byte, error) {
+	if c.Key == nil {
+		return nil, ErrNoKey
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// TLSCertificate returns the certificate, chain and key for a tls.Config.
+func (c *Certificate) TLSCertificate() (tls.Certificate, error) {
+	if c.Key == nil {
+		return tls.Certificate{}, ErrNoKey
+	}
+	tc := tls.Certificate{PrivateKey: c.Key, Leaf: c.Cert}
+	for _, cert := range append([]*x509.Certificate{c.Cert}, c.Chain...) {
+		tc.Certificate = append(tc.Certificate, cert.Raw)
+	}
+	return tc, nil
+}
+
+// WriteFiles writes CertPEM to certPath and KeyPEM to keyPath, the key
+// readable only by its owner.
+func (c *Certificate) WriteFiles(certPath, keyPath string) error {
+	keyPEM, err := c.KeyPEM()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(certPath, c.CertPEM(), 0o644); err != nil {
+		return fmt.Errorf("certs: %w", err)
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		return fmt.Errorf("certs: %w", err)
+	}
+	return nil
+}
+
+// ParsePEM reads a certificate and its chain from certPEM, in the order
+// CertPEM writes them, and its private key from keyPEM, which may be nil.
+// Keys may be PKCS#8, PKCS#1 RSA or SEC 1 EC blocks.
+func ParsePEM(certPEM, keyPEM []byte) (*Certificate, error) {
+	var certs []*x509.Certificate
+	for rest := certPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certs: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("certs: no CERTIFICATE block in PEM data")
+	}
+	c := &Certificate{Cert: certs[0], Chain: certs[1:]}
+	if len(keyPEM) == 0 {
+		return c, nil
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("certs: no key block in PEM data")
+	}
+	key, err := parseKey(block)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.setKey(key); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadFiles reads a certificate and key written by WriteFiles. An empty
+// keyPath loads the certificate alone.
+func LoadFiles(certPath, keyPath string) (*Certificate, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	var keyPEM []byte
+	if keyPath != "" {
+		if keyPEM, err = os.ReadFile(keyPath); err != nil {
+			return nil, fmt.Errorf("certs: %w", err)
+		}
+	}
+	return ParsePEM(certPEM, keyPEM)
+}
+
+func parseKey(block *pem.Block) (crypto.Signer, error) {
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("certs: unsupported key block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("certs: unsupported key %T", key)
+	}
+	return signer, nil
+}
+
+// setKey sets the private key after checking it matches the certificate.
+func (c *Certificate) setKey(key crypto.Signer) error {
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(c.Cert.PublicKey) {
+		return fmt.Errorf("certs: private key does not match certificate %s", c.Cert.Subject)
+	}
+	c.Key = key
+	return nil
+}
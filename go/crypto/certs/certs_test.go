@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIssueAndServeTLS(t *testing.T) {
+	ca, err := NewCA("Test CA", WithOrganization("provide.io"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ca.IsCA() || ca.Cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		t.Fatalf("CA = %+v", ca.Cert)
+	}
+	server, err := ca.Issue("localhost", WithAltNames("localhost", "127.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(server.Chain) != 1 || server.IsCA() || server.Cert.Subject.Organization[0] != DefaultOrganization {
+		t.Errorf("server = %+v", server.Cert)
+	}
+	if err := server.Verify(ca.Cert); err != nil {
+		t.Fatal(err)
+	}
+
+	tc, err := server.TLSCertificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{tc}}
+	srv.StartTLS()
+	defer srv.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestKeyTypesAndUsages(t *testing.T) {
+	for _, tc := range []struct {
+		opts  []Option
+		check func(any) bool
+		usage x509.KeyUsage
+		ext   x509.ExtKeyUsage
+	}{
+		{nil, func(k any) bool { _, ok := k.(*ecdsa.PrivateKey); return ok },
+			x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement, x509.ExtKeyUsageServerAuth},
+		{[]Option{WithKeyType(KeyRSA)}, func(k any) bool { _, ok := k.(*rsa.PrivateKey); return ok },
+			x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment, x509.ExtKeyUsageServerAuth},
+		{[]Option{WithKeyType(KeyRSA), ForClient()}, func(k any) bool { _, ok := k.(*rsa.PrivateKey); return ok },
+			x509.KeyUsageDigitalSignature, x509.ExtKeyUsageClientAuth},
+		{[]Option{WithKeyType(KeyEd25519)}, func(k any) bool { _, ok := k.(ed25519.PrivateKey); return ok },
+			x509.KeyUsageDigitalSignature, x509.ExtKeyUsageServerAuth},
+	} {
+		c, err := SelfSigned("svc", tc.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tc.check(c.Key) || c.Cert.KeyUsage != tc.usage || c.Cert.ExtKeyUsage[0] != tc.ext {
+			t.Errorf("%T: usage %v, ext %v", c.Key, c.Cert.KeyUsage, c.Cert.ExtKeyUsage)
+		}
+		if len(c.Cert.DNSNames) != 1 || c.Cert.DNSNames[0] != "svc" {
+			t.Errorf("default SANs = %v", c.Cert.DNSNames)
+		}
+	}
+	if _, err := SelfSigned("svc", WithKeyType("dsa")); err == nil {
+		t.Error("unsupported key type accepted")
+	}
+}
+
+func TestAltNames(t *testing.T) {
+	c, err := SelfSigned("svc", WithAltNames("svc.internal", "10.0.0.7", "::1", "spiffe://cluster/ns/svc", "ops@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Cert.DNSNames) != 1 || len(c.Cert.IPAddresses) != 2 || len(c.Cert.URIs) != 1 || len(c.Cert.EmailAddresses) != 1 {
+		t.Errorf("SANs: dns %v ip %v uri %v email %v", c.Cert.DNSNames, c.Cert.IPAddresses, c.Cert.URIs, c.Cert.EmailAddresses)
+	}
+}
+
+func TestIssueNeedsCAWithKey(t *testing.T) {
+	leaf, err := SelfSigned("svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := leaf.Issue("other"); !errors.Is(err, ErrNotCA) {
+		t.Errorf("leaf issuing: %v", err)
+	}
+	ca, _ := NewCA("CA")
+	loaded, err := ParsePEM(ca.CertPEM(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loaded.Issue("svc"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("CA without key: %v", err)
+	}
+}
+
+func TestValidity(t *testing.T) {
+	start := time.Now().Add(-48 * time.Hour)
+	c, err := SelfSigned("svc", WithNotBefore(start), WithValidity(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CheckValidity(time.Now()); !errors.Is(err, ErrExpired) {
+		t.Errorf("expired: %v", err)
+	}
+	if err := c.CheckValidity(start.Add(-time.Hour)); !errors.Is(err, ErrNotYetValid) {
+		t.Errorf("not yet valid: %v", err)
+	}
+	if err := c.CheckValidity(start.Add(time.Hour)); err != nil {
+		t.Errorf("valid: %v", err)
+	}
+
+	fresh, _ := SelfSigned("svc", WithValidity(10*24*time.Hour))
+	if !fresh.ExpiresWithin(30*24*time.Hour) || fresh.ExpiresWithin(24*time.Hour) {
+		t.Error("ExpiresWithin")
+	}
+	if _, err := SelfSigned("svc", WithValidity(0)); err == nil {
+		t.Error("zero validity accepted")
+	}
+}
+
+func TestPEMFiles(t *testing.T) {
+	ca, _ := NewCA("CA")
+	leaf, err := ca.Issue("svc", WithKeyType(KeyRSA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := leaf.WriteFiles(certPath, keyPath); err != nil {
+		t.Fatal(err)
+	}
+	back, err := LoadFiles(certPath, keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !back.Cert.Equal(leaf.Cert) || len(back.Chain) != 1 || !back.Chain[0].Equal(ca.Cert) {
+		t.Errorf("loaded %v with chain %d", back.Cert.Subject, len(back.Chain))
+	}
+	if err := back.Verify(ca.Cert); err != nil {
+		t.Error(err)
+	}
+
+	other, _ := SelfSigned("other")
+	otherKey, _ := other.KeyPEM()
+	if _, err := ParsePEM(leaf.CertPEM(), otherKey); err == nil {
+		t.Error("mismatched key accepted")
+	}
+}
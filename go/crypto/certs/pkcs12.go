@@ -0,0 +1,504 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package certs
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+)
+
+// PKCS#12 files are written the way OpenSSL 3 writes them by default:
+// the key in a PBES2 shrouded key bag, encrypted with AES-256-CBC under a
+// PBKDF2-HMAC-SHA256 key, and the whole file authenticated with an
+// HMAC-SHA256.
+const pkcs12Iterations = 2048
+
+var (
+	oidData                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedData       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidKeyBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidX509Certificate     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidLocalKeyID          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	oidPBES2               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACSHA1            = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACSHA256          = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES128CBC           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES256CBC           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidSHA1                = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256              = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA512              = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type encryptedData struct {
+	Version          int
+	EncryptedContent encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Algorithm   pkix.AlgorithmIdentifier
+	Content     []byte `asn1:"tag:0,optional"`
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KDF    pkix.AlgorithmIdentifier
+	Scheme pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// PKCS12 returns the certificate, its chain and its private key as a
+// PKCS#12 (.p12, .pfx) file protected by password, for Java key stores,
+// browsers and the macOS keychain.
+func (c *Certificate) PKCS12(password string) ([]byte, error) {
+	if c.Key == nil {
+		return nil, ErrNoKey
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(c.Key)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	shrouded, err := pbes2Encrypt(keyDER, password)
+	if err != nil {
+		return nil, err
+	}
+	localKeyID := sha1.Sum(c.Cert.Raw)
+	keyAttrs, err := localKeyIDAttrs(localKeyID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	var certBags []safeBag
+	for i, cert := range append([]*x509.Certificate{c.Cert}, c.Chain...) {
+		bag, err := asn1.Marshal(certBag{ID: oidX509Certificate, Data: cert.Raw})
+		if err != nil {
+			return nil, fmt.Errorf("certs: %w", err)
+		}
+		sb := safeBag{ID: oidCertBag, Value: explicit(bag)}
+		if i == 0 {
+			sb.Attributes = keyAttrs
+		}
+		certBags = append(certBags, sb)
+	}
+	keyBag := []safeBag{{ID: oidPKCS8ShroudedKeyBag, Value: explicit(shrouded), Attributes: keyAttrs}}
+
+	var authSafe []contentInfo
+	for _, bags := range [][]safeBag{certBags, keyBag} {
+		ci, err := dataContentInfo(bags)
+		if err != nil {
+			return nil, err
+		}
+		authSafe = append(authSafe, ci)
+	}
+	authSafeDER, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	mac := hmac.New(sha256.New, pkcs12KDF(sha256.New, 64, password, salt, 3, pkcs12Iterations, sha256.Size))
+	mac.Write(authSafeDER)
+	octets, err := asn1.Marshal(authSafeDER)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	out, err := asn1.Marshal(pfxPDU{
+		Version:  3,
+		AuthSafe: contentInfo{ContentType: oidData, Content: explicit(octets)},
+		MacData: macData{
+			Mac:        digestInfo{Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}, Digest: mac.Sum(nil)},
+			MacSalt:    salt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	return out, nil
+}
+
+// explicit wraps der in the [0] EXPLICIT tag of ContentInfo and SafeBag
+// values; encoding/asn1 writes a RawValue as given, ignoring the tag in
+// the struct field.
+func explicit(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+}
+
+func localKeyIDAttrs(id []byte) ([]pkcs12Attribute, error) {
+	octets, err := asn1.Marshal(id)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	return []pkcs12Attribute{{
+		ID:    oidLocalKeyID,
+		Value: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: octets},
+	}}, nil
+}
+
+func dataContentInfo(bags []safeBag) (contentInfo, error) {
+	contents, err := asn1.Marshal(bags)
+	if err != nil {
+		return contentInfo{}, fmt.Errorf("certs: %w", err)
+	}
+	octets, err := asn1.Marshal(contents)
+	if err != nil {
+		return contentInfo{}, fmt.Errorf("certs: %w", err)
+	}
+	return contentInfo{ContentType: oidData, Content: explicit(octets)}, nil
+}
+
+// pbes2Encrypt returns an EncryptedPrivateKeyInfo holding data.
+func pbes2Encrypt(data []byte, password string) ([]byte, error) {
+	salt, iv := make([]byte, 16), make([]byte, aes.BlockSize)
+	rand.Read(salt)
+	rand.Read(iv)
+	key, err := pbkdf2.Key(sha256.New, password, salt, pkcs12Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	pad := aes.BlockSize - len(data)%aes.BlockSize
+	ciphertext := append(bytes.Clone(data), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:       salt,
+		Iterations: pkcs12Iterations,
+		PRF:        pkix.AlgorithmIdentifier{Algorithm: oidHMACSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KDF:    pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		Scheme: pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParam}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	out, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: ciphertext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	return out, nil
+}
+
+// errUnsupportedPKCS12 is returned for PKCS#12 files using legacy
+// algorithms such as RC2 and 3DES.
+var errUnsupportedPKCS12 = errors.New("certs: unsupported PKCS#12 algorithm")
+
+// ParsePKCS12 reads a PKCS#12 file protected by password. The
+// certificate is the one matching the private key, and the others form
+// its chain. Files written by PKCS12 and by OpenSSL 3 with its defaults
+// are supported; files using the legacy RC2 and 3DES encryption are not.
+func ParsePKCS12(data []byte, password string) (*Certificate, error) {
+	var pfx pfxPDU
+	if rest, err := asn1.Unmarshal(data, &pfx); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("certs: malformed PKCS#12 data: %v", err)
+	}
+	if pfx.Version != 3 || !pfx.AuthSafe.ContentType.Equal(oidData) {
+		return nil, errUnsupportedPKCS12
+	}
+	var authSafeDER []byte
+	if _, err := asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &authSafeDER); err != nil {
+		return nil, fmt.Errorf("certs: malformed PKCS#12 data: %w", err)
+	}
+	if err := verifyMAC(pfx.MacData, authSafeDER, password); err != nil {
+		return nil, err
+	}
+	var authSafe []contentInfo
+	if _, err := asn1.Unmarshal(authSafeDER, &authSafe); err != nil {
+		return nil, fmt.Errorf("certs: malformed PKCS#12 data: %w", err)
+	}
+
+	var certs []*x509.Certificate
+	var key crypto.Signer
+	for _, ci := range authSafe {
+		contents, err := safeContents(ci, password)
+		if err != nil {
+			return nil, err
+		}
+		var bags []safeBag
+		if _, err := asn1.Unmarshal(contents, &bags); err != nil {
+			return nil, fmt.Errorf("certs: malformed PKCS#12 data: %w", err)
+		}
+		for _, bag := range bags {
+			switch {
+			case bag.ID.Equal(oidCertBag):
+				var cb certBag
+				if _, err := asn1.Unmarshal(bag.Value.Bytes, &cb); err != nil || !cb.ID.Equal(oidX509Certificate) {
+					return nil, errors.New("certs: malformed PKCS#12 certificate bag")
+				}
+				cert, err := x509.ParseCertificate(cb.Data)
+				if err != nil {
+					return nil, fmt.Errorf("certs: %w", err)
+				}
+				certs = append(certs, cert)
+			case bag.ID.Equal(oidPKCS8ShroudedKeyBag), bag.ID.Equal(oidKeyBag):
+				der := bag.Value.Bytes
+				if bag.ID.Equal(oidPKCS8ShroudedKeyBag) {
+					var epki encryptedPrivateKeyInfo
+					if _, err := asn1.Unmarshal(der, &epki); err != nil {
+						return nil, fmt.Errorf("certs: malformed PKCS#12 key bag: %w", err)
+					}
+					if der, err = pbes2Decrypt(epki.Algorithm, epki.EncryptedData, password); err != nil {
+						return nil, err
+					}
+				}
+				if key, err = parseKey(&pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("certs: no certificate in PKCS#12 data")
+	}
+	leaf := 0
+	if key != nil {
+		for i, cert := range certs {
+			if pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool }); ok && pub.Equal(cert.PublicKey) {
+				leaf = i
+			}
+		}
+	}
+	c := &Certificate{Cert: certs[leaf]}
+	for i, cert := range certs {
+		if i != leaf {
+			c.Chain = append(c.Chain, cert)
+		}
+	}
+	if key != nil {
+		if err := c.setKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// safeContents returns the SafeContents of an AuthenticatedSafe entry,
+// decrypting it if needed.
+func safeContents(ci contentInfo, password string) ([]byte, error) {
+	switch {
+	case ci.ContentType.Equal(oidData):
+		var contents []byte
+		if _, err := asn1.Unmarshal(ci.Content.Bytes, &contents); err != nil {
+			return nil, fmt.Errorf("certs: malformed PKCS#12 data: %w", err)
+		}
+		return contents, nil
+	case ci.ContentType.Equal(oidEncryptedData):
+		var ed encryptedData
+		if _, err := asn1.Unmarshal(ci.Content.Bytes, &ed); err != nil {
+			return nil, fmt.Errorf("certs: malformed PKCS#12 data: %w", err)
+		}
+		return pbes2Decrypt(ed.EncryptedContent.Algorithm, ed.EncryptedContent.Content, password)
+	}
+	return nil, errUnsupportedPKCS12
+}
+
+func verifyMAC(md macData, content []byte, password string) error {
+	var h func() hash.Hash
+	var v int
+	switch alg := md.Mac.Algorithm.Algorithm; {
+	case alg.Equal(oidSHA1):
+		h, v = sha1.New, 64
+	case alg.Equal(oidSHA256):
+		h, v = sha256.New, 64
+	case alg.Equal(oidSHA512):
+		h, v = sha512.New, 128
+	default:
+		return errUnsupportedPKCS12
+	}
+	mac := hmac.New(h, pkcs12KDF(h, v, password, md.MacSalt, 3, md.Iterations, h().Size()))
+	mac.Write(content)
+	if !hmac.Equal(mac.Sum(nil), md.Mac.Digest) {
+		return errors.New("certs: PKCS#12 integrity check failed; wrong password?")
+	}
+	return nil
+}
+
+func pbes2Decrypt(alg pkix.AlgorithmIdentifier, ciphertext []byte, password string) ([]byte, error) {
+	if !alg.Algorithm.Equal(oidPBES2) {
+		return nil, errUnsupportedPKCS12
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("certs: malformed PBES2 parameters: %w", err)
+	}
+	var kdf pbkdf2Params
+	if !params.KDF.Algorithm.Equal(oidPBKDF2) {
+		return nil, errUnsupportedPKCS12
+	}
+	if _, err := asn1.Unmarshal(params.KDF.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("certs: malformed PBKDF2 parameters: %w", err)
+	}
+	var keyLen int
+	switch {
+	case params.Scheme.Algorithm.Equal(oidAES128CBC):
+		keyLen = 16
+	case params.Scheme.Algorithm.Equal(oidAES256CBC):
+		keyLen = 32
+	default:
+		return nil, errUnsupportedPKCS12
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.Scheme.Parameters.FullBytes, &iv); err != nil || len(iv) != aes.BlockSize {
+		return nil, errors.New("certs: malformed AES-CBC parameters")
+	}
+	var key []byte
+	var err error
+	switch prf := kdf.PRF.Algorithm; {
+	case len(prf) == 0, prf.Equal(oidHMACSHA1):
+		key, err = pbkdf2.Key(sha1.New, password, kdf.Salt, kdf.Iterations, keyLen)
+	case prf.Equal(oidHMACSHA256):
+		key, err = pbkdf2.Key(sha256.New, password, kdf.Salt, kdf.Iterations, keyLen)
+	default:
+		return nil, errUnsupportedPKCS12
+	}
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("certs: %w", err)
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, errors.New("certs: malformed PKCS#12 ciphertext")
+	}
+	plain := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, ciphertext)
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > aes.BlockSize || !bytes.Equal(plain[len(plain)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, errors.New("certs: PKCS#12 decryption failed; wrong password?")
+	}
+	return plain[:len(plain)-pad], nil
+}
+
+// pkcs12KDF derives a key from password as RFC 7292 appendix B.2
+// describes; PKCS#12 MACs use it with id 3. v is the block size of h.
+func pkcs12KDF(h func() hash.Hash, v int, password string, salt []byte, id byte, iterations, size int) []byte {
+	// The password is a null-terminated BMPString.
+	var pw []byte
+	for _, r := range utf16.Encode([]rune(password)) {
+		pw = append(pw, byte(r>>8), byte(r))
+	}
+	pw = append(pw, 0, 0)
+	fill := func(b []byte) []byte {
+		if len(b) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(b)+v-1)/v))
+		for i := range out {
+			out[i] = b[i%len(b)]
+		}
+		return out
+	}
+	d := bytes.Repeat([]byte{id}, v)
+	in := append(fill(salt), fill(pw)...)
+
+	var out []byte
+	for len(out) < size {
+		hh := h()
+		hh.Write(d)
+		hh.Write(in)
+		a := hh.Sum(nil)
+		for i := 1; i < iterations; i++ {
+			hh.Reset()
+			hh.Write(a)
+			a = hh.Sum(a[:0])
+		}
+		out = append(out, a...)
+		if len(out) >= size {
+			break
+		}
+		b := fill(a)
+		for j := 0; j < len(in); j += v {
+			// in[j:j+v] += b + 1, as big-endian integers.
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(in[j+k]) + int(b[k]) + carry
+				in[j+k], carry = byte(sum), sum>>8
+			}
+		}
+	}
+	return out[:size]
+}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package certs
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+)
+
+func TestPKCS12RoundTrip(t *testing.T) {
+	ca, _ := NewCA("CA")
+	for _, kt := range []KeyType{KeyECDSA, KeyRSA, KeyEd25519} {
+		leaf, err := ca.Issue("svc", WithKeyType(kt))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p12, err := leaf.PKCS12("s3cret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := ParsePKCS12(p12, "s3cret")
+		if err != nil {
+			t.Fatalf("%s: %v", kt, err)
+		}
+		if !back.Cert.Equal(leaf.Cert) || len(back.Chain) != 1 || !back.Chain[0].Equal(ca.Cert) || back.Key == nil {
+			t.Errorf("%s: got %v, chain %d, key %v", kt, back.Cert.Subject, len(back.Chain), back.Key != nil)
+		}
+		if _, err := ParsePKCS12(p12, "wrong"); err == nil {
+			t.Errorf("%s: wrong password accepted", kt)
+		}
+	}
+}
+
+// TestPKCS12KDF checks the key derivation against a published RFC 7292
+// test vector.
+func TestPKCS12KDF(t *testing.T) {
+	salt, _ := hex.DecodeString("0a58cf64530d823f")
+	got := hex.EncodeToString(pkcs12KDF(sha1.New, 64, "smeg", salt, 1, 1, 24))
+	if want := "8aaae6297b6cb04642ab5b077851284eb7128f1a2a7fbca3"; got != want {
+		t.Errorf("key = %s, want %s", got, want)
+	}
+}
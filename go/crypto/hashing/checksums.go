@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package hashing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/filex"
+)
+
+// SumFiles returns the digest of each named file, keyed by name. Names
+// are relative to dir unless absolute.
+func SumFiles(a Algorithm, dir string, names ...string) (map[string]string, error) {
+	sums := make(map[string]string, len(names))
+	for _, name := range names {
+		sum, err := File(resolve(dir, name), a)
+		if err != nil {
+			return nil, err
+		}
+		sums[name] = sum
+	}
+	return sums, nil
+}
+
+// WriteChecksums writes sums, keyed by file name, in the sha256sum binary
+// format under a comment naming the algorithm, sorted by name:
+//
+//	# SHA256 checksums
+//	# Generated by provide.foundation
+//
+//	e3b0c442...  *app.tar.gz
+func WriteChecksums(w io.Writer, a Algorithm, sums map[string]string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s checksums\n# Generated by provide.foundation\n\n", strings.ToUpper(string(a)))
+	for _, name := range slices.Sorted(maps.Keys(sums)) {
+		fmt.Fprintf(bw, "%s  *%s\n", sums[name], name)
+	}
+	return bw.Flush()
+}
+
+// ReadChecksums parses a checksum file in the sha256sum or md5sum format,
+// text or binary mode, into digests keyed by file name. Blank lines,
+// comments and lines without a name are skipped.
+func ReadChecksums(r io.Reader) (map[string]string, error) {
+	sums := make(map[string]string)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimLeft(line[i:], " \t"), "*")
+		sums[name] = strings.ToLower(line[:i])
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("hashing: %w", err)
+	}
+	return sums, nil
+}
+
+// WriteChecksumFile writes sums to path as WriteChecksums does, with
+// filex.WriteAtomic so readers never see it partial.
+func WriteChecksumFile(path string, a Algorithm, sums map[string]string) error {
+	var buf bytes.Buffer
+	WriteChecksums(&buf, a, sums)
+	if err := filex.WriteAtomic(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("hashing: %w", err)
+	}
+	return nil
+}
+
+// ReadChecksumFile parses the checksum file at path as ReadChecksums does.
+func ReadChecksumFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("hashing: %w", err)
+	}
+	defer f.Close()
+	return ReadChecksums(f)
+}
+
+// VerifyChecksumFile checks every file listed in the checksum file at
+// path against its digest under a, and returns the names that match and
+// those that do not or cannot be read, each sorted. Names are relative to
+// dir, or to the directory of the checksum file when dir is empty. The
+// error is only for a checksum file that cannot be read.
+func VerifyChecksumFile(path, dir string, a Algorithm) (verified, failed []string, err error) {
+	if _, err := a.New(); err != nil {
+		return nil, nil, err
+	}
+	sums, err := ReadChecksumFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dir == "" {
+		dir = filepath.Dir(path)
+	}
+	for _, name := range slices.Sorted(maps.Keys(sums)) {
+		got, err := File(resolve(dir, name), a)
+		if err == nil && Equal(got, sums[name]) {
+			verified = append(verified, name)
+		} else {
+			failed = append(failed, name)
+		}
+	}
+	return verified, failed, nil
+}
+
+func resolve(dir, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package hashing
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestChecksumFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644)
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0o644)
+	os.WriteFile(filepath.Join(dir, "c.txt"), []byte("c"), 0o644)
+
+	sums, err := SumFiles(SHA256, dir, "a.txt", "b.txt", "c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "SHA256SUMS")
+	if err := WriteChecksumFile(path, SHA256, sums); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "# SHA256 checksums\n# Generated by provide.foundation\n\n" + sums["a.txt"] + "  *a.txt\n"
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("checksum file =\n%s\nwant prefix\n%s", data, want)
+	}
+
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("tampered"), 0o644)
+	os.Remove(filepath.Join(dir, "c.txt"))
+	verified, failed, err := VerifyChecksumFile(path, "", SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(verified, []string{"a.txt"}) || !slices.Equal(failed, []string{"b.txt", "c.txt"}) {
+		t.Errorf("verified %v, failed %v", verified, failed)
+	}
+}
+
+func TestReadChecksumsFormats(t *testing.T) {
+	in := `# comment
+D41D8CD98F00B204E9800998ECF8427E  empty.txt
+900150983cd24fb0d6963f7d28e17f72 *abc.bin
+0cc175b9c0f1b6a831c399e269772661 single space.txt
+
+malformed
+`
+	sums, err := ReadChecksums(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"empty.txt":        "d41d8cd98f00b204e9800998ecf8427e",
+		"abc.bin":          "900150983cd24fb0d6963f7d28e17f72",
+		"single space.txt": "0cc175b9c0f1b6a831c399e269772661",
+	}
+	if len(sums) != len(want) {
+		t.Errorf("ReadChecksums = %v, want %v", sums, want)
+	}
+	for name, sum := range want {
+		if sums[name] != sum {
+			t.Errorf("%s = %q, want %q", name, sums[name], sum)
+		}
+	}
+}
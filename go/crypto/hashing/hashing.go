@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hashing computes digests of byte slices, readers and files, the
+// Go port of the Python crypto hashing and checksum modules. Digests are
+// lowercase hex strings, as hashlib's hexdigest returns them:
+//
+//	sum, err := hashing.File("dist/app.tar.gz", hashing.SHA256)
+//	sums, err := hashing.Multi(r, hashing.SHA256, hashing.BLAKE2b)
+//	ok, err := hashing.Verify(r, "sha256:dffd6021...")
+//
+// Input is streamed, so files of any size are hashed in constant memory,
+// and Multi computes several digests in one pass. Checksum files are read
+// and written in the sha256sum format the Python side uses.
+package hashing
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// Algorithm names a hash function as hashlib does.
+type Algorithm string
+
+// Algorithms.
+const (
+	MD5     Algorithm = "md5"
+	SHA1    Algorithm = "sha1"
+	SHA224  Algorithm = "sha224"
+	SHA256  Algorithm = "sha256"
+	SHA384  Algorithm = "sha384"
+	SHA512  Algorithm = "sha512"
+	SHA3224 Algorithm = "sha3_224"
+	SHA3256 Algorithm = "sha3_256"
+	SHA3384 Algorithm = "sha3_384"
+	SHA3512 Algorithm = "sha3_512"
+	BLAKE2b Algorithm = "blake2b"
+	BLAKE2s Algorithm = "blake2s"
+)
+
+// DefaultAlgorithm is the algorithm of checksums that do not name one.
+const DefaultAlgorithm = SHA256
+
+// ErrUnsupported is matched by errors.Is for an unknown algorithm name.
+var ErrUnsupported = errors.New("hashing: unsupported algorithm")
+
+var constructors = map[Algorithm]func() hash.Hash{
+	MD5:     md5.New,
+	SHA1:    sha1.New,
+	SHA224:  sha256.New224,
+	SHA256:  sha256.New,
+	SHA384:  sha512.New384,
+	SHA512:  sha512.New,
+	SHA3224: func() hash.Hash { return sha3.New224() },
+	SHA3256: func() hash.Hash { return sha3.New256() },
+	SHA3384: func() hash.Hash { return sha3.New384() },
+	SHA3512: func() hash.Hash { return sha3.New512() },
+	BLAKE2b: newBLAKE2b,
+	BLAKE2s: newBLAKE2s,
+}
+
+// ParseAlgorithm returns the algorithm named name, in any case. It fails
+// with ErrUnsupported for an unknown name.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	a := Algorithm(strings.ToLower(name))
+	if _, ok := constructors[a]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupported, name)
+	}
+	return a, nil
+}
+
+// New returns a hash for a. It fails with ErrUnsupported for an unknown
+// algorithm.
+func (a Algorithm) New() (hash.Hash, error) {
+	newHash, ok := constructors[a]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupported, string(a))
+	}
+	return newHash(), nil
+}
+
+// Secure reports whether a is fit for integrity checks against tampering:
+// the SHA-2 and SHA-3 functions of 256 bits and more, and BLAKE2. The
+// others only detect accidental corruption.
+func (a Algorithm) Secure() bool {
+	switch a {
+	case SHA256, SHA384, SHA512, SHA3256, SHA3384, SHA3512, BLAKE2b, BLAKE2s:
+		return true
+	}
+	return false
+}
+
+// Sum returns the digest of data.
+func Sum(data []byte, a Algorithm) (string, error) {
+	h, err := a.New()
+	if err != nil {
+		return "", err
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Reader returns the digest of everything read from r.
+func Reader(r io.Reader, a Algorithm) (string, error) {
+	sums, err := Multi(r, a)
+	if err != nil {
+		return "", err
+	}
+	return sums[a], nil
+}
+
+// File returns the digest of the file at path.
+func File(path string, a Algorithm) (string, error) {
+	sums, err := MultiFile(path, a)
+	if err != nil {
+		return "", err
+	}
+	return sums[a], nil
+}
+
+// Multi reads r once and returns its digest under each algorithm.
+func Multi(r io.Reader, algs ...Algorithm) (map[Algorithm]string, error) {
+	hashes := make(map[Algorithm]hash.Hash, len(algs))
+	writers := make([]io.Writer, 0, len(algs))
+	for _, a := range algs {
+		if _, ok := hashes[a]; ok {
+			continue
+		}
+		h, err := a.New()
+		if err != nil {
+			return nil, err
+		}
+		hashes[a] = h
+		writers = append(writers, h)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, fmt.Errorf("hashing: %w", err)
+	}
+	sums := make(map[Algorithm]string, len(hashes))
+	for a, h := range hashes {
+		sums[a] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
+
+// MultiFile reads the file at path once and returns its digest under each
+// algorithm.
+func MultiFile(path string, algs ...Algorithm) (map[Algorithm]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("hashing: %w", err)
+	}
+	defer f.Close()
+	return Multi(f, algs...)
+}
+
+// Format returns the self-describing form of a digest, "sha256:<hex>".
+func Format(a Algorithm, digest string) string {
+	return string(a) + ":" + digest
+}
+
+// Parse splits a checksum in the form Format returns.
+func Parse(checksum string) (Algorithm, string, error) {
+	name, digest, ok := strings.Cut(checksum, ":")
+	if !ok || digest == "" {
+		return "", "", fmt.Errorf("hashing: checksum %q is not in algorithm:value form", checksum)
+	}
+	a, err := ParseAlgorithm(name)
+	if err != nil {
+		return "", "", err
+	}
+	return a, strings.ToLower(digest), nil
+}
+
+// Verify reads r and reports whether it matches checksum, given in the
+// form Format returns.
+func Verify(r io.Reader, checksum string) (bool, error) {
+	a, want, err := Parse(checksum)
+	if err != nil {
+		return false, err
+	}
+	got, err := Reader(r, a)
+	if err != nil {
+		return false, err
+	}
+	return Equal(got, want), nil
+}
+
+// VerifyFile reports whether the file at path matches checksum, given in
+// the form Format returns.
+func VerifyFile(path, checksum string) (bool, error) {
+	a, want, err := Parse(checksum)
+	if err != nil {
+		return false, err
+	}
+	got, err := File(path, a)
+	if err != nil {
+		return false, err
+	}
+	return Equal(got, want), nil
+}
+
+// Equal compares two hex digests in constant time, ignoring case.
+func Equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(strings.ToLower(a)), []byte(strings.ToLower(b))) == 1
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package hashing
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestBLAKE2MatchesHashlib(t *testing.T) {
+	var seq []byte
+	for i := range 768 {
+		seq = append(seq, byte(i))
+	}
+	tests := []struct {
+		a    Algorithm
+		data []byte
+		want string
+	}{
+		{BLAKE2b, nil, "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+		{BLAKE2b, []byte("abc"), "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+		{BLAKE2b, bytes.Repeat([]byte("x"), 128), "082b91ea2e15d1556d2ceefdd5af5d64d31b4e01aff1959724578876293825b236ee8079173a0a38160d7d6685d6bca0bfb62c177b3599b8727d9173e2115b91"},
+		{BLAKE2b, seq, "323e97a7a859ee63c9013debb0ca995811e73117a2f574723416e596ebc184e37a59b66d2f597df4a7c1b0d1d41a1a7f28774f46a6864d56c57b9d6c5f7302fb"},
+		{BLAKE2s, nil, "69217a3079908094e11121d042354a7c1f55b6482ca1a51e1b250dfd1ed0eef9"},
+		{BLAKE2s, []byte("abc"), "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982"},
+		{BLAKE2s, bytes.Repeat([]byte("x"), 64), "c13eb20b85b1d6a72d52af717429fc54eacc63fecde1295b26f0fa251bdcf40e"},
+		{BLAKE2s, seq, "b928a17862e211e99759ba8819280803a914cd3dee7c5d711a3b5185aa96a7b3"},
+	}
+	for _, tt := range tests {
+		if got, err := Sum(tt.data, tt.a); err != nil || got != tt.want {
+			t.Errorf("Sum(%d bytes, %s) = %s, %v; want %s", len(tt.data), tt.a, got, err, tt.want)
+		}
+		// One byte at a time crosses every block boundary.
+		if got, err := Reader(iotest.OneByteReader(bytes.NewReader(tt.data)), tt.a); err != nil || got != tt.want {
+			t.Errorf("Reader(%d bytes, %s) = %s, %v; want %s", len(tt.data), tt.a, got, err, tt.want)
+		}
+	}
+}
+
+func TestMultiHashesInOnePass(t *testing.T) {
+	data := []byte("Hello, World!")
+	r := iotest.OneByteReader(bytes.NewReader(data))
+	sums, err := Multi(r, SHA256, SHA512, BLAKE2s, SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums) != 3 {
+		t.Errorf("Multi returned %d sums, want 3", len(sums))
+	}
+	if sums[SHA256] != "dffd6021bb2bd5b0af676290809ec3a53191dd81c7f70a4b28688a362182986f" {
+		t.Errorf("sha256 = %s", sums[SHA256])
+	}
+	for a, got := range sums {
+		if want, _ := Sum(data, a); got != want {
+			t.Errorf("%s = %s, want %s", a, got, want)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if _, err := Sum(nil, "crc32"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Sum(crc32) error = %v, want ErrUnsupported", err)
+	}
+	if a, err := ParseAlgorithm("SHA3_256"); err != nil || a != SHA3256 {
+		t.Errorf("ParseAlgorithm(SHA3_256) = %q, %v", a, err)
+	}
+	if MD5.Secure() || !BLAKE2b.Secure() || Algorithm("crc32").Secure() {
+		t.Error("Secure misclassifies")
+	}
+}
+
+func TestVerifyPrefixed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	os.WriteFile(path, []byte("Hello, World!"), 0o644)
+	sum, _ := File(path, SHA256)
+	checksum := Format(SHA256, strings.ToUpper(sum))
+
+	if ok, err := VerifyFile(path, checksum); err != nil || !ok {
+		t.Errorf("VerifyFile = %v, %v; want true", ok, err)
+	}
+	if ok, err := Verify(strings.NewReader("tampered"), checksum); err != nil || ok {
+		t.Errorf("Verify(tampered) = %v, %v; want false", ok, err)
+	}
+	if _, err := Verify(strings.NewReader(""), sum); err == nil {
+		t.Error("Verify accepted a checksum without an algorithm")
+	}
+}
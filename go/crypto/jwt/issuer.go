@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/httpx"
+)
+
+// Defaults of issuers, verifiers and RemoteKeys without options.
+const (
+	DefaultTTL     = 15 * time.Minute
+	DefaultLeeway  = 30 * time.Second
+	DefaultRefresh = 15 * time.Minute
+)
+
+// Option configures an Issuer, a Verifier or RemoteKeys. Issuers and
+// verifiers take the same options, so both ends of a service call can be
+// configured from the same settings.
+type Option func(*options)
+
+type options struct {
+	issuer   string
+	audience []string
+	ttl      time.Duration
+	leeway   time.Duration
+	refresh  time.Duration
+	clock    clock.Clock
+}
+
+func newOptions(opts []Option) options {
+	o := options{ttl: DefaultTTL, leeway: DefaultLeeway, refresh: DefaultRefresh}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.clock = clock.Or(o.clock)
+	return o
+}
+
+// WithIssuer sets the iss claim of issued tokens, and the one verified
+// tokens must have.
+func WithIssuer(iss string) Option {
+	return func(o *options) { o.issuer = iss }
+}
+
+// WithAudience sets the aud claim of issued tokens that do not set their
+// own. A verifier accepts tokens whose audience includes one of aud.
+func WithAudience(aud ...string) Option {
+	return func(o *options) { o.audience = aud }
+}
+
+// WithTTL sets how long issued tokens are valid. The default is
+// DefaultTTL.
+func WithTTL(d time.Duration) Option {
+	return func(o *options) { o.ttl = d }
+}
+
+// WithLeeway sets the clock skew a verifier tolerates when checking
+// times. The default is DefaultLeeway.
+func WithLeeway(d time.Duration) Option {
+	return func(o *options) { o.leeway = d }
+}
+
+// WithRefresh sets how long RemoteKeys uses fetched keys before fetching
+// them again. The default is DefaultRefresh.
+func WithRefresh(d time.Duration) Option {
+	return func(o *options) { o.refresh = d }
+}
+
+// WithClock sets the clock tokens are issued and verified by, for tests.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// Issuer issues tokens signed with the current key of a keyring.
+type Issuer struct {
+	keys *Keyring
+	o    options
+}
+
+// NewIssuer returns an issuer signing with keys.
+func NewIssuer(keys *Keyring, opts ...Option) *Issuer {
+	return &Issuer{keys: keys, o: newOptions(opts)}
+}
+
+// Issue returns a signed token with claims: Claims, a struct embedding
+// it, or any other value encoding as a JSON object. The registered
+// claims it leaves empty are filled in: iss and aud from the options,
+// iat with the current time, exp with the TTL, and jti with a random ID.
+func (i *Issuer) Issue(claims any) (string, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("jwt: encoding claims: %w", err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil || m == nil {
+		return "", fmt.Errorf("jwt: claims must encode as a JSON object, got %T", claims)
+	}
+	now := i.o.clock.Now()
+	defaults := Claims{
+		Issuer:    i.o.issuer,
+		Audience:  i.o.audience,
+		IssuedAt:  NewNumericDate(now),
+		ExpiresAt: NewNumericDate(now.Add(i.o.ttl)),
+		ID:        newID(),
+	}
+	data, _ = json.Marshal(defaults)
+	var def map[string]json.RawMessage
+	json.Unmarshal(data, &def)
+	for k, v := range def {
+		if cur, ok := m[k]; !ok || isEmpty(cur) {
+			m[k] = v
+		}
+	}
+	return sign(i.keys.Current(), m)
+}
+
+// isEmpty reports whether a claim is an empty string or list, or null.
+func isEmpty(v json.RawMessage) bool {
+	switch string(v) {
+	case `""`, `[]`, `null`, `0`:
+		return true
+	}
+	return false
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// TokenSource returns a source of tokens with claims for
+// httpx.BearerFrom, for calls authenticated as a service:
+//
+//	client, err := httpx.New(billingURL, httpx.WithMiddleware(
+//		httpx.BearerFrom(issuer.TokenSource(jwt.Claims{Subject: "users", Audience: jwt.Audience{"billing"}}))))
+//
+// A token is reused until shortly before it expires, or until the
+// server rejects it.
+func (i *Issuer) TokenSource(claims any) httpx.TokenSource {
+	return &tokenSource{issuer: i, claims: claims}
+}
+
+type tokenSource struct {
+	issuer *Issuer
+	claims any
+
+	mu    sync.Mutex
+	token httpx.Token
+}
+
+func (s *tokenSource) Token(context.Context) (httpx.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.issuer.o.clock.Now()
+	if s.token.AccessToken != "" && now.Add(httpx.ExpiryLeeway).Before(s.token.Expiry) {
+		return s.token, nil
+	}
+	raw, err := s.issuer.Issue(s.claims)
+	if err != nil {
+		return httpx.Token{}, err
+	}
+	s.token = httpx.Token{AccessToken: raw, Expiry: now.Add(s.issuer.o.ttl)}
+	return s.token, nil
+}
+
+// Invalidate drops the cached token so the next call issues a new one.
+func (s *tokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = httpx.Token{}
+}
@@ -0,0 +1,212 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jwt issues and verifies JSON Web Tokens for service-to-service
+// and session authentication. A service signs tokens with the current key
+// of a Keyring and publishes its public keys as a JWKS,
+//
+//	keys := jwt.NewKeyring(key)
+//	issuer := jwt.NewIssuer(keys, jwt.WithIssuer("https://users.internal"), jwt.WithTTL(time.Hour))
+//	token, err := issuer.Issue(jwt.Claims{Subject: user.ID})
+//	mux.Handle("GET /.well-known/jwks.json", keys.Handler())
+//
+// and the services it calls verify them against the published keys,
+// fetched and cached by RemoteKeys:
+//
+//	keys := jwt.NewRemoteKeys(usersClient, "/.well-known/jwks.json")
+//	v := jwt.NewVerifier(keys, jwt.WithIssuer("https://users.internal"), jwt.WithAudience("billing"))
+//	h := httpserver.Chain(mux, httpserver.Standard(logger), jwt.Middleware(v))
+//
+// Clients send tokens with httpx.BearerFrom and Issuer.TokenSource, which
+// caches each token until shortly before it expires.
+//
+// Keys are rotated by Keyring.Rotate: new tokens are signed with the new
+// key while the old one keeps verifying the tokens it signed until it is
+// retired, and verifiers using RemoteKeys fetch the new key the first
+// time they see its ID. HMAC (HS256, HS384, HS512), RSA (RS256, RS384,
+// RS512), ECDSA (ES256, ES384, ES512) and Ed25519 (EdDSA) keys are
+// supported. A token is only verified with a key of the algorithm in its
+// header, so "none" and algorithm confusion attacks fail.
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+// Algorithm is a JWS signature algorithm.
+type Algorithm string
+
+// Algorithms.
+const (
+	HS256 Algorithm = "HS256"
+	HS384 Algorithm = "HS384"
+	HS512 Algorithm = "HS512"
+	RS256 Algorithm = "RS256"
+	RS384 Algorithm = "RS384"
+	RS512 Algorithm = "RS512"
+	ES256 Algorithm = "ES256"
+	ES384 Algorithm = "ES384"
+	ES512 Algorithm = "ES512"
+	EdDSA Algorithm = "EdDSA"
+)
+
+// Errors of Verifier.Verify, matched by errors.Is. They are all of the
+// errorsx class Unauthenticated.
+var (
+	ErrMalformed        = errorsx.New(errorsx.Unauthenticated, "jwt: malformed token")
+	ErrUnknownKey       = errorsx.New(errorsx.Unauthenticated, "jwt: unknown signing key")
+	ErrInvalidSignature = errorsx.New(errorsx.Unauthenticated, "jwt: invalid signature")
+	ErrExpired          = errorsx.New(errorsx.Unauthenticated, "jwt: token expired")
+	ErrNotYetValid      = errorsx.New(errorsx.Unauthenticated, "jwt: token not valid yet")
+	ErrInvalidClaims    = errorsx.New(errorsx.Unauthenticated, "jwt: invalid claims")
+)
+
+// Claims are the registered claims of a token. Embed Claims in a struct
+// to issue and decode custom claims along with them.
+type Claims struct {
+	Issuer    string      `json:"iss,omitempty"`
+	Subject   string      `json:"sub,omitempty"`
+	Audience  Audience    `json:"aud,omitempty"`
+	ExpiresAt NumericDate `json:"exp,omitzero"`
+	NotBefore NumericDate `json:"nbf,omitzero"`
+	IssuedAt  NumericDate `json:"iat,omitzero"`
+	ID        string      `json:"jti,omitempty"`
+}
+
+// Audience is the aud claim, a single string or a list in JSON.
+type Audience []string
+
+// Contains reports whether aud names a.
+func (aud Audience) Contains(a string) bool { return slices.Contains(aud, a) }
+
+// MarshalJSON encodes a single audience as a string, as most issuers do.
+func (aud Audience) MarshalJSON() ([]byte, error) {
+	if len(aud) == 1 {
+		return json.Marshal(aud[0])
+	}
+	return json.Marshal([]string(aud))
+}
+
+func (aud *Audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*aud = Audience{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(aud))
+}
+
+// NumericDate is a time encoded as seconds since the epoch.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns t truncated to the second.
+func NewNumericDate(t time.Time) NumericDate {
+	return NumericDate{t.Truncate(time.Second)}
+}
+
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprint(d.Unix())), nil
+}
+
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	var secs float64
+	if err := json.Unmarshal(data, &secs); err != nil {
+		return fmt.Errorf("jwt: numeric date: %w", err)
+	}
+	whole, frac := math.Modf(secs)
+	d.Time = time.Unix(int64(whole), int64(frac*float64(time.Second)))
+	return nil
+}
+
+// Header is the JOSE header of a token.
+type Header struct {
+	Algorithm Algorithm `json:"alg"`
+	Type      string    `json:"typ,omitempty"`
+	KeyID     string    `json:"kid,omitempty"`
+}
+
+// Token is a verified token.
+type Token struct {
+	Header Header
+	Claims Claims
+	// Raw is the encoded token.
+	Raw string
+
+	payload []byte
+}
+
+// Decode decodes the claims of the token into v, a struct embedding
+// Claims or a map, for custom claims.
+func (t *Token) Decode(v any) error {
+	if err := json.Unmarshal(t.payload, v); err != nil {
+		return fmt.Errorf("jwt: decoding claims: %w", err)
+	}
+	return nil
+}
+
+var b64 = base64.RawURLEncoding
+
+// sign encodes a token with claims, which must encode as a JSON object,
+// signed with key.
+func sign(key *Key, claims any) (string, error) {
+	header, err := json.Marshal(Header{Algorithm: key.alg, Type: "JWT", KeyID: key.id})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("jwt: encoding claims: %w", err)
+	}
+	input := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	sig, err := key.sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + b64.EncodeToString(sig), nil
+}
+
+// parsed is a token split into its parts, not verified yet.
+type parsed struct {
+	header  Header
+	payload []byte
+	input   []byte
+	sig     []byte
+}
+
+func parse(raw string) (*parsed, error) {
+	h, rest, ok1 := strings.Cut(raw, ".")
+	payload, sig, ok2 := strings.Cut(rest, ".")
+	if !ok1 || !ok2 || strings.Contains(sig, ".") {
+		return nil, fmt.Errorf("%w: want three parts", ErrMalformed)
+	}
+	p := &parsed{input: []byte(raw[:len(h)+1+len(payload)])}
+	var err error
+	var header []byte
+	if header, err = b64.DecodeString(h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformed, err)
+	}
+	if err := json.Unmarshal(header, &p.header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformed, err)
+	}
+	if p.payload, err = b64.DecodeString(payload); err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrMalformed, err)
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(p.payload), []byte("{")) {
+		return nil, fmt.Errorf("%w: payload is not a JSON object", ErrMalformed)
+	}
+	if p.sig, err = b64.DecodeString(sig); err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrMalformed, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+var allAlgorithms = []Algorithm{HS256, HS384, HS512, RS256, RS384, RS512, ES256, ES384, ES512, EdDSA}
+
+func TestIssueVerify(t *testing.T) {
+	ctx := context.Background()
+	for _, alg := range allAlgorithms {
+		key, err := GenerateKey("", alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := NewKeyring(key)
+		raw, err := NewIssuer(keys, WithIssuer("users")).Issue(Claims{Subject: "u1"})
+		if err != nil {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		tok, err := NewVerifier(NewKeyring(key.Public()), WithIssuer("users")).Verify(ctx, raw)
+		if err != nil {
+			t.Fatalf("%s: Verify = %v", alg, err)
+		}
+		if tok.Header.Algorithm != alg || tok.Header.KeyID != key.ID() || tok.Claims.Subject != "u1" || tok.Claims.ID == "" {
+			t.Errorf("%s: token = %+v", alg, tok)
+		}
+
+		tampered := raw[:strings.LastIndexByte(raw, '.')-2] + "xx" + raw[strings.LastIndexByte(raw, '.'):]
+		if _, err := NewVerifier(keys).Verify(ctx, tampered); !errors.Is(err, ErrInvalidSignature) && !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: tampered payload: %v", alg, err)
+		}
+	}
+}
+
+func TestVerifyRFC7515Example(t *testing.T) {
+	// RFC 7515, appendix A.1.
+	secret, _ := b64.DecodeString("AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow")
+	key, err := NewHMACKey("", HS256, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	v := NewVerifier(NewKeyring(key), WithClock(clock.NewFake(time.Unix(1300819000, 0))), WithIssuer("joe"))
+	tok, err := v.Verify(context.Background(), raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var custom struct {
+		Claims
+		Root bool `json:"http://example.com/is_root"`
+	}
+	if err := tok.Decode(&custom); err != nil || !custom.Root || custom.Issuer != "joe" {
+		t.Errorf("claims = %+v, %v", custom, err)
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	key, _ := GenerateKey("k1", EdDSA)
+	keys := NewKeyring(key)
+	issuer := NewIssuer(keys, WithClock(clk), WithIssuer("users"), WithAudience("billing"), WithTTL(time.Minute))
+	raw, _ := issuer.Issue(Claims{Subject: "u1"})
+
+	if _, err := NewVerifier(keys, WithClock(clk), WithIssuer("users"), WithAudience("billing", "search")).Verify(ctx, raw); err != nil {
+		t.Fatalf("Verify = %v", err)
+	}
+	tests := []struct {
+		name string
+		v    *Verifier
+		raw  string
+		want error
+	}{
+		{"issuer", NewVerifier(keys, WithClock(clk), WithIssuer("auth")), raw, ErrInvalidClaims},
+		{"audience", NewVerifier(keys, WithClock(clk), WithAudience("search")), raw, ErrInvalidClaims},
+		{"expired", NewVerifier(keys, WithClock(clock.NewFake(clk.Now().Add(2*time.Minute)))), raw, ErrExpired},
+		{"not yet valid", NewVerifier(keys, WithClock(clock.NewFake(clk.Now().Add(-time.Hour)))), raw, ErrNotYetValid},
+		{"no exp", NewVerifier(keys), mustSign(t, key, map[string]any{"sub": "u1"}), ErrInvalidClaims},
+		{"unknown key", NewVerifier(NewKeyring(mustKey(t, "k2", EdDSA))), raw, ErrUnknownKey},
+		{"garbage", NewVerifier(keys), "not.a.token.at-all", ErrMalformed},
+		{"payload not an object", NewVerifier(keys), mustSign(t, key, []int{1}), ErrMalformed},
+	}
+	for _, tt := range tests {
+		_, err := tt.v.Verify(ctx, tt.raw)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, err, tt.want)
+		}
+		if errorsx.Classify(err) != errorsx.Unauthenticated {
+			t.Errorf("%s: class = %v", tt.name, errorsx.Classify(err))
+		}
+	}
+
+	// Within the leeway, an expired token still passes.
+	late := NewVerifier(keys, WithClock(clock.NewFake(clk.Now().Add(time.Minute+10*time.Second))))
+	if _, err := late.Verify(ctx, raw); err != nil {
+		t.Errorf("within leeway: %v", err)
+	}
+}
+
+func TestVerifyRejectsAlgorithmMismatch(t *testing.T) {
+	ctx := context.Background()
+	rsaKey := mustKey(t, "k1", RS256)
+	keys := NewKeyring(rsaKey.Public())
+	now := time.Now().Unix()
+
+	// A token signed with HMAC using the public key as the secret, the
+	// classic algorithm confusion attack, and an unsigned one.
+	jwk, _ := rsaKey.JWK()
+	forged, _ := NewHMACKey("k1", HS256, []byte(jwk.N))
+	unsigned := b64.EncodeToString([]byte(`{"alg":"none","kid":"k1"}`)) + "." +
+		b64.EncodeToString(fmt.Appendf(nil, `{"sub":"admin","exp":%d}`, now+60)) + "."
+	for _, raw := range []string{mustSign(t, forged, map[string]any{"sub": "admin", "exp": now + 60}), unsigned} {
+		if _, err := NewVerifier(keys).Verify(ctx, raw); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Verify = %v, want ErrInvalidSignature", err)
+		}
+	}
+}
+
+func TestAudienceJSON(t *testing.T) {
+	for _, tt := range []struct {
+		aud  Audience
+		json string
+	}{
+		{Audience{"a"}, `"a"`},
+		{Audience{"a", "b"}, `["a","b"]`},
+	} {
+		data, _ := tt.aud.MarshalJSON()
+		if string(data) != tt.json {
+			t.Errorf("%v encodes as %s", tt.aud, data)
+		}
+		var got Audience
+		if err := got.UnmarshalJSON(data); err != nil || !got.Contains("a") || len(got) != len(tt.aud) {
+			t.Errorf("%s decodes as %v, %v", data, got, err)
+		}
+	}
+}
+
+func mustKey(t *testing.T, id string, alg Algorithm) *Key {
+	t.Helper()
+	k, err := GenerateKey(id, alg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func mustSign(t *testing.T, key *Key, claims any) string {
+	t.Helper()
+	raw, err := sign(key, claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
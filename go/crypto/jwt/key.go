@@ -0,0 +1,455 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // registers SHA-384 and SHA-512
+	"encoding/asn1"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// Key is a key tokens are signed or verified with, identified in token
+// headers by its ID.
+type Key struct {
+	id  string
+	alg Algorithm
+
+	secret  []byte        // HMAC
+	private crypto.Signer // RSA, ECDSA and Ed25519, nil for public keys
+	public  crypto.PublicKey
+}
+
+// NewHMACKey returns a key for an HMAC algorithm with a shared secret of
+// at least the size of its hash.
+func NewHMACKey(id string, alg Algorithm, secret []byte) (*Key, error) {
+	h, ok := hmacHash(alg)
+	if !ok {
+		return nil, fmt.Errorf("jwt: %s is not an HMAC algorithm", alg)
+	}
+	if len(secret) < h.Size() {
+		return nil, fmt.Errorf("jwt: %s secret must be at least %d bytes, got %d", alg, h.Size(), len(secret))
+	}
+	return &Key{id: id, alg: alg, secret: bytes.Clone(secret)}, nil
+}
+
+// NewKey returns a signing key for an RSA, ECDSA or Ed25519 private key.
+// An empty alg picks RS256 for RSA, the ES algorithm of the curve for
+// ECDSA and EdDSA for Ed25519; an empty id the RFC 7638 thumbprint of
+// the key.
+func NewKey(id string, key crypto.Signer, alg Algorithm) (*Key, error) {
+	k, err := NewPublicKey(id, key.Public(), alg)
+	if err != nil {
+		return nil, err
+	}
+	k.private = key
+	return k, nil
+}
+
+// NewPublicKey returns a verification key for an RSA, ECDSA or Ed25519
+// public key, with the defaults of NewKey.
+func NewPublicKey(id string, pub crypto.PublicKey, alg Algorithm) (*Key, error) {
+	def, err := defaultAlgorithm(pub)
+	if err != nil {
+		return nil, err
+	}
+	if alg == "" {
+		alg = def
+	}
+	if !compatible(alg, def) {
+		return nil, fmt.Errorf("jwt: %s cannot be used with a %T", alg, pub)
+	}
+	k := &Key{id: id, alg: alg, public: pub}
+	if k.id == "" {
+		jwk, err := k.JWK()
+		if err != nil {
+			return nil, err
+		}
+		k.id = jwk.Thumbprint()
+	}
+	return k, nil
+}
+
+// GenerateKey returns a key with a new random secret or key pair for
+// alg: 2048-bit RSA keys, and secrets of the size of the hash.
+func GenerateKey(id string, alg Algorithm) (*Key, error) {
+	if h, ok := hmacHash(alg); ok {
+		secret := make([]byte, h.Size())
+		rand.Read(secret)
+		return NewHMACKey(id, alg, secret)
+	}
+	var key crypto.Signer
+	var err error
+	switch alg {
+	case RS256, RS384, RS512:
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case ES256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case ES384:
+		key, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case ES512:
+		key, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case EdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("jwt: unknown algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt: %w", err)
+	}
+	return NewKey(id, key, alg)
+}
+
+// ID returns the key ID.
+func (k *Key) ID() string { return k.id }
+
+// Algorithm returns the algorithm of the key.
+func (k *Key) Algorithm() Algorithm { return k.alg }
+
+// Public returns the verification half of the key: the key itself for
+// HMAC keys.
+func (k *Key) Public() *Key {
+	if k.secret != nil {
+		return k
+	}
+	return &Key{id: k.id, alg: k.alg, public: k.public}
+}
+
+func (k *Key) sign(input []byte) ([]byte, error) {
+	if h, ok := hmacHash(k.alg); ok {
+		mac := hmac.New(h.New, k.secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	}
+	if k.private == nil {
+		return nil, fmt.Errorf("jwt: key %q cannot sign: it has no private key", k.id)
+	}
+	if k.alg == EdDSA {
+		sig, err := k.private.Sign(rand.Reader, input, crypto.Hash(0))
+		if err != nil {
+			return nil, fmt.Errorf("jwt: signing: %w", err)
+		}
+		return sig, nil
+	}
+	h := hashOf(k.alg)
+	sig, err := k.private.Sign(rand.Reader, digest(h, input), h)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: signing: %w", err)
+	}
+	if pub, ok := k.public.(*ecdsa.PublicKey); ok {
+		// JWS signatures are r and s concatenated, not ASN.1.
+		var rs struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(sig, &rs); err != nil {
+			return nil, fmt.Errorf("jwt: signing: %w", err)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		rs.R.FillBytes(sig[:size])
+		rs.S.FillBytes(sig[size:])
+	}
+	return sig, nil
+}
+
+func (k *Key) verify(input, sig []byte) bool {
+	if h, ok := hmacHash(k.alg); ok {
+		mac := hmac.New(h.New, k.secret)
+		mac.Write(input)
+		return hmac.Equal(sig, mac.Sum(nil))
+	}
+	switch pub := k.public.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, input, sig)
+	case *rsa.PublicKey:
+		h := hashOf(k.alg)
+		return rsa.VerifyPKCS1v15(pub, h, digest(h, input), sig) == nil
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(pub, digest(hashOf(k.alg), input), r, s)
+	}
+	return false
+}
+
+func defaultAlgorithm(pub crypto.PublicKey) (Algorithm, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return "", fmt.Errorf("jwt: RSA keys must have at least 2048 bits, got %d", k.N.BitLen())
+		}
+		return RS256, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return ES256, nil
+		case elliptic.P384():
+			return ES384, nil
+		case elliptic.P521():
+			return ES512, nil
+		}
+		return "", fmt.Errorf("jwt: unsupported curve %s", k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return EdDSA, nil
+	}
+	return "", fmt.Errorf("jwt: unsupported key %T", pub)
+}
+
+// compatible reports whether alg can be used with a key whose default
+// algorithm is def.
+func compatible(alg, def Algorithm) bool {
+	switch def {
+	case RS256:
+		return alg == RS256 || alg == RS384 || alg == RS512
+	}
+	return alg == def
+}
+
+func hmacHash(alg Algorithm) (crypto.Hash, bool) {
+	switch alg {
+	case HS256:
+		return crypto.SHA256, true
+	case HS384:
+		return crypto.SHA384, true
+	case HS512:
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+func hashOf(alg Algorithm) crypto.Hash {
+	switch alg[2:] {
+	case "384":
+		return crypto.SHA384
+	case "512":
+		return crypto.SHA512
+	}
+	return crypto.SHA256
+}
+
+func digest(h crypto.Hash, data []byte) []byte {
+	hh := h.New()
+	hh.Write(data)
+	return hh.Sum(nil)
+}
+
+// JWK is a public key as a JSON Web Key.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public key as a JWK. HMAC keys are secret and have
+// none.
+func (k *Key) JWK() (JWK, error) {
+	j := JWK{KeyID: k.id, Algorithm: string(k.alg), Use: "sig"}
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		j.KeyType = "RSA"
+		j.N = b64.EncodeToString(pub.N.Bytes())
+		j.E = b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		point, err := pub.Bytes() // 0x04 || x || y
+		if err != nil {
+			return JWK{}, fmt.Errorf("jwt: %w", err)
+		}
+		size := (len(point) - 1) / 2
+		j.KeyType, j.Curve = "EC", pub.Curve.Params().Name
+		j.X = b64.EncodeToString(point[1 : 1+size])
+		j.Y = b64.EncodeToString(point[1+size:])
+	case ed25519.PublicKey:
+		j.KeyType, j.Curve = "OKP", "Ed25519"
+		j.X = b64.EncodeToString(pub)
+	default:
+		return JWK{}, fmt.Errorf("jwt: %s key %q has no public JWK", k.alg, k.id)
+	}
+	return j, nil
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the key, which
+// NewKey uses as the ID of keys given none.
+func (j JWK) Thumbprint() string {
+	var members any
+	switch j.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.KeyType, j.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{j.Curve, j.KeyType, j.X, j.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Curve, j.KeyType, j.X}
+	}
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return b64.EncodeToString(sum[:])
+}
+
+// Key returns the verification key of j.
+func (j JWK) Key() (*Key, error) {
+	var pub crypto.PublicKey
+	switch j.KeyType {
+	case "RSA":
+		n, err1 := b64.DecodeString(j.N)
+		e, err2 := b64.DecodeString(j.E)
+		if err1 != nil || err2 != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("jwt: JWK %q: invalid RSA key", j.KeyID)
+		}
+		pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case "EC":
+		x, err1 := b64.DecodeString(j.X)
+		y, err2 := b64.DecodeString(j.Y)
+		var curve elliptic.Curve
+		switch j.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwt: JWK %q: unsupported curve %q", j.KeyID, j.Curve)
+		}
+		if err1 != nil || err2 != nil || len(x) != len(y) {
+			return nil, fmt.Errorf("jwt: JWK %q: invalid EC key", j.KeyID)
+		}
+		point := append(append([]byte{4}, x...), y...)
+		var err error
+		if pub, err = ecdsa.ParseUncompressedPublicKey(curve, point); err != nil {
+			return nil, fmt.Errorf("jwt: JWK %q: %w", j.KeyID, err)
+		}
+	case "OKP":
+		x, err := b64.DecodeString(j.X)
+		if j.Curve != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwt: JWK %q: invalid OKP key", j.KeyID)
+		}
+		pub = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("jwt: JWK %q: unsupported key type %q", j.KeyID, j.KeyType)
+	}
+	return NewPublicKey(j.KeyID, pub, Algorithm(j.Algorithm))
+}
+
+// Keyring holds the key new tokens are signed with and the keys tokens
+// are verified with: the current key and the previous ones not retired
+// yet. It is safe for concurrent use.
+type Keyring struct {
+	mu      sync.RWMutex
+	current *Key
+	keys    []*Key
+}
+
+// NewKeyring returns a keyring signing with current and verifying with
+// current and previous.
+func NewKeyring(current *Key, previous ...*Key) *Keyring {
+	return &Keyring{current: current, keys: append([]*Key{current}, previous...)}
+}
+
+// Current returns the key tokens are signed with.
+func (r *Keyring) Current() *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// Rotate signs new tokens with next. The previous keys keep verifying
+// the tokens they signed until retired, which is safe once those tokens
+// have expired. Publish next, through Handler, before verifiers see
+// tokens signed with it; RemoteKeys fetches unknown keys on demand.
+func (r *Keyring) Rotate(next *Key) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current = next
+	r.keys = append([]*Key{next}, slices.DeleteFunc(r.keys, func(k *Key) bool { return k.id == next.id })...)
+}
+
+// Retire removes the key with the given ID, unless it is the current
+// one, and reports whether it did.
+func (r *Keyring) Retire(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current.id == id {
+		return false
+	}
+	n := len(r.keys)
+	r.keys = slices.DeleteFunc(r.keys, func(k *Key) bool { return k.id == id })
+	return len(r.keys) < n
+}
+
+// Key returns the key with the given ID, or the current key for tokens
+// without one, implementing KeySource.
+func (r *Keyring) Key(_ context.Context, id string) (*Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if id == "" {
+		return r.current, nil
+	}
+	for _, k := range r.keys {
+		if k.id == id {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownKey, id)
+}
+
+// JWKS returns the public keys of the keyring. HMAC keys are left out.
+func (r *Keyring) JWKS() JWKSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range r.keys {
+		if j, err := k.JWK(); err == nil {
+			set.Keys = append(set.Keys, j)
+		}
+	}
+	return set
+}
+
+// Handler serves the JWKS of the keyring, conventionally at
+// "/.well-known/jwks.json".
+func (r *Keyring) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(r.JWKS())
+	})
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJWKRoundTrip(t *testing.T) {
+	for _, alg := range allAlgorithms[3:] {
+		key := mustKey(t, "", alg)
+		j, err := key.JWK()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j.KeyID != j.Thumbprint() {
+			t.Errorf("%s: kid %q is not the thumbprint %q", alg, j.KeyID, j.Thumbprint())
+		}
+		pub, err := j.Key()
+		if err != nil {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		raw, _ := sign(key, map[string]any{"exp": 1 << 40})
+		if _, err := NewVerifier(NewKeyring(pub)).Verify(context.Background(), raw); err != nil {
+			t.Errorf("%s: verifying with the parsed JWK: %v", alg, err)
+		}
+		if _, err := pub.sign([]byte("x")); err == nil {
+			t.Errorf("%s: public key signed", alg)
+		}
+	}
+	if _, err := mustKey(t, "hmac", HS256).JWK(); err == nil {
+		t.Error("HMAC key has a public JWK")
+	}
+}
+
+func TestThumbprintRFC7638(t *testing.T) {
+	j := JWK{
+		KeyType: "RSA",
+		N:       "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:       "AQAB",
+	}
+	if got := j.Thumbprint(); got != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("thumbprint = %s", got)
+	}
+}
+
+func TestNewKeyErrors(t *testing.T) {
+	if _, err := NewHMACKey("k", HS256, make([]byte, 16)); err == nil {
+		t.Error("short HMAC secret accepted")
+	}
+	if _, err := NewHMACKey("k", RS256, make([]byte, 32)); err == nil {
+		t.Error("HMAC key for RS256 accepted")
+	}
+	ec, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := NewKey("k", ec, ES384); err == nil {
+		t.Error("ES384 accepted for a P-256 key")
+	}
+	if _, err := GenerateKey("k", "none"); err == nil {
+		t.Error("algorithm none generated")
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	ctx := context.Background()
+	old, next := mustKey(t, "2026-01", ES256), mustKey(t, "2026-02", ES256)
+	keys := NewKeyring(old)
+	issuer, v := NewIssuer(keys), NewVerifier(keys)
+	before, _ := issuer.Issue(Claims{Subject: "u1"})
+
+	keys.Rotate(next)
+	after, _ := issuer.Issue(Claims{Subject: "u1"})
+	for _, raw := range []string{before, after} {
+		if _, err := v.Verify(ctx, raw); err != nil {
+			t.Errorf("Verify after rotation = %v", err)
+		}
+	}
+	if tok, _ := v.Verify(ctx, after); tok.Header.KeyID != "2026-02" {
+		t.Errorf("signed with %q after rotation", tok.Header.KeyID)
+	}
+
+	w := httptest.NewRecorder()
+	keys.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	var set JWKSet
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil || len(set.Keys) != 2 || set.Keys[0].KeyID != "2026-02" {
+		t.Errorf("JWKS = %s, %v", w.Body, err)
+	}
+
+	if keys.Retire("2026-02") {
+		t.Error("retired the current key")
+	}
+	if !keys.Retire("2026-01") {
+		t.Error("Retire(2026-01) = false")
+	}
+	if _, err := v.Verify(ctx, before); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Verify with retired key = %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/httpserver"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+type tokenKey struct{}
+
+// NewContext returns ctx carrying t.
+func NewContext(ctx context.Context, t *Token) context.Context {
+	return context.WithValue(ctx, tokenKey{}, t)
+}
+
+// FromContext returns the token of a request verified by Middleware, or
+// nil.
+func FromContext(ctx context.Context) *Token {
+	t, _ := ctx.Value(tokenKey{}).(*Token)
+	return t
+}
+
+// Middleware requires requests to carry a bearer token v accepts, and
+// gives the handlers after it the token through FromContext. Other
+// requests get a 401 with a WWW-Authenticate challenge, and are logged
+// at DEBUG as jwt_rejected with the reason. When the keys cannot be had,
+// such as when the JWKS of the issuer cannot be fetched, requests get a
+// 503 instead, since their tokens may well be valid.
+func Middleware(v *Verifier) httpserver.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			scheme, raw, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+			if !strings.EqualFold(scheme, "bearer") || raw == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			t, err := v.Verify(req.Context(), strings.TrimSpace(raw))
+			if err != nil && errorsx.Classify(err) != errorsx.Unauthenticated {
+				log.Default().ErrorCtx(req.Context(), "jwt_verification_failed", log.Err(err))
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			if err != nil {
+				log.Default().DebugCtx(req.Context(), "jwt_rejected", log.Err(err))
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(NewContext(req.Context(), t)))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestMiddleware(t *testing.T) {
+	logtest.Capture(t)
+	keys := NewKeyring(mustKey(t, "k1", ES256))
+	issuer := NewIssuer(keys, WithIssuer("users"))
+	var seen atomic.Int32
+	h := Middleware(NewVerifier(keys, WithIssuer("users"), WithAudience("billing")))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tok := FromContext(r.Context())
+		if tok.Raw != "" {
+			seen.Add(1)
+		}
+		io.WriteString(w, tok.Claims.Subject)
+	}))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	c, _ := httpx.New(srv.URL, httpx.WithMiddleware(httpx.BearerFrom(issuer.TokenSource(Claims{Subject: "users", Audience: Audience{"billing"}}))))
+	for range 2 {
+		resp, err := c.Get(context.Background(), "/invoices")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(resp.Body) != "users" {
+			t.Errorf("body = %q", resp.Body)
+		}
+	}
+	if seen.Load() != 2 {
+		t.Errorf("handler saw %d tokens", seen.Load())
+	}
+
+	other, _ := issuer.Issue(Claims{Audience: Audience{"search"}})
+	for _, tt := range []struct {
+		auth, challenge string
+	}{
+		{"", "Bearer"},
+		{"Basic dXNlcjpwdw==", "Bearer"},
+		{"Bearer " + other, `Bearer error="invalid_token"`},
+		{"Bearer garbage", `Bearer error="invalid_token"`},
+	} {
+		req := httptest.NewRequest("GET", "/invoices", nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != tt.challenge {
+			t.Errorf("%q: %d %q", tt.auth, w.Code, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestMiddlewareKeysUnavailable(t *testing.T) {
+	logtest.Capture(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c, _ := httpx.New(srv.URL)
+	h := Middleware(NewVerifier(NewRemoteKeys(c, "/jwks")))(http.NotFoundHandler())
+
+	raw, _ := NewIssuer(NewKeyring(mustKey(t, "k1", EdDSA))).Issue(Claims{})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+raw)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("code = %d", w.Code)
+	}
+}
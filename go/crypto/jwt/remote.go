@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// MinRefetchInterval is how often RemoteKeys fetches the set again for a
+// key ID it does not know, so tokens with made-up IDs cannot make it
+// hammer the issuer.
+const MinRefetchInterval = 30 * time.Second
+
+// RemoteKeys is a KeySource fetching a JWKS over HTTP, such as the one
+// Keyring.Handler serves. Keys are cached for the refresh interval, and
+// fetched again early when a token names a key not in the cache, as
+// happens after the issuer rotates its keys. When a fetch fails the
+// cached keys stay in use.
+type RemoteKeys struct {
+	client *httpx.Client
+	path   string
+	o      options
+
+	mu      sync.Mutex
+	keys    map[string]*Key
+	fetched time.Time
+	err     error // of the last fetch, while no keys were fetched
+}
+
+// NewRemoteKeys returns a source fetching the JWKS at path through c.
+// Only WithRefresh and WithClock apply.
+func NewRemoteKeys(c *httpx.Client, path string, opts ...Option) *RemoteKeys {
+	return &RemoteKeys{client: c, path: path, o: newOptions(opts)}
+}
+
+// Key returns the key with the given ID, fetching the set if needed. A
+// set holding a single key also serves tokens without a key ID.
+func (r *RemoteKeys) Key(ctx context.Context, id string) (*Key, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.o.clock.Now()
+	k, ok := r.lookup(id)
+	stale := now.Sub(r.fetched) >= r.o.refresh
+	if stale || (!ok && now.Sub(r.fetched) >= MinRefetchInterval) {
+		if err := r.fetch(ctx, now); err != nil {
+			if r.keys == nil {
+				r.err = err
+				return nil, err
+			}
+			log.Default().WarnCtx(ctx, "jwks_fetch_failed", "path", r.path, log.Err(err))
+		}
+		k, ok = r.lookup(id)
+	}
+	if !ok && r.keys == nil && r.err != nil {
+		return nil, r.err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	return k, nil
+}
+
+func (r *RemoteKeys) lookup(id string) (*Key, bool) {
+	if id == "" && len(r.keys) == 1 {
+		for _, k := range r.keys {
+			return k, true
+		}
+	}
+	k, ok := r.keys[id]
+	return k, ok
+}
+
+// fetch replaces the cached keys. Keys that cannot be used, such as
+// those of unsupported types, are skipped.
+func (r *RemoteKeys) fetch(ctx context.Context, now time.Time) error {
+	r.fetched = now
+	set, err := httpx.GetJSON[JWKSet](ctx, r.client, r.path)
+	if err != nil {
+		return fmt.Errorf("jwt: fetching JWKS: %w", err)
+	}
+	keys := make(map[string]*Key, len(set.Keys))
+	for _, j := range set.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		k, err := j.Key()
+		if err != nil {
+			log.Default().DebugCtx(ctx, "jwks_key_skipped", "kid", j.KeyID, log.Err(err))
+			continue
+		}
+		keys[k.id] = k
+	}
+	r.keys = keys
+	return nil
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestRemoteKeys(t *testing.T) {
+	logtest.Capture(t)
+	ctx := context.Background()
+	keys := NewKeyring(mustKey(t, "k1", EdDSA))
+	var fetches atomic.Int32
+	down := atomic.Bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		keys.Handler().ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	c, _ := httpx.New(srv.URL)
+	clk := clock.NewFake(time.Now())
+	remote := NewRemoteKeys(c, "/.well-known/jwks.json", WithClock(clk), WithRefresh(time.Hour))
+	issuer, v := NewIssuer(keys, WithClock(clk)), NewVerifier(remote, WithClock(clk))
+
+	raw, _ := issuer.Issue(Claims{Subject: "u1"})
+	for range 3 {
+		if _, err := v.Verify(ctx, raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d, want 1", n)
+	}
+
+	// A token signed with a new key is verified once the key can be fetched
+	// again; made-up key IDs do not cause a fetch each.
+	keys.Rotate(mustKey(t, "k2", EdDSA))
+	rotated, _ := issuer.Issue(Claims{Subject: "u1"})
+	if _, err := v.Verify(ctx, rotated); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Verify right after a fetch = %v", err)
+	}
+	clk.Advance(MinRefetchInterval)
+	if _, err := v.Verify(ctx, rotated); err != nil {
+		t.Errorf("Verify after rotation = %v", err)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+
+	// When the issuer is down, cached keys stay in use.
+	down.Store(true)
+	clk.Advance(time.Hour)
+	fresh, _ := issuer.Issue(Claims{Subject: "u1"})
+	if _, err := v.Verify(ctx, fresh); err != nil {
+		t.Errorf("Verify with the issuer down = %v", err)
+	}
+}
+
+func TestRemoteKeysUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	c, _ := httpx.New(srv.URL)
+	remote := NewRemoteKeys(c, "/jwks")
+	for range 2 {
+		if _, err := remote.Key(context.Background(), "k1"); err == nil || errors.Is(err, ErrUnknownKey) {
+			t.Errorf("Key = %v, want the fetch error", err)
+		}
+	}
+}
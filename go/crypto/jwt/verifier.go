@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// KeySource supplies the keys tokens are verified with, by the key ID in
+// their header, which may be empty.
+type KeySource interface {
+	Key(ctx context.Context, id string) (*Key, error)
+}
+
+// Verifier verifies tokens.
+type Verifier struct {
+	keys KeySource
+	o    options
+}
+
+// NewVerifier returns a verifier checking tokens against keys. Tokens
+// must be signed with the algorithm of the key their header names, carry
+// an exp claim, and match the issuer and audience options when set.
+func NewVerifier(keys KeySource, opts ...Option) *Verifier {
+	return &Verifier{keys: keys, o: newOptions(opts)}
+}
+
+// Verify checks the signature and claims of raw and returns the token.
+// Errors match ErrMalformed, ErrUnknownKey, ErrInvalidSignature,
+// ErrExpired, ErrNotYetValid or ErrInvalidClaims, or are those of the
+// key source.
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Token, error) {
+	p, err := parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	key, err := v.keys.Key(ctx, p.header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.alg != p.header.Algorithm {
+		return nil, fmt.Errorf("%w: algorithm %q does not match key %q", ErrInvalidSignature, p.header.Algorithm, key.id)
+	}
+	if !key.verify(p.input, p.sig) {
+		return nil, ErrInvalidSignature
+	}
+	t := &Token{Header: p.header, Raw: raw, payload: p.payload}
+	if err := json.Unmarshal(p.payload, &t.Claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if err := v.check(&t.Claims); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (v *Verifier) check(c *Claims) error {
+	now := v.o.clock.Now()
+	switch {
+	case c.ExpiresAt.IsZero():
+		return fmt.Errorf("%w: no exp claim", ErrInvalidClaims)
+	case !now.Before(c.ExpiresAt.Add(v.o.leeway)):
+		return fmt.Errorf("%w at %s", ErrExpired, c.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"))
+	case !c.NotBefore.IsZero() && now.Add(v.o.leeway).Before(c.NotBefore.Time):
+		return ErrNotYetValid
+	case !c.IssuedAt.IsZero() && now.Add(v.o.leeway).Before(c.IssuedAt.Time):
+		return fmt.Errorf("%w: issued in the future", ErrNotYetValid)
+	case v.o.issuer != "" && c.Issuer != v.o.issuer:
+		return fmt.Errorf("%w: issuer %q", ErrInvalidClaims, c.Issuer)
+	case len(v.o.audience) > 0 && !slices.ContainsFunc(v.o.audience, c.Audience.Contains):
+		return fmt.Errorf("%w: audience %q", ErrInvalidClaims, []string(c.Audience))
+	}
+	return nil
+}
@@ -0,0 +1,398 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package signing signs and verifies data with Ed25519 or ECDSA keys, the
+// Go port of the Python crypto signers, so artifacts signed by a tool in
+// either language verify in the other:
+//
+//	signer, err := signing.Generate(signing.Ed25519)
+//	err = signer.SignFile("dist/app.tar.gz") // writes dist/app.tar.gz.sig
+//	pub, err := signer.Verifier().PublicPEM()
+//
+//	verifier, err := signing.ParsePublicPEM(pub)
+//	err = verifier.VerifyFile("dist/app.tar.gz")
+//
+// Ed25519 is the default, as on the Python side. Private keys are PEM
+// encoded as PKCS#8 and public keys as PKIX; Ed25519 keys can also be
+// exchanged as the raw 32 bytes the Python signer exports. ECDSA
+// signatures are ASN.1 DER over the digest matching the curve: SHA-256
+// for P-256, SHA-384 for P-384 and SHA-512 for P-521.
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for hashFor
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Algorithm is a signature algorithm.
+type Algorithm string
+
+// Algorithms.
+const (
+	Ed25519 Algorithm = "ed25519"
+	ECDSA   Algorithm = "ecdsa"
+)
+
+// DefaultAlgorithm is the algorithm of Generate callers without a
+// preference, as on the Python side.
+const DefaultAlgorithm = Ed25519
+
+// SignatureExt is appended to the path of a file to name its detached
+// signature.
+const SignatureExt = ".sig"
+
+var (
+	// ErrInvalidSignature is returned when a signature does not match.
+	ErrInvalidSignature = errors.New("signing: invalid signature")
+	// ErrUnsupportedKey is matched by errors.Is for a key that is neither
+	// Ed25519 nor ECDSA.
+	ErrUnsupportedKey = errors.New("signing: unsupported key")
+)
+
+// Option configures Generate.
+type Option func(*options)
+
+type options struct {
+	curve elliptic.Curve
+}
+
+// WithCurve sets the curve of ECDSA keys. The default is P-384.
+func WithCurve(c elliptic.Curve) Option {
+	return func(o *options) { o.curve = c }
+}
+
+// Signer signs with a private key.
+type Signer struct {
+	key crypto.Signer
+}
+
+// Generate returns a signer with a new random key.
+func Generate(alg Algorithm, opts ...Option) (*Signer, error) {
+	o := options{curve: elliptic.P384()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var key crypto.Signer
+	var err error
+	switch alg {
+	case Ed25519:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case ECDSA:
+		key, err = ecdsa.GenerateKey(o.curve, rand.Reader)
+	default:
+		return nil, fmt.Errorf("signing: unknown algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	return NewSigner(key)
+}
+
+// NewSigner returns a signer for an Ed25519 or ECDSA private key.
+func NewSigner(key crypto.Signer) (*Signer, error) {
+	if _, err := algorithmOf(key.Public()); err != nil {
+		return nil, err
+	}
+	return &Signer{key: key}, nil
+}
+
+// NewEd25519Signer returns a signer for the 32-byte Ed25519 seed the Python
+// signer exports.
+func NewEd25519Signer(seed []byte) (*Signer, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing: ed25519 seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	return &Signer{key: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// Algorithm returns the algorithm of the key.
+func (s *Signer) Algorithm() Algorithm {
+	alg, _ := algorithmOf(s.key.Public())
+	return alg
+}
+
+// Key returns the private key.
+func (s *Signer) Key() crypto.Signer { return s.key }
+
+// Seed returns the 32-byte seed of an Ed25519 key, for the Python signer,
+// and nil for other keys.
+func (s *Signer) Seed() []byte {
+	if k, ok := s.key.(ed25519.PrivateKey); ok {
+		return k.Seed()
+	}
+	return nil
+}
+
+// Verifier returns a verifier for the public half of the key.
+func (s *Signer) Verifier() *Verifier {
+	return &Verifier{key: s.key.Public()}
+}
+
+// Sign returns the signature of data.
+func (s *Signer) Sign(data []byte) ([]byte, error) {
+	// Going through crypto.Signer also supports keys held in an HSM or KMS.
+	msg, opts := data, crypto.SignerOpts(crypto.Hash(0))
+	if pub, ok := s.key.Public().(*ecdsa.PublicKey); ok {
+		h := hashFor(pub.Curve)
+		msg, opts = digest(h, data), h
+	}
+	sig, err := s.key.Sign(rand.Reader, msg, opts)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	return sig, nil
+}
+
+// SignFile signs the file at path and writes the detached signature next
+// to it, at path + SignatureExt. See FileDigest for what is signed.
+func (s *Signer) SignFile(path string) error {
+	sum, err := FileDigest(path)
+	if err != nil {
+		return err
+	}
+	sig, err := s.Sign(sum)
+	if err != nil {
+		return err
+	}
+	return WriteSignature(path+SignatureExt, sig)
+}
+
+// PrivatePEM encodes the private key as a PKCS#8 "PRIVATE KEY" block.
+func (s *Signer) PrivatePEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(s.key)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePrivatePEM returns a signer for the first private key in data, in
+// PKCS#8 or, for ECDSA, SEC 1 form.
+func ParsePrivatePEM(data []byte) (*Signer, error) {
+	block, err := decodePEM(data, "PRIVATE KEY", "EC PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	var key any
+	if block.Type == "EC PRIVATE KEY" {
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+	return NewSigner(signer)
+}
+
+// LoadSigner reads a private key PEM file.
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	return ParsePrivatePEM(data)
+}
+
+// Verifier verifies signatures with a public key.
+type Verifier struct {
+	key crypto.PublicKey
+}
+
+// NewVerifier returns a verifier for an Ed25519 or ECDSA public key.
+func NewVerifier(key crypto.PublicKey) (*Verifier, error) {
+	if _, err := algorithmOf(key); err != nil {
+		return nil, err
+	}
+	return &Verifier{key: key}, nil
+}
+
+// NewEd25519Verifier returns a verifier for the 32-byte Ed25519 public key
+// the Python signer exports.
+func NewEd25519Verifier(pub []byte) (*Verifier, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("signing: ed25519 public key must be %d bytes, got %d", ed25519.PublicKeySize, len(pub))
+	}
+	return &Verifier{key: ed25519.PublicKey(bytes.Clone(pub))}, nil
+}
+
+// Algorithm returns the algorithm of the key.
+func (v *Verifier) Algorithm() Algorithm {
+	alg, _ := algorithmOf(v.key)
+	return alg
+}
+
+// Key returns the public key.
+func (v *Verifier) Key() crypto.PublicKey { return v.key }
+
+// Verify checks sig against data and fails with ErrInvalidSignature if it
+// does not match.
+func (v *Verifier) Verify(data, sig []byte) error {
+	var ok bool
+	switch k := v.key.(type) {
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, data, sig)
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(k, digest(hashFor(k.Curve), data), sig)
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyFile checks the file at path against its detached signature at
+// path + SignatureExt.
+func (v *Verifier) VerifyFile(path string) error {
+	sig, err := ReadSignature(path + SignatureExt)
+	if err != nil {
+		return err
+	}
+	sum, err := FileDigest(path)
+	if err != nil {
+		return err
+	}
+	if err := v.Verify(sum, sig); err != nil {
+		return fmt.Errorf("%w for %s", err, path)
+	}
+	return nil
+}
+
+// PublicPEM encodes the public key as a PKIX "PUBLIC KEY" block.
+func (v *Verifier) PublicPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(v.key)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePublicPEM returns a verifier for the first public key in data. A
+// certificate is accepted too, for its public key.
+func ParsePublicPEM(data []byte) (*Verifier, error) {
+	block, err := decodePEM(data, "PUBLIC KEY", "CERTIFICATE")
+	if err != nil {
+		return nil, err
+	}
+	var key any
+	if block.Type == "CERTIFICATE" {
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	} else {
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	return NewVerifier(key)
+}
+
+// LoadVerifier reads a public key or certificate PEM file.
+func LoadVerifier(path string) (*Verifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	return ParsePublicPEM(data)
+}
+
+// FileDigest returns the SHA-256 digest of the file at path, which is what
+// SignFile signs in place of the content, so artifacts of any size are
+// signed in constant memory. A Python verifier checks a detached
+// signature by verifying it against hashlib.sha256(content).digest().
+func FileDigest(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	return h.Sum(nil), nil
+}
+
+// WriteSignature writes sig to a detached signature file: standard base64
+// and a newline, as cosign writes them.
+func WriteSignature(path string, sig []byte) error {
+	data := base64.StdEncoding.AppendEncode(nil, sig)
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("signing: %w", err)
+	}
+	return nil
+}
+
+// ReadSignature reads a detached signature file written by WriteSignature.
+func ReadSignature(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("signing: %s: %w", path, err)
+	}
+	return sig, nil
+}
+
+func algorithmOf(pub crypto.PublicKey) (Algorithm, error) {
+	switch pub.(type) {
+	case ed25519.PublicKey:
+		return Ed25519, nil
+	case *ecdsa.PublicKey:
+		return ECDSA, nil
+	}
+	return "", fmt.Errorf("%w: %T", ErrUnsupportedKey, pub)
+}
+
+// hashFor returns the hash matching the size of an ECDSA curve.
+func hashFor(c elliptic.Curve) crypto.Hash {
+	switch bits := c.Params().BitSize; {
+	case bits > 384:
+		return crypto.SHA512
+	case bits > 256:
+		return crypto.SHA384
+	}
+	return crypto.SHA256
+}
+
+func digest(h crypto.Hash, data []byte) []byte {
+	hh := h.New()
+	hh.Write(data)
+	return hh.Sum(nil)
+}
+
+func decodePEM(data []byte, types ...string) (*pem.Block, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("signing: no %s PEM block", types[0])
+		}
+		for _, t := range types {
+			if block.Type == t {
+				return block, nil
+			}
+		}
+	}
+}
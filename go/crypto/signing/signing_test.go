@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	for _, tt := range []struct {
+		alg  Algorithm
+		opts []Option
+	}{
+		{Ed25519, nil},
+		{ECDSA, nil},
+		{ECDSA, []Option{WithCurve(elliptic.P256())}},
+		{ECDSA, []Option{WithCurve(elliptic.P521())}},
+	} {
+		s, err := Generate(tt.alg, tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Algorithm() != tt.alg {
+			t.Errorf("Algorithm = %s, want %s", s.Algorithm(), tt.alg)
+		}
+		sig, err := s.Sign([]byte("message"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v := s.Verifier()
+		if err := v.Verify([]byte("message"), sig); err != nil {
+			t.Errorf("%s: Verify = %v", tt.alg, err)
+		}
+		if err := v.Verify([]byte("tampered"), sig); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: Verify(tampered) = %v, want ErrInvalidSignature", tt.alg, err)
+		}
+	}
+	if _, err := Generate("rsa"); err == nil {
+		t.Error("Generate(rsa) succeeded")
+	}
+}
+
+func TestPEMRoundTrip(t *testing.T) {
+	for _, alg := range []Algorithm{Ed25519, ECDSA} {
+		s, _ := Generate(alg)
+		priv, err := s.PrivatePEM()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := s.Verifier().PublicPEM()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s2, err := ParsePrivatePEM(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := ParsePublicPEM(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, _ := s2.Sign([]byte("data"))
+		if err := v.Verify([]byte("data"), sig); err != nil {
+			t.Errorf("%s: signature from parsed key = %v", alg, err)
+		}
+	}
+	if _, err := ParsePublicPEM([]byte("not pem")); err == nil {
+		t.Error("ParsePublicPEM accepted garbage")
+	}
+}
+
+// TestEd25519RawKeys uses RFC 8032 test 2, the raw key form the Python
+// signer exchanges.
+func TestEd25519RawKeys(t *testing.T) {
+	seed, _ := hex.DecodeString("4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb")
+	pub, _ := hex.DecodeString("3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c")
+	want, _ := hex.DecodeString("92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00")
+
+	s, err := NewEd25519Signer(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, _ := s.Sign([]byte{0x72})
+	if !bytes.Equal(sig, want) {
+		t.Errorf("signature = %x, want %x", sig, want)
+	}
+	if !bytes.Equal(s.Seed(), seed) || !bytes.Equal(s.Verifier().Key().(ed25519.PublicKey), pub) {
+		t.Error("raw keys do not round trip")
+	}
+	v, err := NewEd25519Verifier(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Verify([]byte{0x72}, want); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewEd25519Signer(seed[:16]); err == nil {
+		t.Error("NewEd25519Signer accepted a short seed")
+	}
+}
+
+func TestDetachedSignatureFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.tar.gz")
+	os.WriteFile(path, []byte("artifact"), 0o644)
+	s, _ := Generate(DefaultAlgorithm)
+	if err := s.SignFile(path); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ReadSignature(path + SignatureExt)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		t.Fatalf("ReadSignature = %d bytes, %v", len(sig), err)
+	}
+	if err := s.Verifier().VerifyFile(path); err != nil {
+		t.Errorf("VerifyFile = %v", err)
+	}
+
+	os.WriteFile(path, []byte("tampered"), 0o644)
+	if err := s.Verifier().VerifyFile(path); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyFile(tampered) = %v, want ErrInvalidSignature", err)
+	}
+	os.Remove(path + SignatureExt)
+	if err := s.Verifier().VerifyFile(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("VerifyFile(no signature) = %v, want ErrNotExist", err)
+	}
+}
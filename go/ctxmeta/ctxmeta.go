@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ctxmeta carries request metadata through a context.Context and
+// across process boundaries: the request ID, the tenant, and baggage, a
+// set of string values the application attaches to a request.
+//
+//	ctx = ctxmeta.WithTenant(ctx, "acme")
+//	ctx = ctxmeta.WithBaggage(ctx, "plan", "pro")
+//	logger.InfoCtx(ctx, "order_placed") // request_id=… tenant=acme baggage.plan=pro
+//
+// Servers wrapped in Handler take the metadata from the request headers
+// and give each request an ID. Logs written with the context are tagged
+// with the metadata, and the httpx client sends it on to the services it
+// calls, so one request ID follows a request through every service.
+package ctxmeta
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Header names the metadata is propagated in. Baggage follows the W3C
+// Baggage format.
+const (
+	RequestIDHeader = "X-Request-ID"
+	TenantHeader    = "X-Tenant-ID"
+	BaggageHeader   = "baggage"
+)
+
+// MaxIDLength bounds the request IDs and tenants accepted from headers.
+const MaxIDLength = 128
+
+// Meta is the metadata of a request. The zero value carries nothing.
+type Meta struct {
+	RequestID string
+	Tenant    string
+	// Baggage is shared by every context derived from the one it was set
+	// on; do not modify it.
+	Baggage map[string]string
+}
+
+// IsZero reports whether m carries no metadata.
+func (m Meta) IsZero() bool {
+	return m.RequestID == "" && m.Tenant == "" && len(m.Baggage) == 0
+}
+
+type metaKey struct{}
+
+// NewContext returns a copy of ctx carrying m.
+func NewContext(ctx context.Context, m Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, m)
+}
+
+// FromContext returns the metadata carried by ctx, or the zero value.
+func FromContext(ctx context.Context) Meta {
+	if ctx == nil {
+		return Meta{}
+	}
+	m, _ := ctx.Value(metaKey{}).(Meta)
+	return m
+}
+
+// WithRequestID returns a copy of ctx with the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	m := FromContext(ctx)
+	m.RequestID = id
+	return NewContext(ctx, m)
+}
+
+// RequestID returns the request ID of ctx, or "".
+func RequestID(ctx context.Context) string { return FromContext(ctx).RequestID }
+
+// EnsureRequestID returns ctx and its request ID, giving it a new one
+// first if it has none.
+func EnsureRequestID(ctx context.Context) (context.Context, string) {
+	if id := RequestID(ctx); id != "" {
+		return ctx, id
+	}
+	id := NewRequestID()
+	return WithRequestID(ctx, id), id
+}
+
+// NewRequestID returns a random request ID of 32 hex digits.
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// WithTenant returns a copy of ctx with the tenant t.
+func WithTenant(ctx context.Context, t string) context.Context {
+	m := FromContext(ctx)
+	m.Tenant = t
+	return NewContext(ctx, m)
+}
+
+// Tenant returns the tenant of ctx, or "".
+func Tenant(ctx context.Context) string { return FromContext(ctx).Tenant }
+
+// WithBaggage returns a copy of ctx with the baggage value of key set to
+// value. An empty value removes key.
+func WithBaggage(ctx context.Context, key, value string) context.Context {
+	m := FromContext(ctx)
+	m.Baggage = maps.Clone(m.Baggage)
+	if value == "" {
+		delete(m.Baggage, key)
+	} else {
+		if m.Baggage == nil {
+			m.Baggage = map[string]string{}
+		}
+		m.Baggage[key] = value
+	}
+	return NewContext(ctx, m)
+}
+
+// Baggage returns the baggage value of key in ctx, or "".
+func Baggage(ctx context.Context, key string) string { return FromContext(ctx).Baggage[key] }
+
+// Extract returns ctx with the metadata of the request headers h. Values
+// in ctx are kept where h has none; request IDs and tenants that are not
+// valid are ignored.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	m := FromContext(ctx)
+	if id := h.Get(RequestIDHeader); validID(id) {
+		m.RequestID = id
+	}
+	if t := h.Get(TenantHeader); validID(t) {
+		m.Tenant = t
+	}
+	if b := parseBaggage(h.Values(BaggageHeader)); len(b) > 0 {
+		m.Baggage = maps.Clone(m.Baggage)
+		if m.Baggage == nil {
+			m.Baggage = b
+		} else {
+			maps.Copy(m.Baggage, b)
+		}
+	}
+	if m.IsZero() {
+		return ctx
+	}
+	return NewContext(ctx, m)
+}
+
+// Inject writes the metadata of ctx to the headers h. Headers for values
+// ctx does not carry are left alone.
+func Inject(ctx context.Context, h http.Header) {
+	m := FromContext(ctx)
+	if m.RequestID != "" {
+		h.Set(RequestIDHeader, m.RequestID)
+	}
+	if m.Tenant != "" {
+		h.Set(TenantHeader, m.Tenant)
+	}
+	if len(m.Baggage) > 0 {
+		h.Set(BaggageHeader, formatBaggage(m.Baggage))
+	}
+}
+
+// Handler gives each request to next the metadata of its headers, and a
+// new request ID if it came without one. The request ID is echoed in the
+// X-Request-ID response header so clients can quote it.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, id := EnsureRequestID(Extract(req.Context(), req.Header))
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// validID reports whether s is a request ID or tenant fit for logs and
+// headers: printable ASCII without spaces, at most MaxIDLength long.
+func validID(s string) bool {
+	if s == "" || len(s) > MaxIDLength {
+		return false
+	}
+	for i := range len(s) {
+		if s[i] <= ' ' || s[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseBaggage parses baggage header values. Entry properties are
+// dropped, as are malformed entries.
+func parseBaggage(values []string) map[string]string {
+	var b map[string]string
+	for _, v := range values {
+		for _, entry := range strings.Split(v, ",") {
+			entry, _, _ = strings.Cut(entry, ";")
+			k, val, ok := strings.Cut(entry, "=")
+			k = strings.TrimSpace(k)
+			if !ok || k == "" {
+				continue
+			}
+			val, err := url.PathUnescape(strings.TrimSpace(val))
+			if err != nil || val == "" {
+				continue
+			}
+			if b == nil {
+				b = map[string]string{}
+			}
+			b[k] = val
+		}
+	}
+	return b
+}
+
+func formatBaggage(b map[string]string) string {
+	entries := make([]string, 0, len(b))
+	for k, v := range b {
+		entries = append(entries, k+"="+url.PathEscape(v))
+	}
+	slices.Sort(entries)
+	return strings.Join(entries, ",")
+}
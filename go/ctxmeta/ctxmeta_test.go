@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package ctxmeta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	ctx := WithTenant(context.Background(), "acme")
+	ctx = WithBaggage(ctx, "plan", "pro")
+	child := WithBaggage(ctx, "region", "eu")
+	child = WithBaggage(child, "plan", "")
+	if Tenant(child) != "acme" || Baggage(child, "region") != "eu" || Baggage(child, "plan") != "" {
+		t.Errorf("child = %+v", FromContext(child))
+	}
+	if Baggage(ctx, "plan") != "pro" || Baggage(ctx, "region") != "" {
+		t.Errorf("parent changed: %+v", FromContext(ctx))
+	}
+	ctx, id := EnsureRequestID(ctx)
+	if len(id) != 32 || RequestID(ctx) != id {
+		t.Errorf("request ID %q", id)
+	}
+	if _, again := EnsureRequestID(ctx); again != id {
+		t.Error("request ID replaced")
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithTenant(ctx, "acme")
+	ctx = WithBaggage(ctx, "plan", "pro plus")
+	ctx = WithBaggage(ctx, "ab", "x,y;z")
+	h := http.Header{}
+	Inject(ctx, h)
+	if got := h.Get(BaggageHeader); got != "ab=x%2Cy%3Bz,plan=pro%20plus" {
+		t.Errorf("baggage header %q", got)
+	}
+	got := FromContext(Extract(context.Background(), h))
+	want := FromContext(ctx)
+	if got.RequestID != want.RequestID || got.Tenant != want.Tenant ||
+		got.Baggage["plan"] != "pro plus" || got.Baggage["ab"] != "x,y;z" {
+		t.Errorf("extracted %+v", got)
+	}
+
+	h = http.Header{}
+	h.Set(RequestIDHeader, "bad id\n")
+	h.Set(TenantHeader, strings.Repeat("t", MaxIDLength+1))
+	h.Add(BaggageHeader, "k1=v1;prop=1, =x, novalue")
+	h.Add(BaggageHeader, "k2=%zz,k3=v3")
+	got = FromContext(Extract(WithRequestID(context.Background(), "kept"), h))
+	if got.RequestID != "kept" || got.Tenant != "" || len(got.Baggage) != 2 ||
+		got.Baggage["k1"] != "v1" || got.Baggage["k3"] != "v3" {
+		t.Errorf("extracted %+v", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var seen Meta
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if seen.RequestID == "" || w.Header().Get(RequestIDHeader) != seen.RequestID {
+		t.Errorf("generated %q, echoed %q", seen.RequestID, w.Header().Get(RequestIDHeader))
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "upstream-7")
+	req.Header.Set(TenantHeader, "acme")
+	h.ServeHTTP(w, req)
+	if seen.RequestID != "upstream-7" || seen.Tenant != "acme" || w.Header().Get(RequestIDHeader) != "upstream-7" {
+		t.Errorf("seen %+v, echoed %q", seen, w.Header().Get(RequestIDHeader))
+	}
+}
@@ -0,0 +1,255 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package db wraps database/sql with the foundation conventions: a
+// bindable Config with pool settings, DSN URLs mapped to driver names,
+// a startup ping retried with backoff, and a health check. The driver is
+// chosen by the application through a blank import:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"
+//
+//	database, err := db.Open(ctx, db.Config{DSN: "postgres://app@db.internal/users"})
+//	rows, err := database.Query(ctx, "SELECT id, name FROM users WHERE id = $1", id)
+//
+// Query, QueryRow and Exec each record a client span of the trace in the
+// context, named after the statement's operation.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+// DefaultConnectTimeout bounds how long Open retries the first ping.
+const DefaultConnectTimeout = 30 * time.Second
+
+// Config describes a database connection, bindable with config.Bind.
+// Zero pool fields keep the database/sql defaults.
+type Config struct {
+	DSN             string        `config:"dsn" env:"DATABASE_URL" validate:"required" desc:"Database URL, e.g. postgres://user@host/db."`
+	Driver          string        `config:"driver" desc:"database/sql driver name; derived from the DSN scheme when empty."`
+	MaxOpenConns    int           `config:"max_open_conns" default:"10" validate:"min=0" desc:"Maximum open connections; 0 is unlimited."`
+	MaxIdleConns    int           `config:"max_idle_conns" default:"5" validate:"min=0" desc:"Maximum idle connections kept in the pool."`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime" default:"30m" desc:"Maximum age of a connection."`
+	ConnMaxIdleTime time.Duration `config:"conn_max_idle_time" default:"5m" desc:"Maximum time a connection stays idle."`
+	ConnectTimeout  time.Duration `config:"connect_timeout" default:"30s" desc:"How long Open retries the first ping."`
+}
+
+// Option configures Open.
+type Option func(*options)
+
+type options struct {
+	logger  *log.Logger
+	startup retry.Policy
+}
+
+// WithLogger sets the logger for connection events. The default is
+// log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(o *options) { o.logger = l }
+}
+
+// WithStartupPolicy sets the backoff between startup pings. Attempts stop
+// at Config.ConnectTimeout or after MaxAttempts, whichever comes first.
+func WithStartupPolicy(p retry.Policy) Option {
+	return func(o *options) { o.startup = p }
+}
+
+// Database is the interface of DB, for code that accepts a test double
+// such as dbtest.Fake in its place.
+type Database interface {
+	Executor
+	Driver() string
+	Placeholder() Placeholder
+	Check(ctx context.Context) error
+	Query(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...any) *sql.Row
+	Exec(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Named(query string, arg any) (string, []any, error)
+	NamedQuery(ctx context.Context, query string, arg any) (*sql.Rows, error)
+	NamedExec(ctx context.Context, query string, arg any) (sql.Result, error)
+	Tx(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error
+	Close() error
+}
+
+var _ Database = (*DB)(nil)
+
+// DB is a connection pool. It is safe for concurrent use.
+type DB struct {
+	sql         *sql.DB
+	driver      string
+	dsn         string // redacted
+	placeholder Placeholder
+}
+
+// Open connects to the database in cfg and pings it until it answers, so
+// a service started alongside its database waits for it instead of
+// failing. The pool is closed if the database does not answer within
+// cfg.ConnectTimeout.
+func Open(ctx context.Context, cfg Config, opts ...Option) (*DB, error) {
+	o := options{
+		startup: retry.Policy{
+			MaxAttempts: math.MaxInt32,
+			BaseDelay:   250 * time.Millisecond,
+			MaxDelay:    5 * time.Second,
+			Jitter:      0.25,
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.logger == nil {
+		o.logger = log.Default()
+	}
+
+	driver, source, redacted := cfg.Driver, cfg.DSN, "[dsn]"
+	placeholder := placeholderFor(driver)
+	if d, err := ParseDSN(cfg.DSN); err == nil {
+		source, redacted = d.String(), d.Redacted()
+		if driver == "" {
+			driver = d.Driver()
+		}
+		placeholder = max(placeholder, placeholderFor(d.Scheme))
+	} else if driver == "" {
+		return nil, err
+	}
+	pool, err := sql.Open(driver, source)
+	if err != nil {
+		return nil, fmt.Errorf("db: %w", err)
+	}
+	if cfg.MaxOpenConns > 0 {
+		pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		pool.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+
+	logger := o.logger.With("db.system", driver, "db.url", redacted)
+	timeout := cfg.ConnectTimeout
+	if timeout <= 0 {
+		timeout = DefaultConnectTimeout
+	}
+	pctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	p := o.startup
+	p.OnRetry = func(attempt int, err error, delay time.Duration) {
+		logger.WarnCtx(ctx, "db_connect_retry", "attempt", attempt, "delay_ms", delay.Milliseconds(), log.Err(err))
+	}
+	var last error // last failure other than the timeout, for the error
+	err = retry.Do(pctx, p, func(ctx context.Context) error {
+		err := pool.PingContext(ctx)
+		if err != nil && ctx.Err() == nil {
+			last = err
+		}
+		return err
+	})
+	if err != nil {
+		pool.Close()
+		if last != nil && errors.Is(err, context.DeadlineExceeded) {
+			err = errors.Join(err, last)
+		}
+		logger.ErrorCtx(ctx, "db_connect_failed", log.Err(err))
+		return nil, fmt.Errorf("db: connecting to %s: %w", redacted, err)
+	}
+	logger.InfoCtx(ctx, "db_connected")
+	return &DB{sql: pool, driver: driver, dsn: redacted, placeholder: placeholder}, nil
+}
+
+// Wrap returns a DB for a pool opened elsewhere, with the placeholder style
+// of driver. Unlike Open it does not ping.
+func Wrap(pool *sql.DB, driver string) *DB {
+	return &DB{sql: pool, driver: driver, dsn: driver, placeholder: placeholderFor(driver)}
+}
+
+// SQL returns the underlying pool, for libraries that take a *sql.DB.
+func (db *DB) SQL() *sql.DB { return db.sql }
+
+// Driver returns the database/sql driver name, such as "pgx".
+func (db *DB) Driver() string { return db.driver }
+
+// Close closes the pool.
+func (db *DB) Close() error { return db.sql.Close() }
+
+// Stats returns the pool statistics.
+func (db *DB) Stats() sql.DBStats { return db.sql.Stats() }
+
+// Check pings the database, for health checks. It fails when ctx ends
+// first, so give it a deadline.
+func (db *DB) Check(ctx context.Context) error {
+	if err := db.sql.PingContext(ctx); err != nil {
+		return fmt.Errorf("db: ping %s: %w", db.dsn, err)
+	}
+	return nil
+}
+
+// Query runs a query returning rows. The caller must close them.
+func (db *DB) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := db.startSpan(ctx, query)
+	defer span.End()
+	rows, err := db.sql.QueryContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("db: query: %w", err)
+	}
+	return rows, nil
+}
+
+// QueryRow runs a query returning at most one row. Errors, including
+// sql.ErrNoRows, are returned by Scan.
+func (db *DB) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := db.startSpan(ctx, query)
+	defer span.End()
+	row := db.sql.QueryRowContext(ctx, query, args...)
+	span.RecordError(row.Err())
+	return row
+}
+
+// Exec runs a statement that returns no rows.
+func (db *DB) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := db.startSpan(ctx, query)
+	defer span.End()
+	res, err := db.sql.ExecContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("db: exec: %w", err)
+	}
+	return res, nil
+}
+
+// Tx runs fn in a transaction, committing if it returns nil and rolling
+// back otherwise, including when fn panics.
+func (db *DB) Tx(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := db.sql.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("db: begin: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) {
+			return errors.Join(err, fmt.Errorf("db: rollback: %w", rerr))
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("db: commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,514 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dbtest fakes the database in unit tests. A Fake is a db.Database
+// backed by in-memory tables, so repositories run their real SQL without a
+// server:
+//
+//	fake := dbtest.New(t)
+//	fake.Table("users", User{ID: 1, Name: "Ann"})
+//	users, _ := repository.New[User](fake, "users")
+//	u, err := users.FindByID(ctx, 1)
+//	fake.AssertCalled(t, "FROM users", 1)
+//
+// The tables answer the simple statements the db and repository packages
+// generate: SELECT, INSERT, UPDATE and DELETE on one table with equality
+// conditions. Anything else, such as joins or aggregates, is answered by
+// expectations registered with On, which are tried first; a statement
+// neither can answer fails the test.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/db"
+)
+
+// Option configures New.
+type Option func(*Fake)
+
+// WithDriver sets the driver name the fake reports, which selects the
+// placeholder style and repository dialect. The default is "pgx".
+func WithDriver(name string) Option {
+	return func(f *Fake) { f.driver = name }
+}
+
+// Fake is an in-memory db.Database. It is safe for concurrent use.
+type Fake struct {
+	*db.DB
+	t      testing.TB
+	driver string
+
+	mu      sync.Mutex
+	tables  map[string]*table
+	expects []*Expect
+	calls   []Call
+}
+
+var _ db.Database = (*Fake)(nil)
+
+// New returns an empty fake. When the test ends it is closed, and the test
+// fails for every expectation registered with Times whose calls did not
+// all happen.
+func New(t testing.TB, opts ...Option) *Fake {
+	f := &Fake{t: t, driver: "pgx", tables: map[string]*table{}}
+	for _, opt := range opts {
+		opt(f)
+	}
+	f.DB = db.Wrap(sql.OpenDB(connector{f}), f.driver)
+	t.Cleanup(func() {
+		f.DB.Close()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, e := range f.expects {
+			if e.times > 0 && e.calls < e.times {
+				t.Errorf("dbtest: expected %d call(s) matching %q, got %d", e.times, e.fragment, e.calls)
+			}
+		}
+	})
+	return f
+}
+
+// Table declares the table name, adding rows to it, and returns it for
+// further setup. Rows are structs, with columns named as in db.Select, or
+// map[string]any. Unquoted names in SQL are folded to lower case, so name
+// tables and columns in lower case.
+func (f *Fake) Table(name string, rows ...any) *Table {
+	f.mu.Lock()
+	t, ok := f.tables[name]
+	if !ok {
+		t = &table{defaults: map[string]driver.Value{}}
+		f.tables[name] = t
+	}
+	f.mu.Unlock()
+	tb := &Table{f: f, name: name, t: t}
+	return tb.Insert(rows...)
+}
+
+// Rows returns the rows of table, for checking the effect of writes. It
+// returns nil for an unknown table.
+func (f *Fake) Rows(table string) []map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.tables[table]
+	if !ok {
+		return nil
+	}
+	out := make([]map[string]any, len(t.rows))
+	for i, r := range t.rows {
+		out[i] = make(map[string]any, len(r))
+		for k, v := range r {
+			out[i][k] = v
+		}
+	}
+	return out
+}
+
+// Table is an in-memory table of a Fake.
+type Table struct {
+	f    *Fake
+	name string
+	t    *table
+}
+
+// Columns declares columns, for tables that start empty.
+func (t *Table) Columns(cols ...string) *Table {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	for _, c := range cols {
+		t.t.addColumn(c)
+	}
+	return t
+}
+
+// Default sets the value of column in inserted rows that do not set it,
+// like a column default. Rows inserted without an id get the next integer
+// id whenever the table has an id column.
+func (t *Table) Default(column string, value any) *Table {
+	v := convert(t.f.t, value)
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	t.t.addColumn(column)
+	t.t.defaults[column] = v
+	return t
+}
+
+// Insert adds rows, which are structs or map[string]any.
+func (t *Table) Insert(rows ...any) *Table {
+	for _, r := range rows {
+		values := rowValues(t.f.t, r)
+		t.f.mu.Lock()
+		row := t.t.newRow()
+		for _, c := range slices.Sorted(maps.Keys(values)) {
+			t.t.addColumn(c)
+			row[c] = values[c]
+		}
+		t.t.rows = append(t.t.rows, row)
+		t.f.mu.Unlock()
+	}
+	return t
+}
+
+func rowValues(tb testing.TB, r any) map[string]driver.Value {
+	out := map[string]driver.Value{}
+	if m, ok := r.(map[string]any); ok {
+		for k, v := range m {
+			out[k] = convert(tb, v)
+		}
+		return out
+	}
+	v := reflect.ValueOf(r)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		tb.Fatalf("dbtest: row must be a struct or map[string]any, got %T", r)
+	}
+	for _, c := range db.Columns(v.Type()) {
+		f, err := v.FieldByIndexErr(c.Index)
+		if err != nil {
+			out[c.Name] = nil
+			continue
+		}
+		out[c.Name] = convert(tb, f.Interface())
+	}
+	return out
+}
+
+// convert turns a Go value into the driver value database/sql would pass
+// for it.
+func convert(tb testing.TB, v any) driver.Value {
+	dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		tb.Fatalf("dbtest: %v", err)
+	}
+	return dv
+}
+
+// Call is a statement received by a Fake.
+type Call struct {
+	Query string
+	Args  []any
+}
+
+// String returns the query followed by its arguments.
+func (c Call) String() string {
+	if len(c.Args) == 0 {
+		return c.Query
+	}
+	return fmt.Sprintf("%s %v", c.Query, c.Args)
+}
+
+// Calls returns every statement received, in order. Transactions are
+// recorded as the calls BEGIN, COMMIT and ROLLBACK.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.calls)
+}
+
+// CallsMatching returns the calls whose query contains fragment, compared
+// as in On.
+func (f *Fake) CallsMatching(fragment string) []Call {
+	var out []Call
+	for _, c := range f.Calls() {
+		if contains(c.Query, fragment) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// AssertCalled fails the test unless a call contains fragment and, if args
+// are given, had exactly those arguments.
+func (f *Fake) AssertCalled(t testing.TB, fragment string, args ...any) {
+	t.Helper()
+	for _, c := range f.CallsMatching(fragment) {
+		if len(args) == 0 || argsEqual(t, c.Args, args) {
+			return
+		}
+	}
+	t.Errorf("dbtest: no call matching %q %v; calls:\n%s", fragment, args, f.describeCalls())
+}
+
+// AssertNotCalled fails the test if a call contains fragment.
+func (f *Fake) AssertNotCalled(t testing.TB, fragment string) {
+	t.Helper()
+	if calls := f.CallsMatching(fragment); len(calls) > 0 {
+		t.Errorf("dbtest: unexpected call matching %q: %s", fragment, calls[0])
+	}
+}
+
+// AssertCallCount fails the test unless exactly n calls contain fragment.
+func (f *Fake) AssertCallCount(t testing.TB, fragment string, n int) {
+	t.Helper()
+	if got := len(f.CallsMatching(fragment)); got != n {
+		t.Errorf("dbtest: %d call(s) matching %q, want %d; calls:\n%s", got, fragment, n, f.describeCalls())
+	}
+}
+
+func (f *Fake) describeCalls() string {
+	var b strings.Builder
+	for _, c := range f.Calls() {
+		b.WriteString("  " + c.String() + "\n")
+	}
+	return b.String()
+}
+
+// Reset forgets the recorded calls and the call counts of expectations.
+// Tables keep their rows.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+	for _, e := range f.expects {
+		e.calls = 0
+	}
+}
+
+// normalize collapses whitespace and lowers case, so fragments match
+// regardless of formatting.
+func normalize(s string) string { return strings.ToLower(strings.Join(strings.Fields(s), " ")) }
+
+func contains(query, fragment string) bool {
+	return strings.Contains(normalize(query), normalize(fragment))
+}
+
+func argsEqual(tb testing.TB, got []any, want []any) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range want {
+		if compare(got[i], convert(tb, want[i])) != 0 || (got[i] == nil) != (want[i] == nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// On registers an expectation for statements whose query contains
+// fragment, ignoring case and whitespace differences. Expectations are
+// tried in registration order before the tables, and the first match with
+// calls left answers. It answers with no rows and no affected rows until
+// configured.
+func (f *Fake) On(fragment string) *Expect {
+	e := &Expect{f: f, fragment: fragment}
+	f.mu.Lock()
+	f.expects = append(f.expects, e)
+	f.mu.Unlock()
+	return e
+}
+
+// Expect is a canned answer for matching statements. Its methods configure
+// it and return it for chaining; configure it before statements use it.
+type Expect struct {
+	f        *Fake
+	fragment string
+	args     []any
+	match    func(query string, args []any) bool
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	lastID   int64
+	err      error
+	times    int
+	calls    int
+}
+
+func (e *Expect) matches(query string, args []any) bool {
+	if !contains(query, e.fragment) {
+		return false
+	}
+	if e.args != nil && !argsEqual(e.f.t, args, e.args) {
+		return false
+	}
+	return e.match == nil || e.match(query, args)
+}
+
+// WithArgs matches statements with exactly these arguments.
+func (e *Expect) WithArgs(args ...any) *Expect {
+	e.args = append([]any{}, args...)
+	return e
+}
+
+// Match adds a custom matcher.
+func (e *Expect) Match(fn func(query string, args []any) bool) *Expect {
+	e.match = fn
+	return e
+}
+
+// Columns sets the columns of the returned rows.
+func (e *Expect) Columns(cols ...string) *Expect {
+	e.columns = cols
+	return e
+}
+
+// Row adds a returned row with one value per column.
+func (e *Expect) Row(values ...any) *Expect {
+	row := make([]driver.Value, len(values))
+	for i, v := range values {
+		row[i] = convert(e.f.t, v)
+	}
+	e.rows = append(e.rows, row)
+	return e
+}
+
+// Result sets the sql.Result of statements that return no rows.
+func (e *Expect) Result(lastInsertID, rowsAffected int64) *Expect {
+	e.lastID, e.affected = lastInsertID, rowsAffected
+	return e
+}
+
+// Error fails matching statements with err.
+func (e *Expect) Error(err error) *Expect {
+	e.err = err
+	return e
+}
+
+// Times makes the expectation answer n statements, after which later
+// expectations and the tables are tried; the test fails if fewer than n
+// arrive.
+func (e *Expect) Times(n int) *Expect {
+	e.times = n
+	return e
+}
+
+// Once is Times(1).
+func (e *Expect) Once() *Expect { return e.Times(1) }
+
+// exec records and answers a statement.
+func (f *Fake) exec(query string, args []driver.NamedValue) (outcome, error) {
+	values := make([]any, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Query: query, Args: values})
+	for _, e := range f.expects {
+		if (e.times > 0 && e.calls >= e.times) || !e.matches(query, values) {
+			continue
+		}
+		e.calls++
+		if e.err != nil {
+			return outcome{}, e.err
+		}
+		return outcome{columns: e.columns, rows: e.rows, affected: e.affected, lastID: e.lastID}, nil
+	}
+	out, err := run(f.tables, query, args)
+	if _, ok := err.(errUnsupported); ok {
+		f.t.Errorf("dbtest: cannot answer %q: %v; register it with On", query, err)
+		return outcome{}, fmt.Errorf("dbtest: unexpected statement %q", query)
+	}
+	return out, err
+}
+
+// begin snapshots the tables for a rollback.
+func (f *Fake) begin() map[string]*table {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Query: "BEGIN"})
+	snap := make(map[string]*table, len(f.tables))
+	for name, t := range f.tables {
+		snap[name] = t.clone()
+	}
+	return snap
+}
+
+// end records a commit, or a rollback restoring snap.
+func (f *Fake) end(commit bool, snap map[string]*table) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if commit {
+		f.calls = append(f.calls, Call{Query: "COMMIT"})
+		return
+	}
+	f.calls = append(f.calls, Call{Query: "ROLLBACK"})
+	for name, t := range snap {
+		*f.tables[name] = *t
+	}
+}
+
+type connector struct{ f *Fake }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{f: c.f}, nil }
+func (c connector) Driver() driver.Driver                        { return nil }
+
+// conn is a connection to a Fake. A rollback restores every table as it
+// was at BEGIN, including writes made meanwhile on other connections.
+type conn struct {
+	f    *Fake
+	snap map[string]*table
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("dbtest: prepared statements are not supported")
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	c.snap = c.f.begin()
+	return c, nil
+}
+
+func (c *conn) Commit() error {
+	c.f.end(true, nil)
+	c.snap = nil
+	return nil
+}
+
+func (c *conn) Rollback() error {
+	c.f.end(false, c.snap)
+	c.snap = nil
+	return nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	out, err := c.f.exec(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return result(out), nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	out, err := c.f.exec(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{columns: out.columns, rows: slices.Clone(out.rows)}, nil
+}
+
+type result outcome
+
+func (r result) LastInsertId() (int64, error) { return r.lastID, nil }
+func (r result) RowsAffected() (int64, error) { return r.affected, nil }
+
+type rows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
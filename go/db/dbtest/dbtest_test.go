@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/db/repository"
+)
+
+type user struct {
+	ID        int64 `db:"id,pk"`
+	Name      string
+	Email     *string
+	CreatedAt time.Time `db:",readonly"`
+}
+
+var created = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func TestRepositoryOnTables(t *testing.T) {
+	for _, driver := range []string{"pgx", "mysql", "sqlite"} {
+		t.Run(driver, func(t *testing.T) {
+			ctx := context.Background()
+			fake := New(t, WithDriver(driver))
+			ann := "ann@example.com"
+			fake.Table("users", user{ID: 1, Name: "Ann", Email: &ann, CreatedAt: created}, user{ID: 2, Name: "Bob", CreatedAt: created}).
+				Default("created_at", created)
+			users, err := repository.New[user](fake, "users")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			u, err := users.FindByID(ctx, 1)
+			if err != nil || u.Name != "Ann" || *u.Email != ann {
+				t.Errorf("FindByID = %+v, %v", u, err)
+			}
+			if _, err := users.FindByID(ctx, 9); !errors.Is(err, sql.ErrNoRows) {
+				t.Errorf("FindByID missing = %v", err)
+			}
+			noEmail, err := users.List(ctx, repository.Where("email", nil))
+			if err != nil || len(noEmail) != 1 || noEmail[0].Name != "Bob" {
+				t.Errorf("List IS NULL = %+v, %v", noEmail, err)
+			}
+
+			cy := user{Name: "Cy"}
+			if err := users.Create(ctx, &cy); err != nil || cy.ID != 3 {
+				t.Fatalf("Create = %+v, %v", cy, err)
+			}
+			cy.Name = "Cyrus"
+			if err := users.Update(ctx, &cy); err != nil {
+				t.Error(err)
+			}
+			if err := users.Delete(ctx, 2); err != nil {
+				t.Error(err)
+			}
+			all, err := users.List(ctx, repository.OrderByDesc("id"), repository.Limit(5))
+			if err != nil || len(all) != 2 || all[0].Name != "Cyrus" || all[1].Name != "Ann" {
+				t.Errorf("List = %+v, %v", all, err)
+			}
+			fake.AssertCallCount(t, "DELETE FROM", 1)
+			fake.AssertCalled(t, "WHERE", int64(1))
+			fake.AssertNotCalled(t, "DROP")
+		})
+	}
+}
+
+func TestExpectations(t *testing.T) {
+	ctx := context.Background()
+	fake := New(t)
+	fake.On("SELECT count(*) FROM users").Columns("count").Row(42)
+	fake.On("UPDATE accounts").WithArgs(100, "alice").Result(0, 1)
+	fake.On("UPDATE accounts").Error(errors.New("deadlock"))
+
+	var n int
+	if err := fake.QueryRow(ctx, "SELECT COUNT(*)\n  FROM users").Scan(&n); err != nil || n != 42 {
+		t.Errorf("count = %d, %v", n, err)
+	}
+	res, err := fake.Exec(ctx, "UPDATE accounts SET balance = $1 WHERE owner = $2", 100, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("RowsAffected = %d", n)
+	}
+	if _, err := fake.Exec(ctx, "UPDATE accounts SET balance = $1 WHERE owner = $2", 5, "bob"); err == nil || err.Error() != "db: exec: deadlock" {
+		t.Errorf("error expectation = %v", err)
+	}
+	if len(fake.Calls()) != 3 {
+		t.Errorf("calls = %v", fake.Calls())
+	}
+	fake.Reset()
+	if len(fake.Calls()) != 0 {
+		t.Error("Reset kept calls")
+	}
+}
+
+func TestTxRollbackRestoresTables(t *testing.T) {
+	ctx := context.Background()
+	fake := New(t)
+	fake.Table("accounts", map[string]any{"id": 1, "balance": 100})
+
+	err := fake.Tx(ctx, nil, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE accounts SET balance = $1 WHERE id = $2", 0, 1); err != nil {
+			return err
+		}
+		return errors.New("insufficient funds")
+	})
+	if err == nil {
+		t.Fatal("Tx succeeded")
+	}
+	if rows := fake.Rows("accounts"); rows[0]["balance"] != int64(100) {
+		t.Errorf("rows after rollback = %v", rows)
+	}
+	err = fake.Tx(ctx, nil, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO accounts (balance) VALUES ($1), ($2)", 5, 6)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := fake.Rows("accounts")
+	if len(rows) != 3 || rows[2]["id"] != int64(3) || rows[2]["balance"] != int64(6) {
+		t.Errorf("rows after commit = %v", rows)
+	}
+	var got []string
+	for _, c := range fake.Calls() {
+		got = append(got, c.Query[:min(len(c.Query), 6)])
+	}
+	if want := "[BEGIN UPDATE ROLLBA BEGIN INSERT COMMIT]"; fmt.Sprint(got) != want {
+		t.Errorf("calls = %v, want %s", got, want)
+	}
+}
+
+func TestDatabaseErrors(t *testing.T) {
+	ctx := context.Background()
+	fake := New(t)
+	fake.Table("users").Columns("id", "name")
+	for _, q := range []string{
+		"SELECT * FROM missing",
+		"SELECT nope FROM users",
+		"INSERT INTO users (nope) VALUES (1)",
+	} {
+		if _, err := fake.Exec(ctx, q); err == nil {
+			t.Errorf("%s: no error", q)
+		}
+	}
+}
+
+func TestUnsupportedStatementFailsTest(t *testing.T) {
+	ft := &fakeT{TB: t}
+	fake := New(ft)
+	fake.On("never").Once()
+	fake.Table("users").Columns("id")
+	if _, err := fake.Exec(context.Background(), "SELECT u.id FROM users u JOIN orders o ON o.user_id = u.id"); err == nil {
+		t.Error("join succeeded")
+	}
+	ft.cleanup()
+	if len(ft.errors) != 2 {
+		t.Errorf("errors = %q", ft.errors)
+	}
+}
+
+// fakeT collects failures and cleanups instead of failing the test.
+type fakeT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeT) Errorf(format string, args ...any) { f.errors = append(f.errors, format) }
+func (f *fakeT) Cleanup(fn func())                 { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeT) cleanup() {
+	for _, fn := range f.cleanups {
+		fn()
+	}
+}
@@ -0,0 +1,779 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package dbtest
+
+import (
+	"bytes"
+	"cmp"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The engine runs the statements the db and repository packages generate:
+//
+//	SELECT cols|* FROM t [WHERE cond AND ...] [ORDER BY col [ASC|DESC], ...] [LIMIT n [OFFSET m]]
+//	INSERT INTO t (cols) VALUES (vals)[, (vals)...] [RETURNING cols]
+//	UPDATE t SET col = val, ... [WHERE cond AND ...] [RETURNING cols]
+//	DELETE FROM t [WHERE cond AND ...]
+//
+// where a cond is col = val, col IS NULL or col IS NOT NULL and a val is
+// a ? or $n parameter, a number, a 'string', NULL, TRUE or FALSE.
+
+// errUnsupported marks statements outside the subset.
+type errUnsupported struct{ msg string }
+
+func (e errUnsupported) Error() string { return e.msg }
+
+type table struct {
+	columns  []string
+	rows     []map[string]driver.Value
+	defaults map[string]driver.Value
+}
+
+func (t *table) has(col string) bool { return slices.Contains(t.columns, col) }
+
+func (t *table) addColumn(col string) {
+	if !t.has(col) {
+		t.columns = append(t.columns, col)
+	}
+}
+
+func (t *table) clone() *table {
+	c := &table{columns: slices.Clone(t.columns), defaults: t.defaults}
+	for _, r := range t.rows {
+		c.rows = append(c.rows, cloneRow(r))
+	}
+	return c
+}
+
+func cloneRow(r map[string]driver.Value) map[string]driver.Value {
+	c := make(map[string]driver.Value, len(r))
+	for k, v := range r {
+		c[k] = v
+	}
+	return c
+}
+
+// nextID returns one more than the largest integer id in t.
+func (t *table) nextID() int64 {
+	var id int64
+	for _, r := range t.rows {
+		if v, ok := r["id"].(int64); ok && v > id {
+			id = v
+		}
+	}
+	return id + 1
+}
+
+// outcome is the result of a statement.
+type outcome struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	lastID   int64
+}
+
+type token struct {
+	kind byte // 'w' word, 'i' quoted identifier, 's' string, 'n' number, 'p' parameter, or the punctuation itself
+	text string
+}
+
+func tokenize(query string) ([]token, error) {
+	var toks []token
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(rs) && (rs[j] == '_' || unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			toks = append(toks, token{'w', string(rs[i:j])})
+			i = j
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i + 1
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{'n', string(rs[i:j])})
+			i = j
+		case r == '\'' || r == '"' || r == '`':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(rs); j++ {
+				if rs[j] == r {
+					if j+1 < len(rs) && rs[j+1] == r {
+						b.WriteRune(r)
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, errUnsupported{"unterminated quote"}
+			}
+			kind := byte('i')
+			if r == '\'' {
+				kind = 's'
+			}
+			toks = append(toks, token{kind, b.String()})
+			i = j + 1
+		case r == '?':
+			toks = append(toks, token{'p', ""})
+			i++
+		case r == '$':
+			j := i + 1
+			for j < len(rs) && unicode.IsDigit(rs[j]) {
+				j++
+			}
+			if j == i+1 {
+				return nil, errUnsupported{"bare $"}
+			}
+			toks = append(toks, token{'p', string(rs[i+1 : j])})
+			i = j
+		case strings.ContainsRune("(),=*.;", r):
+			toks = append(toks, token{byte(r), string(r)})
+			i++
+		default:
+			return nil, errUnsupported{fmt.Sprintf("unexpected %q", r)}
+		}
+	}
+	return toks, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+	args []driver.NamedValue
+	next int // ordinal of the next ? parameter
+}
+
+func (p *parser) peek() token {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return token{}
+}
+
+// keyword consumes the keyword kw if it is next.
+func (p *parser) keyword(kw string) bool {
+	if t := p.peek(); t.kind == 'w' && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind byte, what string) error {
+	if p.peek().kind != kind {
+		return p.fail(what)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) fail(want string) error {
+	got := "end of statement"
+	if t := p.peek(); t.kind != 0 {
+		got = strconv.Quote(t.text)
+	}
+	return errUnsupported{fmt.Sprintf("expected %s, got %s", want, got)}
+}
+
+var reserved = map[string]bool{
+	"select": true, "from": true, "where": true, "and": true, "order": true, "by": true, "limit": true,
+	"offset": true, "insert": true, "into": true, "values": true, "update": true, "set": true,
+	"delete": true, "returning": true, "is": true, "not": true, "null": true,
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.peek()
+	if t.kind == 'i' || (t.kind == 'w' && !reserved[strings.ToLower(t.text)]) {
+		p.pos++
+		if t.kind == 'w' {
+			return strings.ToLower(t.text), nil
+		}
+		return t.text, nil
+	}
+	return "", p.fail("a name")
+}
+
+// name parses a possibly qualified name such as app.users.
+func (p *parser) name() (string, error) {
+	parts := []string{}
+	for {
+		s, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, s)
+		if p.peek().kind != '.' {
+			return strings.Join(parts, "."), nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) names() ([]string, error) {
+	var out []string
+	for {
+		n, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+		if p.peek().kind != ',' {
+			return out, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) value() (driver.Value, error) {
+	t := p.peek()
+	switch {
+	case t.kind == 'p':
+		p.pos++
+		n := p.next
+		if t.text != "" {
+			i, _ := strconv.Atoi(t.text)
+			n = i - 1
+		} else {
+			p.next++
+		}
+		if n < 0 || n >= len(p.args) {
+			return nil, fmt.Errorf("dbtest: parameter %d of %d", n+1, len(p.args))
+		}
+		return p.args[n].Value, nil
+	case t.kind == 's':
+		p.pos++
+		return t.text, nil
+	case t.kind == 'n':
+		p.pos++
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, errUnsupported{"bad number " + t.text}
+		}
+		return f, nil
+	case p.keyword("null"):
+		return nil, nil
+	case p.keyword("true"):
+		return true, nil
+	case p.keyword("false"):
+		return false, nil
+	}
+	return nil, p.fail("a value")
+}
+
+// count parses a LIMIT or OFFSET value.
+func (p *parser) count() (int64, error) {
+	v, err := p.value()
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(int64)
+	if !ok || n < 0 {
+		return 0, errUnsupported{fmt.Sprintf("invalid count %v", v)}
+	}
+	return n, nil
+}
+
+type condition struct {
+	column string
+	op     string // "=", "null" or "not null"
+	value  driver.Value
+}
+
+func (p *parser) where() ([]condition, error) {
+	if !p.keyword("where") {
+		return nil, nil
+	}
+	var conds []condition
+	for {
+		col, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		c := condition{column: col}
+		switch {
+		case p.keyword("is"):
+			c.op = "null"
+			if p.keyword("not") {
+				c.op = "not null"
+			}
+			if !p.keyword("null") {
+				return nil, p.fail("NULL")
+			}
+		default:
+			if err := p.expect('=', "="); err != nil {
+				return nil, err
+			}
+			c.op = "="
+			if c.value, err = p.value(); err != nil {
+				return nil, err
+			}
+		}
+		conds = append(conds, c)
+		if !p.keyword("and") {
+			return conds, nil
+		}
+	}
+}
+
+func (c condition) holds(row map[string]driver.Value) bool {
+	v := row[c.column]
+	switch c.op {
+	case "null":
+		return v == nil
+	case "not null":
+		return v != nil
+	}
+	return v != nil && c.value != nil && compare(v, c.value) == 0
+}
+
+func (p *parser) returning() ([]string, error) {
+	if !p.keyword("returning") {
+		return nil, nil
+	}
+	return p.names()
+}
+
+func (p *parser) end() error {
+	if p.peek().kind == ';' {
+		p.pos++
+	}
+	if p.pos != len(p.toks) {
+		return p.fail("end of statement")
+	}
+	return nil
+}
+
+// run parses query and executes it against tables.
+func run(tables map[string]*table, query string, args []driver.NamedValue) (outcome, error) {
+	toks, err := tokenize(query)
+	if err != nil {
+		return outcome{}, err
+	}
+	p := &parser{toks: toks, args: args}
+	switch {
+	case p.keyword("select"):
+		return p.runSelect(tables)
+	case p.keyword("insert"):
+		return p.runInsert(tables)
+	case p.keyword("update"):
+		return p.runUpdate(tables)
+	case p.keyword("delete"):
+		return p.runDelete(tables)
+	}
+	return outcome{}, p.fail("SELECT, INSERT, UPDATE or DELETE")
+}
+
+func lookup(tables map[string]*table, name string) (*table, error) {
+	t, ok := tables[name]
+	if !ok {
+		return nil, fmt.Errorf("dbtest: no such table %q", name)
+	}
+	return t, nil
+}
+
+func (t *table) check(name string, cols ...string) error {
+	for _, c := range cols {
+		if !t.has(c) {
+			return fmt.Errorf("dbtest: table %q has no column %q", name, c)
+		}
+	}
+	return nil
+}
+
+func (t *table) checkConds(name string, conds []condition) error {
+	for _, c := range conds {
+		if err := t.check(name, c.column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func project(rows []map[string]driver.Value, cols []string) [][]driver.Value {
+	out := make([][]driver.Value, len(rows))
+	for i, r := range rows {
+		out[i] = make([]driver.Value, len(cols))
+		for j, c := range cols {
+			out[i][j] = r[c]
+		}
+	}
+	return out
+}
+
+func (p *parser) runSelect(tables map[string]*table) (outcome, error) {
+	var cols []string
+	if p.peek().kind == '*' {
+		p.pos++
+	} else {
+		var err error
+		if cols, err = p.names(); err != nil {
+			return outcome{}, err
+		}
+	}
+	if !p.keyword("from") {
+		return outcome{}, p.fail("FROM")
+	}
+	name, err := p.name()
+	if err != nil {
+		return outcome{}, err
+	}
+	conds, err := p.where()
+	if err != nil {
+		return outcome{}, err
+	}
+	type order struct {
+		column string
+		desc   bool
+	}
+	var orders []order
+	if p.keyword("order") {
+		if !p.keyword("by") {
+			return outcome{}, p.fail("BY")
+		}
+		for {
+			col, err := p.ident()
+			if err != nil {
+				return outcome{}, err
+			}
+			o := order{column: col}
+			if p.keyword("desc") {
+				o.desc = true
+			} else {
+				p.keyword("asc")
+			}
+			orders = append(orders, o)
+			if p.peek().kind != ',' {
+				break
+			}
+			p.pos++
+		}
+	}
+	limit, offset := int64(-1), int64(0)
+	if p.keyword("limit") {
+		var err error
+		if limit, err = p.count(); err != nil {
+			return outcome{}, err
+		}
+		if p.keyword("offset") {
+			if offset, err = p.count(); err != nil {
+				return outcome{}, err
+			}
+		}
+	}
+	if err := p.end(); err != nil {
+		return outcome{}, err
+	}
+
+	t, err := lookup(tables, name)
+	if err != nil {
+		return outcome{}, err
+	}
+	if cols == nil {
+		cols = slices.Clone(t.columns)
+	}
+	if err := t.check(name, cols...); err != nil {
+		return outcome{}, err
+	}
+	if err := t.checkConds(name, conds); err != nil {
+		return outcome{}, err
+	}
+	var rows []map[string]driver.Value
+	for _, r := range t.rows {
+		if matches(r, conds) {
+			rows = append(rows, r)
+		}
+	}
+	for _, o := range orders {
+		if err := t.check(name, o.column); err != nil {
+			return outcome{}, err
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b map[string]driver.Value) int {
+		for _, o := range orders {
+			c := compare(a[o.column], b[o.column])
+			if o.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	rows = rows[min(int(offset), len(rows)):]
+	if limit >= 0 {
+		rows = rows[:min(int(limit), len(rows))]
+	}
+	return outcome{columns: cols, rows: project(rows, cols)}, nil
+}
+
+func matches(row map[string]driver.Value, conds []condition) bool {
+	for _, c := range conds {
+		if !c.holds(row) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) runInsert(tables map[string]*table) (outcome, error) {
+	if !p.keyword("into") {
+		return outcome{}, p.fail("INTO")
+	}
+	name, err := p.name()
+	if err != nil {
+		return outcome{}, err
+	}
+	if err := p.expect('(', "("); err != nil {
+		return outcome{}, err
+	}
+	cols, err := p.names()
+	if err != nil {
+		return outcome{}, err
+	}
+	if err := p.expect(')', ")"); err != nil {
+		return outcome{}, err
+	}
+	if !p.keyword("values") {
+		return outcome{}, p.fail("VALUES")
+	}
+	var tuples [][]driver.Value
+	for {
+		if err := p.expect('(', "("); err != nil {
+			return outcome{}, err
+		}
+		var vals []driver.Value
+		for {
+			v, err := p.value()
+			if err != nil {
+				return outcome{}, err
+			}
+			vals = append(vals, v)
+			if p.peek().kind != ',' {
+				break
+			}
+			p.pos++
+		}
+		if err := p.expect(')', ")"); err != nil {
+			return outcome{}, err
+		}
+		if len(vals) != len(cols) {
+			return outcome{}, fmt.Errorf("dbtest: %d values for %d columns", len(vals), len(cols))
+		}
+		tuples = append(tuples, vals)
+		if p.peek().kind != ',' {
+			break
+		}
+		p.pos++
+	}
+	ret, err := p.returning()
+	if err != nil {
+		return outcome{}, err
+	}
+	if err := p.end(); err != nil {
+		return outcome{}, err
+	}
+
+	t, err := lookup(tables, name)
+	if err != nil {
+		return outcome{}, err
+	}
+	if err := t.check(name, append(slices.Clone(cols), ret...)...); err != nil {
+		return outcome{}, err
+	}
+	out := outcome{columns: ret}
+	for _, vals := range tuples {
+		row := t.newRow()
+		for i, c := range cols {
+			row[c] = vals[i]
+		}
+		if id, ok := row["id"].(int64); ok {
+			out.lastID = id
+		}
+		t.rows = append(t.rows, row)
+		out.affected++
+		if ret != nil {
+			out.rows = append(out.rows, project([]map[string]driver.Value{row}, ret)...)
+		}
+	}
+	return out, nil
+}
+
+// newRow returns a row holding the column defaults and, if the table has
+// an id column, the next id.
+func (t *table) newRow() map[string]driver.Value {
+	row := cloneRow(t.defaults)
+	if t.has("id") {
+		row["id"] = t.nextID()
+	}
+	return row
+}
+
+func (p *parser) runUpdate(tables map[string]*table) (outcome, error) {
+	name, err := p.name()
+	if err != nil {
+		return outcome{}, err
+	}
+	if !p.keyword("set") {
+		return outcome{}, p.fail("SET")
+	}
+	sets := map[string]driver.Value{}
+	var setCols []string
+	for {
+		col, err := p.ident()
+		if err != nil {
+			return outcome{}, err
+		}
+		if err := p.expect('=', "="); err != nil {
+			return outcome{}, err
+		}
+		v, err := p.value()
+		if err != nil {
+			return outcome{}, err
+		}
+		sets[col] = v
+		setCols = append(setCols, col)
+		if p.peek().kind != ',' {
+			break
+		}
+		p.pos++
+	}
+	conds, err := p.where()
+	if err != nil {
+		return outcome{}, err
+	}
+	ret, err := p.returning()
+	if err != nil {
+		return outcome{}, err
+	}
+	if err := p.end(); err != nil {
+		return outcome{}, err
+	}
+
+	t, err := lookup(tables, name)
+	if err != nil {
+		return outcome{}, err
+	}
+	if err := t.check(name, append(setCols, ret...)...); err != nil {
+		return outcome{}, err
+	}
+	if err := t.checkConds(name, conds); err != nil {
+		return outcome{}, err
+	}
+	out := outcome{columns: ret}
+	for _, r := range t.rows {
+		if !matches(r, conds) {
+			continue
+		}
+		for c, v := range sets {
+			r[c] = v
+		}
+		out.affected++
+		if ret != nil {
+			out.rows = append(out.rows, project([]map[string]driver.Value{r}, ret)...)
+		}
+	}
+	return out, nil
+}
+
+func (p *parser) runDelete(tables map[string]*table) (outcome, error) {
+	if !p.keyword("from") {
+		return outcome{}, p.fail("FROM")
+	}
+	name, err := p.name()
+	if err != nil {
+		return outcome{}, err
+	}
+	conds, err := p.where()
+	if err != nil {
+		return outcome{}, err
+	}
+	if err := p.end(); err != nil {
+		return outcome{}, err
+	}
+	t, err := lookup(tables, name)
+	if err != nil {
+		return outcome{}, err
+	}
+	if err := t.checkConds(name, conds); err != nil {
+		return outcome{}, err
+	}
+	n := len(t.rows)
+	t.rows = slices.DeleteFunc(t.rows, func(r map[string]driver.Value) bool { return matches(r, conds) })
+	return outcome{affected: int64(n - len(t.rows))}, nil
+}
+
+// compare orders driver values; nil sorts first and values of different
+// types compare by their formatted text.
+func compare(a, b driver.Value) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmp.Compare(x, y)
+		case float64:
+			return cmp.Compare(float64(x), y)
+		}
+	case float64:
+		switch y := b.(type) {
+		case float64:
+			return cmp.Compare(x, y)
+		case int64:
+			return cmp.Compare(x, float64(y))
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return cmp.Compare(boolInt(x), boolInt(y))
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y)
+		}
+		if y, ok := b.(string); ok {
+			return strings.Compare(string(x), y)
+		}
+	case string:
+		if y, ok := b.([]byte); ok {
+			return strings.Compare(x, string(y))
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
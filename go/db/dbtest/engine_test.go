@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package dbtest
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+func TestEngine(t *testing.T) {
+	tables := map[string]*table{"app.items": {
+		columns: []string{"id", "name", "qty"},
+		rows: []map[string]driver.Value{
+			{"id": int64(1), "name": "it's", "qty": int64(5)},
+			{"id": int64(2), "name": "b", "qty": nil},
+			{"id": int64(3), "name": "c", "qty": int64(5)},
+		},
+	}}
+	tests := []struct {
+		query string
+		args  []any
+		want  string
+	}{
+		{`SELECT name FROM app.items WHERE name = 'it''s'`, nil, "[[it's]]"},
+		{`select "id" from "app"."items" where qty = ? and id = ?`, []any{5, 3}, "[[3]]"},
+		{`SELECT id FROM app.items WHERE qty IS NOT NULL ORDER BY qty DESC, id DESC LIMIT 1 OFFSET 1;`, nil, "[[1]]"},
+		{`SELECT id FROM app.items ORDER BY qty LIMIT $1`, []any{1}, "[[2]]"},
+		{`UPDATE app.items SET qty = NULL WHERE qty = 5 RETURNING id`, nil, "[[1] [3]]"},
+		{`SELECT count(*) FROM app.items`, nil, "error"},
+		{`SELECT id FROM app.items WHERE id > 1`, nil, "error"},
+	}
+	for _, tt := range tests {
+		args := make([]driver.NamedValue, len(tt.args))
+		for i, a := range tt.args {
+			args[i] = driver.NamedValue{Ordinal: i + 1, Value: int64(a.(int))}
+		}
+		out, err := run(tables, tt.query, args)
+		got := fmt.Sprint(out.rows)
+		if err != nil {
+			got = "error"
+		}
+		if got != tt.want {
+			t.Errorf("%s = %s (%v), want %s", tt.query, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Usage describes the arguments accepted by Run.
+const Usage = `usage: migrate <command> [arg]
+
+commands:
+  up [version]  apply pending migrations, up to version if given
+  down [n]      roll back the last n migrations (default 1)
+  status        list migrations and whether they are applied`
+
+// Run executes a migrate command line such as "up", "down 2" or "status"
+// and writes its report to w. It is the implementation behind the migrate
+// commands of a foundation CLI, and can be called directly from an
+// application's own main.
+func Run(ctx context.Context, m *Migrator, args []string, w io.Writer) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("migrate: expected a command\n%s", Usage)
+	}
+	arg := func(def int64) (int64, error) {
+		if len(args) < 2 {
+			return def, nil
+		}
+		n, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("migrate: %s: invalid argument %q", args[0], args[1])
+		}
+		return n, nil
+	}
+	switch args[0] {
+	case "up":
+		version, err := arg(-1)
+		if err != nil {
+			return err
+		}
+		n, err := m.UpTo(ctx, version)
+		fmt.Fprintf(w, "applied %d migration(s)\n", n)
+		return err
+	case "down":
+		steps, err := arg(1)
+		if err != nil {
+			return err
+		}
+		n := int64(0)
+		for ; n < steps; n++ {
+			if err = m.Down(ctx); err != nil {
+				break
+			}
+		}
+		fmt.Fprintf(w, "rolled back %d migration(s)\n", n)
+		if errors.Is(err, ErrNoChange) && n > 0 {
+			return nil
+		}
+		return err
+	case "status":
+		if len(args) > 1 {
+			return fmt.Errorf("migrate: status takes no argument")
+		}
+		st, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS")
+		for _, s := range st {
+			state, name := "pending", s.Name
+			if s.Applied {
+				state = "applied " + s.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			if s.Missing {
+				name = "(missing)"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", s.Version, name, state)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("migrate: unknown command %q\n%s", args[0], Usage)
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	m := newMigrator(t, openFake(t), Migration{Version: 4, Name: "reversible", Up: seed, Down: seed})
+	run := func(args ...string) (string, error) {
+		var out strings.Builder
+		err := Run(ctx, m, args, &out)
+		return out.String(), err
+	}
+
+	if out, err := run("up", "1"); err != nil || out != "applied 1 migration(s)\n" {
+		t.Errorf("up 1 = %q, %v", out, err)
+	}
+	out, err := run("status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "VERSION") ||
+		!strings.Contains(lines[1], "create_users  applied ") || !strings.HasSuffix(lines[2], "seed          pending") {
+		t.Errorf("status =\n%s", out)
+	}
+
+	run("up")
+	delete(store.applied, 2) // seed has no down; skip it
+	if out, err := run("down", "5"); err != nil || out != "rolled back 3 migration(s)\n" {
+		t.Errorf("down 5 = %q, %v", out, err)
+	}
+	if _, err := run("down"); err != ErrNoChange {
+		t.Errorf("down with nothing applied = %v", err)
+	}
+
+	for _, args := range [][]string{nil, {"sideways"}, {"up", "x"}, {"down", "-1"}, {"status", "1"}} {
+		if _, err := run(args...); err == nil {
+			t.Errorf("%q: no error", args)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package migrate applies versioned schema migrations and records them in
+// a table, so every application built on the foundation migrates the same
+// way. Migrations are SQL files, usually embedded,
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//
+//	m, err := migrate.New(database, migrate.WithFS(migrations, "migrations"))
+//	applied, err := m.Up(ctx)
+//
+// named 0001_create_users.up.sql and 0001_create_users.down.sql, or Go
+// functions added with WithMigrations. Each migration runs in its own
+// transaction together with its bookkeeping row.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/db"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// DefaultTable is the table that records applied migrations.
+const DefaultTable = "schema_migrations"
+
+// ErrNoChange is returned by Down when no migration is applied.
+var ErrNoChange = errors.New("migrate: no migration to roll back")
+
+// Migration is one schema change. Down may be nil for irreversible
+// migrations.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(ctx context.Context, tx *sql.Tx) error
+	Down    func(ctx context.Context, tx *sql.Tx) error
+}
+
+// Option configures a Migrator.
+type Option func(*Migrator)
+
+// WithFS loads SQL migrations from dir in fsys. Files are named
+// VERSION_NAME.up.sql and VERSION_NAME.down.sql; other files are ignored.
+// Each file is executed as one batch, so drivers that need it must allow
+// multiple statements, e.g. multiStatements=true for MySQL.
+func WithFS(fsys fs.FS, dir string) Option {
+	return func(m *Migrator) { m.sources = append(m.sources, fsSource{fsys, dir}) }
+}
+
+// WithMigrations adds migrations written in Go.
+func WithMigrations(ms ...Migration) Option {
+	return func(m *Migrator) { m.migrations = append(m.migrations, ms...) }
+}
+
+// WithTable sets the bookkeeping table. The default is DefaultTable.
+func WithTable(name string) Option {
+	return func(m *Migrator) { m.table = name }
+}
+
+// WithLogger sets the logger for migration events. The default is
+// log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(m *Migrator) { m.logger = l }
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	db         *db.DB
+	table      string
+	logger     *log.Logger
+	sources    []fsSource
+	migrations []Migration
+
+	create, list, insert, remove string
+}
+
+type fsSource struct {
+	fsys fs.FS
+	dir  string
+}
+
+// New loads the migrations and checks that versions are unique.
+func New(database *db.DB, opts ...Option) (*Migrator, error) {
+	m := &Migrator{db: database, table: DefaultTable}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.logger == nil {
+		m.logger = log.Default()
+	}
+	if !identifier.MatchString(m.table) {
+		return nil, fmt.Errorf("migrate: invalid table name %q", m.table)
+	}
+	// The table name is checked above and cannot be a bind parameter.
+	m.create = "CREATE TABLE IF NOT EXISTS " + m.table +
+		" (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)"
+	m.list = "SELECT version, applied_at FROM " + m.table
+	m.insert = "INSERT INTO " + m.table + " (version, name, applied_at) VALUES (:version, :name, :applied_at)"
+	m.remove = "DELETE FROM " + m.table + " WHERE version = :version"
+	for _, src := range m.sources {
+		ms, err := loadFS(src.fsys, src.dir)
+		if err != nil {
+			return nil, err
+		}
+		m.migrations = append(m.migrations, ms...)
+	}
+	slices.SortFunc(m.migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	for i := 1; i < len(m.migrations); i++ {
+		if m.migrations[i].Version == m.migrations[i-1].Version {
+			return nil, fmt.Errorf("migrate: duplicate version %d", m.migrations[i].Version)
+		}
+	}
+	return m, nil
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+func loadFS(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	byVersion := make(map[int64]*Migration)
+	var out []*Migration
+	for _, e := range entries {
+		match := fileName.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %s: %w", e.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+			out = append(out, mig)
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migrate: version %d has two names, %q and %q", version, mig.Name, match[2])
+		}
+		fn := execSQL(string(data))
+		if match[3] == "up" {
+			mig.Up = fn
+		} else {
+			mig.Down = fn
+		}
+	}
+	ms := make([]Migration, 0, len(out))
+	for _, mig := range out {
+		if mig.Up == nil {
+			return nil, fmt.Errorf("migrate: version %d (%s) has no up file", mig.Version, mig.Name)
+		}
+		ms = append(ms, *mig)
+	}
+	return ms, nil
+}
+
+func execSQL(query string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, query)
+		return err
+	}
+}
+
+// Status describes a migration and whether it is applied.
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Missing marks a version recorded in the database that no known
+	// migration has, e.g. after a file was deleted.
+	Missing bool
+}
+
+// Status lists every known migration and every applied version, sorted by
+// version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Status
+	for _, mig := range m.migrations {
+		at, ok := applied[mig.Version]
+		out = append(out, Status{Version: mig.Version, Name: mig.Name, Applied: ok, AppliedAt: at})
+		delete(applied, mig.Version)
+	}
+	for v, at := range applied {
+		out = append(out, Status{Version: v, Applied: true, AppliedAt: at, Missing: true})
+	}
+	slices.SortFunc(out, func(a, b Status) int { return cmp.Compare(a.Version, b.Version) })
+	return out, nil
+}
+
+// Up applies every pending migration in version order and returns how
+// many it applied. It stops at the first failure, leaving that migration
+// unapplied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.UpTo(ctx, -1)
+}
+
+// UpTo is Up for the migrations up to and including version; -1 means
+// all.
+func (m *Migrator) UpTo(ctx context.Context, version int64) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, mig := range m.migrations {
+		if version >= 0 && mig.Version > version {
+			break
+		}
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if err := m.apply(ctx, mig, true); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	for _, mig := range slices.Backward(m.migrations) {
+		if _, ok := applied[mig.Version]; ok {
+			if mig.Down == nil {
+				return fmt.Errorf("migrate: version %d (%s) is irreversible", mig.Version, mig.Name)
+			}
+			return m.apply(ctx, mig, false)
+		}
+	}
+	return ErrNoChange
+}
+
+func (m *Migrator) apply(ctx context.Context, mig Migration, up bool) error {
+	direction, fn := "up", mig.Up
+	if !up {
+		direction, fn = "down", mig.Down
+	}
+	logger := m.logger.With("migration.version", mig.Version, "migration.name", mig.Name, "migration.direction", direction)
+	start := time.Now()
+	err := m.db.Tx(ctx, nil, func(tx *sql.Tx) error {
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+		stmt := m.insert
+		if !up {
+			stmt = m.remove
+		}
+		q, args, err := m.db.Named(stmt, map[string]any{
+			"version": mig.Version, "name": mig.Name, "applied_at": time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, q, args...)
+		return err
+	})
+	if err != nil {
+		logger.ErrorCtx(ctx, "migration_failed", log.Err(err))
+		return fmt.Errorf("migrate: %s %d (%s): %w", direction, mig.Version, mig.Name, err)
+	}
+	logger.InfoCtx(ctx, "migration_applied", "duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+// applied creates the bookkeeping table if needed and returns the applied
+// versions.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if _, err := m.db.Exec(ctx, m.create); err != nil {
+		return nil, fmt.Errorf("migrate: creating %s: %w", m.table, err)
+	}
+	type row struct {
+		Version   int64
+		AppliedAt time.Time
+	}
+	rows, err := db.Select[row](ctx, m.db, m.list)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: reading %s: %w", m.table, err)
+	}
+	out := make(map[int64]time.Time, len(rows))
+	for _, r := range rows {
+		out[r.Version] = r.AppliedAt
+	}
+	return out, nil
+}
@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/db"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+// fakeStore is the state behind the fake driver: the rows of the
+// migrations table and the migration statements that were committed.
+type fakeStore struct {
+	mu      sync.Mutex
+	applied map[int64]time.Time
+	execs   []string
+}
+
+var (
+	registerOnce sync.Once
+	store        *fakeStore
+)
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{}, nil }
+
+// fakeConn buffers the effects of a transaction until it commits.
+type fakeConn struct{ pending []func(*fakeStore) }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.pending = []func(*fakeStore){}
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, fn := range c.pending {
+		fn(store)
+	}
+	c.pending = nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	var fn func(*fakeStore)
+	switch {
+	case strings.Contains(query, "FAIL"):
+		return nil, errors.New("syntax error")
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+		fn = func(*fakeStore) {}
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		fn = func(s *fakeStore) { s.applied[args[0].Value.(int64)] = args[2].Value.(time.Time) }
+	case strings.HasPrefix(query, "DELETE FROM schema_migrations"):
+		fn = func(s *fakeStore) { delete(s.applied, args[0].Value.(int64)) }
+	default:
+		fn = func(s *fakeStore) { s.execs = append(s.execs, query) }
+	}
+	if c.pending != nil {
+		c.pending = append(c.pending, fn)
+		return driver.RowsAffected(1), nil
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	fn(store)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if query != "SELECT version, applied_at FROM schema_migrations" {
+		return nil, errors.New("unexpected query: " + query)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	rows := &fakeRows{}
+	for v, at := range store.applied {
+		rows.rows = append(rows.rows, []driver.Value{v, at})
+	}
+	return rows, nil
+}
+
+type fakeRows struct{ rows [][]driver.Value }
+
+func (r *fakeRows) Columns() []string { return []string{"version", "applied_at"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func openFake(t *testing.T) *db.DB {
+	t.Helper()
+	logtest.Capture(t)
+	registerOnce.Do(func() { sql.Register("migratefake", fakeDriver{}) })
+	store = &fakeStore{applied: map[int64]time.Time{}}
+	database, err := db.Open(context.Background(), db.Config{Driver: "migratefake", DSN: "memory", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+var files = fstest.MapFS{
+	"migrations/0001_create_users.up.sql":      {Data: []byte("CREATE TABLE users (id INT)")},
+	"migrations/0001_create_users.down.sql":    {Data: []byte("DROP TABLE users")},
+	"migrations/0003_add_email.up.sql":         {Data: []byte("ALTER TABLE users ADD email TEXT")},
+	"migrations/0003_add_email.down.sql":       {Data: []byte("ALTER TABLE users DROP email")},
+	"migrations/README.md":                     {Data: []byte("not a migration")},
+	"migrations/0004_backfill.sql.disabled":    {Data: []byte("ignored")},
+	"elsewhere/0009_not_loaded.up.sql":         {Data: []byte("FAIL")},
+	"migrations/nested/0010_not_loaded.up.sql": {Data: []byte("FAIL")},
+}
+
+func seed(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO users VALUES (1)")
+	return err
+}
+
+func newMigrator(t *testing.T, database *db.DB, extra ...Migration) *Migrator {
+	t.Helper()
+	m, err := New(database, WithFS(files, "migrations"),
+		WithMigrations(append([]Migration{{Version: 2, Name: "seed", Up: seed}}, extra...)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func statuses(t *testing.T, m *Migrator) []string {
+	t.Helper()
+	st, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, s := range st {
+		state := "pending"
+		if s.Applied {
+			state = "applied"
+		}
+		if s.Missing {
+			state = "missing"
+		}
+		out = append(out, s.Name+":"+state)
+	}
+	return out
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	m := newMigrator(t, openFake(t))
+
+	if got := statuses(t, m); !slices.Equal(got, []string{"create_users:pending", "seed:pending", "add_email:pending"}) {
+		t.Errorf("status = %v", got)
+	}
+	n, err := m.UpTo(ctx, 2)
+	if err != nil || n != 2 {
+		t.Fatalf("UpTo = %d, %v", n, err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 1 {
+		t.Fatalf("Up = %d, %v", n, err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 0 {
+		t.Fatalf("second Up = %d, %v", n, err)
+	}
+	want := []string{"CREATE TABLE users (id INT)", "INSERT INTO users VALUES (1)", "ALTER TABLE users ADD email TEXT"}
+	if !slices.Equal(store.execs, want) {
+		t.Errorf("execs = %v, want %v", store.execs, want)
+	}
+
+	if err := m.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := statuses(t, m); !slices.Equal(got, []string{"create_users:applied", "seed:applied", "add_email:pending"}) {
+		t.Errorf("status after down = %v", got)
+	}
+	if err := m.Down(ctx); err == nil || !strings.Contains(err.Error(), "irreversible") {
+		t.Errorf("Down past seed = %v", err)
+	}
+}
+
+func TestFailedMigrationIsNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	database := openFake(t)
+	rec := logtest.Capture(t)
+	m := newMigrator(t, database, Migration{Version: 5, Name: "broken", Up: func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "FAIL")
+		return err
+	}})
+	n, err := m.Up(ctx)
+	if n != 3 || err == nil || err.Error() != "migrate: up 5 (broken): syntax error" {
+		t.Fatalf("Up = %d, %v", n, err)
+	}
+	if _, ok := store.applied[5]; ok {
+		t.Error("failed migration recorded as applied")
+	}
+	if rec.WithEvent("migration_failed").Count() != 1 || rec.WithEvent("migration_applied").Count() != 3 {
+		t.Errorf("events = %v", rec.Records().Events())
+	}
+}
+
+func TestStatusReportsMissing(t *testing.T) {
+	m := newMigrator(t, openFake(t))
+	store.applied[7] = time.Now()
+	got := statuses(t, m)
+	if got[len(got)-1] != ":missing" {
+		t.Errorf("status = %v", got)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	database := openFake(t)
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"duplicate", []Option{WithFS(files, "migrations"), WithMigrations(Migration{Version: 3, Name: "again"})}, "duplicate version 3"},
+		{"no up", []Option{WithFS(fstest.MapFS{"m/1_x.down.sql": {}}, "m")}, "version 1 (x) has no up file"},
+		{"two names", []Option{WithFS(fstest.MapFS{"m/1_x.up.sql": {}, "m/1_y.down.sql": {}}, "m")}, `version 1 has two names`},
+		{"missing dir", []Option{WithFS(files, "nope")}, "nope"},
+		{"table", []Option{WithTable("x; DROP TABLE users")}, "invalid table name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(database, tt.opts...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
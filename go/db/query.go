@@ -0,0 +1,376 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Placeholder is the bind parameter syntax of a driver.
+type Placeholder int
+
+// Placeholder styles.
+const (
+	// Question is ?, used by MySQL and SQLite.
+	Question Placeholder = iota
+	// Dollar is $1, $2, ..., used by PostgreSQL.
+	Dollar
+)
+
+// placeholderFor returns the style of a driver name or DSN scheme.
+func placeholderFor(name string) Placeholder {
+	switch name {
+	case "pgx", "postgres", "postgresql", "pq", "cockroach", "cockroachdb":
+		return Dollar
+	}
+	return Question
+}
+
+// Placeholder returns the bind parameter style of the database.
+func (db *DB) Placeholder() Placeholder { return db.placeholder }
+
+// Named rewrites :name parameters in query to the database placeholder
+// style and returns the matching arguments; see the package function Named.
+func (db *DB) Named(query string, arg any) (string, []any, error) {
+	return Named(db.placeholder, query, arg)
+}
+
+// NamedQuery runs a query with :name parameters taken from arg.
+func (db *DB) NamedQuery(ctx context.Context, query string, arg any) (*sql.Rows, error) {
+	q, args, err := db.Named(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return db.Query(ctx, q, args...)
+}
+
+// NamedExec runs a statement with :name parameters taken from arg.
+func (db *DB) NamedExec(ctx context.Context, query string, arg any) (sql.Result, error) {
+	q, args, err := db.Named(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return db.Exec(ctx, q, args...)
+}
+
+// Named rewrites the :name parameters of query to positional placeholders
+// of style p and returns the values for them, taken from arg: a
+// map[string]any, or a struct or struct pointer whose fields are named as
+// in Select. A parameter used twice is bound twice. Quoted text and
+// PostgreSQL :: casts are left alone.
+//
+//	q, args, err := db.Named(db.Dollar,
+//		"UPDATE users SET email = :email WHERE id = :id", user)
+func Named(p Placeholder, query string, arg any) (string, []any, error) {
+	lookup, err := namedSource(arg)
+	if err != nil {
+		return "", nil, err
+	}
+	var b strings.Builder
+	var args []any
+	quote := rune(0)
+	rs := []rune(query)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ':' && i+1 < len(rs) && rs[i+1] == ':':
+			b.WriteString("::")
+			i++
+			continue
+		case r == ':' && i+1 < len(rs) && isNameStart(rs[i+1]):
+			j := i + 1
+			for j < len(rs) && isNamePart(rs[j]) {
+				j++
+			}
+			name := string(rs[i+1 : j])
+			v, ok := lookup(name)
+			if !ok {
+				return "", nil, fmt.Errorf("db: no value for parameter :%s", name)
+			}
+			args = append(args, v)
+			if p == Dollar {
+				b.WriteString("$" + strconv.Itoa(len(args)))
+			} else {
+				b.WriteByte('?')
+			}
+			i = j - 1
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), args, nil
+}
+
+func isNameStart(r rune) bool { return r == '_' || unicode.IsLetter(r) }
+func isNamePart(r rune) bool  { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+func namedSource(arg any) (func(string) (any, bool), error) {
+	if m, ok := arg.(map[string]any); ok {
+		return func(name string) (any, bool) {
+			v, ok := m[name]
+			return v, ok
+		}, nil
+	}
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("db: named parameters need a map[string]any or a struct, got %T", arg)
+	}
+	fields := columnsOf(v.Type())
+	return func(name string) (any, bool) {
+		index, ok := fields[name]
+		if !ok {
+			return nil, false
+		}
+		f, err := v.FieldByIndexErr(index)
+		if err != nil {
+			return nil, true // nil embedded pointer binds NULL
+		}
+		return f.Interface(), true
+	}, nil
+}
+
+// Querier runs queries. *sql.DB, *sql.Conn, *sql.Tx and *DB satisfy it,
+// so Select and Get work inside transactions.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// QueryContext is Query, so that DB satisfies Querier.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.Query(ctx, query, args...)
+}
+
+// Executor runs queries and statements. *sql.DB, *sql.Conn, *sql.Tx and
+// *DB satisfy it, for code that works the same inside a transaction.
+type Executor interface {
+	Querier
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// ExecContext is Exec, so that DB satisfies Executor.
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.Exec(ctx, query, args...)
+}
+
+// Select runs query and scans every row into a T. For a struct T each
+// column is stored in the field tagged `db:"column"`, or else the field
+// whose snake_case name is the column, including fields of embedded
+// structs; a column without a field is an error. Any other T receives the
+// single column of the result.
+//
+//	users, err := db.Select[User](ctx, database, "SELECT id, name, email FROM users WHERE active = $1", true)
+func Select[T any](ctx context.Context, q Querier, query string, args ...any) ([]T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []T
+	for rows.Next() {
+		var v T
+		if err := scanInto(rows, &v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db: query: %w", err)
+	}
+	return out, nil
+}
+
+// Get is Select for exactly one row. It returns sql.ErrNoRows when there
+// is none and ignores any rows after the first.
+func Get[T any](ctx context.Context, q Querier, query string, args ...any) (T, error) {
+	var v T
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return v, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return v, fmt.Errorf("db: query: %w", err)
+		}
+		return v, sql.ErrNoRows
+	}
+	err = scanInto(rows, &v)
+	return v, err
+}
+
+var scannerType = reflect.TypeFor[sql.Scanner]()
+
+func scanInto(rows *sql.Rows, dst any) error {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+	if t.Kind() != reflect.Struct || t == reflect.TypeFor[time.Time]() || reflect.PointerTo(t).Implements(scannerType) {
+		if err := rows.Scan(dst); err != nil {
+			return fmt.Errorf("db: scan: %w", err)
+		}
+		return nil
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("db: scan: %w", err)
+	}
+	fields := columnsOf(t)
+	targets := make([]any, len(cols))
+	for i, col := range cols {
+		index, ok := fields[strings.ToLower(col)]
+		if !ok {
+			return fmt.Errorf("db: scan: column %q has no field in %s", col, t)
+		}
+		f, err := fieldAlloc(v, index)
+		if err != nil {
+			return fmt.Errorf("db: scan: column %q: %w", col, err)
+		}
+		targets[i] = f.Addr().Interface()
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return fmt.Errorf("db: scan: %w", err)
+	}
+	return nil
+}
+
+// fieldAlloc returns the field of v at index, allocating nil embedded
+// struct pointers on the way.
+func fieldAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, fmt.Errorf("cannot allocate unexported embedded %s", v.Type())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// Column is a struct field stored in a database column, named by its
+// `db:"name"` tag or its snake_case field name. Tag options after the name
+// mark keys and generated columns:
+//
+//	ID        int64     `db:"id,pk"`
+//	CreatedAt time.Time `db:",readonly"` // filled by a column default
+type Column struct {
+	Name  string
+	Index []int // field index path, as for reflect.Value.FieldByIndex
+	// PrimaryKey is set by the pk option.
+	PrimaryKey bool
+	// ReadOnly is set by the readonly option, for columns the database
+	// fills, which are not written by inserts and updates.
+	ReadOnly bool
+}
+
+// Columns returns the columns of struct type t in field order, including
+// those of embedded structs. It panics if t is not a struct.
+func Columns(t reflect.Type) []Column {
+	return slices.Clone(mappingOf(t).columns)
+}
+
+type mapping struct {
+	columns []Column
+	byName  map[string][]int
+}
+
+var columnCache sync.Map // reflect.Type -> *mapping
+
+// columnsOf maps column names to field index paths of struct type t.
+func columnsOf(t reflect.Type) map[string][]int { return mappingOf(t).byName }
+
+func mappingOf(t reflect.Type) *mapping {
+	if m, ok := columnCache.Load(t); ok {
+		return m.(*mapping)
+	}
+	var cols []Column
+	collectColumns(t, nil, &cols)
+	m := &mapping{columns: cols, byName: make(map[string][]int, len(cols))}
+	for _, c := range cols {
+		m.byName[c.Name] = c.Index
+	}
+	columnCache.Store(t, m)
+	return m
+}
+
+func collectColumns(t reflect.Type, prefix []int, cols *[]Column) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, opts, _ := strings.Cut(f.Tag.Get("db"), ",")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		index := append(append([]int(nil), prefix...), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tag == "" && opts == "" && ft.Kind() == reflect.Struct {
+			collectColumns(ft, index, cols)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		c := Column{Name: tag, Index: index}
+		if c.Name == "" {
+			c.Name = snakeCase(f.Name)
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "pk":
+				c.PrimaryKey = true
+			case "readonly":
+				c.ReadOnly = true
+			}
+		}
+		// Shallower fields win, as in Go field promotion.
+		j := slices.IndexFunc(*cols, func(o Column) bool { return o.Name == c.Name })
+		switch {
+		case j < 0:
+			*cols = append(*cols, c)
+		case len((*cols)[j].Index) > len(index):
+			(*cols)[j] = c
+		}
+	}
+}
+
+// snakeCase converts a Go field name such as UserID to user_id, with the
+// same rules as config struct binding.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+type audit struct {
+	CreatedBy string
+}
+
+type user struct {
+	*audit
+	ID    int64
+	Name  string
+	Email string `db:"email_address"`
+}
+
+func TestNamed(t *testing.T) {
+	u := user{ID: 7, Email: "ann@example.com", audit: &audit{CreatedBy: "admin"}}
+	q, args, err := Named(Dollar, "UPDATE users SET email = :email_address, created_by = :created_by WHERE id = :id AND id::text <> ':id' OR id = :id", &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != "UPDATE users SET email = $1, created_by = $2 WHERE id = $3 AND id::text <> ':id' OR id = $4" {
+		t.Errorf("query = %q", q)
+	}
+	if !reflect.DeepEqual(args, []any{"ann@example.com", "admin", int64(7), int64(7)}) {
+		t.Errorf("args = %v", args)
+	}
+
+	q, args, _ = Named(Question, "SELECT * FROM users WHERE id = :id", map[string]any{"id": 1})
+	if q != "SELECT * FROM users WHERE id = ?" || len(args) != 1 {
+		t.Errorf("map: %q %v", q, args)
+	}
+	if _, _, err := Named(Question, "SELECT :missing", map[string]any{}); err == nil || !strings.Contains(err.Error(), ":missing") {
+		t.Errorf("missing parameter: %v", err)
+	}
+	if _, _, err := Named(Question, "SELECT :id", 1); err == nil {
+		t.Error("non-struct argument accepted")
+	}
+}
+
+func TestSelectAndGet(t *testing.T) {
+	logtest.Capture(t)
+	db, err := openFake(t, 0, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	u, err := Get[user](ctx, db, "SELECT id, name FROM users WHERE id = ?", int64(1))
+	if err != nil || u.ID != 1 || u.Name != "Alice" {
+		t.Errorf("Get = %+v, %v", u, err)
+	}
+	if _, err := Get[user](ctx, db, "SELECT id, name FROM users WHERE id = ?", int64(2)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Get missing = %v", err)
+	}
+	users, err := Select[user](ctx, db.SQL(), "SELECT id, name FROM users WHERE id = ?", int64(1))
+	if err != nil || len(users) != 1 || users[0].Name != "Alice" {
+		t.Errorf("Select = %+v, %v", users, err)
+	}
+	type idOnly struct{ ID int64 }
+	if _, err := Select[idOnly](ctx, db, "SELECT id, name FROM users WHERE id = ?", int64(1)); err == nil || !strings.Contains(err.Error(), `"name"`) {
+		t.Errorf("unmapped column: %v", err)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{"UserID": "user_id", "HTTPServer": "http_server", "Name": "name", "CreatedAt2": "created_at2"} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	type row struct {
+		user
+		ID        int64     `db:"id,pk"` // shadows user.ID
+		CreatedAt time.Time `db:",readonly"`
+		Skipped   string    `db:"-"`
+	}
+	var got []string
+	for _, c := range Columns(reflect.TypeFor[row]()) {
+		s := c.Name + fmt.Sprint(c.Index)
+		if c.PrimaryKey {
+			s += ",pk"
+		}
+		if c.ReadOnly {
+			s += ",readonly"
+		}
+		got = append(got, s)
+	}
+	want := []string{"created_by[0 0 0]", "id[1],pk", "name[0 2]", "email_address[0 3]", "created_at[2],readonly"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns = %v, want %v", got, want)
+	}
+}
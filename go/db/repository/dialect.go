@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package repository
+
+import (
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/db"
+)
+
+// Dialect is the SQL syntax a repository generates for one database.
+type Dialect interface {
+	// Name identifies the dialect in errors and logs.
+	Name() string
+	// Placeholder is the bind parameter style.
+	Placeholder() db.Placeholder
+	// Quote quotes an identifier that is already known to be valid.
+	Quote(ident string) string
+	// Returning reports whether INSERT ... RETURNING is supported. Without
+	// it a generated key is read with sql.Result.LastInsertId.
+	Returning() bool
+}
+
+// Built-in dialects.
+var (
+	Postgres Dialect = dialect{"postgres", db.Dollar, `"`, true}
+	MySQL    Dialect = dialect{"mysql", db.Question, "`", false}
+	SQLite   Dialect = dialect{"sqlite", db.Question, `"`, true}
+)
+
+type dialect struct {
+	name        string
+	placeholder db.Placeholder
+	quote       string
+	returning   bool
+}
+
+func (d dialect) Name() string                { return d.name }
+func (d dialect) Placeholder() db.Placeholder { return d.placeholder }
+func (d dialect) Returning() bool             { return d.returning }
+
+func (d dialect) Quote(ident string) string {
+	parts := strings.Split(ident, ".")
+	for i, p := range parts {
+		parts[i] = d.quote + p + d.quote
+	}
+	return strings.Join(parts, ".")
+}
+
+// Dialects maps database/sql driver names to dialects. Register the driver
+// of another database here, or pass WithDialect.
+var Dialects = map[string]Dialect{
+	"pgx": Postgres, "postgres": Postgres, "pq": Postgres,
+	"mysql":  MySQL,
+	"sqlite": SQLite, "sqlite3": SQLite,
+}
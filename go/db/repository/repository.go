@@ -0,0 +1,507 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package repository provides CRUD[T], the create, read, update and delete
+// operations of a table whose rows are structs of type T, so a domain
+// repository only holds its own queries:
+//
+//	type User struct {
+//		ID        int64 `db:"id,pk"`
+//		Name      string
+//		Email     string
+//		CreatedAt time.Time `db:",readonly"`
+//	}
+//
+//	type UserRepository struct{ *repository.CRUD[User] }
+//
+//	func (r UserRepository) ByEmail(ctx context.Context, email string) ([]User, error) {
+//		return r.List(ctx, repository.Where("email", email))
+//	}
+//
+// Columns are mapped as in db.Select. The primary key is the field tagged
+// pk, or else the id column; readonly columns are filled by the database
+// and only read. SQL is generated for a Dialect, taken from the driver of
+// a *db.DB or given with WithDialect.
+//
+// WithCache puts a cache in front of FindByID, for hot rows such as the
+// current user:
+//
+//	users := cache.New[string, User](cache.WithTTL(time.Minute), cache.WithMaxEntries(10_000))
+//	repo := UserRepository{crud.WithCache(users)}
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/cache"
+	"github.com/provide-io/provide-foundation/go/db"
+)
+
+// Option configures New.
+type Option func(*options)
+
+type options struct {
+	dialect Dialect
+}
+
+// WithDialect sets the dialect, which is otherwise looked up in Dialects
+// by the driver name of the executor.
+func WithDialect(d Dialect) Option {
+	return func(o *options) { o.dialect = d }
+}
+
+// CRUD runs the basic operations on one table. It is safe for concurrent
+// use.
+type CRUD[T any] struct {
+	ex      db.Executor
+	dialect Dialect
+	table   string
+	pk      db.Column
+	cols    []db.Column
+
+	selectList string // quoted column list
+	find       string
+	insert     string      // with the primary key
+	insertGen  string      // without it, for a generated key
+	generated  []db.Column // returned by insertGen
+	readonly   []db.Column // returned by insert and update
+	update     string
+	remove     string
+
+	cache     *cache.Cache[string, T] // by CacheKey of the primary key
+	readCache bool                    // false within a transaction
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// New returns the repository of table, optionally schema-qualified, on ex.
+// T must be a struct with a primary key column.
+func New[T any](ex db.Executor, table string, opts ...Option) (*CRUD[T], error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("repository: %s is not a struct", t)
+	}
+	if o.dialect == nil {
+		if d, ok := ex.(interface{ Driver() string }); ok {
+			o.dialect = Dialects[d.Driver()]
+		}
+		if o.dialect == nil {
+			return nil, fmt.Errorf("repository: no dialect for %T; use WithDialect", ex)
+		}
+	}
+	for _, part := range strings.Split(table, ".") {
+		if !identifier.MatchString(part) {
+			return nil, fmt.Errorf("repository: invalid table name %q", table)
+		}
+	}
+	r := &CRUD[T]{ex: ex, dialect: o.dialect, table: table, cols: db.Columns(t)}
+
+	pks := 0
+	for _, c := range r.cols {
+		if !identifier.MatchString(c.Name) {
+			return nil, fmt.Errorf("repository: %s: invalid column name %q", t, c.Name)
+		}
+		if c.PrimaryKey {
+			r.pk = c
+			pks++
+		}
+	}
+	if pks == 0 {
+		for _, c := range r.cols {
+			if c.Name == "id" {
+				r.pk, pks = c, 1
+			}
+		}
+	}
+	switch {
+	case pks == 0:
+		return nil, fmt.Errorf("repository: %s has no primary key; tag one with `db:\",pk\"`", t)
+	case pks > 1:
+		return nil, fmt.Errorf("repository: %s has %d primary keys; composite keys are not supported", t, pks)
+	}
+	r.prepare()
+	return r, nil
+}
+
+// prepare builds the statements, with :name parameters bound per call by
+// db.Named.
+func (r *CRUD[T]) prepare() {
+	q := r.dialect.Quote
+	table := q(r.table)
+	var all, writable, sets, returning []string
+	for _, c := range r.cols {
+		all = append(all, q(c.Name))
+		switch {
+		case c.ReadOnly:
+			returning = append(returning, q(c.Name))
+			r.readonly = append(r.readonly, c)
+		case c.Name != r.pk.Name:
+			writable = append(writable, c.Name)
+			sets = append(sets, q(c.Name)+" = :"+c.Name)
+		}
+	}
+	r.selectList = strings.Join(all, ", ")
+	where := " WHERE " + q(r.pk.Name) + " = :" + r.pk.Name
+	r.find = "SELECT " + r.selectList + " FROM " + table + where
+	r.remove = "DELETE FROM " + table + where
+
+	insert := func(names []string) string {
+		cols, params := make([]string, len(names)), make([]string, len(names))
+		for i, n := range names {
+			cols[i], params[i] = q(n), ":"+n
+		}
+		return "INSERT INTO " + table + " (" + strings.Join(cols, ", ") + ") VALUES (" + strings.Join(params, ", ") + ")"
+	}
+	r.insert = insert(append([]string{r.pk.Name}, writable...))
+	r.insertGen = insert(writable)
+	r.generated = append([]db.Column{r.pk}, r.readonly...)
+	if r.dialect.Returning() {
+		r.insertGen += " RETURNING " + q(r.pk.Name)
+		if len(returning) > 0 {
+			r.insert += " RETURNING " + strings.Join(returning, ", ")
+			r.insertGen += ", " + strings.Join(returning, ", ")
+		}
+	}
+	if len(sets) == 0 {
+		sets = []string{q(r.pk.Name) + " = :" + r.pk.Name}
+	}
+	r.update = "UPDATE " + table + " SET " + strings.Join(sets, ", ") + where
+	if r.dialect.Returning() && len(returning) > 0 {
+		r.update += " RETURNING " + strings.Join(returning, ", ")
+	}
+}
+
+// With returns a copy of the repository that runs on ex, typically the
+// *sql.Tx of db.DB.Tx. The copy does not read from the cache, which would
+// not see the transaction's writes, but still invalidates it.
+func (r *CRUD[T]) With(ex db.Executor) *CRUD[T] {
+	c := *r
+	c.ex = ex
+	c.readCache = false
+	return &c
+}
+
+// WithCache returns a copy of the repository whose FindByID reads through
+// c, keyed by CacheKey of the id. Update and Delete remove the rows they
+// write from c, so writes through other repositories or processes are
+// only seen once entries expire; give c a time to live accordingly. The
+// entries of a rolled back transaction's writes are removed all the same,
+// which only costs a reload.
+func (r *CRUD[T]) WithCache(c *cache.Cache[string, T]) *CRUD[T] {
+	cp := *r
+	cp.cache, cp.readCache = c, true
+	return &cp
+}
+
+// CacheKey returns the cache key of a primary key value, the same for
+// the id passed to FindByID or Delete and the field of the struct, such
+// as int and int64.
+func CacheKey(id any) string {
+	return fmt.Sprint(id)
+}
+
+// invalidate removes the row of id from the cache.
+func (r *CRUD[T]) invalidate(id any) {
+	if r.cache != nil {
+		r.cache.Delete(CacheKey(id))
+	}
+}
+
+// Executor returns the executor the repository runs on, for domain
+// queries.
+func (r *CRUD[T]) Executor() db.Executor { return r.ex }
+
+// Dialect returns the dialect of the generated SQL.
+func (r *CRUD[T]) Dialect() Dialect { return r.dialect }
+
+// FindByID returns the row whose primary key is id, or sql.ErrNoRows.
+func (r *CRUD[T]) FindByID(ctx context.Context, id any) (T, error) {
+	if r.cache != nil && r.readCache {
+		return r.cache.GetOrLoad(ctx, CacheKey(id), func(ctx context.Context, _ string) (T, error) {
+			return r.findByID(ctx, id)
+		})
+	}
+	return r.findByID(ctx, id)
+}
+
+func (r *CRUD[T]) findByID(ctx context.Context, id any) (T, error) {
+	query, args, err := db.Named(r.dialect.Placeholder(), r.find, map[string]any{r.pk.Name: id})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return db.Get[T](ctx, r.ex, query, args...)
+}
+
+// ListOption filters, orders or pages List.
+type ListOption func(*listQuery)
+
+type listQuery struct {
+	where  []condition
+	order  []string
+	limit  int
+	offset int
+}
+
+type condition struct {
+	column string
+	value  any
+}
+
+// Where keeps the rows whose column equals value, or is NULL for a nil
+// value. Several conditions must all hold.
+func Where(column string, value any) ListOption {
+	return func(q *listQuery) { q.where = append(q.where, condition{column, value}) }
+}
+
+// OrderBy sorts by column, ascending, then by any later order.
+func OrderBy(column string) ListOption {
+	return func(q *listQuery) { q.order = append(q.order, column) }
+}
+
+// OrderByDesc sorts by column, descending.
+func OrderByDesc(column string) ListOption {
+	return func(q *listQuery) { q.order = append(q.order, "-"+column) }
+}
+
+// Limit returns at most n rows.
+func Limit(n int) ListOption {
+	return func(q *listQuery) { q.limit = n }
+}
+
+// Offset skips the first n rows. It requires Limit.
+func Offset(n int) ListOption {
+	return func(q *listQuery) { q.offset = n }
+}
+
+// List returns the rows selected by opts, or every row without any.
+// Columns are checked against T, so they never come from user input
+// unchecked.
+func (r *CRUD[T]) List(ctx context.Context, opts ...ListOption) ([]T, error) {
+	var lq listQuery
+	for _, opt := range opts {
+		opt(&lq)
+	}
+	known := func(name string) error {
+		for _, c := range r.cols {
+			if c.Name == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("repository: %s has no column %q", r.table, name)
+	}
+	q := r.dialect.Quote
+	var b strings.Builder
+	b.WriteString("SELECT " + r.selectList + " FROM " + q(r.table))
+	params := map[string]any{}
+	for i, c := range lq.where {
+		if err := known(c.column); err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			b.WriteString(" WHERE ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		if c.value == nil {
+			b.WriteString(q(c.column) + " IS NULL")
+			continue
+		}
+		name := "w" + strconv.Itoa(i)
+		params[name] = c.value
+		b.WriteString(q(c.column) + " = :" + name)
+	}
+	for i, col := range lq.order {
+		dir := " ASC"
+		if rest, ok := strings.CutPrefix(col, "-"); ok {
+			col, dir = rest, " DESC"
+		}
+		if err := known(col); err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			b.WriteString(" ORDER BY ")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(q(col) + dir)
+	}
+	switch {
+	case lq.limit < 0 || lq.offset < 0:
+		return nil, fmt.Errorf("repository: negative limit or offset")
+	case lq.offset > 0 && lq.limit == 0:
+		return nil, fmt.Errorf("repository: Offset requires Limit")
+	case lq.limit > 0:
+		b.WriteString(" LIMIT " + strconv.Itoa(lq.limit))
+		if lq.offset > 0 {
+			b.WriteString(" OFFSET " + strconv.Itoa(lq.offset))
+		}
+	}
+	query, args, err := db.Named(r.dialect.Placeholder(), b.String(), params)
+	if err != nil {
+		return nil, err
+	}
+	return db.Select[T](ctx, r.ex, query, args...)
+}
+
+// Create inserts v. If its primary key is the zero value the key is left
+// to the database and stored back into v, as are readonly columns when
+// the dialect supports RETURNING.
+func (r *CRUD[T]) Create(ctx context.Context, v *T) error {
+	rv := reflect.ValueOf(v).Elem()
+	pk, err := field(rv, r.pk.Index)
+	if err != nil {
+		return err
+	}
+	if !pk.IsZero() {
+		return r.write(ctx, rv, r.insert, r.readonly, "insert")
+	}
+	if r.dialect.Returning() {
+		return r.write(ctx, rv, r.insertGen, r.generated, "insert")
+	}
+	res, err := r.exec(ctx, rv, r.insertGen, "insert")
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("repository: insert into %s: reading generated key: %w", r.table, err)
+	}
+	switch pk.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		pk.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		pk.SetUint(uint64(id))
+	default:
+		return fmt.Errorf("repository: insert into %s: generated key %d does not fit %s", r.table, id, pk.Type())
+	}
+	return nil
+}
+
+// Update writes every column of v except the primary key and readonly
+// columns to the row with v's primary key. It returns an error wrapping
+// sql.ErrNoRows if there is no such row. Without RETURNING this relies on
+// the affected row count, which MySQL reports for changed rows only unless
+// the DSN sets clientFoundRows=true.
+func (r *CRUD[T]) Update(ctx context.Context, v *T) error {
+	rv := reflect.ValueOf(v).Elem()
+	if r.cache != nil {
+		pk, err := field(rv, r.pk.Index)
+		if err != nil {
+			return err
+		}
+		defer r.invalidate(pk.Interface())
+	}
+	if r.dialect.Returning() && len(r.readonly) > 0 {
+		return r.write(ctx, rv, r.update, r.readonly, "update")
+	}
+	res, err := r.exec(ctx, rv, r.update, "update")
+	if err != nil {
+		return err
+	}
+	return r.affected(res, "update")
+}
+
+// Delete deletes the row whose primary key is id. It returns an error
+// wrapping sql.ErrNoRows if there is none.
+func (r *CRUD[T]) Delete(ctx context.Context, id any) error {
+	defer r.invalidate(id)
+	query, args, err := db.Named(r.dialect.Placeholder(), r.remove, map[string]any{r.pk.Name: id})
+	if err != nil {
+		return err
+	}
+	res, err := r.ex.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("repository: delete from %s: %w", r.table, err)
+	}
+	return r.affected(res, "delete from")
+}
+
+func (r *CRUD[T]) exec(ctx context.Context, rv reflect.Value, stmt, op string) (sql.Result, error) {
+	query, args, err := db.Named(r.dialect.Placeholder(), stmt, rv.Interface())
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.ex.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository: %s %s: %w", op, r.table, err)
+	}
+	return res, nil
+}
+
+func (r *CRUD[T]) affected(res sql.Result, op string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository: %s %s: %w", op, r.table, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("repository: %s %s: %w", op, r.table, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// write runs stmt and, if the dialect added a RETURNING clause for the
+// returned columns, scans them into rv.
+func (r *CRUD[T]) write(ctx context.Context, rv reflect.Value, stmt string, returned []db.Column, op string) error {
+	if !r.dialect.Returning() || len(returned) == 0 {
+		if _, err := r.exec(ctx, rv, stmt, op); err != nil {
+			return err
+		}
+		return nil
+	}
+	query, args, err := db.Named(r.dialect.Placeholder(), stmt, rv.Interface())
+	if err != nil {
+		return err
+	}
+	rows, err := r.ex.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("repository: %s %s: %w", op, r.table, err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("repository: %s %s: %w", op, r.table, err)
+		}
+		return fmt.Errorf("repository: %s %s: %w", op, r.table, sql.ErrNoRows)
+	}
+	dest := make([]any, len(returned))
+	for i, c := range returned {
+		f, err := field(rv, c.Index)
+		if err != nil {
+			return err
+		}
+		dest[i] = f.Addr().Interface()
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return fmt.Errorf("repository: %s %s: %w", op, r.table, err)
+	}
+	return rows.Close()
+}
+
+// field returns the field of v at index, allocating nil embedded struct
+// pointers on the way.
+func field(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, fmt.Errorf("repository: cannot allocate unexported embedded %s", v.Type())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
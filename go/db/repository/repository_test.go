@@ -0,0 +1,299 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/cache"
+)
+
+// result is what the fake returns for one statement.
+type result struct {
+	cols     []string
+	rows     [][]driver.Value
+	affected int64
+	lastID   int64
+}
+
+// recorder is a database/sql connector that records statements and
+// answers them with the next queued result.
+type recorder struct {
+	calls   []string
+	results []result
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return r, nil }
+func (r *recorder) Driver() driver.Driver                        { return nil }
+
+func (r *recorder) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (r *recorder) Close() error                        { return nil }
+func (r *recorder) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (r *recorder) next(query string, args []driver.NamedValue) result {
+	call := query
+	for _, a := range args {
+		call += fmt.Sprintf(" [%v]", a.Value)
+	}
+	r.calls = append(r.calls, call)
+	if len(r.results) == 0 {
+		return result{}
+	}
+	res := r.results[0]
+	r.results = r.results[1:]
+	return res
+}
+
+func (r *recorder) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res := r.next(query, args)
+	return execResult(res), nil
+}
+
+func (r *recorder) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res := r.next(query, args)
+	return &rows{cols: res.cols, rows: res.rows}, nil
+}
+
+type execResult result
+
+func (e execResult) LastInsertId() (int64, error) { return e.lastID, nil }
+func (e execResult) RowsAffected() (int64, error) { return e.affected, nil }
+
+type rows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *rows) Columns() []string { return r.cols }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type user struct {
+	ID        int64 `db:"id,pk"`
+	Name      string
+	Email     string    `db:"email_address"`
+	CreatedAt time.Time `db:",readonly"`
+}
+
+func newRepo(t *testing.T, d Dialect) (*CRUD[user], *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	pool := sql.OpenDB(rec)
+	pool.SetMaxOpenConns(1)
+	t.Cleanup(func() { pool.Close() })
+	r, err := New[user](pool, "app.users", WithDialect(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, rec
+}
+
+func (r *recorder) want(t *testing.T, calls ...string) {
+	t.Helper()
+	if !reflect.DeepEqual(r.calls, calls) {
+		t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(r.calls, "\n"), strings.Join(calls, "\n"))
+	}
+	r.calls = nil
+}
+
+var created = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestPostgres(t *testing.T) {
+	ctx := context.Background()
+	r, rec := newRepo(t, Postgres)
+
+	rec.results = []result{{cols: []string{"id", "created_at"}, rows: [][]driver.Value{{int64(42), created}}}}
+	u := user{Name: "Ann", Email: "ann@example.com"}
+	if err := r.Create(ctx, &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 42 || !u.CreatedAt.Equal(created) {
+		t.Errorf("generated columns not read back: %+v", u)
+	}
+	rec.want(t, `INSERT INTO "app"."users" ("name", "email_address") VALUES ($1, $2) RETURNING "id", "created_at" [Ann] [ann@example.com]`)
+
+	rec.results = []result{{cols: []string{"id", "name", "email_address", "created_at"}, rows: [][]driver.Value{{int64(42), "Ann", "ann@example.com", created}}}}
+	got, err := r.FindByID(ctx, 42)
+	if err != nil || got != u {
+		t.Errorf("FindByID = %+v, %v", got, err)
+	}
+	if _, err := r.FindByID(ctx, 7); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindByID missing = %v", err)
+	}
+	rec.want(t,
+		`SELECT "id", "name", "email_address", "created_at" FROM "app"."users" WHERE "id" = $1 [42]`,
+		`SELECT "id", "name", "email_address", "created_at" FROM "app"."users" WHERE "id" = $1 [7]`)
+
+	u.Email = "ann@example.org"
+	if err := r.Update(ctx, &u); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Update of missing row = %v", err)
+	}
+	rec.want(t, `UPDATE "app"."users" SET "name" = $1, "email_address" = $2 WHERE "id" = $3 RETURNING "created_at" [Ann] [ann@example.org] [42]`)
+
+	rec.results = []result{{affected: 1}}
+	if err := r.Delete(ctx, 42); err != nil {
+		t.Error(err)
+	}
+	if err := r.Delete(ctx, 42); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second Delete = %v", err)
+	}
+	rec.want(t, `DELETE FROM "app"."users" WHERE "id" = $1 [42]`, `DELETE FROM "app"."users" WHERE "id" = $1 [42]`)
+}
+
+func TestMySQL(t *testing.T) {
+	ctx := context.Background()
+	r, rec := newRepo(t, MySQL)
+
+	rec.results = []result{{lastID: 9, affected: 1}}
+	u := user{Name: "Bob"}
+	if err := r.Create(ctx, &u); err != nil || u.ID != 9 {
+		t.Errorf("Create = %+v, %v", u, err)
+	}
+	u2 := user{ID: 10, Name: "Cy"}
+	if err := r.Create(ctx, &u2); err != nil {
+		t.Error(err)
+	}
+	rec.results = []result{{affected: 1}}
+	if err := r.Update(ctx, &u); err != nil {
+		t.Error(err)
+	}
+	rec.want(t,
+		"INSERT INTO `app`.`users` (`name`, `email_address`) VALUES (?, ?) [Bob] []",
+		"INSERT INTO `app`.`users` (`id`, `name`, `email_address`) VALUES (?, ?, ?) [10] [Cy] []",
+		"UPDATE `app`.`users` SET `name` = ?, `email_address` = ? WHERE `id` = ? [Bob] [] [9]")
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	r, rec := newRepo(t, Postgres)
+
+	if _, err := r.List(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.List(ctx, Where("name", "Ann"), Where("email_address", nil), OrderByDesc("created_at"), OrderBy("id"), Limit(10), Offset(20)); err != nil {
+		t.Fatal(err)
+	}
+	rec.want(t,
+		`SELECT "id", "name", "email_address", "created_at" FROM "app"."users"`,
+		`SELECT "id", "name", "email_address", "created_at" FROM "app"."users" WHERE "name" = $1 AND "email_address" IS NULL ORDER BY "created_at" DESC, "id" ASC LIMIT 10 OFFSET 20 [Ann]`)
+
+	for _, opts := range [][]ListOption{
+		{Where("name; DROP TABLE users", 1)},
+		{OrderBy("password")},
+		{Offset(5)},
+		{Limit(-1)},
+	} {
+		if _, err := r.List(ctx, opts...); err == nil {
+			t.Errorf("List accepted invalid options")
+		}
+	}
+	if len(rec.calls) != 0 {
+		t.Errorf("invalid List ran queries: %v", rec.calls)
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	r, rec := newRepo(t, MySQL)
+	users := cache.New[string, user]()
+	r = r.WithCache(users)
+	row := func(name string) result {
+		return result{cols: []string{"id", "name", "email_address", "created_at"}, rows: [][]driver.Value{{int64(42), name, "ann@example.com", created}}}
+	}
+	find := "SELECT `id`, `name`, `email_address`, `created_at` FROM `app`.`users` WHERE `id` = ? [42]"
+
+	rec.results = []result{row("Ann")}
+	for range 2 {
+		if u, err := r.FindByID(ctx, 42); err != nil || u.Name != "Ann" {
+			t.Fatalf("FindByID = %+v, %v", u, err)
+		}
+	}
+	rec.want(t, find)
+
+	// Updates invalidate the row, with the key as typed in the struct.
+	rec.results = []result{{affected: 1}, row("Bea")}
+	if err := r.Update(ctx, &user{ID: 42, Name: "Bea"}); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ := r.FindByID(ctx, 42); u.Name != "Bea" {
+		t.Errorf("FindByID after Update = %+v", u)
+	}
+	rec.calls = nil
+
+	// Transactions read the database but invalidate the cache.
+	tx := r.With(r.Executor())
+	rec.results = []result{row("Bea"), {affected: 1}, row("Cy")}
+	tx.FindByID(ctx, 42)
+	tx.Delete(ctx, int64(42))
+	if _, ok := users.Get(CacheKey(42)); ok {
+		t.Error("Delete left the row cached")
+	}
+	if u, _ := r.FindByID(ctx, 42); u.Name != "Cy" {
+		t.Errorf("FindByID after Delete = %+v", u)
+	}
+	if len(rec.calls) != 3 {
+		t.Errorf("calls = %v", rec.calls)
+	}
+
+	// Missing rows are not cached.
+	rec.results = nil
+	for range 2 {
+		if _, err := r.FindByID(ctx, 7); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("FindByID missing = %v", err)
+		}
+	}
+	if s := users.Stats(); s.Hits != 1 || s.Loads != 5 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	pool := sql.OpenDB(&recorder{})
+	defer pool.Close()
+	type noKey struct{ Name string }
+	type twoKeys struct {
+		A int `db:"a,pk"`
+		B int `db:"b,pk"`
+	}
+	type badColumn struct {
+		ID int    `db:"id"`
+		X  string `db:"x y"`
+	}
+	type implicitKey struct {
+		ID   int
+		Name string
+	}
+	for name, fn := range map[string]func() error{
+		"no dialect": func() error { _, err := New[user](pool, "users"); return err },
+		"not struct": func() error { _, err := New[int](pool, "users", WithDialect(SQLite)); return err },
+		"table":      func() error { _, err := New[user](pool, "users;--", WithDialect(SQLite)); return err },
+		"no key":     func() error { _, err := New[noKey](pool, "users", WithDialect(SQLite)); return err },
+		"two keys":   func() error { _, err := New[twoKeys](pool, "users", WithDialect(SQLite)); return err },
+		"bad column": func() error { _, err := New[badColumn](pool, "users", WithDialect(SQLite)); return err },
+	} {
+		if err := fn(); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+	if _, err := New[implicitKey](pool, "users", WithDialect(SQLite)); err != nil {
+		t.Errorf("id column not used as key: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sqlcheck finds SQL built by string interpolation and passed to
+// query methods, the injection-prone pattern
+//
+//	db.Query(ctx, fmt.Sprintf("SELECT * FROM users WHERE id = %d", id))
+//
+// that bind parameters replace. It works on syntax alone, like a vet
+// check, and is run over a tree by cmd/sqlcheck. A call is reported when
+// a method named like a query function (Query, Exec, Select, ...) gets an
+// argument that is fmt.Sprintf or a concatenation whose literal text
+// looks like SQL.
+package sqlcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// QueryFuncs are the function and method names whose arguments are
+// checked.
+var QueryFuncs = map[string]bool{
+	"Query": true, "QueryContext": true, "QueryRow": true, "QueryRowContext": true,
+	"Exec": true, "ExecContext": true, "Prepare": true, "PrepareContext": true,
+	"NamedQuery": true, "NamedExec": true, "Named": true, "Select": true, "Get": true,
+}
+
+var sqlLike = regexp.MustCompile(`(?i)\b(select\s.+\sfrom|insert\s+into|update\s.+\sset|delete\s+from|where\s)`)
+
+// Finding is an interpolated query.
+type Finding struct {
+	Pos     token.Position
+	Func    string
+	Message string
+}
+
+func (f Finding) String() string { return fmt.Sprintf("%s: %s", f.Pos, f.Message) }
+
+// CheckFile reports the interpolated queries in f.
+func CheckFile(fset *token.FileSet, f *ast.File) []Finding {
+	var out []Finding
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		name := funcName(call.Fun)
+		if !QueryFuncs[name] {
+			return true
+		}
+		for _, arg := range call.Args {
+			if how := interpolation(arg); how != "" {
+				out = append(out, Finding{
+					Pos:     fset.Position(arg.Pos()),
+					Func:    name,
+					Message: fmt.Sprintf("SQL built with %s passed to %s; use bind parameters", how, name),
+				})
+			}
+		}
+		return true
+	})
+	return out
+}
+
+// funcName returns the name of the called function or method, seeing
+// through generic instantiation such as db.Select[User].
+func funcName(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		return funcName(f.X)
+	case *ast.IndexListExpr:
+		return funcName(f.X)
+	case *ast.SelectorExpr:
+		return f.Sel.Name
+	case *ast.Ident:
+		return f.Name
+	}
+	return ""
+}
+
+// interpolation describes how e interpolates values into SQL, or returns
+// "" if it does not.
+func interpolation(e ast.Expr) string {
+	switch e := ast.Unparen(e).(type) {
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || len(e.Args) == 0 {
+			return ""
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "fmt" && strings.HasPrefix(sel.Sel.Name, "Sprint") {
+			if looksLikeSQL(literalText(e.Args[0])) {
+				return "fmt." + sel.Sel.Name
+			}
+		}
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return ""
+		}
+		var text strings.Builder
+		dynamic := false
+		for _, op := range flattenAdd(e) {
+			if s, ok := stringLit(op); ok {
+				text.WriteString(s)
+			} else {
+				dynamic = true
+			}
+		}
+		if dynamic && looksLikeSQL(text.String()) {
+			return "string concatenation"
+		}
+	}
+	return ""
+}
+
+func flattenAdd(e ast.Expr) []ast.Expr {
+	if b, ok := ast.Unparen(e).(*ast.BinaryExpr); ok && b.Op == token.ADD {
+		return append(flattenAdd(b.X), flattenAdd(b.Y)...)
+	}
+	return []ast.Expr{e}
+}
+
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := ast.Unparen(e).(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func literalText(e ast.Expr) string {
+	s, _ := stringLit(e)
+	return s
+}
+
+func looksLikeSQL(s string) bool { return sqlLike.MatchString(s) }
+
+// CheckDir checks every Go file under root, skipping testdata, vendor and
+// hidden directories.
+func CheckDir(root string) ([]Finding, error) {
+	fset := token.NewFileSet()
+	var out []Finding
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		out = append(out, CheckFile(fset, f)...)
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlcheck
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+const src = `package repo
+
+func f() {
+	db.Query(ctx, fmt.Sprintf("SELECT * FROM users WHERE id = %d", id))      // 1
+	db.Exec(ctx, "DELETE FROM users WHERE id = " + strconv.Itoa(id))         // 2
+	db.Select[User](ctx, q, "SELECT name FROM users WHERE org = '" + org + "'") // 3
+	db.Query(ctx, "SELECT * FROM users WHERE id = $1", id)
+	db.Query(ctx, "SELECT * FROM users " + "WHERE id = $1", id)
+	db.Query(ctx, query, id)
+	client.Get(ctx, fmt.Sprintf("/users/%d", id))
+	log.Info(fmt.Sprintf("SELECT * FROM users WHERE id = %d", id))
+}
+`
+
+func TestCheckFile(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "repo.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := CheckFile(fset, f)
+	want := []struct {
+		line int
+		fn   string
+	}{{4, "Query"}, {5, "Exec"}, {6, "Select"}}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %v", got)
+	}
+	for i, w := range want {
+		if got[i].Pos.Line != w.line || got[i].Func != w.fn {
+			t.Errorf("finding %d = %v, want line %d in %s", i, got[i], w.line, w.fn)
+		}
+	}
+}
+
+// TestTreeIsClean keeps the foundation and its examples free of
+// interpolated SQL.
+func TestTreeIsClean(t *testing.T) {
+	for _, dir := range []string{"../..", "../../../examples"} {
+		findings, err := CheckDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range findings {
+			t.Error(f)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+// startSpan starts a client span for a statement, as a child of the span
+// in ctx. Spans follow the OpenTelemetry database conventions and are
+// named after the statement's operation, such as SELECT.
+func (db *DB) startSpan(ctx context.Context, query string) (context.Context, *trace.Span) {
+	return trace.Start(ctx, operation(query),
+		trace.WithKind(trace.SpanKindClient),
+		trace.WithAttr("db.system", db.driver),
+		trace.WithAttr("db.statement", query))
+}
+
+// operation returns the first keyword of query in upper case, or "query"
+// when it does not start with one.
+func operation(query string) string {
+	query = strings.TrimLeftFunc(query, unicode.IsSpace)
+	end := strings.IndexFunc(query, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end < 0 {
+		end = len(query)
+	}
+	if end == 0 {
+		return "query"
+	}
+	return strings.ToUpper(query[:end])
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+}
+
+func TestStatementSpans(t *testing.T) {
+	logtest.Capture(t)
+	db, err := openFake(t, 0, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rec := &spanRecorder{}
+	trace.SetExporter(rec)
+	defer trace.SetExporter(nil)
+
+	var parent trace.SpanContext
+	trace.WithSpan(context.Background(), "user.get", func(ctx context.Context) error {
+		parent = trace.SpanContextFromContext(ctx)
+		var name string
+		db.QueryRow(ctx, "SELECT name FROM users WHERE id = ?", int64(1)).Scan(new(int64), &name)
+		db.Exec(ctx, "FAIL")
+		return nil
+	})
+
+	if len(rec.spans) != 3 {
+		t.Fatalf("got %d spans", len(rec.spans))
+	}
+	for i, want := range []struct {
+		name   string
+		status trace.StatusCode
+	}{{"SELECT", trace.StatusUnset}, {"FAIL", trace.StatusError}} {
+		s := rec.spans[i]
+		if s.Name != want.name || s.Status != want.status || s.Parent != parent.SpanID || s.Kind != trace.SpanKindClient {
+			t.Errorf("span %d = %+v", i, s)
+		}
+		if s.Attrs[0] != (trace.Attr{Key: "db.system", Value: "fakedb"}) {
+			t.Errorf("span %d attrs = %v", i, s.Attrs)
+		}
+	}
+}
+
+func TestOperation(t *testing.T) {
+	for query, want := range map[string]string{
+		"SELECT 1":                  "SELECT",
+		"\n  insert into users(id)": "INSERT",
+		"with x as (select 1)":      "WITH",
+		"(SELECT 1)":                "query",
+		"":                          "query",
+	} {
+		if got := operation(query); got != want {
+			t.Errorf("operation(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
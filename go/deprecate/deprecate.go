@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package deprecate reports uses of deprecated APIs at run time, so code
+// relying on them is found before they are removed. A deprecated function
+// calls Warn, which logs each place that calls it once:
+//
+//	// Deprecated: use Do.
+//	func (c *Client) Get(ctx context.Context, path string) (*Response, error) {
+//		deprecate.Warn(ctx, "Client.Get", "use Do(ctx, http.MethodGet, path, nil)")
+//		return c.Do(ctx, http.MethodGet, path, nil)
+//	}
+//
+// Tests turn the warnings into failures with testkit.FailOnDeprecation,
+// so a migration is complete once the suite passes with it.
+package deprecate
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Warning is one use of a deprecated API.
+type Warning struct {
+	// API names the deprecated API, such as "Client.Get".
+	API string
+	// Advice tells what to use instead.
+	Advice string
+	// File, Line and Function locate the code using the API.
+	File     string
+	Line     int
+	Function string
+}
+
+func (w *Warning) Error() string {
+	return fmt.Sprintf("deprecate: %s is deprecated, %s (called from %s:%d)", w.API, w.Advice, w.File, w.Line)
+}
+
+var (
+	mu      sync.RWMutex
+	handler func(ctx context.Context, w *Warning)
+	seen    sync.Map // of site
+)
+
+// site is a call site of a deprecated API.
+type site struct {
+	api string
+	pc  uintptr
+}
+
+// Warn reports that the caller of the function calling it uses the
+// deprecated api, logging it at WARNING as deprecated_api_used the first
+// time that call site does.
+func Warn(ctx context.Context, api, advice string) {
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // runtime.Callers, Warn, the deprecated API
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	w := &Warning{API: api, Advice: advice, File: frame.File, Line: frame.Line, Function: frame.Function}
+
+	mu.RLock()
+	h := handler
+	mu.RUnlock()
+	if h != nil {
+		h(ctx, w)
+		return
+	}
+	if _, loaded := seen.LoadOrStore(site{api: api, pc: pcs[0]}, true); loaded {
+		return
+	}
+	log.Default().WarnCtx(ctx, "deprecated_api_used",
+		"api", w.API,
+		"advice", w.Advice,
+		"code.filepath", w.File,
+		"code.lineno", w.Line,
+		"code.function", w.Function,
+	)
+}
+
+// reset forgets the call sites already logged.
+func reset() { seen.Clear() }
+
+// SetHandler makes fn receive every warning, from every call, in place of
+// the log, and returns a function restoring the previous handler. A nil
+// fn restores logging.
+func SetHandler(fn func(ctx context.Context, w *Warning)) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	prev := handler
+	handler = fn
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		handler = prev
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package deprecate
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+// legacy stands for a deprecated API.
+func legacy(ctx context.Context) {
+	Warn(ctx, "legacy", "use modern")
+}
+
+func TestWarnLogsOncePerCallSite(t *testing.T) {
+	rec := logtest.Capture(t)
+	reset()
+	t.Cleanup(reset)
+	for range 3 {
+		legacy(context.Background()) // one call site
+	}
+	legacy(context.Background()) // another
+	records := rec.Records().WithEvent("deprecated_api_used")
+	if records.Count() != 2 {
+		t.Fatalf("logged %d times, want once per call site", records.Count())
+	}
+	r := records.First()
+	if file, _ := r.Get("code.filepath"); filepath.Base(file.(string)) != "deprecate_test.go" {
+		t.Errorf("code.filepath = %v, want the caller of the deprecated API", file)
+	}
+	if fn, _ := r.Get("code.function"); !strings.HasSuffix(fn.(string), "TestWarnLogsOncePerCallSite") {
+		t.Errorf("code.function = %v", fn)
+	}
+	if advice, _ := r.Get("advice"); advice != "use modern" {
+		t.Errorf("advice = %v", advice)
+	}
+}
+
+func TestSetHandler(t *testing.T) {
+	rec := logtest.Capture(t)
+	var got []*Warning
+	restore := SetHandler(func(_ context.Context, w *Warning) { got = append(got, w) })
+	for range 2 {
+		legacy(context.Background())
+	}
+	restore()
+	if len(got) != 2 {
+		t.Fatalf("handler called %d times, want every use", len(got))
+	}
+	if msg := got[0].Error(); !strings.HasPrefix(msg, "deprecate: legacy is deprecated, use modern (called from ") {
+		t.Errorf("Error() = %q", msg)
+	}
+	if rec.Records().WithEvent("deprecated_api_used").Count() != 0 {
+		t.Error("warning logged while a handler was set")
+	}
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package diag
+
+import (
+	"context"
+
+	"github.com/provide-io/provide-foundation/go/cli"
+	"github.com/provide-io/provide-foundation/go/i18n"
+	"github.com/provide-io/provide-foundation/go/serde"
+)
+
+// Command returns the doctor command, also named diag, which prints the
+// report Collect builds with opts. It exits with cli.ExitFailure when a
+// health check fails, so deployment scripts can gate on it.
+func Command(opts ...Option) *cli.Command {
+	return &cli.Command{
+		Name:        "doctor",
+		Aliases:     []string{"diag"},
+		Description: "Report the build, environment, configuration and health",
+		Help: `Prints the build and the detected platform, profile and telemetry
+settings, the resolved configuration with secrets masked, the registered
+components and the results of the health checks. With --output json or
+yaml the report is written as one document.`,
+		Output: true,
+		Run: func(ctx context.Context, args []string) error {
+			if len(args) > 0 {
+				return cli.Usagef("unexpected arguments %q", args)
+			}
+			r, err := Collect(ctx, opts...)
+			if err != nil {
+				return err
+			}
+			if err := write(ctx, r); err != nil {
+				return err
+			}
+			if !r.Healthy() {
+				return cli.Exit(cli.ExitFailure, i18n.NewError("diag.health_failed"))
+			}
+			return nil
+		},
+	}
+}
+
+func write(ctx context.Context, r Report) error {
+	var format serde.Format
+	switch cli.Output(ctx) {
+	case cli.OutputJSON:
+		format = serde.JSON
+	case cli.OutputYAML:
+		format = serde.YAML
+	default:
+		return r.WriteText(cli.Stdout(ctx))
+	}
+	data, err := serde.Marshal(format, r)
+	if err != nil {
+		return err
+	}
+	if format == serde.JSON {
+		data = append(data, '\n')
+	}
+	_, err = cli.Stdout(ctx).Write(data)
+	return err
+}
@@ -0,0 +1,329 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package diag reports how a process is set up, in one place, for
+// debugging misconfigured deployments: the build, the detected platform,
+// profile and telemetry, the resolved configuration with secrets masked,
+// the components registered in the hub and the results of the health
+// checks. Applications register it as a command,
+//
+//	cli.MustRegister(hub.Default(), diag.Command(
+//		diag.WithConfig(cfg),
+//		diag.WithSettings("telemetry", telemetry.Current())))
+//
+// so "app doctor", or "app diag", prints the report, as JSON or YAML with
+// --output, and fails when a health check does. Collect returns the same
+// report for other uses, such as a debug endpoint.
+//
+// Values are masked when they were resolved from a secret reference, when
+// their key names a credential, as log redaction decides, and in the
+// password of URLs such as database DSNs.
+package diag
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/config"
+	"github.com/provide-io/provide-foundation/go/env"
+	"github.com/provide-io/provide-foundation/go/health"
+	"github.com/provide-io/provide-foundation/go/hub"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/platform"
+	"github.com/provide-io/provide-foundation/go/telemetry"
+	"github.com/provide-io/provide-foundation/go/version"
+)
+
+// Report is a snapshot of the process setup.
+type Report struct {
+	Build       version.Info   `json:"build"`
+	Environment Environment    `json:"environment"`
+	Config      []Setting      `json:"config"`
+	Components  []Component    `json:"components"`
+	Health      *health.Report `json:"health,omitempty"`
+}
+
+// Healthy reports whether every health check passed, or none ran.
+func (r Report) Healthy() bool { return r.Health == nil || r.Health.Status == health.StatusUp }
+
+// Environment is what was detected about where the process runs.
+type Environment struct {
+	Profile   env.Profile `json:"profile"`
+	Platform  string      `json:"platform"`
+	Hostname  string      `json:"hostname,omitempty"`
+	CPUs      int         `json:"cpus"`
+	Container bool        `json:"container,omitempty"`
+	WSL       bool        `json:"wsl,omitempty"`
+	CI        string      `json:"ci,omitempty"`
+	// Telemetry lists the signals the telemetry settings let out.
+	Telemetry []telemetry.Signal `json:"telemetry"`
+}
+
+// Setting is a configuration value.
+type Setting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Source is the layer the value came from, such as "env", and Origin
+	// the file or variable, when known.
+	Source string `json:"source,omitempty"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// Component is a component registered in the hub.
+type Component struct {
+	Dimension   hub.Dimension `json:"dimension"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	// State is "ready", or for lazy components "lazy" until their factory
+	// has run.
+	State string `json:"state"`
+}
+
+// Option configures Collect.
+type Option func(*options)
+
+type settings struct {
+	section string
+	v       any
+}
+
+type options struct {
+	configs  []*config.Config
+	settings []settings
+	hub      *hub.Hub
+	health   *health.Registry
+	redactor *log.Redactor
+}
+
+// WithConfig adds the values of c to the report.
+func WithConfig(c *config.Config) Option {
+	return func(o *options) { o.configs = append(o.configs, c) }
+}
+
+// WithSettings adds the fields of v, a struct filled by config.Bind or a
+// pointer to one, to the report under section, so "telemetry" and the
+// Disabled field give "telemetry.disabled".
+func WithSettings(section string, v any) Option {
+	return func(o *options) { o.settings = append(o.settings, settings{section, v}) }
+}
+
+// WithHub sets the hub whose components are listed. The default is
+// hub.Default().
+func WithHub(h *hub.Hub) Option {
+	return func(o *options) { o.hub = h }
+}
+
+// WithHealth sets the registry whose checks are run. The default is
+// health.Default; nil runs no checks.
+func WithHealth(r *health.Registry) Option {
+	return func(o *options) { o.health = r }
+}
+
+// WithRedactor sets the redactor deciding which keys hold credentials.
+// The default is log.NewRedactor().
+func WithRedactor(r *log.Redactor) Option {
+	return func(o *options) { o.redactor = r }
+}
+
+func newOptions(opts []Option) options {
+	o := options{hub: hub.Default(), health: health.Default, redactor: log.NewRedactor()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Collect builds the report, running the health checks under ctx.
+func Collect(ctx context.Context, opts ...Option) (Report, error) {
+	o := newOptions(opts)
+	p := platform.Current()
+	r := Report{
+		Build: version.Get(),
+		Environment: Environment{
+			Profile:   env.Current(),
+			Platform:  p.String(),
+			Hostname:  p.Hostname,
+			CPUs:      p.CPUs,
+			Container: p.Container,
+			WSL:       p.WSL,
+			CI:        p.CI,
+			Telemetry: []telemetry.Signal{},
+		},
+		Config:     []Setting{},
+		Components: []Component{},
+	}
+	ts := telemetry.Current()
+	for _, sig := range []telemetry.Signal{telemetry.Traces, telemetry.Metrics, telemetry.Logs, telemetry.CrashReports} {
+		if ts.Enabled(sig) {
+			r.Environment.Telemetry = append(r.Environment.Telemetry, sig)
+		}
+	}
+
+	for _, c := range o.configs {
+		for _, key := range c.Keys() {
+			v, _ := c.Get(key)
+			src, origin, _ := c.Source(key)
+			s := Setting{Key: key, Value: o.mask(key, v), Source: src.String(), Origin: origin}
+			if c.FromSecret(key) {
+				s.Value = log.RedactedValue
+			}
+			r.Config = append(r.Config, s)
+		}
+	}
+	for _, s := range o.settings {
+		fields, err := config.Schema(s.v)
+		if err != nil {
+			return Report{}, fmt.Errorf("diag: %s: %w", s.section, err)
+		}
+		rv := reflect.Indirect(reflect.ValueOf(s.v))
+		for _, f := range fields {
+			key := s.section + "." + f.Key
+			setting := Setting{Key: key, Value: o.mask(key, fieldValue(rv, f.Field))}
+			if _, ok := os.LookupEnv(f.Env); ok && f.Env != "" {
+				setting.Source, setting.Origin = config.SourceEnv.String(), f.Env
+			}
+			r.Config = append(r.Config, setting)
+		}
+	}
+
+	if o.hub != nil {
+		for _, dim := range o.hub.Dimensions() {
+			for _, e := range o.hub.List(dim) {
+				state := "ready"
+				if e.Lazy && !e.Initialized {
+					state = "lazy"
+				}
+				r.Components = append(r.Components, Component{Dimension: dim, Name: e.Name, Description: e.Description, State: state})
+			}
+		}
+	}
+
+	if o.health != nil && len(o.health.Names()) > 0 {
+		h := o.health.Readiness(ctx)
+		r.Health = &h
+	}
+	return r, nil
+}
+
+// fieldValue returns the field of v at path, such as "HTTP.Timeout".
+func fieldValue(v reflect.Value, path string) any {
+	for name := range strings.SplitSeq(path, ".") {
+		v = reflect.Indirect(v)
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		v = v.FieldByName(name)
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// mask formats v for the report, hiding it if key names a credential and
+// the password of a URL. Empty values are left empty, so a missing
+// credential shows.
+func (o options) mask(key string, v any) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []string:
+		s = strings.Join(v, ",")
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" {
+		return ""
+	}
+	if o.redactor.RedactAttr(key, s) == log.RedactedValue {
+		return log.RedactedValue
+	}
+	if u, err := url.Parse(s); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			s = u.Redacted()
+		}
+	}
+	return o.redactor.RedactString(s)
+}
+
+// WriteText writes the report as sections of aligned columns.
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	e := r.Environment
+	fmt.Fprintln(tw, "Build")
+	fmt.Fprintf(tw, "  version\t%s\n", r.Build)
+	fmt.Fprintln(tw, "\nEnvironment")
+	fmt.Fprintf(tw, "  profile\t%s\n", e.Profile)
+	fmt.Fprintf(tw, "  platform\t%s\n", e.Platform)
+	if e.Hostname != "" {
+		fmt.Fprintf(tw, "  hostname\t%s\n", e.Hostname)
+	}
+	fmt.Fprintf(tw, "  cpus\t%d\n", e.CPUs)
+	if e.Container {
+		fmt.Fprintln(tw, "  container\tyes")
+	}
+	if e.WSL {
+		fmt.Fprintln(tw, "  wsl\tyes")
+	}
+	if e.CI != "" {
+		fmt.Fprintf(tw, "  ci\t%s\n", e.CI)
+	}
+	signals := make([]string, len(e.Telemetry))
+	for i, s := range e.Telemetry {
+		signals[i] = string(s)
+	}
+	fmt.Fprintf(tw, "  telemetry\t%s\n", cmp.Or(strings.Join(signals, ", "), "off"))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Config) > 0 {
+		fmt.Fprintln(w, "\nConfiguration")
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, s := range r.Config {
+			row(tw, s.Key, s.Value, strings.TrimSpace(s.Source+" "+s.Origin))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Components) > 0 {
+		fmt.Fprintln(w, "\nComponents")
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, c := range r.Components {
+			row(tw, string(c.Dimension), c.Name, c.State, c.Description)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if r.Health != nil {
+		fmt.Fprintf(w, "\nHealth: %s\n", r.Health.Status)
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, c := range r.Health.Checks {
+			row(tw, c.Name, string(c.Status), c.Duration.Round(time.Millisecond).String(), c.Error)
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+// row writes cells separated by tabs, leaving out trailing empty ones so
+// lines carry no trailing padding.
+func row(w io.Writer, cells ...string) {
+	for len(cells) > 0 && cells[len(cells)-1] == "" {
+		cells = cells[:len(cells)-1]
+	}
+	fmt.Fprintf(w, "  %s\n", strings.Join(cells, "\t"))
+}
@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package diag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/cli"
+	"github.com/provide-io/provide-foundation/go/config"
+	"github.com/provide-io/provide-foundation/go/health"
+	"github.com/provide-io/provide-foundation/go/hub"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+type serverSettings struct {
+	Addr    string        `config:"addr" env:"DIAG_TEST_ADDR" default:":8080"`
+	Timeout time.Duration `config:"timeout" default:"30s"`
+	APIKey  string        `config:"api_key"`
+}
+
+func testOptions(t *testing.T) []Option {
+	t.Helper()
+	t.Setenv("DIAG_TEST_ADDR", ":9090")
+	t.Setenv("APP_DATABASE_URL", "postgresql://app:s3cret@db/myapp")
+	cfg, err := config.Load(
+		config.WithDefaults(map[string]any{
+			"database.url":      "",
+			"database.password": "secret://db",
+			"log.level":         "info",
+		}),
+		config.WithEnvPrefix("APP"),
+		config.WithSecrets(config.SecretsProviderFunc(func(context.Context, string) (string, error) { return "hunter2", nil })),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s serverSettings
+	if err := config.Bind(&s); err != nil {
+		t.Fatal(err)
+	}
+	s.APIKey = "k-123"
+
+	h := hub.New()
+	h.MustRegister(hub.Component, "cache", struct{}{}, hub.WithDescription("in-memory cache"))
+	h.RegisterLazy(hub.Client, "billing", func(context.Context) (any, error) { return nil, nil })
+	checks := health.New()
+	checks.Register("database", func(context.Context) error { return nil })
+	return []Option{WithConfig(cfg), WithSettings("server", &s), WithHub(h), WithHealth(checks)}
+}
+
+func TestCollect(t *testing.T) {
+	r, err := Collect(context.Background(), testOptions(t)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Setting{
+		{Key: "database.password", Value: log.RedactedValue, Source: "default"},
+		{Key: "database.url", Value: "postgresql://app:xxxxx@db/myapp", Source: "env", Origin: "APP_DATABASE_URL"},
+		{Key: "log.level", Value: "info", Source: "default"},
+		{Key: "server.addr", Value: ":9090", Source: "env", Origin: "DIAG_TEST_ADDR"},
+		{Key: "server.timeout", Value: "30s"},
+		{Key: "server.api_key", Value: log.RedactedValue},
+	}
+	if len(r.Config) != len(want) {
+		t.Fatalf("config = %+v", r.Config)
+	}
+	for i, s := range r.Config {
+		if s != want[i] {
+			t.Errorf("config[%d] = %+v, want %+v", i, s, want[i])
+		}
+	}
+
+	if len(r.Components) != 2 ||
+		r.Components[0] != (Component{Dimension: hub.Client, Name: "billing", State: "lazy"}) ||
+		r.Components[1] != (Component{Dimension: hub.Component, Name: "cache", Description: "in-memory cache", State: "ready"}) {
+		t.Errorf("components = %+v", r.Components)
+	}
+	if r.Health == nil || r.Health.Status != health.StatusUp || !r.Healthy() {
+		t.Errorf("health = %+v", r.Health)
+	}
+	if r.Environment.Platform == "" || r.Environment.CPUs == 0 || r.Build.GoVersion == "" {
+		t.Errorf("report = %+v", r)
+	}
+
+	if _, err := Collect(context.Background(), WithSettings("bad", 1)); err == nil {
+		t.Error("Collect accepted settings that are not a struct")
+	}
+}
+
+func run(t *testing.T, opts []Option, args ...string) (int, string, string) {
+	t.Helper()
+	h := hub.New()
+	cli.MustRegister(h, Command(opts...))
+	var stdout, stderr bytes.Buffer
+	app := &cli.App{Name: "app", Hub: h, Stdout: &stdout, Stderr: &stderr}
+	code := app.Run(context.Background(), args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCommand(t *testing.T) {
+	opts := testOptions(t)
+	code, out, _ := run(t, opts, "doctor")
+	if code != cli.ExitOK {
+		t.Errorf("code = %d", code)
+	}
+	for _, want := range []string{
+		"Build\n  version  ",
+		"\nConfiguration\n  database.password  [REDACTED]  ",
+		"  server.api_key     [REDACTED]\n",
+		"\nComponents\n  client     billing  lazy\n  component  cache    ready  in-memory cache\n",
+		"\nHealth: up\n  database  up  ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "s3cret") || strings.Contains(out, "k-123") {
+		t.Errorf("output leaks a secret:\n%s", out)
+	}
+
+	code, out, _ = run(t, opts, "diag", "--json")
+	var r Report
+	if err := json.Unmarshal([]byte(out), &r); err != nil || code != cli.ExitOK {
+		t.Fatalf("diag --json = %d, %q: %v", code, out, err)
+	}
+	if len(r.Config) != 6 || len(r.Components) != 2 {
+		t.Errorf("decoded report = %+v", r)
+	}
+	if code, out, _ = run(t, opts, "doctor", "-o", "yaml"); code != cli.ExitOK || !strings.Contains(out, "\nconfig:\n") {
+		t.Errorf("doctor -o yaml = %d:\n%s", code, out)
+	}
+
+	checks := health.New()
+	checks.Register("queue", func(context.Context) error { return errors.New("unreachable") })
+	code, out, errOut := run(t, append(opts, WithHealth(checks)), "doctor")
+	if code != cli.ExitFailure || !strings.Contains(out, "\nHealth: down\n  queue  down  ") || !strings.Contains(out, "unreachable") {
+		t.Errorf("failing check = %d:\n%s", code, out)
+	}
+	if errOut != "app doctor: health checks failed\n" {
+		t.Errorf("stderr = %q", errOut)
+	}
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package env detects the deployment profile the process runs in, so
+// behavior that differs between development and production is decided in
+// one place:
+//
+//	if env.IsProduction() {
+//		// no debug endpoints
+//	}
+//
+// The profile comes from the first of Vars that is set, and defaults to
+// Test under go test and to Development otherwise.
+package env
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// Profile is a deployment profile. Besides the predefined profiles any
+// lower-case name, such as "qa", is allowed.
+type Profile string
+
+// Predefined profiles.
+const (
+	Development Profile = "development"
+	Test        Profile = "test"
+	Staging     Profile = "staging"
+	Production  Profile = "production"
+)
+
+// Vars are the environment variables consulted by Detect, in order.
+// FOUNDATION_ENV is the foundation's own; the others are common
+// conventions, including the variables read by the Python foundation.
+var Vars = []string{
+	"FOUNDATION_ENV",
+	"OTEL_DEPLOYMENT_ENVIRONMENT",
+	"PROVIDE_ENVIRONMENT",
+	"APP_ENV",
+	"ENVIRONMENT",
+}
+
+// aliases maps common spellings to the predefined profiles.
+var aliases = map[string]Profile{
+	"dev":         Development,
+	"develop":     Development,
+	"development": Development,
+	"local":       Development,
+	"test":        Test,
+	"testing":     Test,
+	"ci":          Test,
+	"stage":       Staging,
+	"staging":     Staging,
+	"stg":         Staging,
+	"preprod":     Staging,
+	"prod":        Production,
+	"production":  Production,
+	"prd":         Production,
+	"live":        Production,
+}
+
+// Parse normalizes a profile name, mapping aliases such as "prod" and
+// "dev" to the predefined profiles. It reports false for an empty name.
+func Parse(s string) (Profile, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "", false
+	}
+	if p, ok := aliases[s]; ok {
+		return p, true
+	}
+	return Profile(s), true
+}
+
+// Detect resolves the profile from the variables in Vars using lookup,
+// usually os.LookupEnv.
+func Detect(lookup func(string) (string, bool)) Profile {
+	for _, name := range Vars {
+		if v, ok := lookup(name); ok {
+			if p, ok := Parse(v); ok {
+				return p
+			}
+		}
+	}
+	if testing.Testing() {
+		return Test
+	}
+	return Development
+}
+
+var (
+	current  atomic.Pointer[Profile]
+	detected = sync.OnceValue(func() Profile { return Detect(os.LookupEnv) })
+)
+
+// Current returns the profile of the process: the one passed to Set, or
+// else the one detected from the environment on first use.
+func Current() Profile {
+	if p := current.Load(); p != nil {
+		return *p
+	}
+	return detected()
+}
+
+// Set overrides the detected profile, e.g. from a command-line flag.
+func Set(p Profile) { current.Store(&p) }
+
+// IsDevelopment reports whether the process runs in development.
+func IsDevelopment() bool { return Current() == Development }
+
+// IsTest reports whether the process runs under test.
+func IsTest() bool { return Current() == Test }
+
+// IsStaging reports whether the process runs in staging.
+func IsStaging() bool { return Current() == Staging }
+
+// IsProduction reports whether the process runs in production.
+func IsProduction() bool { return Current() == Production }
+
+// String returns the profile name.
+func (p Profile) String() string { return string(p) }
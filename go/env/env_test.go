@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package env
+
+import "testing"
+
+func lookup(vars map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := vars[k]
+		return v, ok
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct {
+		vars map[string]string
+		want Profile
+	}{
+		{map[string]string{"FOUNDATION_ENV": "prod", "APP_ENV": "dev"}, Production},
+		{map[string]string{"FOUNDATION_ENV": " ", "OTEL_DEPLOYMENT_ENVIRONMENT": "Staging"}, Staging},
+		{map[string]string{"PROVIDE_ENVIRONMENT": "local"}, Development},
+		{map[string]string{"ENVIRONMENT": "QA"}, Profile("qa")},
+		{nil, Test},
+	} {
+		if got := Detect(lookup(tc.vars)); got != tc.want {
+			t.Errorf("Detect(%v) = %q, want %q", tc.vars, got, tc.want)
+		}
+	}
+}
+
+func TestSetOverridesDetection(t *testing.T) {
+	t.Cleanup(func() { current.Store(nil) })
+	if !IsTest() {
+		t.Fatalf("Current() = %q under go test", Current())
+	}
+	Set(Production)
+	if !IsProduction() || IsDevelopment() || IsStaging() {
+		t.Errorf("Current() = %q after Set", Current())
+	}
+}
@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package errorsx sorts errors into a small set of classes, so that retry
+// policies, circuit breakers and logs can tell a timeout from a missing
+// row without knowing which transport or driver produced the error:
+//
+//	if errorsx.IsNotFound(err) {
+//		return nil, ErrNoSuchUser
+//	}
+//
+// An error gets its class from, in order: a class attached with Mark or
+// New, a Class method on the error, a classifier added with Register, and
+// finally the standard library errors this package knows. Packages that
+// wrap a transport or driver register a classifier for its native errors
+// in an init function.
+package errorsx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"syscall"
+)
+
+// Class is the kind of an error. Its value is a stable snake_case name,
+// suitable for logs and metric labels.
+type Class string
+
+// Classes. Unknown is the class of errors nothing recognizes.
+const (
+	Unknown          Class = ""
+	Canceled         Class = "canceled"
+	Timeout          Class = "timeout"
+	Unavailable      Class = "unavailable"
+	RateLimited      Class = "rate_limited"
+	NotFound         Class = "not_found"
+	Conflict         Class = "conflict"
+	InvalidArgument  Class = "invalid_argument"
+	PermissionDenied Class = "permission_denied"
+	Unauthenticated  Class = "unauthenticated"
+	Internal         Class = "internal"
+)
+
+// String returns the class name, or "unknown".
+func (c Class) String() string {
+	if c == Unknown {
+		return "unknown"
+	}
+	return string(c)
+}
+
+// Retryable reports whether an error of the class may go away if the
+// operation is repeated: timeouts, unavailable dependencies and rate
+// limiting.
+func (c Class) Retryable() bool {
+	switch c {
+	case Timeout, Unavailable, RateLimited:
+		return true
+	}
+	return false
+}
+
+// CallerFault reports whether an error of the class is down to the
+// request rather than the dependency that answered it, so repeating the
+// request cannot help and the answer says nothing about the dependency's
+// health.
+func (c Class) CallerFault() bool {
+	switch c {
+	case Canceled, NotFound, Conflict, InvalidArgument, PermissionDenied, Unauthenticated:
+		return true
+	}
+	return false
+}
+
+// Classifier returns the class of errors it recognizes and Unknown for
+// the rest. It is given the error as returned, and should use errors.Is
+// or errors.As to look through wrapping.
+type Classifier func(err error) Class
+
+var (
+	mu          sync.RWMutex
+	classifiers []Classifier
+)
+
+// Register adds a classifier. Classifiers are consulted before the
+// built-in rules, the most recently registered first, so a driver can
+// override how the standard library errors it returns are classed.
+func Register(fn Classifier) {
+	mu.Lock()
+	defer mu.Unlock()
+	classifiers = append(classifiers, fn)
+}
+
+// classError is an error with an attached class.
+type classError struct {
+	err   error
+	class Class
+}
+
+func (e *classError) Error() string { return e.err.Error() }
+func (e *classError) Unwrap() error { return e.err }
+func (e *classError) Class() Class  { return e.class }
+
+// Mark attaches class to err, overriding any class it would otherwise
+// get. It returns nil for a nil err.
+func Mark(err error, class Class) error {
+	if err == nil {
+		return nil
+	}
+	return &classError{err: err, class: class}
+}
+
+// New returns an error with the given message and class.
+func New(class Class, msg string) error {
+	return &classError{err: errors.New(msg), class: class}
+}
+
+// Classify returns the class of err, or Unknown for nil and unrecognized
+// errors.
+func Classify(err error) Class {
+	if err == nil {
+		return Unknown
+	}
+	var ce interface{ Class() Class }
+	if errors.As(err, &ce) {
+		if c := ce.Class(); c != Unknown {
+			return c
+		}
+	}
+	mu.RLock()
+	fns := slices.Clone(classifiers)
+	mu.RUnlock()
+	for _, fn := range slices.Backward(fns) {
+		if c := fn(err); c != Unknown {
+			return c
+		}
+	}
+	return builtin(err)
+}
+
+// builtin classes the standard library errors.
+func builtin(err error) Class {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return Timeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return Timeout
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, fs.ErrNotExist):
+		return NotFound
+	case errors.Is(err, fs.ErrExist):
+		return Conflict
+	case errors.Is(err, fs.ErrPermission):
+		return PermissionDenied
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE), errors.Is(err, sql.ErrConnDone):
+		return Unavailable
+	}
+	return Unknown
+}
+
+// FromHTTPStatus returns the class of an HTTP error status, or Unknown for
+// statuses below 400.
+func FromHTTPStatus(code int) Class {
+	switch {
+	case code < 400:
+		return Unknown
+	case code == 400, code == 422:
+		return InvalidArgument
+	case code == 401:
+		return Unauthenticated
+	case code == 403:
+		return PermissionDenied
+	case code == 404, code == 410:
+		return NotFound
+	case code == 408, code == 504:
+		return Timeout
+	case code == 409, code == 412:
+		return Conflict
+	case code == 429:
+		return RateLimited
+	case code == 502, code == 503:
+		return Unavailable
+	case code >= 500:
+		return Internal
+	}
+	return InvalidArgument
+}
+
+// IsRetryable reports whether err's class is retryable.
+func IsRetryable(err error) bool { return Classify(err).Retryable() }
+
+// IsTimeout reports whether err is a timeout.
+func IsTimeout(err error) bool { return Classify(err) == Timeout }
+
+// IsNotFound reports whether err reports something missing.
+func IsNotFound(err error) bool { return Classify(err) == NotFound }
+
+// IsConflict reports whether err reports a conflict with existing state.
+func IsConflict(err error) bool { return Classify(err) == Conflict }
+
+// IsUnavailable reports whether err reports an unreachable dependency.
+func IsUnavailable(err error) bool { return Classify(err) == Unavailable }
+
+// IsRateLimited reports whether err reports rate limiting.
+func IsRateLimited(err error) bool { return Classify(err) == RateLimited }
+
+// IsCanceled reports whether err reports cancellation.
+func IsCanceled(err error) bool { return Classify(err) == Canceled }
+
+// IsInvalidArgument reports whether err reports a bad request.
+func IsInvalidArgument(err error) bool { return Classify(err) == InvalidArgument }
+
+// IsPermissionDenied reports whether err reports a forbidden operation.
+func IsPermissionDenied(err error) bool { return Classify(err) == PermissionDenied }
+
+// IsUnauthenticated reports whether err reports missing or bad
+// credentials.
+func IsUnauthenticated(err error) bool { return Classify(err) == Unauthenticated }
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package errorsx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"syscall"
+	"testing"
+)
+
+type driverError struct{ code string }
+
+func (e *driverError) Error() string { return "driver: " + e.code }
+
+type classed struct{}
+
+func (classed) Error() string { return "classed" }
+func (classed) Class() Class  { return Conflict }
+
+func TestClassify(t *testing.T) {
+	Register(func(err error) Class {
+		var de *driverError
+		if errors.As(err, &de) && de.code == "40001" {
+			return Conflict
+		}
+		return Unknown
+	})
+	Register(func(err error) Class {
+		if errors.Is(err, sql.ErrNoRows) {
+			return InvalidArgument
+		}
+		return Unknown
+	})
+	defer func() { classifiers = nil }()
+
+	tests := []struct {
+		err  error
+		want Class
+	}{
+		{nil, Unknown},
+		{errors.New("boom"), Unknown},
+		{fmt.Errorf("get: %w", context.Canceled), Canceled},
+		{fmt.Errorf("get: %w", context.DeadlineExceeded), Timeout},
+		{&net.OpError{Op: "dial", Err: &net.DNSError{IsTimeout: true}}, Timeout},
+		{&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, Unavailable},
+		{fs.ErrNotExist, NotFound},
+		{fs.ErrPermission, PermissionDenied},
+		{sql.ErrNoRows, InvalidArgument},
+		{fmt.Errorf("tx: %w", &driverError{"40001"}), Conflict},
+		{&driverError{"42P01"}, Unknown},
+		{fmt.Errorf("wrapped: %w", classed{}), Conflict},
+		{Mark(context.Canceled, Timeout), Timeout},
+		{New(RateLimited, "slow down"), RateLimited},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Errorf("Classify(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+	if Mark(nil, Timeout) != nil {
+		t.Error("Mark(nil) != nil")
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	timeout := Mark(errors.New("read"), Timeout)
+	if !IsTimeout(timeout) || !IsRetryable(timeout) || IsNotFound(timeout) {
+		t.Errorf("timeout predicates wrong")
+	}
+	missing := fmt.Errorf("user 7: %w", fs.ErrNotExist)
+	if !IsNotFound(missing) || IsRetryable(missing) || !Classify(missing).CallerFault() {
+		t.Errorf("not found predicates wrong")
+	}
+	if IsRetryable(errors.New("boom")) || Classify(errors.New("boom")).CallerFault() {
+		t.Errorf("unknown errors have no policy")
+	}
+}
+
+func TestFromHTTPStatus(t *testing.T) {
+	for code, want := range map[int]Class{
+		200: Unknown, 400: InvalidArgument, 401: Unauthenticated, 403: PermissionDenied,
+		404: NotFound, 409: Conflict, 418: InvalidArgument, 429: RateLimited,
+		500: Internal, 503: Unavailable, 504: Timeout,
+	} {
+		if got := FromHTTPStatus(code); got != want {
+			t.Errorf("FromHTTPStatus(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/provide-io/provide-foundation/go/eventbus"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// DefaultMaxDeliveries is the number of attempts after which a message is
+// dead-lettered.
+const DefaultMaxDeliveries = 5
+
+// Keyer is implemented by events that set the key of their message, such
+// as an aggregate ID that keeps the events of one entity in order.
+type Keyer interface {
+	EventKey() string
+}
+
+// Option configures NewBridge.
+type Option func(*Bridge)
+
+// WithMaxDeliveries sets the attempts after which a message whose
+// handlers keep failing is dead-lettered. The default is
+// DefaultMaxDeliveries.
+func WithMaxDeliveries(n int) Option {
+	return func(br *Bridge) { br.maxDeliveries = n }
+}
+
+// WithDeadLetter sets the topic a failed message of a topic is moved to.
+// The default appends ".dlq"; a function returning "" drops the message
+// after logging it.
+func WithDeadLetter(topic func(string) string) Option {
+	return func(br *Bridge) { br.deadLetter = topic }
+}
+
+// WithLogger sets the logger; the default is log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(br *Bridge) { br.logger = l }
+}
+
+// Bridge connects a bus to a broker.
+type Bridge struct {
+	bus           *eventbus.Bus
+	broker        Broker
+	maxDeliveries int
+	deadLetter    func(string) string
+	logger        *log.Logger
+}
+
+// NewBridge returns a bridge between bus and b.
+func NewBridge(bus *eventbus.Bus, b Broker, opts ...Option) *Bridge {
+	br := &Bridge{
+		bus:           bus,
+		broker:        b,
+		maxDeliveries: DefaultMaxDeliveries,
+		deadLetter:    func(topic string) string { return topic + ".dlq" },
+	}
+	for _, opt := range opts {
+		opt(br)
+	}
+	if br.maxDeliveries <= 0 {
+		br.maxDeliveries = DefaultMaxDeliveries
+	}
+	return br
+}
+
+// receivedFrom marks the context of an event published by Consume with
+// its topic, so Route does not send it back.
+type receivedFrom struct{}
+
+// Route sends the events of type T published on the bus to topic, as
+// JSON. Events published by Consume from the same topic, and by handlers
+// using their context, are not sent back. The send is synchronous, so
+// Publish fails when the broker does not accept the event.
+func Route[T any](br *Bridge, topic string) (unsubscribe func()) {
+	return eventbus.SubscribeTo(br.bus, func(ctx context.Context, e T) error {
+		if from, _ := ctx.Value(receivedFrom{}).(string); from == topic {
+			return nil
+		}
+		body, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("broker: %w", err)
+		}
+		m := Message{
+			Topic:   topic,
+			Headers: map[string]string{HeaderEvent: eventbus.Name(e), HeaderContentType: "application/json"},
+			Body:    body,
+		}
+		if k, ok := any(e).(Keyer); ok {
+			m.Key = k.EventKey()
+		}
+		return br.broker.Publish(ctx, m)
+	}, eventbus.WithName("broker:"+topic))
+}
+
+// Consume receives the events of type T from topic as a member of group
+// and publishes them on the bus. A message is delivered again while the
+// synchronous bus handlers fail, and is dead-lettered after the maximum
+// deliveries, or at once when it does not decode.
+func Consume[T any](ctx context.Context, br *Bridge, topic, group string) (Subscription, error) {
+	return br.broker.Subscribe(ctx, topic, group, func(ctx context.Context, m Message) error {
+		var e T
+		if err := json.Unmarshal(m.Body, &e); err != nil {
+			return br.deadLetterMessage(ctx, m, fmt.Errorf("decoding: %w", err))
+		}
+		err := br.bus.Publish(context.WithValue(ctx, receivedFrom{}, topic), e)
+		if err == nil {
+			return nil
+		}
+		if m.Attempt >= br.maxDeliveries {
+			return br.deadLetterMessage(ctx, m, err)
+		}
+		br.log().WarnCtx(ctx, "broker_delivery_failed", "topic", m.Topic, "group", group, "attempt", m.Attempt, log.Err(err))
+		return err
+	})
+}
+
+// deadLetterMessage moves m to the dead-letter topic; if that fails, m is
+// delivered again.
+func (br *Bridge) deadLetterMessage(ctx context.Context, m Message, cause error) error {
+	topic := br.deadLetter(m.Topic)
+	br.log().ErrorCtx(ctx, "broker_dead_lettered", "topic", m.Topic, "dead_letter_topic", topic, "attempt", m.Attempt, log.Err(cause))
+	if topic == "" {
+		return nil
+	}
+	headers := maps.Clone(m.Headers)
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	headers[HeaderError] = cause.Error()
+	headers[HeaderTopic] = m.Topic
+	if err := br.broker.Publish(ctx, Message{Topic: topic, Key: m.Key, Headers: headers, Body: m.Body}); err != nil {
+		return fmt.Errorf("broker: dead-lettering to %s: %w", topic, err)
+	}
+	return nil
+}
+
+func (br *Bridge) log() *log.Logger {
+	if br.logger != nil {
+		return br.logger
+	}
+	return log.Default()
+}
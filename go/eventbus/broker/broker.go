@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package broker carries the events of an eventbus.Bus through a message
+// broker, so they reach the subscribers of other processes. Services keep
+// publishing and subscribing on the bus; a Bridge sends the events of the
+// routed types to the broker and publishes what it receives back on the
+// bus:
+//
+//	nb, err := nats.New(js, nats.Config{Stream: "EVENTS"})
+//	br := broker.NewBridge(bus, nb)
+//	broker.Route[UserCreated](br, "events.user.created")
+//	sub, err := broker.Consume[UserCreated](ctx, br, "events.user.created", "mailer")
+//
+// Delivery is at least once: a received event whose bus handlers fail is
+// delivered again, and after WithMaxDeliveries attempts it is moved to a
+// dead-letter topic. Subscribers sharing a consumer group divide the
+// messages of a topic between them; every group receives each message.
+//
+// The brokers are Memory, for tests and single processes, and the
+// adapters in the nats and kafka subpackages.
+package broker
+
+import (
+	"context"
+	"errors"
+)
+
+// Message is a message on a broker.
+type Message struct {
+	Topic string
+	// Key orders and partitions messages, where the broker supports it.
+	Key     string
+	Headers map[string]string
+	Body    []byte
+	// Attempt is the 1-based delivery attempt of a received message.
+	Attempt int
+}
+
+// Handler processes a received message. Returning an error asks for the
+// message to be delivered again.
+type Handler func(ctx context.Context, m Message) error
+
+// Broker publishes messages and delivers them to consumer groups.
+type Broker interface {
+	// Publish sends m, returning once the broker has accepted it.
+	Publish(ctx context.Context, m Message) error
+	// Subscribe delivers the messages of topic to h as a member of
+	// group, in the background, until the subscription is closed or ctx
+	// is done. A message is redelivered until h returns nil.
+	Subscribe(ctx context.Context, topic, group string, h Handler) (Subscription, error)
+	// Close closes the broker and its subscriptions.
+	Close() error
+}
+
+// Subscription is a subscription to a topic.
+type Subscription interface {
+	// Close stops the deliveries, waiting for a handler in progress.
+	Close() error
+}
+
+// ErrClosed is returned by a closed broker.
+var ErrClosed = errors.New("broker: closed")
+
+// Headers of the messages a Bridge publishes.
+const (
+	HeaderEvent       = "event-name"
+	HeaderContentType = "content-type"
+	// HeaderError and HeaderTopic are set on dead-lettered messages to
+	// the last handler error and the topic the message came from.
+	HeaderError = "dead-letter-error"
+	HeaderTopic = "dead-letter-topic"
+)
@@ -0,0 +1,212 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package broker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/eventbus"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+type userCreated struct {
+	ID int `json:"id"`
+}
+
+func (e userCreated) EventKey() string { return "user-1" }
+
+// received collects what a bus handler saw.
+type received struct {
+	mu  sync.Mutex
+	ids []int
+}
+
+func (r *received) add(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, id)
+}
+
+func (r *received) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.ids)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBridgeCarriesEvents(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemory()
+	defer b.Close()
+
+	// The consuming service subscribes on its bus as it would locally.
+	consumerBus := eventbus.New()
+	var got received
+	eventbus.SubscribeTo(consumerBus, func(_ context.Context, e userCreated) error {
+		got.add(e.ID)
+		return nil
+	})
+	if _, err := Consume[userCreated](ctx, NewBridge(consumerBus, b), "users", "mailer"); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var sent []Message
+	b.Subscribe(ctx, "users", "tap", func(_ context.Context, m Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, m)
+		return nil
+	})
+	producerBus := eventbus.New()
+	Route[userCreated](NewBridge(producerBus, b), "users")
+	for id := range 3 {
+		if err := producerBus.Publish(ctx, userCreated{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, "events", func() bool { return got.len() == 3 })
+	waitFor(t, "messages", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent) == 3
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if sent[0].Key != "user-1" || sent[0].Headers[HeaderEvent] != "broker.userCreated" || string(sent[0].Body) != `{"id":0}` {
+		t.Errorf("message = %+v", sent[0])
+	}
+}
+
+func TestConsumerGroups(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemory()
+	defer b.Close()
+	var mu sync.Mutex
+	counts := map[string]int{}
+	handler := func(name string) Handler {
+		return func(context.Context, Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			counts[name]++
+			return nil
+		}
+	}
+	b.Subscribe(ctx, "t", "a", handler("a1"))
+	b.Subscribe(ctx, "t", "a", handler("a2"))
+	b.Subscribe(ctx, "t", "b", handler("b"))
+	for range 20 {
+		b.Publish(ctx, Message{Topic: "t"})
+	}
+	total := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts["a1"] + counts["a2"] + counts["b"]
+	}
+	waitFor(t, "deliveries", func() bool { return total() == 40 })
+	mu.Lock()
+	defer mu.Unlock()
+	if counts["b"] != 20 || counts["a1"]+counts["a2"] != 20 {
+		t.Errorf("counts = %v", counts)
+	}
+}
+
+func TestRedeliveryAndDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	rec := logtest.Capture(t)
+	b := NewMemory()
+	defer b.Close()
+	bus := eventbus.New()
+	var attempts received
+	eventbus.SubscribeTo(bus, func(_ context.Context, e userCreated) error {
+		attempts.add(e.ID)
+		if e.ID == 1 && attempts.len() == 1 {
+			return errors.New("transient")
+		}
+		if e.ID == 2 {
+			return errors.New("always")
+		}
+		return nil
+	})
+	var dead []Message
+	var mu sync.Mutex
+	b.Subscribe(ctx, "users.dlq", "ops", func(_ context.Context, m Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		dead = append(dead, m)
+		return nil
+	})
+	br := NewBridge(bus, b, WithMaxDeliveries(3), WithLogger(rec.Logger()))
+	if _, err := Consume[userCreated](ctx, br, "users", "svc"); err != nil {
+		t.Fatal(err)
+	}
+	b.Publish(ctx, Message{Topic: "users", Body: []byte(`{"id":1}`)})
+	b.Publish(ctx, Message{Topic: "users", Body: []byte(`{"id":2}`)})
+	b.Publish(ctx, Message{Topic: "users", Body: []byte(`not json`)})
+	waitFor(t, "dead letters", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(dead) == 2
+	})
+	// id 1 twice, id 2 three times.
+	waitFor(t, "attempts", func() bool { return attempts.len() == 5 })
+	mu.Lock()
+	defer mu.Unlock()
+	for _, m := range dead {
+		if m.Headers[HeaderTopic] != "users" || m.Headers[HeaderError] == "" {
+			t.Errorf("dead letter = %+v", m)
+		}
+	}
+	if n := rec.WithEvent("broker_dead_lettered").Count(); n != 2 {
+		t.Errorf("logged %d dead letters", n)
+	}
+	if n := rec.WithEvent("broker_delivery_failed").Count(); n != 3 {
+		t.Errorf("logged %d failed deliveries", n)
+	}
+}
+
+func TestConsumedEventsAreNotRoutedBack(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemory()
+	defer b.Close()
+	bus := eventbus.New()
+	br := NewBridge(bus, b)
+	Route[userCreated](br, "users")
+	var got received
+	eventbus.SubscribeTo(bus, func(_ context.Context, e userCreated) error { got.add(e.ID); return nil })
+	if _, err := Consume[userCreated](ctx, br, "users", "svc"); err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish(ctx, userCreated{ID: 1}) // handled locally, then once more from the broker
+	waitFor(t, "event", func() bool { return got.len() == 2 })
+	time.Sleep(20 * time.Millisecond)
+	if got.len() != 2 {
+		t.Errorf("handled %d times", got.len())
+	}
+}
+
+func TestMemoryClose(t *testing.T) {
+	b := NewMemory()
+	b.Close()
+	if err := b.Publish(context.Background(), Message{Topic: "t"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish = %v", err)
+	}
+	if _, err := b.Subscribe(context.Background(), "t", "g", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe = %v", err)
+	}
+}
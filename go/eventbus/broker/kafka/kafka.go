@@ -0,0 +1,264 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package kafka is a broker.Broker on Kafka. Topics are Kafka topics, a
+// consumer group is a Kafka consumer group, and message keys choose the
+// partition, so the events of one key stay in order.
+//
+// Kafka delivers a partition as a log rather than message by message: a
+// record cannot be redelivered on its own without holding back the ones
+// after it. A failed record is therefore handled again in place, after a
+// backoff, until its handler succeeds or the bridge dead-letters it, and
+// offsets are committed only past handled records, so a member that stops
+// leaves the rest to be delivered again to the group.
+//
+// The broker drives a Client, which adapts a Kafka client library such as
+// franz-go or kafka-go; the module itself does not depend on one:
+//
+//	b := kafka.New(myClient{cl})
+//	br := broker.NewBridge(bus, b)
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/eventbus/broker"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+// Header is a record header.
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Record is a Kafka record.
+type Record struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+}
+
+// Client is the part of a Kafka client the broker uses.
+type Client interface {
+	// Produce writes r and returns once the cluster acknowledged it;
+	// acks=all is recommended for at-least-once delivery.
+	Produce(ctx context.Context, r Record) error
+	// NewConsumer joins group as a new member consuming topic, with
+	// automatic offset commits disabled.
+	NewConsumer(ctx context.Context, topic, group string) (Consumer, error)
+}
+
+// Consumer is a member of a consumer group.
+type Consumer interface {
+	// Poll returns the next records of the partitions assigned to the
+	// member, each partition in offset order, waiting until some arrive
+	// or ctx is done.
+	Poll(ctx context.Context) ([]Record, error)
+	// Commit commits, for each partition, the offset after the last of
+	// records in it.
+	Commit(ctx context.Context, records []Record) error
+	// Close leaves the group.
+	Close() error
+}
+
+// DefaultBackoff spaces out the attempts of a failed record.
+var DefaultBackoff = retry.Policy{Backoff: retry.Exponential, BaseDelay: 100 * time.Millisecond, MaxDelay: 10 * time.Second}
+
+// Option configures New.
+type Option func(*Broker)
+
+// WithBackoff sets the delay before each new attempt of a failed record,
+// from p.Delay of the attempts so far. The default is DefaultBackoff.
+func WithBackoff(p retry.Policy) Option {
+	return func(b *Broker) { b.backoff = p }
+}
+
+// WithClock sets the clock of the backoff. The default is clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(b *Broker) { b.clock = c }
+}
+
+// WithLogger sets the logger; the default is log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(b *Broker) { b.logger = l }
+}
+
+// Broker is a broker on a Kafka client. It is safe for concurrent use.
+type Broker struct {
+	client  Client
+	backoff retry.Policy
+	clock   clock.Clock
+	logger  *log.Logger
+
+	mu     sync.Mutex
+	subs   map[*subscription]bool
+	closed bool
+}
+
+var _ broker.Broker = (*Broker)(nil)
+
+// New returns a broker on client.
+func New(client Client, opts ...Option) *Broker {
+	b := &Broker{client: client, backoff: DefaultBackoff, clock: clock.Real(), subs: map[*subscription]bool{}}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Publish produces m, keyed by m.Key.
+func (b *Broker) Publish(ctx context.Context, m broker.Message) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return broker.ErrClosed
+	}
+	r := Record{Topic: m.Topic, Value: m.Body}
+	if m.Key != "" {
+		r.Key = []byte(m.Key)
+	}
+	for k, v := range m.Headers {
+		r.Headers = append(r.Headers, Header{Key: k, Value: []byte(v)})
+	}
+	if err := b.client.Produce(ctx, r); err != nil {
+		return fmt.Errorf("kafka: producing to %s: %w", m.Topic, err)
+	}
+	return nil
+}
+
+// Subscribe joins group on topic and handles its records in the
+// background.
+func (b *Broker) Subscribe(ctx context.Context, topic, group string, h broker.Handler) (broker.Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, broker.ErrClosed
+	}
+	c, err := b.client.NewConsumer(ctx, topic, group)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: joining %s on %s: %w", group, topic, err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &subscription{b: b, c: c, group: group, h: h, cancel: cancel, done: make(chan struct{})}
+	b.subs[s] = true
+	go s.run(ctx)
+	return s, nil
+}
+
+// Close closes the subscriptions. The client is left open.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = map[*subscription]bool{}
+	b.mu.Unlock()
+	var errs []error
+	for s := range subs {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (b *Broker) log() *log.Logger {
+	if b.logger != nil {
+		return b.logger
+	}
+	return log.Default()
+}
+
+type subscription struct {
+	b      *Broker
+	c      Consumer
+	group  string
+	h      broker.Handler
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+	err    error
+}
+
+func (s *subscription) run(ctx context.Context) {
+	defer close(s.done)
+	for ctx.Err() == nil {
+		records, err := s.c.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.b.log().WarnCtx(ctx, "kafka_poll_failed", "group", s.group, log.Err(err))
+			if clock.Sleep(ctx, s.b.clock, s.b.backoff.Delay(1)) != nil {
+				return
+			}
+			continue
+		}
+		handled := s.handleAll(ctx, records)
+		if len(handled) == 0 {
+			continue
+		}
+		// Commit even when stopping, so the records handled are not
+		// delivered again.
+		if err := s.c.Commit(context.WithoutCancel(ctx), handled); err != nil {
+			s.b.log().WarnCtx(ctx, "kafka_commit_failed", "group", s.group, log.Err(err))
+		}
+	}
+}
+
+// handleAll handles records in order and returns those handled, which
+// stop short when ctx is done.
+func (s *subscription) handleAll(ctx context.Context, records []Record) []Record {
+	for i, r := range records {
+		if !s.handle(ctx, r) {
+			return records[:i]
+		}
+	}
+	return records
+}
+
+// handle runs the handler on r until it succeeds, and reports whether it
+// did before ctx was done.
+func (s *subscription) handle(ctx context.Context, r Record) bool {
+	m := broker.Message{Topic: r.Topic, Key: string(r.Key), Body: r.Value}
+	if len(r.Headers) > 0 {
+		m.Headers = make(map[string]string, len(r.Headers))
+		for _, h := range r.Headers {
+			m.Headers[h.Key] = string(h.Value)
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		if ctx.Err() != nil {
+			return false
+		}
+		m.Attempt = attempt
+		if s.h(ctx, m) == nil {
+			return true
+		}
+		if clock.Sleep(ctx, s.b.clock, s.b.backoff.Delay(attempt)) != nil {
+			return false
+		}
+	}
+}
+
+// Close stops handling, waits for a handler in progress, commits what was
+// handled and leaves the group.
+func (s *subscription) Close() error {
+	s.once.Do(func() {
+		s.cancel()
+		<-s.done
+		s.err = s.c.Close()
+		s.b.mu.Lock()
+		delete(s.b.subs, s)
+		s.b.mu.Unlock()
+	})
+	return s.err
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/eventbus/broker"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+// cluster is a fake client with one partition per topic.
+type cluster struct {
+	mu        sync.Mutex
+	logs      map[string][]Record
+	committed map[string]int64 // by group, the next offset
+}
+
+func newCluster() *cluster {
+	return &cluster{logs: map[string][]Record{}, committed: map[string]int64{}}
+}
+
+func (c *cluster) Produce(_ context.Context, r Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r.Offset = int64(len(c.logs[r.Topic]))
+	c.logs[r.Topic] = append(c.logs[r.Topic], r)
+	return nil
+}
+
+func (c *cluster) NewConsumer(_ context.Context, topic, group string) (Consumer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &consumer{c: c, topic: topic, group: group, next: c.committed[group]}, nil
+}
+
+func (c *cluster) offset(group string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.committed[group]
+}
+
+type consumer struct {
+	c     *cluster
+	topic string
+	group string
+	next  int64
+}
+
+func (k *consumer) Poll(ctx context.Context) ([]Record, error) {
+	for {
+		k.c.mu.Lock()
+		log := k.c.logs[k.topic]
+		if int(k.next) < len(log) {
+			records := append([]Record(nil), log[k.next:]...)
+			k.next = int64(len(log))
+			k.c.mu.Unlock()
+			return records, nil
+		}
+		k.c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func (k *consumer) Commit(_ context.Context, records []Record) error {
+	k.c.mu.Lock()
+	defer k.c.mu.Unlock()
+	k.c.committed[k.group] = records[len(records)-1].Offset + 1
+	return nil
+}
+
+func (k *consumer) Close() error { return nil }
+
+func TestPublishAndConsume(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster()
+	b := New(c)
+	defer b.Close()
+	err := b.Publish(ctx, broker.Message{Topic: "users", Key: "u1", Headers: map[string]string{"event-name": "user.created"}, Body: []byte("1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(chan broker.Message, 1)
+	sub, err := b.Subscribe(ctx, "users", "mailer", func(_ context.Context, m broker.Message) error {
+		got <- m
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := <-got
+	if m.Key != "u1" || string(m.Body) != "1" || m.Headers["event-name"] != "user.created" || m.Attempt != 1 {
+		t.Errorf("message = %+v", m)
+	}
+	sub.Close()
+	if c.offset("mailer") != 1 {
+		t.Errorf("committed %d", c.offset("mailer"))
+	}
+}
+
+func TestFailedRecordIsRetriedInPlace(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster()
+	b := New(c, WithBackoff(retry.Policy{Backoff: retry.Fixed, BaseDelay: time.Millisecond}))
+	defer b.Close()
+	for _, body := range []string{"a", "b"} {
+		b.Publish(ctx, broker.Message{Topic: "t", Body: []byte(body)})
+	}
+	var mu sync.Mutex
+	var seen []string
+	var attempts []int
+	done := make(chan struct{})
+	b.Subscribe(ctx, "t", "g", func(_ context.Context, m broker.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, string(m.Body))
+		attempts = append(attempts, m.Attempt)
+		if string(m.Body) == "a" && m.Attempt < 3 {
+			return errors.New("transient")
+		}
+		if string(m.Body) == "b" {
+			close(done)
+		}
+		return nil
+	})
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 4 || seen[3] != "b" || attempts[2] != 3 {
+		t.Errorf("seen %v, attempts %v", seen, attempts)
+	}
+}
+
+func TestStopLeavesUnhandledRecords(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster()
+	b := New(c, WithBackoff(retry.Policy{Backoff: retry.Fixed, BaseDelay: time.Hour}))
+	b.Publish(ctx, broker.Message{Topic: "t", Body: []byte("ok")})
+	b.Publish(ctx, broker.Message{Topic: "t", Body: []byte("fails")})
+	failed := make(chan struct{}, 1)
+	sub, _ := b.Subscribe(ctx, "t", "g", func(_ context.Context, m broker.Message) error {
+		if string(m.Body) == "fails" {
+			failed <- struct{}{}
+			return errors.New("down")
+		}
+		return nil
+	})
+	<-failed
+	if err := sub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if c.offset("g") != 1 {
+		t.Errorf("committed %d, want 1", c.offset("g"))
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Publish(ctx, broker.Message{Topic: "t"}); !errors.Is(err, broker.ErrClosed) {
+		t.Errorf("Publish after Close = %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package broker
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// Memory is a broker within the process, for tests and for services that
+// want the bridge semantics without a server. Each consumer group of a
+// topic queues the messages published after its first member subscribed;
+// a failed message goes to the back of its queue. It is safe for
+// concurrent use.
+type Memory struct {
+	mu     sync.Mutex
+	groups map[string]map[string]*queue // by topic, then group
+	subs   map[*memorySub]bool
+	closed bool
+}
+
+var _ Broker = (*Memory)(nil)
+
+type queue struct {
+	msgs  []Message
+	ready chan struct{} // signaled when msgs is not empty
+}
+
+func (q *queue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// NewMemory returns an empty broker.
+func NewMemory() *Memory {
+	return &Memory{groups: map[string]map[string]*queue{}, subs: map[*memorySub]bool{}}
+}
+
+// Publish queues m for every consumer group of its topic.
+func (b *Memory) Publish(ctx context.Context, m Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	for _, q := range b.groups[m.Topic] {
+		c := m
+		c.Headers = maps.Clone(m.Headers)
+		c.Attempt = 0
+		q.msgs = append(q.msgs, c)
+		q.signal()
+	}
+	return nil
+}
+
+// Subscribe delivers the messages of topic queued for group to h.
+func (b *Memory) Subscribe(ctx context.Context, topic, group string, h Handler) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if b.groups[topic] == nil {
+		b.groups[topic] = map[string]*queue{}
+	}
+	q := b.groups[topic][group]
+	if q == nil {
+		q = &queue{ready: make(chan struct{}, 1)}
+		b.groups[topic][group] = q
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &memorySub{b: b, cancel: cancel, done: make(chan struct{})}
+	b.subs[s] = true
+	go s.run(ctx, q, h)
+	return s, nil
+}
+
+// Close closes every subscription and rejects further messages.
+func (b *Memory) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = map[*memorySub]bool{}
+	b.mu.Unlock()
+	for s := range subs {
+		s.Close()
+	}
+	return nil
+}
+
+type memorySub struct {
+	b      *Memory
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (s *memorySub) run(ctx context.Context, q *queue, h Handler) {
+	defer close(s.done)
+	for {
+		m, ok := s.next(q)
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.ready:
+				continue
+			}
+		}
+		m.Attempt++
+		if err := h(ctx, m); err != nil {
+			s.b.mu.Lock()
+			q.msgs = append(q.msgs, m)
+			q.signal()
+			s.b.mu.Unlock()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// next takes the first queued message, leaving the queue signaled for
+// the other members if more remain.
+func (s *memorySub) next(q *queue) (Message, bool) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if len(q.msgs) == 0 {
+		return Message{}, false
+	}
+	m := q.msgs[0]
+	q.msgs = q.msgs[1:]
+	if len(q.msgs) > 0 {
+		q.signal()
+	}
+	return m, true
+}
+
+// Close stops the subscription, waiting for a handler in progress.
+func (s *memorySub) Close() error {
+	s.cancel()
+	<-s.done
+	s.b.mu.Lock()
+	delete(s.b.subs, s)
+	s.b.mu.Unlock()
+	return nil
+}
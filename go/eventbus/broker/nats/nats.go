@@ -0,0 +1,291 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nats is a broker.Broker on NATS JetStream. Topics are subjects
+// of one stream, which keeps messages until every consumer group has
+// acknowledged them; a consumer group is a durable pull consumer, whose
+// members fetch in turn. A message not acknowledged within Config.AckWait,
+// because its handler failed or its process stopped, is delivered again.
+//
+// The broker drives a Client, which adapts a JetStream client library
+// such as nats.go; the module itself does not depend on one. The client
+// owns the connection, with its TLS, authentication and reconnection:
+//
+//	b, err := nats.New(myJetStream{js}, nats.Config{Stream: "EVENTS"})
+//	err = b.EnsureStream(ctx, "events.>")
+//	br := broker.NewBridge(bus, b)
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/eventbus/broker"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Defaults of Config.
+const (
+	DefaultAckWait   = 30 * time.Second
+	DefaultPullBatch = 10
+)
+
+// HeaderKey carries broker.Message.Key, which NATS has no field for.
+const HeaderKey = "Message-Key"
+
+// Config describes the stream and consumers of a broker, bindable with
+// config.Bind.
+type Config struct {
+	Stream    string        `config:"stream" env:"FOUNDATION_NATS_STREAM" validate:"required" desc:"JetStream stream holding the topics."`
+	AckWait   time.Duration `config:"ack_wait" env:"FOUNDATION_NATS_ACK_WAIT" default:"30s" desc:"How long a delivered message waits for its acknowledgment before redelivery."`
+	PullBatch int           `config:"pull_batch" env:"FOUNDATION_NATS_PULL_BATCH" default:"10" validate:"min=0" desc:"Messages a consumer fetches at once."`
+}
+
+// Client is the part of a JetStream client the broker uses.
+type Client interface {
+	// Publish stores a message in the stream holding subject and returns
+	// once the server acknowledged it.
+	Publish(ctx context.Context, subject string, header map[string]string, data []byte) error
+	// CreateStream creates the stream name over subjects if it does not
+	// exist, leaving an existing one as it is.
+	CreateStream(ctx context.Context, name string, subjects []string) error
+	// Consumer creates or joins the durable pull consumer of stream
+	// described by cfg.
+	Consumer(ctx context.Context, stream string, cfg ConsumerConfig) (Consumer, error)
+}
+
+// ConsumerConfig describes a durable pull consumer with explicit
+// acknowledgment, delivering every message of the stream that matches
+// FilterSubject, without a limit of deliveries.
+type ConsumerConfig struct {
+	Durable       string
+	FilterSubject string
+	AckWait       time.Duration
+}
+
+// Consumer is a member of a durable pull consumer.
+type Consumer interface {
+	// Fetch returns up to batch messages, waiting until some arrive, the
+	// server ends the pull, which returns none, or ctx is done.
+	Fetch(ctx context.Context, batch int) ([]Msg, error)
+}
+
+// Msg is a message fetched from a consumer.
+type Msg interface {
+	Subject() string
+	Header() map[string]string
+	Data() []byte
+	// Delivered is the delivery count of the message, from 1.
+	Delivered() int
+	// Ack acknowledges the message; Nak asks for its redelivery.
+	Ack(ctx context.Context) error
+	Nak(ctx context.Context) error
+}
+
+// fetchRetry spaces out the fetches of a consumer that fail.
+const fetchRetry = time.Second
+
+// Option configures New.
+type Option func(*Broker)
+
+// WithLogger sets the logger; the default is log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(b *Broker) { b.logger = l }
+}
+
+// WithClock sets the clock of the delay after a failed fetch. The default
+// is clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(b *Broker) { b.clock = c }
+}
+
+// Broker is a broker on a JetStream stream. It is safe for concurrent
+// use.
+type Broker struct {
+	client Client
+	cfg    Config
+	logger *log.Logger
+	clock  clock.Clock
+
+	mu     sync.Mutex
+	subs   map[*subscription]bool
+	closed bool
+}
+
+var _ broker.Broker = (*Broker)(nil)
+
+// New returns a broker on the stream of cfg through client.
+func New(client Client, cfg Config, opts ...Option) (*Broker, error) {
+	if cfg.Stream == "" {
+		return nil, errors.New("nats: no stream")
+	}
+	if cfg.AckWait <= 0 {
+		cfg.AckWait = DefaultAckWait
+	}
+	if cfg.PullBatch <= 0 {
+		cfg.PullBatch = DefaultPullBatch
+	}
+	b := &Broker{client: client, cfg: cfg, clock: clock.Real(), subs: map[*subscription]bool{}}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b, nil
+}
+
+// EnsureStream creates the stream of the configuration, holding subjects,
+// if it does not exist. An existing stream is left as it is.
+func (b *Broker) EnsureStream(ctx context.Context, subjects ...string) error {
+	if err := b.client.CreateStream(ctx, b.cfg.Stream, subjects); err != nil {
+		return fmt.Errorf("nats: creating stream %s: %w", b.cfg.Stream, err)
+	}
+	return nil
+}
+
+// Publish stores m in the stream, returning once the server has
+// acknowledged it.
+func (b *Broker) Publish(ctx context.Context, m broker.Message) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return broker.ErrClosed
+	}
+	header := maps.Clone(m.Headers)
+	if m.Key != "" {
+		if header == nil {
+			header = map[string]string{}
+		}
+		header[HeaderKey] = m.Key
+	}
+	if err := b.client.Publish(ctx, m.Topic, header, m.Body); err != nil {
+		return fmt.Errorf("nats: publishing to %s: %w", m.Topic, err)
+	}
+	return nil
+}
+
+// consumerName returns the durable consumer of group on topic. Names
+// cannot hold the subject separators.
+func consumerName(topic, group string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '/', '\\':
+			return '_'
+		}
+		return r
+	}, group+"_"+topic)
+}
+
+// Subscribe creates or joins the durable consumer of group on topic and
+// fetches its messages in the background. The consumer outlives the
+// subscription, so the group resumes where it stopped.
+func (b *Broker) Subscribe(ctx context.Context, topic, group string, h broker.Handler) (broker.Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, broker.ErrClosed
+	}
+	name := consumerName(topic, group)
+	c, err := b.client.Consumer(ctx, b.cfg.Stream, ConsumerConfig{Durable: name, FilterSubject: topic, AckWait: b.cfg.AckWait})
+	if err != nil {
+		return nil, fmt.Errorf("nats: creating consumer %s: %w", name, err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &subscription{b: b, c: c, group: group, h: h, cancel: cancel, done: make(chan struct{})}
+	b.subs[s] = true
+	go s.run(ctx)
+	return s, nil
+}
+
+// Close closes the subscriptions. The client is left open.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subs
+	b.subs = map[*subscription]bool{}
+	b.mu.Unlock()
+	for s := range subs {
+		s.Close()
+	}
+	return nil
+}
+
+func (b *Broker) log() *log.Logger {
+	if b.logger != nil {
+		return b.logger
+	}
+	return log.Default()
+}
+
+// subscription is a pull subscription of a consumer group.
+type subscription struct {
+	b      *Broker
+	c      Consumer
+	group  string
+	h      broker.Handler
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (s *subscription) run(ctx context.Context) {
+	defer close(s.done)
+	for ctx.Err() == nil {
+		msgs, err := s.c.Fetch(ctx, s.b.cfg.PullBatch)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.b.log().WarnCtx(ctx, "nats_fetch_failed", "group", s.group, log.Err(err))
+			if clock.Sleep(ctx, s.b.clock, fetchRetry) != nil {
+				return
+			}
+			continue
+		}
+		for _, m := range msgs {
+			if ctx.Err() != nil {
+				return
+			}
+			s.handle(ctx, m)
+		}
+	}
+}
+
+// handle runs the handler on m and acknowledges it, or asks for its
+// redelivery.
+func (s *subscription) handle(ctx context.Context, m Msg) {
+	bm := broker.Message{Topic: m.Subject(), Body: m.Data(), Attempt: max(m.Delivered(), 1), Headers: m.Header()}
+	if k, ok := bm.Headers[HeaderKey]; ok {
+		bm.Key = k
+		bm.Headers = maps.Clone(bm.Headers)
+		delete(bm.Headers, HeaderKey)
+	}
+	// Acknowledge even when stopping, so the message handled is not
+	// delivered again.
+	actx := context.WithoutCancel(ctx)
+	ack := m.Ack
+	if err := s.h(ctx, bm); err != nil {
+		ack = m.Nak
+	}
+	if err := ack(actx); err != nil {
+		s.b.log().WarnCtx(ctx, "nats_ack_failed", "topic", bm.Topic, "group", s.group, log.Err(err))
+	}
+}
+
+// Close stops fetching, waiting for a handler in progress. Messages
+// fetched but not handled are redelivered after the acknowledgment wait.
+func (s *subscription) Close() error {
+	s.once.Do(func() {
+		s.cancel()
+		<-s.done
+		s.b.mu.Lock()
+		delete(s.b.subs, s)
+		s.b.mu.Unlock()
+	})
+	return nil
+}
@@ -0,0 +1,269 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/eventbus/broker"
+)
+
+// jetStream is a fake client with streams over subjects ending in ".>"
+// and durable consumers that redeliver what they were asked to.
+type jetStream struct {
+	mu        sync.Mutex
+	streams   map[string]string // name to subject prefix
+	messages  []*msg
+	consumers map[string]*consumer
+}
+
+func newJetStream() *jetStream {
+	return &jetStream{streams: map[string]string{}, consumers: map[string]*consumer{}}
+}
+
+func (js *jetStream) Publish(_ context.Context, subject string, header map[string]string, data []byte) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	for _, prefix := range js.streams {
+		if strings.HasPrefix(subject, prefix) {
+			js.messages = append(js.messages, &msg{subject: subject, header: maps.Clone(header), data: data})
+			return nil
+		}
+	}
+	return errors.New("no responders")
+}
+
+func (js *jetStream) CreateStream(_ context.Context, name string, subjects []string) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if _, ok := js.streams[name]; !ok {
+		js.streams[name] = strings.TrimSuffix(subjects[0], ">")
+	}
+	return nil
+}
+
+func (js *jetStream) Consumer(_ context.Context, stream string, cfg ConsumerConfig) (Consumer, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if _, ok := js.streams[stream]; !ok {
+		return nil, errors.New("stream not found")
+	}
+	c, ok := js.consumers[cfg.Durable]
+	if !ok {
+		c = &consumer{js: js, cfg: cfg, delivered: map[*msg]int{}, acked: map[*msg]bool{}}
+		js.consumers[cfg.Durable] = c
+	}
+	return c, nil
+}
+
+// acked returns the messages consumer name acknowledged.
+func (js *jetStream) acked(name string) int {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if c := js.consumers[name]; c != nil {
+		return len(c.acked)
+	}
+	return 0
+}
+
+type consumer struct {
+	js        *jetStream
+	cfg       ConsumerConfig
+	delivered map[*msg]int
+	acked     map[*msg]bool
+	pending   map[*msg]bool
+}
+
+func (c *consumer) Fetch(ctx context.Context, batch int) ([]Msg, error) {
+	for {
+		c.js.mu.Lock()
+		var msgs []Msg
+		for _, m := range c.js.messages {
+			if len(msgs) == batch {
+				break
+			}
+			if m.subject != c.cfg.FilterSubject || c.acked[m] || c.pending[m] {
+				continue
+			}
+			c.delivered[m]++
+			if c.pending == nil {
+				c.pending = map[*msg]bool{}
+			}
+			c.pending[m] = true
+			msgs = append(msgs, &delivery{msg: m, c: c, n: c.delivered[m]})
+		}
+		c.js.mu.Unlock()
+		if len(msgs) > 0 {
+			return msgs, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+type msg struct {
+	subject string
+	header  map[string]string
+	data    []byte
+}
+
+type delivery struct {
+	*msg
+	c *consumer
+	n int
+}
+
+func (d *delivery) Subject() string           { return d.subject }
+func (d *delivery) Header() map[string]string { return d.header }
+func (d *delivery) Data() []byte              { return d.data }
+func (d *delivery) Delivered() int            { return d.n }
+
+func (d *delivery) Ack(context.Context) error {
+	d.c.js.mu.Lock()
+	defer d.c.js.mu.Unlock()
+	delete(d.c.pending, d.msg)
+	d.c.acked[d.msg] = true
+	return nil
+}
+
+func (d *delivery) Nak(context.Context) error {
+	d.c.js.mu.Lock()
+	defer d.c.js.mu.Unlock()
+	delete(d.c.pending, d.msg)
+	return nil
+}
+
+func newBroker(t *testing.T, js *jetStream) *Broker {
+	t.Helper()
+	b, err := New(js, Config{Stream: "EVENTS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	if err := b.EnsureStream(context.Background(), "events.>"); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	js := newJetStream()
+	b := newBroker(t, js)
+	ctx := context.Background()
+	if err := b.EnsureStream(ctx, "events.>"); err != nil {
+		t.Fatal(err)
+	}
+	err := b.Publish(ctx, broker.Message{Topic: "events.user", Key: "u1", Headers: map[string]string{"event-name": "user.created"}, Body: []byte(`{"id":1}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(chan broker.Message, 1)
+	sub, err := b.Subscribe(ctx, "events.user", "mailer", func(_ context.Context, m broker.Message) error {
+		got <- m
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	m := <-got
+	if m.Topic != "events.user" || m.Key != "u1" || m.Headers["event-name"] != "user.created" ||
+		m.Headers[HeaderKey] != "" || string(m.Body) != `{"id":1}` || m.Attempt != 1 {
+		t.Errorf("message = %+v", m)
+	}
+	waitFor(t, func() bool { return js.acked("mailer_events_user") == 1 })
+}
+
+func TestNakRedelivers(t *testing.T) {
+	js := newJetStream()
+	b := newBroker(t, js)
+	ctx := context.Background()
+	b.Publish(ctx, broker.Message{Topic: "events.a", Body: []byte("x")})
+	attempts := make(chan int, 3)
+	sub, err := b.Subscribe(ctx, "events.a", "g", func(_ context.Context, m broker.Message) error {
+		attempts <- m.Attempt
+		if m.Attempt < 2 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if a, b := <-attempts, <-attempts; a != 1 || b != 2 {
+		t.Errorf("attempts %d, %d", a, b)
+	}
+	waitFor(t, func() bool { return js.acked("g_events_a") == 1 })
+}
+
+func TestGroupResumes(t *testing.T) {
+	js := newJetStream()
+	b := newBroker(t, js)
+	ctx := context.Background()
+	got := make(chan string, 2)
+	h := func(_ context.Context, m broker.Message) error {
+		got <- string(m.Body)
+		return nil
+	}
+	sub, _ := b.Subscribe(ctx, "events.r", "g", h)
+	b.Publish(ctx, broker.Message{Topic: "events.r", Body: []byte("first")})
+	if body := <-got; body != "first" {
+		t.Errorf("got %q", body)
+	}
+	waitFor(t, func() bool { return js.acked("g_events_r") == 1 })
+	sub.Close()
+
+	b.Publish(ctx, broker.Message{Topic: "events.r", Body: []byte("second")})
+	sub, _ = b.Subscribe(ctx, "events.r", "g", h)
+	defer sub.Close()
+	if body := <-got; body != "second" {
+		t.Errorf("after resuming got %q", body)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := New(newJetStream(), Config{}); err == nil {
+		t.Error("created without a stream")
+	}
+	b := newBroker(t, newJetStream())
+	ctx := context.Background()
+	err := b.Publish(ctx, broker.Message{Topic: "other.x", Body: []byte("x")})
+	if err == nil || !strings.Contains(err.Error(), "nats: publishing to other.x") {
+		t.Errorf("Publish outside the stream = %v", err)
+	}
+	b.Close()
+	if err := b.Publish(ctx, broker.Message{Topic: "events.x"}); !errors.Is(err, broker.ErrClosed) {
+		t.Errorf("Publish after Close = %v", err)
+	}
+	if _, err := b.Subscribe(ctx, "events.x", "g", nil); !errors.Is(err, broker.ErrClosed) {
+		t.Errorf("Subscribe after Close = %v", err)
+	}
+}
+
+func TestConsumerName(t *testing.T) {
+	if got := consumerName("events.user.*", "mail ers"); got != "mail_ers_events_user__" {
+		t.Errorf("consumerName = %q", got)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,287 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package eventbus dispatches events within a process, so the code that
+// makes a change announces it without depending on the code that reacts:
+//
+//	type UserCreated struct{ ID int }
+//
+//	eventbus.Subscribe(func(ctx context.Context, e UserCreated) error {
+//		return notifier.Send(ctx, notify.Message{Event: "user.created", Data: map[string]any{"user_id": e.ID}})
+//	}, eventbus.Async())
+//
+//	err := eventbus.Publish(ctx, UserCreated{ID: 1})
+//
+// A handler of type T receives the events that are a T: the events of
+// that type, or, when T is an interface, every event implementing it.
+// Handlers run in the publishing goroutine, in the order they subscribed,
+// unless they subscribe with Async. Publish and Subscribe use the Default
+// bus; services wired by the container take a *Bus and use its Publish
+// method and SubscribeTo.
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/recovery"
+)
+
+// Handler handles an event; middleware wraps it.
+type Handler func(ctx context.Context, event any) error
+
+// Middleware wraps the handlers of a bus with a cross-cutting concern
+// such as retries, timeouts or tracing.
+type Middleware func(next Handler) Handler
+
+// ErrorPolicy decides what Publish does when a synchronous handler fails.
+type ErrorPolicy int
+
+// Error policies. Failures are logged as "event_handler_failed" under
+// every policy.
+const (
+	// Collect runs every handler and returns their errors joined.
+	Collect ErrorPolicy = iota
+	// StopOnError returns the first error, skipping the handlers after
+	// the failing one.
+	StopOnError
+	// LogOnly runs every handler and returns nil.
+	LogOnly
+)
+
+// Namer is implemented by events that name themselves in logs; other
+// events are named by their type.
+type Namer interface {
+	EventName() string
+}
+
+// Name returns the name of event: its EventName, or its type, such as
+// "users.UserCreated".
+func Name(event any) string {
+	if n, ok := event.(Namer); ok {
+		return n.EventName()
+	}
+	return fmt.Sprintf("%T", event)
+}
+
+// ErrNilEvent is returned by Publish for a nil event.
+var ErrNilEvent = errors.New("eventbus: nil event")
+
+// Option configures New.
+type Option func(*Bus)
+
+// WithErrorPolicy sets how Publish handles failing synchronous handlers.
+// The default is Collect.
+func WithErrorPolicy(p ErrorPolicy) Option {
+	return func(b *Bus) { b.policy = p }
+}
+
+// WithMiddleware wraps every handler, synchronous or not, with mw; the
+// first is outermost.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(b *Bus) { b.middleware = append(b.middleware, mw...) }
+}
+
+// WithAsyncLimit bounds the asynchronous handlers running at once; Publish
+// waits for a free slot when the limit is reached. Zero, the default, is
+// unbounded.
+func WithAsyncLimit(n int) Option {
+	return func(b *Bus) { b.limit = n }
+}
+
+// WithLogger sets the logger; the default is log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(b *Bus) { b.logger = l }
+}
+
+// Bus dispatches events to subscribers. It is safe for concurrent use.
+type Bus struct {
+	policy     ErrorPolicy
+	middleware []Middleware
+	limit      int
+	logger     *log.Logger
+	slots      chan struct{}
+
+	mu     sync.RWMutex
+	subs   []*subscription // replaced, never modified, so Publish can range without the lock
+	nextID int
+	wg     sync.WaitGroup
+}
+
+// New returns a bus without subscribers.
+func New(opts ...Option) *Bus {
+	b := &Bus{}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.limit > 0 {
+		b.slots = make(chan struct{}, b.limit)
+	}
+	return b
+}
+
+var defaultBus = New()
+
+// Default returns the bus of Publish and Subscribe.
+func Default() *Bus { return defaultBus }
+
+type subscription struct {
+	id      int
+	name    string
+	async   bool
+	accepts func(event any) bool
+	handler Handler
+}
+
+// SubscribeOption configures a subscription.
+type SubscribeOption func(*subscription)
+
+// Async runs the handler in its own goroutine, so Publish returns without
+// waiting for it. Its context keeps the values of the publisher's but not
+// its cancellation, and its failures are only logged.
+func Async() SubscribeOption {
+	return func(s *subscription) { s.async = true }
+}
+
+// WithName names the handler in logs. The default is the event type.
+func WithName(name string) SubscribeOption {
+	return func(s *subscription) { s.name = name }
+}
+
+// Subscribe subscribes fn to the events of type T on the Default bus and
+// returns a function that unsubscribes it.
+func Subscribe[T any](fn func(ctx context.Context, event T) error, opts ...SubscribeOption) (unsubscribe func()) {
+	return SubscribeTo(defaultBus, fn, opts...)
+}
+
+// SubscribeTo subscribes fn to the events of type T on b and returns a
+// function that unsubscribes it.
+func SubscribeTo[T any](b *Bus, fn func(ctx context.Context, event T) error, opts ...SubscribeOption) (unsubscribe func()) {
+	s := &subscription{
+		name:    reflect.TypeFor[T]().String(),
+		accepts: func(event any) bool { _, ok := event.(T); return ok },
+		handler: func(ctx context.Context, event any) error { return fn(ctx, event.(T)) },
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return b.add(s)
+}
+
+func (b *Bus) add(s *subscription) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, mw := range slices.Backward(b.middleware) {
+		s.handler = mw(s.handler)
+	}
+	b.nextID++
+	s.id = b.nextID
+	b.subs = append(slices.Clip(b.subs), s)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.subs = slices.DeleteFunc(slices.Clone(b.subs), func(x *subscription) bool { return x.id == s.id })
+		})
+	}
+}
+
+// Publish publishes event on the Default bus.
+func Publish(ctx context.Context, event any) error {
+	return defaultBus.Publish(ctx, event)
+}
+
+// Publish runs the synchronous handlers of event in turn and starts the
+// asynchronous ones in the background. Its error follows the error policy
+// of the bus; a handler that panics fails with a *recovery.PanicError.
+func (b *Bus) Publish(ctx context.Context, event any) error {
+	if event == nil {
+		return ErrNilEvent
+	}
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+	var errs []error
+	for _, s := range subs {
+		if !s.accepts(event) {
+			continue
+		}
+		if s.async {
+			b.dispatchAsync(ctx, s, event)
+			continue
+		}
+		err := b.call(ctx, s, event)
+		if err == nil {
+			continue
+		}
+		if b.policy == StopOnError {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	if b.policy == LogOnly {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+func (b *Bus) dispatchAsync(ctx context.Context, s *subscription, event any) {
+	ctx = context.WithoutCancel(ctx)
+	if b.slots != nil {
+		b.slots <- struct{}{}
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		if b.slots != nil {
+			defer func() { <-b.slots }()
+		}
+		b.call(ctx, s, event)
+	}()
+}
+
+// call runs one handler and logs its failure.
+func (b *Bus) call(ctx context.Context, s *subscription, event any) error {
+	err := recovery.Do(ctx, func(ctx context.Context) error {
+		return s.handler(ctx, event)
+	}, recovery.WithLogger(b.log()))
+	if err == nil {
+		return nil
+	}
+	name := Name(event)
+	b.log().WarnCtx(ctx, "event_handler_failed", "event_name", name, "handler", s.name, "async", s.async, log.Err(err))
+	return fmt.Errorf("eventbus: %s: %s: %w", name, s.name, err)
+}
+
+// Wait waits until the asynchronous handlers running or started by an
+// earlier Publish return, or ctx is done.
+func (b *Bus) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnStop waits for the asynchronous handlers, so the container stops a
+// bus before the services its handlers use.
+func (b *Bus) OnStop(ctx context.Context) error { return b.Wait(ctx) }
+
+func (b *Bus) log() *log.Logger {
+	if b.logger != nil {
+		return b.logger
+	}
+	return log.Default()
+}
@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/recovery"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+type userCreated struct{ ID int }
+
+type userDeleted struct{ ID int }
+
+func (userDeleted) EventName() string { return "user.deleted" }
+
+type ctxKey struct{}
+
+func TestTypedDispatch(t *testing.T) {
+	b := New()
+	ctx := context.Background()
+	var got []string
+	SubscribeTo(b, func(_ context.Context, e userCreated) error {
+		got = append(got, "created")
+		return nil
+	})
+	SubscribeTo(b, func(_ context.Context, e userDeleted) error {
+		got = append(got, "deleted")
+		return nil
+	})
+	SubscribeTo(b, func(_ context.Context, e Namer) error {
+		got = append(got, "namer:"+e.EventName())
+		return nil
+	})
+	SubscribeTo(b, func(_ context.Context, e any) error {
+		got = append(got, "any")
+		return nil
+	})
+	b.Publish(ctx, userCreated{1})
+	b.Publish(ctx, userDeleted{1})
+	want := "created,any,deleted,namer:user.deleted,any"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+	if err := b.Publish(ctx, nil); !errors.Is(err, ErrNilEvent) {
+		t.Errorf("Publish(nil) = %v", err)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	b := New()
+	var n int
+	unsubscribe := SubscribeTo(b, func(context.Context, userCreated) error { n++; return nil })
+	b.Publish(context.Background(), userCreated{})
+	unsubscribe()
+	unsubscribe()
+	b.Publish(context.Background(), userCreated{})
+	if n != 1 {
+		t.Errorf("handled %d events", n)
+	}
+}
+
+func TestErrorPolicies(t *testing.T) {
+	boom := errors.New("boom")
+	for _, tc := range []struct {
+		policy ErrorPolicy
+		calls  int
+		err    bool
+	}{
+		{Collect, 3, true},
+		{StopOnError, 1, true},
+		{LogOnly, 3, false},
+	} {
+		rec := logtest.Capture(t)
+		b := New(WithErrorPolicy(tc.policy), WithLogger(rec.Logger()))
+		var calls int
+		for range 3 {
+			SubscribeTo(b, func(context.Context, userCreated) error { calls++; return boom })
+		}
+		err := b.Publish(context.Background(), userCreated{})
+		if calls != tc.calls || (err != nil) != tc.err {
+			t.Errorf("policy %d: %d calls, err %v", tc.policy, calls, err)
+		}
+		if tc.err && !errors.Is(err, boom) {
+			t.Errorf("policy %d: err = %v", tc.policy, err)
+		}
+		if n := rec.WithEvent("event_handler_failed").WithField("event_name", "eventbus.userCreated").Count(); n != tc.calls {
+			t.Errorf("policy %d: logged %d failures", tc.policy, n)
+		}
+	}
+}
+
+func TestPanickingHandler(t *testing.T) {
+	rec := logtest.Capture(t)
+	b := New(WithLogger(rec.Logger()))
+	var after bool
+	SubscribeTo(b, func(context.Context, userCreated) error { panic("bad") })
+	SubscribeTo(b, func(context.Context, userCreated) error { after = true; return nil })
+	err := b.Publish(context.Background(), userCreated{})
+	if !errors.Is(err, recovery.ErrPanic) || !after {
+		t.Errorf("Publish = %v, later handler ran: %v", err, after)
+	}
+}
+
+func TestAsync(t *testing.T) {
+	rec := logtest.Capture(t)
+	b := New(WithLogger(rec.Logger()))
+	release := make(chan struct{})
+	var handled atomic.Int32
+	var value any
+	SubscribeTo(b, func(ctx context.Context, e userCreated) error {
+		<-release
+		value = ctx.Value(ctxKey{})
+		handled.Add(1)
+		return errors.New("async failure")
+	}, Async(), WithName("mailer"))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "v"))
+	if err := b.Publish(ctx, userCreated{}); err != nil {
+		t.Fatalf("Publish = %v", err)
+	}
+	cancel()
+	if handled.Load() != 0 {
+		t.Fatal("Publish waited for an async handler")
+	}
+	short, stop := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer stop()
+	if err := b.Wait(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait = %v", err)
+	}
+	close(release)
+	if err := b.OnStop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if handled.Load() != 1 || value != "v" {
+		t.Errorf("handled %d, value %v", handled.Load(), value)
+	}
+	if rec.WithEvent("event_handler_failed").WithField("handler", "mailer").WithField("async", true).Count() != 1 {
+		t.Errorf("events = %v", rec.Records().Events())
+	}
+}
+
+func TestAsyncLimit(t *testing.T) {
+	b := New(WithAsyncLimit(2))
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	SubscribeTo(b, func(context.Context, userCreated) error {
+		n := running.Add(1)
+		mu.Lock()
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}, Async())
+	for range 6 {
+		b.Publish(context.Background(), userCreated{})
+	}
+	b.Wait(context.Background())
+	if peak.Load() > 2 {
+		t.Errorf("%d handlers ran at once", peak.Load())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var trail []string
+	tag := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, event any) error {
+				trail = append(trail, name)
+				return next(ctx, event)
+			}
+		}
+	}
+	b := New(WithMiddleware(tag("outer"), tag("inner")))
+	SubscribeTo(b, func(context.Context, userCreated) error { trail = append(trail, "handler"); return nil })
+	b.Publish(context.Background(), userDeleted{}) // no subscriber, no middleware
+	b.Publish(context.Background(), userCreated{})
+	if strings.Join(trail, ",") != "outer,inner,handler" {
+		t.Errorf("trail = %v", trail)
+	}
+}
+
+func TestRetryAndTimeout(t *testing.T) {
+	b := New(WithMiddleware(
+		Retry(retry.Policy{MaxAttempts: 3, Backoff: retry.Fixed, BaseDelay: time.Millisecond}),
+		Timeout(time.Second),
+	))
+	var calls int
+	SubscribeTo(b, func(ctx context.Context, _ userCreated) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("no deadline")
+		}
+		if calls++; calls < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err := b.Publish(context.Background(), userCreated{}); err != nil || calls != 3 {
+		t.Errorf("Publish = %v after %d calls", err, calls)
+	}
+}
+
+func TestDefaultBus(t *testing.T) {
+	var got int
+	unsubscribe := Subscribe(func(_ context.Context, e userCreated) error { got = e.ID; return nil })
+	defer unsubscribe()
+	if err := Publish(context.Background(), userCreated{ID: 7}); err != nil || got != 7 {
+		t.Errorf("Publish = %v, got %d", err, got)
+	}
+}
@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package eventbus
+
+import (
+	"context"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+// Retry retries a failing handler under p.
+func Retry(p retry.Policy) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event any) error {
+			return retry.Do(ctx, p, func(ctx context.Context) error { return next(ctx, event) })
+		}
+	}
+}
+
+// Timeout bounds each handler call by d.
+func Timeout(d time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event any) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, event)
+		}
+	}
+}
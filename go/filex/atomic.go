@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package filex holds the file helpers of the Python file module that
+// the standard library lacks: atomic writes and a lock file shared across
+// processes, so concurrent CLI invocations do not corrupt state files,
+// and a debounced directory watcher:
+//
+//	lock, err := filex.Lock("state.json.lock")
+//	if err != nil {
+//		return err
+//	}
+//	defer lock.Unlock()
+//	err = filex.WriteAtomic("state.json", data, 0o600)
+//
+//	changes, err := filex.Watch(ctx, []string{"conf.d"}, filex.WithInclude("*.yaml"))
+//	for batch := range changes {
+//		reload(batch)
+//	}
+//
+// Lock files are compatible with the Python FileLock, so Go and Python
+// tools can guard the same file.
+package filex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteAtomic writes data to path so readers see either the old content
+// or all of the new, never part of it, even if the process or machine
+// fails midway: the data is written to a temporary file in the same
+// directory, synced to disk, and renamed over path. The file gets perm,
+// or when perm is zero the permissions of the file it replaces, or 0o644
+// for a new one.
+func WriteAtomic(path string, data []byte, perm os.FileMode) error {
+	if perm == 0 {
+		perm = 0o644
+		if fi, err := os.Stat(path); err == nil {
+			perm = fi.Mode().Perm()
+		}
+	}
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("filex: %w", err)
+	}
+	tmp := f.Name()
+	err = f.Chmod(perm)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("filex: write %s: %w", path, err)
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir makes a rename in dir durable. Not every platform can sync a
+// directory, so failures are ignored: the rename is still atomic.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package filex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Lock defaults, as in the Python FileLock.
+const (
+	DefaultLockTimeout  = 10 * time.Second
+	DefaultLockInterval = 100 * time.Millisecond
+)
+
+var (
+	// ErrLocked is matched by errors.Is when another owner holds the lock.
+	ErrLocked = errors.New("filex: file is locked")
+	// ErrNotOwner is returned by Unlock when the lock file was taken over,
+	// typically after it was judged stale.
+	ErrNotOwner = errors.New("filex: lock is held by another owner")
+)
+
+// LockOption configures Lock.
+type LockOption func(*lockOptions)
+
+type lockOptions struct {
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// WithLockTimeout sets how long Lock waits for the lock.
+func WithLockTimeout(d time.Duration) LockOption {
+	return func(o *lockOptions) { o.timeout = d }
+}
+
+// WithLockInterval sets how often Lock retries while the lock is held.
+func WithLockInterval(d time.Duration) LockOption {
+	return func(o *lockOptions) { o.interval = d }
+}
+
+// lockInfo is the content of a lock file, as the Python FileLock writes
+// it. The Python side also accepts a bare PID.
+type lockInfo struct {
+	PID      int     `json:"pid"`
+	Hostname string  `json:"hostname"`
+	Created  float64 `json:"created"`
+}
+
+// FileLock is a held lock, released with Unlock.
+type FileLock struct {
+	path string
+	id   string // content written, to check ownership on Unlock
+}
+
+// Lock acquires the lock file at path, waiting up to the timeout set with
+// WithLockTimeout. It fails with an error matching ErrLocked if the lock
+// is still held then.
+func Lock(path string, opts ...LockOption) (*FileLock, error) {
+	return LockContext(context.Background(), path, opts...)
+}
+
+// LockContext acquires the lock file at path as Lock does, giving up
+// early when ctx is done.
+func LockContext(ctx context.Context, path string, opts ...LockOption) (*FileLock, error) {
+	o := lockOptions{timeout: DefaultLockTimeout, interval: DefaultLockInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	for {
+		l, err := TryLock(path)
+		if !errors.Is(err, ErrLocked) {
+			return l, err
+		}
+		t := time.NewTimer(o.interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%w: %s: not acquired within %s", ErrLocked, path, o.timeout)
+			}
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// TryLock acquires the lock file at path without waiting. It fails with
+// an error matching ErrLocked if another owner holds it.
+//
+// A lock left behind by a process that died on this host is stale and
+// is removed. Locks held by other hosts, on a shared file system, are
+// never judged stale.
+func TryLock(path string) (*FileLock, error) {
+	host, _ := os.Hostname()
+	info, _ := json.Marshal(lockInfo{
+		PID:      os.Getpid(),
+		Hostname: host,
+		Created:  float64(time.Now().UnixMicro()) / 1e6,
+	})
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, err = f.Write(info)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("filex: lock %s: %w", path, err)
+			}
+			return &FileLock{path: path, id: string(info)}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("filex: lock %s: %w", path, err)
+		}
+		if attempt > 0 || !removeStale(path, host) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+	}
+}
+
+// removeStale removes the lock file at path if its owner is a process on
+// host that no longer runs, and reports whether it did.
+func removeStale(path, host string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Is(err, os.ErrNotExist) // released meanwhile; retry
+	}
+	content := strings.TrimSpace(string(data))
+	var info lockInfo
+	if err := json.Unmarshal(data, &info); err != nil || info.PID == 0 {
+		pid, err := strconv.Atoi(content)
+		if err != nil {
+			// A lock being written is briefly empty; it is not stale.
+			return false
+		}
+		info = lockInfo{PID: pid}
+	}
+	if info.Hostname != "" && info.Hostname != host || processAlive(info.PID) {
+		return false
+	}
+	// Only remove the file that was judged stale, not one a competing
+	// process created since.
+	if current, err := os.ReadFile(path); err != nil || string(current) != string(data) {
+		return err != nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	log.Default().Warn("file_lock_stale_removed", "path", path, "pid", info.PID)
+	return true
+}
+
+// Path returns the path of the lock file.
+func (l *FileLock) Path() string { return l.path }
+
+// Unlock releases the lock by removing the lock file. It fails with
+// ErrNotOwner, leaving the file, if another owner has taken it over.
+func (l *FileLock) Unlock() error {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("filex: unlock %s: %w", l.path, err)
+	}
+	if string(data) != l.id {
+		return fmt.Errorf("%w: %s", ErrNotOwner, l.path)
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("filex: unlock %s: %w", l.path, err)
+	}
+	return nil
+}
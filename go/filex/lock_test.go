@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package filex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestLockExcludes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+	l, err := Lock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info lockInfo
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &info); err != nil || info.PID != os.Getpid() {
+		t.Errorf("lock file = %s, want the Python JSON form with our pid", data)
+	}
+
+	if _, err := TryLock(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("TryLock while held = %v, want ErrLocked", err)
+	}
+	start := time.Now()
+	_, err = Lock(path, WithLockTimeout(50*time.Millisecond), WithLockInterval(10*time.Millisecond))
+	if !errors.Is(err, ErrLocked) || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("Lock while held = %v after %s, want ErrLocked after the timeout", err, time.Since(start))
+	}
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		l.Unlock()
+	}()
+	l2, err := Lock(path, WithLockInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Lock after Unlock = %v", err)
+	}
+	if err := l2.Unlock(); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Unlock left the lock file")
+	}
+}
+
+func TestLockContextCanceled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+	l, _ := Lock(path)
+	defer l.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LockContext(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Errorf("LockContext = %v, want context.Canceled", err)
+	}
+}
+
+func TestStaleLockRemoved(t *testing.T) {
+	rec := logtest.Capture(t)
+	path := filepath.Join(t.TempDir(), "state.lock")
+	// A bare PID, as older Python locks hold, of a process that has exited.
+	os.WriteFile(path, []byte(strconv.Itoa(deadPID(t))), 0o644)
+
+	l, err := TryLock(path)
+	if err != nil {
+		t.Fatalf("TryLock over a stale lock = %v", err)
+	}
+	defer l.Unlock()
+	if rec.WithEvent("file_lock_stale_removed").Count() != 1 {
+		t.Error("stale lock removal not logged")
+	}
+}
+
+func TestForeignHostLockNotStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+	data, _ := json.Marshal(lockInfo{PID: deadPID(t), Hostname: "elsewhere.invalid"})
+	os.WriteFile(path, data, 0o644)
+	if _, err := TryLock(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("TryLock over another host's lock = %v, want ErrLocked", err)
+	}
+}
+
+func TestUnlockTakenOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+	l, _ := Lock(path)
+	os.WriteFile(path, []byte(`{"pid": 1}`), 0o644)
+	if err := l.Unlock(); !errors.Is(err, ErrNotOwner) {
+		t.Errorf("Unlock = %v, want ErrNotOwner", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("Unlock removed a lock it does not own")
+	}
+}
+
+// deadPID returns the pid of a process that has exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	if !processAlive(os.Getpid()) {
+		t.Skip("processes cannot be probed on this platform")
+	}
+	p, err := os.StartProcess(os.Args[0], []string{os.Args[0], "-test.run=^$"}, &os.ProcAttr{})
+	if err != nil {
+		t.Skip(err)
+	}
+	p.Wait()
+	return p.Pid
+}
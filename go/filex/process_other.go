@@ -0,0 +1,12 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !unix
+
+package filex
+
+// processAlive cannot probe processes here, so every lock owner is
+// assumed alive and stale locks wait for their timeout.
+func processAlive(pid int) bool {
+	return true
+}
@@ -0,0 +1,21 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unix
+
+package filex
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process with pid runs. A process of
+// another user answers EPERM, and is alive.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,229 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package filex
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// DefaultDebounce is how long Watch waits for changes to settle.
+const DefaultDebounce = 100 * time.Millisecond
+
+// Op is a set of changes to a path.
+type Op uint32
+
+// Changes.
+const (
+	Create Op = 1 << iota
+	Write
+	Remove
+	Rename
+	Chmod
+)
+
+// Has reports whether o includes every change in h.
+func (o Op) Has(h Op) bool { return o&h == h }
+
+// String lists the changes, such as "CREATE|WRITE".
+func (o Op) String() string {
+	var names []string
+	for i, name := range []string{"CREATE", "WRITE", "REMOVE", "RENAME", "CHMOD"} {
+		if o.Has(1 << i) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Event reports the changes to one path during a burst.
+type Event struct {
+	Path string
+	Op   Op
+}
+
+// WatchOption configures Watch.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	debounce  time.Duration
+	include   []string
+	exclude   []string
+	recursive bool
+}
+
+// WithDebounce sets how long changes must pause before they are
+// delivered, so an editor saving a file or a tool writing many files
+// produces one batch.
+func WithDebounce(d time.Duration) WatchOption {
+	return func(o *watchOptions) { o.debounce = d }
+}
+
+// WithInclude only reports paths whose file name matches one of the
+// filepath.Match patterns, such as "*.yaml".
+func WithInclude(patterns ...string) WatchOption {
+	return func(o *watchOptions) { o.include = append(o.include, patterns...) }
+}
+
+// WithExclude drops paths whose file name matches one of the
+// filepath.Match patterns, such as ".*" or "*.tmp".
+func WithExclude(patterns ...string) WatchOption {
+	return func(o *watchOptions) { o.exclude = append(o.exclude, patterns...) }
+}
+
+// WithRecursive also watches the subdirectories of watched directories,
+// including those created later.
+func WithRecursive() WatchOption {
+	return func(o *watchOptions) { o.recursive = true }
+}
+
+// Watch watches paths, files or directories, until ctx is done and sends
+// the changes in batches once they have paused for the debounce period.
+// A batch holds one Event per changed path, sorted by path. The channel
+// is closed when ctx is done.
+//
+// A file is watched through its directory, so it may be missing when
+// Watch is called, and replacing it by rename, as WriteAtomic and most
+// editors do, is reported as a change to it. Watch fails if a path's
+// directory does not exist or the system cannot watch more paths.
+func Watch(ctx context.Context, paths []string, opts ...WatchOption) (<-chan []Event, error) {
+	o := watchOptions{debounce: DefaultDebounce}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for _, p := range append(slices.Clone(o.include), o.exclude...) {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("filex: watch pattern %q: %w", p, err)
+		}
+	}
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("filex: watch: %w", err)
+	}
+	w := &watcher{opts: o, fw: fw, files: make(map[string]bool), dirs: make(map[string]bool)}
+	for _, p := range paths {
+		if err := w.add(filepath.Clean(p)); err != nil {
+			fw.Close()
+			return nil, fmt.Errorf("filex: watch %s: %w", p, err)
+		}
+	}
+	out := make(chan []Event)
+	go w.run(ctx, out)
+	return out, nil
+}
+
+type watcher struct {
+	opts  watchOptions
+	fw    *fsnotify.Watcher
+	files map[string]bool // files watched through their directory
+	dirs  map[string]bool // directories whose every entry is watched
+}
+
+func (w *watcher) add(path string) error {
+	fi, err := os.Stat(path)
+	if err == nil && fi.IsDir() {
+		if !w.opts.recursive {
+			w.dirs[path] = true
+			return w.fw.Add(path)
+		}
+		return w.addTree(path)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	w.files[path] = true
+	return w.fw.Add(filepath.Dir(path))
+}
+
+func (w *watcher) addTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		w.dirs[path] = true
+		return w.fw.Add(path)
+	})
+}
+
+// wanted reports whether an event for path is reported.
+func (w *watcher) wanted(path string) bool {
+	if !w.files[path] && !w.dirs[filepath.Dir(path)] {
+		return false
+	}
+	name := filepath.Base(path)
+	for _, p := range w.opts.exclude {
+		if ok, _ := filepath.Match(p, name); ok {
+			return false
+		}
+	}
+	for _, p := range w.opts.include {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return len(w.opts.include) == 0
+}
+
+func (w *watcher) run(ctx context.Context, out chan<- []Event) {
+	defer close(out)
+	defer w.fw.Close()
+	pending := make(map[string]Op)
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err, ok := <-w.fw.Errors:
+			if !ok {
+				return
+			}
+			log.Default().WarnCtx(ctx, "file_watch_failed", log.Err(err))
+		case e, ok := <-w.fw.Events:
+			if !ok {
+				return
+			}
+			path := filepath.Clean(e.Name)
+			if w.opts.recursive && e.Has(fsnotify.Create) && w.dirs[filepath.Dir(path)] {
+				if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+					if err := w.addTree(path); err != nil {
+						log.Default().WarnCtx(ctx, "file_watch_failed", "path", path, log.Err(err))
+					}
+				}
+			}
+			if !w.wanted(path) {
+				continue
+			}
+			op := Op(e.Op) & (Create | Write | Remove | Rename | Chmod)
+			if op == 0 {
+				continue
+			}
+			pending[path] |= op
+			timer.Reset(w.opts.debounce)
+		case <-timer.C:
+			batch := make([]Event, 0, len(pending))
+			for path, op := range pending {
+				batch = append(batch, Event{Path: path, Op: op})
+			}
+			clear(pending)
+			slices.SortFunc(batch, func(a, b Event) int { return cmp.Compare(a.Path, b.Path) })
+			select {
+			case out <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package filex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func watch(t *testing.T, paths []string, opts ...WatchOption) <-chan []Event {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ch, err := Watch(ctx, paths, append([]WatchOption{WithDebounce(50 * time.Millisecond)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch
+}
+
+func next(t *testing.T, ch <-chan []Event) []Event {
+	t.Helper()
+	select {
+	case batch := <-ch:
+		return batch
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+		return nil
+	}
+}
+
+func paths(batch []Event) []string {
+	var ps []string
+	for _, e := range batch {
+		ps = append(ps, filepath.Base(e.Path))
+	}
+	return ps
+}
+
+func TestWatchDebouncesAndFilters(t *testing.T) {
+	dir := t.TempDir()
+	ch := watch(t, []string{dir}, WithInclude("*.yaml"), WithExclude("skip*"))
+
+	for i := range 5 {
+		os.WriteFile(filepath.Join(dir, "a.yaml"), []byte{byte(i)}, 0o644)
+	}
+	os.WriteFile(filepath.Join(dir, "b.yaml"), nil, 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o644)
+	os.WriteFile(filepath.Join(dir, "skip.yaml"), nil, 0o644)
+
+	batch := next(t, ch)
+	if got := paths(batch); !reflect.DeepEqual(got, []string{"a.yaml", "b.yaml"}) {
+		t.Errorf("batch = %v, want one event per included file", batch)
+	}
+	if !batch[0].Op.Has(Create | Write) {
+		t.Errorf("a.yaml op = %v, want CREATE|WRITE", batch[0].Op)
+	}
+}
+
+func TestWatchFileReplacedAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.json")
+	ch := watch(t, []string{path})
+
+	os.WriteFile(filepath.Join(dir, "other.json"), nil, 0o644)
+	if err := WriteAtomic(path, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(next(t, ch)); !reflect.DeepEqual(got, []string{"app.json"}) {
+		t.Errorf("batch paths = %v, want only the watched file", got)
+	}
+}
+
+func TestWatchRecursive(t *testing.T) {
+	dir := t.TempDir()
+	ch := watch(t, []string{dir}, WithRecursive(), WithInclude("*.txt"))
+
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0o755)
+	time.Sleep(100 * time.Millisecond) // let the new directory be added
+	os.WriteFile(filepath.Join(sub, "deep.txt"), nil, 0o644)
+	for {
+		batch := next(t, ch)
+		if reflect.DeepEqual(paths(batch), []string{"deep.txt"}) {
+			return
+		}
+	}
+}
+
+func TestWatchClosesWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := Watch(ctx, []string{t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	for range ch {
+	}
+	if _, err := Watch(context.Background(), []string{"/does/not/exist/x"}); err == nil {
+		t.Error("Watch of a missing directory succeeded")
+	}
+}
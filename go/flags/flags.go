@@ -0,0 +1,569 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package flags decides rollouts with feature flags instead of ad-hoc
+// conditions. Flags are declared once with a default and evaluated for
+// the target in the context, the user and environment of the request:
+//
+//	var newCheckout = flags.Bool("new_checkout", false)
+//	var checkoutRollout = flags.Percentage("checkout_v2_rollout")
+//
+//	ctx = flags.WithTarget(ctx, flags.Target{UserID: user.ID})
+//	if newCheckout.Enabled(ctx) || checkoutRollout.Enabled(ctx) {
+//		...
+//	}
+//
+// A Set holds the flag values, each a Spec with overrides per environment
+// and per user, loaded from a Provider: the configuration, or an HTTP
+// endpoint. Registered with the container, a Set loads its provider from
+// OnStart and keeps it current until OnStop:
+//
+//	flags.SetDefault(flags.New(flags.WithProvider(flags.FromConfig(cfg, "flags"))))
+//
+// A flag without a value, or with one of the wrong type, evaluates to its
+// default. Tests force values with the flagstest package.
+package flags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/env"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Spec is the value of a flag: a bool, a string, or for a percentage flag
+// a number from 0 to 100, with overrides for environments and users. The
+// value for a target is its user's, else its environment's, else Value.
+type Spec struct {
+	Value        any            `json:"value,omitempty"`
+	Environments map[string]any `json:"environments,omitempty"`
+	Users        map[string]any `json:"users,omitempty"`
+}
+
+// Target is what flags are evaluated for.
+type Target struct {
+	UserID string
+	// Environment defaults to env.Current().
+	Environment string
+}
+
+type targetKey struct{}
+
+// WithTarget returns a copy of ctx evaluating flags for t.
+func WithTarget(ctx context.Context, t Target) context.Context {
+	return context.WithValue(ctx, targetKey{}, t)
+}
+
+// TargetFrom returns the target of ctx, with the current environment if
+// it has none.
+func TargetFrom(ctx context.Context) Target {
+	t, _ := ctx.Value(targetKey{}).(Target)
+	if t.Environment == "" {
+		t.Environment = env.Current().String()
+	}
+	return t
+}
+
+// Provider loads flags.
+type Provider interface {
+	Flags(ctx context.Context) (map[string]Spec, error)
+}
+
+// Watcher is a provider that reports its changes, which a Set then loads
+// instead of polling.
+type Watcher interface {
+	Provider
+	// Watch calls fn after the flags may have changed, until cancelled.
+	Watch(fn func()) (cancel func())
+}
+
+// DefaultPollInterval is how often a Set polls a provider that is not a
+// Watcher.
+const DefaultPollInterval = 30 * time.Second
+
+// Option configures New.
+type Option func(*Set)
+
+// WithProvider sets the provider of the flags.
+func WithProvider(p Provider) Option {
+	return func(s *Set) { s.provider = p }
+}
+
+// WithPollInterval sets how often the provider is polled. The default is
+// DefaultPollInterval.
+func WithPollInterval(d time.Duration) Option {
+	return func(s *Set) { s.interval = d }
+}
+
+// WithClock sets the clock of polling. The default is clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(s *Set) { s.clock = c }
+}
+
+// WithLogger sets the logger; the default is log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(s *Set) { s.logger = l }
+}
+
+// Set holds flag values. It is safe for concurrent use.
+type Set struct {
+	provider Provider
+	interval time.Duration
+	clock    clock.Clock
+	logger   *log.Logger
+
+	mu          sync.RWMutex
+	specs       map[string]Spec
+	forced      map[string]any
+	kinds       map[string]kind
+	subscribers map[int]func(names []string)
+	nextSub     int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a set without values; Replace or a provider loaded by
+// Refresh or OnStart sets them.
+func New(opts ...Option) *Set {
+	s := &Set{
+		interval: DefaultPollInterval,
+		clock:    clock.Real(),
+		specs:    map[string]Spec{},
+		forced:   map[string]any{},
+		kinds:    map[string]kind{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+var defaultSet atomic.Pointer[Set]
+
+func init() { defaultSet.Store(New()) }
+
+// Default returns the set of the package-level flags.
+func Default() *Set { return defaultSet.Load() }
+
+// SetDefault makes s the set of the package-level flags.
+func SetDefault(s *Set) { defaultSet.Store(s) }
+
+func (s *Set) log() *log.Logger {
+	if s.logger != nil {
+		return s.logger
+	}
+	return log.Default()
+}
+
+// Replace sets the flags to specs, and notifies the subscribers of those
+// that changed.
+func (s *Set) Replace(specs map[string]Spec) {
+	s.mu.Lock()
+	var changed []string
+	for name := range s.specs {
+		if _, ok := specs[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	for name, spec := range specs {
+		if old, ok := s.specs[name]; !ok || !reflect.DeepEqual(old, spec) {
+			changed = append(changed, name)
+		}
+	}
+	s.specs = maps.Clone(specs)
+	for _, name := range changed {
+		s.check(name)
+	}
+	s.mu.Unlock()
+	s.notify(changed)
+}
+
+// Force makes name evaluate to v for every target, whatever its spec,
+// until the returned function restores it.
+func (s *Set) Force(name string, v any) (restore func()) {
+	s.mu.Lock()
+	prev, had := s.forced[name]
+	s.forced[name] = v
+	s.mu.Unlock()
+	s.notify([]string{name})
+	return func() {
+		s.mu.Lock()
+		if had {
+			s.forced[name] = prev
+		} else {
+			delete(s.forced, name)
+		}
+		s.mu.Unlock()
+		s.notify([]string{name})
+	}
+}
+
+// Spec returns the spec of name.
+func (s *Set) Spec(name string) (Spec, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	spec, ok := s.specs[name]
+	return spec, ok
+}
+
+// Names returns the names of the flags with a spec, sorted.
+func (s *Set) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.specs))
+}
+
+// OnChange registers fn to be called with the names of the flags whose
+// value changed, on the goroutine that changed them. The returned
+// function unsubscribes fn.
+func (s *Set) OnChange(fn func(names []string)) (cancel func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers == nil {
+		s.subscribers = map[int]func([]string){}
+	}
+	id := s.nextSub
+	s.nextSub++
+	s.subscribers[id] = fn
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+func (s *Set) notify(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	slices.Sort(names)
+	s.mu.RLock()
+	ids := slices.Sorted(maps.Keys(s.subscribers))
+	subs := make([]func([]string), len(ids))
+	for i, id := range ids {
+		subs[i] = s.subscribers[id]
+	}
+	s.mu.RUnlock()
+	for _, fn := range subs {
+		fn(names)
+	}
+}
+
+// value returns the raw value of name for t. s.mu is not held.
+func (s *Set) value(name string, t Target) (any, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if v, ok := s.forced[name]; ok {
+		return v, true
+	}
+	spec, ok := s.specs[name]
+	if !ok {
+		return nil, false
+	}
+	if v, ok := spec.Users[t.UserID]; ok && t.UserID != "" {
+		return v, true
+	}
+	if v, ok := spec.Environments[t.Environment]; ok {
+		return v, true
+	}
+	return spec.Value, spec.Value != nil
+}
+
+// Refresh loads the flags from the provider. The flags are left as they
+// were if it fails.
+func (s *Set) Refresh(ctx context.Context) error {
+	if s.provider == nil {
+		return nil
+	}
+	specs, err := s.provider.Flags(ctx)
+	if err != nil {
+		return fmt.Errorf("flags: %w", err)
+	}
+	s.Replace(specs)
+	return nil
+}
+
+// OnStart loads the flags and keeps them current until OnStop: on the
+// changes of a Watcher, or by polling. A provider failing at start is
+// logged and leaves the flags at their defaults, so a flag service down
+// does not keep the application from starting.
+func (s *Set) OnStart(ctx context.Context) error {
+	if s.provider == nil {
+		return nil
+	}
+	s.refresh(ctx)
+	s.stop, s.done = make(chan struct{}), make(chan struct{})
+	w, ok := s.provider.(Watcher)
+	if !ok {
+		go s.poll(context.WithoutCancel(ctx))
+		return nil
+	}
+	// Subscribe before returning so no change after OnStart is missed.
+	changed := make(chan struct{}, 1)
+	cancel := w.Watch(func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	go s.watch(context.WithoutCancel(ctx), changed, cancel)
+	return nil
+}
+
+// OnStop stops keeping the flags current.
+func (s *Set) OnStop(context.Context) error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+	return nil
+}
+
+func (s *Set) watch(ctx context.Context, changed <-chan struct{}, cancel func()) {
+	defer close(s.done)
+	defer cancel()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-changed:
+			s.refresh(ctx)
+		}
+	}
+}
+
+func (s *Set) poll(ctx context.Context) {
+	defer close(s.done)
+	t := s.clock.NewTicker(s.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C():
+			s.refresh(ctx)
+		}
+	}
+}
+
+// refresh is Refresh for the background loops, which log failures.
+func (s *Set) refresh(ctx context.Context) {
+	if err := s.Refresh(ctx); err != nil {
+		s.log().WarnCtx(ctx, "flags_refresh_failed", log.Err(err))
+	}
+}
+
+// kind is the type of a declared flag, for checking its spec.
+type kind int
+
+const (
+	kindBool kind = iota + 1
+	kindString
+	kindPercentage
+)
+
+// declare records the kind of name and checks its spec.
+func (s *Set) declare(name string, k kind) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kinds[name] = k
+	s.check(name)
+}
+
+// check logs the values of name's spec that do not fit its kind. s.mu is
+// held.
+func (s *Set) check(name string) {
+	k, ok := s.kinds[name]
+	spec, has := s.specs[name]
+	if !ok || !has {
+		return
+	}
+	values := slices.Collect(maps.Values(spec.Environments))
+	values = append(values, slices.Collect(maps.Values(spec.Users))...)
+	if spec.Value != nil {
+		values = append(values, spec.Value)
+	}
+	for _, v := range values {
+		if _, err := k.convert(v); err != nil {
+			s.log().Warn("flag_invalid", "flag", name, log.Err(err))
+			return
+		}
+	}
+}
+
+func (k kind) convert(v any) (any, error) {
+	switch k {
+	case kindBool:
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(v)
+		}
+	case kindString:
+		switch v.(type) {
+		case string, bool, int, int64, float64:
+			return fmt.Sprint(v), nil
+		}
+	case kindPercentage:
+		var p float64
+		switch v := v.(type) {
+		case bool:
+			if v {
+				p = 100
+			}
+		case int:
+			p = float64(v)
+		case int64:
+			p = float64(v)
+		case float64:
+			p = v
+		case string:
+			var err error
+			if p, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%T is not a percentage", v)
+		}
+		if p < 0 || p > 100 {
+			return nil, fmt.Errorf("percentage %v out of range", p)
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("%T does not fit the flag", v)
+}
+
+// eval returns the value of a flag of kind k for the target of ctx.
+func eval[T any](s *Set, ctx context.Context, name string, k kind) (T, bool) {
+	if s == nil {
+		s = Default()
+	}
+	var zero T
+	v, ok := s.value(name, TargetFrom(ctx))
+	if !ok {
+		return zero, false
+	}
+	c, err := k.convert(v)
+	if err != nil {
+		return zero, false
+	}
+	return c.(T), true
+}
+
+// BoolFlag is an on or off flag.
+type BoolFlag struct {
+	set  *Set // nil for Default()
+	name string
+	def  bool
+}
+
+// Bool declares a bool flag of the default set.
+func Bool(name string, def bool) *BoolFlag {
+	Default().declare(name, kindBool)
+	return &BoolFlag{name: name, def: def}
+}
+
+// Bool declares a bool flag of s.
+func (s *Set) Bool(name string, def bool) *BoolFlag {
+	s.declare(name, kindBool)
+	return &BoolFlag{set: s, name: name, def: def}
+}
+
+// Name returns the name of the flag.
+func (f *BoolFlag) Name() string { return f.name }
+
+// Enabled reports whether the flag is on for the target of ctx.
+func (f *BoolFlag) Enabled(ctx context.Context) bool {
+	if v, ok := eval[bool](f.set, ctx, f.name, kindBool); ok {
+		return v
+	}
+	return f.def
+}
+
+// StringFlag chooses between variants by name.
+type StringFlag struct {
+	set  *Set
+	name string
+	def  string
+}
+
+// String declares a string flag of the default set.
+func String(name, def string) *StringFlag {
+	Default().declare(name, kindString)
+	return &StringFlag{name: name, def: def}
+}
+
+// String declares a string flag of s.
+func (s *Set) String(name, def string) *StringFlag {
+	s.declare(name, kindString)
+	return &StringFlag{set: s, name: name, def: def}
+}
+
+// Name returns the name of the flag.
+func (f *StringFlag) Name() string { return f.name }
+
+// Value returns the variant for the target of ctx.
+func (f *StringFlag) Value(ctx context.Context) string {
+	if v, ok := eval[string](f.set, ctx, f.name, kindString); ok {
+		return v
+	}
+	return f.def
+}
+
+// PercentageFlag is on for a share of the users, given as its value from
+// 0 to 100, or true and false for all or none. A user keeps its answer
+// as the share grows, and targets without a user are in only at 100.
+type PercentageFlag struct {
+	set  *Set
+	name string
+}
+
+// Percentage declares a percentage flag of the default set. Without a
+// value it is off.
+func Percentage(name string) *PercentageFlag {
+	Default().declare(name, kindPercentage)
+	return &PercentageFlag{name: name}
+}
+
+// Percentage declares a percentage flag of s.
+func (s *Set) Percentage(name string) *PercentageFlag {
+	s.declare(name, kindPercentage)
+	return &PercentageFlag{set: s, name: name}
+}
+
+// Name returns the name of the flag.
+func (f *PercentageFlag) Name() string { return f.name }
+
+// Enabled reports whether the user of ctx is within the flag's share.
+func (f *PercentageFlag) Enabled(ctx context.Context) bool {
+	p, ok := eval[float64](f.set, ctx, f.name, kindPercentage)
+	if !ok || p <= 0 {
+		return false
+	}
+	if p >= 100 {
+		return true
+	}
+	t := TargetFrom(ctx)
+	return t.UserID != "" && Bucket(f.name, t.UserID) < p
+}
+
+// Bucket returns where user falls from 0 to 100 in the rollout of flag,
+// stable for the pair and independent between flags.
+func Bucket(flag, user string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(flag + "\x00" + user))
+	return float64(h.Sum32()%10000) / 100
+}
@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package flags
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestTargeting(t *testing.T) {
+	s := New()
+	checkout := s.Bool("new_checkout", false)
+	theme := s.String("theme", "classic")
+	s.Replace(map[string]Spec{
+		"new_checkout": {
+			Value:        false,
+			Environments: map[string]any{"staging": true},
+			Users:        map[string]any{"42": "true"},
+		},
+		"theme": {Environments: map[string]any{"production": "modern"}},
+	})
+	for _, tc := range []struct {
+		target   Target
+		checkout bool
+		theme    string
+	}{
+		{Target{Environment: "production"}, false, "modern"},
+		{Target{Environment: "staging"}, true, "classic"},
+		{Target{Environment: "production", UserID: "42"}, true, "modern"},
+		{Target{Environment: "development", UserID: "7"}, false, "classic"},
+	} {
+		ctx := WithTarget(context.Background(), tc.target)
+		if got := checkout.Enabled(ctx); got != tc.checkout {
+			t.Errorf("%+v: checkout = %v", tc.target, got)
+		}
+		if got := theme.Value(ctx); got != tc.theme {
+			t.Errorf("%+v: theme = %q", tc.target, got)
+		}
+	}
+	if TargetFrom(context.Background()).Environment != "test" {
+		t.Errorf("environment not defaulted: %+v", TargetFrom(context.Background()))
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	rec := logtest.Capture(t)
+	s := New()
+	s.Replace(map[string]Spec{"on": {Value: "maybe"}})
+	on := s.Bool("on", true)
+	missing := s.Bool("missing", true)
+	if !on.Enabled(context.Background()) || !missing.Enabled(context.Background()) {
+		t.Error("invalid or missing flag not defaulted")
+	}
+	if n := rec.WithEvent("flag_invalid").WithField("flag", "on").Count(); n != 1 {
+		t.Errorf("logged %d invalid flags", n)
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	s := New()
+	rollout := s.Percentage("rollout")
+	in := func(p any) int {
+		s.Replace(map[string]Spec{"rollout": {Value: p}})
+		n := 0
+		for i := range 1000 {
+			if rollout.Enabled(WithTarget(context.Background(), Target{UserID: fmt.Sprint(i)})) {
+				n++
+			}
+		}
+		return n
+	}
+	if n := in(25.0); n < 200 || n > 300 {
+		t.Errorf("25%% enabled %d of 1000", n)
+	}
+	if in(0) != 0 || in(100) != 1000 || in(true) != 1000 || in(false) != 0 || in("50") == 0 {
+		t.Error("bounds not honored")
+	}
+	// Users enabled at a share stay enabled as it grows.
+	s.Replace(map[string]Spec{"rollout": {Value: 10}})
+	var enabled []string
+	for i := range 100 {
+		if id := fmt.Sprint(i); rollout.Enabled(WithTarget(context.Background(), Target{UserID: id})) {
+			enabled = append(enabled, id)
+		}
+	}
+	s.Replace(map[string]Spec{"rollout": {Value: 50}})
+	for _, id := range enabled {
+		if !rollout.Enabled(WithTarget(context.Background(), Target{UserID: id})) {
+			t.Errorf("user %s dropped as the rollout grew", id)
+		}
+	}
+	s.Replace(map[string]Spec{"rollout": {Value: 99.9}})
+	if rollout.Enabled(context.Background()) {
+		t.Error("target without a user enabled below 100%")
+	}
+}
+
+func TestOnChangeAndForce(t *testing.T) {
+	s := New()
+	beta := s.Bool("beta", false)
+	var changes [][]string
+	cancel := s.OnChange(func(names []string) { changes = append(changes, names) })
+	s.Replace(map[string]Spec{"beta": {Value: true}, "x": {Value: 1}})
+	s.Replace(map[string]Spec{"beta": {Value: true}})
+	restore := s.Force("beta", false)
+	if beta.Enabled(context.Background()) {
+		t.Error("forced value ignored")
+	}
+	restore()
+	if !beta.Enabled(context.Background()) {
+		t.Error("forced value not restored")
+	}
+	cancel()
+	s.Replace(nil)
+	want := [][]string{{"beta", "x"}, {"x"}, {"beta"}, {"beta"}}
+	if !slices.EqualFunc(changes, want, slices.Equal) {
+		t.Errorf("changes = %v", changes)
+	}
+	if names := s.Names(); len(names) != 0 {
+		t.Errorf("names = %v", names)
+	}
+}
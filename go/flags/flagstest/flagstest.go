@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package flagstest forces feature flags in tests:
+//
+//	flagstest.Force(t, nil, "new_checkout", true)
+//	resp := checkout(ctx) // takes the new path
+package flagstest
+
+import (
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/flags"
+)
+
+// Force makes name evaluate to v in s, or in flags.Default() when s is
+// nil, for every target until the test ends. Tests forcing flags of the
+// default set must not run in parallel with others evaluating them.
+func Force(t testing.TB, s *flags.Set, name string, v any) {
+	t.Helper()
+	if s == nil {
+		s = flags.Default()
+	}
+	t.Cleanup(s.Force(name, v))
+}
+
+// Use makes s the default set until the test ends, so the package-level
+// flags evaluate in it.
+func Use(t testing.TB, s *flags.Set) {
+	t.Helper()
+	prev := flags.Default()
+	flags.SetDefault(s)
+	t.Cleanup(func() { flags.SetDefault(prev) })
+}
@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package flagstest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/flags"
+)
+
+var beta = flags.Bool("flagstest_beta", false)
+
+func TestForce(t *testing.T) {
+	t.Run("forced", func(t *testing.T) {
+		Force(t, nil, "flagstest_beta", true)
+		if !beta.Enabled(context.Background()) {
+			t.Error("forced flag off")
+		}
+	})
+	if beta.Enabled(context.Background()) {
+		t.Error("flag still forced after the test")
+	}
+}
+
+func TestUse(t *testing.T) {
+	s := flags.New()
+	s.Replace(map[string]flags.Spec{"flagstest_beta": {Value: true}})
+	t.Run("used", func(t *testing.T) {
+		Use(t, s)
+		if !beta.Enabled(context.Background()) {
+			t.Error("package-level flag not evaluated in the set")
+		}
+	})
+	if flags.Default() == s {
+		t.Error("default set not restored")
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package flags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/config"
+	"github.com/provide-io/provide-foundation/go/httpx"
+)
+
+// UnmarshalJSON decodes a spec object, or a bare value as the spec's
+// Value.
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	if t := bytes.TrimSpace(data); len(t) > 0 && t[0] != '{' {
+		*s = Spec{}
+		return json.Unmarshal(t, &s.Value)
+	}
+	type spec Spec // without the method
+	return json.Unmarshal(data, (*spec)(s))
+}
+
+// FromConfig returns a provider of the flags under prefix in cfg, which
+// a Set reloads when cfg changes. Under the prefix, each flag is a value
+// or a section with the fields of Spec:
+//
+//	flags:
+//	  new_checkout: true
+//	  checkout_theme:
+//	    value: classic
+//	    environments:
+//	      staging: modern
+//	    users:
+//	      "42": modern
+//
+// With config.WithEnvPrefix("APP"), APP_FLAGS_NEW_CHECKOUT=false then
+// turns new_checkout off. Flag names cannot contain dots.
+func FromConfig(cfg *config.Config, prefix string) Watcher {
+	return configProvider{cfg: cfg, prefix: prefix + "."}
+}
+
+type configProvider struct {
+	cfg    *config.Config
+	prefix string
+}
+
+func (p configProvider) Flags(context.Context) (map[string]Spec, error) {
+	specs := map[string]Spec{}
+	for _, key := range p.cfg.Keys() {
+		rest, ok := strings.CutPrefix(key, p.prefix)
+		if !ok {
+			continue
+		}
+		v, _ := p.cfg.Get(key)
+		name, field, _ := strings.Cut(rest, ".")
+		spec := specs[name]
+		switch section, sub, _ := strings.Cut(field, "."); {
+		case field == "" || field == "value":
+			spec.Value = v
+		case section == "environments" && sub != "":
+			if spec.Environments == nil {
+				spec.Environments = map[string]any{}
+			}
+			spec.Environments[sub] = v
+		case section == "users" && sub != "":
+			if spec.Users == nil {
+				spec.Users = map[string]any{}
+			}
+			spec.Users[sub] = v
+		default:
+			continue
+		}
+		specs[name] = spec
+	}
+	return specs, nil
+}
+
+func (p configProvider) Watch(fn func()) (cancel func()) {
+	return p.cfg.OnChange(func(_, _ *config.Config) { fn() })
+}
+
+// HTTP returns a provider fetching the flags from path on c, as a JSON
+// object of specs by flag name, each a Spec object or a bare value:
+//
+//	{"new_checkout": true, "checkout_theme": {"value": "classic", "users": {"42": "modern"}}}
+func HTTP(c *httpx.Client, path string) Provider {
+	return httpProvider{c: c, path: path}
+}
+
+type httpProvider struct {
+	c    *httpx.Client
+	path string
+}
+
+func (p httpProvider) Flags(ctx context.Context) (map[string]Spec, error) {
+	return httpx.GetJSON[map[string]Spec](ctx, p.c, p.path)
+}
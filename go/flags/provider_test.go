@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package flags
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/config"
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/httpx/httpxtest"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestFromConfig(t *testing.T) {
+	cfg, err := config.Load(config.WithDefaults(map[string]any{
+		"flags.new_checkout":               true,
+		"flags.theme.value":                "classic",
+		"flags.theme.environments.staging": "modern",
+		"flags.theme.users.42":             "modern",
+		"flags.theme.unknown":              "ignored",
+		"other.setting":                    1,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(WithProvider(FromConfig(cfg, "flags")))
+	checkout := s.Bool("new_checkout", false)
+	if err := s.OnStart(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer s.OnStop(context.Background())
+	want := map[string]Spec{
+		"new_checkout": {Value: true},
+		"theme": {
+			Value:        "classic",
+			Environments: map[string]any{"staging": "modern"},
+			Users:        map[string]any{"42": "modern"},
+		},
+	}
+	for name, spec := range want {
+		if got, _ := s.Spec(name); !reflect.DeepEqual(got, spec) {
+			t.Errorf("%s = %+v, want %+v", name, got, spec)
+		}
+	}
+
+	changed := make(chan []string, 1)
+	s.OnChange(func(names []string) { changed <- names })
+	cfg.Set("flags.new_checkout", "false")
+	select {
+	case names := <-changed:
+		if !reflect.DeepEqual(names, []string{"new_checkout"}) {
+			t.Errorf("changed %v", names)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change not loaded")
+	}
+	if checkout.Enabled(context.Background()) {
+		t.Error("flag not turned off")
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	rec := logtest.Capture(t)
+	mock := httpxtest.NewMockTransport(t)
+	mock.On("GET", "/flags").Once().Reply(200, `{"beta": true, "theme": {"value": "classic", "users": {"7": "modern"}}}`)
+	mock.On("GET", "/flags").Once().Reply(503, "")
+	mock.On("GET", "/flags").Reply(200, `{"beta": false}`)
+	client, err := httpx.New("https://flags.example", httpx.WithTransport(mock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := clock.NewFake(time.Unix(1000, 0))
+	s := New(WithProvider(HTTP(client, "/flags")), WithPollInterval(time.Minute), WithClock(clk))
+	beta := s.Bool("beta", false)
+	theme := s.String("theme", "")
+	if err := s.OnStart(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer s.OnStop(context.Background())
+	ctx := WithTarget(context.Background(), Target{UserID: "7"})
+	if !beta.Enabled(ctx) || theme.Value(ctx) != "modern" {
+		t.Errorf("beta %v, theme %q", beta.Enabled(ctx), theme.Value(ctx))
+	}
+
+	changed := make(chan struct{}, 1)
+	s.OnChange(func([]string) { changed <- struct{}{} })
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute) // fails; the flags stay
+	clk.BlockUntil(1)
+	for rec.WithEvent("flags_refresh_failed").Count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if !beta.Enabled(ctx) {
+		t.Error("failed refresh dropped the flags")
+	}
+	clk.Advance(time.Minute)
+	<-changed
+	if beta.Enabled(ctx) || theme.Value(ctx) != "" {
+		t.Errorf("refreshed beta %v, theme %q", beta.Enabled(ctx), theme.Value(ctx))
+	}
+}
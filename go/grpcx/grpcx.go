@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package grpcx holds the gRPC interceptors of the Go foundation, the
+// counterparts of the httpx middleware: logging, metrics, tracing,
+// retries and panic recovery, for unary and streaming calls on both ends.
+//
+//	conn, err := grpc.NewClient(target, append(grpcx.ClientOptions(
+//		grpcx.ClientLogging(logger, httpx.WithLoggingConfig(cfg.Log)),
+//		grpcx.ClientMetrics(nil),
+//		grpcx.ClientTracing(),
+//		grpcx.ClientRetry(cfg.Retry),
+//	), grpc.WithTransportCredentials(creds))...)
+//
+//	srv := grpc.NewServer(grpcx.ServerOptions(
+//		grpcx.ServerRecovery(),
+//		grpcx.ServerTracing(),
+//		grpcx.ServerLogging(logger),
+//		grpcx.ServerMetrics(nil),
+//		grpcx.ServerErrors(),
+//	)...)
+//
+// The interceptors take the settings of the HTTP middleware, an
+// httpx.LoggingConfig, a retry.Policy, recovery options and a
+// metrics.Registry, so a service speaking both protocols configures them
+// once. Their logs, metrics and spans follow the HTTP ones with rpc.*
+// fields in place of http.* ones, and the trace context and request
+// metadata (see package ctxmeta) travel in gRPC metadata under the
+// header names HTTP uses.
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+func init() {
+	errorsx.Register(classify)
+}
+
+// ClientInterceptor intercepts the calls of a client. Either field may be
+// nil for interceptors that leave one kind of call alone.
+type ClientInterceptor struct {
+	Unary  grpc.UnaryClientInterceptor
+	Stream grpc.StreamClientInterceptor
+}
+
+// ServerInterceptor intercepts the calls a server handles. Either field
+// may be nil.
+type ServerInterceptor struct {
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// ClientOptions returns the dial options installing ics. The first is the
+// outermost, as with httpx middleware: it sees each call first and its
+// result last.
+func ClientOptions(ics ...ClientInterceptor) []grpc.DialOption {
+	var unary []grpc.UnaryClientInterceptor
+	var stream []grpc.StreamClientInterceptor
+	for _, ic := range ics {
+		if ic.Unary != nil {
+			unary = append(unary, ic.Unary)
+		}
+		if ic.Stream != nil {
+			stream = append(stream, ic.Stream)
+		}
+	}
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(unary...), grpc.WithChainStreamInterceptor(stream...)}
+}
+
+// ServerOptions returns the server options installing ics, the first
+// outermost.
+func ServerOptions(ics ...ServerInterceptor) []grpc.ServerOption {
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	for _, ic := range ics {
+		if ic.Unary != nil {
+			unary = append(unary, ic.Unary)
+		}
+		if ic.Stream != nil {
+			stream = append(stream, ic.Stream)
+		}
+	}
+	return []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...)}
+}
+
+// Code returns the gRPC code of err: the code of its status, or for
+// errors without one the code matching their errorsx class, so a
+// sql.ErrNoRows is NotFound and a context.DeadlineExceeded
+// DeadlineExceeded.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+	switch errorsx.Classify(err) {
+	case errorsx.Canceled:
+		return codes.Canceled
+	case errorsx.Timeout:
+		return codes.DeadlineExceeded
+	case errorsx.Unavailable:
+		return codes.Unavailable
+	case errorsx.RateLimited:
+		return codes.ResourceExhausted
+	case errorsx.NotFound:
+		return codes.NotFound
+	case errorsx.Conflict:
+		return codes.AlreadyExists
+	case errorsx.InvalidArgument:
+		return codes.InvalidArgument
+	case errorsx.PermissionDenied:
+		return codes.PermissionDenied
+	case errorsx.Unauthenticated:
+		return codes.Unauthenticated
+	case errorsx.Internal:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// classify gives errors carrying a gRPC status the errorsx class of their
+// code, so retry policies and circuit breakers treat them like HTTP
+// errors.
+func classify(err error) errorsx.Class {
+	var se interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &se) {
+		return errorsx.Unknown
+	}
+	switch se.GRPCStatus().Code() {
+	case codes.Canceled:
+		return errorsx.Canceled
+	case codes.DeadlineExceeded:
+		return errorsx.Timeout
+	case codes.Unavailable:
+		return errorsx.Unavailable
+	case codes.ResourceExhausted:
+		return errorsx.RateLimited
+	case codes.NotFound:
+		return errorsx.NotFound
+	case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+		return errorsx.Conflict
+	case codes.InvalidArgument, codes.OutOfRange:
+		return errorsx.InvalidArgument
+	case codes.PermissionDenied:
+		return errorsx.PermissionDenied
+	case codes.Unauthenticated:
+		return errorsx.Unauthenticated
+	case codes.Internal, codes.DataLoss, codes.Unimplemented:
+		return errorsx.Internal
+	}
+	return errorsx.Unknown
+}
+
+// splitMethod splits a full method name, "/pkg.Service/Method", into the
+// service and the method.
+func splitMethod(full string) (service, method string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(full, "/"), "/")
+	if !ok {
+		return "unknown", full
+	}
+	return service, method
+}
+
+// serverStream is a server stream with the context of an interceptor.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+// clientStream calls done once with the outcome of a client stream: when
+// receiving fails, with nil for io.EOF, when the response of a stream
+// without server streaming arrives, or when the stream is dropped without
+// being read to the end.
+type clientStream struct {
+	grpc.ClientStream
+	single bool // one response message
+	once   sync.Once
+	done   func(err error)
+	ended  chan struct{}
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || s.single {
+		s.finish(err)
+	}
+	return err
+}
+
+// finish reports the outcome once.
+func (s *clientStream) finish(err error) {
+	s.once.Do(func() {
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+		s.done(err)
+		close(s.ended)
+	})
+}
+
+// watchStream wraps a newly created client stream so done learns its
+// outcome, or calls done at once if it could not be created.
+func watchStream(ctx context.Context, desc *grpc.StreamDesc, cs grpc.ClientStream, err error, done func(error)) (grpc.ClientStream, error) {
+	if err != nil {
+		done(err)
+		return nil, err
+	}
+	s := &clientStream{ClientStream: cs, single: !desc.ServerStreams, done: done, ended: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.finish(ctx.Err())
+		case <-s.ended:
+		}
+	}()
+	return s, nil
+}
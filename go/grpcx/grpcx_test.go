@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+// healthServer is the service the tests call. Check answers by service
+// name: "panic" panics, "missing" fails with an error without a status,
+// "flaky" is Unavailable for its first two calls and "denied" is
+// PermissionDenied. Watch sends two statuses.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	flaky atomic.Int32
+	ctx   chan context.Context // receives the context of each call, if set
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if s.ctx != nil {
+		s.ctx <- ctx
+	}
+	switch req.Service {
+	case "panic":
+		panic("boom")
+	case "missing":
+		return nil, fmt.Errorf("finding service: %w", sql.ErrNoRows)
+	case "flaky":
+		if s.flaky.Add(1) <= 2 {
+			return nil, status.Error(codes.Unavailable, "warming up")
+		}
+	case "denied":
+		return nil, status.Error(codes.PermissionDenied, "not yours")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream grpc.ServerStreamingServer[healthpb.HealthCheckResponse]) error {
+	if s.ctx != nil {
+		s.ctx <- stream.Context()
+	}
+	if req.Service == "panic" {
+		panic("boom")
+	}
+	for _, st := range []healthpb.HealthCheckResponse_ServingStatus{healthpb.HealthCheckResponse_NOT_SERVING, healthpb.HealthCheckResponse_SERVING} {
+		if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serve starts srv on an in-memory listener with the server interceptors
+// and returns a client for it using the client interceptors.
+func serve(t *testing.T, srv *healthServer, client []ClientInterceptor, server ...ServerInterceptor) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(ServerOptions(server...)...)
+	healthpb.RegisterHealthServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	opts := append(ClientOptions(client...),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+// watch reads a Watch stream to its end.
+func watch(ctx context.Context, c healthpb.HealthClient, service string) (int, error) {
+	stream, err := c.Watch(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return 0, err
+	}
+	for n := 0; ; n++ {
+		if _, err := stream.Recv(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{nil, codes.OK},
+		{status.Error(codes.Aborted, "retry"), codes.Aborted},
+		{fmt.Errorf("wrapped: %w", status.Error(codes.NotFound, "gone")), codes.NotFound},
+		{sql.ErrNoRows, codes.NotFound},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{context.Canceled, codes.Canceled},
+		{errorsx.New(errorsx.RateLimited, "slow down"), codes.ResourceExhausted},
+		{errors.New("mystery"), codes.Unknown},
+	}
+	for _, tt := range tests {
+		if got := Code(tt.err); got != tt.want {
+			t.Errorf("Code(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want errorsx.Class
+	}{
+		{codes.Unavailable, errorsx.Unavailable},
+		{codes.DeadlineExceeded, errorsx.Timeout},
+		{codes.ResourceExhausted, errorsx.RateLimited},
+		{codes.AlreadyExists, errorsx.Conflict},
+		{codes.FailedPrecondition, errorsx.Conflict},
+		{codes.OutOfRange, errorsx.InvalidArgument},
+		{codes.Unauthenticated, errorsx.Unauthenticated},
+		{codes.DataLoss, errorsx.Internal},
+		{codes.Unknown, errorsx.Unknown},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("calling: %w", status.Error(tt.code, "x"))
+		if got := errorsx.Classify(err); got != tt.want {
+			t.Errorf("Classify(%v) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+	if !errorsx.IsRetryable(status.Error(codes.Unavailable, "down")) || errorsx.IsRetryable(status.Error(codes.NotFound, "gone")) {
+		t.Error("retryable codes not classified")
+	}
+}
+
+func TestWatchStreamEndsOnce(t *testing.T) {
+	var mu sync.Mutex
+	var outcomes []error
+	done := func(err error) {
+		mu.Lock()
+		outcomes = append(outcomes, err)
+		mu.Unlock()
+	}
+	got := func() []error {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]error(nil), outcomes...)
+	}
+	c := serve(t, &healthServer{}, []ClientInterceptor{{Stream: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		return watchStream(ctx, desc, cs, err, done)
+	}}})
+
+	if n, err := watch(context.Background(), c, ""); err != nil || n != 2 {
+		t.Fatalf("watch = %d, %v", n, err)
+	}
+	if o := got(); len(o) != 1 || o[0] != nil {
+		t.Fatalf("outcomes = %v", o)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := c.Watch(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for len(got()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if o := got(); len(o) != 2 || !errors.Is(o[1], context.Canceled) {
+		t.Errorf("abandoned stream outcomes = %v", o)
+	}
+}
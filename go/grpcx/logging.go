@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// ClientLogging logs every call through logger, as httpx.Logging logs
+// requests: grpc_request_started at DEBUG, then grpc_request_completed at
+// INFO, or grpc_request_failed at ERROR when the call fails with an error
+// that is not the caller's fault (see errorsx.Class.CallerFault). Records
+// carry rpc.service, rpc.method and rpc.grpc.status. The options are
+// those of httpx.Logging: headers log the outgoing metadata and bodies
+// the messages of unary calls, as redacted JSON.
+func ClientLogging(logger *log.Logger, opts ...httpx.LoggingOption) ClientInterceptor {
+	l := newCallLogger(logger, "grpc_request", opts)
+	if l == nil {
+		return ClientInterceptor{}
+	}
+	return ClientInterceptor{
+		Unary: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			call := l.start(ctx, method, md, req)
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			call.end(err, reply)
+			return err
+		},
+		Stream: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			call := l.start(ctx, method, md, nil)
+			cs, err := streamer(ctx, desc, cc, method, opts...)
+			return watchStream(ctx, desc, cs, err, func(err error) { call.end(err, nil) })
+		},
+	}
+}
+
+// ServerLogging logs every call a server handles like ClientLogging, with
+// the events grpc_server_request_started, grpc_server_request_completed
+// and grpc_server_request_failed. Headers log the incoming metadata.
+func ServerLogging(logger *log.Logger, opts ...httpx.LoggingOption) ServerInterceptor {
+	l := newCallLogger(logger, "grpc_server_request", opts)
+	if l == nil {
+		return ServerInterceptor{}
+	}
+	return ServerInterceptor{
+		Unary: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			call := l.start(ctx, info.FullMethod, md, req)
+			resp, err := handler(ctx, req)
+			call.end(err, resp)
+			return resp, err
+		},
+		Stream: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			md, _ := metadata.FromIncomingContext(ss.Context())
+			call := l.start(ss.Context(), info.FullMethod, md, nil)
+			err := handler(srv, ss)
+			call.end(err, nil)
+			return err
+		},
+	}
+}
+
+// callLogger logs calls with the events prefix_started, prefix_completed
+// and prefix_failed.
+type callLogger struct {
+	logger   *log.Logger
+	prefix   string
+	cfg      httpx.LoggingConfig
+	redactor *log.Redactor
+}
+
+func newCallLogger(logger *log.Logger, prefix string, opts []httpx.LoggingOption) *callLogger {
+	var cfg httpx.LoggingConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.Disabled {
+		return nil
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = httpx.DefaultMaxBodyBytes
+	}
+	return &callLogger{logger: logger, prefix: prefix, cfg: cfg, redactor: log.NewRedactor()}
+}
+
+// loggedCall is a call being logged.
+type loggedCall struct {
+	*callLogger
+	ctx    context.Context
+	logger *log.Logger
+	start  time.Time
+}
+
+func (l *callLogger) start(ctx context.Context, fullMethod string, md metadata.MD, req any) *loggedCall {
+	service, method := splitMethod(fullMethod)
+	logger := l.logger.With("rpc.system", "grpc", "rpc.service", service, "rpc.method", method)
+	var started []any
+	if l.cfg.Headers {
+		started = append(started, "rpc.request.metadata", l.redactor.Redact(map[string][]string(md)))
+	}
+	if l.cfg.Bodies && req != nil {
+		started = append(started, "rpc.request.body", l.message(req))
+	}
+	logger.DebugCtx(ctx, l.prefix+"_started", started...)
+	return &loggedCall{callLogger: l, ctx: ctx, logger: logger, start: time.Now()}
+}
+
+func (c *loggedCall) end(err error, resp any) {
+	code := Code(err)
+	kv := []any{
+		"rpc.grpc.status", code.String(),
+		"rpc.grpc.status_code", int(code),
+		"duration_ms", time.Since(c.start).Milliseconds(),
+	}
+	if err != nil && !errorsx.Classify(err).CallerFault() {
+		c.logger.ErrorCtx(c.ctx, c.prefix+"_failed", append(kv, log.Err(err))...)
+		return
+	}
+	if c.cfg.Bodies && err == nil && resp != nil {
+		kv = append(kv, "rpc.response.body", c.message(resp))
+	}
+	c.logger.InfoCtx(c.ctx, c.prefix+"_completed", kv...)
+}
+
+// message renders a message for the log: protocol buffers as JSON with
+// sensitive fields redacted, truncated to the body limit.
+func (l *callLogger) message(m any) string {
+	pm, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Sprintf("[%T message omitted]", m)
+	}
+	data, err := protojson.Marshal(pm)
+	if err != nil {
+		return fmt.Sprintf("[%T message omitted]", m)
+	}
+	var v any
+	if json.Unmarshal(data, &v) == nil {
+		data, _ = json.Marshal(l.redactor.Redact(v))
+	}
+	if len(data) > l.cfg.MaxBodyBytes {
+		return string(data[:l.cfg.MaxBodyBytes]) + "...[truncated]"
+	}
+	return string(data)
+}
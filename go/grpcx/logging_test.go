@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestClientLogging(t *testing.T) {
+	rec := logtest.Capture(t)
+	c := serve(t, &healthServer{}, []ClientInterceptor{ClientLogging(rec.Logger())})
+
+	if _, err := c.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Records().Events(); !reflect.DeepEqual(got, []string{"grpc_request_started", "grpc_request_completed"}) {
+		t.Fatalf("events = %v", got)
+	}
+	done := rec.Records().Last()
+	for k, want := range map[string]any{"rpc.service": "grpc.health.v1.Health", "rpc.method": "Check", "rpc.grpc.status": "OK"} {
+		if v, _ := done.Get(k); v != want {
+			t.Errorf("%s = %v, want %v", k, v, want)
+		}
+	}
+
+	rec.Reset()
+	c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "denied"})
+	if got := rec.Records().Last(); got.Event != "grpc_request_completed" || got.Level != log.LevelInfo {
+		t.Errorf("caller fault logged as %s at %v", got.Event, got.Level)
+	}
+	c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "flaky"})
+	if got := rec.WithEvent("grpc_request_failed"); got.Count() != 1 {
+		t.Errorf("failure not logged: %v", rec.Records().Events())
+	} else if v, _ := got.First().Get("rpc.grpc.status"); v != "Unavailable" {
+		t.Errorf("status = %v", v)
+	}
+
+	rec.Reset()
+	if n, err := watch(context.Background(), c, ""); err != nil || n != 2 {
+		t.Fatalf("watch = %d, %v", n, err)
+	}
+	if got := rec.Records().Events(); !reflect.DeepEqual(got, []string{"grpc_request_started", "grpc_request_completed"}) {
+		t.Errorf("stream events = %v", got)
+	}
+}
+
+func TestServerLoggingBodiesAndMetadata(t *testing.T) {
+	rec := logtest.Capture(t)
+	c := serve(t, &healthServer{}, nil, ServerLogging(rec.Logger(), httpx.WithHeaders(), httpx.WithBodies(0)))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	if _, err := c.Check(ctx, &healthpb.HealthCheckRequest{Service: "users"}); err != nil {
+		t.Fatal(err)
+	}
+	started := rec.WithEvent("grpc_server_request_started").First()
+	if started == nil {
+		t.Fatalf("events = %v", rec.Records().Events())
+	}
+	if v, _ := started.Get("rpc.request.body"); v != `{"service":"users"}` {
+		t.Errorf("request body = %v", v)
+	}
+	if v, _ := started.Get("rpc.request.metadata"); strings.Contains(strings.Join(v.(map[string][]string)["authorization"], ""), "secret") {
+		t.Errorf("metadata not redacted: %v", v)
+	}
+	if v, _ := rec.WithEvent("grpc_server_request_completed").First().Get("rpc.response.body"); v != `{"status":"SERVING"}` {
+		t.Errorf("response body = %v", v)
+	}
+}
+
+func TestLoggingDisabled(t *testing.T) {
+	rec := logtest.Capture(t)
+	c := serve(t, &healthServer{}, []ClientInterceptor{ClientLogging(rec.Logger(), httpx.WithLoggingConfig(httpx.LoggingConfig{Disabled: true}))})
+	c.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if n := rec.Records().Count(); n != 0 {
+		t.Errorf("disabled logging wrote %d records", n)
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+)
+
+// Metric names recorded by ClientMetrics and ServerMetrics, the gRPC
+// counterparts of the httpx ones.
+const (
+	MetricClientRequests = "grpc_client_requests_total"
+	MetricClientInFlight = "grpc_client_requests_in_flight"
+	MetricClientDuration = "grpc_client_request_duration_seconds"
+	MetricServerRequests = "grpc_server_requests_total"
+	MetricServerInFlight = "grpc_server_requests_in_flight"
+	MetricServerDuration = "grpc_server_request_duration_seconds"
+)
+
+// ClientMetrics records the golden signals of every call in reg, or in
+// metrics.Default when reg is nil, like httpx.Metrics: a call counter and
+// a duration histogram labeled by service, method and code, and an
+// in-flight gauge labeled by service and method. Streams are measured
+// from their start to their end.
+//
+// Place it outside ClientRetry to count calls, or inside to count
+// attempts.
+func ClientMetrics(reg *metrics.Registry) ClientInterceptor {
+	m := newCallMetrics(reg, MetricClientRequests, MetricClientInFlight, MetricClientDuration, "gRPC client")
+	return ClientInterceptor{
+		Unary: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			done := m.start(method)
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			done(err)
+			return err
+		},
+		Stream: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			done := m.start(method)
+			cs, err := streamer(ctx, desc, cc, method, opts...)
+			return watchStream(ctx, desc, cs, err, done)
+		},
+	}
+}
+
+// ServerMetrics records the calls a server handles like ClientMetrics,
+// under the grpc_server metric names.
+func ServerMetrics(reg *metrics.Registry) ServerInterceptor {
+	m := newCallMetrics(reg, MetricServerRequests, MetricServerInFlight, MetricServerDuration, "gRPC server")
+	return ServerInterceptor{
+		Unary: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			done := m.start(info.FullMethod)
+			resp, err := handler(ctx, req)
+			done(err)
+			return resp, err
+		},
+		Stream: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			done := m.start(info.FullMethod)
+			err := handler(srv, ss)
+			done(err)
+			return err
+		},
+	}
+}
+
+type callMetrics struct {
+	requests *metrics.Counter
+	inFlight *metrics.Gauge
+	duration *metrics.Histogram
+}
+
+func newCallMetrics(reg *metrics.Registry, requests, inFlight, duration, side string) *callMetrics {
+	if reg == nil {
+		reg = metrics.Default
+	}
+	return &callMetrics{
+		requests: reg.Counter(requests, side+" requests.", "service", "method", "code"),
+		inFlight: reg.Gauge(inFlight, side+" requests in flight.", "service", "method"),
+		duration: reg.Histogram(duration, side+" request duration in seconds.", nil, "service", "method", "code"),
+	}
+}
+
+// start records the start of a call and returns the function recording
+// its end.
+func (m *callMetrics) start(fullMethod string) func(err error) {
+	service, method := splitMethod(fullMethod)
+	m.inFlight.Inc(service, method)
+	start := time.Now()
+	return func(err error) {
+		elapsed := time.Since(start).Seconds()
+		m.inFlight.Dec(service, method)
+		code := Code(err).String()
+		m.requests.Inc(service, method, code)
+		m.duration.Observe(elapsed, service, method, code)
+	}
+}
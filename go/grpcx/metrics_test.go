@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"testing"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	c := serve(t, &healthServer{}, []ClientInterceptor{ClientMetrics(reg)}, ServerMetrics(reg))
+	c.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	c.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "denied"})
+	if _, err := watch(context.Background(), c, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]metrics.Family{}
+	for _, f := range reg.Collect() {
+		got[f.Name] = f
+	}
+	for _, name := range []string{MetricClientRequests, MetricServerRequests} {
+		counts := map[[3]string]float64{}
+		for _, s := range got[name].Series {
+			counts[[3]string(s.LabelValues)] = s.Value
+		}
+		want := map[[3]string]float64{
+			{"grpc.health.v1.Health", "Check", "OK"}:               2,
+			{"grpc.health.v1.Health", "Check", "PermissionDenied"}: 1,
+			{"grpc.health.v1.Health", "Watch", "OK"}:               1,
+		}
+		if len(counts) != len(want) {
+			t.Errorf("%s = %v", name, counts)
+		}
+		for k, v := range want {
+			if counts[k] != v {
+				t.Errorf("%s%v = %v, want %v", name, k, counts[k], v)
+			}
+		}
+	}
+	if s := got[MetricClientDuration].Series; len(s) != 3 {
+		t.Errorf("durations = %+v", s)
+	}
+	for _, name := range []string{MetricClientInFlight, MetricServerInFlight} {
+		for _, s := range got[name].Series {
+			if s.Value != 0 {
+				t.Errorf("%s after calls = %+v", name, s)
+			}
+		}
+	}
+}
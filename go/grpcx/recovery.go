@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/provide-io/provide-foundation/go/recovery"
+)
+
+// ServerRecovery recovers panics in handlers and the interceptors after
+// it, reported like recovery.Handler reports them, and fails the call
+// with codes.Internal.
+func ServerRecovery(opts ...recovery.Option) ServerInterceptor {
+	return ServerInterceptor{
+		Unary: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+			err = recovery.Do(ctx, func(ctx context.Context) error {
+				resp, err = handler(ctx, req)
+				return err
+			}, opts...)
+			return resp, internal(err)
+		},
+		Stream: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return internal(recovery.Do(ss.Context(), func(context.Context) error {
+				return handler(srv, ss)
+			}, opts...))
+		},
+	}
+}
+
+// internal replaces a recovered panic with an Internal status, keeping
+// its details out of the response.
+func internal(err error) error {
+	if errors.Is(err, recovery.ErrPanic) {
+		return status.Error(codes.Internal, "internal error")
+	}
+	return err
+}
+
+// ClientRecovery recovers panics in the client interceptors after it and
+// returns a *recovery.PanicError from the call instead, as
+// recovery.Transport does for HTTP clients.
+func ClientRecovery(opts ...recovery.Option) ClientInterceptor {
+	return ClientInterceptor{
+		Unary: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+			return recovery.Do(ctx, func(ctx context.Context) error {
+				return invoker(ctx, method, req, reply, cc, callOpts...)
+			}, opts...)
+		},
+		Stream: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (cs grpc.ClientStream, err error) {
+			err = recovery.Do(ctx, func(ctx context.Context) error {
+				cs, err = streamer(ctx, desc, cc, method, callOpts...)
+				return err
+			}, opts...)
+			return cs, err
+		},
+	}
+}
+
+// ServerErrors gives errors returned by handlers without a gRPC status
+// the code of their errorsx class (see Code), so handlers can return the
+// errors they return to HTTP clients: a sql.ErrNoRows reaches the client
+// as NotFound rather than Unknown. Place it inside the logging, metrics
+// and tracing interceptors, which already see the code.
+func ServerErrors() ServerInterceptor {
+	return ServerInterceptor{
+		Unary: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			resp, err := handler(ctx, req)
+			return resp, withStatus(err)
+		},
+		Stream: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return withStatus(handler(srv, ss))
+		},
+	}
+}
+
+func withStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(Code(err), err.Error())
+}
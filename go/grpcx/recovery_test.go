@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/recovery"
+)
+
+func TestServerRecovery(t *testing.T) {
+	rec := logtest.Capture(t)
+	c := serve(t, &healthServer{}, nil, ServerRecovery(recovery.WithLogger(rec.Logger())))
+
+	_, err := c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "panic"})
+	if s, _ := status.FromError(err); s.Code() != codes.Internal || s.Message() != "internal error" {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := watch(context.Background(), c, "panic"); status.Code(err) != codes.Internal {
+		t.Errorf("stream err = %v", err)
+	}
+	if n := len(rec.Records()); n != 2 {
+		t.Errorf("panics logged = %d: %v", n, rec.Records().Events())
+	}
+	if _, err := c.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("server down after panics: %v", err)
+	}
+}
+
+func TestClientRecovery(t *testing.T) {
+	logtest.Capture(t)
+	panicky := ClientInterceptor{
+		Unary: func(context.Context, string, any, any, *grpc.ClientConn, grpc.UnaryInvoker, ...grpc.CallOption) error {
+			panic("bad interceptor")
+		},
+	}
+	c := serve(t, &healthServer{}, []ClientInterceptor{ClientRecovery(), panicky})
+	_, err := c.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	var pe *recovery.PanicError
+	if !errors.As(err, &pe) || pe.Value != "bad interceptor" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	c := serve(t, &healthServer{}, nil, ServerErrors())
+	_, err := c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	if s, _ := status.FromError(err); s.Code() != codes.NotFound || s.Message() != "finding service: sql: no rows in result set" {
+		t.Errorf("err = %v", err)
+	}
+	_, err = c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "denied"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("status replaced: %v", err)
+	}
+
+	c = serve(t, &healthServer{}, nil)
+	_, err = c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	if status.Code(err) != codes.Unknown {
+		t.Errorf("without ServerErrors: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+// ClientRetry retries unary calls under p, as httpx.Retry retries
+// requests. Without a RetryIf, calls are retried when their error class
+// is retryable: codes Unavailable, DeadlineExceeded and
+// ResourceExhausted, the counterparts of retry.DefaultRetryStatus, whose
+// RetryStatus does not apply here. A call that runs out of attempts
+// returns a *retry.Error wrapping the last status, which status.Code
+// still sees.
+//
+// Streams are not retried, since the messages sent on them cannot be
+// replayed. Like httpx.Retry it does not tell idempotent methods apart;
+// add it only to clients whose calls are safe to repeat.
+func ClientRetry(p retry.Policy) ClientInterceptor {
+	if p.RetryIf == nil {
+		p.RetryIf = errorsx.IsRetryable
+	}
+	return ClientInterceptor{
+		Unary: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return retry.Do(ctx, p, func(ctx context.Context) error {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			})
+		},
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+func TestClientRetry(t *testing.T) {
+	reg := metrics.NewRegistry()
+	srv := &healthServer{}
+	p := retry.Policy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	c := serve(t, srv, []ClientInterceptor{ClientRetry(p), ClientMetrics(reg)})
+
+	resp, err := c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "flaky"})
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Check = %v, %v", resp, err)
+	}
+	if n := srv.flaky.Load(); n != 3 {
+		t.Errorf("attempts = %d", n)
+	}
+
+	_, err = c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "denied"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("err = %v", err)
+	}
+	var attempts float64
+	for _, f := range reg.Collect() {
+		if f.Name == MetricClientRequests {
+			for _, s := range f.Series {
+				attempts += s.Value
+			}
+		}
+	}
+	if attempts != 4 {
+		t.Errorf("attempts sent = %v, want 3 for flaky and 1 for denied", attempts)
+	}
+
+	srv.flaky.Store(0)
+	p.MaxAttempts = 2
+	c = serve(t, srv, []ClientInterceptor{ClientRetry(p)})
+	_, err = c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "flaky"})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("exhausted retries: %v", err)
+	}
+}
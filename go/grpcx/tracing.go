@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+// ClientTracing starts a client span for every call, as a child of the
+// span in the call context, and sends the trace context and the request
+// metadata of the context to the server, as the httpx client does for
+// every request. Metadata the caller set itself is left alone. Spans
+// follow the OpenTelemetry RPC conventions and are named after the full
+// method, such as "pkg.Service/Method"; a call failing with any code
+// marks its span as failed.
+func ClientTracing() ClientInterceptor {
+	return ClientInterceptor{
+		Unary: func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			ctx, span := startSpan(ctx, method, trace.SpanKindClient, trace.WithAttr("server.address", cc.Target()))
+			err := invoker(outgoing(ctx), method, req, reply, cc, opts...)
+			endSpan(span, err, true)
+			return err
+		},
+		Stream: func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			ctx, span := startSpan(ctx, method, trace.SpanKindClient, trace.WithAttr("server.address", cc.Target()))
+			cs, err := streamer(outgoing(ctx), desc, cc, method, opts...)
+			return watchStream(ctx, desc, cs, err, func(err error) { endSpan(span, err, true) })
+		},
+	}
+}
+
+// ServerTracing continues the trace of every call from its metadata in a
+// server span, and gives the handler the request metadata of the call,
+// with a new request ID if it came without one, as ctxmeta.Handler does
+// for HTTP. The request ID is sent back in the x-request-id header. Only
+// codes that are not the caller's fault mark the span as failed.
+func ServerTracing() ServerInterceptor {
+	return ServerInterceptor{
+		Unary: func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, id := incoming(ctx)
+			grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(ctxmeta.RequestIDHeader), id))
+			ctx, span := startSpan(ctx, info.FullMethod, trace.SpanKindServer)
+			resp, err := handler(ctx, req)
+			endSpan(span, err, false)
+			return resp, err
+		},
+		Stream: func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, id := incoming(ss.Context())
+			ss.SetHeader(metadata.Pairs(strings.ToLower(ctxmeta.RequestIDHeader), id))
+			ctx, span := startSpan(ctx, info.FullMethod, trace.SpanKindServer)
+			err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+			endSpan(span, err, false)
+			return err
+		},
+	}
+}
+
+func startSpan(ctx context.Context, fullMethod string, kind trace.SpanKind, opts ...trace.SpanOption) (context.Context, *trace.Span) {
+	service, method := splitMethod(fullMethod)
+	opts = append([]trace.SpanOption{
+		trace.WithKind(kind),
+		trace.WithAttr("rpc.system", "grpc"),
+		trace.WithAttr("rpc.service", service),
+		trace.WithAttr("rpc.method", method),
+	}, opts...)
+	return trace.Start(ctx, strings.TrimPrefix(fullMethod, "/"), opts...)
+}
+
+// endSpan records the outcome of a call and ends its span. Errors that
+// are the caller's fault fail only client spans.
+func endSpan(span *trace.Span, err error, client bool) {
+	span.SetAttr("rpc.grpc.status_code", int(Code(err)))
+	if err != nil && (client || !errorsx.Classify(err).CallerFault()) {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// outgoing returns ctx with the trace context and request metadata of ctx
+// added to its outgoing gRPC metadata.
+func outgoing(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	h := http.Header{}
+	trace.Inject(ctx, h)
+	for k, vs := range h {
+		md.Set(k, vs...)
+	}
+	h = http.Header{}
+	ctxmeta.Inject(ctx, h)
+	for k, vs := range h {
+		if len(md.Get(k)) == 0 {
+			md.Set(k, vs...)
+		}
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// incoming returns ctx with the trace context and request metadata of its
+// incoming gRPC metadata, and the request ID of the call.
+func incoming(ctx context.Context) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	h := http.Header{}
+	for k, vs := range md {
+		for _, v := range vs {
+			h.Add(k, v)
+		}
+	}
+	return ctxmeta.EnsureRequestID(ctxmeta.Extract(trace.Extract(ctx, h), h))
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcx
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+}
+
+// byKind returns the recorded spans of kind k.
+func (r *spanRecorder) byKind(k trace.SpanKind) []*trace.SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var spans []*trace.SpanData
+	for _, s := range r.spans {
+		if s.Kind == k {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+func attr(s *trace.SpanData, key string) any {
+	for _, a := range s.Attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	rec := &spanRecorder{}
+	trace.SetExporter(rec)
+	defer trace.SetExporter(nil)
+
+	srv := &healthServer{ctx: make(chan context.Context, 1)}
+	c := serve(t, srv, []ClientInterceptor{ClientTracing()}, ServerTracing())
+
+	var parent trace.SpanContext
+	var header metadata.MD
+	trace.WithSpan(context.Background(), "health.poll", func(ctx context.Context) error {
+		parent = trace.SpanContextFromContext(ctx)
+		ctx = ctxmeta.WithRequestID(ctx, "req-1")
+		_, err := c.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+		return err
+	})
+	got := <-srv.ctx
+
+	client, server := rec.byKind(trace.SpanKindClient), rec.byKind(trace.SpanKindServer)
+	if len(client) != 1 || len(server) != 1 {
+		t.Fatalf("spans = %+v", rec.spans)
+	}
+	if s := client[0]; s.Name != "grpc.health.v1.Health/Check" || s.Parent != parent.SpanID || attr(s, "rpc.method") != "Check" {
+		t.Errorf("client span = %+v", s)
+	}
+	if s := server[0]; s.Parent != client[0].SpanContext.SpanID || s.SpanContext.TraceID != parent.TraceID {
+		t.Errorf("server span %+v is not a child of the client span", s)
+	}
+	if sc := trace.SpanContextFromContext(got); sc.SpanID != server[0].SpanContext.SpanID {
+		t.Errorf("handler span = %v", sc)
+	}
+	if id := ctxmeta.RequestID(got); id != "req-1" {
+		t.Errorf("handler request ID = %q", id)
+	}
+	if id := header.Get("x-request-id"); len(id) != 1 || id[0] != "req-1" {
+		t.Errorf("x-request-id header = %v", id)
+	}
+}
+
+func TestServerTracingStatus(t *testing.T) {
+	rec := &spanRecorder{}
+	trace.SetExporter(rec)
+	defer trace.SetExporter(nil)
+
+	srv := &healthServer{ctx: make(chan context.Context, 3)}
+	c := serve(t, srv, []ClientInterceptor{ClientTracing()}, ServerTracing())
+	c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "denied"})
+	c.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "flaky"})
+	if _, err := watch(context.Background(), c, ""); err != nil {
+		t.Fatal(err)
+	}
+	if id := ctxmeta.RequestID(<-srv.ctx); id == "" {
+		t.Error("no request ID generated")
+	}
+
+	server := rec.byKind(trace.SpanKindServer)
+	if len(server) != 3 {
+		t.Fatalf("server spans = %+v", server)
+	}
+	if s := server[0]; s.Status == trace.StatusError || attr(s, "rpc.grpc.status_code") != 7 {
+		t.Errorf("PermissionDenied server span = %+v", s)
+	}
+	if s := server[1]; s.Status != trace.StatusError {
+		t.Errorf("Unavailable server span = %+v", s)
+	}
+	if s := server[2]; s.Name != "grpc.health.v1.Health/Watch" || s.Status == trace.StatusError {
+		t.Errorf("stream server span = %+v", s)
+	}
+	if client := rec.byKind(trace.SpanKindClient); len(client) != 3 || client[0].Status != trace.StatusError {
+		t.Errorf("client spans = %+v", client)
+	}
+}
@@ -0,0 +1,307 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package health is a registry of named health checks served as
+// Kubernetes-style liveness and readiness endpoints. Components register
+// a check when they are built,
+//
+//	health.Default.Register("database", database.Check)
+//	health.Default.Register("billing", health.HTTP(billing, "/health"), health.WithTimeout(2*time.Second))
+//
+// and the application mounts the handlers:
+//
+//	mux.Handle("GET /healthz", health.Default.LivenessHandler())
+//	mux.Handle("GET /readyz", health.Default.ReadinessHandler())
+//
+// Checks run concurrently, each under its own timeout, and their results
+// are cached briefly so frequent probes do not load the dependencies.
+// Both endpoints answer 200 when every check they run passes and 503
+// otherwise, with the result of each check as JSON.
+package health
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Defaults for checks registered without options.
+const (
+	DefaultTimeout  = 5 * time.Second
+	DefaultCacheTTL = time.Second
+)
+
+// ErrExists is matched by errors.Is when a check name is already taken.
+var ErrExists = errors.New("health: check already registered")
+
+// Check reports whether a dependency is healthy. It must return promptly
+// once ctx is done.
+type Check func(ctx context.Context) error
+
+// HTTP returns a check that GETs path through c and fails for transport
+// errors and 4xx and 5xx responses, for downstream API reachability.
+func HTTP(c *httpx.Client, path string) Check {
+	return func(ctx context.Context) error {
+		_, err := c.Get(ctx, path)
+		return err
+	}
+}
+
+// Status is the outcome of a check or of a set of checks.
+type Status string
+
+// Statuses.
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Name      string        `json:"name"`
+	Status    Status        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"-"`
+	CheckedAt time.Time     `json:"checked_at"`
+	// Cached is set when the result was served from the cache.
+	Cached bool `json:"cached,omitempty"`
+}
+
+// MarshalJSON adds the duration in milliseconds.
+func (r Result) MarshalJSON() ([]byte, error) {
+	type plain Result
+	return json.Marshal(struct {
+		plain
+		DurationMS float64 `json:"duration_ms"`
+	}{plain(r), float64(r.Duration) / float64(time.Millisecond)})
+}
+
+// Report is the outcome of a set of checks: up when all are up.
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Option configures a Registry.
+type Option func(*Registry)
+
+// WithDefaultTimeout sets the timeout of checks registered without
+// WithTimeout.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(r *Registry) { r.timeout = d }
+}
+
+// WithDefaultCacheTTL sets how long the results of checks registered
+// without WithCacheTTL are reused.
+func WithDefaultCacheTTL(d time.Duration) Option {
+	return func(r *Registry) { r.cacheTTL = d }
+}
+
+// WithLogger sets the logger for status changes. The default is
+// log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(r *Registry) { r.logger = l }
+}
+
+// CheckOption configures a registered check.
+type CheckOption func(*check)
+
+// WithTimeout bounds one run of the check; a check still running then
+// fails with context.DeadlineExceeded, even if it ignores its context.
+func WithTimeout(d time.Duration) CheckOption {
+	return func(c *check) { c.timeout = d }
+}
+
+// WithCacheTTL sets how long a result is reused. Zero runs the check on
+// every request.
+func WithCacheTTL(d time.Duration) CheckOption {
+	return func(c *check) { c.cacheTTL = d }
+}
+
+// Liveness includes the check in the liveness endpoint. Only register
+// liveness checks for faults a restart fixes, such as a deadlocked worker;
+// a failing dependency should fail readiness, not restart the process.
+func Liveness() CheckOption {
+	return func(c *check) { c.liveness = true }
+}
+
+type check struct {
+	name     string
+	fn       Check
+	timeout  time.Duration
+	cacheTTL time.Duration
+	liveness bool
+
+	mu   sync.Mutex // serializes runs, so concurrent probes share one
+	last Result
+	ran  bool
+}
+
+// Registry holds named checks. It is safe for concurrent use.
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	logger   *log.Logger
+
+	mu     sync.RWMutex
+	checks map[string]*check
+}
+
+// Default is the process-wide registry.
+var Default = New()
+
+// New returns an empty registry.
+func New(opts ...Option) *Registry {
+	r := &Registry{timeout: DefaultTimeout, cacheTTL: DefaultCacheTTL, checks: make(map[string]*check)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register adds a check under name. It fails with ErrExists if the name
+// is taken.
+func (r *Registry) Register(name string, fn Check, opts ...CheckOption) error {
+	if fn == nil {
+		return fmt.Errorf("health: check %q: nil function", name)
+	}
+	c := &check{name: name, fn: fn, timeout: r.timeout, cacheTTL: r.cacheTTL}
+	for _, opt := range opts {
+		opt(c)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.checks[name]; ok {
+		return fmt.Errorf("%w: %q", ErrExists, name)
+	}
+	r.checks[name] = c
+	return nil
+}
+
+// Unregister removes the named check and reports whether it existed.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.checks[name]
+	delete(r.checks, name)
+	return ok
+}
+
+// Names returns the registered check names, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.checks))
+}
+
+// Liveness runs the checks registered with Liveness.
+func (r *Registry) Liveness(ctx context.Context) Report {
+	return r.run(ctx, func(c *check) bool { return c.liveness })
+}
+
+// Readiness runs every check.
+func (r *Registry) Readiness(ctx context.Context) Report {
+	return r.run(ctx, func(*check) bool { return true })
+}
+
+func (r *Registry) run(ctx context.Context, include func(*check) bool) Report {
+	r.mu.RLock()
+	var checks []*check
+	for _, c := range r.checks {
+		if include(c) {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.RUnlock()
+	slices.SortFunc(checks, func(a, b *check) int { return cmp.Compare(a.name, b.name) })
+
+	rep := Report{Status: StatusUp, Checks: make([]Result, len(checks))}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rep.Checks[i] = r.runCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+	for _, res := range rep.Checks {
+		if res.Status != StatusUp {
+			rep.Status = StatusDown
+		}
+	}
+	return rep
+}
+
+// runCheck returns the cached result of c or runs it, logging when its
+// status changes.
+func (r *Registry) runCheck(ctx context.Context, c *check) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ran && time.Since(c.last.CheckedAt) < c.cacheTTL {
+		res := c.last
+		res.Cached = true
+		return res
+	}
+	// The check outlives a probe that disconnects, so its result is
+	// cached for the next one, but not its own timeout.
+	ctx = context.WithoutCancel(ctx)
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.fn(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	res := Result{Name: c.name, Status: StatusUp, Duration: time.Since(start), CheckedAt: start}
+	if err != nil {
+		res.Status, res.Error = StatusDown, err.Error()
+	}
+	logger := r.logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	switch {
+	case res.Status == StatusDown && (!c.ran || c.last.Status == StatusUp):
+		logger.WarnCtx(ctx, "health_check_failed", "check", c.name, log.Err(err))
+	case res.Status == StatusUp && c.ran && c.last.Status == StatusDown:
+		logger.InfoCtx(ctx, "health_check_recovered", "check", c.name)
+	}
+	c.last, c.ran = res, true
+	return res
+}
+
+// LivenessHandler serves Liveness, for /healthz.
+func (r *Registry) LivenessHandler() http.Handler { return r.handler(r.Liveness) }
+
+// ReadinessHandler serves Readiness, for /readyz.
+func (r *Registry) ReadinessHandler() http.Handler { return r.handler(r.Readiness) }
+
+func (r *Registry) handler(run func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rep := run(req.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if rep.Status != StatusUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(rep)
+	})
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func ok(context.Context) error { return nil }
+
+func TestReadinessAndLiveness(t *testing.T) {
+	rec := logtest.Capture(t)
+	r := New()
+	r.Register("worker", ok, Liveness())
+	r.Register("database", func(context.Context) error { return errors.New("connection refused") })
+	if err := r.Register("worker", ok); !errors.Is(err, ErrExists) {
+		t.Errorf("duplicate = %v", err)
+	}
+
+	srv := httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(srv, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if srv.Code != http.StatusServiceUnavailable || srv.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("readyz = %d %s", srv.Code, srv.Header().Get("Content-Type"))
+	}
+	var rep struct {
+		Status string
+		Checks []map[string]any
+	}
+	if err := json.Unmarshal(srv.Body.Bytes(), &rep); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Status != "down" || len(rep.Checks) != 2 || rep.Checks[0]["name"] != "database" ||
+		rep.Checks[0]["error"] != "connection refused" || rep.Checks[1]["status"] != "up" {
+		t.Errorf("report = %s", srv.Body)
+	}
+	if _, ok := rep.Checks[0]["duration_ms"].(float64); !ok {
+		t.Errorf("duration_ms missing: %s", srv.Body)
+	}
+	if v, _ := rec.WithEvent("health_check_failed").First().Get("check"); v != "database" {
+		t.Error("failure not logged")
+	}
+
+	srv = httptest.NewRecorder()
+	r.LivenessHandler().ServeHTTP(srv, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if srv.Code != http.StatusOK {
+		t.Errorf("healthz = %d: %s", srv.Code, srv.Body)
+	}
+}
+
+func TestCaching(t *testing.T) {
+	logtest.Capture(t)
+	var calls atomic.Int32
+	r := New(WithDefaultCacheTTL(time.Hour))
+	r.Register("cached", func(context.Context) error { calls.Add(1); return nil })
+	r.Register("fresh", func(context.Context) error { calls.Add(1); return nil }, WithCacheTTL(0))
+	ctx := context.Background()
+	r.Readiness(ctx)
+	rep := r.Readiness(ctx)
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+	if !rep.Checks[0].Cached || rep.Checks[1].Cached {
+		t.Errorf("cached = %v, %v", rep.Checks[0].Cached, rep.Checks[1].Cached)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	logtest.Capture(t)
+	r := New()
+	block := make(chan struct{})
+	defer close(block)
+	r.Register("stuck", func(context.Context) error { <-block; return nil }, WithTimeout(10*time.Millisecond))
+	rep := r.Readiness(context.Background())
+	if rep.Status != StatusDown || rep.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("report = %+v", rep)
+	}
+}
+
+func TestRecoveryIsLogged(t *testing.T) {
+	rec := logtest.Capture(t)
+	var fail atomic.Bool
+	fail.Store(true)
+	r := New(WithDefaultCacheTTL(0))
+	r.Register("cache", func(context.Context) error {
+		if fail.Load() {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r.Readiness(ctx)
+	r.Readiness(ctx)
+	fail.Store(false)
+	if rep := r.Readiness(ctx); rep.Status != StatusUp {
+		t.Errorf("status = %s", rep.Status)
+	}
+	if n := rec.WithEvent("health_check_failed").Count(); n != 1 {
+		t.Errorf("failures logged %d times", n)
+	}
+	if rec.WithEvent("health_check_recovered").Count() != 1 {
+		t.Error("recovery not logged")
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	c, err := httpx.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := HTTP(c, "/health")(ctx); err != nil {
+		t.Errorf("healthy: %v", err)
+	}
+	var se *httpx.StatusError
+	if err := HTTP(c, "/other")(ctx); !errors.As(err, &se) {
+		t.Errorf("unhealthy: %v", err)
+	}
+}
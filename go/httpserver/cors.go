@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures CORS, e.g. bound with config.Bind under an
+// "http.cors" section. The zero value allows no cross-origin requests.
+type CORSConfig struct {
+	AllowedOrigins   []string      `config:"allowed_origins" desc:"Origins allowed to call the API; \"*\" allows any."`
+	AllowedMethods   []string      `config:"allowed_methods" desc:"Methods allowed in cross-origin requests; GET, HEAD and POST when empty."`
+	AllowedHeaders   []string      `config:"allowed_headers" desc:"Request headers allowed in cross-origin requests; \"*\" allows any."`
+	ExposedHeaders   []string      `config:"exposed_headers" desc:"Response headers scripts may read."`
+	AllowCredentials bool          `config:"allow_credentials" desc:"Allow cookies and credentials."`
+	MaxAge           time.Duration `config:"max_age" desc:"How long browsers may cache preflight responses."`
+}
+
+// defaultCORSMethods are the methods allowed when none are configured,
+// the CORS-safelisted ones.
+var defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// CORS answers cross-origin requests from the origins of cfg. Preflight
+// requests get a 204 without reaching the handler, or a 403 when their
+// origin, method or headers are not allowed; other requests pass through
+// with the CORS response headers added when their origin is allowed.
+// With credentials allowed, a "*" origin is answered with the requesting
+// origin, since browsers reject the wildcard there.
+func CORS(cfg CORSConfig) Middleware {
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	anyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+	anyHeader := slices.Contains(cfg.AllowedHeaders, "*")
+	allowed := func(origin string) bool {
+		return anyOrigin || slices.ContainsFunc(cfg.AllowedOrigins, func(o string) bool { return strings.EqualFold(o, origin) })
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+			if origin == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			if !allowed(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, req)
+				return
+			}
+			if anyOrigin && !cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if !preflight {
+				if len(cfg.ExposedHeaders) > 0 {
+					h.Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+				}
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			method := req.Header.Get("Access-Control-Request-Method")
+			if !slices.Contains(methods, method) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			requested := req.Header.Get("Access-Control-Request-Headers")
+			for name := range strings.SplitSeq(requested, ",") {
+				name = strings.TrimSpace(name)
+				if name != "" && !anyHeader && !slices.ContainsFunc(cfg.AllowedHeaders, func(a string) bool { return strings.EqualFold(a, name) }) {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+			}
+			h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			if requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
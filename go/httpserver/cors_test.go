@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func corsRequest(h http.Handler, method, origin string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/users/1", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS(t *testing.T) {
+	h := Chain(mux(), CORS(CORSConfig{
+		AllowedOrigins: []string{"https://app.example"},
+		AllowedMethods: []string{"GET", "DELETE"},
+		AllowedHeaders: []string{"Authorization"},
+		ExposedHeaders: []string{"X-Request-ID"},
+		MaxAge:         time.Hour,
+	}))
+
+	w := corsRequest(h, "GET", "https://app.example")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example" ||
+		w.Header().Get("Access-Control-Expose-Headers") != "X-Request-ID" {
+		t.Errorf("simple request: %d %v", w.Code, w.Header())
+	}
+	w = corsRequest(h, "GET", "https://evil.example")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("other origin: %d %v", w.Code, w.Header())
+	}
+
+	w = corsRequest(h, "OPTIONS", "https://app.example", "Access-Control-Request-Method", "DELETE", "Access-Control-Request-Headers", "authorization")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Methods") != "GET, DELETE" ||
+		w.Header().Get("Access-Control-Allow-Headers") != "authorization" || w.Header().Get("Access-Control-Max-Age") != "3600" {
+		t.Errorf("preflight: %d %v", w.Code, w.Header())
+	}
+	for _, headers := range [][]string{
+		{"Access-Control-Request-Method", "PUT"},
+		{"Access-Control-Request-Method", "GET", "Access-Control-Request-Headers", "X-Secret"},
+	} {
+		if w := corsRequest(h, "OPTIONS", "https://app.example", headers...); w.Code != http.StatusForbidden {
+			t.Errorf("preflight %v answered %d", headers, w.Code)
+		}
+	}
+	if w := corsRequest(h, "OPTIONS", "https://evil.example", "Access-Control-Request-Method", "GET"); w.Code != http.StatusForbidden {
+		t.Errorf("preflight from other origin answered %d", w.Code)
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	h := Chain(mux(), CORS(CORSConfig{AllowedOrigins: []string{"*"}}))
+	if got := corsRequest(h, "GET", "https://a.example").Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("allow origin = %q", got)
+	}
+
+	h = Chain(mux(), CORS(CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}))
+	w := corsRequest(h, "GET", "https://a.example")
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://a.example" || w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("with credentials: %v", w.Header())
+	}
+	if w := corsRequest(h, "GET", ""); w.Header().Get("Access-Control-Allow-Origin") != "" || w.Header().Get("Vary") != "Origin" {
+		t.Errorf("same-origin request: %v", w.Header())
+	}
+}
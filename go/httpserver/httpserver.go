@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package httpserver is the server side of httpx: middleware for request
+// IDs, panic recovery, tracing, logging, metrics, timeouts and CORS that
+// works with any router, and a Server shut down gracefully by the
+// foundation shutdown coordinator.
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("GET /users/{id}", getUser)
+//	h := httpserver.Chain(mux,
+//		httpserver.Standard(logger),
+//		httpserver.CORS(cfg.CORS),
+//		httpserver.Timeout(10*time.Second),
+//	)
+//	srv := httpserver.New(cfg.Addr, h)
+//	go srv.ListenAndServe(ctx)
+//	shutdown.Default.Wait(ctx)
+//
+// The middleware logs, measures and traces requests like the httpx
+// client middleware does, with http_server_* events and metrics. Metrics
+// and spans are labeled by route rather than path so their cardinality
+// stays bounded: the pattern matched by an http.ServeMux is picked up
+// on its own, and other routers report theirs with SetRoute.
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/recovery"
+)
+
+// Middleware wraps a handler.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h in mws. The first is the outermost, as with httpx
+// middleware: it sees each request first and its response last.
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Standard is the middleware every service wants, in the order it wants
+// it: RequestID, Tracing, Logging through logger, or log.Default() when
+// nil, Metrics in metrics.Default, and Recovery, so a panic is logged
+// and measured as the 500 it becomes.
+func Standard(logger *log.Logger) Middleware {
+	mws := []Middleware{RequestID(), Tracing(), Logging(logger), Metrics(nil), Recovery(recovery.WithLogger(logger))}
+	return func(h http.Handler) http.Handler { return Chain(h, mws...) }
+}
+
+// requestState is shared by the middleware of a request through its
+// context, so the outer ones learn the route found by the router even
+// when the inner ones passed it a copy of the request.
+type requestState struct {
+	mu      sync.Mutex
+	pattern string
+}
+
+type stateKey struct{}
+
+// withState returns req with a state, adding one if it has none yet.
+func withState(req *http.Request) (*http.Request, *requestState) {
+	if st, ok := req.Context().Value(stateKey{}).(*requestState); ok {
+		return req, st
+	}
+	st := &requestState{}
+	return req.WithContext(context.WithValue(req.Context(), stateKey{}, st)), st
+}
+
+// SetRoute reports the route pattern matched for the request of ctx, such
+// as "/users/{id}", for routers other than http.ServeMux, whose patterns
+// are found without it. Call it from the handler or the router hook that
+// knows the pattern; it does nothing outside this package's middleware.
+func SetRoute(ctx context.Context, route string) {
+	if st, ok := ctx.Value(stateKey{}).(*requestState); ok {
+		st.mu.Lock()
+		st.pattern = route
+		st.mu.Unlock()
+	}
+}
+
+// route returns the route of the request, once served.
+func (st *requestState) route() string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.pattern
+}
+
+// track wraps the handler a middleware calls so that, once it has
+// served a request, or panicked, the pattern an http.ServeMux set on the
+// request it was given is recorded in the state.
+func track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if st, ok := req.Context().Value(stateKey{}).(*requestState); ok {
+			defer st.record(req)
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// record keeps the pattern of req unless a route is known already.
+func (st *requestState) record(req *http.Request) {
+	if req.Pattern == "" {
+		return
+	}
+	p := req.Pattern
+	if _, path, ok := strings.Cut(p, " "); ok {
+		p = path
+	}
+	st.mu.Lock()
+	if st.pattern == "" {
+		st.pattern = p
+	}
+	st.mu.Unlock()
+}
+
+// responseWriter records the status and size of a response and, when
+// capture is set, its first capture bytes.
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	size    int64
+	capture int
+	body    []byte
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if n := w.capture + 1 - len(w.body); w.capture > 0 && n > 0 {
+		w.body = append(w.body, b[:min(n, len(b))]...)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush flushes the underlying writer if it supports flushing.
+func (w *responseWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// code returns the status of the response, 200 for a handler that wrote
+// nothing.
+func (w *responseWriter) code() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
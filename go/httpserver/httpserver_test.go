@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/metrics"
+)
+
+// mux is the router of the tests.
+func mux() *http.ServeMux {
+	m := http.NewServeMux()
+	m.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"`+r.PathValue("id")+`","request_id":"`+ctxmeta.RequestID(r.Context())+`"}`)
+	})
+	m.HandleFunc("POST /users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+	m.HandleFunc("GET /panic", func(http.ResponseWriter, *http.Request) { panic("boom") })
+	m.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	return m
+}
+
+func serveReq(h http.Handler, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, body))
+	return w
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name+">")
+				next.ServeHTTP(w, r)
+				order = append(order, "<"+name)
+			})
+		}
+	}
+	h := Chain(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { order = append(order, "handler") }), mw("a"), mw("b"))
+	serveReq(h, "GET", "/", nil)
+	if want := []string{"a>", "b>", "handler", "<b", "<a"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestStandard(t *testing.T) {
+	rec := logtest.Capture(t)
+	h := Chain(mux(), Standard(rec.Logger()))
+
+	w := serveReq(h, "GET", "/users/7", nil)
+	id := w.Header().Get(ctxmeta.RequestIDHeader)
+	if w.Code != http.StatusOK || id == "" || !strings.Contains(w.Body.String(), `"request_id":"`+id+`"`) {
+		t.Fatalf("response = %d %q, request ID %q", w.Code, w.Body, id)
+	}
+	done := rec.WithEvent("http_server_request_completed").Last()
+	if done == nil {
+		t.Fatalf("events = %v", rec.Records().Events())
+	}
+	if v, _ := done.Get("http.route"); v != "/users/{id}" {
+		t.Errorf("route = %v", v)
+	}
+
+	if w := serveReq(h, "GET", "/panic", nil); w.Code != http.StatusInternalServerError {
+		t.Errorf("panic answered %d", w.Code)
+	}
+	if rec.WithEvent("panic_recovered").Count() != 1 || rec.WithEvent("http_server_request_failed").Count() != 1 {
+		t.Errorf("events = %v", rec.Records().Events())
+	}
+}
+
+func TestSetRoute(t *testing.T) {
+	reg := metrics.NewRegistry()
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r.Context(), "/things/:id")
+	}), RequestID(), Metrics(reg))
+	serveReq(h, "GET", "/things/1", nil)
+	SetRoute(httptest.NewRequest("GET", "/", nil).Context(), "/ignored")
+	for _, f := range reg.Collect() {
+		if f.Name == MetricRequests {
+			if got := f.Series[0].LabelValues[1]; got != "/things/:id" {
+				t.Errorf("route = %q", got)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/internal/httplog"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Logging logs every request through logger, or log.Default() when nil,
+// as httpx.Logging logs the requests of clients:
+// http_server_request_started at DEBUG, then http_server_request_completed
+// at INFO, or http_server_request_failed at ERROR for 5xx responses, with
+// the http event-set fields and the route. The options are those of
+// httpx.Logging; bodies are read as the handler reads the request and
+// writes the response.
+func Logging(logger *log.Logger, opts ...httpx.LoggingOption) Middleware {
+	var cfg httpx.LoggingConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = httpx.DefaultMaxBodyBytes
+	}
+	redactor := log.NewRedactor()
+	return func(next http.Handler) http.Handler {
+		if cfg.Disabled {
+			return next
+		}
+		next = track(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req, st := withState(req)
+			ctx := req.Context()
+			l := logger
+			if l == nil {
+				l = log.Default()
+			}
+			l = l.With("http.method", req.Method, "http.target", req.URL.RequestURI(), "client.address", clientAddress(req))
+			var started []any
+			if cfg.Headers {
+				started = append(started, "http.request.headers", redactor.Redact(req.Header))
+			}
+			if cfg.Bodies && req.Body != nil && req.Body != http.NoBody {
+				data, truncated := httplog.Peek(req.Body, cfg.MaxBodyBytes)
+				req.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(data), req.Body), req.Body}
+				if len(data) > 0 {
+					started = append(started, "http.request.body", httplog.FormatBody(redactor, req.Header, data, truncated))
+				}
+			}
+			l.DebugCtx(ctx, "http_server_request_started", started...)
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w}
+			if cfg.Bodies {
+				rw.capture = cfg.MaxBodyBytes
+			}
+			next.ServeHTTP(rw, req)
+			code := rw.code()
+			done := []any{
+				"http.status_code", code,
+				"http.status_class", fmt.Sprintf("%dxx", code/100),
+				"http.response.body.size", rw.size,
+				"duration_ms", time.Since(start).Milliseconds(),
+			}
+			if route := st.route(); route != "" {
+				done = append(done, "http.route", route)
+			}
+			if cfg.Headers {
+				done = append(done, "http.response.headers", redactor.Redact(w.Header()))
+			}
+			if cfg.Bodies && len(rw.body) > 0 {
+				done = append(done, "http.response.body", httplog.FormatBody(redactor, w.Header(), rw.body, len(rw.body) > cfg.MaxBodyBytes))
+			}
+			if code >= 500 {
+				l.ErrorCtx(ctx, "http_server_request_failed", done...)
+				return
+			}
+			l.InfoCtx(ctx, "http_server_request_completed", done...)
+		})
+	}
+}
+
+// clientAddress returns the host of the remote address of req.
+func clientAddress(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestLogging(t *testing.T) {
+	rec := logtest.Capture(t)
+	h := Chain(mux(), Logging(rec.Logger()))
+
+	serveReq(h, "GET", "/users/1?expand=teams", nil)
+	if got := rec.Records().Events(); !reflect.DeepEqual(got, []string{"http_server_request_started", "http_server_request_completed"}) {
+		t.Fatalf("events = %v", got)
+	}
+	done := rec.Records().Last()
+	for k, want := range map[string]any{
+		"http.method": "GET", "http.target": "/users/1?expand=teams", "http.route": "/users/{id}",
+		"http.status_code": 200, "http.status_class": "2xx", "client.address": "192.0.2.1",
+	} {
+		if v, _ := done.Get(k); v != want {
+			t.Errorf("%s = %v, want %v", k, v, want)
+		}
+	}
+
+	rec.Reset()
+	serveReq(h, "GET", "/nowhere", nil)
+	if got := rec.Records().Last(); got.Event != "http_server_request_completed" || got.Level != log.LevelInfo {
+		t.Errorf("404 logged as %s at %v", got.Event, got.Level)
+	} else if _, ok := got.Get("http.route"); ok {
+		t.Error("route logged for an unmatched request")
+	}
+
+	h = Chain(mux(), Logging(rec.Logger()), Recovery())
+	serveReq(h, "GET", "/panic", nil)
+	if got := rec.WithEvent("http_server_request_failed"); got.Count() != 1 {
+		t.Errorf("500 not logged as failed: %v", rec.Records().Events())
+	}
+}
+
+func TestLoggingBodiesAndHeaders(t *testing.T) {
+	rec := logtest.Capture(t)
+	h := Chain(mux(), Logging(rec.Logger(), httpx.WithHeaders(), httpx.WithBodies(0)))
+
+	req := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"ann","password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), "hunter2") {
+		t.Fatalf("handler did not get the whole body: %d %q", w.Code, w.Body)
+	}
+	started := rec.WithEvent("http_server_request_started").First()
+	if v, _ := started.Get("http.request.body"); strings.Contains(v.(string), "hunter2") || !strings.Contains(v.(string), "ann") {
+		t.Errorf("request body = %v", v)
+	}
+	done := rec.WithEvent("http_server_request_completed").First()
+	if v, _ := done.Get("http.response.body"); v == nil {
+		t.Error("response body not logged")
+	}
+	if v, _ := done.Get("http.response.body.size"); v != int64(35) {
+		t.Errorf("response size = %v", v)
+	}
+
+	rec.Reset()
+	h = Chain(mux(), Logging(rec.Logger(), httpx.WithBodies(4)))
+	serveReq(h, "GET", "/users/1", nil)
+	if v, _ := rec.Records().Last().Get("http.response.body"); !strings.HasSuffix(v.(string), "...[truncated]") {
+		t.Errorf("response body = %v", v)
+	}
+}
+
+func TestLoggingDisabled(t *testing.T) {
+	rec := logtest.Capture(t)
+	h := Chain(mux(), Logging(rec.Logger(), httpx.WithLoggingConfig(httpx.LoggingConfig{Disabled: true})))
+	serveReq(h, "GET", "/users/1", nil)
+	if n := rec.Records().Count(); n != 0 {
+		t.Errorf("disabled logging wrote %d records", n)
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+)
+
+// Metric names recorded by Metrics, following the OpenTelemetry HTTP
+// server conventions in Prometheus form.
+const (
+	MetricRequests = "http_server_requests_total"
+	MetricInFlight = "http_server_requests_in_flight"
+	MetricDuration = "http_server_request_duration_seconds"
+)
+
+// Metrics records the golden signals of every request in reg, or in
+// metrics.Default when reg is nil, like httpx.Metrics: a request counter
+// and a duration histogram labeled by method, route and status, and an
+// in-flight gauge labeled by method. Requests no route matched have an
+// empty route, methods outside the standard ones are counted as
+// "_OTHER", and a request whose handler panicked past the middleware
+// has the status 500.
+func Metrics(reg *metrics.Registry) Middleware {
+	if reg == nil {
+		reg = metrics.Default
+	}
+	requests := reg.Counter(MetricRequests, "HTTP server requests.", "method", "route", "status")
+	inFlight := reg.Gauge(MetricInFlight, "HTTP server requests in flight.", "method")
+	duration := reg.Histogram(MetricDuration, "HTTP server request duration in seconds.", nil, "method", "route", "status")
+	return func(next http.Handler) http.Handler {
+		next = track(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req, st := withState(req)
+			method := methodLabel(req.Method)
+			inFlight.Inc(method)
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w}
+			served := false
+			defer func() {
+				elapsed := time.Since(start).Seconds()
+				inFlight.Dec(method)
+				status := strconv.Itoa(rw.code())
+				if !served {
+					status = "500"
+				}
+				route := st.route()
+				requests.Inc(method, route, status)
+				duration.Observe(elapsed, method, route, status)
+			}()
+			next.ServeHTTP(rw, req)
+			served = true
+		})
+	}
+}
+
+// methodLabel returns m if it is a standard method, so clients cannot
+// make up label values.
+func methodLabel(m string) string {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return m
+	}
+	return "_OTHER"
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	logtest.Capture(t)
+	reg := metrics.NewRegistry()
+	var inFlight float64
+	probe := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, f := range reg.Collect() {
+				if f.Name == MetricInFlight {
+					inFlight = f.Series[0].Value
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	h := Chain(mux(), Recovery(), Metrics(reg), RequestID(), probe)
+	serveReq(h, "GET", "/users/1", nil)
+	serveReq(h, "GET", "/users/2", nil)
+	serveReq(h, "GET", "/nowhere", nil)
+	serveReq(h, "BREW", "/users/1", nil)
+	serveReq(h, "GET", "/panic", nil)
+	if inFlight != 1 {
+		t.Errorf("in flight during request = %v", inFlight)
+	}
+
+	got := map[string]metrics.Family{}
+	for _, f := range reg.Collect() {
+		got[f.Name] = f
+	}
+	counts := map[[3]string]float64{}
+	for _, s := range got[MetricRequests].Series {
+		counts[[3]string(s.LabelValues)] = s.Value
+	}
+	want := map[[3]string]float64{
+		{"GET", "/users/{id}", "200"}: 2,
+		{"GET", "", "404"}:            1,
+		{"_OTHER", "", "405"}:         1,
+		{"GET", "/panic", "500"}:      1,
+	}
+	if len(counts) != len(want) {
+		t.Errorf("requests = %v", counts)
+	}
+	for k, v := range want {
+		if counts[k] != v {
+			t.Errorf("requests%v = %v, want %v", k, counts[k], v)
+		}
+	}
+	for _, s := range got[MetricInFlight].Series {
+		if s.Value != 0 {
+			t.Errorf("in flight after requests = %+v", s)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+	"github.com/provide-io/provide-foundation/go/recovery"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+// RequestID gives each request the metadata of its headers and a request
+// ID, echoed in the X-Request-ID response header; see ctxmeta.Handler.
+// Place it first so every log record of the request carries the ID.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		h := ctxmeta.Handler(track(next))
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req, _ = withState(req)
+			h.ServeHTTP(w, req)
+		})
+	}
+}
+
+// Recovery recovers panics in the handlers after it and answers 500; see
+// recovery.Handler.
+func Recovery(opts ...recovery.Option) Middleware {
+	return func(next http.Handler) http.Handler {
+		h := recovery.Handler(track(next), opts...)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req, _ = withState(req)
+			h.ServeHTTP(w, req)
+		})
+	}
+}
+
+// Timeout gives the handlers after it d to respond: their context is
+// canceled after d, and the client gets a 503 if they have not finished
+// by then; see http.TimeoutHandler. Responses are buffered until the
+// handler returns, so leave it off streaming endpoints.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		h := http.TimeoutHandler(track(next), d, http.StatusText(http.StatusServiceUnavailable))
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req, _ = withState(req)
+			h.ServeHTTP(w, req)
+		})
+	}
+}
+
+// Tracing continues the trace of every request from its headers in a
+// server span named after the method, as the httpx client names its
+// spans, with the route as the http.route attribute. Responses with a
+// 5xx status mark the span as failed; 4xx ones are the client's fault.
+func Tracing() Middleware {
+	return func(next http.Handler) http.Handler {
+		next = track(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req, st := withState(req)
+			ctx, span := trace.Start(trace.Extract(req.Context(), req.Header), req.Method,
+				trace.WithKind(trace.SpanKindServer),
+				trace.WithAttr("http.request.method", req.Method),
+				trace.WithAttr("url.path", req.URL.Path))
+			defer span.End()
+			rw := &responseWriter{ResponseWriter: w}
+			next.ServeHTTP(rw, req.WithContext(ctx))
+			if route := st.route(); route != "" {
+				span.SetAttr("http.route", route)
+			}
+			code := rw.code()
+			span.SetAttr("http.response.status_code", code)
+			if code >= 500 {
+				span.SetStatus(trace.StatusError, strconv.Itoa(code)+" "+http.StatusText(code))
+			}
+		})
+	}
+}
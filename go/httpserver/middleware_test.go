@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/recovery"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+}
+
+func attr(s *trace.SpanData, key string) any {
+	for _, a := range s.Attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return nil
+}
+
+func TestRequestID(t *testing.T) {
+	h := Chain(mux(), RequestID())
+	if w := serveReq(h, "GET", "/users/1", nil); w.Header().Get(ctxmeta.RequestIDHeader) == "" {
+		t.Error("no request ID generated")
+	}
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set(ctxmeta.RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if got := w.Header().Get(ctxmeta.RequestIDHeader); got != "req-42" {
+		t.Errorf("request ID = %q", got)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	rec := logtest.Capture(t)
+	h := Chain(mux(), Recovery(recovery.WithLogger(rec.Logger())))
+	if w := serveReq(h, "GET", "/panic", nil); w.Code != http.StatusInternalServerError {
+		t.Errorf("code = %d", w.Code)
+	}
+	if rec.WithEvent("panic_recovered").Count() != 1 {
+		t.Errorf("events = %v", rec.Records().Events())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	h := Chain(mux(), Timeout(20*time.Millisecond))
+	start := time.Now()
+	w := serveReq(h, "GET", "/slow", nil)
+	if w.Code != http.StatusServiceUnavailable || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("slow request answered %d after %v", w.Code, time.Since(start))
+	}
+	if w := serveReq(h, "GET", "/users/1", nil); w.Code != http.StatusOK {
+		t.Errorf("fast request answered %d", w.Code)
+	}
+}
+
+func TestTracing(t *testing.T) {
+	rec := &spanRecorder{}
+	trace.SetExporter(rec)
+	defer trace.SetExporter(nil)
+
+	var parent trace.SpanContext
+	var req *http.Request
+	trace.WithSpan(context.Background(), "client", func(ctx context.Context) error {
+		parent = trace.SpanContextFromContext(ctx)
+		req = httptest.NewRequest("GET", "/users/3", nil)
+		trace.Inject(ctx, req.Header)
+		return nil
+	})
+	rec.spans = nil
+
+	h := Chain(mux(), Tracing(), Timeout(time.Second))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(rec.spans) != 1 {
+		t.Fatalf("spans = %+v", rec.spans)
+	}
+	s := rec.spans[0]
+	if s.Name != "GET" || s.Kind != trace.SpanKindServer || s.Parent != parent.SpanID || s.SpanContext.TraceID != parent.TraceID {
+		t.Errorf("span = %+v", s)
+	}
+	if attr(s, "http.route") != "/users/{id}" || attr(s, "http.response.status_code") != http.StatusOK {
+		t.Errorf("attrs = %+v", s.Attrs)
+	}
+}
@@ -0,0 +1,225 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/shutdown"
+)
+
+// Server timeouts applied when Timeouts leaves them at zero. Reads and
+// writes of whole requests are not bounded by default, since that would
+// cut off uploads and streams; bound handlers with Timeout instead.
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
+)
+
+// Timeouts are the connection timeouts of a Server, e.g. bound with
+// config.Bind under an "http.server" section; see http.Server.
+type Timeouts struct {
+	ReadHeader time.Duration `config:"read_header_timeout" default:"10s" desc:"Time allowed to read request headers."`
+	Read       time.Duration `config:"read_timeout" desc:"Time allowed to read a whole request; unlimited when 0."`
+	Write      time.Duration `config:"write_timeout" desc:"Time allowed to write a response; unlimited when 0."`
+	Idle       time.Duration `config:"idle_timeout" default:"2m" desc:"Time keep-alive connections may stay idle."`
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithLogger sets the logger for the server lifecycle and the errors of
+// the net/http server. The default is log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(s *Server) { s.logger = l }
+}
+
+// WithCoordinator sets the shutdown coordinator the server registers with
+// when it starts serving. The default is shutdown.Default.
+func WithCoordinator(c *shutdown.Coordinator) Option {
+	return func(s *Server) { s.coordinator = c }
+}
+
+// WithTLSConfig serves HTTPS with cfg, which must hold the certificates.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(s *Server) { s.srv.TLSConfig = cfg }
+}
+
+// WithTimeouts sets the connection timeouts.
+func WithTimeouts(t Timeouts) Option {
+	return func(s *Server) {
+		s.srv.ReadHeaderTimeout = t.ReadHeader
+		s.srv.ReadTimeout = t.Read
+		s.srv.WriteTimeout = t.Write
+		s.srv.IdleTimeout = t.Idle
+	}
+}
+
+// Server is an http.Server shut down gracefully: once serving, it
+// registers with the shutdown coordinator at shutdown.PriorityServers,
+// so on SIGTERM it stops accepting connections and waits for the
+// requests in flight before workers and resources are stopped. It
+// implements container.Starter and container.Stopper, so it can also be
+// a component of a container.App.
+type Server struct {
+	srv         *http.Server
+	logger      *log.Logger
+	coordinator *shutdown.Coordinator
+
+	mu       sync.Mutex
+	ln       net.Listener
+	stopOnce sync.Once
+	stopped  chan struct{}
+	stopErr  error
+}
+
+// New returns a server for h on addr, such as ":8080".
+func New(addr string, h http.Handler, opts ...Option) *Server {
+	s := &Server{
+		srv:         &http.Server{Addr: addr, Handler: h},
+		coordinator: shutdown.Default,
+		stopped:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.srv.ReadHeaderTimeout == 0 {
+		s.srv.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if s.srv.IdleTimeout == 0 {
+		s.srv.IdleTimeout = DefaultIdleTimeout
+	}
+	s.srv.ErrorLog = slog.NewLogLogger(log.NewSlogHandler(s.log()), slog.LevelWarn)
+	return s
+}
+
+// Addr returns the address the server listens on, with the port picked
+// by the system for ":0", or the configured address before it listens.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln != nil {
+		return s.ln.Addr().String()
+	}
+	return s.srv.Addr
+}
+
+// ListenAndServe listens on the address of the server and serves until
+// it is shut down, by Shutdown, the coordinator or the end of ctx. It
+// returns nil once the requests in flight are done, or the error that
+// stopped it.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	ln, err := s.listen()
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve is ListenAndServe on ln.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	if s.srv.TLSConfig != nil {
+		ln = tls.NewListener(ln, s.srv.TLSConfig)
+	}
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+	s.srv.BaseContext = func(net.Listener) context.Context { return context.WithoutCancel(ctx) }
+	s.coordinator.Register("http server "+ln.Addr().String(), shutdown.PriorityServers, s.Shutdown)
+	go func() {
+		select {
+		case <-ctx.Done():
+			sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdown.DefaultTimeout)
+			defer cancel()
+			s.Shutdown(sctx)
+		case <-s.stopped:
+		}
+	}()
+
+	s.log().InfoCtx(ctx, "http_server_started", "server.address", ln.Addr().String(), "tls", s.srv.TLSConfig != nil)
+	err := s.srv.Serve(ln)
+	if !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("httpserver: serving on %s: %w", ln.Addr(), err)
+	}
+	<-s.stopped
+	return s.stopErr
+}
+
+// OnStart listens and serves in the background, so the server can be a
+// container component. Listen errors, such as a port in use, are
+// returned.
+func (s *Server) OnStart(ctx context.Context) error {
+	ln, err := s.listen()
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := s.Serve(context.WithoutCancel(ctx), ln); err != nil {
+			s.log().ErrorCtx(ctx, "http_server_failed", "server.address", ln.Addr().String(), log.Err(err))
+		}
+	}()
+	return nil
+}
+
+// OnStop is Shutdown.
+func (s *Server) OnStop(ctx context.Context) error { return s.Shutdown(ctx) }
+
+// Shutdown stops accepting connections and waits for the requests in
+// flight until ctx is done, when the remaining connections are closed.
+// Later calls wait for the first one.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		go func() {
+			start := time.Now()
+			err := s.srv.Shutdown(ctx)
+			if err != nil {
+				s.srv.Close()
+				err = fmt.Errorf("httpserver: shutting down %s: %w", s.Addr(), err)
+			}
+			s.stopErr = err
+			s.log().InfoCtx(ctx, "http_server_stopped", "server.address", s.Addr(), "duration_ms", time.Since(start).Milliseconds())
+			close(s.stopped)
+		}()
+	})
+	select {
+	case <-s.stopped:
+		return s.stopErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server) listen() (net.Listener, error) {
+	addr := s.srv.Addr
+	if addr == "" {
+		addr = ":http"
+		if s.srv.TLSConfig != nil {
+			addr = ":https"
+		}
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("httpserver: %w", err)
+	}
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+	return ln, nil
+}
+
+func (s *Server) log() *log.Logger {
+	if s.logger != nil {
+		return s.logger
+	}
+	return log.Default()
+}
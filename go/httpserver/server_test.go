@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/shutdown"
+)
+
+func TestServerGracefulShutdown(t *testing.T) {
+	rec := logtest.Capture(t)
+	coord := shutdown.New()
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := New("127.0.0.1:0", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	}), WithCoordinator(coord), WithLogger(rec.Logger()))
+
+	served := make(chan error, 1)
+	go func() { served <- srv.ListenAndServe(context.Background()) }()
+	for srv.Addr() == "127.0.0.1:0" {
+		time.Sleep(time.Millisecond)
+	}
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + srv.Addr())
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		body <- string(data)
+	}()
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- coord.Shutdown(context.Background()) }()
+	select {
+	case err := <-served:
+		t.Fatalf("server returned with a request in flight: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if got := <-body; got != "done" {
+		t.Errorf("in-flight request got %q", got)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("ListenAndServe = %v", err)
+	}
+	if err := <-stopped; err != nil {
+		t.Errorf("Shutdown = %v", err)
+	}
+	if rec.WithEvent("http_server_started").Count() != 1 || rec.WithEvent("http_server_stopped").Count() != 1 {
+		t.Errorf("events = %v", rec.Records().Events())
+	}
+}
+
+func TestServerContextAndLifecycle(t *testing.T) {
+	logtest.Capture(t)
+	srv := New("127.0.0.1:0", mux(), WithCoordinator(shutdown.New()))
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.ListenAndServe(ctx) }()
+	for srv.Addr() == "127.0.0.1:0" {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("ListenAndServe after cancel = %v", err)
+	}
+
+	srv = New("127.0.0.1:0", mux(), WithCoordinator(shutdown.New()))
+	if err := srv.OnStart(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get("http://" + srv.Addr() + "/users/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if err := srv.OnStop(context.Background()); err != nil {
+		t.Errorf("OnStop = %v", err)
+	}
+	if _, err := http.Get("http://" + srv.Addr() + "/users/1"); err == nil {
+		t.Error("server still answering after OnStop")
+	}
+
+	other := New("127.0.0.1:0", mux(), WithCoordinator(shutdown.New()))
+	if err := other.OnStart(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer other.OnStop(context.Background())
+	busy := New(other.Addr(), mux(), WithCoordinator(shutdown.New()))
+	if err := busy.OnStart(context.Background()); err == nil {
+		t.Error("OnStart on a port in use succeeded")
+	}
+}
@@ -0,0 +1,257 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKey sets a static API key header, e.g. APIKey("X-API-Key", key).
+func APIKey(header, key string) Middleware {
+	return setHeader(func(*http.Request) (string, string, error) { return header, key, nil })
+}
+
+// Bearer sends a static bearer token.
+func Bearer(token string) Middleware {
+	return setHeader(func(*http.Request) (string, string, error) { return "Authorization", "Bearer " + token, nil })
+}
+
+// setHeader sets a header on a copy of each request, leaving the caller's
+// request untouched as http.RoundTripper requires.
+func setHeader(value func(*http.Request) (key, value string, err error)) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			k, v, err := value(req)
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set(k, v)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// Token is an OAuth2 access token.
+type Token struct {
+	AccessToken string
+	TokenType   string // "Bearer" when empty
+	Expiry      time.Time
+}
+
+// ExpiryLeeway is how long before its expiry a token is refreshed, so
+// requests in flight do not carry a token that expires on the way.
+const ExpiryLeeway = 10 * time.Second
+
+// valid reports whether t can still be used at now.
+func (t Token) valid(now time.Time) bool {
+	return t.AccessToken != "" && (t.Expiry.IsZero() || now.Add(ExpiryLeeway).Before(t.Expiry))
+}
+
+// TokenSource supplies access tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// invalidator is implemented by token sources that can drop a token the
+// server rejected.
+type invalidator interface {
+	Invalidate()
+}
+
+// BearerFrom authenticates requests with tokens from src. If the server
+// answers 401 and src caches tokens, as ClientCredentials does, the token
+// is dropped and the request is retried once with a fresh one, provided
+// its body can be replayed.
+func BearerFrom(src TokenSource) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		send := func(req *http.Request) (*http.Response, error) {
+			tok, err := src.Token(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("httpx: fetching token: %w", err)
+			}
+			typ := tok.TokenType
+			if typ == "" || strings.EqualFold(typ, "bearer") {
+				typ = "Bearer"
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", typ+" "+tok.AccessToken)
+			return next.RoundTrip(req)
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := send(req)
+			inv, ok := src.(invalidator)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized || !ok {
+				return resp, err
+			}
+			replay := req
+			if req.Body != nil && req.Body != http.NoBody {
+				if req.GetBody == nil {
+					return resp, nil
+				}
+				body, err := req.GetBody()
+				if err != nil {
+					return resp, nil
+				}
+				replay = req.Clone(req.Context())
+				replay.Body = body
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			inv.Invalidate()
+			return send(replay)
+		})
+	}
+}
+
+// OAuthError is an error response from a token endpoint.
+type OAuthError struct {
+	StatusCode  int
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("httpx: token endpoint returned %d %s: %s", e.StatusCode, e.Code, e.Description)
+	}
+	return fmt.Sprintf("httpx: token endpoint returned %d %s", e.StatusCode, e.Code)
+}
+
+// tokenFetchTimeout bounds a token request, which runs detached from the
+// caller that triggered it.
+const tokenFetchTimeout = 30 * time.Second
+
+// ClientCredentials is a TokenSource for the OAuth2 client-credentials
+// grant. Tokens are cached until shortly before they expire, and
+// concurrent callers share a single fetch. Set the exported fields before
+// first use.
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Params are extra form values, e.g. "audience".
+	Params url.Values
+	// HTTPClient sends token requests; http.DefaultClient when nil. Do
+	// not route it through BearerFrom with this source.
+	HTTPClient *http.Client
+
+	now func() time.Time
+
+	mu     sync.Mutex
+	token  Token
+	flight *tokenFlight
+}
+
+type tokenFlight struct {
+	done  chan struct{}
+	token Token
+	err   error
+}
+
+// Token returns the cached token or fetches a new one. Callers waiting on
+// a fetch give up when their own ctx is done; the fetch itself continues
+// for the others.
+func (c *ClientCredentials) Token(ctx context.Context) (Token, error) {
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	c.mu.Lock()
+	if c.token.valid(now()) {
+		tok := c.token
+		c.mu.Unlock()
+		return tok, nil
+	}
+	f := c.flight
+	if f == nil {
+		f = &tokenFlight{done: make(chan struct{})}
+		c.flight = f
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tokenFetchTimeout)
+		go func() {
+			defer cancel()
+			f.token, f.err = c.fetch(fetchCtx, now())
+			c.mu.Lock()
+			if f.err == nil {
+				c.token = f.token
+			}
+			c.flight = nil
+			c.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	c.mu.Unlock()
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return Token{}, ctx.Err()
+	}
+}
+
+// Invalidate drops the cached token so the next call fetches a new one.
+func (c *ClientCredentials) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = Token{}
+}
+
+func (c *ClientCredentials) fetch(ctx context.Context, now time.Time) (Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	for k, vs := range c.Params {
+		form[k] = vs
+	}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return Token{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Token{}, err
+	}
+	var payload struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	if resp.StatusCode != http.StatusOK || payload.Error != "" {
+		return Token{}, &OAuthError{StatusCode: resp.StatusCode, Code: payload.Error, Description: payload.ErrorDescription}
+	}
+	if payload.AccessToken == "" {
+		return Token{}, fmt.Errorf("httpx: token endpoint response has no access_token")
+	}
+	tok := Token{AccessToken: payload.AccessToken, TokenType: payload.TokenType}
+	if secs, err := payload.ExpiresIn.Int64(); err == nil && secs > 0 {
+		tok.Expiry = now.Add(time.Duration(secs) * time.Second)
+	}
+	return tok, nil
+}
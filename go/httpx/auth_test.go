@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStaticAuth(t *testing.T) {
+	var got http.Header
+	rt := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Request: req}, nil
+	})
+	c, _ := New("http://api.example", WithTransport(rt), WithMiddleware(APIKey("X-API-Key", "k1"), Bearer("t1")))
+	if _, err := c.Get(context.Background(), "/notifications"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("X-API-Key") != "k1" || got.Get("Authorization") != "Bearer t1" {
+		t.Errorf("headers = %v", got)
+	}
+}
+
+func tokenServer(t *testing.T, fetches *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		r.ParseForm()
+		if id != "svc" || secret != "s3cret" || r.Form.Get("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client","error_description":"bad credentials"}`)
+			return
+		}
+		n := fetches.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprintf(w, `{"access_token":"tok-%d","token_type":"bearer","expires_in":3600}`, n)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientCredentialsSingleFlight(t *testing.T) {
+	var fetches atomic.Int32
+	srv := tokenServer(t, &fetches)
+	now := time.Now()
+	src := &ClientCredentials{TokenURL: srv.URL, ClientID: "svc", ClientSecret: "s3cret", now: func() time.Time { return now }}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tok, err := src.Token(context.Background())
+			if err != nil || tok.AccessToken != "tok-1" {
+				t.Errorf("Token = %+v, %v", tok, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d, want 1", n)
+	}
+
+	now = now.Add(3600*time.Second - ExpiryLeeway)
+	if tok, _ := src.Token(context.Background()); tok.AccessToken != "tok-2" {
+		t.Errorf("token near expiry not refreshed: %+v", tok)
+	}
+}
+
+func TestClientCredentialsError(t *testing.T) {
+	var fetches atomic.Int32
+	srv := tokenServer(t, &fetches)
+	src := &ClientCredentials{TokenURL: srv.URL, ClientID: "svc", ClientSecret: "wrong"}
+	_, err := src.Token(context.Background())
+	var oe *OAuthError
+	if !errors.As(err, &oe) || oe.Code != "invalid_client" || oe.StatusCode != 401 {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestBearerFromRetriesUnauthorized(t *testing.T) {
+	var fetches atomic.Int32
+	tokens := tokenServer(t, &fetches)
+	var bodies []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer tok-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer api.Close()
+
+	src := &ClientCredentials{TokenURL: tokens.URL, ClientID: "svc", ClientSecret: "s3cret", Scopes: []string{"notify"}}
+	c, _ := New(api.URL, WithMiddleware(BearerFrom(src)))
+	resp, err := c.Post(context.Background(), "/notifications", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusAccepted || fetches.Load() != 2 {
+		t.Errorf("status = %d, fetches = %d", resp.StatusCode, fetches.Load())
+	}
+	if len(bodies) != 2 || bodies[1] != "hello" {
+		t.Errorf("bodies = %q", bodies)
+	}
+}
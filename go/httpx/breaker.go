@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/provide-io/provide-foundation/go/resilience"
+)
+
+// Breaker guards requests with cb. Transport errors and 5xx responses
+// count as failures; while the circuit is open requests fail at once with
+// an error matching resilience.ErrCircuitOpen. Place it outside Retry so
+// that exhausted retries count as one failure.
+func Breaker(cb *resilience.CircuitBreaker) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			done, err := cb.Allow()
+			if err != nil {
+				return nil, err
+			}
+			resp, err := next.RoundTrip(req)
+			switch {
+			case err != nil:
+				done(err)
+			case resp.StatusCode >= 500:
+				done(fmt.Errorf("httpx: %s %s: status %d", req.Method, req.URL.Redacted(), resp.StatusCode))
+			default:
+				done(nil)
+			}
+			return resp, err
+		})
+	}
+}
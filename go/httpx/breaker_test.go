@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/resilience"
+)
+
+func TestBreakerFailsFast(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	cb := resilience.NewCircuitBreaker("notifications", resilience.WithFailureThreshold(2), resilience.WithRecoveryTimeout(time.Hour))
+	c, _ := New(srv.URL, WithMiddleware(Breaker(cb)))
+	ctx := context.Background()
+	c.Post(ctx, "/notifications", nil)
+	c.Post(ctx, "/notifications", nil)
+	_, err := c.Post(ctx, "/notifications", nil)
+	if !errors.Is(err, resilience.ErrCircuitOpen) || calls.Load() != 2 {
+		t.Errorf("err %v calls %d", err, calls.Load())
+	}
+}
@@ -0,0 +1,273 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package httpx is the HTTP transport of the Go foundation, the
+// counterpart of the Python transport client. A Client wraps an
+// *http.Client with a base URL, default headers and per-request timeouts:
+//
+//	client, err := httpx.New("https://api.example.com", httpx.WithTimeout(30*time.Second))
+//	resp, err := client.Post(ctx, "/notifications", strings.NewReader(body),
+//		httpx.WithHeader("Content-Type", "application/json"))
+//
+// Every call takes a context, reads the whole response body, and returns
+// a *StatusError for 4xx and 5xx responses. Each attempt is a client span
+// of the trace in the context, propagated with the traceparent header,
+// and the request metadata of the context (see package ctxmeta) is sent
+// along in its headers.
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+// DefaultTimeout bounds a request when no timeout is configured.
+const DefaultTimeout = 30 * time.Second
+
+// Option configures a Client created by New.
+type Option func(*Client)
+
+// WithTimeout sets the default timeout of each request, including reading
+// the response body. Zero disables it.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.timeout = d }
+}
+
+// WithHTTPClient sets the underlying client, e.g. to share a connection
+// pool. Its Timeout field still applies in addition to WithTimeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.hc = hc }
+}
+
+// WithTransport sets the round tripper requests are sent through. The
+// default is http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.transport = rt }
+}
+
+// WithDefaultHeader adds a header sent with every request.
+func WithDefaultHeader(key, value string) Option {
+	return func(c *Client) { c.header.Add(key, value) }
+}
+
+// Client sends HTTP requests relative to a base URL. It is safe for
+// concurrent use.
+type Client struct {
+	base      *url.URL
+	hc        *http.Client
+	transport http.RoundTripper
+	header    http.Header
+	timeout   time.Duration
+
+	mu         sync.RWMutex
+	middleware []Middleware
+	chain      http.RoundTripper
+}
+
+// New creates a client for baseURL. Request paths are joined to it, so a
+// base of https://api.example.com/v1 and a path of /notifications send to
+// https://api.example.com/v1/notifications. An empty base requires
+// absolute request URLs.
+//
+// Besides http and https, baseURL may use the unix and h2c schemes:
+// unix:///var/run/api.sock sends every request over that socket, with
+// request paths relative to its root, and h2c://sidecar:8080 speaks HTTP/2
+// without TLS. A transport set with WithTransport replaces the one these
+// schemes select and must then reach the endpoint itself.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: invalid base URL: %w", err)
+	}
+	if baseURL != "" && !base.IsAbs() {
+		return nil, fmt.Errorf("httpx: base URL %q is not absolute", baseURL)
+	}
+	if base.Scheme == "unix" && base.Path == "" {
+		return nil, fmt.Errorf("httpx: base URL %q has no socket path", baseURL)
+	}
+	base, routed := routeScheme(base)
+	c := &Client{base: base, header: make(http.Header), timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(c)
+	}
+	hc := &http.Client{}
+	if c.hc != nil {
+		*hc = *c.hc
+	}
+	if c.transport == nil && routed != nil {
+		c.transport = routed
+	}
+	if c.transport == nil {
+		c.transport = hc.Transport
+	}
+	if c.transport == nil {
+		c.transport = http.DefaultTransport
+	}
+	c.transport = metadata(tracing(c.transport))
+	c.chain = buildChain(c.transport, c.middleware)
+	hc.Transport = RoundTripperFunc(c.roundTrip)
+	c.hc = hc
+	return c, nil
+}
+
+// Response is a completed HTTP response with its body read.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Request    *http.Request
+}
+
+// StatusError is returned for responses with a 4xx or 5xx status. The
+// response is returned alongside it.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("httpx: %s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Class returns the errorsx class of the status, so a 404 is NotFound and
+// a 503 Unavailable.
+func (e *StatusError) Class() errorsx.Class { return errorsx.FromHTTPStatus(e.StatusCode) }
+
+// RequestOption configures a single request.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header  http.Header
+	query   url.Values
+	timeout *time.Duration
+}
+
+// WithHeader sets a header on one request, replacing a default header of
+// the same name.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) { o.header.Set(key, value) }
+}
+
+// WithQuery adds a query parameter to one request.
+func WithQuery(key, value string) RequestOption {
+	return func(o *requestOptions) { o.query.Add(key, value) }
+}
+
+// WithRequestTimeout overrides the client timeout for one request.
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) { o.timeout = &d }
+}
+
+// Get sends a GET request.
+func (c *Client) Get(ctx context.Context, path string, opts ...RequestOption) (*Response, error) {
+	return c.Do(ctx, http.MethodGet, path, nil, opts...)
+}
+
+// Post sends a POST request with body, which may be nil.
+func (c *Client) Post(ctx context.Context, path string, body io.Reader, opts ...RequestOption) (*Response, error) {
+	return c.Do(ctx, http.MethodPost, path, body, opts...)
+}
+
+// Put sends a PUT request with body, which may be nil.
+func (c *Client) Put(ctx context.Context, path string, body io.Reader, opts ...RequestOption) (*Response, error) {
+	return c.Do(ctx, http.MethodPut, path, body, opts...)
+}
+
+// Patch sends a PATCH request with body, which may be nil.
+func (c *Client) Patch(ctx context.Context, path string, body io.Reader, opts ...RequestOption) (*Response, error) {
+	return c.Do(ctx, http.MethodPatch, path, body, opts...)
+}
+
+// Delete sends a DELETE request.
+func (c *Client) Delete(ctx context.Context, path string, opts ...RequestOption) (*Response, error) {
+	return c.Do(ctx, http.MethodDelete, path, nil, opts...)
+}
+
+// Do sends a request and reads its response. Transport failures, including
+// timeouts, are returned as errors matching context.DeadlineExceeded or
+// the underlying network error; 4xx and 5xx responses return both the
+// response and a *StatusError.
+func (c *Client) Do(ctx context.Context, method, path string, body io.Reader, opts ...RequestOption) (*Response, error) {
+	o := requestOptions{header: make(http.Header), query: make(url.Values)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	u, err := c.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.query) > 0 {
+		q := u.Query()
+		for k, vs := range o.query {
+			q[k] = append(q[k], vs...)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	timeout := c.timeout
+	if o.timeout != nil {
+		timeout = *o.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: %w", err)
+	}
+	for k, vs := range c.header {
+		req.Header[k] = append([]string(nil), vs...)
+	}
+	for k, vs := range o.header {
+		req.Header[k] = vs
+	}
+	return c.send(req)
+}
+
+// send performs req and reads the response body.
+func (c *Client) send(req *http.Request) (*Response, error) {
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: %w", err)
+	}
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return nil, fmt.Errorf("httpx: reading response of %s %s: %w", req.Method, req.URL.Redacted(), err)
+	}
+	r := &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: buf.Bytes(), Request: req}
+	if resp.StatusCode >= 400 {
+		return r, &StatusError{Method: req.Method, URL: req.URL.Redacted(), StatusCode: resp.StatusCode, Body: r.Body}
+	}
+	return r, nil
+}
+
+// resolve joins path to the base URL. Absolute URLs are used as given.
+func (c *Client) resolve(path string) (*url.URL, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: invalid path %q: %w", path, err)
+	}
+	if ref.IsAbs() {
+		return ref, nil
+	}
+	if !c.base.IsAbs() {
+		return nil, fmt.Errorf("httpx: %q is not absolute and the client has no base URL", path)
+	}
+	u := c.base.JoinPath(ref.Path)
+	u.RawQuery = ref.RawQuery
+	return u, nil
+}
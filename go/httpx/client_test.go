@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientMethods(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Seen", r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("X-Service")+" "+r.Header.Get("X-Trace"))
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL+"/v1", WithDefaultHeader("X-Service", "users"), WithDefaultHeader("X-Trace", "default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, tc := range []struct {
+		call func() (*Response, error)
+		seen string
+		body string
+	}{
+		{func() (*Response, error) { return c.Get(ctx, "/users/1", WithQuery("fields", "name")) }, "GET /v1/users/1?fields=name users default", ""},
+		{func() (*Response, error) {
+			return c.Post(ctx, "notifications", strings.NewReader("hi"), WithHeader("X-Trace", "t1"))
+		}, "POST /v1/notifications users t1", "hi"},
+		{func() (*Response, error) { return c.Put(ctx, "/users/1?force=1", strings.NewReader("x")) }, "PUT /v1/users/1?force=1 users default", "x"},
+		{func() (*Response, error) { return c.Delete(ctx, srv.URL+"/abs") }, "DELETE /abs users default", ""},
+	} {
+		resp, err := tc.call()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Header.Get("X-Seen"); got != tc.seen || string(resp.Body) != tc.body {
+			t.Errorf("seen %q body %q, want %q %q", got, resp.Body, tc.seen, tc.body)
+		}
+	}
+}
+
+func TestClientStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such user", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL)
+	resp, err := c.Get(context.Background(), "/users/9")
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusNotFound || resp == nil || !strings.Contains(string(resp.Body), "no such user") {
+		t.Fatalf("resp %v, err %v", resp, err)
+	}
+	if !strings.HasSuffix(se.Error(), "/users/9: 404 Not Found") {
+		t.Errorf("message = %q", se.Error())
+	}
+}
+
+func TestClientTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c, _ := New(srv.URL, WithTimeout(time.Minute))
+	start := time.Now()
+	_, err := c.Get(context.Background(), "/slow", WithRequestTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("request timeout not applied")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Get(ctx, "/slow"); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled err = %v", err)
+	}
+}
+
+func TestNewRejectsRelativeBase(t *testing.T) {
+	if _, err := New("api.example.com"); err == nil {
+		t.Error("expected an error")
+	}
+	c, _ := New("")
+	if _, err := c.Get(context.Background(), "/users"); err == nil {
+		t.Error("expected an error for a relative path without base")
+	}
+}
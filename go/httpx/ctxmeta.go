@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"net/http"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+)
+
+// metadata sends the request metadata of the request context, such as the
+// request ID, to the server in the ctxmeta headers. Headers the caller
+// set on the request are kept.
+func metadata(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if ctxmeta.FromContext(req.Context()).IsZero() {
+			return next.RoundTrip(req)
+		}
+		h := make(http.Header)
+		ctxmeta.Inject(req.Context(), h)
+		req = req.Clone(req.Context())
+		for k, v := range h {
+			if _, ok := req.Header[k]; !ok {
+				req.Header[k] = v
+			}
+		}
+		return next.RoundTrip(req)
+	})
+}
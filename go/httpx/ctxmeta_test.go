@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+)
+
+func TestClientPropagatesMetadata(t *testing.T) {
+	var got []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+	}))
+	defer srv.Close()
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := ctxmeta.WithRequestID(context.Background(), "req-1")
+	ctx = ctxmeta.WithBaggage(ctx, "plan", "pro")
+	if _, err := c.Get(ctx, "/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "/", WithHeader(ctxmeta.RequestIDHeader, "explicit")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Get(ctxmeta.RequestIDHeader) != "req-1" || got[0].Get(ctxmeta.BaggageHeader) != "plan=pro" {
+		t.Errorf("headers %v", got[0])
+	}
+	if got[1].Get(ctxmeta.RequestIDHeader) != "explicit" {
+		t.Errorf("explicit request ID replaced: %v", got[1])
+	}
+	if got[2].Get(ctxmeta.RequestIDHeader) != "" {
+		t.Errorf("request ID without metadata: %v", got[2])
+	}
+}
@@ -0,0 +1,314 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+// MetricHedges counts the backup requests considered by Hedge, labeled
+// by host and result: won or lost against the first request, or
+// throttled when the budget did not allow one.
+const MetricHedges = "http_client_hedges_total"
+
+// Defaults of Hedge.
+const (
+	DefaultHedgePercentile = 0.95
+	DefaultHedgeBudget     = 0.1
+)
+
+const (
+	hedgeWindow     = 1000 // latencies kept for the percentile
+	hedgeMinSamples = 20   // latencies needed before hedging
+	hedgeBurst      = 10   // budget tokens saved up at most
+)
+
+// HedgeOption configures Hedge.
+type HedgeOption func(*hedge)
+
+// WithHedgePercentile sends the backup request once the first has taken
+// longer than the p-th percentile, between 0 and 1, of recent request
+// latencies. The default is DefaultHedgePercentile.
+func WithHedgePercentile(p float64) HedgeOption {
+	return func(h *hedge) { h.percentile = p }
+}
+
+// WithHedgeDelay sends the backup request after a fixed delay instead of
+// a percentile.
+func WithHedgeDelay(d time.Duration) HedgeOption {
+	return func(h *hedge) { h.delay = d }
+}
+
+// WithHedgeBudget caps backup requests at ratio of all requests, such as
+// 0.05 for at most 5% extra load. The default is DefaultHedgeBudget.
+func WithHedgeBudget(ratio float64) HedgeOption {
+	return func(h *hedge) { h.ratio = ratio }
+}
+
+// WithHedgeMethods sets the methods hedged. The default is GET, HEAD and
+// OPTIONS, as a backup request sends a request twice.
+func WithHedgeMethods(methods ...string) HedgeOption {
+	return func(h *hedge) { h.methods = methods }
+}
+
+// WithHedgeRetryStatus sets the statuses that do not win a race while the
+// other request is running, as Retry would retry them. The default is
+// retry.DefaultRetryStatus; pass the RetryStatus of the Retry policy
+// when it has its own.
+func WithHedgeRetryStatus(codes ...int) HedgeOption {
+	return func(h *hedge) { h.retryStatus = codes }
+}
+
+// WithHedgeMetrics counts backup requests in reg, or in metrics.Default
+// when reg is nil, as MetricHedges.
+func WithHedgeMetrics(reg *metrics.Registry) HedgeOption {
+	return func(h *hedge) {
+		if reg == nil {
+			reg = metrics.Default
+		}
+		h.hedges = reg.Counter(MetricHedges, "HTTP client backup requests.", "host", "result")
+	}
+}
+
+type hedge struct {
+	percentile  float64
+	delay       time.Duration
+	ratio       float64
+	methods     []string
+	retryStatus []int
+	hedges      *metrics.Counter
+
+	mu        sync.Mutex
+	tokens    float64
+	latencies []time.Duration // ring of the last hedgeWindow
+	next      int
+	stale     int           // latencies added since cutoff was computed
+	cutoff    time.Duration // the percentile, 0 until known
+}
+
+// Hedge cuts tail latency by sending a backup of a request that is slow
+// to answer, and returning whichever response comes first; the other
+// request is canceled. A request is slow once it has taken longer than a
+// percentile of the latencies seen so far, after a warm-up of some
+// requests, or than WithHedgeDelay. Backups are limited by a budget
+// relative to the requests made, so a struggling server does not get
+// twice the load.
+//
+// Hedge is retry-aware: a transport error or a response with a status
+// Retry would retry does not win while the other request may still
+// succeed, and when the first request fails before a backup is sent its
+// failure is returned at once, for Retry to act on. Place Hedge inside
+// Retry so every attempt is hedged. Requests with a body are hedged only
+// if it can be replayed through GetBody.
+func Hedge(opts ...HedgeOption) Middleware {
+	h := &hedge{
+		percentile:  DefaultHedgePercentile,
+		ratio:       DefaultHedgeBudget,
+		methods:     []string{http.MethodGet, http.MethodHead, http.MethodOptions},
+		retryStatus: retry.DefaultRetryStatus,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !slices.Contains(h.methods, req.Method) ||
+				(req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+				return next.RoundTrip(req)
+			}
+			return h.roundTrip(next, req)
+		})
+	}
+}
+
+// attempt is the outcome of one of the hedged requests: the first, 0, or
+// the backup, 1.
+type attempt struct {
+	i    int
+	resp *http.Response
+	err  error
+}
+
+func (h *hedge) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	delay, ok := h.start()
+	began := time.Now()
+	if !ok {
+		resp, err := next.RoundTrip(req)
+		if err == nil {
+			h.observe(time.Since(began))
+		}
+		return resp, err
+	}
+
+	ctx := req.Context()
+	results := make(chan attempt, 2)
+	var cancels []context.CancelFunc
+	send := func(r *http.Request) {
+		actx, cancel := context.WithCancel(ctx)
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		r = r.WithContext(actx)
+		go func() {
+			resp, err := next.RoundTrip(r)
+			results <- attempt{i, resp, err}
+		}()
+	}
+	send(req)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	timeout := timer.C
+	pending := 1
+	var failed *attempt
+	for {
+		select {
+		case <-timeout:
+			timeout = nil
+			if ctx.Err() != nil {
+				continue
+			}
+			if backup, ok := h.backup(req); ok {
+				pending++
+				send(backup)
+			}
+		case a := <-results:
+			pending--
+			if a.err == nil && !slices.Contains(h.retryStatus, a.resp.StatusCode) {
+				h.observe(time.Since(began))
+				return h.finish(req, a, cancels, results, pending)
+			}
+			// Keep the failure with a response, for Retry to read.
+			if failed == nil || (failed.resp == nil && a.resp != nil) {
+				if failed != nil {
+					discard(*failed)
+				}
+				failed = &a
+			} else {
+				discard(a)
+			}
+			if pending == 0 {
+				return h.finish(req, *failed, cancels, results, 0)
+			}
+		}
+	}
+}
+
+// finish returns the outcome of a, after canceling the other request and
+// draining the pending ones. The request of a is canceled once its body
+// is closed.
+func (h *hedge) finish(req *http.Request, a attempt, cancels []context.CancelFunc, results chan attempt, pending int) (*http.Response, error) {
+	for i, cancel := range cancels {
+		if i != a.i {
+			cancel()
+		}
+	}
+	if pending > 0 {
+		go func() {
+			for range pending {
+				discard(<-results)
+			}
+		}()
+	}
+	if len(cancels) > 1 && h.hedges != nil {
+		result := "lost"
+		if a.i == 1 && a.err == nil && !slices.Contains(h.retryStatus, a.resp.StatusCode) {
+			result = "won"
+		}
+		h.hedges.Inc(req.URL.Host, result)
+	}
+	if a.err != nil {
+		cancels[a.i]()
+		return nil, a.err
+	}
+	a.resp.Body = &cancelBody{ReadCloser: a.resp.Body, cancel: cancels[a.i]}
+	return a.resp, nil
+}
+
+// discard releases the response of a request that lost.
+func discard(a attempt) {
+	if a.resp != nil {
+		a.resp.Body.Close()
+	}
+}
+
+// cancelBody cancels the context of its request once closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// start adds the request to the budget and returns the delay before a
+// backup, or false if none may be sent yet.
+func (h *hedge) start() (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens = min(h.tokens+h.ratio, hedgeBurst)
+	if h.delay > 0 {
+		return h.delay, true
+	}
+	if len(h.latencies) < hedgeMinSamples {
+		return 0, false
+	}
+	if h.cutoff == 0 || h.stale >= hedgeMinSamples {
+		sorted := slices.Sorted(slices.Values(h.latencies))
+		i := int(math.Ceil(h.percentile*float64(len(sorted)))) - 1
+		h.cutoff = sorted[min(max(i, 0), len(sorted)-1)]
+		h.stale = 0
+	}
+	return h.cutoff, true
+}
+
+// backup returns a copy of req to send as the backup, if the budget
+// allows one and its body can be replayed.
+func (h *hedge) backup(req *http.Request) (*http.Request, bool) {
+	h.mu.Lock()
+	ok := h.tokens >= 1
+	if ok {
+		h.tokens--
+	}
+	h.mu.Unlock()
+	if !ok {
+		if h.hedges != nil {
+			h.hedges.Inc(req.URL.Host, "throttled")
+		}
+		return nil, false
+	}
+	r := req.Clone(req.Context())
+	if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		r.Body = body
+	}
+	return r, true
+}
+
+// observe records the latency of a successful request.
+func (h *hedge) observe(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.latencies) < hedgeWindow {
+		h.latencies = append(h.latencies, d)
+	} else {
+		h.latencies[h.next] = d
+		h.next = (h.next + 1) % hedgeWindow
+	}
+	h.stale++
+}
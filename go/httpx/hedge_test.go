@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+)
+
+// hedgeStub answers the nth request, counting from 1, with respond.
+func hedgeStub(calls *atomic.Int32, respond func(n int32, req *http.Request) (*http.Response, error)) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return respond(calls.Add(1), req)
+	})
+}
+
+func reply(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}
+}
+
+func hedgeCounts(reg *metrics.Registry) map[string]float64 {
+	got := map[string]float64{}
+	for _, f := range reg.Collect() {
+		for _, s := range f.Series {
+			got[s.LabelValues[1]] = s.Value
+		}
+	}
+	return got
+}
+
+func TestHedgeBackupWins(t *testing.T) {
+	var calls atomic.Int32
+	canceled := make(chan struct{})
+	rt := hedgeStub(&calls, func(n int32, req *http.Request) (*http.Response, error) {
+		if n == 1 {
+			<-req.Context().Done()
+			close(canceled)
+			return nil, req.Context().Err()
+		}
+		return reply(http.StatusOK, "backup"), nil
+	})
+	reg := metrics.NewRegistry()
+	c, _ := New("http://api.test", WithTransport(rt),
+		WithMiddleware(Hedge(WithHedgeDelay(5*time.Millisecond), WithHedgeBudget(1), WithHedgeMetrics(reg))))
+	resp, err := c.Get(context.Background(), "/users/1")
+	if err != nil || string(resp.Body) != "backup" {
+		t.Fatalf("Get = %v, %v", resp, err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow request not canceled")
+	}
+	if got := hedgeCounts(reg); got["won"] != 1 {
+		t.Errorf("%s = %v", MetricHedges, got)
+	}
+}
+
+func TestHedgeBudget(t *testing.T) {
+	var calls atomic.Int32
+	rt := hedgeStub(&calls, func(n int32, req *http.Request) (*http.Response, error) {
+		time.Sleep(20 * time.Millisecond)
+		return reply(http.StatusOK, "ok"), nil
+	})
+	reg := metrics.NewRegistry()
+	c, _ := New("http://api.test", WithTransport(rt),
+		WithMiddleware(Hedge(WithHedgeDelay(time.Millisecond), WithHedgeBudget(0.5), WithHedgeMetrics(reg))))
+	for range 4 {
+		if _, err := c.Get(context.Background(), "/users/1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Half a token per request pays for a backup every other request.
+	got := hedgeCounts(reg)
+	if got["throttled"] != 2 || got["won"]+got["lost"] != 2 {
+		t.Errorf("%s = %v", MetricHedges, got)
+	}
+
+	if _, err := c.Post(context.Background(), "/users", strings.NewReader("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if got := hedgeCounts(reg); got["throttled"] != 2 {
+		t.Errorf("POST was considered for hedging: %v", got)
+	}
+}
+
+func TestHedgeRetryAware(t *testing.T) {
+	var calls atomic.Int32
+	rt := hedgeStub(&calls, func(n int32, req *http.Request) (*http.Response, error) {
+		if n == 1 {
+			return reply(http.StatusServiceUnavailable, "overloaded"), nil
+		}
+		return reply(http.StatusOK, "ok"), nil
+	})
+	c, _ := New("http://api.test", WithTransport(rt),
+		WithMiddleware(Hedge(WithHedgeDelay(time.Hour), WithHedgeBudget(1))))
+	if _, err := c.Get(context.Background(), "/users/1"); err == nil || calls.Load() != 1 {
+		t.Errorf("early failure = %v after %d calls, want it returned at once", err, calls.Load())
+	}
+
+	// A retryable status does not win while the backup may succeed.
+	calls.Store(0)
+	rt = hedgeStub(&calls, func(n int32, req *http.Request) (*http.Response, error) {
+		if n == 1 {
+			time.Sleep(20 * time.Millisecond)
+			return reply(http.StatusServiceUnavailable, "overloaded"), nil
+		}
+		time.Sleep(40 * time.Millisecond)
+		return reply(http.StatusOK, "ok"), nil
+	})
+	c, _ = New("http://api.test", WithTransport(rt),
+		WithMiddleware(Hedge(WithHedgeDelay(5*time.Millisecond), WithHedgeBudget(1))))
+	resp, err := c.Get(context.Background(), "/users/1")
+	if err != nil || string(resp.Body) != "ok" {
+		t.Errorf("Get = %v, %v", resp, err)
+	}
+}
+
+func TestHedgePercentile(t *testing.T) {
+	var calls atomic.Int32
+	rt := hedgeStub(&calls, func(n int32, req *http.Request) (*http.Response, error) {
+		if n == hedgeMinSamples+1 {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}
+		return reply(http.StatusOK, "ok"), nil
+	})
+	c, _ := New("http://api.test", WithTransport(rt), WithMiddleware(Hedge(WithHedgeBudget(1))))
+	for range hedgeMinSamples {
+		c.Get(context.Background(), "/users/1")
+	}
+	if calls.Load() != hedgeMinSamples {
+		t.Fatalf("%d calls while warming up", calls.Load())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.Get(ctx, "/users/1"); err != nil {
+		t.Errorf("slow request not hedged: %v", err)
+	}
+}
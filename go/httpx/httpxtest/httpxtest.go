@@ -0,0 +1,289 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package httpxtest fakes HTTP dependencies in tests. A MockTransport
+// answers requests from canned routes and records every call, so code
+// built on an httpx.Client runs without a server:
+//
+//	mock := httpxtest.NewMockTransport(t)
+//	mock.On("POST", "/notifications").ReplyJSON(202, map[string]string{"status": "success"})
+//	client, _ := httpx.New("https://notify.example", httpx.WithTransport(mock))
+//	svc := NewNotificationService(client)
+//	svc.Send(ctx, 1, "user.created")
+//	mock.AssertCalls(t, "POST /notifications")
+package httpxtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// MockTransport is an http.RoundTripper answering from registered routes.
+// Routes are tried in registration order and the first match with
+// replies left answers. A request no route matches fails the test and
+// returns an error. It is safe for concurrent use.
+type MockTransport struct {
+	t testing.TB
+
+	mu     sync.Mutex
+	routes []*Route
+	calls  []Call
+}
+
+// NewMockTransport returns an empty transport. When the test ends it
+// fails the test for every route registered with Times whose calls did
+// not all happen.
+func NewMockTransport(t testing.TB) *MockTransport {
+	m := &MockTransport{t: t}
+	t.Cleanup(func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, r := range m.routes {
+			if r.times > 0 && r.calls != r.times {
+				t.Errorf("httpxtest: %s called %d times, want %d", r, r.calls, r.times)
+			}
+		}
+	})
+	return m
+}
+
+// On registers a route for method and path. An empty method matches any
+// method. path is compared with the request URL path; the query is
+// ignored unless matched with MatchQuery. The route answers 200 with an
+// empty body until a reply is set.
+func (m *MockTransport) On(method, path string) *Route {
+	r := &Route{method: method, path: path, status: http.StatusOK, header: make(http.Header)}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = append(m.routes, r)
+	return r
+}
+
+// RoundTrip records req and answers it from the first matching route.
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	call := Call{Method: req.Method, URL: req.URL, Header: req.Header.Clone(), Body: body}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	var route *Route
+	for _, r := range m.routes {
+		if (r.times == 0 || r.calls < r.times) && r.matches(req, body) {
+			r.calls++
+			route = r
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if route == nil {
+		m.t.Errorf("httpxtest: no route for %s", call)
+		return nil, fmt.Errorf("httpxtest: no route for %s", call)
+	}
+	if route.reply != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return route.reply(req)
+	}
+	if route.err != nil {
+		return nil, route.err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", route.status, http.StatusText(route.status)),
+		StatusCode:    route.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        route.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(route.body)),
+		ContentLength: int64(len(route.body)),
+		Request:       req,
+	}, nil
+}
+
+// Calls returns every request received, matched or not, in order.
+func (m *MockTransport) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.calls)
+}
+
+// CallsTo returns the calls with the given method and URL path.
+func (m *MockTransport) CallsTo(method, path string) []Call {
+	var out []Call
+	for _, c := range m.Calls() {
+		if c.Method == method && c.URL.Path == path {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// AssertCalls fails the test unless the calls received, written as
+// "METHOD /path", are exactly want in order.
+func (m *MockTransport) AssertCalls(t testing.TB, want ...string) {
+	t.Helper()
+	calls := m.Calls()
+	got := make([]string, len(calls))
+	for i, c := range calls {
+		got[i] = c.String()
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("httpxtest: calls = %q, want %q", got, want)
+	}
+}
+
+// Reset forgets the recorded calls and the call counts of routes.
+func (m *MockTransport) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+	for _, r := range m.routes {
+		r.calls = 0
+	}
+}
+
+// Call is a request received by a MockTransport.
+type Call struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// String returns "METHOD /path".
+func (c Call) String() string { return c.Method + " " + c.URL.Path }
+
+// DecodeJSON decodes the request body into v.
+func (c Call) DecodeJSON(v any) error { return json.Unmarshal(c.Body, v) }
+
+// Route is a canned answer for matching requests. Its methods configure it
+// and return it for chaining; configure a route before requests use it.
+type Route struct {
+	method   string
+	path     string
+	matchers []func(*http.Request, []byte) bool
+	times    int
+
+	status int
+	header http.Header
+	body   []byte
+	err    error
+	reply  func(*http.Request) (*http.Response, error)
+
+	calls int // guarded by MockTransport.mu
+}
+
+// String describes the route as "METHOD /path".
+func (r *Route) String() string {
+	method := r.method
+	if method == "" {
+		method = "*"
+	}
+	return method + " " + r.path
+}
+
+func (r *Route) matches(req *http.Request, body []byte) bool {
+	if r.method != "" && !strings.EqualFold(r.method, req.Method) {
+		return false
+	}
+	if r.path != req.URL.Path {
+		return false
+	}
+	for _, match := range r.matchers {
+		if !match(req, body) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match adds a custom matcher.
+func (r *Route) Match(fn func(req *http.Request, body []byte) bool) *Route {
+	r.matchers = append(r.matchers, fn)
+	return r
+}
+
+// MatchHeader matches requests whose header key equals value.
+func (r *Route) MatchHeader(key, value string) *Route {
+	return r.Match(func(req *http.Request, _ []byte) bool { return req.Header.Get(key) == value })
+}
+
+// MatchQuery matches requests whose query parameter key equals value.
+func (r *Route) MatchQuery(key, value string) *Route {
+	return r.Match(func(req *http.Request, _ []byte) bool { return req.URL.Query().Get(key) == value })
+}
+
+// MatchJSON matches requests whose body is JSON equal to v once both are
+// decoded, so field order and whitespace do not matter.
+func (r *Route) MatchJSON(v any) *Route {
+	want, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("httpxtest: MatchJSON: %v", err))
+	}
+	var wantv any
+	json.Unmarshal(want, &wantv)
+	return r.Match(func(_ *http.Request, body []byte) bool {
+		var got any
+		return json.Unmarshal(body, &got) == nil && reflect.DeepEqual(got, wantv)
+	})
+}
+
+// Times makes the route answer n requests, after which later routes for
+// the same request are tried; the test fails if fewer than n arrive.
+// Register several routes with Times(1) to answer a sequence, e.g. a 503
+// followed by a 200 for a retry test.
+func (r *Route) Times(n int) *Route {
+	r.times = n
+	return r
+}
+
+// Once is Times(1).
+func (r *Route) Once() *Route { return r.Times(1) }
+
+// Reply answers with status and body.
+func (r *Route) Reply(status int, body string) *Route {
+	r.status, r.body = status, []byte(body)
+	return r
+}
+
+// ReplyJSON answers with status and v encoded as JSON.
+func (r *Route) ReplyJSON(status int, v any) *Route {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("httpxtest: ReplyJSON: %v", err))
+	}
+	r.status, r.body = status, body
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+// ReplyHeader adds a response header.
+func (r *Route) ReplyHeader(key, value string) *Route {
+	r.header.Add(key, value)
+	return r
+}
+
+// ReplyError fails matching requests with err, as a transport failure.
+func (r *Route) ReplyError(err error) *Route {
+	r.err = err
+	return r
+}
+
+// ReplyFunc answers with fn, for responses that depend on the request.
+func (r *Route) ReplyFunc(fn func(req *http.Request) (*http.Response, error)) *Route {
+	r.reply = fn
+	return r
+}
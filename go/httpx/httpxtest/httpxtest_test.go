@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpxtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+type notification struct {
+	UserID int    `json:"user_id"`
+	Event  string `json:"event"`
+}
+
+func TestMockTransportRoutesAndRecords(t *testing.T) {
+	mock := NewMockTransport(t)
+	mock.On("POST", "/notifications").MatchJSON(notification{1, "user.created"}).ReplyJSON(202, map[string]string{"status": "success"})
+	mock.On("GET", "/users/1").MatchHeader("Authorization", "Bearer t").Reply(200, "ann")
+	mock.On("GET", "/down").ReplyError(errors.New("connection refused"))
+
+	c, _ := httpx.New("https://api.example", httpx.WithTransport(mock), httpx.WithDefaultHeader("Authorization", "Bearer t"))
+	ctx := context.Background()
+	ack, err := httpx.PostJSON[notification, map[string]string](ctx, c, "/notifications", notification{1, "user.created"})
+	if err != nil || ack["status"] != "success" {
+		t.Fatalf("PostJSON = %v, %v", ack, err)
+	}
+	if resp, err := c.Get(ctx, "/users/1", httpx.WithQuery("fields", "name")); err != nil || string(resp.Body) != "ann" {
+		t.Fatalf("Get = %v, %v", resp, err)
+	}
+	if _, err := c.Get(ctx, "/down"); err == nil {
+		t.Error("ReplyError did not fail the request")
+	}
+
+	mock.AssertCalls(t, "POST /notifications", "GET /users/1", "GET /down")
+	var sent notification
+	if err := mock.CallsTo("POST", "/notifications")[0].DecodeJSON(&sent); err != nil || sent.Event != "user.created" {
+		t.Errorf("payload = %+v, %v", sent, err)
+	}
+}
+
+func TestMockTransportSequence(t *testing.T) {
+	mock := NewMockTransport(t)
+	mock.On("GET", "/users/1").Once().Reply(503, "busy")
+	mock.On("GET", "/users/1").Once().Reply(200, "ann")
+
+	p := retry.DefaultPolicy
+	p.BaseDelay = time.Millisecond
+	c, _ := httpx.New("https://api.example", httpx.WithTransport(mock), httpx.WithMiddleware(httpx.Retry(p)))
+	resp, err := c.Get(context.Background(), "/users/1")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Get = %v, %v", resp, err)
+	}
+	if n := len(mock.Calls()); n != 2 {
+		t.Errorf("calls = %d", n)
+	}
+}
+
+func TestMockTransportUnmatched(t *testing.T) {
+	ft := &fakeT{TB: t}
+	mock := NewMockTransport(ft)
+	mock.On("GET", "/expected").Once()
+	c, _ := httpx.New("https://api.example", httpx.WithTransport(mock))
+	if _, err := c.Get(context.Background(), "/other"); err == nil {
+		t.Error("unmatched request succeeded")
+	}
+	ft.cleanup()
+	if len(ft.errors) != 2 {
+		t.Errorf("errors = %q", ft.errors)
+	}
+}
+
+// fakeT collects failures and cleanups instead of failing the test.
+type fakeT struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeT) Errorf(format string, args ...any) { f.errors = append(f.errors, format) }
+func (f *fakeT) Cleanup(fn func())                 { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeT) cleanup() {
+	for _, fn := range f.cleanups {
+		fn()
+	}
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// GetJSON sends a GET request and decodes the JSON response into Resp.
+func GetJSON[Resp any](ctx context.Context, c *Client, path string, opts ...RequestOption) (Resp, error) {
+	return doJSON[Resp](ctx, c, http.MethodGet, path, nil, opts)
+}
+
+// PostJSON sends body as JSON and decodes the JSON response into Resp:
+//
+//	type notification struct {
+//		UserID int    `json:"user_id"`
+//		Event  string `json:"event"`
+//	}
+//	type ack struct {
+//		Status string `json:"status"`
+//	}
+//	res, err := httpx.PostJSON[notification, ack](ctx, client, "/notifications",
+//		notification{UserID: user.ID, Event: "user.created"})
+func PostJSON[Req, Resp any](ctx context.Context, c *Client, path string, body Req, opts ...RequestOption) (Resp, error) {
+	return sendJSON[Req, Resp](ctx, c, http.MethodPost, path, body, opts)
+}
+
+// PutJSON is PostJSON with the PUT method.
+func PutJSON[Req, Resp any](ctx context.Context, c *Client, path string, body Req, opts ...RequestOption) (Resp, error) {
+	return sendJSON[Req, Resp](ctx, c, http.MethodPut, path, body, opts)
+}
+
+// PatchJSON is PostJSON with the PATCH method.
+func PatchJSON[Req, Resp any](ctx context.Context, c *Client, path string, body Req, opts ...RequestOption) (Resp, error) {
+	return sendJSON[Req, Resp](ctx, c, http.MethodPatch, path, body, opts)
+}
+
+// DoJSON sends a request with any method, with body encoded as JSON
+// unless it is nil, and decodes the JSON response into Resp. It serves
+// methods without a helper of their own, and the clients generated by
+// package openapigen.
+func DoJSON[Resp any](ctx context.Context, c *Client, method, path string, body any, opts ...RequestOption) (Resp, error) {
+	if body == nil {
+		return doJSON[Resp](ctx, c, method, path, nil, opts)
+	}
+	return sendJSON[any, Resp](ctx, c, method, path, body, opts)
+}
+
+func sendJSON[Req, Resp any](ctx context.Context, c *Client, method, path string, body Req, opts []RequestOption) (Resp, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		var zero Resp
+		return zero, fmt.Errorf("httpx: encoding %s %s request: %w", method, path, err)
+	}
+	opts = append([]RequestOption{WithHeader("Content-Type", "application/json")}, opts...)
+	return doJSON[Resp](ctx, c, method, path, data, opts)
+}
+
+// doJSON sends data, when not nil, and decodes the response. The body is
+// an in-memory reader so Retry can replay it. An empty response body
+// leaves Resp at its zero value.
+func doJSON[Resp any](ctx context.Context, c *Client, method, path string, data []byte, opts []RequestOption) (Resp, error) {
+	var out Resp
+	opts = append([]RequestOption{WithHeader("Accept", "application/json")}, opts...)
+	var resp *Response
+	var err error
+	if data == nil {
+		resp, err = c.Do(ctx, method, path, nil, opts...)
+	} else {
+		resp, err = c.Do(ctx, method, path, bytes.NewReader(data), opts...)
+	}
+	if err != nil {
+		return out, err
+	}
+	if len(bytes.TrimSpace(resp.Body)) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(resp.Body, &out); err != nil {
+		return out, fmt.Errorf("httpx: decoding %s %s response: %w", method, resp.Request.URL.Redacted(), err)
+	}
+	return out, nil
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type notification struct {
+	UserID int    `json:"user_id"`
+	Event  string `json:"event"`
+}
+
+type ack struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func TestPostJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("Accept") != "application/json" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		var n notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(ack{Status: "success", Message: n.Event})
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL)
+	got, err := PostJSON[notification, ack](context.Background(), c, "/notifications", notification{UserID: 1, Event: "user.created"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != (ack{Status: "success", Message: "user.created"}) {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestGetJSONErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte("<html>"))
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL)
+	ctx := context.Background()
+	var se *StatusError
+	if _, err := GetJSON[ack](ctx, c, "/missing"); !errors.As(err, &se) || se.StatusCode != 404 {
+		t.Errorf("missing: %v", err)
+	}
+	if got, err := GetJSON[ack](ctx, c, "/empty"); err != nil || got != (ack{}) {
+		t.Errorf("empty: %+v %v", got, err)
+	}
+	var syn *json.SyntaxError
+	if _, err := GetJSON[ack](ctx, c, "/html"); !errors.As(err, &syn) {
+		t.Errorf("html: %v", err)
+	}
+	if _, err := PutJSON[func(), ack](ctx, c, "/x", func() {}); err == nil {
+		t.Error("expected an encoding error")
+	}
+}
+
+func TestDoJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n notification
+		json.NewDecoder(r.Body).Decode(&n)
+		json.NewEncoder(w).Encode(ack{Status: r.Method, Message: r.Header.Get("Content-Type") + n.Event})
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL)
+	ctx := context.Background()
+	got, err := DoJSON[ack](ctx, c, http.MethodDelete, "/notifications/1", nil)
+	if err != nil || got != (ack{Status: "DELETE"}) {
+		t.Errorf("without body: %+v %v", got, err)
+	}
+	got, err = DoJSON[ack](ctx, c, http.MethodDelete, "/notifications", notification{Event: "purge"})
+	if err != nil || got != (ack{Status: "DELETE", Message: "application/jsonpurge"}) {
+		t.Errorf("with body: %+v %v", got, err)
+	}
+}
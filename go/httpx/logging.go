@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/internal/httplog"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// DefaultMaxBodyBytes is how much of a body Logging includes when body
+// logging is on and no limit is set.
+const DefaultMaxBodyBytes = 1024
+
+// LoggingConfig configures Logging from settings, e.g. bound with
+// config.Bind under an "http.log" section. The zero value logs requests
+// without headers or bodies.
+type LoggingConfig struct {
+	Disabled     bool `config:"disabled" desc:"Turn off request logging."`
+	Headers      bool `config:"headers" desc:"Log request and response headers, redacted."`
+	Bodies       bool `config:"bodies" desc:"Log request and response bodies, redacted and truncated."`
+	MaxBodyBytes int  `config:"max_body_bytes" default:"1024" validate:"min=0" desc:"Longest body prefix logged."`
+}
+
+// LoggingOption configures Logging.
+type LoggingOption func(*LoggingConfig)
+
+// WithLoggingConfig applies cfg, replacing earlier options.
+func WithLoggingConfig(cfg LoggingConfig) LoggingOption {
+	return func(c *LoggingConfig) { *c = cfg }
+}
+
+// WithHeaders logs request and response headers. Sensitive headers such
+// as Authorization are redacted.
+func WithHeaders() LoggingOption {
+	return func(c *LoggingConfig) { c.Headers = true }
+}
+
+// WithBodies logs up to max bytes of textual request and response bodies,
+// redacted; DefaultMaxBodyBytes when max is 0. Request bodies are only
+// logged when they can be replayed, which holds for every body passed to
+// a Client. The response body prefix is read before the response is
+// returned, so do not enable it for streaming endpoints.
+func WithBodies(max int) LoggingOption {
+	return func(c *LoggingConfig) { c.Bodies, c.MaxBodyBytes = true, max }
+}
+
+// Logging logs every request through logger, like the Python transport
+// LoggingMiddleware: http_request_started at DEBUG, then
+// http_request_completed at INFO or http_request_failed at ERROR, with
+// the http event-set fields. Credentials in URLs are redacted, and so are
+// headers and bodies when logged.
+func Logging(logger *log.Logger, opts ...LoggingOption) Middleware {
+	var cfg LoggingConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	redactor := log.NewRedactor()
+	return func(next http.RoundTripper) http.RoundTripper {
+		if cfg.Disabled {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			l := logger.With("http.method", req.Method, "http.url", req.URL.Redacted())
+			var started []any
+			if cfg.Headers {
+				started = append(started, "http.request.headers", redactor.Redact(req.Header))
+			}
+			if cfg.Bodies && req.GetBody != nil {
+				if body, err := req.GetBody(); err == nil {
+					data, truncated := httplog.Peek(body, cfg.MaxBodyBytes)
+					body.Close()
+					if len(data) > 0 {
+						started = append(started, "http.request.body", httplog.FormatBody(redactor, req.Header, data, truncated))
+					}
+				}
+			}
+			l.DebugCtx(ctx, "http_request_started", started...)
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			ms := time.Since(start).Milliseconds()
+			if err != nil {
+				l.ErrorCtx(ctx, "http_request_failed", "duration_ms", ms, log.Err(err))
+				return resp, err
+			}
+			done := []any{
+				"http.status_code", resp.StatusCode,
+				"http.status_class", fmt.Sprintf("%dxx", resp.StatusCode/100),
+				"duration_ms", ms,
+			}
+			if cfg.Headers {
+				done = append(done, "http.response.headers", redactor.Redact(resp.Header))
+			}
+			if cfg.Bodies && resp.Body != nil {
+				data, truncated := httplog.Peek(resp.Body, cfg.MaxBodyBytes)
+				resp.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+				if len(data) > 0 {
+					done = append(done, "http.response.body", httplog.FormatBody(redactor, resp.Header, data, truncated))
+				}
+			}
+			l.InfoCtx(ctx, "http_request_completed", done...)
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	rec := logtest.Capture(t)
+	c, _ := New(strings.Replace(srv.URL, "http://", "http://user:pw@", 1), WithMiddleware(Logging(rec.Logger())))
+	if _, err := c.Post(context.Background(), "/notifications", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Records().Events(); !reflect.DeepEqual(got, []string{"http_request_started", "http_request_completed"}) {
+		t.Fatalf("events = %v", got)
+	}
+	done := rec.Records().Last()
+	if v, _ := done.Get("http.status_class"); v != "2xx" {
+		t.Errorf("status class = %v", v)
+	}
+	if v, _ := done.Get("http.url"); strings.Contains(v.(string), "pw") {
+		t.Errorf("url not redacted: %v", v)
+	}
+
+	srv.Close()
+	c.Get(context.Background(), "/users/1")
+	if rec.Records().WithEvent("http_request_failed").Count() != 1 {
+		t.Errorf("failure not logged: %v", rec.Records().Events())
+	}
+}
+
+func TestLoggingBodiesAndHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		io.WriteString(w, `{"status":"success","access_token":"tok"}`)
+	}))
+	defer srv.Close()
+
+	rec := logtest.Capture(t)
+	c, _ := New(srv.URL, WithMiddleware(Logging(rec.Logger(), WithHeaders(), WithBodies(0))))
+	resp, err := c.Post(context.Background(), "/notifications", strings.NewReader(`{"user":"ann","password":"hunter2"}`),
+		WithHeader("Content-Type", "application/json"), WithHeader("Authorization", "Bearer t"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(resp.Body), `"access_token":"tok"`) {
+		t.Errorf("response body consumed by logging: %q", resp.Body)
+	}
+
+	started := rec.Records().WithEvent("http_request_started").Last()
+	if v, _ := started.Get("http.request.body"); v != `{"password":"[REDACTED]","user":"ann"}` {
+		t.Errorf("request body = %v", v)
+	}
+	if v, _ := started.Get("http.request.headers"); v.(http.Header).Get("Authorization") != "[REDACTED]" {
+		t.Errorf("request headers = %v", v)
+	}
+	done := rec.Records().WithEvent("http_request_completed").Last()
+	if v, _ := done.Get("http.response.body"); v != `{"access_token":"[REDACTED]","status":"success"}` {
+		t.Errorf("response body = %v", v)
+	}
+	if v, _ := done.Get("http.response.headers"); v.(http.Header).Get("Set-Cookie") != "[REDACTED]" {
+		t.Errorf("response headers = %v", v)
+	}
+}
+
+func TestLoggingTruncatesAndDisables(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, strings.Repeat("x", 100))
+	}))
+	defer srv.Close()
+
+	rec := logtest.Capture(t)
+	c, _ := New(srv.URL, WithMiddleware(Logging(rec.Logger(), WithLoggingConfig(LoggingConfig{Bodies: true, MaxBodyBytes: 10}))))
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil || len(resp.Body) != 100 {
+		t.Fatalf("response = %d bytes, %v", len(resp.Body), err)
+	}
+	if v, _ := rec.Records().Last().Get("http.response.body"); v != "xxxxxxxxxx...[truncated]" {
+		t.Errorf("body = %v", v)
+	}
+
+	quiet := logtest.Capture(t)
+	c, _ = New(srv.URL, WithMiddleware(Logging(quiet.Logger(), WithLoggingConfig(LoggingConfig{Disabled: true}))))
+	c.Get(context.Background(), "/")
+	if n := quiet.Records().Count(); n != 0 {
+		t.Errorf("disabled logging wrote %d records", n)
+	}
+}
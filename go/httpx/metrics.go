@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+)
+
+// Metric names recorded by Metrics, following the OpenTelemetry HTTP
+// client conventions in Prometheus form.
+const (
+	MetricRequests = "http_client_requests_total"
+	MetricInFlight = "http_client_requests_in_flight"
+	MetricDuration = "http_client_request_duration_seconds"
+)
+
+// Metrics records the golden signals of every request in reg, or in
+// metrics.Default when reg is nil: a request counter and a duration
+// histogram labeled by host, method and status, and an in-flight gauge
+// labeled by host and method. Requests that fail without a response have
+// the status "error".
+//
+// Place it outside Retry to count calls, or inside to count attempts.
+func Metrics(reg *metrics.Registry) Middleware {
+	if reg == nil {
+		reg = metrics.Default
+	}
+	requests := reg.Counter(MetricRequests, "HTTP client requests.", "host", "method", "status")
+	inFlight := reg.Gauge(MetricInFlight, "HTTP client requests in flight.", "host", "method")
+	duration := reg.Histogram(MetricDuration, "HTTP client request duration in seconds.", nil, "host", "method", "status")
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			host, method := req.URL.Host, req.Method
+			inFlight.Inc(host, method)
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			elapsed := time.Since(start).Seconds()
+			inFlight.Dec(host, method)
+			status := "error"
+			if err == nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			requests.Inc(host, method, status)
+			duration.Observe(elapsed, host, method, status)
+			return resp, err
+		})
+	}
+}
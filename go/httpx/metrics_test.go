@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	reg := metrics.NewRegistry()
+	var inFlight float64
+	rt := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		for _, f := range reg.Collect() {
+			if f.Name == MetricInFlight {
+				inFlight = f.Series[0].Value
+			}
+		}
+		if req.URL.Path == "/down" {
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: 201, Body: http.NoBody, Request: req}, nil
+	})
+	c, _ := New("http://api.example", WithTransport(rt), WithMiddleware(Metrics(reg)))
+	c.Post(context.Background(), "/notifications", nil)
+	c.Post(context.Background(), "/notifications", nil)
+	c.Get(context.Background(), "/down")
+	if inFlight != 1 {
+		t.Errorf("in flight during request = %v", inFlight)
+	}
+
+	got := map[string]metrics.Family{}
+	for _, f := range reg.Collect() {
+		got[f.Name] = f
+	}
+	req := got[MetricRequests].Series
+	if len(req) != 2 || req[0].Value != 1 || req[0].LabelValues[2] != "error" || req[1].Value != 2 || req[1].LabelValues[2] != "201" {
+		t.Errorf("requests = %+v", req)
+	}
+	if s := got[MetricDuration].Series; len(s) != 2 || s[1].Count != 2 {
+		t.Errorf("durations = %+v", s)
+	}
+	for _, s := range got[MetricInFlight].Series {
+		if s.Value != 0 {
+			t.Errorf("in flight after requests = %+v", s)
+		}
+	}
+}
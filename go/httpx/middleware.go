@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import "net/http"
+
+// Middleware wraps a round tripper with a cross-cutting concern such as
+// logging, authentication or retries.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// WithMiddleware adds middleware when the client is created; see Use.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) { c.middleware = append(c.middleware, mw...) }
+}
+
+// Use appends middleware to the chain. The first middleware added is the
+// outermost: it sees each request first and its response last, so
+//
+//	client.Use(httpx.Logging(logger), auth, retry)
+//
+// logs once per call while retries happen inside. Use is safe to call
+// while requests are in flight; they finish with the chain they started
+// with.
+func (c *Client) Use(mw ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middleware = append(c.middleware, mw...)
+	c.chain = buildChain(c.transport, c.middleware)
+}
+
+// roundTrip sends req through the current chain.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.RLock()
+	rt := c.chain
+	c.mu.RUnlock()
+	return rt.RoundTrip(req)
+}
+
+func buildChain(rt http.RoundTripper, mw []Middleware) http.RoundTripper {
+	for i := len(mw) - 1; i >= 0; i-- {
+		rt = mw[i](rt)
+	}
+	return rt
+}
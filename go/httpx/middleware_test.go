@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func tagging(name string, order *[]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*order = append(*order, name+">")
+			req.Header.Add("X-Chain", name)
+			resp, err := next.RoundTrip(req)
+			*order = append(*order, "<"+name)
+			return resp, err
+		})
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Join(r.Header.Values("X-Chain"), ",")))
+	}))
+	defer srv.Close()
+
+	var order []string
+	c, _ := New(srv.URL, WithMiddleware(tagging("a", &order)))
+	c.Use(tagging("b", &order), tagging("c", &order))
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body) != "a,b,c" {
+		t.Errorf("server saw %q", resp.Body)
+	}
+	if want := []string{"a>", "b>", "c>", "<c", "<b", "<a"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
@@ -0,0 +1,144 @@
+// Code generated by foundation gen openapi. DO NOT EDIT.
+
+package petstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+)
+
+// Client calls the Petstore API, version 1.2.0. Requests are sent
+// through the httpx.Client it was created with, and so through its
+// middleware: retries, authentication, tracing and the rest.
+type Client struct {
+	client *httpx.Client
+}
+
+// NewClient returns a Client sending requests with client, whose base
+// URL is the server of the API.
+func NewClient(client *httpx.Client) *Client {
+	return &Client{client: client}
+}
+
+// ListPets sends GET /pets.
+//
+// List the pets of the store.
+func (c *Client) ListPets(ctx context.Context, params ListPetsParams, opts ...httpx.RequestOption) ([]Pet, error) {
+	var req []httpx.RequestOption
+	if params.Limit != nil {
+		req = append(req, httpx.WithQuery("limit", fmt.Sprint(*params.Limit)))
+	}
+	if params.Status != nil {
+		req = append(req, httpx.WithQuery("status", string(*params.Status)))
+	}
+	for _, v := range params.Tag {
+		req = append(req, httpx.WithQuery("tag", v))
+	}
+	return httpx.DoJSON[[]Pet](ctx, c.client, http.MethodGet, "/pets", nil, append(req, opts...)...)
+}
+
+// CreatePet sends POST /pets.
+func (c *Client) CreatePet(ctx context.Context, body NewPet, opts ...httpx.RequestOption) (Pet, error) {
+	return httpx.DoJSON[Pet](ctx, c.client, http.MethodPost, "/pets", body, opts...)
+}
+
+// ShowPetByID sends GET /pets/{petId}.
+func (c *Client) ShowPetByID(ctx context.Context, petID int64, opts ...httpx.RequestOption) (Pet, error) {
+	return httpx.DoJSON[Pet](ctx, c.client, http.MethodGet, "/pets/"+url.PathEscape(fmt.Sprint(petID)), nil, opts...)
+}
+
+// UpdatePet sends PATCH /pets/{petId}.
+//
+// Changes the fields of the pet set in the request; the others are left
+// as they are.
+func (c *Client) UpdatePet(ctx context.Context, petID int64, params UpdatePetParams, body UpdatePetRequest, opts ...httpx.RequestOption) (Pet, error) {
+	var req []httpx.RequestOption
+	req = append(req, httpx.WithHeader("If-Match", params.IfMatch))
+	return httpx.DoJSON[Pet](ctx, c.client, http.MethodPatch, "/pets/"+url.PathEscape(fmt.Sprint(petID)), body, append(req, opts...)...)
+}
+
+// DeletePet sends DELETE /pets/{petId}.
+//
+// Deprecated: the operation is deprecated by the API.
+func (c *Client) DeletePet(ctx context.Context, petID int64, opts ...httpx.RequestOption) error {
+	_, err := httpx.DoJSON[any](ctx, c.client, http.MethodDelete, "/pets/"+url.PathEscape(fmt.Sprint(petID)), nil, opts...)
+	return err
+}
+
+// GetStats sends GET /stats.
+//
+// Count the pets by status.
+func (c *Client) GetStats(ctx context.Context, opts ...httpx.RequestOption) (map[string]int, error) {
+	return httpx.DoJSON[map[string]int](ctx, c.client, http.MethodGet, "/stats", nil, opts...)
+}
+
+// NewPet is the NewPet schema.
+type NewPet struct {
+	Name   string  `json:"name"`
+	Tag    *string `json:"tag,omitempty"`
+	Status *Status `json:"status,omitempty"`
+}
+
+// Pet is the Pet schema.
+//
+// A pet of the store.
+type Pet struct {
+	Name      string    `json:"name"`
+	Tag       *string   `json:"tag,omitempty"`
+	Status    *Status   `json:"status,omitempty"`
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	// The owner of the pet, if it was adopted.
+	Owner      *PetOwner         `json:"owner,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Photo      json.RawMessage   `json:"photo,omitempty"`
+}
+
+// PetOwner is the owner property of Pet.
+//
+// The owner of the pet, if it was adopted.
+type PetOwner struct {
+	Name  *string `json:"name,omitempty"`
+	Email *string `json:"email,omitempty"`
+}
+
+// Status is the Status schema.
+type Status string
+
+// Values of Status.
+const (
+	StatusAvailable Status = "available"
+	StatusPending   Status = "pending"
+	StatusSold      Status = "sold"
+)
+
+// Error is the Error schema.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ListPetsParams are the query and header parameters of ListPets.
+type ListPetsParams struct {
+	// Most pets to return.
+	Limit  *int32
+	Status *Status
+	Tag    []string
+}
+
+// UpdatePetParams are the query and header parameters of UpdatePet.
+type UpdatePetParams struct {
+	IfMatch string
+}
+
+// UpdatePetRequest is the request body of UpdatePet.
+type UpdatePetRequest struct {
+	Name   *string `json:"name,omitempty"`
+	Status *Status `json:"status,omitempty"`
+}
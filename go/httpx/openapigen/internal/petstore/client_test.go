@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package petstore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/httpx/httpxtest"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+	"github.com/provide-io/provide-foundation/go/testkit"
+)
+
+func newClient(t *testing.T, mock *httpxtest.MockTransport) *Client {
+	t.Helper()
+	client, err := httpx.New("https://petstore.example/v1",
+		httpx.WithTransport(mock),
+		httpx.WithMiddleware(
+			httpx.Retry(retry.Policy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+			httpx.Bearer("secret"),
+		))
+	testkit.Require(t).NoError(err)
+	return NewClient(client)
+}
+
+func TestListPets(t *testing.T) {
+	mock := httpxtest.NewMockTransport(t)
+	mock.On("GET", "/v1/pets").
+		MatchQuery("limit", "2").MatchQuery("status", "available").
+		Match(func(req *http.Request, _ []byte) bool {
+			tags := req.URL.Query()["tag"]
+			return len(tags) == 2 && tags[0] == "cat" && tags[1] == "indoor"
+		}).
+		ReplyJSON(200, []map[string]any{{"id": 1, "name": "Tom", "createdAt": "2026-01-02T03:04:05Z"}})
+	pets := newClient(t, mock)
+
+	limit, status := int32(2), StatusAvailable
+	got, err := pets.ListPets(context.Background(), ListPetsParams{Limit: &limit, Status: &status, Tag: []string{"cat", "indoor"}})
+	testkit.Require(t).NoError(err)
+	testkit.Equal(t, got, []Pet{{ID: 1, Name: "Tom", CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}})
+}
+
+func TestCreatePet(t *testing.T) {
+	mock := httpxtest.NewMockTransport(t)
+	mock.On("POST", "/v1/pets").
+		MatchJSON(map[string]any{"name": "Rex", "status": "pending"}).
+		MatchHeader("Authorization", "Bearer secret").
+		ReplyJSON(201, map[string]any{"id": 7, "name": "Rex", "status": "pending"})
+	pets := newClient(t, mock)
+
+	status := StatusPending
+	got, err := pets.CreatePet(context.Background(), NewPet{Name: "Rex", Status: &status})
+	testkit.Require(t).NoError(err)
+	testkit.Equal(t, got.ID, int64(7))
+	testkit.Equal(t, *got.Status, StatusPending)
+}
+
+// TestMiddleware checks that generated calls are retried by the
+// middleware of the client, and fail with its status errors.
+func TestMiddleware(t *testing.T) {
+	mock := httpxtest.NewMockTransport(t)
+	mock.On("GET", "/v1/pets/7").Once().Reply(503, "")
+	mock.On("GET", "/v1/pets/7").Once().ReplyJSON(200, map[string]any{"id": 7, "name": "Rex"})
+	mock.On("DELETE", "/v1/pets/8").Reply(404, `{"code":404,"message":"no pet 8"}`)
+	pets := newClient(t, mock)
+
+	got, err := pets.ShowPetByID(context.Background(), 7)
+	testkit.Require(t).NoError(err)
+	testkit.Equal(t, got.Name, "Rex")
+
+	err = pets.DeletePet(context.Background(), 8)
+	var serr *httpx.StatusError
+	testkit.Require(t).True(errors.As(err, &serr), "error %v", err)
+	testkit.Equal(t, serr.StatusCode, 404)
+	mock.AssertCalls(t, "GET /v1/pets/7", "GET /v1/pets/7", "DELETE /v1/pets/8")
+}
+
+func TestUpdatePet(t *testing.T) {
+	mock := httpxtest.NewMockTransport(t)
+	mock.On("PATCH", "/v1/pets/7").
+		MatchHeader("If-Match", `"v1"`).
+		MatchJSON(map[string]any{"status": "sold"}).
+		ReplyJSON(200, map[string]any{"id": 7, "name": "Rex", "status": "sold"})
+	pets := newClient(t, mock)
+
+	status := StatusSold
+	got, err := pets.UpdatePet(context.Background(), 7, UpdatePetParams{IfMatch: `"v1"`}, UpdatePetRequest{Status: &status})
+	testkit.Require(t).NoError(err)
+	testkit.Equal(t, *got.Status, StatusSold)
+}
@@ -0,0 +1,8 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package petstore is a client generated from petstore.yaml, the example
+// and test of package openapigen.
+package petstore
+
+//go:generate go run github.com/provide-io/provide-foundation/go/cmd/foundation gen openapi -spec petstore.yaml
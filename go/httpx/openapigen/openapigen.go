@@ -0,0 +1,685 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package openapigen generates typed API clients from OpenAPI 3
+// documents. The client has a method for each operation, taking its path
+// parameters, a struct of its query and header parameters, and its JSON
+// request body, and returning its decoded JSON response:
+//
+//	client, err := httpx.New("https://petstore.example/v1", httpx.WithMiddleware(
+//		httpx.Retry(retry.DefaultPolicy),
+//		httpx.Bearer(token),
+//	))
+//	pets := petstore.NewClient(client)
+//	pet, err := pets.ShowPetByID(ctx, 42)
+//
+// Every request is sent with httpx.DoJSON through the httpx.Client given
+// to NewClient, so generated clients get the retries, authentication,
+// tracing, metrics and request metadata of its middleware like any other
+// call, and fail with *httpx.StatusError on 4xx and 5xx responses.
+//
+// Schemas become Go types: objects structs, with pointers for optional
+// properties, string enums named string types with a constant for each
+// value, and allOf the struct of the merged properties. Schemas that
+// cannot be typed, oneOf and anyOf, are left as json.RawMessage. Only
+// JSON bodies are supported. cmd/foundation runs the generator as
+// "foundation gen openapi".
+package openapigen
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Header starts the files written by the generator.
+const Header = "// Code generated by foundation gen openapi. DO NOT EDIT."
+
+// DefaultClient is the name of the generated client type.
+const DefaultClient = "Client"
+
+const httpxPath = "github.com/provide-io/provide-foundation/go/httpx"
+
+// Config names the generated code.
+type Config struct {
+	// Package is the name of the package of the generated file.
+	Package string
+	// Client is the name of the client type, created by New<Client>;
+	// DefaultClient when empty.
+	Client string
+}
+
+// Generate returns the source of a Go file holding a client for the API
+// described by the OpenAPI 3 document spec, in YAML or JSON.
+func Generate(spec []byte, cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		return nil, errors.New("openapigen: no package name given")
+	}
+	if cfg.Client == "" {
+		cfg.Client = DefaultClient
+	}
+	s, err := parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		spec:    s,
+		cfg:     cfg,
+		names:   map[string]bool{cfg.Client: true, "New" + cfg.Client: true},
+		strs:    map[string]bool{},
+		imports: map[string]bool{"context": true, "net/http": true, httpxPath: true},
+	}
+	for _, name := range s.Components.Schemas.keys {
+		g.names[goName(name)] = true
+	}
+	for _, name := range s.Components.Schemas.keys {
+		n := goName(name)
+		if err := g.declare(n, s.Components.Schemas.values[name], n+" is the "+name+" schema."); err != nil {
+			return nil, fmt.Errorf("openapigen: schema %s: %w", name, err)
+		}
+	}
+	for _, path := range s.Paths.keys {
+		item := s.Paths.values[path]
+		for _, m := range item.operations() {
+			if err := g.operation(path, item, m); err != nil {
+				return nil, fmt.Errorf("openapigen: %s %s: %w", m.method, path, err)
+			}
+		}
+	}
+	return g.output()
+}
+
+type generator struct {
+	spec    *spec
+	cfg     Config
+	names   map[string]bool // package-level identifiers in use
+	strs    map[string]bool // declared types with an underlying string
+	imports map[string]bool
+	decls   []string
+	methods bytes.Buffer
+}
+
+// declare adds the type name for s to the output.
+func (g *generator) declare(name string, s *schema, doc string) error {
+	g.names[name] = true
+	i := len(g.decls)
+	g.decls = append(g.decls, "") // nested types follow the type using them
+	s, err := g.merge(s)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	comment(&b, "", doc, s.Description)
+	switch {
+	case len(s.Properties.keys) > 0 || (s.Type.name == "object" && s.AdditionalProperties == nil):
+		fmt.Fprintf(&b, "type %s struct {\n", name)
+		fields := map[string]bool{}
+		for _, prop := range s.Properties.keys {
+			ps := s.Properties.values[prop]
+			field := unique(goName(prop), fields)
+			t, err := g.goType(ps, name+field, name+field+" is the "+prop+" property of "+name+".")
+			if err != nil {
+				return fmt.Errorf("property %s: %w", prop, err)
+			}
+			tag := prop
+			if !slices.Contains(s.Required, prop) {
+				tag += ",omitempty"
+				t = pointer(t)
+			} else if ps.Nullable || ps.Type.nullable {
+				t = pointer(t)
+			}
+			if ps.Ref == "" {
+				comment(&b, "\t", ps.Description)
+			}
+			fmt.Fprintf(&b, "\t%s %s `json:%q`\n", field, t, tag)
+		}
+		b.WriteString("}\n")
+	case len(s.Enum) > 0 && s.Type.name == "string":
+		g.strs[name] = true
+		fmt.Fprintf(&b, "type %s string\n\n// Values of %s.\nconst (\n", name, name)
+		for _, v := range s.Enum {
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("enum value %v is not a string", v)
+			}
+			suffix := goName(str)
+			if str == "" {
+				suffix = "Empty"
+			}
+			fmt.Fprintf(&b, "\t%s %s = %q\n", unique(name+suffix, g.names), name, str)
+		}
+		b.WriteString(")\n")
+	default:
+		t, err := g.goType(s, name+"Item", name+"Item is an item of "+name+".")
+		if err != nil {
+			return err
+		}
+		if t == "string" {
+			g.strs[name] = true
+		}
+		fmt.Fprintf(&b, "type %s %s\n", name, t)
+	}
+	g.decls[i] = b.String()
+	return nil
+}
+
+// goType returns the Go type of s, declaring a type named after hint and
+// documented by doc for an object or enum.
+func (g *generator) goType(s *schema, hint, doc string) (string, error) {
+	if s == nil {
+		return "any", nil
+	}
+	if s.Ref != "" {
+		name, _, err := g.schemaRef(s.Ref)
+		return name, err
+	}
+	if len(s.AllOf) == 1 && len(s.Properties.keys) == 0 {
+		return g.goType(s.AllOf[0], hint, doc)
+	}
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		g.imports["encoding/json"] = true
+		return "json.RawMessage", nil
+	}
+	if len(s.AllOf) > 0 || len(s.Properties.keys) > 0 || (len(s.Enum) > 0 && s.Type.name == "string") {
+		name := unique(hint, g.names)
+		return name, g.declare(name, s, strings.Replace(doc, hint, name, 1))
+	}
+	switch s.Type.name {
+	case "array":
+		item, err := g.goType(s.Items, hint+"Item", hint+"Item is an item of "+hint+".")
+		return "[]" + item, err
+	case "object", "":
+		if s.AdditionalProperties != nil && !s.AdditionalProperties.never {
+			v, err := g.goType(s.AdditionalProperties, hint+"Value", hint+"Value is a value of "+hint+".")
+			return "map[string]" + v, err
+		}
+		if s.Type.name == "object" {
+			return "map[string]any", nil
+		}
+		return "any", nil
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.imports["time"] = true
+			return "time.Time", nil
+		case "byte":
+			return "[]byte", nil
+		}
+		return "string", nil
+	case "integer":
+		switch s.Format {
+		case "int32":
+			return "int32", nil
+		case "int64":
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		if s.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %q", s.Type.name)
+}
+
+// merge returns s with the properties of its allOf schemas.
+func (g *generator) merge(s *schema) (*schema, error) {
+	if len(s.AllOf) == 0 {
+		return s, nil
+	}
+	m := &schema{Type: schemaType{name: "object"}, Description: s.Description}
+	m.Properties.values = map[string]*schema{}
+	for _, part := range append(slices.Clone(s.AllOf), &schema{Properties: s.Properties, Required: s.Required}) {
+		if part.Ref != "" {
+			var err error
+			if _, part, err = g.schemaRef(part.Ref); err != nil {
+				return nil, err
+			}
+		}
+		part, err := g.merge(part)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range part.Properties.keys {
+			if _, ok := m.Properties.values[k]; !ok {
+				m.Properties.keys = append(m.Properties.keys, k)
+			}
+			m.Properties.values[k] = part.Properties.values[k]
+		}
+		m.Required = append(m.Required, part.Required...)
+	}
+	return m, nil
+}
+
+// schemaRef returns the type name and schema of a reference to a
+// component schema.
+func (g *generator) schemaRef(ref string) (string, *schema, error) {
+	name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+	s := g.spec.Components.Schemas.values[name]
+	if !ok || s == nil {
+		return "", nil, fmt.Errorf("unresolved reference %s", ref)
+	}
+	return goName(name), s, nil
+}
+
+// operation adds the client method for the operation m of path.
+func (g *generator) operation(path string, item *pathItem, m methodOp) error {
+	op := m.op
+	name := goName(op.OperationID)
+	if op.OperationID == "" {
+		name = goName(strings.ToLower(m.method) + " " + strings.NewReplacer("{", "", "}", "").Replace(path))
+	}
+	if g.names[name] {
+		return fmt.Errorf("operation name %s is already in use", name)
+	}
+	g.names[name] = true
+
+	params, err := g.parameters(item.Parameters, op.Parameters)
+	if err != nil {
+		return err
+	}
+	locals := map[string]bool{"ctx": true, "params": true, "body": true, "opts": true, "req": true, "c": true, "err": true}
+	args := []string{"ctx context.Context"}
+
+	// The path: literal segments and escaped path parameters.
+	var pathExpr []string
+	rest := path
+	for rest != "" {
+		lit, after, found := strings.Cut(rest, "{")
+		if lit != "" {
+			pathExpr = append(pathExpr, strconv.Quote(lit))
+		}
+		if !found {
+			break
+		}
+		pname, after, ok := strings.Cut(after, "}")
+		if !ok {
+			return fmt.Errorf("unterminated parameter in path")
+		}
+		rest = after
+		i := slices.IndexFunc(params, func(p *parameter) bool { return p.In == "path" && p.Name == pname })
+		if i < 0 {
+			return fmt.Errorf("path parameter %s is not declared", pname)
+		}
+		p := params[i]
+		arg := unique(lowerName(pname), locals)
+		t, err := g.goType(p.Schema, name+goName(pname), name+goName(pname)+" is the "+pname+" parameter of "+name+".")
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", pname, err)
+		}
+		args = append(args, arg+" "+t)
+		g.imports["net/url"] = true
+		pathExpr = append(pathExpr, "url.PathEscape("+g.str(arg, t)+")")
+	}
+
+	// Query and header parameters, set through request options.
+	var pstruct, setters strings.Builder
+	fields := map[string]bool{}
+	for _, p := range params {
+		var option string
+		switch p.In {
+		case "path":
+			continue
+		case "query":
+			option = "httpx.WithQuery"
+		case "header":
+			option = "httpx.WithHeader"
+		default:
+			return fmt.Errorf("parameter %s: %s parameters are not supported", p.Name, p.In)
+		}
+		field := unique(goName(p.Name), fields)
+		t, err := g.goType(p.Schema, name+field, name+field+" is the "+p.Name+" parameter of "+name+".")
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		if !p.Required {
+			t = pointer(t)
+		}
+		comment(&pstruct, "\t", p.Description)
+		fmt.Fprintf(&pstruct, "\t%s %s\n", field, t)
+		value := "params." + field
+		switch {
+		case strings.HasPrefix(t, "[]") && t != "[]byte":
+			if p.In == "header" {
+				return fmt.Errorf("parameter %s: list headers are not supported", p.Name)
+			}
+			fmt.Fprintf(&setters, "\tfor _, v := range %s {\n\t\treq = append(req, %s(%q, %s))\n\t}\n",
+				value, option, p.Name, g.str("v", t[2:]))
+		case strings.HasPrefix(t, "*"):
+			fmt.Fprintf(&setters, "\tif %s != nil {\n\t\treq = append(req, %s(%q, %s))\n\t}\n",
+				value, option, p.Name, g.str("*"+value, t[1:]))
+		default:
+			fmt.Fprintf(&setters, "\treq = append(req, %s(%q, %s))\n", option, p.Name, g.str(value, t))
+		}
+	}
+	if pstruct.Len() > 0 {
+		pname := unique(name+"Params", g.names)
+		var b strings.Builder
+		comment(&b, "", pname+" are the query and header parameters of "+name+".")
+		fmt.Fprintf(&b, "type %s struct {\n%s}\n", pname, pstruct.String())
+		g.decls = append(g.decls, b.String())
+		args = append(args, "params "+pname)
+	}
+
+	body := "nil"
+	if rb := op.RequestBody; rb != nil {
+		if rb.Ref != "" {
+			ref, ok := strings.CutPrefix(rb.Ref, "#/components/requestBodies/")
+			if rb = g.spec.Components.RequestBodies[ref]; !ok || rb == nil {
+				return fmt.Errorf("unresolved reference %s", op.RequestBody.Ref)
+			}
+		}
+		mt, ok := jsonContent(rb.Content)
+		if !ok {
+			return errors.New("request body is not JSON")
+		}
+		t, err := g.goType(mt.Schema, name+"Request", name+"Request is the request body of "+name+".")
+		if err != nil {
+			return fmt.Errorf("request body: %w", err)
+		}
+		args = append(args, "body "+t)
+		body = "body"
+	}
+	args = append(args, "opts ...httpx.RequestOption")
+
+	result, err := g.result(name, op.Responses)
+	if err != nil {
+		return err
+	}
+
+	b := &g.methods
+	b.WriteString("\n")
+	comment(b, "", name+" sends "+m.method+" "+path+".", op.Summary, op.Description)
+	if op.Deprecated {
+		b.WriteString("//\n// Deprecated: the operation is deprecated by the API.\n")
+	}
+	results := "error"
+	if result != "" {
+		results = "(" + result + ", error)"
+	}
+	fmt.Fprintf(b, "func (c *%s) %s(%s) %s {\n", g.cfg.Client, name, strings.Join(args, ", "), results)
+	options := "opts..."
+	if setters.Len() > 0 {
+		b.WriteString("\tvar req []httpx.RequestOption\n")
+		b.WriteString(setters.String())
+		options = "append(req, opts...)..."
+	}
+	call := fmt.Sprintf("httpx.DoJSON[%s](ctx, c.client, http.Method%s, %s, %s, %s)",
+		cmp.Or(result, "any"), methodConst(m.method), strings.Join(pathExpr, "+"), body, options)
+	if result != "" {
+		fmt.Fprintf(b, "\treturn %s\n}\n", call)
+	} else {
+		fmt.Fprintf(b, "\t_, err := %s\n\treturn err\n}\n", call)
+	}
+	return nil
+}
+
+// parameters returns the parameters of an operation: those of its path,
+// replaced by its own of the same name and location.
+func (g *generator) parameters(lists ...[]*parameter) ([]*parameter, error) {
+	var params []*parameter
+	for _, list := range lists {
+		for _, p := range list {
+			if p.Ref != "" {
+				ref, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+				if p = g.spec.Components.Parameters[ref]; !ok || p == nil {
+					return nil, fmt.Errorf("unresolved reference %s", ref)
+				}
+			}
+			i := slices.IndexFunc(params, func(q *parameter) bool { return q.Name == p.Name && q.In == p.In })
+			if i >= 0 {
+				params[i] = p
+			} else {
+				params = append(params, p)
+			}
+		}
+	}
+	return params, nil
+}
+
+// result returns the Go type of the JSON body of the first success
+// response, or "" if it has none.
+func (g *generator) result(name string, responses map[string]*response) (string, error) {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return "", nil
+	}
+	slices.Sort(codes)
+	resp := responses[codes[0]]
+	if resp.Ref != "" {
+		ref, ok := strings.CutPrefix(resp.Ref, "#/components/responses/")
+		if resp = g.spec.Components.Responses[ref]; !ok || resp == nil {
+			return "", fmt.Errorf("unresolved reference %s", responses[codes[0]].Ref)
+		}
+	}
+	mt, ok := jsonContent(resp.Content)
+	if !ok {
+		return "", nil
+	}
+	t, err := g.goType(mt.Schema, name+"Response", name+"Response is the response of "+name+".")
+	if err != nil {
+		return "", fmt.Errorf("response %s: %w", codes[0], err)
+	}
+	return t, nil
+}
+
+// str returns the expression formatting expr, of type t, as a parameter
+// value.
+func (g *generator) str(expr, t string) string {
+	switch {
+	case t == "string":
+		return expr
+	case g.strs[t]:
+		return "string(" + expr + ")"
+	case t == "time.Time":
+		return expr + ".Format(time.RFC3339)"
+	}
+	g.imports["fmt"] = true
+	return "fmt.Sprint(" + expr + ")"
+}
+
+func (g *generator) output() ([]byte, error) {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s\n\npackage %s\n\nimport (\n", Header, g.cfg.Package)
+	// Standard library first, as goimports groups them.
+	var std, other []string
+	for path := range g.imports {
+		if strings.Contains(path, ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	slices.Sort(std)
+	slices.Sort(other)
+	for _, path := range std {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString("\n")
+	for _, path := range other {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n\n")
+
+	title := g.spec.Info.Title
+	if title == "" {
+		title = "the"
+	}
+	version := ""
+	if g.spec.Info.Version != "" {
+		version = ", version " + g.spec.Info.Version
+	}
+	client := g.cfg.Client
+	comment(&out, "", fmt.Sprintf("%s calls the %s API%s. Requests are sent through the httpx.Client it was created with, and so through its middleware: retries, authentication, tracing and the rest.", client, title, version))
+	fmt.Fprintf(&out, "type %s struct {\n\tclient *httpx.Client\n}\n\n", client)
+	comment(&out, "", fmt.Sprintf("New%s returns a %s sending requests with client, whose base URL is the server of the API.", client, client))
+	fmt.Fprintf(&out, "func New%s(client *httpx.Client) *%s {\n\treturn &%s{client: client}\n}\n", client, client, client)
+	out.Write(g.methods.Bytes())
+	for _, d := range g.decls {
+		out.WriteString("\n" + d)
+	}
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("openapigen: formatting output: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// jsonContent returns the JSON media type of content.
+func jsonContent(content map[string]mediaType) (mediaType, bool) {
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	for _, t := range types {
+		base, _, _ := strings.Cut(t, ";")
+		if base == "application/json" || strings.HasSuffix(base, "+json") {
+			return content[t], true
+		}
+	}
+	return mediaType{}, false
+}
+
+// comment writes paragraphs as a doc comment wrapped at 72 columns,
+// skipping empty ones.
+func comment(b io.StringWriter, indent string, paragraphs ...string) {
+	first := true
+	for _, p := range paragraphs {
+		words := strings.Fields(p)
+		if len(words) == 0 {
+			continue
+		}
+		if !first {
+			b.WriteString(indent + "//\n")
+		}
+		first = false
+		line := indent + "//"
+		for _, w := range words {
+			if len(line)+1+len(w) > 72+len(indent) && line != indent+"//" {
+				b.WriteString(line + "\n")
+				line = indent + "//"
+			}
+			line += " " + w
+		}
+		b.WriteString(line + "\n")
+	}
+}
+
+func methodConst(method string) string {
+	return method[:1] + strings.ToLower(method[1:])
+}
+
+func pointer(t string) string {
+	if strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") || strings.HasPrefix(t, "*") ||
+		t == "any" || t == "json.RawMessage" {
+		return t
+	}
+	return "*" + t
+}
+
+// unique returns name, or name with a number appended if it is taken,
+// and marks the result taken.
+func unique(name string, taken map[string]bool) string {
+	n := name
+	for i := 2; taken[n]; i++ {
+		n = name + strconv.Itoa(i)
+	}
+	taken[n] = true
+	return n
+}
+
+// initialisms are the words written in capitals in Go names.
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SQL": true, "TLS": true, "TTL": true, "UI": true, "URI": true,
+	"URL": true, "UUID": true, "XML": true,
+}
+
+// goName returns an exported Go name for s: PetID for pet_id or petId.
+func goName(s string) string {
+	name := title(words(s))
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// lowerName returns an unexported Go name for s: petID for pet_id.
+func lowerName(s string) string {
+	ws := words(s)
+	if len(ws) == 0 {
+		return "x"
+	}
+	name := strings.ToLower(ws[0]) + title(ws[1:])
+	if !unicode.IsLetter([]rune(name)[0]) {
+		name = "x" + name
+	}
+	if token.IsKeyword(name) {
+		name += "Value"
+	}
+	return name
+}
+
+// title joins words capitalized, and initialisms in capitals.
+func title(words []string) string {
+	var b strings.Builder
+	for _, w := range words {
+		if up := strings.ToUpper(w); initialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		r := []rune(strings.ToLower(w))
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
+
+// words splits s into words at non-alphanumeric characters and changes
+// of case: "petId" and "pet_id" are both pet and id.
+func words(s string) []string {
+	var words []string
+	r := []rune(s)
+	start := -1
+	for i, c := range r {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			if start >= 0 {
+				words = append(words, string(r[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(c) && i > start {
+			prev := r[i-1]
+			nextLower := i+1 < len(r) && unicode.IsLower(r[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(r[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(r[start:]))
+	}
+	return words
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapigen
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/testkit"
+)
+
+// TestGenerate checks that the client in internal/petstore is up to date;
+// run the tests with -update to regenerate it.
+func TestGenerate(t *testing.T) {
+	spec, err := os.ReadFile("internal/petstore/petstore.yaml")
+	testkit.Require(t).NoError(err)
+	src, err := Generate(spec, Config{Package: "petstore"})
+	testkit.Require(t).NoError(err)
+
+	const path = "internal/petstore/client_gen.go"
+	if testkit.Updating() {
+		testkit.Require(t).NoError(os.WriteFile(path, src, 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	testkit.Require(t).NoError(err)
+	if string(src) != string(want) {
+		t.Errorf("%s is out of date (-want +got); run go generate or the tests with -update:\n%s",
+			path, testkit.Diff(string(src), string(want)))
+	}
+}
+
+func TestGenerateJSON(t *testing.T) {
+	spec := `{
+		"openapi": "3.1.0",
+		"info": {"title": "Users", "version": "2"},
+		"paths": {"/users/{user_name}": {"get": {
+			"operationId": "get_user",
+			"parameters": [{"name": "user_name", "in": "path", "required": true, "schema": {"type": "string"}}],
+			"responses": {"200": {"content": {"application/json": {"schema": {
+				"type": "object",
+				"required": ["url"],
+				"properties": {"url": {"type": ["string", "null"]}, "score": {"type": "number"}}
+			}}}}}
+		}}}
+	}`
+	src, err := Generate([]byte(spec), Config{Package: "users", Client: "Users"})
+	testkit.Require(t).NoError(err)
+	for _, want := range []string{
+		"func NewUsers(client *httpx.Client) *Users",
+		"func (c *Users) GetUser(ctx context.Context, userName string, opts ...httpx.RequestOption) (GetUserResponse, error)",
+		`"/users/"+url.PathEscape(userName)`,
+		"URL   *string  `json:\"url\"`",
+		"Score *float64 `json:\"score,omitempty\"`",
+	} {
+		testkit.Contains(t, string(src), want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name, spec, want string
+	}{
+		{"version", `swagger: "2.0"`, `unsupported OpenAPI version ""`},
+		{"ref", `
+openapi: 3.0.0
+components:
+  schemas:
+    Pet: {$ref: "#/components/schemas/Missing"}`, "schema Pet: unresolved reference #/components/schemas/Missing"},
+		{"path parameter", `
+openapi: 3.0.0
+paths:
+  /pets/{id}:
+    get: {responses: {}}`, "GET /pets/{id}: path parameter id is not declared"},
+		{"cookie", `
+openapi: 3.0.0
+paths:
+  /pets:
+    get:
+      parameters: [{name: session, in: cookie, schema: {type: string}}]
+      responses: {}`, "parameter session: cookie parameters are not supported"},
+		{"body", `
+openapi: 3.0.0
+paths:
+  /pets:
+    post:
+      requestBody: {content: {text/plain: {schema: {type: string}}}}
+      responses: {}`, "POST /pets: request body is not JSON"},
+		{"duplicate", `
+openapi: 3.0.0
+paths:
+  /a: {get: {operationId: list, responses: {}}}
+  /b: {get: {operationId: list, responses: {}}}`, "operation name List is already in use"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate([]byte(strings.TrimSpace(tt.spec)), Config{Package: "p"})
+			testkit.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	for _, tt := range []struct{ in, exported, unexported string }{
+		{"petId", "PetID", "petID"},
+		{"pet_id", "PetID", "petID"},
+		{"If-Match", "IfMatch", "ifMatch"},
+		{"HTTPServer", "HTTPServer", "httpServer"},
+		{"AVAILABLE", "Available", "available"},
+		{"2fa", "X2fa", "x2fa"},
+		{"type", "Type", "typeValue"},
+	} {
+		testkit.Equal(t, goName(tt.in), tt.exported, tt.in)
+		testkit.Equal(t, lowerName(tt.in), tt.unexported, tt.in)
+	}
+}
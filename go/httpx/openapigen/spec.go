@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// spec is the part of an OpenAPI document the generator reads.
+type spec struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      ordered[*pathItem] `json:"paths"`
+	Components struct {
+		Schemas       ordered[*schema]        `json:"schemas"`
+		Parameters    map[string]*parameter   `json:"parameters"`
+		RequestBodies map[string]*requestBody `json:"requestBodies"`
+		Responses     map[string]*response    `json:"responses"`
+	} `json:"components"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `json:"parameters"`
+	Get        *operation   `json:"get"`
+	Put        *operation   `json:"put"`
+	Post       *operation   `json:"post"`
+	Delete     *operation   `json:"delete"`
+	Patch      *operation   `json:"patch"`
+}
+
+// methodOp is an operation with its HTTP method.
+type methodOp struct {
+	method string
+	op     *operation
+}
+
+// operations returns the operations of p, in a fixed order of methods.
+func (p *pathItem) operations() []methodOp {
+	var ops []methodOp
+	for _, m := range []methodOp{{"GET", p.Get}, {"POST", p.Post}, {"PUT", p.Put}, {"PATCH", p.Patch}, {"DELETE", p.Delete}} {
+		if m.op != nil {
+			ops = append(ops, m)
+		}
+	}
+	return ops
+}
+
+type operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Deprecated  bool                 `json:"deprecated"`
+	Parameters  []*parameter         `json:"parameters"`
+	RequestBody *requestBody         `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Ref      string               `json:"$ref"`
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Ref     string               `json:"$ref"`
+	Content map[string]mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string           `json:"$ref"`
+	Type                 schemaType       `json:"type"`
+	Format               string           `json:"format"`
+	Description          string           `json:"description"`
+	Nullable             bool             `json:"nullable"`
+	Enum                 []any            `json:"enum"`
+	Properties           ordered[*schema] `json:"properties"`
+	Required             []string         `json:"required"`
+	Items                *schema          `json:"items"`
+	AdditionalProperties *schema          `json:"additionalProperties"`
+	AllOf                []*schema        `json:"allOf"`
+	OneOf                []*schema        `json:"oneOf"`
+	AnyOf                []*schema        `json:"anyOf"`
+
+	never bool // the false schema, matching nothing
+}
+
+// schemaType is the type of a schema: a name in OpenAPI 3.0, a list of
+// names in 3.1, where "null" stands for nullable.
+type schemaType struct {
+	name     string
+	nullable bool
+}
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &t.name); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	for _, n := range names {
+		if n == "null" {
+			t.nullable = true
+		} else if t.name == "" {
+			t.name = n
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON reads a schema, which in places such as
+// additionalProperties may be a boolean: true allows anything, false
+// nothing.
+func (s *schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = schema{}
+		return nil
+	case "false":
+		*s = schema{never: true}
+		return nil
+	}
+	type plain schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// ordered is a JSON object read with the order of its keys.
+type ordered[T any] struct {
+	keys   []string
+	values map[string]T
+}
+
+func (o *ordered[T]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("expected an object")
+	}
+	o.values = map[string]T{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if _, dup := o.values[key]; !dup {
+			o.keys = append(o.keys, key)
+		}
+		o.values[key] = v
+	}
+	return nil
+}
+
+// parse reads an OpenAPI document in YAML or JSON, a subset of YAML.
+func parse(data []byte) (*spec, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("openapigen: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := toJSON(&buf, &doc); err != nil {
+		return nil, fmt.Errorf("openapigen: %w", err)
+	}
+	s := new(spec)
+	if err := json.Unmarshal(buf.Bytes(), s); err != nil {
+		return nil, fmt.Errorf("openapigen: %w", err)
+	}
+	if !strings.HasPrefix(s.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapigen: unsupported OpenAPI version %q; want 3.x", s.OpenAPI)
+	}
+	return s, nil
+}
+
+// toJSON writes the YAML node n as JSON, keeping the order of mapping
+// keys, which decoding YAML into Go maps would lose.
+func toJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return toJSON(buf, n.Content[0])
+	case yaml.AliasNode:
+		return toJSON(buf, n.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(n.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := toJSON(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := toJSON(buf, c); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!null":
+			buf.WriteString("null")
+		case "!!bool", "!!int":
+			var v any
+			if err := n.Decode(&v); err != nil {
+				return err
+			}
+			data, _ := json.Marshal(v)
+			buf.Write(data)
+		case "!!float":
+			f, err := strconv.ParseFloat(n.Value, 64)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", n.Line, err)
+			}
+			buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+		default:
+			data, _ := json.Marshal(n.Value)
+			buf.Write(data)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/resilience"
+)
+
+// ErrRateLimited is returned by RateLimit in fail-fast mode when no token
+// is available.
+var ErrRateLimited = errorsx.New(errorsx.RateLimited, "httpx: client rate limit exceeded")
+
+// RateLimitOption configures RateLimit.
+type RateLimitOption func(*rateLimit)
+
+// WithGlobalLimit limits all requests through the middleware together.
+func WithGlobalLimit(rate float64, burst int) RateLimitOption {
+	return func(r *rateLimit) { r.globalRate, r.globalBurst = rate, burst }
+}
+
+// WithHostLimit limits requests to each host separately.
+func WithHostLimit(rate float64, burst int) RateLimitOption {
+	return func(r *rateLimit) { r.hostRate, r.hostBurst = rate, burst }
+}
+
+// WithRateLimitClock sets the clock the buckets refill and requests wait
+// by. The default is clock.Real().
+func WithRateLimitClock(c clock.Clock) RateLimitOption {
+	return func(r *rateLimit) { r.clock = c }
+}
+
+// WithFailFast returns ErrRateLimited instead of waiting for a token.
+func WithFailFast() RateLimitOption {
+	return func(r *rateLimit) { r.failFast = true }
+}
+
+type rateLimit struct {
+	globalRate  float64
+	globalBurst int
+	hostRate    float64
+	hostBurst   int
+	failFast    bool
+	clock       clock.Clock
+
+	global *resilience.RateLimiter
+
+	mu    sync.Mutex
+	hosts map[string]*resilience.RateLimiter
+}
+
+// RateLimit keeps requests within a downstream quota using token buckets,
+// globally, per host, or both. By default a request waits for a token
+// until its context is done; WithFailFast rejects it instead.
+func RateLimit(opts ...RateLimitOption) Middleware {
+	r := &rateLimit{clock: clock.Real(), hosts: make(map[string]*resilience.RateLimiter)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.globalRate > 0 {
+		r.global = resilience.NewRateLimiter(r.globalRate, r.globalBurst, resilience.WithLimiterClock(r.clock))
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := r.acquire(req); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// limiters returns the buckets that apply to host.
+func (r *rateLimit) limiters(host string) []*resilience.RateLimiter {
+	var ls []*resilience.RateLimiter
+	if r.global != nil {
+		ls = append(ls, r.global)
+	}
+	if r.hostRate > 0 {
+		r.mu.Lock()
+		l, ok := r.hosts[host]
+		if !ok {
+			l = resilience.NewRateLimiter(r.hostRate, r.hostBurst, resilience.WithLimiterClock(r.clock))
+			r.hosts[host] = l
+		}
+		r.mu.Unlock()
+		ls = append(ls, l)
+	}
+	return ls
+}
+
+// acquire takes a token from every applicable bucket, so a request counts
+// against the global and the host quota at once.
+func (r *rateLimit) acquire(req *http.Request) error {
+	ls := r.limiters(req.URL.Host)
+	var wait time.Duration
+	for _, l := range ls {
+		wait = max(wait, l.Reserve())
+	}
+	if wait <= 0 {
+		return nil
+	}
+	cancel := func() {
+		for _, l := range ls {
+			l.Cancel()
+		}
+	}
+	if r.failFast {
+		cancel()
+		return ErrRateLimited
+	}
+	if err := clock.Sleep(req.Context(), r.clock, wait); err != nil {
+		cancel()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+)
+
+func okTransport() http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: http.NoBody, Request: req}, nil
+	})
+}
+
+func TestRateLimitFailFastPerHost(t *testing.T) {
+	c, _ := New("", WithTransport(okTransport()), WithMiddleware(RateLimit(WithHostLimit(0.001, 2), WithFailFast())))
+	ctx := context.Background()
+	for i := range 2 {
+		if _, err := c.Get(ctx, "http://a.example/"); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if _, err := c.Get(ctx, "http://a.example/"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("third call to a: %v", err)
+	}
+	if _, err := c.Get(ctx, "http://b.example/"); err != nil {
+		t.Errorf("other host limited: %v", err)
+	}
+}
+
+func TestRateLimitGlobalBlocks(t *testing.T) {
+	c, _ := New("", WithTransport(okTransport()), WithMiddleware(RateLimit(WithGlobalLimit(50, 1))))
+	start := time.Now()
+	for _, host := range []string{"a", "b", "c"} {
+		if _, err := c.Get(context.Background(), "http://"+host+".example/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("three calls at 50/s took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	slow, _ := New("", WithTransport(okTransport()), WithMiddleware(RateLimit(WithGlobalLimit(0.001, 1))))
+	slow.Get(ctx, "http://a.example/")
+	if _, err := slow.Get(ctx, "http://a.example/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRateLimitClock(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	c, _ := New("", WithTransport(okTransport()), WithMiddleware(RateLimit(WithHostLimit(1, 1), WithRateLimitClock(clk))))
+	ctx := context.Background()
+	if _, err := c.Get(ctx, "http://a.example/"); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := c.Get(ctx, "http://a.example/")
+		done <- err
+	}()
+	clk.BlockUntil(1)
+	select {
+	case <-done:
+		t.Fatal("second call did not wait for a token")
+	default:
+	}
+	clk.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("after a second: %v", err)
+	}
+}
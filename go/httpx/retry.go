@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+// retryableStatus signals a response whose status the policy retries.
+type retryableStatus struct{ code int }
+
+func (e *retryableStatus) Error() string {
+	return fmt.Sprintf("httpx: retryable status %d", e.code)
+}
+
+// Class is Unavailable for statuses that would class as caller faults,
+// since the policy chose to retry them.
+func (e *retryableStatus) Class() errorsx.Class {
+	if c := errorsx.FromHTTPStatus(e.code); !c.CallerFault() {
+		return c
+	}
+	return errorsx.Unavailable
+}
+
+// Retry retries failed requests according to p: transport errors other
+// than cancellation, and responses whose status is in p.RetryStatus (by
+// default retry.DefaultRetryStatus). A Retry-After header on such a
+// response replaces the backoff delay. When attempts run out the last
+// response is returned as is.
+//
+// Requests with a body are retried only if it can be replayed through
+// GetBody, which http.NewRequest sets for in-memory readers. Retry does
+// not distinguish idempotent methods; add it only where repeating a
+// request is safe.
+func Retry(p retry.Policy) Middleware {
+	statuses := p.RetryStatus
+	if statuses == nil {
+		statuses = retry.DefaultRetryStatus
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+				return next.RoundTrip(req)
+			}
+			var resp *http.Response
+			first := true
+			err := retry.Do(req.Context(), p, func(ctx context.Context) error {
+				if resp != nil {
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+					resp = nil
+				}
+				attempt := req
+				if !first && req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return retry.Permanent(err)
+					}
+					attempt = req.Clone(ctx)
+					attempt.Body = body
+				}
+				first = false
+				var err error
+				resp, err = next.RoundTrip(attempt)
+				if err != nil {
+					return err
+				}
+				if !slices.Contains(statuses, resp.StatusCode) {
+					return nil
+				}
+				rerr := &retryableStatus{code: resp.StatusCode}
+				if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+					return retry.After(rerr, d)
+				}
+				return rerr
+			})
+			if err == nil {
+				return resp, nil
+			}
+			if resp != nil {
+				var rs *retryableStatus
+				if errors.As(err, &rs) {
+					return resp, nil
+				}
+				resp.Body.Close()
+			}
+			return nil, err
+		})
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+var fastRetry = retry.Policy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+func TestRetryStatusAndBodyReplay(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL, WithMiddleware(Retry(fastRetry)))
+	resp, err := c.Post(context.Background(), "/notifications", strings.NewReader(`{"user_id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 || string(resp.Body) != `{"user_id":1}` {
+		t.Errorf("calls %d body %q", calls.Load(), resp.Body)
+	}
+}
+
+func TestRetryExhaustedReturnsLastResponse(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "overloaded", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL, WithMiddleware(Retry(fastRetry)))
+	resp, err := c.Get(context.Background(), "/users/1")
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusTooManyRequests || !strings.Contains(string(resp.Body), "overloaded") {
+		t.Errorf("resp %v err %v", resp, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d", calls.Load())
+	}
+
+	calls.Store(0)
+	notFound := fastRetry
+	notFound.RetryStatus = []int{http.StatusNotFound}
+	c, _ = New(srv.URL, WithMiddleware(Retry(notFound)))
+	c.Get(context.Background(), "/users/1")
+	if calls.Load() != 1 {
+		t.Errorf("429 retried although not listed: %d calls", calls.Load())
+	}
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	var calls atomic.Int32
+	failing := RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return nil, errors.New("connection reset")
+	})
+	c, _ := New("http://api.invalid", WithTransport(failing), WithMiddleware(Retry(fastRetry)))
+	_, err := c.Get(context.Background(), "/")
+	var rerr *retry.Error
+	if !errors.As(err, &rerr) || calls.Load() != 3 {
+		t.Errorf("err %v calls %d", err, calls.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d, ok := retryAfter("7"); !ok || d != 7*time.Second {
+		t.Errorf("seconds: %v %v", d, ok)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d, ok := retryAfter(date); !ok || d < 59*time.Minute {
+		t.Errorf("date: %v %v", d, ok)
+	}
+	if _, ok := retryAfter("soon"); ok {
+		t.Error("invalid value accepted")
+	}
+}
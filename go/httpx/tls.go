@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+)
+
+// TLSConfig describes the TLS side of a transport: a client certificate
+// for mutual TLS, the CAs trusted for server certificates, and an SNI
+// override. Certificates and keys come from files or PEM bytes; when both
+// are set the file wins, so a config loaded from settings can fall back
+// to embedded material.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	CertPEM  []byte
+	KeyPEM   []byte
+
+	// CAFile and CAPEM hold PEM bundles of trusted CAs. Both may be set.
+	// Without either the system roots are used.
+	CAFile string
+	CAPEM  []byte
+	// SystemRoots keeps trusting the system roots alongside the custom CAs.
+	SystemRoots bool
+
+	// ServerName overrides the name sent in SNI and verified against the
+	// server certificate, e.g. when dialing an internal service by IP.
+	ServerName string
+	// MinVersion defaults to TLS 1.2.
+	MinVersion uint16
+}
+
+// Config reads the certificate material and returns a *tls.Config.
+func (c TLSConfig) Config() (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: c.ServerName, MinVersion: c.MinVersion}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	certPEM, err := readPEM(c.CertFile, c.CertPEM)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := readPEM(c.KeyFile, c.KeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(certPEM) > 0 && len(keyPEM) > 0:
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("httpx: loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case len(certPEM) > 0 || len(keyPEM) > 0:
+		return nil, errors.New("httpx: client certificate and key must be set together")
+	}
+
+	if c.CAFile != "" || len(c.CAPEM) > 0 {
+		pool := x509.NewCertPool()
+		if c.SystemRoots {
+			if sys, err := x509.SystemCertPool(); err == nil {
+				pool = sys
+			}
+		}
+		if c.CAFile != "" {
+			data, err := readPEM(c.CAFile, nil)
+			if err != nil {
+				return nil, err
+			}
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("httpx: no certificates found in %s", c.CAFile)
+			}
+		}
+		if len(c.CAPEM) > 0 && !pool.AppendCertsFromPEM(c.CAPEM) {
+			return nil, errors.New("httpx: no certificates found in CA PEM")
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+func readPEM(path string, fallback []byte) ([]byte, error) {
+	if path == "" {
+		return fallback, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("httpx: reading TLS material: %w", err)
+	}
+	return data, nil
+}
+
+// TLSTransport is an http.RoundTripper using a TLSConfig. Reload re-reads
+// the certificate files, so rotated certificates are picked up without
+// rebuilding clients:
+//
+//	tr, err := httpx.NewTLSTransport(httpx.TLSConfig{
+//		CertFile: "/etc/certs/client.crt",
+//		KeyFile:  "/etc/certs/client.key",
+//		CAFile:   "/etc/certs/ca.crt",
+//	})
+//	client, err := httpx.New("https://billing.internal", httpx.WithTransport(tr))
+//	cfg.OnChange(func(_, _ *config.Config) { tr.Reload() })
+//
+// It is safe for concurrent use.
+type TLSTransport struct {
+	cfg     TLSConfig
+	base    *http.Transport
+	current atomic.Pointer[http.Transport]
+}
+
+// NewTLSTransport creates a transport for cfg, based on a clone of
+// http.DefaultTransport.
+func NewTLSTransport(cfg TLSConfig) (*TLSTransport, error) {
+	t := &TLSTransport{cfg: cfg, base: http.DefaultTransport.(*http.Transport).Clone()}
+	if err := t.Reload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reload re-reads the certificate material. New connections use it; idle
+// connections made with the old material are closed and requests in
+// flight finish on theirs. If the material cannot be loaded the transport
+// keeps the previous one and the error is returned.
+func (t *TLSTransport) Reload() error {
+	tc, err := t.cfg.Config()
+	if err != nil {
+		return err
+	}
+	tr := t.base.Clone()
+	tr.TLSClientConfig = tc
+	if old := t.current.Swap(tr); old != nil {
+		old.CloseIdleConnections()
+	}
+	return nil
+}
+
+// RoundTrip sends req over the current transport.
+func (t *TLSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.current.Load().RoundTrip(req)
+}
+
+// CloseIdleConnections closes idle connections of the current transport.
+func (t *TLSTransport) CloseIdleConnections() {
+	t.current.Load().CloseIdleConnections()
+}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key signed by ca.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// mtlsServer serves TLS as billing.internal and requires a client
+// certificate signed by ca.
+func mtlsServer(t *testing.T, ca *testCA) *httptest.Server {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, "billing.internal", x509.ExtKeyUsageServerAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients := x509.NewCertPool()
+	clients.AddCert(ca.cert)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, ClientCAs: clients, ClientAuth: tls.RequireAndVerifyClientCert}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTLSTransportMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	srv := mtlsServer(t, ca)
+	certPEM, keyPEM := ca.issue(t, "svc", x509.ExtKeyUsageClientAuth)
+
+	tr, err := NewTLSTransport(TLSConfig{CertPEM: certPEM, KeyPEM: keyPEM, CAPEM: ca.pem, ServerName: "billing.internal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := New(srv.URL, WithTransport(tr))
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body) != "svc" {
+		t.Errorf("peer = %q", resp.Body)
+	}
+
+	noSNI, _ := NewTLSTransport(TLSConfig{CertPEM: certPEM, KeyPEM: keyPEM, CAPEM: ca.pem})
+	c, _ = New(srv.URL, WithTransport(noSNI))
+	if _, err := c.Get(context.Background(), "/"); err == nil {
+		t.Error("certificate for billing.internal accepted for 127.0.0.1")
+	}
+}
+
+func TestTLSTransportReload(t *testing.T) {
+	ca := newTestCA(t)
+	srv := mtlsServer(t, ca)
+	dir := t.TempDir()
+	cfg := TLSConfig{
+		CertFile:   filepath.Join(dir, "client.crt"),
+		KeyFile:    filepath.Join(dir, "client.key"),
+		CAFile:     filepath.Join(dir, "ca.crt"),
+		ServerName: "billing.internal",
+	}
+	write := func(certPEM, keyPEM []byte) {
+		os.WriteFile(cfg.CertFile, certPEM, 0o600)
+		os.WriteFile(cfg.KeyFile, keyPEM, 0o600)
+	}
+	os.WriteFile(cfg.CAFile, ca.pem, 0o600)
+	write(newTestCA(t).issue(t, "stranger", x509.ExtKeyUsageClientAuth))
+
+	tr, err := NewTLSTransport(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := New(srv.URL, WithTransport(tr))
+	if _, err := c.Get(context.Background(), "/"); err == nil {
+		t.Fatal("certificate from an unknown CA accepted")
+	}
+
+	write(ca.issue(t, "svc", x509.ExtKeyUsageClientAuth))
+	if err := tr.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil || string(resp.Body) != "svc" {
+		t.Fatalf("after reload: %v, %q", err, resp.Body)
+	}
+
+	os.WriteFile(cfg.KeyFile, []byte("garbage"), 0o600)
+	if err := tr.Reload(); err == nil {
+		t.Error("Reload accepted a broken key")
+	}
+	if _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Errorf("failed reload replaced the working transport: %v", err)
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	for name, cfg := range map[string]TLSConfig{
+		"cert without key": {CertPEM: []byte("x")},
+		"missing file":     {CAFile: filepath.Join(t.TempDir(), "none.pem")},
+		"empty CA":         {CAPEM: []byte("not a certificate")},
+	} {
+		if _, err := cfg.Config(); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
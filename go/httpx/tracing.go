@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+// tracing starts a client span for every attempt that reaches next, as a
+// child of the span in the request context, and propagates it to the
+// server in the traceparent and tracestate headers. Spans follow the
+// OpenTelemetry HTTP client conventions and are named after the method.
+func tracing(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		ctx, span := trace.Start(req.Context(), req.Method,
+			trace.WithKind(trace.SpanKindClient),
+			trace.WithAttr("http.request.method", req.Method),
+			trace.WithAttr("url.full", req.URL.Redacted()),
+			trace.WithAttr("server.address", req.URL.Hostname()))
+		defer span.End()
+		req = req.Clone(ctx)
+		trace.Inject(ctx, req.Header)
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			span.RecordError(err)
+			return resp, err
+		}
+		span.SetAttr("http.response.status_code", resp.StatusCode)
+		if resp.StatusCode >= 400 {
+			span.SetStatus(trace.StatusError, strconv.Itoa(resp.StatusCode)+" "+http.StatusText(resp.StatusCode))
+		}
+		return resp, nil
+	})
+}
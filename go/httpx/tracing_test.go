@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+}
+
+func TestClientSpans(t *testing.T) {
+	rec := &spanRecorder{}
+	trace.SetExporter(rec)
+	defer trace.SetExporter(nil)
+
+	var got trace.SpanContext
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = trace.SpanContextFromContext(trace.Extract(r.Context(), r.Header))
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parent trace.SpanContext
+	trace.WithSpan(context.Background(), "user.get", func(ctx context.Context) error {
+		parent = trace.SpanContextFromContext(ctx)
+		_, err := c.Get(ctx, "/users/1")
+		return err
+	})
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("got %d spans", len(rec.spans))
+	}
+	span := rec.spans[0]
+	if span.Name != "GET" || span.Kind != trace.SpanKindClient || span.Parent != parent.SpanID {
+		t.Errorf("client span = %+v", span)
+	}
+	if got.TraceID != parent.TraceID || got.SpanID != span.SpanContext.SpanID {
+		t.Errorf("server saw %v, want client span %v", got, span.SpanContext)
+	}
+	if span.Status != trace.StatusError {
+		t.Errorf("status = %v for a 404", span.Status)
+	}
+	var code any
+	for _, a := range span.Attrs {
+		if a.Key == "http.response.status_code" {
+			code = a.Value
+		}
+	}
+	if code != http.StatusNotFound {
+		t.Errorf("http.response.status_code = %v", code)
+	}
+}
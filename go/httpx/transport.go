@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// UnixTransport returns a transport that sends every request over the
+// unix domain socket at path, whatever the host in the request URL.
+func UnixTransport(path string) *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = nil
+	var d net.Dialer
+	tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return d.DialContext(ctx, "unix", path)
+	}
+	return tr
+}
+
+// HTTP2Transport returns a transport that speaks only HTTP/2 over TLS, for
+// servers where a fallback to HTTP/1.1 would hide a misconfiguration.
+func HTTP2Transport() *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Protocols = new(http.Protocols)
+	tr.Protocols.SetHTTP2(true)
+	return tr
+}
+
+// H2CTransport returns a transport that speaks HTTP/2 without TLS (h2c)
+// to http:// URLs, as gRPC gateways and most sidecars expect.
+func H2CTransport() *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Protocols = new(http.Protocols)
+	tr.Protocols.SetUnencryptedHTTP2(true)
+	return tr
+}
+
+// routeScheme maps the non-HTTP schemes New accepts for base URLs to an
+// HTTP base and the transport that reaches it, like the scheme routing of
+// the Python UniversalClient:
+//
+//	unix:///var/run/api.sock  requests go over the socket
+//	h2c://sidecar:8080        requests use HTTP/2 without TLS
+//
+// It returns a nil transport for http and https.
+func routeScheme(base *url.URL) (*url.URL, http.RoundTripper) {
+	switch base.Scheme {
+	case "unix":
+		return &url.URL{Scheme: "http", Host: "localhost"}, UnixTransport(base.Path)
+	case "h2c":
+		u := *base
+		u.Scheme = "http"
+		return &u, H2CTransport()
+	}
+	return base, nil
+}
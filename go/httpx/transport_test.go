@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package httpx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// serve runs srv on l until the test ends.
+func serve(t *testing.T, l net.Listener, srv *http.Server) {
+	t.Helper()
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+}
+
+func TestUnixSocketBaseURL(t *testing.T) {
+	// Socket paths are limited to about 100 bytes, which t.TempDir can
+	// exceed.
+	dir, err := os.MkdirTemp("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	sock := filepath.Join(dir, "api.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	serve(t, l, &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})})
+
+	c, err := New("unix://" + sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Get(context.Background(), "/v1/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body) != "/v1/health" {
+		t.Errorf("path = %q", resp.Body)
+	}
+
+	if _, err := New("unix://"); err == nil {
+		t.Error("unix URL without a socket path accepted")
+	}
+}
+
+func TestH2CBaseURL(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Protocols: new(http.Protocols), Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})}
+	srv.Protocols.SetHTTP1(true)
+	srv.Protocols.SetUnencryptedHTTP2(true)
+	serve(t, l, srv)
+
+	c, err := New("h2c://" + l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body) != "HTTP/2.0" {
+		t.Errorf("proto = %q", resp.Body)
+	}
+
+	plain, _ := New("http://" + l.Addr().String())
+	if resp, _ := plain.Get(context.Background(), "/"); string(resp.Body) != "HTTP/1.1" {
+		t.Errorf("http proto = %q", resp.Body)
+	}
+}
@@ -0,0 +1,360 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hub is the Go counterpart of the Python foundation Hub: a
+// registry where components are registered by name within a dimension
+// such as command, resource or client, discovered by dimension or
+// metadata, and built lazily on first use.
+//
+//	h := hub.New()
+//	h.RegisterLazy(hub.Client, "billing", func(ctx context.Context) (any, error) {
+//		return httpx.New(os.Getenv("BILLING_URL"))
+//	}, hub.WithDescription("Billing API client"))
+//
+//	billing, err := hub.Get[*httpx.Client](ctx, h, hub.Client, "billing")
+//
+// Where the container package wires values by type, the hub names them,
+// so tools and other languages can list and address them.
+package hub
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/provide-io/provide-foundation/go/container"
+)
+
+// Dimension categorizes components. The constants match the Python
+// ComponentCategory values; other strings work as well.
+type Dimension string
+
+// Standard dimensions.
+const (
+	Command      Dimension = "command"
+	Component    Dimension = "component"
+	Resource     Dimension = "resource"
+	Client       Dimension = "client"
+	ConfigSource Dimension = "config_source"
+	Processor    Dimension = "processor"
+	ErrorHandler Dimension = "error_handler"
+	Formatter    Dimension = "formatter"
+	Filter       Dimension = "filter"
+	Transport    Dimension = "transport"
+)
+
+var (
+	// ErrNotFound is matched by errors.Is when no component has the name.
+	ErrNotFound = errors.New("hub: component not found")
+	// ErrExists is matched by errors.Is when a name is already taken.
+	ErrExists = errors.New("hub: component already registered")
+)
+
+// Factory builds a lazily registered component.
+type Factory func(ctx context.Context) (any, error)
+
+// Entry describes a registered component.
+type Entry struct {
+	Name        string
+	Dimension   Dimension
+	Description string
+	Aliases     []string
+	Metadata    map[string]any
+	// Lazy is set for components registered with a factory, and
+	// Initialized once the factory has succeeded.
+	Lazy        bool
+	Initialized bool
+}
+
+// Option configures a registration.
+type Option func(*entry)
+
+// WithDescription sets a one-line description, shown in listings.
+func WithDescription(s string) Option {
+	return func(e *entry) { e.info.Description = s }
+}
+
+// WithMetadata adds a metadata key.
+func WithMetadata(key string, value any) Option {
+	return func(e *entry) { e.info.Metadata[key] = value }
+}
+
+// WithAliases adds other names the component is found by within its
+// dimension.
+func WithAliases(aliases ...string) Option {
+	return func(e *entry) { e.info.Aliases = append(e.info.Aliases, aliases...) }
+}
+
+// Replace allows the registration to replace a component of the same name.
+func Replace() Option {
+	return func(e *entry) { e.replace = true }
+}
+
+type entry struct {
+	info    Entry
+	replace bool
+
+	mu      sync.Mutex // serializes the factory
+	factory Factory
+	value   any
+	ready   bool
+}
+
+type key struct {
+	dim  Dimension
+	name string
+}
+
+// Hub is a registry of named components. It is safe for concurrent use.
+type Hub struct {
+	mu      sync.RWMutex
+	entries map[key]*entry
+	aliases map[key]string
+	built   []*entry        // initialized entries, in build order
+	loaded  map[string]bool // plugins loaded by LoadPlugins
+}
+
+// New returns an empty hub.
+func New() *Hub {
+	return &Hub{entries: make(map[key]*entry), aliases: make(map[key]string), loaded: make(map[string]bool)}
+}
+
+var defaultHub = New()
+
+// Default returns the process-wide hub, into which packages register
+// their components at init time.
+func Default() *Hub { return defaultHub }
+
+// Register adds value under name in dim. It fails with ErrExists if the
+// name or an alias is taken, unless Replace is given.
+func (h *Hub) Register(dim Dimension, name string, value any, opts ...Option) error {
+	e := newEntry(dim, name, opts)
+	e.value, e.ready = value, true
+	return h.add(e)
+}
+
+// RegisterLazy adds a component built by factory on its first Get. A
+// failed build is retried by the next Get.
+func (h *Hub) RegisterLazy(dim Dimension, name string, factory Factory, opts ...Option) error {
+	if factory == nil {
+		return fmt.Errorf("hub: %s %q: nil factory", dim, name)
+	}
+	e := newEntry(dim, name, opts)
+	e.factory = factory
+	e.info.Lazy = true
+	return h.add(e)
+}
+
+// MustRegister is like Register but panics on error. It is intended for
+// init functions.
+func (h *Hub) MustRegister(dim Dimension, name string, value any, opts ...Option) {
+	if err := h.Register(dim, name, value, opts...); err != nil {
+		panic(err)
+	}
+}
+
+func newEntry(dim Dimension, name string, opts []Option) *entry {
+	e := &entry{info: Entry{Name: name, Dimension: dim, Metadata: map[string]any{}}}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+func (h *Hub) add(e *entry) error {
+	if e.info.Name == "" || e.info.Dimension == "" {
+		return fmt.Errorf("hub: component needs a name and a dimension")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	dim := e.info.Dimension
+	k := key{dim, e.info.Name}
+	for _, n := range append([]string{e.info.Name}, e.info.Aliases...) {
+		if owner, taken := h.owner(dim, n); taken && owner != e.info.Name {
+			return fmt.Errorf("%w: %s %q is an alias of %q", ErrExists, dim, n, owner)
+		}
+	}
+	if _, ok := h.entries[k]; ok {
+		if !e.replace {
+			return fmt.Errorf("%w: %s %q", ErrExists, dim, e.info.Name)
+		}
+		h.removeLocked(k)
+	}
+	h.entries[k] = e
+	for _, a := range e.info.Aliases {
+		h.aliases[key{dim, a}] = e.info.Name
+	}
+	if e.ready {
+		h.built = append(h.built, e)
+	}
+	return nil
+}
+
+// owner returns the component name that n denotes in dim, if any.
+func (h *Hub) owner(dim Dimension, n string) (string, bool) {
+	if _, ok := h.entries[key{dim, n}]; ok {
+		return n, true
+	}
+	name, ok := h.aliases[key{dim, n}]
+	return name, ok
+}
+
+func (h *Hub) lookup(dim Dimension, name string) (*entry, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if real, ok := h.owner(dim, name); ok {
+		return h.entries[key{dim, real}], nil
+	}
+	return nil, fmt.Errorf("%w: %s %q", ErrNotFound, dim, name)
+}
+
+// Get returns the component name, or one it is an alias of, building it
+// if it is lazy. A factory must not Get its own component.
+func (h *Hub) Get(ctx context.Context, dim Dimension, name string) (any, error) {
+	e, err := h.lookup(dim, name)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ready {
+		return e.value, nil
+	}
+	v, err := e.factory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("hub: building %s %q: %w", dim, e.info.Name, err)
+	}
+	h.mu.Lock()
+	e.value, e.ready = v, true
+	h.built = append(h.built, e)
+	h.mu.Unlock()
+	return v, nil
+}
+
+// Get returns the component as a T. It fails if the component has another
+// type.
+func Get[T any](ctx context.Context, h *Hub, dim Dimension, name string) (T, error) {
+	var zero T
+	v, err := h.Get(ctx, dim, name)
+	if err != nil {
+		return zero, err
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("hub: %s %q is a %T, not a %s", dim, name, v, reflect.TypeFor[T]())
+	}
+	return t, nil
+}
+
+// Entry describes the component name without building it.
+func (h *Hub) Entry(dim Dimension, name string) (Entry, bool) {
+	e, err := h.lookup(dim, name)
+	if err != nil {
+		return Entry{}, false
+	}
+	return e.snapshot(), true
+}
+
+func (e *entry) snapshot() Entry {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	info := e.info
+	info.Aliases = slices.Clone(info.Aliases)
+	info.Metadata = maps.Clone(info.Metadata)
+	info.Initialized = e.ready
+	return info
+}
+
+// List returns the components of dim sorted by name.
+func (h *Hub) List(dim Dimension) []Entry {
+	return h.Find(func(e Entry) bool { return e.Dimension == dim })
+}
+
+// Find returns the components for which match reports true, sorted by
+// dimension and name. A nil match returns every component.
+func (h *Hub) Find(match func(Entry) bool) []Entry {
+	h.mu.RLock()
+	all := slices.Collect(maps.Values(h.entries))
+	h.mu.RUnlock()
+	var out []Entry
+	for _, e := range all {
+		info := e.snapshot()
+		if match == nil || match(info) {
+			out = append(out, info)
+		}
+	}
+	slices.SortFunc(out, func(a, b Entry) int {
+		return cmp.Or(cmp.Compare(a.Dimension, b.Dimension), cmp.Compare(a.Name, b.Name))
+	})
+	return out
+}
+
+// Dimensions returns the dimensions that have components, sorted.
+func (h *Hub) Dimensions() []Dimension {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var out []Dimension
+	for k := range h.entries {
+		if !slices.Contains(out, k.dim) {
+			out = append(out, k.dim)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Remove unregisters a component without closing it and reports whether
+// it existed.
+func (h *Hub) Remove(dim Dimension, name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	real, ok := h.owner(dim, name)
+	if ok {
+		h.removeLocked(key{dim, real})
+	}
+	return ok
+}
+
+func (h *Hub) removeLocked(k key) {
+	e := h.entries[k]
+	delete(h.entries, k)
+	for _, a := range e.info.Aliases {
+		delete(h.aliases, key{k.dim, a})
+	}
+	h.built = slices.DeleteFunc(h.built, func(b *entry) bool { return b == e })
+}
+
+// Close releases the built components in reverse build order, calling
+// OnStop for a container.Stopper and Close for an io.Closer, and empties
+// the hub, so LoadPlugins loads every plugin again. It returns every
+// error joined.
+func (h *Hub) Close(ctx context.Context) error {
+	h.mu.Lock()
+	built := h.built
+	h.entries = make(map[key]*entry)
+	h.aliases = make(map[key]string)
+	h.loaded = make(map[string]bool)
+	h.built = nil
+	h.mu.Unlock()
+
+	var errs []error
+	for _, e := range slices.Backward(built) {
+		var err error
+		switch v := e.value.(type) {
+		case container.Stopper:
+			err = v.OnStop(ctx)
+		case io.Closer:
+			err = v.Close()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hub: closing %s %q: %w", e.info.Dimension, e.info.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
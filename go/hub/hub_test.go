@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package hub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type closer struct {
+	name   string
+	closed *[]string
+}
+
+func (c closer) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	if c.name == "bad" {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func TestRegisterAndGet(t *testing.T) {
+	ctx := context.Background()
+	h := New()
+	if err := h.Register(Resource, "cache", 42, WithAliases("kv"), WithDescription("shared cache"), WithMetadata("version", "1.2")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"cache", "kv"} {
+		if v, err := Get[int](ctx, h, Resource, name); err != nil || v != 42 {
+			t.Errorf("Get(%q) = %v, %v", name, v, err)
+		}
+	}
+	if _, err := h.Get(ctx, Client, "cache"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("other dimension = %v", err)
+	}
+	if _, err := Get[string](ctx, h, Resource, "cache"); err == nil || !strings.Contains(err.Error(), "is a int, not a string") {
+		t.Errorf("wrong type = %v", err)
+	}
+	if err := h.Register(Resource, "cache", 1); !errors.Is(err, ErrExists) {
+		t.Errorf("duplicate = %v", err)
+	}
+	if err := h.Register(Resource, "kv", 1); !errors.Is(err, ErrExists) {
+		t.Errorf("name taken by alias = %v", err)
+	}
+	if err := h.Register(Resource, "cache", 7, Replace()); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := Get[int](ctx, h, Resource, "cache"); v != 7 {
+		t.Errorf("replaced value = %d", v)
+	}
+	if _, err := h.Get(ctx, Resource, "kv"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("alias survived replace: %v", err)
+	}
+	if !h.Remove(Resource, "cache") || h.Remove(Resource, "cache") {
+		t.Error("Remove did not report existence")
+	}
+}
+
+func TestLazy(t *testing.T) {
+	ctx := context.Background()
+	h := New()
+	var builds atomic.Int32
+	fail := true
+	h.RegisterLazy(Client, "billing", func(context.Context) (any, error) {
+		builds.Add(1)
+		if fail {
+			return nil, errors.New("no credentials")
+		}
+		return "client", nil
+	})
+	if e, _ := h.Entry(Client, "billing"); !e.Lazy || e.Initialized {
+		t.Errorf("entry before build = %+v", e)
+	}
+	if _, err := h.Get(ctx, Client, "billing"); err == nil || err.Error() != `hub: building client "billing": no credentials` {
+		t.Errorf("failed build = %v", err)
+	}
+	fail = false
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := h.Get(ctx, Client, "billing"); err != nil || v != "client" {
+				t.Errorf("Get = %v, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := builds.Load(); n != 2 {
+		t.Errorf("factory ran %d times, want 2", n)
+	}
+	if e, _ := h.Entry(Client, "billing"); !e.Initialized {
+		t.Errorf("entry after build = %+v", e)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	h := New()
+	h.MustRegister(Command, "serve", nil)
+	h.MustRegister(Command, "migrate", nil, WithMetadata("group", "db"))
+	h.MustRegister(Client, "billing", nil, WithMetadata("group", "db"))
+	h.MustRegister(Dimension("widget"), "knob", nil)
+
+	var names []string
+	for _, e := range h.List(Command) {
+		names = append(names, e.Name)
+	}
+	if strings.Join(names, ",") != "migrate,serve" {
+		t.Errorf("List = %v", names)
+	}
+	found := h.Find(func(e Entry) bool { return e.Metadata["group"] == "db" })
+	if len(found) != 2 || found[0].Dimension != Client || found[1].Name != "migrate" {
+		t.Errorf("Find = %+v", found)
+	}
+	if d := h.Dimensions(); len(d) != 3 || d[2] != "widget" {
+		t.Errorf("Dimensions = %v", d)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustRegister of a duplicate did not panic")
+		}
+	}()
+	h.MustRegister(Command, "serve", nil)
+}
+
+func TestClose(t *testing.T) {
+	ctx := context.Background()
+	h := New()
+	var closed []string
+	h.MustRegister(Resource, "first", closer{"first", &closed})
+	h.RegisterLazy(Resource, "lazy", func(context.Context) (any, error) { return closer{"lazy", &closed}, nil })
+	h.RegisterLazy(Resource, "unused", func(context.Context) (any, error) { return closer{"unused", &closed}, nil })
+	h.MustRegister(Resource, "bad", closer{"bad", &closed})
+	h.Get(ctx, Resource, "lazy")
+
+	err := h.Close(ctx)
+	if err == nil || !strings.Contains(err.Error(), `closing resource "bad": boom`) {
+		t.Errorf("Close = %v", err)
+	}
+	if strings.Join(closed, ",") != "lazy,bad,first" {
+		t.Errorf("closed = %v", closed)
+	}
+	if len(h.Find(nil)) != 0 {
+		t.Error("hub not emptied")
+	}
+}
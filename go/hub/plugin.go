@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package hub
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Plugin is an optional subsystem that adds components to a hub. A plugin
+// package registers itself from an init function,
+//
+//	func init() {
+//		hub.RegisterPlugin(hub.Plugin{
+//			Name:         "metrics/prometheus",
+//			Capabilities: []string{"metrics.exporter"},
+//			Register: func(h *hub.Hub) error {
+//				return h.Register(hub.Component, "prometheus", Handler())
+//			},
+//		})
+//	}
+//
+// so a binary includes it with a blank import, optionally in a file with a
+// build tag so builds choose their subsystems,
+//
+//	//go:build prometheus
+//
+//	package main
+//
+//	import _ "example.com/app/metrics/prometheus"
+//
+// and loads every imported plugin with Hub.LoadPlugins. Binaries only
+// link the plugins they import.
+type Plugin struct {
+	Name        string
+	Description string
+	// Capabilities name what the plugin provides, such as
+	// "metrics.exporter" or "cli.command", for HasCapability.
+	Capabilities []string
+	// Register adds the plugin's components to h.
+	Register func(h *Hub) error
+}
+
+// Metadata keys set on the entries a plugin registers.
+const (
+	MetaPlugin       = "plugin"
+	MetaCapabilities = "capabilities"
+)
+
+var (
+	pluginsMu sync.RWMutex
+	plugins   []Plugin
+)
+
+// RegisterPlugin makes p available to LoadPlugins. It panics if p has no
+// name or Register function or its name is taken, as database/sql.Register
+// does, since both are programming errors found at startup.
+func RegisterPlugin(p Plugin) {
+	if p.Name == "" || p.Register == nil {
+		panic("hub: RegisterPlugin needs a name and a Register function")
+	}
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	if slices.ContainsFunc(plugins, func(q Plugin) bool { return q.Name == p.Name }) {
+		panic(fmt.Sprintf("hub: plugin %q registered twice", p.Name))
+	}
+	p.Capabilities = slices.Clone(p.Capabilities)
+	plugins = append(plugins, p)
+}
+
+// Plugins returns the registered plugins sorted by name.
+func Plugins() []Plugin {
+	pluginsMu.RLock()
+	out := slices.Clone(plugins)
+	pluginsMu.RUnlock()
+	slices.SortFunc(out, func(a, b Plugin) int { return cmp.Compare(a.Name, b.Name) })
+	return out
+}
+
+// HasCapability reports whether a registered plugin provides capability.
+func HasCapability(capability string) bool {
+	return len(PluginsWith(capability)) > 0
+}
+
+// PluginsWith returns the plugins that provide capability, sorted by name.
+func PluginsWith(capability string) []Plugin {
+	var out []Plugin
+	for _, p := range Plugins() {
+		if slices.Contains(p.Capabilities, capability) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// LoadPlugins registers the components of every plugin not yet loaded
+// into h, in name order, and tags each new entry with the MetaPlugin and
+// MetaCapabilities metadata. A plugin that fails is reported, keeping the
+// components it registered before failing, and the others still load.
+func (h *Hub) LoadPlugins() error {
+	var errs []error
+	for _, p := range Plugins() {
+		h.mu.Lock()
+		if h.loaded[p.Name] {
+			h.mu.Unlock()
+			continue
+		}
+		h.loaded[p.Name] = true
+		before := make(map[key]bool, len(h.entries))
+		for k := range h.entries {
+			before[k] = true
+		}
+		h.mu.Unlock()
+
+		if err := p.Register(h); err != nil {
+			errs = append(errs, fmt.Errorf("hub: plugin %q: %w", p.Name, err))
+		}
+
+		h.mu.RLock()
+		var added []*entry
+		for k, e := range h.entries {
+			if !before[k] {
+				added = append(added, e)
+			}
+		}
+		h.mu.RUnlock()
+		for _, e := range added {
+			e.mu.Lock()
+			e.info.Metadata[MetaPlugin] = p.Name
+			e.info.Metadata[MetaCapabilities] = slices.Clone(p.Capabilities)
+			e.mu.Unlock()
+		}
+	}
+	return errors.Join(errs...)
+}
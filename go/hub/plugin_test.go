@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package hub
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Plugins register at init time, as a blank-imported package would.
+func init() {
+	RegisterPlugin(Plugin{
+		Name:         "test/exporter",
+		Capabilities: []string{"metrics.exporter"},
+		Register: func(h *Hub) error {
+			return h.Register(Component, "exporter", "prometheus")
+		},
+	})
+	RegisterPlugin(Plugin{
+		Name:         "test/commands",
+		Capabilities: []string{"cli.command"},
+		Register: func(h *Hub) error {
+			h.MustRegister(Command, "hello", "hi")
+			return errors.New("second command unavailable")
+		},
+	})
+}
+
+func TestPlugins(t *testing.T) {
+	if !HasCapability("metrics.exporter") || HasCapability("crypto") {
+		t.Error("HasCapability wrong")
+	}
+	if ps := PluginsWith("cli.command"); len(ps) != 1 || ps[0].Name != "test/commands" {
+		t.Errorf("PluginsWith = %+v", ps)
+	}
+	var names []string
+	for _, p := range Plugins() {
+		names = append(names, p.Name)
+	}
+	if !slices.IsSorted(names) || len(names) < 2 {
+		t.Errorf("Plugins = %v", names)
+	}
+
+	h := New()
+	err := h.LoadPlugins()
+	if err == nil || !strings.Contains(err.Error(), `plugin "test/commands": second command unavailable`) {
+		t.Errorf("LoadPlugins = %v", err)
+	}
+	e, ok := h.Entry(Component, "exporter")
+	if !ok || e.Metadata[MetaPlugin] != "test/exporter" {
+		t.Errorf("exporter entry = %+v", e)
+	}
+	if e, _ := h.Entry(Command, "hello"); !slices.Equal(e.Metadata[MetaCapabilities].([]string), []string{"cli.command"}) {
+		t.Errorf("hello entry = %+v", e)
+	}
+	if err := h.LoadPlugins(); err != nil {
+		t.Errorf("second LoadPlugins = %v", err)
+	}
+}
+
+func TestRegisterPluginPanics(t *testing.T) {
+	for _, p := range []Plugin{
+		{Name: "test/exporter", Register: func(*Hub) error { return nil }},
+		{Name: "test/no-register"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterPlugin(%q) did not panic", p.Name)
+				}
+			}()
+			RegisterPlugin(p)
+		}()
+	}
+}
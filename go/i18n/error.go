@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package i18n
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Error is an error with a translatable message. Its Error method returns
+// the message in DefaultLocale, for logs; Localize returns it in the
+// locale of the user.
+type Error struct {
+	Key  string
+	Args []any
+	// Err is the cause, appended to the message after ": ".
+	Err error
+}
+
+// NewError returns an error with the message of key and args, as T.
+func NewError(key string, args ...any) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+// Wrap returns an error with the message of key and args, caused by err.
+func Wrap(err error, key string, args ...any) *Error {
+	return &Error{Key: key, Args: args, Err: err}
+}
+
+func (e *Error) Error() string {
+	msg := Default.Message(DefaultLocale, e.Key, e.Args...)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Localize returns the message of err in the locale of ctx: the message of
+// the first *Error in its chain is translated, along with its cause, and
+// the text the errors wrapping it add is kept.
+func Localize(ctx context.Context, err error) string {
+	var e *Error
+	if !errors.As(err, &e) {
+		return err.Error()
+	}
+	msg := T(ctx, e.Key, e.Args...)
+	if e.Err != nil {
+		msg += ": " + Localize(ctx, e.Err)
+	}
+	return strings.Replace(err.Error(), e.Error(), msg, 1)
+}
@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package i18n translates user-facing messages. Messages are looked up by
+// key in a catalog of the locale of the context, and {name} placeholders
+// are filled from key-value pairs:
+//
+//	//go:embed locales
+//	var locales embed.FS
+//
+//	err := i18n.Default.LoadFS(locales, "locales") // en.json, de.yaml, ...
+//	msg := i18n.T(ctx, "user.not_found", "id", 42)  // "user 42 not found"
+//
+// The locale is the one set with WithLocale, or else the process locale
+// from FOUNDATION_LOCALE, LC_ALL, LC_MESSAGES or LANG. A message missing
+// in the locale falls back to its language, then to English, then to the
+// key itself. Errors made with NewError are translated when the cli
+// package reports them, without changing the code that returns them.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/provide-io/provide-foundation/go/serde"
+)
+
+// DefaultLocale is the locale messages fall back to.
+const DefaultLocale = "en"
+
+// Vars are the environment variables consulted by DetectLocale, in order.
+// FOUNDATION_LOCALE sets the locale of the foundation alone, ahead of the
+// POSIX ones.
+var Vars = []string{"FOUNDATION_LOCALE", "LC_ALL", "LC_MESSAGES", "LANG"}
+
+// Normalize returns the tag of a locale name, such as "de-DE" for
+// "de_DE.UTF-8", or DefaultLocale for the POSIX locales C and POSIX. It
+// returns "" for an empty name.
+func Normalize(name string) string {
+	name, _, _ = strings.Cut(strings.TrimSpace(name), ".")
+	name, _, _ = strings.Cut(name, "@")
+	if name == "C" || name == "POSIX" {
+		return DefaultLocale
+	}
+	lang, region, ok := strings.Cut(strings.ReplaceAll(name, "_", "-"), "-")
+	if !ok {
+		return strings.ToLower(lang)
+	}
+	return strings.ToLower(lang) + "-" + strings.ToUpper(region)
+}
+
+// DetectLocale resolves the locale from the variables in Vars using
+// lookup, usually os.LookupEnv, or returns DefaultLocale.
+func DetectLocale(lookup func(string) (string, bool)) string {
+	for _, name := range Vars {
+		if v, ok := lookup(name); ok {
+			if l := Normalize(v); l != "" {
+				return l
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+var (
+	current  atomic.Pointer[string]
+	detected = sync.OnceValue(func() string { return DetectLocale(os.LookupEnv) })
+)
+
+// SetLocale overrides the detected locale of the process, e.g. from a
+// command-line flag.
+func SetLocale(locale string) {
+	l := Normalize(locale)
+	current.Store(&l)
+}
+
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx whose messages are in locale, e.g. the
+// one a request asked for in Accept-Language.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, Normalize(locale))
+}
+
+// Locale returns the locale of ctx: the one set with WithLocale, or else
+// the one passed to SetLocale or detected from the environment on first
+// use.
+func Locale(ctx context.Context) string {
+	if ctx != nil {
+		if l, ok := ctx.Value(localeKey{}).(string); ok && l != "" {
+			return l
+		}
+	}
+	if l := current.Load(); l != nil && *l != "" {
+		return *l
+	}
+	return detected()
+}
+
+// Catalog holds messages by locale and key. It is safe for concurrent
+// use.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string
+}
+
+// NewCatalog returns an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{messages: map[string]map[string]string{}}
+}
+
+//go:embed locales
+var builtin embed.FS
+
+// Default is the catalog used by T and Error, holding the messages of the
+// foundation packages. Applications add theirs with Add or LoadFS.
+var Default = func() *Catalog {
+	c := NewCatalog()
+	if err := c.LoadFS(builtin, "locales"); err != nil {
+		panic(err)
+	}
+	return c
+}()
+
+// Add adds messages by key to locale, replacing those with the same key.
+func (c *Catalog) Add(locale string, messages map[string]string) {
+	locale = Normalize(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[locale] == nil {
+		c.messages[locale] = map[string]string{}
+	}
+	maps.Copy(c.messages[locale], messages)
+}
+
+// LoadFS adds the catalogs in dir of fsys, one file per locale named
+// after it, such as de.json or pt_BR.yaml, in any format serde reads.
+// Nested objects make dotted keys: {"user": {"not_found": "..."}} holds
+// "user.not_found".
+func (c *Catalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		f, err := serde.FormatOf(e.Name())
+		if err != nil {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("i18n: %w", err)
+		}
+		var tree map[string]any
+		if err := serde.Unmarshal(f, data, &tree); err != nil {
+			return fmt.Errorf("i18n: %s: %w", e.Name(), err)
+		}
+		messages := map[string]string{}
+		flatten(messages, "", tree)
+		c.Add(strings.TrimSuffix(e.Name(), path.Ext(e.Name())), messages)
+	}
+	return nil
+}
+
+func flatten(dst map[string]string, prefix string, tree map[string]any) {
+	for k, v := range tree {
+		if sub, ok := v.(map[string]any); ok {
+			flatten(dst, prefix+k+".", sub)
+			continue
+		}
+		dst[prefix+k] = fmt.Sprint(v)
+	}
+}
+
+// Locales returns the locales of the catalog, sorted.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Sorted(maps.Keys(c.messages))
+}
+
+// Lookup returns the message of key in locale, falling back to its
+// language and then DefaultLocale, and reports whether one was found.
+func (c *Catalog) Lookup(locale, key string) (string, bool) {
+	locale = Normalize(locale)
+	lang, _, _ := strings.Cut(locale, "-")
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, l := range []string{locale, lang, DefaultLocale} {
+		if msg, ok := c.messages[l][key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// Message returns the message of key in locale with its placeholders
+// filled from args, alternating names and values as in log calls. A
+// missing message is the key itself, so it shows up untranslated.
+func (c *Catalog) Message(locale, key string, args ...any) string {
+	msg, ok := c.Lookup(locale, key)
+	if !ok {
+		msg = key
+	}
+	if len(args) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// T returns the message of key in the locale of ctx; see Message.
+func (c *Catalog) T(ctx context.Context, key string, args ...any) string {
+	return c.Message(Locale(ctx), key, args...)
+}
+
+// T returns the message of key from Default in the locale of ctx, with
+// {name} placeholders filled from args:
+//
+//	i18n.T(ctx, "user.not_found", "id", 42)
+func T(ctx context.Context, key string, args ...any) string {
+	return Default.T(ctx, key, args...)
+}
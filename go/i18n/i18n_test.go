@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"de_DE.UTF-8":    "de-DE",
+		"pt-br":          "pt-BR",
+		"FR":             "fr",
+		"ca_ES@valencia": "ca-ES",
+		"C.UTF-8":        "en",
+		"POSIX":          "en",
+		"":               "",
+	} {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDetectLocale(t *testing.T) {
+	env := map[string]string{"LANG": "fr_FR.UTF-8", "LC_MESSAGES": "", "LC_ALL": "de_CH"}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	if got := DetectLocale(lookup); got != "de-CH" {
+		t.Errorf("DetectLocale = %q", got)
+	}
+	env["FOUNDATION_LOCALE"] = "es"
+	if got := DetectLocale(lookup); got != "es" {
+		t.Errorf("DetectLocale with FOUNDATION_LOCALE = %q", got)
+	}
+	if got := DetectLocale(func(string) (string, bool) { return "", false }); got != DefaultLocale {
+		t.Errorf("DetectLocale without variables = %q", got)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	c := NewCatalog()
+	err := c.LoadFS(fstest.MapFS{
+		"locales/en.json":    {Data: []byte(`{"user": {"not_found": "user {id} not found", "greeting": "Hello, {name}"}, "bye": "Goodbye"}`)},
+		"locales/de.yaml":    {Data: []byte("user:\n  not_found: Benutzer {id} nicht gefunden\n")},
+		"locales/de_AT.toml": {Data: []byte(`"user.greeting" = "Servus, {name}"`)},
+		"locales/README.md":  {Data: []byte("not a catalog")},
+	}, "locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Locales(); fmt.Sprint(got) != "[de de-AT en]" {
+		t.Errorf("Locales = %v", got)
+	}
+
+	ctx := WithLocale(context.Background(), "de_AT")
+	for _, tt := range []struct {
+		key  string
+		args []any
+		want string
+	}{
+		{"user.greeting", []any{"name", "Ada"}, "Servus, Ada"},
+		{"user.not_found", []any{"id", 42}, "Benutzer 42 nicht gefunden"},
+		{"bye", nil, "Goodbye"},
+		{"user.unknown", nil, "user.unknown"},
+		{"user.greeting", []any{"other", 1}, "Servus, {name}"},
+	} {
+		if got := c.T(ctx, tt.key, tt.args...); got != tt.want {
+			t.Errorf("T(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	if got := c.Message("fr", "user.greeting", "name", "Ada"); got != "Hello, Ada" {
+		t.Errorf("fallback to English = %q", got)
+	}
+
+	if err := c.LoadFS(fstest.MapFS{"l/en.json": {Data: []byte("{")}}, "l"); err == nil {
+		t.Error("invalid catalog loaded")
+	}
+}
+
+func TestLocale(t *testing.T) {
+	SetLocale("fr_FR")
+	defer SetLocale(DefaultLocale)
+	if got := Locale(context.Background()); got != "fr-FR" {
+		t.Errorf("Locale = %q", got)
+	}
+	if got := Locale(WithLocale(context.Background(), "de")); got != "de" {
+		t.Errorf("Locale of context = %q", got)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	Default.Add("en", map[string]string{"test.not_found": "user {id} not found", "test.lookup": "lookup failed"})
+	Default.Add("de", map[string]string{"test.not_found": "Benutzer {id} nicht gefunden", "test.lookup": "Suche fehlgeschlagen"})
+
+	err := fmt.Errorf("app users show: %w", Wrap(NewError("test.not_found", "id", 7), "test.lookup"))
+	if got := err.Error(); got != "app users show: lookup failed: user 7 not found" {
+		t.Errorf("Error = %q", got)
+	}
+	ctx := WithLocale(context.Background(), "de")
+	if got := Localize(ctx, err); got != "app users show: Suche fehlgeschlagen: Benutzer 7 nicht gefunden" {
+		t.Errorf("Localize = %q", got)
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Key != "test.lookup" || errors.Unwrap(e) == nil {
+		t.Errorf("chain = %v", err)
+	}
+	if got := Localize(ctx, errors.New("plain")); got != "plain" {
+		t.Errorf("Localize of a plain error = %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package httplog renders HTTP bodies for the request logs of the client
+// middleware in httpx and the server middleware in httpserver.
+package httplog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Peek reads up to max+1 bytes of r, reporting whether there was more than
+// max. The returned slice holds everything read.
+func Peek(r io.Reader, max int) ([]byte, bool) {
+	data, _ := io.ReadAll(io.LimitReader(r, int64(max)+1))
+	return data, len(data) > max
+}
+
+// FormatBody renders a body for the log: JSON and form bodies have their
+// sensitive fields redacted, other text has secret patterns masked, and
+// binary bodies are left out.
+func FormatBody(r *log.Redactor, h http.Header, data []byte, truncated bool) string {
+	mt, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if truncated {
+		data = data[:len(data)-1]
+	}
+	var s string
+	switch {
+	case !truncated && (mt == "application/json" || strings.HasSuffix(mt, "+json")):
+		var v any
+		if json.Unmarshal(data, &v) != nil {
+			return r.RedactString(string(data))
+		}
+		out, _ := json.Marshal(r.Redact(v))
+		return string(out)
+	case !truncated && mt == "application/x-www-form-urlencoded":
+		q, err := url.ParseQuery(string(data))
+		if err != nil {
+			return r.RedactString(string(data))
+		}
+		red := r.Redact(map[string][]string(q)).(map[string][]string)
+		var parts []string
+		for _, k := range slices.Sorted(maps.Keys(red)) {
+			for _, v := range red[k] {
+				parts = append(parts, k+"="+v)
+			}
+		}
+		return strings.Join(parts, "&")
+	case mt == "" || textual(mt):
+		s = r.RedactString(string(data))
+	default:
+		return fmt.Sprintf("[%s body omitted]", mt)
+	}
+	if truncated {
+		s += "...[truncated]"
+	}
+	return s
+}
+
+func textual(mt string) bool {
+	return strings.HasPrefix(mt, "text/") ||
+		strings.HasSuffix(mt, "json") || strings.HasSuffix(mt, "xml") ||
+		mt == "application/x-www-form-urlencoded"
+}
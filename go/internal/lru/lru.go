@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lru is the store behind cache.Cache and syncx.Memoize: values by
+// key, expiring after a time to live, with the least recently used
+// evicted beyond a maximum count.
+package lru
+
+import (
+	"container/list"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+)
+
+// Eviction reasons passed to the evict callback of New.
+const (
+	Capacity = "capacity"
+	Expired  = "expired"
+)
+
+// Store holds values of type V by keys of type K. It is not safe for
+// concurrent use.
+type Store[K comparable, V any] struct {
+	maxEntries int
+	clock      clock.Clock
+	evict      func(reason string)
+
+	entries map[K]*list.Element // of *entry[K, V]
+	lru     *list.List          // most recently used first
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time // zero for never
+}
+
+// New returns an empty store holding at most maxEntries, or any number
+// when it is 0, whose entries expire by c. evict, if not nil, is called
+// for each entry dropped by capacity or expiry.
+func New[K comparable, V any](maxEntries int, c clock.Clock, evict func(reason string)) *Store[K, V] {
+	return &Store[K, V]{
+		maxEntries: maxEntries,
+		clock:      c,
+		evict:      evict,
+		entries:    make(map[K]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get returns the value of key and whether it was present and live,
+// dropping it if expired.
+func (s *Store[K, V]) Get(key K) (V, bool) {
+	el, ok := s.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	e := el.Value.(*entry[K, V])
+	if !e.expires.IsZero() && !s.clock.Now().Before(e.expires) {
+		s.remove(el, Expired)
+		var zero V
+		return zero, false
+	}
+	s.lru.MoveToFront(el)
+	return e.value, true
+}
+
+// Set stores value under key for ttl, or without expiry when ttl is 0,
+// and evicts beyond the maximum.
+func (s *Store[K, V]) Set(key K, value V, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = s.clock.Now().Add(ttl)
+	}
+	if el, ok := s.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		s.lru.MoveToFront(el)
+		return
+	}
+	s.entries[key] = s.lru.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+	for s.maxEntries > 0 && s.lru.Len() > s.maxEntries {
+		s.remove(s.lru.Back(), Capacity)
+	}
+}
+
+// Delete removes key.
+func (s *Store[K, V]) Delete(key K) {
+	if el, ok := s.entries[key]; ok {
+		s.remove(el, "")
+	}
+}
+
+// Purge removes every entry.
+func (s *Store[K, V]) Purge() {
+	clear(s.entries)
+	s.lru.Init()
+}
+
+// Prune removes the expired entries and returns how many there were.
+func (s *Store[K, V]) Prune() int {
+	now := s.clock.Now()
+	n := 0
+	for el := s.lru.Front(); el != nil; {
+		next := el.Next()
+		if e := el.Value.(*entry[K, V]); !e.expires.IsZero() && !now.Before(e.expires) {
+			s.remove(el, Expired)
+			n++
+		}
+		el = next
+	}
+	return n
+}
+
+// Len returns the number of entries, including expired ones not yet
+// removed.
+func (s *Store[K, V]) Len() int { return s.lru.Len() }
+
+// remove drops an entry, reporting it as evicted for reason unless reason
+// is empty.
+func (s *Store[K, V]) remove(el *list.Element, reason string) {
+	s.lru.Remove(el)
+	delete(s.entries, el.Value.(*entry[K, V]).key)
+	if reason != "" && s.evict != nil {
+		s.evict(reason)
+	}
+}
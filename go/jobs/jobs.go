@@ -0,0 +1,331 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jobs runs background work on a bounded pool of workers, so a
+// request hands off slow side effects, such as delivering a notification,
+// instead of waiting for them:
+//
+//	notifications := jobs.New("notifications", jobs.WithWorkers(4), jobs.WithRetry(retry.DefaultPolicy))
+//	err := notifications.Submit(ctx, func(ctx context.Context) error {
+//		return notifier.Send(ctx, notify.Message{Event: "user.viewed", Data: data})
+//	}, jobs.WithName("user.viewed"))
+//
+// Jobs wait in a queue of fixed size for a free worker. A job runs with
+// the values of the context it was submitted with but not its
+// cancellation, is retried by its policy, and a panic fails the job
+// without taking down the worker. The pool registers with the shutdown
+// coordinator at shutdown.PriorityWorkers: on shutdown it stops taking
+// jobs and drains its queue, and the jobs still running when the deadline
+// passes are cancelled.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/recovery"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+	"github.com/provide-io/provide-foundation/go/shutdown"
+)
+
+var (
+	// ErrQueueFull is returned by TrySubmit when the queue has no room.
+	ErrQueueFull = errorsx.New(errorsx.Unavailable, "jobs: queue is full")
+	// ErrClosed is returned for jobs submitted once the pool is shutting
+	// down.
+	ErrClosed = errorsx.New(errorsx.Unavailable, "jobs: pool is closed")
+)
+
+// Defaults of New.
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 100
+)
+
+// Metric names, labeled by pool name.
+const (
+	MetricJobs       = "jobs_total" // and status: succeeded, failed, panicked, dropped or rejected
+	MetricDuration   = "jobs_duration_seconds"
+	MetricQueueDepth = "jobs_queue_depth"
+	MetricInFlight   = "jobs_in_flight"
+)
+
+// Job is a unit of background work.
+type Job func(ctx context.Context) error
+
+// Option configures a Pool.
+type Option func(*Pool)
+
+// WithWorkers sets how many jobs run at once. The default is
+// DefaultWorkers.
+func WithWorkers(n int) Option {
+	return func(p *Pool) { p.workers = n }
+}
+
+// WithQueueSize sets how many jobs may wait for a worker. The default is
+// DefaultQueueSize.
+func WithQueueSize(n int) Option {
+	return func(p *Pool) { p.queueSize = n }
+}
+
+// WithRetry retries failing jobs by policy. By default a job runs once.
+func WithRetry(policy retry.Policy) Option {
+	return func(p *Pool) { p.retry = &policy }
+}
+
+// WithLogger sets the logger for failed jobs and panics. The default is
+// log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(p *Pool) { p.logger = l }
+}
+
+// WithMetrics records the pool's jobs in reg instead of metrics.Default.
+func WithMetrics(reg *metrics.Registry) Option {
+	return func(p *Pool) { p.registry = reg }
+}
+
+// WithCoordinator sets the shutdown coordinator the pool registers with.
+// The default is shutdown.Default.
+func WithCoordinator(c *shutdown.Coordinator) Option {
+	return func(p *Pool) { p.coordinator = c }
+}
+
+// JobOption configures one submitted job.
+type JobOption func(*job)
+
+// WithName names the job in logs.
+func WithName(name string) JobOption {
+	return func(j *job) { j.name = name }
+}
+
+// WithJobRetry retries the job by policy instead of the pool's policy.
+func WithJobRetry(policy retry.Policy) JobOption {
+	return func(j *job) { j.retry = &policy }
+}
+
+type job struct {
+	ctx   context.Context
+	fn    Job
+	name  string
+	retry *retry.Policy
+}
+
+// Pool runs submitted jobs on a fixed number of workers. It is safe for
+// concurrent use.
+type Pool struct {
+	name        string
+	workers     int
+	queueSize   int
+	retry       *retry.Policy
+	logger      *log.Logger
+	registry    *metrics.Registry
+	coordinator *shutdown.Coordinator
+
+	jobs       *metrics.Counter
+	duration   *metrics.Histogram
+	queueDepth *metrics.Gauge
+	inFlight   *metrics.Gauge
+
+	queue    chan *job
+	ctx      context.Context // cancelled when the drain deadline passes
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.RWMutex
+	closed   bool
+	stopping chan struct{}
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+// New starts a pool with its workers and registers it with the shutdown
+// coordinator.
+func New(name string, opts ...Option) *Pool {
+	p := &Pool{
+		name:        name,
+		workers:     DefaultWorkers,
+		queueSize:   DefaultQueueSize,
+		registry:    metrics.Default,
+		coordinator: shutdown.Default,
+		stopping:    make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.workers = max(p.workers, 1)
+	p.queueSize = max(p.queueSize, 0)
+	p.jobs = p.registry.Counter(MetricJobs, "Background jobs.", "pool", "status")
+	p.duration = p.registry.Histogram(MetricDuration, "Background job duration in seconds.", nil, "pool", "status")
+	p.queueDepth = p.registry.Gauge(MetricQueueDepth, "Background jobs waiting for a worker.", "pool")
+	p.inFlight = p.registry.Gauge(MetricInFlight, "Background jobs running.", "pool")
+	p.queue = make(chan *job, p.queueSize)
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.wg.Add(p.workers)
+	for range p.workers {
+		go p.work()
+	}
+	p.coordinator.Register("jobs "+name, shutdown.PriorityWorkers, p.Shutdown)
+	return p
+}
+
+// Name returns the pool name.
+func (p *Pool) Name() string { return p.name }
+
+// Stats is a snapshot of a pool's load.
+type Stats struct {
+	Queued    int
+	Workers   int
+	QueueSize int
+}
+
+// Stats returns the current load.
+func (p *Pool) Stats() Stats {
+	return Stats{Queued: len(p.queue), Workers: p.workers, QueueSize: p.queueSize}
+}
+
+// Submit queues fn, waiting for room in the queue until ctx is done. It
+// returns ErrClosed once the pool is shutting down, or the error of ctx.
+func (p *Pool) Submit(ctx context.Context, fn Job, opts ...JobOption) error {
+	return p.submit(ctx, fn, opts, true)
+}
+
+// TrySubmit is Submit without waiting: when the queue is full it returns
+// ErrQueueFull.
+func (p *Pool) TrySubmit(ctx context.Context, fn Job, opts ...JobOption) error {
+	return p.submit(ctx, fn, opts, false)
+}
+
+func (p *Pool) submit(ctx context.Context, fn Job, opts []JobOption, wait bool) error {
+	j := &job{ctx: context.WithoutCancel(ctx), fn: fn}
+	for _, opt := range opts {
+		opt(j)
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+	// Count the job before sending it, so a worker taking it at once
+	// never drives the gauge below zero.
+	p.queueDepth.Inc(p.name)
+	select {
+	case p.queue <- j:
+		return nil
+	default:
+	}
+	if !wait {
+		p.queueDepth.Dec(p.name)
+		p.jobs.Inc(p.name, "rejected")
+		return ErrQueueFull
+	}
+	select {
+	case p.queue <- j:
+		return nil
+	case <-ctx.Done():
+		p.queueDepth.Dec(p.name)
+		return ctx.Err()
+	case <-p.stopping:
+		p.queueDepth.Dec(p.name)
+		return ErrClosed
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for j := range p.queue {
+		p.queueDepth.Dec(p.name)
+		if p.ctx.Err() != nil {
+			p.jobs.Inc(p.name, "dropped")
+			p.log().WarnCtx(j.ctx, "job_dropped", "pool", p.name, "job", j.name)
+			continue
+		}
+		p.run(j)
+	}
+}
+
+// run runs one job with its retries and records its outcome.
+func (p *Pool) run(j *job) {
+	ctx, cancel := context.WithCancel(j.ctx)
+	defer cancel()
+	stop := context.AfterFunc(p.ctx, cancel)
+	defer stop()
+
+	p.inFlight.Inc(p.name)
+	start := time.Now()
+	err := p.attempt(ctx, j)
+	elapsed := time.Since(start)
+	p.inFlight.Dec(p.name)
+
+	status := "succeeded"
+	switch {
+	case errors.Is(err, recovery.ErrPanic):
+		status = "panicked"
+	case err != nil:
+		status = "failed"
+	}
+	p.jobs.Inc(p.name, status)
+	p.duration.Observe(elapsed.Seconds(), p.name, status)
+	if err != nil {
+		p.log().ErrorCtx(ctx, "job_failed", "pool", p.name, "job", j.name, "duration_ms", elapsed.Milliseconds(), log.Err(err))
+	}
+}
+
+// attempt runs the job under its retry policy. A panic is not retried.
+func (p *Pool) attempt(ctx context.Context, j *job) error {
+	once := func(ctx context.Context) error {
+		err := recovery.Do(ctx, j.fn, recovery.WithLogger(p.log()))
+		if errors.Is(err, recovery.ErrPanic) {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	policy := j.retry
+	if policy == nil {
+		policy = p.retry
+	}
+	if policy == nil {
+		return once(ctx)
+	}
+	return retry.Do(ctx, *policy, once)
+}
+
+// Shutdown stops taking jobs and waits for the queued and running ones
+// until ctx is done, when the running jobs are cancelled and the queued
+// ones dropped. Later calls wait for the first one.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stopping)
+		p.mu.Lock()
+		p.closed = true
+		close(p.queue)
+		p.mu.Unlock()
+		go func() {
+			p.wg.Wait()
+			p.cancel()
+			close(p.stopped)
+		}()
+	})
+	select {
+	case <-p.stopped:
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return fmt.Errorf("jobs: draining %s: %w", p.name, ctx.Err())
+	}
+}
+
+// OnStop is Shutdown, so the pool can be a container component.
+func (p *Pool) OnStop(ctx context.Context) error { return p.Shutdown(ctx) }
+
+func (p *Pool) log() *log.Logger {
+	if p.logger != nil {
+		return p.logger
+	}
+	return log.Default()
+}
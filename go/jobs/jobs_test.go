@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+	"github.com/provide-io/provide-foundation/go/shutdown"
+)
+
+// newPool returns a pool with its own registry and coordinator, shut down
+// when the test ends.
+func newPool(t *testing.T, opts ...Option) (*Pool, *metrics.Registry) {
+	t.Helper()
+	reg := metrics.NewRegistry()
+	p := New("test", append([]Option{WithMetrics(reg), WithCoordinator(shutdown.New())}, opts...)...)
+	t.Cleanup(func() { p.Shutdown(context.Background()) })
+	return p, reg
+}
+
+// value returns the value of the series of name with labelValues.
+func value(reg *metrics.Registry, name string, labelValues ...string) float64 {
+	for _, f := range reg.Collect() {
+		if f.Name != name {
+			continue
+		}
+		for _, s := range f.Series {
+			if slices.Equal(s.LabelValues, labelValues) {
+				if f.Kind == metrics.KindHistogram {
+					return float64(s.Count)
+				}
+				return s.Value
+			}
+		}
+	}
+	return 0
+}
+
+func TestSubmitRunsInBackground(t *testing.T) {
+	p, reg := newPool(t)
+	ctx, cancel := context.WithCancel(ctxmeta.WithRequestID(context.Background(), "req-1"))
+	done := make(chan string, 1)
+	err := p.Submit(ctx, func(ctx context.Context) error {
+		if ctx.Err() != nil {
+			t.Error("job inherited the cancellation of its submitter")
+		}
+		done <- ctxmeta.RequestID(ctx)
+		return nil
+	})
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := <-done; id != "req-1" {
+		t.Errorf("request id = %q", id)
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v := value(reg, MetricJobs, "test", "succeeded"); v != 1 {
+		t.Errorf("succeeded = %v", v)
+	}
+	if v := value(reg, MetricDuration, "test", "succeeded"); v != 1 {
+		t.Errorf("duration observations = %v", v)
+	}
+}
+
+func TestQueueBounds(t *testing.T) {
+	p, reg := newPool(t, WithWorkers(1), WithQueueSize(1))
+	release := make(chan struct{})
+	started := make(chan struct{})
+	block := func(ctx context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	if err := p.Submit(context.Background(), block); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := p.TrySubmit(context.Background(), block); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.TrySubmit(context.Background(), block); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("TrySubmit on a full queue = %v", err)
+	}
+	if v := value(reg, MetricQueueDepth, "test"); v != 1 {
+		t.Errorf("queue depth = %v", v)
+	}
+	if v := value(reg, MetricInFlight, "test"); v != 1 {
+		t.Errorf("in flight = %v", v)
+	}
+	if v := value(reg, MetricJobs, "test", "rejected"); v != 1 {
+		t.Errorf("rejected = %v", v)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, block); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit on a full queue = %v", err)
+	}
+	close(release)
+	<-started
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v := value(reg, MetricQueueDepth, "test"); v != 0 {
+		t.Errorf("queue depth after drain = %v", v)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	p, reg := newPool(t, WithRetry(retry.Policy{MaxAttempts: 3, Backoff: retry.Fixed, BaseDelay: time.Millisecond}))
+	var calls atomic.Int32
+	p.Submit(context.Background(), func(ctx context.Context) error {
+		if calls.Add(1) < 3 {
+			return errors.New("flaky")
+		}
+		return nil
+	})
+	var single atomic.Int32
+	p.Submit(context.Background(), func(ctx context.Context) error {
+		single.Add(1)
+		return errors.New("down")
+	}, WithJobRetry(retry.Policy{MaxAttempts: 1}))
+	p.Shutdown(context.Background())
+	if calls.Load() != 3 {
+		t.Errorf("flaky job ran %d times", calls.Load())
+	}
+	if single.Load() != 1 {
+		t.Errorf("job with its own policy ran %d times", single.Load())
+	}
+	if v := value(reg, MetricJobs, "test", "failed"); v != 1 {
+		t.Errorf("failed = %v", v)
+	}
+}
+
+func TestPanicIsolation(t *testing.T) {
+	rec := logtest.Capture(t)
+	p, reg := newPool(t, WithWorkers(1), WithRetry(retry.Policy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	var panics atomic.Int32
+	p.Submit(context.Background(), func(ctx context.Context) error {
+		panics.Add(1)
+		panic("boom")
+	}, WithName("explode"))
+	ran := make(chan struct{})
+	p.Submit(context.Background(), func(ctx context.Context) error {
+		close(ran)
+		return nil
+	})
+	<-ran
+	p.Shutdown(context.Background())
+	if panics.Load() != 1 {
+		t.Errorf("panicking job ran %d times", panics.Load())
+	}
+	if v := value(reg, MetricJobs, "test", "panicked"); v != 1 {
+		t.Errorf("panicked = %v", v)
+	}
+	r := rec.Records().WithEvent("job_failed").Last()
+	if r == nil {
+		t.Fatal("no job_failed record")
+	}
+	if job, _ := r.Get("job"); job != "explode" {
+		t.Errorf("job_failed job = %v", job)
+	}
+}
+
+func TestShutdownDrains(t *testing.T) {
+	coord := shutdown.New()
+	reg := metrics.NewRegistry()
+	p := New("drain", WithWorkers(1), WithMetrics(reg), WithCoordinator(coord))
+	var ran atomic.Int32
+	for range 5 {
+		p.Submit(context.Background(), func(ctx context.Context) error {
+			time.Sleep(time.Millisecond)
+			ran.Add(1)
+			return nil
+		})
+	}
+	if err := coord.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran.Load() != 5 {
+		t.Errorf("%d of 5 queued jobs ran", ran.Load())
+	}
+	if err := p.Submit(context.Background(), func(context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after shutdown = %v", err)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	p, reg := newPool(t, WithWorkers(1))
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	p.Submit(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+	p.Submit(context.Background(), func(ctx context.Context) error {
+		t.Error("queued job ran after the deadline")
+		return nil
+	})
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v", err)
+	}
+	<-cancelled
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v := value(reg, MetricJobs, "test", "dropped"); v != 1 {
+		t.Errorf("dropped = %v", v)
+	}
+}
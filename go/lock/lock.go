@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lock coordinates work across replicas with distributed locks. A
+// Manager acquires a named lock for a time to live and renews it in the
+// background while it is held:
+//
+//	locks := lock.NewManager(redis.New(client))
+//	l, err := locks.Acquire(ctx, "billing:close-month", 30*time.Second)
+//	if err != nil {
+//		return err
+//	}
+//	defer l.Release(ctx)
+//	err = closeMonth(ctx, l.Token())
+//
+// A lock can be lost while held: its process pauses past the time to
+// live, or its backend fails over. Lost is closed then, and Do cancels
+// the work it runs. Work that writes to shared storage should also pass
+// the lock's fencing token along and have the storage reject writes with
+// a token older than the newest it has seen, as a paused holder can
+// resume after another has acquired the lock.
+//
+// The backends are Memory, for tests and single processes, and the redis
+// and postgres subpackages.
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+var (
+	// ErrNotAcquired is returned by TryAcquire when another holder has
+	// the lock.
+	ErrNotAcquired = errors.New("lock: held by another owner")
+	// ErrLost reports that a lock was no longer held: it expired, or the
+	// backend lost it.
+	ErrLost = errors.New("lock: lost")
+)
+
+// Backend stores locks.
+type Backend interface {
+	// TryAcquire takes key for ttl if nobody holds it, or returns
+	// ErrNotAcquired. The lease's token is greater than that of every
+	// earlier lease of key.
+	TryAcquire(ctx context.Context, key string, ttl time.Duration) (Lease, error)
+}
+
+// Lease is a lock held in a backend.
+type Lease interface {
+	// Token returns the fencing token of the lease.
+	Token() int64
+	// Renew extends the lease to ttl from now, or returns ErrLost if it
+	// is no longer held.
+	Renew(ctx context.Context, ttl time.Duration) error
+	// Release gives the lock up, or returns ErrLost if it was no longer
+	// held.
+	Release(ctx context.Context) error
+}
+
+// DefaultPoll spaces out the attempts of Acquire while the lock is held
+// elsewhere.
+var DefaultPoll = retry.Policy{Backoff: retry.Exponential, BaseDelay: 50 * time.Millisecond, MaxDelay: 2 * time.Second, Jitter: 0.2}
+
+// Option configures NewManager.
+type Option func(*Manager)
+
+// WithPoll sets the delay between the attempts of Acquire, from p.Delay
+// of the attempts so far. The default is DefaultPoll.
+func WithPoll(p retry.Policy) Option {
+	return func(m *Manager) { m.poll = p }
+}
+
+// WithClock sets the clock of polling and renewal. The default is
+// clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(m *Manager) { m.clock = c }
+}
+
+// WithLogger sets the logger; the default is log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(m *Manager) { m.logger = l }
+}
+
+// Manager acquires locks from a backend. It is safe for concurrent use.
+type Manager struct {
+	backend Backend
+	poll    retry.Policy
+	clock   clock.Clock
+	logger  *log.Logger
+}
+
+// NewManager returns a manager of the locks in b.
+func NewManager(b Backend, opts ...Option) *Manager {
+	m := &Manager{backend: b, poll: DefaultPoll, clock: clock.Real()}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *Manager) log() *log.Logger {
+	if m.logger != nil {
+		return m.logger
+	}
+	return log.Default()
+}
+
+// TryAcquire takes key for ttl, or returns ErrNotAcquired if another
+// owner holds it. The lock is renewed every third of ttl until released.
+func (m *Manager) TryAcquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lock: %s: non-positive ttl %v", key, ttl)
+	}
+	lease, err := m.backend.TryAcquire(ctx, key, ttl)
+	if err != nil {
+		if errors.Is(err, ErrNotAcquired) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("lock: acquiring %s: %w", key, err)
+	}
+	l := &Lock{
+		m:       m,
+		key:     key,
+		ttl:     ttl,
+		lease:   lease,
+		expires: m.clock.Now().Add(ttl),
+		lost:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go l.renew()
+	return l, nil
+}
+
+// Acquire takes key for ttl, waiting while another owner holds it until
+// ctx is done.
+func (m *Manager) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	for attempt := 1; ; attempt++ {
+		l, err := m.TryAcquire(ctx, key, ttl)
+		if !errors.Is(err, ErrNotAcquired) {
+			return l, err
+		}
+		if err := clock.Sleep(ctx, m.clock, m.poll.Delay(attempt)); err != nil {
+			return nil, fmt.Errorf("lock: acquiring %s: %w", key, err)
+		}
+	}
+}
+
+// Do runs fn holding key, acquired as by Acquire and released when fn
+// returns. The ctx of fn is cancelled if the lock is lost, and Do then
+// returns an error wrapping ErrLost.
+func (m *Manager) Do(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	l, err := m.Acquire(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	fctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		select {
+		case <-l.Lost():
+			cancel(fmt.Errorf("lock: %s: %w", key, ErrLost))
+		case <-fctx.Done():
+		}
+	}()
+	err = fn(fctx)
+	rerr := l.Release(context.WithoutCancel(ctx))
+	if errors.Is(rerr, ErrLost) {
+		return errors.Join(err, rerr)
+	}
+	if rerr != nil {
+		m.log().WarnCtx(ctx, "lock_release_failed", "key", key, log.Err(rerr))
+	}
+	return err
+}
+
+// Lock is a held lock.
+type Lock struct {
+	m     *Manager
+	key   string
+	ttl   time.Duration
+	lease Lease
+
+	mu      sync.Mutex
+	expires time.Time // of the last successful renewal
+	lost    chan struct{}
+	isLost  bool
+
+	stop    chan struct{}
+	done    chan struct{}
+	release sync.Once
+	err     error
+}
+
+// Key returns the name of the lock.
+func (l *Lock) Key() string { return l.key }
+
+// Token returns the fencing token of the lock, greater than that of every
+// earlier holder of the key.
+func (l *Lock) Token() int64 { return l.lease.Token() }
+
+// Lost returns a channel closed when the lock is lost.
+func (l *Lock) Lost() <-chan struct{} { return l.lost }
+
+// renew extends the lease every third of the ttl until released. A lease
+// whose renewals fail until it expires is lost.
+func (l *Lock) renew() {
+	defer close(l.done)
+	ctx := context.Background()
+	t := l.m.clock.NewTimer(l.ttl / 3)
+	defer t.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-t.C():
+		}
+		t.Reset(l.ttl / 3)
+		rctx, cancel := context.WithTimeout(ctx, l.ttl/3)
+		err := l.lease.Renew(rctx, l.ttl)
+		cancel()
+		switch {
+		case err == nil:
+			l.mu.Lock()
+			l.expires = l.m.clock.Now().Add(l.ttl)
+			l.mu.Unlock()
+			continue
+		case errors.Is(err, ErrLost):
+		default:
+			l.m.log().WarnCtx(ctx, "lock_renew_failed", "key", l.key, log.Err(err))
+			l.mu.Lock()
+			expired := !l.m.clock.Now().Before(l.expires)
+			l.mu.Unlock()
+			if !expired {
+				continue
+			}
+		}
+		l.markLost()
+		return
+	}
+}
+
+func (l *Lock) markLost() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.isLost {
+		l.isLost = true
+		close(l.lost)
+		l.m.log().Warn("lock_lost", "key", l.key, "token", l.lease.Token())
+	}
+}
+
+// Release stops renewing and gives the lock up. It returns an error
+// wrapping ErrLost if the lock was lost before, so work done under it may
+// have overlapped with another holder. Later calls return the same.
+func (l *Lock) Release(ctx context.Context) error {
+	l.release.Do(func() {
+		close(l.stop)
+		<-l.done
+		l.mu.Lock()
+		lost := l.isLost
+		l.mu.Unlock()
+		if lost {
+			l.err = fmt.Errorf("lock: %s: %w", l.key, ErrLost)
+			return
+		}
+		if err := l.lease.Release(ctx); err != nil {
+			l.err = fmt.Errorf("lock: releasing %s: %w", l.key, err)
+		}
+	})
+	return l.err
+}
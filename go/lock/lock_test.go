@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+func TestTryAcquire(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(NewMemory(nil))
+	a, err := m.TryAcquire(ctx, "k", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.TryAcquire(ctx, "k", time.Minute); !errors.Is(err, ErrNotAcquired) {
+		t.Errorf("second TryAcquire = %v", err)
+	}
+	other, err := m.TryAcquire(ctx, "other", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Release(ctx)
+	if err := a.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Release(ctx); err != nil {
+		t.Errorf("second Release = %v", err)
+	}
+	b, err := m.TryAcquire(ctx, "k", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Release(ctx)
+	if b.Token() <= a.Token() || b.Key() != "k" {
+		t.Errorf("tokens %d then %d", a.Token(), b.Token())
+	}
+	if _, err := m.TryAcquire(ctx, "k", 0); err == nil {
+		t.Error("acquired without a ttl")
+	}
+}
+
+func TestAcquireWaits(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(NewMemory(nil), WithPoll(retry.Policy{Backoff: retry.Fixed, BaseDelay: time.Millisecond}))
+	var mu sync.Mutex
+	holders, maxHolders := 0, 0
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := m.Do(ctx, "k", time.Minute, func(context.Context) error {
+				mu.Lock()
+				holders++
+				maxHolders = max(maxHolders, holders)
+				mu.Unlock()
+				time.Sleep(2 * time.Millisecond)
+				mu.Lock()
+				holders--
+				mu.Unlock()
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxHolders != 1 {
+		t.Errorf("%d holders at once", maxHolders)
+	}
+
+	l, _ := m.Acquire(ctx, "k", time.Minute)
+	defer l.Release(ctx)
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.Acquire(cctx, "k", time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire of a held lock = %v", err)
+	}
+}
+
+func TestRenewal(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Unix(1000, 0))
+	mem := NewMemory(clk)
+	m := NewManager(mem, WithClock(clk))
+	l, err := m.TryAcquire(ctx, "k", 30*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 5 { // well past the ttl, renewed every 10s
+		clk.BlockUntil(1)
+		clk.Advance(10 * time.Second)
+	}
+	clk.BlockUntil(1)
+	if _, err := m.TryAcquire(ctx, "k", time.Second); !errors.Is(err, ErrNotAcquired) {
+		t.Errorf("renewed lock acquired again: %v", err)
+	}
+	select {
+	case <-l.Lost():
+		t.Fatal("renewed lock lost")
+	default:
+	}
+	if err := l.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// flaky is a backend whose leases fail to renew.
+type flaky struct {
+	*Memory
+	err error
+}
+
+func (f flaky) TryAcquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	l, err := f.Memory.TryAcquire(ctx, key, ttl)
+	return flakyLease{l, f.err}, err
+}
+
+type flakyLease struct {
+	Lease
+	err error
+}
+
+func (l flakyLease) Renew(context.Context, time.Duration) error { return l.err }
+
+func TestLost(t *testing.T) {
+	ctx := context.Background()
+	for name, renewErr := range map[string]error{"lost": ErrLost, "failing": errors.New("timeout")} {
+		t.Run(name, func(t *testing.T) {
+			rec := logtest.Capture(t)
+			clk := clock.NewFake(time.Unix(1000, 0))
+			m := NewManager(flaky{NewMemory(clk), renewErr}, WithClock(clk), WithLogger(rec.Logger()))
+			errc := make(chan error)
+			go func() {
+				errc <- m.Do(ctx, "k", 30*time.Second, func(ctx context.Context) error {
+					<-ctx.Done()
+					return context.Cause(ctx)
+				})
+			}()
+			renewals := 0
+			for {
+				clk.BlockUntil(1)
+				clk.Advance(10 * time.Second)
+				renewals++
+				select {
+				case err := <-errc:
+					if !errors.Is(err, ErrLost) {
+						t.Errorf("Do = %v", err)
+					}
+					if renewErr == ErrLost && renewals != 1 || renewErr != ErrLost && renewals != 3 {
+						t.Errorf("lost after %d renewals", renewals)
+					}
+					if n := rec.WithEvent("lock_lost").Count(); n != 1 {
+						t.Errorf("logged %d losses", n)
+					}
+					return
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+		})
+	}
+}
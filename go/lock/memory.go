@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+)
+
+// Memory is a backend within one process.
+type Memory struct {
+	clock clock.Clock
+
+	mu     sync.Mutex
+	held   map[string]*memoryLease
+	tokens int64
+}
+
+// NewMemory returns an empty in-memory backend whose leases expire by c,
+// or clock.Real() when nil.
+func NewMemory(c clock.Clock) *Memory {
+	return &Memory{clock: clock.Or(c), held: map[string]*memoryLease{}}
+}
+
+// TryAcquire implements Backend.
+func (m *Memory) TryAcquire(_ context.Context, key string, ttl time.Duration) (Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	if l, ok := m.held[key]; ok && now.Before(l.expires) {
+		return nil, ErrNotAcquired
+	}
+	m.tokens++
+	l := &memoryLease{m: m, key: key, token: m.tokens, expires: now.Add(ttl)}
+	m.held[key] = l
+	return l, nil
+}
+
+type memoryLease struct {
+	m       *Memory
+	key     string
+	token   int64
+	expires time.Time // guarded by m.mu
+}
+
+func (l *memoryLease) Token() int64 { return l.token }
+
+// holds reports whether l is the live lease of its key. m.mu is held.
+func (l *memoryLease) holds() bool {
+	return l.m.held[l.key] == l && l.m.clock.Now().Before(l.expires)
+}
+
+func (l *memoryLease) Renew(_ context.Context, ttl time.Duration) error {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+	if !l.holds() {
+		return ErrLost
+	}
+	l.expires = l.m.clock.Now().Add(ttl)
+	return nil
+}
+
+func (l *memoryLease) Release(context.Context) error {
+	l.m.mu.Lock()
+	defer l.m.mu.Unlock()
+	if !l.holds() {
+		return ErrLost
+	}
+	delete(l.m.held, l.key)
+	return nil
+}
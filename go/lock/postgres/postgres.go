@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package postgres is a lock.Backend on PostgreSQL session advisory
+// locks. A lock holds a connection of the pool and is held as long as its
+// session lives: the server releases it when the connection closes,
+// including when its process dies, so the time to live only bounds how
+// long renewals may fail before the Manager reports it lost. Fencing
+// tokens come from a sequence, created by Schema or Migration:
+//
+//	b, err := postgres.New(database.SQL())
+//	locks := lock.NewManager(b)
+//
+// Lock names are hashed to the 64-bit keys of advisory locks, so other
+// users of advisory locks in the database should pick keys unlikely to
+// collide, or a separate database.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/db/migrate"
+	"github.com/provide-io/provide-foundation/go/lock"
+)
+
+// DefaultSequence is the sequence of fencing tokens.
+const DefaultSequence = "lock_fencing_tokens"
+
+// Option configures New.
+type Option func(*Backend)
+
+// WithSequence sets the sequence of fencing tokens, optionally
+// schema-qualified. The default is DefaultSequence.
+func WithSequence(name string) Option {
+	return func(b *Backend) { b.sequence = name }
+}
+
+// Backend is a lock backend on a PostgreSQL pool. It is safe for
+// concurrent use.
+type Backend struct {
+	pool     *sql.DB
+	sequence string
+}
+
+var _ lock.Backend = (*Backend)(nil)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func checkSequence(name string) error {
+	for _, part := range strings.Split(name, ".") {
+		if !identifier.MatchString(part) {
+			return fmt.Errorf("postgres: invalid sequence name %q", name)
+		}
+	}
+	return nil
+}
+
+// New returns a backend on pool. Each held lock takes a connection, so
+// the pool's maximum should leave room for them.
+func New(pool *sql.DB, opts ...Option) (*Backend, error) {
+	b := &Backend{pool: pool, sequence: DefaultSequence}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if err := checkSequence(b.sequence); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Schema returns the statement creating the sequence of fencing tokens.
+func Schema(sequence string) string {
+	return "CREATE SEQUENCE IF NOT EXISTS " + sequence
+}
+
+// Migration returns a migration creating the sequence, for
+// migrate.WithMigrations.
+func Migration(version int64, sequence string) migrate.Migration {
+	return migrate.Migration{
+		Version: version,
+		Name:    "create_" + strings.ReplaceAll(sequence, ".", "_"),
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			if err := checkSequence(sequence); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, Schema(sequence))
+			return err
+		},
+		Down: func(ctx context.Context, tx *sql.Tx) error {
+			if err := checkSequence(sequence); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, "DROP SEQUENCE "+sequence)
+			return err
+		},
+	}
+}
+
+// Key returns the advisory lock key of a lock name.
+func Key(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// TryAcquire implements lock.Backend. The ttl is not stored.
+func (b *Backend) TryAcquire(ctx context.Context, key string, _ time.Duration) (lock.Lease, error) {
+	conn, err := b.pool.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	l := &lease{conn: conn, key: Key(key)}
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	if !ok {
+		conn.Close()
+		return nil, lock.ErrNotAcquired
+	}
+	if err := conn.QueryRowContext(ctx, "SELECT nextval('"+b.sequence+"')").Scan(&l.token); err != nil {
+		l.Release(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("postgres: fencing token: %w", err)
+	}
+	return l, nil
+}
+
+type lease struct {
+	conn  *sql.Conn
+	key   int64
+	token int64
+}
+
+func (l *lease) Token() int64 { return l.token }
+
+// Renew checks that the session holding the lock is alive.
+func (l *lease) Renew(ctx context.Context, _ time.Duration) error {
+	return lost(l.conn.PingContext(ctx))
+}
+
+func (l *lease) Release(ctx context.Context) error {
+	defer l.conn.Close()
+	var ok bool
+	if err := l.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.key).Scan(&ok); err != nil {
+		return lost(err)
+	}
+	if !ok {
+		return lock.ErrLost
+	}
+	return nil
+}
+
+// lost reports the failure of a lock's session as lock.ErrLost, as the
+// server releases the locks of a closed session.
+func lost(err error) error {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return fmt.Errorf("%w: %w", lock.ErrLost, err)
+	}
+	if err != nil {
+		return fmt.Errorf("postgres: %w", err)
+	}
+	return nil
+}
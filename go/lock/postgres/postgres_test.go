@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/lock"
+)
+
+// server holds the advisory locks of its sessions, as PostgreSQL does.
+type server struct {
+	mu       sync.Mutex
+	locks    map[int64]*session
+	sequence int64
+	sessions []*session
+}
+
+type session struct {
+	s    *server
+	dead bool // guarded by s.mu
+}
+
+func (s *server) Connect(context.Context) (driver.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := &session{s: s}
+	s.sessions = append(s.sessions, c)
+	return c, nil
+}
+
+func (s *server) Driver() driver.Driver { return nil }
+
+// kill ends every session, as a restart or network failure would.
+func (s *server) kill() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.sessions {
+		c.dead = true
+	}
+	clear(s.locks)
+}
+
+func (c *session) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *session) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *session) Close() error {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	for k, holder := range c.s.locks {
+		if holder == c {
+			delete(c.s.locks, k)
+		}
+	}
+	return nil
+}
+
+func (c *session) Ping(context.Context) error {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	if c.dead {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *session) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	if c.dead {
+		return nil, driver.ErrBadConn
+	}
+	switch {
+	case query == "SELECT pg_try_advisory_lock($1)":
+		k := args[0].Value.(int64)
+		if holder, ok := c.s.locks[k]; ok && holder != c {
+			return &rows{false}, nil
+		}
+		c.s.locks[k] = c
+		return &rows{true}, nil
+	case query == "SELECT pg_advisory_unlock($1)":
+		k := args[0].Value.(int64)
+		if c.s.locks[k] != c {
+			return &rows{false}, nil
+		}
+		delete(c.s.locks, k)
+		return &rows{true}, nil
+	case strings.HasPrefix(query, "SELECT nextval('app.tokens')"):
+		c.s.sequence++
+		return &rows{c.s.sequence}, nil
+	}
+	return nil, errors.New("unexpected query " + query)
+}
+
+type rows struct{ v driver.Value }
+
+func (r *rows) Columns() []string { return []string{"v"} }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.v == nil {
+		return io.EOF
+	}
+	dest[0], r.v = r.v, nil
+	return nil
+}
+
+func newBackend(t *testing.T) (*Backend, *server) {
+	t.Helper()
+	s := &server{locks: map[int64]*session{}}
+	pool := sql.OpenDB(s)
+	t.Cleanup(func() { pool.Close() })
+	b, err := New(pool, WithSequence("app.tokens"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, s
+}
+
+func TestBackend(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newBackend(t)
+	a, err := b.TryAcquire(ctx, "k", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.TryAcquire(ctx, "k", time.Second); !errors.Is(err, lock.ErrNotAcquired) {
+		t.Errorf("TryAcquire of a held lock = %v", err)
+	}
+	other, err := b.TryAcquire(ctx, "other", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Release(ctx)
+	if err := a.Renew(ctx, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c, err := b.TryAcquire(ctx, "k", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Release(ctx)
+	if a.Token() != 1 || other.Token() != 2 || c.Token() != 3 {
+		t.Errorf("tokens %d, %d, %d", a.Token(), other.Token(), c.Token())
+	}
+}
+
+func TestSessionLost(t *testing.T) {
+	ctx := context.Background()
+	b, s := newBackend(t)
+	a, err := b.TryAcquire(ctx, "k", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.kill()
+	if err := a.Renew(ctx, time.Second); !errors.Is(err, lock.ErrLost) {
+		t.Errorf("Renew = %v", err)
+	}
+	if err := a.Release(ctx); !errors.Is(err, lock.ErrLost) {
+		t.Errorf("Release = %v", err)
+	}
+	c, err := b.TryAcquire(ctx, "k", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Release(ctx)
+}
+
+func TestNames(t *testing.T) {
+	if _, err := New(nil, WithSequence("x; DROP TABLE users")); err == nil {
+		t.Error("invalid sequence accepted")
+	}
+	if Key("a") == Key("b") || Key("a") != Key("a") {
+		t.Error("keys do not follow names")
+	}
+	if got := Schema("app.tokens"); got != "CREATE SEQUENCE IF NOT EXISTS app.tokens" {
+		t.Errorf("Schema = %q", got)
+	}
+}
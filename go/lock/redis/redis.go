@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redis is a lock.Backend on Redis. A lock is a key set with NX
+// and a millisecond expiry to a random owner value, which renewal and
+// release check in a script, so an owner never extends or deletes the
+// lock of another. Fencing tokens come from a counter key beside each
+// lock.
+//
+// The locks are as safe as the Redis instance holding them: a failover to
+// a replica that had not received a lock loses it. Use a primary without
+// replicas, or rely on fencing tokens.
+//
+// The backend drives a Client, which adapts a Redis client library; the
+// module itself does not depend on one. With go-redis:
+//
+//	b := redis.New(redis.ClientFunc(func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//		return rdb.Eval(ctx, script, keys, args...).Result()
+//	}))
+//	locks := lock.NewManager(b)
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/lock"
+)
+
+// Client runs Lua scripts on Redis.
+type Client interface {
+	// Eval runs script with keys and args and returns its reply, with
+	// integers as int64.
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// ClientFunc adapts a function to a Client.
+type ClientFunc func(ctx context.Context, script string, keys []string, args ...any) (any, error)
+
+// Eval calls f.
+func (f ClientFunc) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	return f(ctx, script, keys, args...)
+}
+
+// DefaultPrefix is prepended to lock names to make their keys.
+const DefaultPrefix = "lock:"
+
+// Scripts, with KEYS[1] the lock and ARGV[1] the owner. Acquiring returns
+// the fencing token from the counter in KEYS[2], or 0 if the lock is
+// held; the others return 1 if the owner held the lock, or 0.
+const (
+	acquireScript = `if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0`
+	renewScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`
+	releaseScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+)
+
+// Option configures New.
+type Option func(*Backend)
+
+// WithPrefix sets the prefix of the keys. The default is DefaultPrefix.
+func WithPrefix(p string) Option {
+	return func(b *Backend) { b.prefix = p }
+}
+
+// Backend is a lock backend on a Redis client. It is safe for concurrent
+// use.
+type Backend struct {
+	client Client
+	prefix string
+}
+
+var _ lock.Backend = (*Backend)(nil)
+
+// New returns a backend on client.
+func New(client Client, opts ...Option) *Backend {
+	b := &Backend{client: client, prefix: DefaultPrefix}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// TryAcquire implements lock.Backend.
+func (b *Backend) TryAcquire(ctx context.Context, key string, ttl time.Duration) (lock.Lease, error) {
+	var owner [16]byte
+	rand.Read(owner[:])
+	l := &lease{b: b, key: b.prefix + key, owner: hex.EncodeToString(owner[:])}
+	token, err := b.eval(ctx, acquireScript, []string{l.key, l.key + ":fencing"}, l.owner, ttl.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	if token == 0 {
+		return nil, lock.ErrNotAcquired
+	}
+	l.token = token
+	return l, nil
+}
+
+// eval runs a script returning an integer.
+func (b *Backend) eval(ctx context.Context, script string, keys []string, args ...any) (int64, error) {
+	reply, err := b.client.Eval(ctx, script, keys, args...)
+	if err != nil {
+		return 0, fmt.Errorf("redis: %w", err)
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected reply %T", reply)
+	}
+	return n, nil
+}
+
+type lease struct {
+	b     *Backend
+	key   string
+	owner string
+	token int64
+}
+
+func (l *lease) Token() int64 { return l.token }
+
+func (l *lease) Renew(ctx context.Context, ttl time.Duration) error {
+	return l.run(ctx, renewScript, ttl.Milliseconds())
+}
+
+func (l *lease) Release(ctx context.Context) error {
+	return l.run(ctx, releaseScript)
+}
+
+func (l *lease) run(ctx context.Context, script string, args ...any) error {
+	held, err := l.b.eval(ctx, script, []string{l.key}, append([]any{l.owner}, args...)...)
+	if err != nil {
+		return err
+	}
+	if held == 0 {
+		return lock.ErrLost
+	}
+	return nil
+}
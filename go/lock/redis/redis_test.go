@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/lock"
+)
+
+// server runs the backend's scripts on a map, as Redis would.
+type server struct {
+	mu      sync.Mutex
+	now     time.Time
+	values  map[string]string
+	expires map[string]time.Time
+	counts  map[string]int64
+}
+
+func newServer() *server {
+	return &server{now: time.Unix(1000, 0), values: map[string]string{}, expires: map[string]time.Time{}, counts: map[string]int64{}}
+}
+
+func (s *server) advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+func (s *server) get(key string) (string, bool) {
+	if exp, ok := s.expires[key]; ok && !s.now.Before(exp) {
+		delete(s.values, key)
+		delete(s.expires, key)
+	}
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func (s *server) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owner := args[0].(string)
+	ms := func() time.Duration { return time.Duration(args[1].(int64)) * time.Millisecond }
+	v, held := s.get(keys[0])
+	switch script {
+	case acquireScript:
+		if held {
+			return int64(0), nil
+		}
+		s.values[keys[0]], s.expires[keys[0]] = owner, s.now.Add(ms())
+		s.counts[keys[1]]++
+		return s.counts[keys[1]], nil
+	case renewScript:
+		if !held || v != owner {
+			return int64(0), nil
+		}
+		s.expires[keys[0]] = s.now.Add(ms())
+		return int64(1), nil
+	case releaseScript:
+		if !held || v != owner {
+			return int64(0), nil
+		}
+		delete(s.values, keys[0])
+		delete(s.expires, keys[0])
+		return int64(1), nil
+	}
+	return nil, errors.New("unknown script")
+}
+
+func TestBackend(t *testing.T) {
+	ctx := context.Background()
+	s := newServer()
+	b := New(s, WithPrefix("app:lock:"))
+
+	a, err := b.TryAcquire(ctx, "k", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.values["app:lock:k"]; !ok || a.Token() != 1 {
+		t.Errorf("values %v, token %d", s.values, a.Token())
+	}
+	if _, err := b.TryAcquire(ctx, "k", time.Second); !errors.Is(err, lock.ErrNotAcquired) {
+		t.Errorf("TryAcquire of a held lock = %v", err)
+	}
+	s.advance(9 * time.Second)
+	if err := a.Renew(ctx, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	s.advance(9 * time.Second)
+	if err := a.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := b.TryAcquire(ctx, "k", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token() != 2 {
+		t.Errorf("token %d", c.Token())
+	}
+	s.advance(time.Second) // expired; another owner takes it
+	d, err := b.TryAcquire(ctx, "k", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Renew(ctx, time.Second); !errors.Is(err, lock.ErrLost) {
+		t.Errorf("Renew of an expired lease = %v", err)
+	}
+	if err := c.Release(ctx); !errors.Is(err, lock.ErrLost) {
+		t.Errorf("Release of an expired lease = %v", err)
+	}
+	if err := d.Release(ctx); err != nil {
+		t.Errorf("expired owner released the lock of another: %v", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := context.Background()
+	down := errors.New("connection refused")
+	b := New(ClientFunc(func(context.Context, string, []string, ...any) (any, error) { return nil, down }))
+	if _, err := b.TryAcquire(ctx, "k", time.Second); !errors.Is(err, down) {
+		t.Errorf("TryAcquire = %v", err)
+	}
+	b = New(ClientFunc(func(context.Context, string, []string, ...any) (any, error) { return "OK", nil }))
+	if _, err := b.TryAcquire(ctx, "k", time.Second); err == nil {
+		t.Error("unexpected reply accepted")
+	}
+}
@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSinkClosed is returned when writing to a sink that has been closed.
+var ErrSinkClosed = errors.New("log: sink closed")
+
+// Flusher is implemented by sinks that buffer records. Logger.Flush calls
+// it on every sink that has one.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// OverflowPolicy decides what an AsyncSink does when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest discards the record being written. It never
+	// blocks the caller and is the default.
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered record to make room.
+	OverflowDropOldest
+	// OverflowBlock makes the caller wait until there is room.
+	OverflowBlock
+)
+
+// Defaults for NewAsyncSink.
+const (
+	DefaultAsyncBufferSize = 1024
+	DefaultAsyncBatchSize  = 64
+)
+
+// AsyncOption configures an AsyncSink.
+type AsyncOption func(*AsyncSink)
+
+// WithBufferSize sets how many records the sink holds before the overflow
+// policy applies.
+func WithBufferSize(n int) AsyncOption {
+	return func(s *AsyncSink) {
+		if n > 0 {
+			s.buf = make([]*Record, n)
+		}
+	}
+}
+
+// WithBatchSize sets how many records the background goroutine takes from
+// the buffer at once.
+func WithBatchSize(n int) AsyncOption {
+	return func(s *AsyncSink) {
+		if n > 0 {
+			s.batchSize = n
+		}
+	}
+}
+
+// WithOverflow sets the policy used when the buffer is full.
+func WithOverflow(p OverflowPolicy) AsyncOption {
+	return func(s *AsyncSink) { s.policy = p }
+}
+
+// AsyncSink queues records in a bounded ring buffer and writes them to
+// another sink from a background goroutine, keeping encoding and I/O off
+// the logging call path. Call Close, or at least Flush, before exiting.
+type AsyncSink struct {
+	next      Sink
+	policy    OverflowPolicy
+	batchSize int
+	dropped   atomic.Uint64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	buf     []*Record
+	head    int
+	n       int
+	busy    bool
+	closed  bool
+	drained chan struct{}
+	done    chan struct{}
+}
+
+// NewAsyncSink starts a background writer feeding next.
+func NewAsyncSink(next Sink, opts ...AsyncOption) *AsyncSink {
+	s := &AsyncSink{
+		next:      next,
+		batchSize: DefaultAsyncBatchSize,
+		buf:       make([]*Record, DefaultAsyncBufferSize),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.cond = sync.NewCond(&s.mu)
+	go s.run()
+	return s
+}
+
+// Write implements Sink. The record is cloned and queued.
+func (s *AsyncSink) Write(r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.n == len(s.buf) && !s.closed {
+		switch s.policy {
+		case OverflowDropNewest:
+			s.dropped.Add(1)
+			return nil
+		case OverflowDropOldest:
+			s.buf[s.head] = nil
+			s.head = (s.head + 1) % len(s.buf)
+			s.n--
+			s.dropped.Add(1)
+		default:
+			s.cond.Wait()
+		}
+	}
+	if s.closed {
+		return ErrSinkClosed
+	}
+	s.buf[(s.head+s.n)%len(s.buf)] = r.Clone()
+	s.n++
+	s.cond.Broadcast()
+	return nil
+}
+
+// Dropped returns how many records were discarded by the overflow policy.
+func (s *AsyncSink) Dropped() uint64 { return s.dropped.Load() }
+
+// Flush waits until every record queued so far has been written to the
+// next sink, or ctx is done.
+func (s *AsyncSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	if s.n == 0 && !s.busy {
+		s.mu.Unlock()
+		return s.flushNext(ctx)
+	}
+	if s.drained == nil {
+		s.drained = make(chan struct{})
+	}
+	drained := s.drained
+	s.mu.Unlock()
+
+	select {
+	case <-drained:
+		return s.flushNext(ctx)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *AsyncSink) flushNext(ctx context.Context) error {
+	if f, ok := s.next.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Close stops accepting records, writes what is buffered and stops the
+// background goroutine. It returns ctx.Err() if ctx ends first; the
+// goroutine still finishes writing in that case.
+func (s *AsyncSink) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return s.flushNext(ctx)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.done)
+	batch := make([]*Record, 0, s.batchSize)
+	for {
+		s.mu.Lock()
+		for s.n == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.n == 0 {
+			s.mu.Unlock()
+			return
+		}
+		for s.n > 0 && len(batch) < s.batchSize {
+			batch = append(batch, s.buf[s.head])
+			s.buf[s.head] = nil
+			s.head = (s.head + 1) % len(s.buf)
+			s.n--
+		}
+		s.busy = true
+		s.cond.Broadcast()
+		s.mu.Unlock()
+
+		for i, r := range batch {
+			if err := s.next.Write(r); err != nil {
+				reportSinkError(s.next, err)
+			}
+			batch[i] = nil
+		}
+		batch = batch[:0]
+
+		s.mu.Lock()
+		s.busy = false
+		if s.n == 0 && s.drained != nil {
+			close(s.drained)
+			s.drained = nil
+		}
+		s.mu.Unlock()
+	}
+}
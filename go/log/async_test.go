@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateSink records events and blocks writes until the gate is opened.
+type gateSink struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	got  []string
+}
+
+func (s *gateSink) Write(r *Record) error {
+	<-s.gate
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.got = append(s.got, r.Event)
+	return nil
+}
+
+func (s *gateSink) events() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.got...)
+}
+
+func openGate() *gateSink {
+	s := &gateSink{gate: make(chan struct{})}
+	close(s.gate)
+	return s
+}
+
+func TestAsyncSinkFlushWritesInOrder(t *testing.T) {
+	next := openGate()
+	sink := NewAsyncSink(next, WithBatchSize(3))
+	logger := New(WithSink(sink))
+
+	want := []string{"a", "b", "c", "d", "e"}
+	for _, e := range want {
+		logger.Info(e)
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := next.events()
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestAsyncSinkOverflowPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		policy OverflowPolicy
+		want   string
+	}{
+		{OverflowDropNewest, "first"},
+		{OverflowDropOldest, "last"},
+	} {
+		next := &gateSink{gate: make(chan struct{})}
+		sink := NewAsyncSink(next, WithBufferSize(1), WithBatchSize(1), WithOverflow(tc.policy))
+
+		// The first record is taken by the writer goroutine and blocks
+		// there; the rest compete for the single buffer slot.
+		_ = sink.Write(&Record{Event: "held"})
+		waitFor(t, func() bool {
+			sink.mu.Lock()
+			defer sink.mu.Unlock()
+			return sink.busy
+		})
+		for _, e := range []string{"first", "middle", "last"} {
+			_ = sink.Write(&Record{Event: e})
+		}
+		close(next.gate)
+		if err := sink.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		got := next.events()
+		if len(got) != 2 || got[1] != tc.want || sink.Dropped() != 2 {
+			t.Errorf("policy %d: got %v dropped %d, want [held %s] dropped 2", tc.policy, got, sink.Dropped(), tc.want)
+		}
+	}
+}
+
+func TestAsyncSinkBlockPolicyWaitsForRoom(t *testing.T) {
+	next := &gateSink{gate: make(chan struct{})}
+	sink := NewAsyncSink(next, WithBufferSize(1), WithBatchSize(1), WithOverflow(OverflowBlock))
+	_ = sink.Write(&Record{Event: "held"})
+	_ = sink.Write(&Record{Event: "queued"})
+
+	written := make(chan struct{})
+	go func() {
+		_ = sink.Write(&Record{Event: "blocked"})
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("write did not block on a full buffer")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(next.gate)
+	<-written
+	if err := sink.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := next.events(); len(got) != 3 || sink.Dropped() != 0 {
+		t.Errorf("got %v dropped %d", got, sink.Dropped())
+	}
+}
+
+func TestAsyncSinkClose(t *testing.T) {
+	next := &gateSink{gate: make(chan struct{})}
+	sink := NewAsyncSink(next)
+	_ = sink.Write(&Record{Event: "pending"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := sink.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Flush with a stuck sink returned %v", err)
+	}
+	close(next.gate)
+	if err := sink.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(&Record{Event: "late"}); !errors.Is(err, ErrSinkClosed) {
+		t.Errorf("Write after Close returned %v", err)
+	}
+	if got := next.events(); len(got) != 1 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
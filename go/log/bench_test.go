@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// benchUserID is a variable so the compiler cannot treat it as a constant.
+var benchUserID = 12345
+
+func BenchmarkDisabled(b *testing.B) {
+	logger := New(WithOutput(io.Discard), WithLevel(LevelInfo))
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Debug("user_fetch_started", "user_id", 1, "cached", false, "source", "db")
+	}
+}
+
+func BenchmarkDisabledLazy(b *testing.B) {
+	logger := New(WithOutput(io.Discard), WithLevel(LevelInfo))
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Debug("user_fetch_started", "user", Lazy(expensiveUser))
+	}
+}
+
+func BenchmarkDisabledGuarded(b *testing.B) {
+	logger := New(WithOutput(io.Discard), WithLevel(LevelInfo))
+	b.ReportAllocs()
+	for b.Loop() {
+		if logger.Enabled(LevelDebug) {
+			logger.Debug("user_fetch_started", "user_id", benchUserID)
+		}
+	}
+}
+
+func BenchmarkDisabledWithFields(b *testing.B) {
+	logger := New(WithOutput(io.Discard), WithLevel(LevelInfo)).With("service", "users").Named("repository")
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Trace("query_plan_built", "rows", 3)
+	}
+}
+
+func BenchmarkEnabledConsole(b *testing.B) {
+	logger := New(WithOutput(io.Discard))
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Info("user_fetch_started", "user_id", 1, "cached", false, "source", "db")
+	}
+}
+
+func BenchmarkEnabledJSON(b *testing.B) {
+	logger := New(WithOutput(io.Discard), WithFormat(JSONEncoder{}))
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Info("user_fetch_started", "user_id", 1, "cached", false, "source", "db")
+	}
+}
+
+func expensiveUser() any { return map[string]any{"id": benchUserID} }
+
+func TestDisabledPathDoesNotAllocate(t *testing.T) {
+	logger := New(WithOutput(io.Discard), WithLevel(LevelInfo))
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Debug("user_fetch_started", "user_id", 1, "user", Lazy(expensiveUser))
+	})
+	if allocs != 0 {
+		t.Errorf("disabled Debug allocated %v times per call", allocs)
+	}
+}
+
+func TestLazyIsEvaluatedOnlyWhenEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}), WithLevel(LevelInfo))
+	calls := 0
+	value := Lazy(func() any { calls++; return "computed" })
+
+	logger.Debug("skipped", "v", value)
+	logger.With("v", value).Info("logged")
+
+	if calls != 1 {
+		t.Errorf("lazy value computed %d times, want 1", calls)
+	}
+	if got := decodeLines(t, &buf)[0]["v"]; got != "computed" {
+		t.Errorf("v = %v", got)
+	}
+}
@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+)
+
+// Field names of the request metadata. Baggage values are logged as
+// BaggagePrefix followed by their key.
+const (
+	RequestIDKey  = "request_id"
+	TenantKey     = "tenant"
+	BaggagePrefix = "baggage."
+)
+
+// addMetadata tags r with the request metadata of ctx. Fields the caller
+// set explicitly are kept.
+func addMetadata(ctx context.Context, r *Record) {
+	m := ctxmeta.FromContext(ctx)
+	if m.IsZero() {
+		return
+	}
+	add := func(key string, v string) {
+		if _, ok := r.Get(key); !ok && v != "" {
+			r.Attrs = append(r.Attrs, Attr{Key: key, Value: v})
+		}
+	}
+	add(RequestIDKey, m.RequestID)
+	add(TenantKey, m.Tenant)
+	for _, k := range slices.Sorted(maps.Keys(m.Baggage)) {
+		add(BaggagePrefix+k, m.Baggage[k])
+	}
+}
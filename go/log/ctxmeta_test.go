@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/ctxmeta"
+)
+
+func TestCtxMethodsInjectMetadata(t *testing.T) {
+	ctx := ctxmeta.WithRequestID(context.Background(), "req-1")
+	ctx = ctxmeta.WithTenant(ctx, "acme")
+	ctx = ctxmeta.WithBaggage(ctx, "plan", "pro")
+
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}))
+	logger.InfoCtx(ctx, "order_placed")
+	logger.InfoCtx(ctx, "order_placed", TenantKey, "explicit")
+	logger.Info("no_context")
+	slog.New(NewSlogHandler(logger)).InfoContext(ctx, "via_slog")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	for _, i := range []int{0, 3} {
+		if lines[i][RequestIDKey] != "req-1" || lines[i][TenantKey] != "acme" || lines[i]["baggage.plan"] != "pro" {
+			t.Errorf("line %d = %v", i, lines[i])
+		}
+	}
+	if lines[1][TenantKey] != "explicit" {
+		t.Errorf("explicit tenant overwritten: %v", lines[1])
+	}
+	if _, ok := lines[2][RequestIDKey]; ok {
+		t.Errorf("request_id without metadata: %v", lines[2])
+	}
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import "strings"
+
+// Domain-Action-Status field names, as used by the Python foundation
+// logger (logger.info("...", domain="user", action="fetch", status="started")).
+const (
+	DomainKey = "domain"
+	ActionKey = "action"
+	StatusKey = "status"
+)
+
+// Event builds a Domain-Action-Status event name such as
+// "user_fetch_started".
+func Event(domain, action, status string) string {
+	return domain + "_" + action + "_" + status
+}
+
+// ParseEvent splits a Domain-Action-Status event name. The first segment is
+// the domain, the last the status, and everything in between the action, so
+// "user_password_reset_completed" yields ("user", "password_reset",
+// "completed"). Names with fewer than three segments are not DAS events.
+func ParseEvent(event string) (domain, action, status string, ok bool) {
+	first := strings.IndexByte(event, '_')
+	last := strings.LastIndexByte(event, '_')
+	if first <= 0 || last-first < 2 || last == len(event)-1 || strings.ContainsAny(event, " \t") {
+		return "", "", "", false
+	}
+	return event[:first], event[first+1 : last], event[last+1:], true
+}
@@ -0,0 +1,274 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/provide-io/provide-foundation/go/term"
+)
+
+// TimestampFormat matches the Python foundation logger timestamps.
+const TimestampFormat = "2006-01-02 15:04:05.000000"
+
+// Format names accepted by ParseFormat. They mirror the Python logger's
+// console_formatter setting.
+const (
+	FormatKeyValue = "key_value"
+	FormatJSON     = "json"
+)
+
+// Encoder renders a record as a single line appended to buf. Encoders are
+// called concurrently and must not keep state between calls.
+type Encoder interface {
+	Encode(buf []byte, r *Record) []byte
+}
+
+// ParseFormat returns the encoder for a format name. "console" is accepted
+// as an alias of key_value.
+func ParseFormat(name string) (Encoder, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case FormatKeyValue, "console", "":
+		return ConsoleEncoder{}, nil
+	case FormatJSON:
+		return JSONEncoder{}, nil
+	}
+	return nil, fmt.Errorf("log: unknown format %q (valid: %s, %s)", name, FormatJSON, FormatKeyValue)
+}
+
+// JSONEncoder renders one JSON object per record.
+type JSONEncoder struct{}
+
+// Encode implements Encoder.
+func (JSONEncoder) Encode(buf []byte, r *Record) []byte {
+	buf = append(buf, '{')
+	if !r.Time.IsZero() {
+		buf = append(buf, `"timestamp":"`...)
+		buf = r.Time.AppendFormat(buf, TimestampFormat)
+		buf = append(buf, `",`...)
+	}
+	buf = append(buf, `"level":"`...)
+	buf = append(buf, r.Level.String()...)
+	buf = append(buf, `","event":`...)
+	if len(r.Markers) > 0 {
+		buf = appendJSONString(buf, string(appendMarkers(nil, r))+r.Event)
+	} else {
+		buf = appendJSONString(buf, r.Event)
+	}
+	if r.Logger != "" {
+		buf = append(buf, `,"logger_name":`...)
+		buf = appendJSONString(buf, r.Logger)
+	}
+	for _, a := range r.Attrs {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, a.Key)
+		buf = append(buf, ':')
+		buf = appendJSONValue(buf, a.Value)
+	}
+	return append(buf, '}', '\n')
+}
+
+// ConsoleEncoder renders the human-readable key=value layout of the Python
+// logger's key_value formatter. The zero value writes plain lines with
+// their emoji markers; New turns on Color and NoEmoji for the default
+// output sink as term.Detect finds its stream capable.
+type ConsoleEncoder struct {
+	// Color styles the timestamp, level, event and keys with Theme.
+	Color bool
+	// Theme is the styles of Color, term.DefaultTheme if nil.
+	Theme *term.Theme
+	// NoEmoji leaves out the markers.
+	NoEmoji bool
+}
+
+// eventWidth pads event names so fields line up across lines.
+const eventWidth = 30
+
+// Encode implements Encoder.
+func (e ConsoleEncoder) Encode(buf []byte, r *Record) []byte {
+	var theme term.Theme
+	if e.Color {
+		theme = *cmp.Or(e.Theme, term.DefaultTheme)
+	}
+	if !r.Time.IsZero() {
+		buf = theme.Timestamp.Open(buf)
+		buf = r.Time.AppendFormat(buf, TimestampFormat)
+		buf = theme.Timestamp.Close(buf)
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, '[')
+	name := r.Level.String()
+	buf = theme.Level(name).Append(buf, name)
+	for i := len(name); i < len("critical"); i++ {
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, "] "...)
+	width := 0
+	if !e.NoEmoji {
+		start := len(buf)
+		buf = appendMarkers(buf, r)
+		width = utf8.RuneCount(buf[start:])
+	}
+	buf = theme.Event.Append(buf, r.Event)
+	if len(r.Attrs) > 0 {
+		for i := width + utf8.RuneCountInString(r.Event); i < eventWidth; i++ {
+			buf = append(buf, ' ')
+		}
+	}
+	for _, a := range r.Attrs {
+		buf = append(buf, ' ')
+		buf = theme.Key.Append(buf, a.Key)
+		buf = append(buf, '=')
+		buf = appendConsoleValue(buf, a.Value)
+	}
+	return append(buf, '\n')
+}
+
+// appendMarkers renders markers the way the Python enrichment does:
+// "[👤][🔍] " ahead of the event.
+func appendMarkers(buf []byte, r *Record) []byte {
+	if len(r.Markers) == 0 {
+		return buf
+	}
+	for _, m := range r.Markers {
+		buf = append(buf, '[')
+		buf = append(buf, m...)
+		buf = append(buf, ']')
+	}
+	return append(buf, ' ')
+}
+
+func appendJSONValue(buf []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return appendJSONString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float32:
+		return appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		return appendJSONFloat(buf, v, 64)
+	case time.Duration:
+		return appendJSONString(buf, v.String())
+	case time.Time:
+		return appendJSONString(buf, v.Format(time.RFC3339Nano))
+	case error:
+		return appendJSONString(buf, v.Error())
+	case fmt.Stringer:
+		return appendJSONString(buf, v.String())
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return appendJSONString(buf, fmt.Sprintf("%+v", v))
+	}
+	return append(buf, data...)
+}
+
+func appendJSONFloat(buf []byte, f float64, bits int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) { // not valid JSON numbers
+		return appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, bits)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string literal.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' && c < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				buf = append(buf, s[start:i]...)
+				buf = append(buf, "\ufffd"...)
+				i++
+				start = i
+				continue
+			}
+			i += size
+			continue
+		}
+		buf = append(buf, s[start:i]...)
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+		}
+		i++
+		start = i
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+func appendConsoleValue(buf []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "<nil>"...)
+	case string:
+		return appendConsoleString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float64:
+		return strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case time.Duration:
+		return append(buf, v.String()...)
+	case time.Time:
+		return v.AppendFormat(buf, time.RFC3339Nano)
+	case error:
+		return appendConsoleString(buf, v.Error())
+	case fmt.Stringer:
+		return appendConsoleString(buf, v.String())
+	}
+	return appendConsoleString(buf, fmt.Sprintf("%+v", v))
+}
+
+// appendConsoleString quotes s only when it would otherwise be ambiguous.
+func appendConsoleString(buf []byte, s string) []byte {
+	if s == "" || strings.ContainsAny(s, " =\"\\\n\r\t") || !utf8.ValidString(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
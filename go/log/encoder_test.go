@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/term"
+)
+
+var fixedTime = time.Date(2025, 3, 14, 9, 26, 53, 589793000, time.UTC)
+
+func TestConsoleEncoder(t *testing.T) {
+	r := &Record{
+		Time:  fixedTime,
+		Level: LevelInfo,
+		Event: "user_fetch_started",
+		Attrs: []Attr{Int("user_id", 1), String("name", "Alice Smith"), Duration("took", 1500*time.Millisecond)},
+	}
+	got := string(ConsoleEncoder{}.Encode(nil, r))
+	want := `2025-03-14 09:26:53.589793 [info    ] user_fetch_started             user_id=1 name="Alice Smith" took=1.5s` + "\n"
+	if got != want {
+		t.Fatalf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestConsoleEncoderStyles(t *testing.T) {
+	r := &Record{
+		Level:   LevelWarning,
+		Event:   "cache_miss",
+		Markers: []string{"🔍"},
+		Attrs:   []Attr{Int("key", 7)},
+	}
+	theme := &term.Theme{
+		Levels: map[string]term.Style{"warning": {Color: term.Yellow}},
+		Key:    term.Style{Color: term.Cyan},
+	}
+	got := string(ConsoleEncoder{Color: true, Theme: theme}.Encode(nil, r))
+	want := "[\x1b[33mwarning\x1b[0m ] [🔍] cache_miss                 \x1b[36mkey\x1b[0m=7\n"
+	if got != want {
+		t.Errorf("themed got  %q\nwant %q", got, want)
+	}
+	got = string(ConsoleEncoder{NoEmoji: true}.Encode(nil, r))
+	want = "[warning ] cache_miss                     key=7\n"
+	if got != want {
+		t.Errorf("without emoji got  %q\nwant %q", got, want)
+	}
+}
+
+func TestNewDetectsConsole(t *testing.T) {
+	t.Setenv(term.EnvForceColor, "1")
+	t.Setenv(term.EnvNoEmoji, "1")
+	var buf bytes.Buffer
+	New(WithOutput(&buf), WithProcessor(func(_ context.Context, r *Record) bool {
+		r.Markers = []string{"🔍"}
+		return true
+	})).Error("cache_failed")
+	if got := buf.String(); !strings.Contains(got, "\x1b[31merror\x1b[0m") || strings.Contains(got, "🔍") {
+		t.Errorf("line = %q, want colors and no emoji", got)
+	}
+}
+
+func TestJSONEncoderProducesValidJSON(t *testing.T) {
+	r := &Record{
+		Time:   fixedTime,
+		Level:  LevelError,
+		Logger: "httpclient",
+		Event:  "request\t\"failed\"\n",
+		Attrs: []Attr{
+			Err(errors.New("dial tcp: refused")),
+			Float64("ratio", math.Inf(1)),
+			Any("tags", []string{"a", "b"}),
+			String("bad_utf8", "\xff"),
+			Any("nothing", nil),
+		},
+	}
+	line := JSONEncoder{}.Encode(nil, r)
+	var m map[string]any
+	if err := json.Unmarshal(line, &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", line, err)
+	}
+	if m["event"] != "request\t\"failed\"\n" || m["error"] != "dial tcp: refused" || m["ratio"] != "+Inf" {
+		t.Fatalf("unexpected decode: %v", m)
+	}
+	if m["timestamp"] != "2025-03-14 09:26:53.589793" || m["bad_utf8"] != "�" {
+		t.Fatalf("unexpected decode: %v", m)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if enc, err := ParseFormat("JSON"); err != nil || enc != (JSONEncoder{}) {
+		t.Fatalf("ParseFormat(JSON) = %v, %v", enc, err)
+	}
+	if enc, err := ParseFormat("key_value"); err != nil || enc != (ConsoleEncoder{}) {
+		t.Fatalf("ParseFormat(key_value) = %v, %v", enc, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
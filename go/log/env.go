@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"os"
+
+	"github.com/provide-io/provide-foundation/go/env"
+)
+
+// Environment variables read by WithEnv.
+const (
+	// EnvLogLevel sets the default level, e.g. FOUNDATION_LOG_LEVEL=INFO.
+	EnvLogLevel = "FOUNDATION_LOG_LEVEL"
+	// EnvModuleLevels sets per-module overrides, e.g.
+	// FOUNDATION_MODULE_LEVELS=repository:DEBUG,httpclient:WARN.
+	EnvModuleLevels = "FOUNDATION_MODULE_LEVELS"
+)
+
+// WithEnv applies level settings from the environment. Invalid values are
+// ignored, like the Python logger does, so a typo in production never
+// prevents startup.
+func WithEnv() Option {
+	return func(o *options) {
+		if v, ok := os.LookupEnv(EnvLogLevel); ok {
+			if level, err := ParseLevel(v); err == nil {
+				o.level = level
+			}
+		}
+		if v, ok := os.LookupEnv(EnvModuleLevels); ok {
+			modules, _ := ParseModuleLevels(v)
+			o.modules = modules
+		}
+	}
+}
+
+// WithProfile applies the defaults of a deployment profile: production
+// writes JSON for log shippers and tags records WithPlatform, every other
+// profile keeps the console format. Place it before WithFormat to
+// override the choice.
+func WithProfile(p env.Profile) Option {
+	return func(o *options) {
+		if p == env.Production {
+			o.encoder = JSONEncoder{}
+			WithPlatform()(o)
+		}
+	}
+}
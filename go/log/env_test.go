@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/env"
+)
+
+func TestModuleLevelsUseLongestPrefix(t *testing.T) {
+	logger := New(WithOutput(io.Discard), WithLevel(LevelWarning), WithModuleLevels(map[string]Level{
+		"repository":      LevelDebug,
+		"repository.pool": LevelError,
+	}))
+
+	for name, want := range map[string]Level{
+		"":                      LevelWarning,
+		"httpclient":            LevelWarning,
+		"repository":            LevelDebug,
+		"repository.users":      LevelDebug,
+		"repository.pool.conns": LevelError,
+	} {
+		l := logger
+		if name != "" {
+			l = logger.Named(name)
+		}
+		if got := l.Level(); got != want {
+			t.Errorf("%q: level %v, want %v", name, got, want)
+		}
+	}
+	if got := logger.Named("repository").Named("pool").Level(); got != LevelError {
+		t.Errorf("nested child level %v, want error", got)
+	}
+}
+
+func TestWithEnvReadsLevels(t *testing.T) {
+	t.Setenv(EnvLogLevel, "error")
+	t.Setenv(EnvModuleLevels, "repository:DEBUG, httpclient:WARN, broken, cache:LOUD")
+
+	logger := New(WithOutput(io.Discard), WithLevel(LevelInfo), WithEnv())
+	for name, want := range map[string]Level{
+		"app":        LevelError,
+		"repository": LevelDebug,
+		"httpclient": LevelWarning,
+		"cache":      LevelError,
+	} {
+		if got := logger.Named(name).Level(); got != want {
+			t.Errorf("%s: level %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseModuleLevelsReportsBadEntries(t *testing.T) {
+	levels, err := ParseModuleLevels("a:info,b,c:nope,,d : trace")
+	if err == nil {
+		t.Fatal("expected an error for malformed entries")
+	}
+	if len(levels) != 2 || levels["a"] != LevelInfo || levels["d"] != LevelTrace {
+		t.Errorf("levels = %v", levels)
+	}
+}
+
+func TestWithProfileSelectsFormat(t *testing.T) {
+	for profile, wantJSON := range map[env.Profile]bool{env.Production: true, env.Development: false} {
+		var buf bytes.Buffer
+		New(WithProfile(profile), WithOutput(&buf)).Info("service_start_completed")
+		if got := strings.HasPrefix(buf.String(), "{"); got != wantJSON {
+			t.Errorf("%s: output %q", profile, buf.String())
+		}
+		if got := strings.Contains(buf.String(), `"platform":`); got != wantJSON {
+			t.Errorf("%s: platform fields in %q", profile, buf.String())
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// EventMapping enriches records based on the value of one field, the Go
+// form of the Python eventsets.EventMapping.
+type EventMapping struct {
+	// Name matches a field key, either directly ("domain"), by its last
+	// dotted segment, or with dots replaced by underscores ("http_method"
+	// for "http.method").
+	Name string
+	// VisualMarkers maps lower-cased field values to an emoji marker.
+	VisualMarkers map[string]string
+	// MetadataFields adds fields, when absent, for a given value.
+	MetadataFields map[string]map[string]any
+	// Transformations rewrite a value before markers are looked up.
+	Transformations map[string]func(any) any
+	// DefaultKey names the VisualMarkers entry used when no value matches.
+	DefaultKey string
+}
+
+// FieldMapping documents a field that an event set understands.
+type FieldMapping struct {
+	LogKey             string
+	Description        string
+	ValueType          string
+	EventSetName       string
+	DefaultOverrideKey string
+	DefaultValue       any
+}
+
+// EventSet is a named group of mappings for one domain. Sets with a higher
+// Priority are consulted first.
+type EventSet struct {
+	Name          string
+	Description   string
+	Mappings      []EventMapping
+	FieldMappings []FieldMapping
+	Priority      int
+}
+
+// EventSetRegistry holds the event sets used for enrichment.
+type EventSetRegistry struct {
+	mu     sync.RWMutex
+	sets   map[string]EventSet
+	sorted []EventSet
+}
+
+// NewEventSetRegistry returns a registry containing sets.
+func NewEventSetRegistry(sets ...EventSet) *EventSetRegistry {
+	r := &EventSetRegistry{sets: make(map[string]EventSet)}
+	for _, s := range sets {
+		r.Register(s)
+	}
+	return r
+}
+
+var defaultEventSets = sync.OnceValue(func() *EventSetRegistry {
+	return NewEventSetRegistry(BuiltinEventSets()...)
+})
+
+// DefaultEventSets returns the process-wide registry, preloaded with the
+// built-in sets. Libraries register their own sets here at init time.
+func DefaultEventSets() *EventSetRegistry { return defaultEventSets() }
+
+// Register adds or replaces an event set.
+func (r *EventSetRegistry) Register(s EventSet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sets[s.Name] = s
+	r.sorted = r.sorted[:0]
+	for _, set := range r.sets {
+		r.sorted = append(r.sorted, set)
+	}
+	slices.SortFunc(r.sorted, func(a, b EventSet) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// Get returns the event set called name.
+func (r *EventSetRegistry) Get(name string) (EventSet, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.sets[name]
+	return s, ok
+}
+
+// List returns every event set, highest priority first.
+func (r *EventSetRegistry) List() []EventSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.sorted)
+}
+
+// find returns the mapping responsible for a field key, using the same
+// matching rules as the Python resolver. Callers hold r.mu.
+func (r *EventSetRegistry) find(key string) *EventMapping {
+	simple := key
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		simple = key[i+1:]
+	}
+	underscored := strings.ReplaceAll(key, ".", "_")
+	for _, set := range r.sorted {
+		for i := range set.Mappings {
+			m := &set.Mappings[i]
+			if m.Name == simple || m.Name == key {
+				return m
+			}
+			if strings.Contains(key, ".") && m.Name == underscored {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// marker applies m to value, adding metadata fields to rec, and returns the
+// visual marker if any.
+func (m *EventMapping) marker(rec *Record, value any) string {
+	str := strings.ToLower(fmt.Sprint(value))
+	if t, ok := m.Transformations[str]; ok {
+		str = strings.ToLower(fmt.Sprint(t(value)))
+	}
+	meta := m.MetadataFields[str]
+	for _, k := range slices.Sorted(maps.Keys(meta)) {
+		if _, exists := rec.Get(k); !exists {
+			rec.Attrs = append(rec.Attrs, Attr{Key: k, Value: meta[k]})
+		}
+	}
+	if marker, ok := m.VisualMarkers[str]; ok {
+		return marker
+	}
+	return m.VisualMarkers[m.DefaultKey]
+}
+
+// Enrich adds visual markers and metadata fields to rec. When the record
+// has no explicit domain/action/status fields but its event name is in
+// Domain-Action-Status form, the parsed segments are used for the markers.
+func (r *EventSetRegistry) Enrich(rec *Record) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var markers []string
+	add := func(key string, value any) {
+		if value == nil {
+			return
+		}
+		if m := r.find(key); m != nil {
+			if marker := m.marker(rec, value); marker != "" {
+				markers = append(markers, marker)
+			}
+		}
+	}
+
+	_, hasDomain := rec.Get(DomainKey)
+	_, hasAction := rec.Get(ActionKey)
+	_, hasStatus := rec.Get(StatusKey)
+	if !hasDomain && !hasAction && !hasStatus {
+		if domain, action, status, ok := ParseEvent(rec.Event); ok {
+			add(DomainKey, domain)
+			add(ActionKey, action)
+			add(StatusKey, status)
+		}
+	}
+	// The range expression is evaluated once, so metadata fields appended
+	// while enriching are not themselves enriched.
+	for _, a := range rec.Attrs {
+		add(a.Key, a.Value)
+	}
+	rec.Markers = append(rec.Markers, markers...)
+}
+
+// WithEventSets enables event-set enrichment using reg, matching the
+// Python logger's DAS emoji prefixes. Pass DefaultEventSets() for the
+// built-in sets.
+func WithEventSets(reg *EventSetRegistry) Option {
+	return WithProcessor(func(_ context.Context, r *Record) bool {
+		reg.Enrich(r)
+		return true
+	})
+}
@@ -0,0 +1,394 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+// Built-in event sets, kept in sync with
+// src/provide/foundation/eventsets/sets in the Python implementation.
+
+// BuiltinEventSets returns the event sets shipped with foundation: the
+// Domain-Action-Status default set plus http, database, llm and task_queue.
+func BuiltinEventSets() []EventSet {
+	return []EventSet{dasEventSet, httpEventSet, databaseEventSet, llmEventSet, taskQueueEventSet}
+}
+
+var dasEventSet = EventSet{
+	Name:        "default",
+	Description: "Core Domain-Action-Status event enrichment",
+	Priority:    0,
+	Mappings: []EventMapping{
+		{
+			Name: "domain",
+			VisualMarkers: map[string]string{
+				"server":    "🛎️",
+				"client":    "🙋",
+				"network":   "🌐",
+				"security":  "🔐",
+				"config":    "🔩",
+				"database":  "🗄️",
+				"cache":     "💾",
+				"task":      "🔄",
+				"telemetry": "🛰️",
+				"di":        "💉",
+				"protocol":  "📡",
+				"user":      "👤",
+				"core":      "🌟",
+				"auth":      "🔑",
+				"entity":    "🦎",
+				"report":    "📈",
+				"payment":   "💳",
+				"default":   "❓",
+			},
+			DefaultKey: "default",
+		},
+		{
+			Name: "action",
+			VisualMarkers: map[string]string{
+				"init":       "🌱",
+				"start":      "🚀",
+				"stop":       "🛑",
+				"connect":    "🔗",
+				"disconnect": "💔",
+				"listen":     "👂",
+				"send":       "📤",
+				"receive":    "📥",
+				"write":      "📝",
+				"validate":   "🛡️",
+				"execute":    "▶️",
+				"query":      "🔍",
+				"update":     "🔄",
+				"delete":     "🗑️",
+				"login":      "➡️",
+				"logout":     "⬅️",
+				"auth":       "🔑",
+				"register":   "📋",
+				"error":      "🔥",
+				"encrypt":    "🛡️",
+				"decrypt":    "🔓",
+				"transmit":   "📡",
+				"schedule":   "📅",
+				"emit":       "📢",
+				"load":       "💡",
+				"observe":    "🧐",
+				"request":    "🗣️",
+				"interrupt":  "🚦",
+				"default":    "❓",
+			},
+			DefaultKey: "default",
+		},
+		{
+			Name: "status",
+			VisualMarkers: map[string]string{
+				"failure":      "❌",
+				"error":        "🔥",
+				"warning":      "⚠️",
+				"info":         "i",
+				"debug":        "🐞",
+				"trace":        "👣",
+				"attempt":      "⏳",
+				"retry":        "🔁",
+				"skip":         "⏭️",
+				"complete":     "🏁",
+				"timeout":      "⏱️",
+				"notfound":     "❓",
+				"unauthorized": "🚫",
+				"invalid":      "💢",
+				"cached":       "🎯",
+				"ongoing":      "🏃",
+				"idle":         "💤",
+				"ready":        "👍",
+				"default":      "➡️",
+			},
+			DefaultKey: "default",
+		},
+	},
+	FieldMappings: []FieldMapping{
+		{LogKey: "domain", Description: "System domain or component", EventSetName: "default"},
+		{LogKey: "action", Description: "Action being performed", EventSetName: "default"},
+		{LogKey: "status", Description: "Status or outcome of the action", EventSetName: "default"},
+	},
+}
+
+var httpEventSet = EventSet{
+	Name:        "http",
+	Description: "HTTP client and server interaction enrichment",
+	Priority:    80,
+	Mappings: []EventMapping{
+		{
+			Name: "http_method",
+			VisualMarkers: map[string]string{
+				"get":     "📥",
+				"post":    "📤",
+				"put":     "📝⬆️",
+				"delete":  "🗑️",
+				"patch":   "🩹",
+				"head":    "👤❔",
+				"default": "🌐",
+			},
+			DefaultKey: "default",
+		},
+		{
+			Name: "http_status_class",
+			VisualMarkers: map[string]string{
+				"1xx":     "i",
+				"3xx":     "↪️",
+				"4xx":     "⚠️CLIENT",
+				"5xx":     "🔥SERVER",
+				"default": "❓",
+			},
+			MetadataFields: map[string]map[string]any{
+				"2xx": {"http.success": true},
+				"4xx": {"http.client_error": true},
+				"5xx": {"http.server_error": true},
+			},
+			DefaultKey: "default",
+		},
+		{
+			Name: "http_target_type",
+			VisualMarkers: map[string]string{
+				"path":     "🛣️",
+				"query":    "❓",
+				"fragment": "#️⃣",
+				"default":  "🎯",
+			},
+			DefaultKey: "default",
+		},
+	},
+	FieldMappings: []FieldMapping{
+		{LogKey: "http.method", Description: "HTTP request method", ValueType: "string", EventSetName: "http"},
+		{LogKey: "http.status_class", Description: "HTTP status code class", ValueType: "string", EventSetName: "http"},
+		{LogKey: "http.target", Description: "Request target path and query", ValueType: "string", EventSetName: "http", DefaultOverrideKey: "path"},
+		{LogKey: "http.url", Description: "Full HTTP URL", ValueType: "string", EventSetName: "http"},
+		{LogKey: "http.scheme", Description: "URL scheme", ValueType: "string", EventSetName: "http"},
+		{LogKey: "http.host", Description: "Request hostname", ValueType: "string", EventSetName: "http"},
+		{LogKey: "http.status_code", Description: "HTTP response status code", ValueType: "integer", EventSetName: "http"},
+		{LogKey: "http.request.body.size", Description: "Request body size in bytes", ValueType: "integer", EventSetName: "http"},
+		{LogKey: "http.response.body.size", Description: "Response body size in bytes", ValueType: "integer", EventSetName: "http"},
+		{LogKey: "client.address", Description: "Client IP address", ValueType: "string", EventSetName: "http"},
+		{LogKey: "server.address", Description: "Server address or hostname", ValueType: "string", EventSetName: "http"},
+		{LogKey: "duration_ms", Description: "Request duration in milliseconds", ValueType: "integer", EventSetName: "http"},
+		{LogKey: "trace_id", Description: "Distributed trace ID", ValueType: "string", EventSetName: "http"},
+		{LogKey: "span_id", Description: "Span ID", ValueType: "string", EventSetName: "http"},
+		{LogKey: "error.message", Description: "Error message if request failed", ValueType: "string", EventSetName: "http"},
+		{LogKey: "error.type", Description: "Error type if request failed", ValueType: "string", EventSetName: "http"},
+	},
+}
+
+var databaseEventSet = EventSet{
+	Name:        "database",
+	Description: "Database interaction and query enrichment",
+	Priority:    90,
+	Mappings: []EventMapping{
+		{
+			Name: "db_system",
+			VisualMarkers: map[string]string{
+				"postgres":      "🐘",
+				"mysql":         "🐬",
+				"sqlite":        "💾",
+				"mongodb":       "🍃",
+				"redis":         "🟥",
+				"elasticsearch": "🔍",
+				"default":       "🗄️",
+			},
+			MetadataFields: map[string]map[string]any{
+				"postgres":      {"db.type": "sql", "db.vendor": "postgresql"},
+				"mysql":         {"db.type": "sql", "db.vendor": "mysql"},
+				"sqlite":        {"db.type": "sql", "db.vendor": "sqlite"},
+				"mongodb":       {"db.type": "nosql", "db.vendor": "mongodb"},
+				"redis":         {"db.type": "cache", "db.vendor": "redis"},
+				"elasticsearch": {"db.type": "search", "db.vendor": "elastic"},
+			},
+			DefaultKey: "default",
+		},
+		{
+			Name: "db_operation",
+			VisualMarkers: map[string]string{
+				"query":                "🔍",
+				"select":               "🔍",
+				"insert":               "+",
+				"update":               "🔄",
+				"delete":               "🗑️",
+				"connect":              "🔗",
+				"disconnect":           "💔",
+				"transaction_begin":    "💳🟢",
+				"transaction_rollback": "💳❌",
+			},
+			MetadataFields: map[string]map[string]any{
+				"select": {"db.read": true},
+				"query":  {"db.read": true},
+				"insert": {"db.write": true},
+				"update": {"db.write": true},
+				"delete": {"db.write": true},
+			},
+			DefaultKey: "default",
+		},
+		{
+			Name: "db_outcome",
+			VisualMarkers: map[string]string{
+				"success":   "👍",
+				"error":     "🔥",
+				"not_found": "❓🤷",
+				"timeout":   "⏱️",
+				"default":   "➡️",
+			},
+			MetadataFields: map[string]map[string]any{
+				"success": {"db.success": true},
+				"error":   {"db.error": true},
+				"timeout": {"db.timeout": true},
+			},
+			DefaultKey: "default",
+		},
+	},
+	FieldMappings: []FieldMapping{
+		{LogKey: "db.system", Description: "Database system type", ValueType: "string", EventSetName: "database"},
+		{LogKey: "db.operation", Description: "Database operation performed", ValueType: "string", EventSetName: "database"},
+		{LogKey: "db.outcome", Description: "Operation outcome", ValueType: "string", EventSetName: "database"},
+		{LogKey: "db.statement", Description: "SQL or query statement", ValueType: "string", EventSetName: "database"},
+		{LogKey: "db.table", Description: "Table name", ValueType: "string", EventSetName: "database", DefaultOverrideKey: "default"},
+		{LogKey: "db.rows_affected", Description: "Number of rows affected", ValueType: "integer", EventSetName: "database"},
+		{LogKey: "duration_ms", Description: "Query duration in milliseconds", ValueType: "integer", EventSetName: "database"},
+		{LogKey: "trace_id", Description: "Distributed trace ID", ValueType: "string", EventSetName: "database"},
+	},
+}
+
+var llmEventSet = EventSet{
+	Name:        "llm",
+	Description: "LLM provider and interaction enrichment",
+	Priority:    100,
+	Mappings: []EventMapping{
+		{
+			Name: "llm_provider",
+			VisualMarkers: map[string]string{
+				"openai":     "🤖",
+				"anthropic":  "📚",
+				"google":     "🇬",
+				"meta":       "🦙",
+				"mistral":    "🌬️",
+				"perplexity": "❓",
+				"cohere":     "🔊",
+				"default":    "💡",
+			},
+			MetadataFields: map[string]map[string]any{
+				"openai":    {"llm.vendor": "openai", "llm.api": "openai"},
+				"anthropic": {"llm.vendor": "anthropic", "llm.api": "claude"},
+				"google":    {"llm.vendor": "google", "llm.api": "gemini"},
+				"meta":      {"llm.vendor": "meta", "llm.api": "llama"},
+				"mistral":   {"llm.vendor": "mistral", "llm.api": "mistral"},
+			},
+			DefaultKey: "default",
+		},
+		{
+			Name: "llm_task",
+			VisualMarkers: map[string]string{
+				"generation":     "✍️",
+				"embedding":      "🔗",
+				"chat":           "💬",
+				"tool_use":       "🛠️",
+				"summarization":  "📜",
+				"translation":    "🌐",
+				"classification": "🏷️",
+				"default":        "⚡",
+			},
+			MetadataFields: map[string]map[string]any{
+				"generation": {"llm.type": "generative"},
+				"completion": {"llm.type": "completion"},
+				"embedding":  {"llm.type": "embedding"},
+				"chat":       {"llm.type": "conversational"},
+				"tool_use":   {"llm.type": "function_calling"},
+			},
+			DefaultKey: "default",
+		},
+		{
+			Name: "llm_outcome",
+			VisualMarkers: map[string]string{
+				"success":         "👍",
+				"error":           "🔥",
+				"filtered_input":  "🛡️👁️",
+				"filtered_output": "🛡️🗣️",
+				"rate_limit":      "⏳",
+				"partial_success": "🤏",
+				"default":         "➡️",
+			},
+			MetadataFields: map[string]map[string]any{
+				"success":         {"llm.success": true},
+				"error":           {"llm.error": true},
+				"rate_limit":      {"llm.rate_limited": true},
+				"filtered_input":  {"llm.filtered": true, "llm.filter_type": "input"},
+				"filtered_output": {"llm.filtered": true, "llm.filter_type": "output"},
+			},
+			DefaultKey: "default",
+		},
+	},
+	FieldMappings: []FieldMapping{
+		{LogKey: "llm.provider", Description: "LLM provider name", ValueType: "string", EventSetName: "llm"},
+		{LogKey: "llm.task", Description: "LLM task type", ValueType: "string", EventSetName: "llm"},
+		{LogKey: "llm.model", Description: "Model identifier", ValueType: "string", EventSetName: "llm"},
+		{LogKey: "llm.outcome", Description: "Operation outcome", ValueType: "string", EventSetName: "llm"},
+		{LogKey: "llm.input.tokens", Description: "Input token count", ValueType: "integer", EventSetName: "llm"},
+		{LogKey: "llm.output.tokens", Description: "Output token count", ValueType: "integer", EventSetName: "llm"},
+		{LogKey: "llm.tool.name", Description: "Tool/function name", ValueType: "string", EventSetName: "llm"},
+		{LogKey: "llm.tool.call_id", Description: "Tool call identifier", ValueType: "string", EventSetName: "llm"},
+		{LogKey: "duration_ms", Description: "LLM operation duration", ValueType: "integer", EventSetName: "llm"},
+		{LogKey: "trace_id", Description: "Distributed trace ID", ValueType: "string", EventSetName: "llm"},
+	},
+}
+
+var taskQueueEventSet = EventSet{
+	Name:        "task_queue",
+	Description: "Asynchronous task queue operation enrichment",
+	Priority:    70,
+	Mappings: []EventMapping{
+		{
+			Name: "task_system",
+			VisualMarkers: map[string]string{
+				"celery":   "🥕",
+				"rq":       "🟥🇶",
+				"dramatiq": "🎭",
+				"rabbitmq": "🐇",
+				"default":  "📨",
+			},
+			MetadataFields: map[string]map[string]any{
+				"celery":   {"task.broker": "celery"},
+				"rq":       {"task.broker": "redis"},
+				"dramatiq": {"task.broker": "dramatiq"},
+				"kafka":    {"task.broker": "kafka", "task.streaming": true},
+				"rabbitmq": {"task.broker": "amqp"},
+			},
+			DefaultKey: "default",
+		},
+		{
+			Name: "task_status",
+			VisualMarkers: map[string]string{
+				"submitted": "➡️📨",
+				"received":  "📥",
+				"started":   "▶️",
+				"progress":  "🔄",
+				"retrying":  "🔁",
+				"failure":   "❌🔥",
+				"revoked":   "🚫",
+				"default":   "❓",
+			},
+			MetadataFields: map[string]map[string]any{
+				"submitted": {"task.state": "pending"},
+				"received":  {"task.state": "pending"},
+				"started":   {"task.state": "active"},
+				"progress":  {"task.state": "active"},
+				"retrying":  {"task.state": "retry"},
+				"success":   {"task.state": "completed", "task.success": true},
+				"failure":   {"task.state": "failed", "task.success": false},
+				"revoked":   {"task.state": "cancelled"},
+			},
+			DefaultKey: "default",
+		},
+	},
+	FieldMappings: []FieldMapping{
+		{LogKey: "task.system", Description: "Task queue system", ValueType: "string", EventSetName: "task_queue"},
+		{LogKey: "task.status", Description: "Task execution status", ValueType: "string", EventSetName: "task_queue"},
+		{LogKey: "task.id", Description: "Unique task identifier", ValueType: "string", EventSetName: "task_queue"},
+		{LogKey: "task.name", Description: "Task or job name", ValueType: "string", EventSetName: "task_queue"},
+		{LogKey: "task.queue_name", Description: "Queue name", ValueType: "string", EventSetName: "task_queue"},
+		{LogKey: "task.retries", Description: "Retry attempt count", ValueType: "integer", EventSetName: "task_queue"},
+		{LogKey: "duration_ms", Description: "Task execution duration", ValueType: "integer", EventSetName: "task_queue"},
+		{LogKey: "trace_id", Description: "Distributed trace ID", ValueType: "string", EventSetName: "task_queue"},
+	},
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnrichUsesExplicitDASFields(t *testing.T) {
+	r := &Record{Event: "Database query executed", Attrs: []Attr{
+		String("domain", "database"), String("action", "query"), String("status", "success"),
+	}}
+	DefaultEventSets().Enrich(r)
+
+	// "success" is not a DAS status marker, so the default applies.
+	if want := []string{"🗄️", "🔍", "➡️"}; !slices.Equal(r.Markers, want) {
+		t.Fatalf("got markers %v, want %v", r.Markers, want)
+	}
+}
+
+func TestEnrichParsesDASEventNames(t *testing.T) {
+	r := &Record{Event: "user_login_complete"}
+	DefaultEventSets().Enrich(r)
+	if want := []string{"👤", "➡️", "🏁"}; !slices.Equal(r.Markers, want) {
+		t.Fatalf("got markers %v, want %v", r.Markers, want)
+	}
+	if len(r.Attrs) != 0 {
+		t.Fatalf("parsed DAS segments must not become fields: %v", r.Attrs)
+	}
+}
+
+func TestEnrichAddsDomainMetadata(t *testing.T) {
+	r := &Record{Event: "request_completed", Attrs: []Attr{
+		String("http.method", "POST"), String("http.status_class", "5xx"),
+		String("db.system", "postgres"), String("db.vendor", "custom"),
+	}}
+	DefaultEventSets().Enrich(r)
+
+	if want := []string{"📤", "🔥SERVER", "🐘"}; !slices.Equal(r.Markers, want) {
+		t.Fatalf("got markers %v, want %v", r.Markers, want)
+	}
+	if v, _ := r.Get("http.server_error"); v != true {
+		t.Error("missing http.server_error metadata")
+	}
+	if v, _ := r.Get("db.type"); v != "sql" {
+		t.Error("missing db.type metadata")
+	}
+	if v, _ := r.Get("db.vendor"); v != "custom" {
+		t.Error("metadata must not overwrite existing fields")
+	}
+}
+
+func TestRegistryPriorityAndCustomSets(t *testing.T) {
+	reg := NewEventSetRegistry(BuiltinEventSets()...)
+	reg.Register(EventSet{
+		Name:     "orders",
+		Priority: 200,
+		Mappings: []EventMapping{{
+			Name:          "status",
+			VisualMarkers: map[string]string{"shipped": "📦", "default": "🧾"},
+			DefaultKey:    "default",
+		}},
+	})
+	if got := reg.List()[0].Name; got != "orders" {
+		t.Fatalf("expected highest priority first, got %s", got)
+	}
+
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithEventSets(reg))
+	logger.Info("order_dispatch_shipped", "order_id", 7)
+	if !strings.Contains(buf.String(), "] [❓][❓][📦] order_dispatch_shipped") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
+
+func TestJSONEncoderPrefixesMarkers(t *testing.T) {
+	r := &Record{Level: LevelInfo, Event: "user_fetch_started", Markers: []string{"👤"}}
+	if got := string(JSONEncoder{}.Encode(nil, r)); !strings.Contains(got, `"event":"[👤] user_fetch_started"`) {
+		t.Fatalf("unexpected JSON %s", got)
+	}
+}
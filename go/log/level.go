@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Level is a log severity. The numeric values match the Python foundation
+// logger so levels compare the same way in both implementations.
+type Level int
+
+// Log levels, lowest to highest.
+const (
+	LevelTrace    Level = 5
+	LevelDebug    Level = 10
+	LevelInfo     Level = 20
+	LevelWarning  Level = 30
+	LevelError    Level = 40
+	LevelCritical Level = 50
+	LevelFatal    Level = 60
+)
+
+// String returns the lower-case level name used in rendered records.
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarning:
+		return "warning"
+	case LevelError:
+		return "error"
+	case LevelCritical:
+		return "critical"
+	case LevelFatal:
+		return "fatal"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel parses a level name in any case, e.g. "INFO" or "warn".
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TRACE":
+		return LevelTrace, nil
+	case "DEBUG":
+		return LevelDebug, nil
+	case "INFO":
+		return LevelInfo, nil
+	case "WARNING", "WARN":
+		return LevelWarning, nil
+	case "ERROR":
+		return LevelError, nil
+	case "CRITICAL":
+		return LevelCritical, nil
+	case "FATAL":
+		return LevelFatal, nil
+	}
+	return LevelInfo, fmt.Errorf("log: unknown level %q", s)
+}
+
+// LevelVar is a Level that can be changed concurrently with logging.
+type LevelVar struct {
+	v atomic.Int64
+}
+
+// NewLevelVar returns a LevelVar set to l.
+func NewLevelVar(l Level) *LevelVar {
+	lv := &LevelVar{}
+	lv.Set(l)
+	return lv
+}
+
+// Level returns the current level.
+func (lv *LevelVar) Level() Level { return Level(lv.v.Load()) }
+
+// Set changes the level.
+func (lv *LevelVar) Set(l Level) { lv.v.Store(int64(l)) }
+
+// levelTable resolves the effective level of each logger name from a
+// default level and per-module overrides. Overrides match by longest
+// logger-name prefix, as in the Python logger's module_levels.
+type levelTable struct {
+	mu       sync.Mutex
+	fallback Level
+	modules  map[string]Level
+	prefixes []string // module keys, longest first
+	vars     map[string]*LevelVar
+}
+
+func newLevelTable(fallback Level, modules map[string]Level) *levelTable {
+	t := &levelTable{vars: make(map[string]*LevelVar)}
+	t.configure(fallback, modules)
+	return t
+}
+
+func (t *levelTable) configure(fallback Level, modules map[string]Level) {
+	t.fallback = fallback
+	t.modules = maps.Clone(modules)
+	t.prefixes = slices.SortedFunc(maps.Keys(modules), func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+}
+
+func (t *levelTable) resolve(name string) Level {
+	for _, prefix := range t.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return t.modules[prefix]
+		}
+	}
+	return t.fallback
+}
+
+// varFor returns the shared LevelVar of a logger name, creating it with
+// the resolved level on first use.
+func (t *levelTable) varFor(name string) *LevelVar {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lv, ok := t.vars[name]
+	if !ok {
+		lv = NewLevelVar(t.resolve(name))
+		t.vars[name] = lv
+	}
+	return lv
+}
+
+// ParseModuleLevels parses "module:LEVEL" pairs separated by commas, e.g.
+// "repository:DEBUG,httpclient:WARN". Malformed entries are skipped and
+// reported in the returned error alongside the valid ones.
+func ParseModuleLevels(s string) (map[string]Level, error) {
+	levels := make(map[string]Level)
+	var errs []error
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		module, name, ok := strings.Cut(pair, ":")
+		module = strings.TrimSpace(module)
+		if !ok || module == "" {
+			errs = append(errs, fmt.Errorf("log: invalid module level %q, want module:LEVEL", pair))
+			continue
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("log: module %q: %w", module, err))
+			continue
+		}
+		levels[module] = level
+	}
+	return levels, errors.Join(errs...)
+}
@@ -0,0 +1,321 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package log is the structured, leveled logger of the Go foundation. It
+// follows the Python foundation logger: events are short snake_case names
+// in Domain-Action-Status form, and everything else is a key/value field.
+//
+//	logger := log.New(log.WithFormat(log.JSONEncoder{}))
+//	logger.Info("user_fetch_started", "user_id", 1)
+//
+// Records pass through the configured processors, which may enrich,
+// rewrite or drop them, and are then written to every sink.
+//
+// A call below the logger's level returns before any record is built and
+// does not allocate when its fields are constants. Arguments are still
+// evaluated by Go before the call, so wrap expensive values in Lazy, or
+// guard the call with Enabled when a field would otherwise be boxed:
+//
+//	logger.Debug("cache_state_dumped", "entries", log.Lazy(cache.Dump))
+//	if logger.Enabled(log.LevelDebug) {
+//		logger.Debug("user_fetch_started", "user_id", id)
+//	}
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/env"
+	"github.com/provide-io/provide-foundation/go/term"
+)
+
+// Processor inspects or modifies a record before it reaches the sinks.
+// Returning false drops the record. Records are reused, so a processor
+// must not retain r after it returns.
+type Processor func(ctx context.Context, r *Record) bool
+
+// Option configures a Logger created by New.
+type Option func(*options)
+
+type options struct {
+	name       string
+	level      Level
+	modules    map[string]Level
+	encoder    Encoder
+	output     io.Writer
+	sinks      []Sink
+	processors []Processor
+}
+
+// WithName sets the logger name reported as logger_name.
+func WithName(name string) Option {
+	return func(o *options) { o.name = name }
+}
+
+// WithLevel sets the minimum level that is logged.
+func WithLevel(level Level) Option {
+	return func(o *options) { o.level = level }
+}
+
+// WithModuleLevels sets per-module level overrides. A logger uses the
+// level of the longest module name that prefixes its own name, e.g.
+// {"repository": LevelDebug} applies to "repository" and "repository.cache".
+func WithModuleLevels(levels map[string]Level) Option {
+	return func(o *options) { o.modules = levels }
+}
+
+// WithFormat sets the encoder of the default output sink.
+func WithFormat(enc Encoder) Option {
+	return func(o *options) { o.encoder = enc }
+}
+
+// WithOutput sets the writer of the default output sink. The default is
+// os.Stderr.
+func WithOutput(w io.Writer) Option {
+	return func(o *options) { o.output = w }
+}
+
+// WithSink adds a sink. Once any sink is added the default output sink is
+// no longer created; add a WriterSink explicitly to keep it.
+func WithSink(s Sink) Option {
+	return func(o *options) { o.sinks = append(o.sinks, s) }
+}
+
+// WithProcessor appends a processor to the pipeline.
+func WithProcessor(p Processor) Option {
+	return func(o *options) { o.processors = append(o.processors, p) }
+}
+
+// core is shared by a logger and every logger derived from it.
+type core struct {
+	levels     *levelTable
+	processors []Processor
+	sinks      []Sink
+}
+
+// Logger writes structured records. Loggers are safe for concurrent use;
+// With and Named return new loggers sharing the same sinks and level.
+type Logger struct {
+	core  *core
+	name  string
+	level *LevelVar
+	attrs []Attr
+}
+
+// New creates a logger. Without options it writes key/value lines at INFO
+// and above to os.Stderr, colored when term.Detect finds it a terminal.
+// Options apply in order, so WithEnv placed after WithLevel lets the
+// environment override the coded default.
+func New(opts ...Option) *Logger {
+	o := options{level: LevelInfo, encoder: ConsoleEncoder{}, output: os.Stderr}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	sinks := o.sinks
+	if len(sinks) == 0 {
+		enc := o.encoder
+		if console, ok := enc.(ConsoleEncoder); ok {
+			caps := term.Detect(o.output)
+			console.Color = console.Color || caps.Color
+			console.NoEmoji = console.NoEmoji || !caps.Emoji
+			enc = console
+		}
+		sinks = []Sink{NewWriterSink(o.output, enc)}
+	}
+	c := &core{
+		levels:     newLevelTable(o.level, o.modules),
+		processors: o.processors,
+		sinks:      sinks,
+	}
+	return &Logger{core: c, name: o.name, level: c.levels.varFor(o.name)}
+}
+
+var defaultLogger atomic.Pointer[Logger]
+
+func init() {
+	defaultLogger.Store(New(WithProfile(env.Current()), WithEnv()))
+}
+
+// Default returns the process-wide logger. It follows the defaults of
+// env.Current() and the level settings of the environment.
+func Default() *Logger { return defaultLogger.Load() }
+
+// SetDefault replaces the process-wide logger.
+func SetDefault(l *Logger) { defaultLogger.Store(l) }
+
+// Name returns the logger name.
+func (l *Logger) Name() string { return l.name }
+
+// Level returns the minimum level currently logged.
+func (l *Logger) Level() Level { return l.level.Level() }
+
+// Enabled reports whether records at level would be logged.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.level.Level()
+}
+
+// With returns a logger that adds the given key/value fields to every
+// record.
+func (l *Logger) With(kv ...any) *Logger {
+	c := *l
+	c.attrs = appendKV(slices.Clip(l.attrs), kv)
+	return &c
+}
+
+// Named returns a child logger. Names nest with dots, so
+// log.Named("app").Named("repository") is "app.repository". The child's
+// level is resolved from the module levels for its full name.
+func (l *Logger) Named(name string) *Logger {
+	c := *l
+	if l.name == "" {
+		c.name = name
+	} else {
+		c.name = l.name + "." + name
+	}
+	c.level = l.core.levels.varFor(c.name)
+	return &c
+}
+
+// Flush waits for every sink that buffers records, such as AsyncSink, to
+// write them out. Call it before the process exits.
+func (l *Logger) Flush(ctx context.Context) error {
+	var errs []error
+	for _, s := range l.core.sinks {
+		if f, ok := s.(Flusher); ok {
+			errs = append(errs, f.Flush(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Trace logs at TRACE level.
+func (l *Logger) Trace(event string, kv ...any) { l.log(context.Background(), LevelTrace, event, kv) }
+
+// Debug logs at DEBUG level.
+func (l *Logger) Debug(event string, kv ...any) { l.log(context.Background(), LevelDebug, event, kv) }
+
+// Info logs at INFO level.
+func (l *Logger) Info(event string, kv ...any) { l.log(context.Background(), LevelInfo, event, kv) }
+
+// Warn logs at WARNING level.
+func (l *Logger) Warn(event string, kv ...any) { l.log(context.Background(), LevelWarning, event, kv) }
+
+// Error logs at ERROR level.
+func (l *Logger) Error(event string, kv ...any) { l.log(context.Background(), LevelError, event, kv) }
+
+// Critical logs at CRITICAL level.
+func (l *Logger) Critical(event string, kv ...any) {
+	l.log(context.Background(), LevelCritical, event, kv)
+}
+
+// TraceCtx logs at TRACE level with the trace context and request metadata
+// of ctx.
+func (l *Logger) TraceCtx(ctx context.Context, event string, kv ...any) {
+	l.log(ctx, LevelTrace, event, kv)
+}
+
+// DebugCtx logs at DEBUG level with the trace context and request metadata
+// of ctx.
+func (l *Logger) DebugCtx(ctx context.Context, event string, kv ...any) {
+	l.log(ctx, LevelDebug, event, kv)
+}
+
+// InfoCtx logs at INFO level with the trace context and request metadata
+// of ctx.
+func (l *Logger) InfoCtx(ctx context.Context, event string, kv ...any) {
+	l.log(ctx, LevelInfo, event, kv)
+}
+
+// WarnCtx logs at WARNING level with the trace context and request metadata
+// of ctx.
+func (l *Logger) WarnCtx(ctx context.Context, event string, kv ...any) {
+	l.log(ctx, LevelWarning, event, kv)
+}
+
+// ErrorCtx logs at ERROR level with the trace context and request metadata
+// of ctx.
+func (l *Logger) ErrorCtx(ctx context.Context, event string, kv ...any) {
+	l.log(ctx, LevelError, event, kv)
+}
+
+// CriticalCtx logs at CRITICAL level with the trace context and request
+// metadata of ctx.
+func (l *Logger) CriticalCtx(ctx context.Context, event string, kv ...any) {
+	l.log(ctx, LevelCritical, event, kv)
+}
+
+// exit is replaced in tests.
+var exit = os.Exit
+
+// fatalFlushTimeout bounds how long Fatal waits for buffered sinks.
+const fatalFlushTimeout = 5 * time.Second
+
+// Fatal logs at FATAL level, flushes buffered sinks and terminates the
+// process with status 1.
+func (l *Logger) Fatal(event string, kv ...any) {
+	l.FatalCtx(context.Background(), event, kv...)
+}
+
+// FatalCtx is Fatal with the trace context and request metadata of ctx.
+func (l *Logger) FatalCtx(ctx context.Context, event string, kv ...any) {
+	l.log(ctx, LevelFatal, event, kv)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fatalFlushTimeout)
+	_ = l.Flush(ctx)
+	cancel()
+	exit(1)
+}
+
+// Log logs at an arbitrary level.
+func (l *Logger) Log(level Level, event string, kv ...any) {
+	l.log(context.Background(), level, event, kv)
+}
+
+// LogCtx logs at an arbitrary level with the trace context and request
+// metadata of ctx.
+func (l *Logger) LogCtx(ctx context.Context, level Level, event string, kv ...any) {
+	l.log(ctx, level, event, kv)
+}
+
+func (l *Logger) log(ctx context.Context, level Level, event string, kv []any) {
+	if !l.Enabled(level) {
+		return
+	}
+	r := newRecord()
+	r.Time = time.Now()
+	r.Level = level
+	r.Logger = l.name
+	r.Event = event
+	r.Attrs = append(r.Attrs, l.attrs...)
+	r.Attrs = appendKV(r.Attrs, kv)
+	resolveLazy(r.Attrs)
+	l.core.emit(ctx, r)
+	freeRecord(r)
+}
+
+func (c *core) emit(ctx context.Context, r *Record) {
+	addTraceContext(ctx, r)
+	addMetadata(ctx, r)
+	for _, p := range c.processors {
+		if !p(ctx, r) {
+			return
+		}
+	}
+	for _, s := range c.sinks {
+		if err := s.Write(r); err != nil {
+			reportSinkError(s, err)
+		}
+	}
+}
+
+// reportSinkError writes a sink failure to stderr; logging it through the
+// logger could fail the same way.
+func reportSinkError(s Sink, err error) {
+	fmt.Fprintf(os.Stderr, "log: sink %T: %v\n", s, err)
+}
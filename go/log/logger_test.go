@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestLoggerEmitsStructuredJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}), WithName("repository"))
+
+	logger.Info("user_fetch_started", "user_id", 1, Bool("cached", false))
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 1 {
+		t.Fatalf("expected one record, got %d", len(lines))
+	}
+	got := lines[0]
+	for key, want := range map[string]any{
+		"event":       "user_fetch_started",
+		"level":       "info",
+		"logger_name": "repository",
+		"user_id":     float64(1),
+		"cached":      false,
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %v", key, got[key], want)
+		}
+	}
+	if _, ok := got["timestamp"]; !ok {
+		t.Error("missing timestamp")
+	}
+}
+
+func TestLoggerFiltersByLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}), WithLevel(LevelWarning))
+
+	logger.Debug("cache_lookup_started")
+	logger.Info("user_fetch_started")
+	logger.Warn("user_fetch_slow")
+	logger.Error("user_fetch_failed")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 2 || lines[0]["event"] != "user_fetch_slow" || lines[1]["level"] != "error" {
+		t.Fatalf("unexpected records: %v", lines)
+	}
+	if logger.Enabled(LevelInfo) || !logger.Enabled(LevelCritical) {
+		t.Fatal("Enabled disagrees with configured level")
+	}
+}
+
+func TestWithAndNamedDeriveLoggers(t *testing.T) {
+	var buf bytes.Buffer
+	root := New(WithOutput(&buf), WithFormat(JSONEncoder{}), WithName("app"))
+	repo := root.Named("repository").With("component", "users")
+	repo.With("request_id", "abc").Info("user_fetch_started")
+	repo.Info("user_fetch_completed")
+
+	lines := decodeLines(t, &buf)
+	if lines[0]["logger_name"] != "app.repository" || lines[0]["request_id"] != "abc" {
+		t.Fatalf("unexpected first record: %v", lines[0])
+	}
+	if _, leaked := lines[1]["request_id"]; leaked || lines[1]["component"] != "users" {
+		t.Fatalf("With fields leaked between siblings: %v", lines[1])
+	}
+}
+
+func TestMalformedKeyValues(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}))
+	logger.Info("odd_args_logged", 42, "dangling")
+
+	got := decodeLines(t, &buf)[0]
+	if got[badKey] != "dangling" {
+		t.Fatalf("expected %s field, got %v", badKey, got)
+	}
+}
+
+func TestProcessorsCanRewriteAndDrop(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(
+		WithOutput(&buf),
+		WithFormat(JSONEncoder{}),
+		WithProcessor(func(_ context.Context, r *Record) bool {
+			r.Set("service_name", "users")
+			return r.Event != "health_check_completed"
+		}),
+	)
+	logger.Info("health_check_completed")
+	logger.Info("user_fetch_started")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 1 || lines[0]["service_name"] != "users" {
+		t.Fatalf("unexpected records: %v", lines)
+	}
+}
+
+func TestFatalExits(t *testing.T) {
+	var code int
+	exit = func(c int) { code = c }
+	t.Cleanup(func() { exit = os.Exit })
+
+	var buf bytes.Buffer
+	New(WithOutput(&buf)).Fatal("config_load_failed")
+	if code != 1 || !strings.Contains(buf.String(), "[fatal   ] config_load_failed") {
+		t.Fatalf("code=%d output=%q", code, buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]Level{
+		"trace": LevelTrace, "DEBUG": LevelDebug, " Info ": LevelInfo,
+		"WARN": LevelWarning, "warning": LevelWarning, "critical": LevelCritical,
+	} {
+		got, err := ParseLevel(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	d, a, s, ok := ParseEvent("user_password_reset_completed")
+	if !ok || d != "user" || a != "password_reset" || s != "completed" {
+		t.Fatalf("got %q %q %q %v", d, a, s, ok)
+	}
+	for _, bad := range []string{"started", "cache_miss", "user__started", "user_fetch_", "User login attempt"} {
+		if _, _, _, ok := ParseEvent(bad); ok {
+			t.Errorf("ParseEvent(%q) unexpectedly ok", bad)
+		}
+	}
+	if Event("user", "fetch", "started") != "user_fetch_started" {
+		t.Error("Event did not join segments")
+	}
+}
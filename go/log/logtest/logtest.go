@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package logtest records log output in memory so tests can assert on
+// structured events instead of scraping text:
+//
+//	rec := logtest.Capture(t)
+//	svc := NewUserService(rec.Logger())
+//	svc.GetUser(ctx, 1)
+//	if rec.WithEvent("user_fetch_started").Count() != 1 { ... }
+package logtest
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Recorder is a log.Sink that keeps every record written to it.
+type Recorder struct {
+	logger *log.Logger
+
+	mu      sync.Mutex
+	records []*log.Record
+}
+
+// Capture returns a recorder and makes its logger the process default
+// until the test ends. The logger logs every level; opts are applied
+// after that, so they may raise the level or add processors. Tests using
+// Capture must not run in parallel with others relying on log.Default.
+func Capture(t testing.TB, opts ...log.Option) *Recorder {
+	t.Helper()
+	rec := &Recorder{}
+	rec.logger = log.New(append([]log.Option{log.WithLevel(log.LevelTrace), log.WithSink(rec)}, opts...)...)
+	prev := log.Default()
+	log.SetDefault(rec.logger)
+	t.Cleanup(func() { log.SetDefault(prev) })
+	return rec
+}
+
+// Logger returns the logger writing to the recorder.
+func (r *Recorder) Logger() *log.Logger { return r.logger }
+
+// Write implements log.Sink.
+func (r *Recorder) Write(rec *log.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rec.Clone())
+	return nil
+}
+
+// Records returns a snapshot of the records written so far.
+func (r *Recorder) Records() Records {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.records)
+}
+
+// WithEvent is shorthand for r.Records().WithEvent(event).
+func (r *Recorder) WithEvent(event string) Records { return r.Records().WithEvent(event) }
+
+// Reset discards the recorded records.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
+
+// Records is a list of captured records with query helpers. Filters
+// return new lists and can be chained.
+type Records []*log.Record
+
+// Filter returns the records for which keep returns true.
+func (rs Records) Filter(keep func(*log.Record) bool) Records {
+	var out Records
+	for _, r := range rs {
+		if keep(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// WithEvent returns the records with the given event name.
+func (rs Records) WithEvent(event string) Records {
+	return rs.Filter(func(r *log.Record) bool { return r.Event == event })
+}
+
+// WithLevel returns the records logged at level.
+func (rs Records) WithLevel(level log.Level) Records {
+	return rs.Filter(func(r *log.Record) bool { return r.Level == level })
+}
+
+// WithLogger returns the records of the named logger.
+func (rs Records) WithLogger(name string) Records {
+	return rs.Filter(func(r *log.Record) bool { return r.Logger == name })
+}
+
+// WithField returns the records having field key equal to value, compared
+// with reflect.DeepEqual.
+func (rs Records) WithField(key string, value any) Records {
+	return rs.Filter(func(r *log.Record) bool {
+		v, ok := r.Get(key)
+		return ok && reflect.DeepEqual(v, value)
+	})
+}
+
+// HasField returns the records that have field key, whatever its value.
+func (rs Records) HasField(key string) Records {
+	return rs.Filter(func(r *log.Record) bool {
+		_, ok := r.Get(key)
+		return ok
+	})
+}
+
+// Count returns the number of records.
+func (rs Records) Count() int { return len(rs) }
+
+// Events returns the event names in order.
+func (rs Records) Events() []string {
+	out := make([]string, len(rs))
+	for i, r := range rs {
+		out[i] = r.Event
+	}
+	return out
+}
+
+// First returns the first record, or nil.
+func (rs Records) First() *log.Record {
+	if len(rs) == 0 {
+		return nil
+	}
+	return rs[0]
+}
+
+// Last returns the last record, or nil.
+func (rs Records) Last() *log.Record {
+	if len(rs) == 0 {
+		return nil
+	}
+	return rs[len(rs)-1]
+}
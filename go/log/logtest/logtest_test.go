@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package logtest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+func TestCaptureRecordsAndQueries(t *testing.T) {
+	rec := Capture(t)
+	if log.Default() != rec.Logger() {
+		t.Fatal("Capture did not install its logger as the default")
+	}
+
+	repo := rec.Logger().Named("repository")
+	repo.Info("user_fetch_started", "user_id", 1)
+	repo.Debug("cache_miss_detected", "user_id", 1)
+	repo.Info("user_fetch_started", "user_id", 2)
+	log.Default().Error("user_fetch_failed", "user_id", 2, log.Err(nil))
+
+	if got := rec.WithEvent("user_fetch_started").Count(); got != 2 {
+		t.Errorf("user_fetch_started count = %d", got)
+	}
+	if got := rec.Records().WithField("user_id", 2).Events(); !slices.Equal(got, []string{"user_fetch_started", "user_fetch_failed"}) {
+		t.Errorf("user_id=2 events = %v", got)
+	}
+	if got := rec.Records().WithLogger("repository").WithLevel(log.LevelDebug).First(); got == nil || got.Event != "cache_miss_detected" {
+		t.Errorf("debug record = %v", got)
+	}
+	if got := rec.Records().HasField("error").Last(); got == nil || got.Event != "user_fetch_failed" {
+		t.Errorf("record with error = %v", got)
+	}
+	if rec.WithEvent("missing").First() != nil {
+		t.Error("First of an empty list should be nil")
+	}
+
+	rec.Reset()
+	if rec.Records().Count() != 0 {
+		t.Error("Reset kept records")
+	}
+}
+
+func TestCaptureRestoresDefault(t *testing.T) {
+	prev := log.Default()
+	t.Run("capture", func(t *testing.T) { Capture(t) })
+	if log.Default() != prev {
+		t.Error("default logger was not restored")
+	}
+}
@@ -0,0 +1,268 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package otlplog exports log records to an OpenTelemetry collector over
+// OTLP/gRPC or OTLP/HTTP. The exporter is a log.Sink that batches records
+// in the background:
+//
+//	exp, err := otlplog.New()
+//	logger := log.New(log.WithSink(exp), log.WithSink(log.NewWriterSink(os.Stderr, log.ConsoleEncoder{})))
+//	defer exp.Close(ctx)
+//
+// Configuration comes from the standard OTEL_EXPORTER_OTLP_* and
+// OTEL_RESOURCE_ATTRIBUTES variables unless overridden with options.
+package otlplog
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/batch"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/otlp"
+	"github.com/provide-io/provide-foundation/go/telemetry"
+)
+
+// Defaults follow the OpenTelemetry batch log processor.
+const (
+	DefaultBatchSize      = 512
+	DefaultQueueSize      = 2048
+	DefaultExportInterval = time.Second
+)
+
+// scopeName is used for records without a logger name.
+const scopeName = "github.com/provide-io/provide-foundation/go/log"
+
+// Option configures an Exporter.
+type Option func(*Exporter)
+
+// WithConfig replaces the configuration read from the environment.
+func WithConfig(cfg otlp.Config) Option {
+	return func(e *Exporter) { e.cfg = &cfg }
+}
+
+// WithResource adds resource attributes such as service.version.
+// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES still take precedence.
+func WithResource(attrs ...otlp.KeyValue) Option {
+	return func(e *Exporter) { e.extraResource = append(e.extraResource, attrs...) }
+}
+
+// WithBatchSize sets the maximum number of records per export request.
+func WithBatchSize(n int) Option {
+	return func(e *Exporter) {
+		if n > 0 {
+			e.batchSize = n
+		}
+	}
+}
+
+// WithQueueSize sets how many records may wait for export; further
+// records are dropped.
+func WithQueueSize(n int) Option {
+	return func(e *Exporter) {
+		if n > 0 {
+			e.queueSize = n
+		}
+	}
+}
+
+// WithExportInterval sets how often queued records are exported when a
+// full batch has not accumulated.
+func WithExportInterval(d time.Duration) Option {
+	return func(e *Exporter) {
+		if d > 0 {
+			e.interval = d
+		}
+	}
+}
+
+// Exporter is a log.Sink that exports records over OTLP.
+type Exporter struct {
+	cfg           *otlp.Config
+	extraResource []otlp.KeyValue
+	batchSize     int
+	queueSize     int
+	interval      time.Duration
+
+	client    *otlp.Client
+	resource  []otlp.KeyValue
+	batches   *batch.Processor[*log.Record]
+	closeOnce sync.Once
+}
+
+// New returns an exporter and starts its background goroutine.
+func New(opts ...Option) (*Exporter, error) {
+	e := &Exporter{
+		batchSize: DefaultBatchSize,
+		queueSize: DefaultQueueSize,
+		interval:  DefaultExportInterval,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.cfg == nil {
+		cfg, err := otlp.ConfigFromEnv(otlp.SignalLogs)
+		if err != nil {
+			return nil, err
+		}
+		e.cfg = &cfg
+	}
+	client, err := otlp.NewClient(*e.cfg, otlp.SignalLogs)
+	if err != nil {
+		return nil, err
+	}
+	e.client = client
+	e.resource = otlp.ResourceFromEnv(e.extraResource...)
+	e.batches = batch.New(e.export,
+		batch.WithMaxSize(e.batchSize),
+		batch.WithQueueSize(e.queueSize),
+		batch.WithMaxWait(e.interval),
+		batch.WithErrorHandler(func(err error) { fmt.Fprintf(os.Stderr, "otlplog: %v\n", err) }),
+	)
+	return e, nil
+}
+
+// Write implements log.Sink. The record is queued for export, or dropped
+// when the queue is full. Nothing is queued while telemetry settings
+// disable logs.
+func (e *Exporter) Write(r *log.Record) error {
+	if !telemetry.Enabled(telemetry.Logs) {
+		return nil
+	}
+	if err := e.batches.Add(context.Background(), r.Clone()); errors.Is(err, batch.ErrClosed) {
+		return log.ErrSinkClosed
+	}
+	return nil
+}
+
+// Dropped returns how many records were discarded because the queue was
+// full.
+func (e *Exporter) Dropped() uint64 { return e.batches.Dropped() }
+
+// Flush exports every queued record. It implements log.Flusher.
+func (e *Exporter) Flush(ctx context.Context) error { return e.batches.Flush(ctx) }
+
+// Close stops the background goroutine and exports what is queued.
+// Records written afterwards are rejected with log.ErrSinkClosed.
+func (e *Exporter) Close(ctx context.Context) error {
+	var err error
+	e.closeOnce.Do(func() {
+		err = e.batches.Close(ctx)
+		e.client.Close()
+	})
+	return err
+}
+
+func (e *Exporter) export(ctx context.Context, records []*log.Record) error {
+	return e.client.Export(ctx, e.encode(records))
+}
+
+// encode builds an ExportLogsServiceRequest with one ScopeLogs per logger
+// name, its attributes cleaned by the telemetry scrubber.
+func (e *Exporter) encode(batch []*log.Record) []byte {
+	var scopes []string
+	byScope := map[string][]*log.Record{}
+	for _, r := range batch {
+		name := r.Logger
+		if name == "" {
+			name = scopeName
+		}
+		if _, ok := byScope[name]; !ok {
+			scopes = append(scopes, name)
+		}
+		byScope[name] = append(byScope[name], r)
+	}
+
+	observed := uint64(time.Now().UnixNano())
+	var enc otlp.Encoder
+	if s := telemetry.Current().Scrubber; s != nil {
+		enc.Scrub = s.Scrub
+	}
+	enc.Message(1, func(enc *otlp.Encoder) { // ResourceLogs
+		enc.Resource(1, e.resource)
+		for _, scope := range scopes {
+			enc.Message(2, func(enc *otlp.Encoder) { // ScopeLogs
+				enc.Scope(1, scope, "")
+				for _, r := range byScope[scope] {
+					enc.Message(2, func(enc *otlp.Encoder) { encodeRecord(enc, r, observed) })
+				}
+			})
+		}
+	})
+	return enc.Bytes()
+}
+
+// encodeRecord writes the fields of an opentelemetry.proto.logs.v1.LogRecord.
+func encodeRecord(enc *otlp.Encoder, r *log.Record, observed uint64) {
+	enc.Fixed64(1, uint64(r.Time.UnixNano()))
+	enc.Uint64(2, uint64(Severity(r.Level)))
+	enc.String(3, strings.ToUpper(r.Level.String()))
+	enc.AnyValue(5, r.Event)
+	var traceID, spanID []byte
+	var flags uint32
+	for _, a := range r.Attrs {
+		// Trace context travels in the dedicated LogRecord fields.
+		switch a.Key {
+		case log.TraceIDKey:
+			if b, ok := hexID(a.Value, 16); ok {
+				traceID = b
+				continue
+			}
+		case log.SpanIDKey:
+			if b, ok := hexID(a.Value, 8); ok {
+				spanID = b
+				continue
+			}
+		case log.TraceFlagsKey:
+			if f, ok := a.Value.(int); ok {
+				flags = uint32(f)
+				continue
+			}
+		}
+		enc.KeyValue(6, a.Key, a.Value)
+	}
+	if flags != 0 {
+		enc.Fixed32(8, flags)
+	}
+	if traceID != nil {
+		enc.BytesField(9, traceID)
+	}
+	if spanID != nil {
+		enc.BytesField(10, spanID)
+	}
+	enc.Fixed64(11, observed)
+}
+
+// hexID decodes a hex trace or span ID of n bytes.
+func hexID(v any, n int) ([]byte, bool) {
+	s, ok := v.(string)
+	if !ok || len(s) != 2*n {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
+
+// Severity maps a log level to the OTLP severity number, as the Python
+// foundation does: the first number of each OTLP severity range.
+func Severity(l log.Level) int {
+	switch {
+	case l >= log.LevelCritical:
+		return 21
+	case l >= log.LevelError:
+		return 17
+	case l >= log.LevelWarning:
+		return 13
+	case l >= log.LevelInfo:
+		return 9
+	case l >= log.LevelDebug:
+		return 5
+	}
+	return 1
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlplog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/otlp"
+)
+
+type collector struct {
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.mu.Lock()
+	c.bodies = append(c.bodies, body)
+	c.mu.Unlock()
+}
+
+func (c *collector) requests() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bodies
+}
+
+func TestExporterBatchesRecords(t *testing.T) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+	t.Setenv("OTEL_SERVICE_NAME", "users")
+
+	exp, err := New(
+		WithConfig(otlp.Config{Endpoint: otlp.SignalURL(srv.URL, otlp.SignalLogs), Timeout: time.Second}),
+		WithBatchSize(2),
+		WithExportInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := log.New(log.WithSink(exp), log.WithName("repository"))
+	logger.Info("user_fetch_started", "user_id", 7)
+	logger.Named("cache").Warn("cache_miss_detected")
+	logger.Error("user_fetch_failed")
+
+	if err := exp.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	reqs := col.requests()
+	if len(reqs) != 2 {
+		t.Fatalf("got %d requests, want a full batch and the remainder", len(reqs))
+	}
+	all := bytes.Join(reqs, nil)
+	for _, want := range []string{"users", "repository", "repository.cache", "user_fetch_started", "user_id", "WARNING", "user_fetch_failed"} {
+		if !bytes.Contains(all, []byte(want)) {
+			t.Errorf("export is missing %q", want)
+		}
+	}
+	if err := exp.Write(&log.Record{Event: "late"}); !errors.Is(err, log.ErrSinkClosed) {
+		t.Errorf("Write after Close returned %v", err)
+	}
+}
+
+func TestExporterDropsWhenQueueIsFull(t *testing.T) {
+	exp, err := New(
+		WithConfig(otlp.Config{Endpoint: "http://127.0.0.1:1/v1/logs", Retry: otlp.RetryConfig{}}),
+		WithQueueSize(1),
+		WithBatchSize(10),
+		WithExportInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exp.Close(context.Background())
+	_ = exp.Write(&log.Record{Event: "kept"})
+	_ = exp.Write(&log.Record{Event: "dropped"})
+	if exp.Dropped() != 1 {
+		t.Errorf("dropped = %d, want 1", exp.Dropped())
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for level, want := range map[log.Level]int{
+		log.LevelTrace: 1, log.LevelDebug: 5, log.LevelInfo: 9, log.LevelWarning: 13,
+		log.LevelError: 17, log.LevelCritical: 21, log.LevelFatal: 21,
+	} {
+		if got := Severity(level); got != want {
+			t.Errorf("Severity(%v) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestEncodeMovesTraceContextToRecordFields(t *testing.T) {
+	exp := &Exporter{}
+	r := &log.Record{Level: log.LevelInfo, Event: "traced", Attrs: []log.Attr{
+		log.String(log.TraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736"),
+		log.String(log.SpanIDKey, "00f067aa0ba902b7"),
+		log.Int(log.TraceFlagsKey, 1),
+	}}
+	body := exp.encode([]*log.Record{r})
+	if bytes.Contains(body, []byte(log.TraceIDKey)) {
+		t.Error("trace_id exported as an attribute")
+	}
+	wantTrace := append([]byte{0x4a, 16}, 0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36)
+	wantSpan := []byte{0x52, 8, 0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	if !bytes.Contains(body, wantTrace) || !bytes.Contains(body, wantSpan) || !bytes.Contains(body, []byte{0x45, 1, 0, 0, 0}) {
+		t.Errorf("trace fields missing from %x", body)
+	}
+}
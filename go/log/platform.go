@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"context"
+
+	"github.com/provide-io/provide-foundation/go/platform"
+)
+
+// WithPlatform tags every record with the fields of
+// platform.Current().Fields(): hostname and platform, plus container, wsl
+// and ci when they apply. Fields the caller set are kept. WithProfile
+// turns it on in production, where records from many hosts meet.
+func WithPlatform() Option {
+	attrs := appendKV(nil, platform.Current().Fields())
+	return WithProcessor(func(_ context.Context, r *Record) bool {
+		for _, a := range attrs {
+			if _, ok := r.Get(a.Key); !ok {
+				r.Attrs = append(r.Attrs, a)
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import "sync"
+
+// Pooled objects larger than these limits are dropped rather than kept, so
+// one huge record does not pin memory for the life of the process.
+const (
+	maxPooledAttrs  = 64
+	maxPooledBuffer = 64 << 10
+)
+
+var recordPool = sync.Pool{
+	New: func() any { return &Record{Attrs: make([]Attr, 0, 8)} },
+}
+
+// newRecord returns an empty record from the pool.
+func newRecord() *Record {
+	return recordPool.Get().(*Record)
+}
+
+// freeRecord returns r to the pool. Sinks and processors never retain
+// records, so r is unreachable once emit returns.
+func freeRecord(r *Record) {
+	if cap(r.Attrs) > maxPooledAttrs {
+		return
+	}
+	clear(r.Attrs)
+	clear(r.Markers)
+	*r = Record{Attrs: r.Attrs[:0], Markers: r.Markers[:0]}
+	recordPool.Put(r)
+}
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+func getBuffer() *[]byte { return bufferPool.Get().(*[]byte) }
+
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"slices"
+	"time"
+)
+
+// badKey is used for values that are not preceded by a string key.
+const badKey = "!BADKEY"
+
+// Attr is a single structured field.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string field.
+func String(key, value string) Attr { return Attr{Key: key, Value: value} }
+
+// Int returns an integer field.
+func Int(key string, value int) Attr { return Attr{Key: key, Value: value} }
+
+// Int64 returns a 64-bit integer field.
+func Int64(key string, value int64) Attr { return Attr{Key: key, Value: value} }
+
+// Float64 returns a floating point field.
+func Float64(key string, value float64) Attr { return Attr{Key: key, Value: value} }
+
+// Bool returns a boolean field.
+func Bool(key string, value bool) Attr { return Attr{Key: key, Value: value} }
+
+// Duration returns a duration field.
+func Duration(key string, value time.Duration) Attr { return Attr{Key: key, Value: value} }
+
+// Time returns a timestamp field.
+func Time(key string, value time.Time) Attr { return Attr{Key: key, Value: value} }
+
+// Any returns a field holding an arbitrary value.
+func Any(key string, value any) Attr { return Attr{Key: key, Value: value} }
+
+// Err returns an "error" field.
+func Err(err error) Attr { return Attr{Key: "error", Value: err} }
+
+// LazyValue is a field value computed only when the record is logged.
+type LazyValue struct {
+	fn func() any
+}
+
+// Lazy defers computing a field value until a record passes the level
+// check, so disabled calls skip the work:
+//
+//	logger.Debug("request_dumped", "body", log.Lazy(func() any { return dump(req) }))
+func Lazy(fn func() any) LazyValue { return LazyValue{fn: fn} }
+
+// Value calls the wrapped function.
+func (v LazyValue) Value() any { return v.fn() }
+
+// resolveLazy replaces lazy values with their results.
+func resolveLazy(attrs []Attr) {
+	for i := range attrs {
+		if lv, ok := attrs[i].Value.(LazyValue); ok {
+			attrs[i].Value = lv.Value()
+		}
+	}
+}
+
+// Record is a single log event as seen by processors and sinks.
+type Record struct {
+	Time   time.Time
+	Level  Level
+	Logger string
+	Event  string
+	Attrs  []Attr
+	// Markers are visual prefixes such as DAS emoji, rendered before the
+	// event by the encoders. They are kept apart from Event so the event
+	// name stays stable for filtering and tests.
+	Markers []string
+}
+
+// Get returns the value of the first field named key.
+func (r *Record) Get(key string) (any, bool) {
+	for _, a := range r.Attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set replaces the value of the field named key, or appends it.
+func (r *Record) Set(key string, value any) {
+	for i := range r.Attrs {
+		if r.Attrs[i].Key == key {
+			r.Attrs[i].Value = value
+			return
+		}
+	}
+	r.Attrs = append(r.Attrs, Attr{Key: key, Value: value})
+}
+
+// Delete removes every field named key.
+func (r *Record) Delete(key string) {
+	r.Attrs = slices.DeleteFunc(r.Attrs, func(a Attr) bool { return a.Key == key })
+}
+
+// Clone returns a copy of r that does not share field storage, for sinks
+// that keep records after Write returns.
+func (r *Record) Clone() *Record {
+	c := *r
+	c.Attrs = slices.Clone(r.Attrs)
+	c.Markers = slices.Clone(r.Markers)
+	return &c
+}
+
+// appendKV converts alternating key/value arguments into fields. Attr
+// values are accepted in place of a pair.
+func appendKV(attrs []Attr, kv []any) []Attr {
+	for len(kv) > 0 {
+		switch k := kv[0].(type) {
+		case Attr:
+			attrs = append(attrs, k)
+			kv = kv[1:]
+		case string:
+			if len(kv) == 1 {
+				attrs = append(attrs, Attr{Key: badKey, Value: k})
+				kv = nil
+				continue
+			}
+			attrs = append(attrs, Attr{Key: k, Value: kv[1]})
+			kv = kv[2:]
+		default:
+			attrs = append(attrs, Attr{Key: badKey, Value: k})
+			kv = kv[1:]
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Replacement values used by Redactor, matching the Python security
+// defaults.
+const (
+	// RedactedValue replaces the value of a sensitive field.
+	RedactedValue = "[REDACTED]"
+	// MaskedValue replaces a secret found inside a string.
+	MaskedValue = "[MASKED]"
+)
+
+// DefaultSensitiveKeys are field names whose values are always redacted:
+// the Python defaults for sensitive HTTP headers and parameters.
+var DefaultSensitiveKeys = []string{
+	"authorization", "x-api-key", "x-auth-token", "cookie", "set-cookie",
+	"x-csrf-token", "x-session-token", "proxy-authorization",
+	"token", "key", "password", "secret", "apikey", "api_key",
+	"access_token", "refresh_token", "auth", "credentials",
+}
+
+// DefaultSecretPatterns find secrets inside free text such as
+// "password=hunter2" or "--token abc". The first group is kept and the
+// rest of the match is masked.
+var DefaultSecretPatterns = compilePatterns(
+	`(password[=:\s]+)([^\s]+)`,
+	`(passwd[=:\s]+)([^\s]+)`,
+	`(pwd[=:\s]+)([^\s]+)`,
+	`(token[=:\s]+)([^\s]+)`,
+	`(api[_-]?key[=:\s]+)([^\s]+)`,
+	`(api[_-]?token[=:\s]+)([^\s]+)`,
+	`(access[_-]?key[=:\s]+)([^\s]+)`,
+	`(secret[_-]?key[=:\s]+)([^\s]+)`,
+	`(secret[=:\s]+)([^\s]+)`,
+	`(auth[=:\s]+)([^\s]+)`,
+	`(credentials?[=:\s]+)([^\s]+)`,
+	`(--password[=\s]+)([^\s]+)`,
+	`(--token[=\s]+)([^\s]+)`,
+	`(--api-key[=\s]+)([^\s]+)`,
+	`(--api-token[=\s]+)([^\s]+)`,
+	`(--secret[=\s]+)([^\s]+)`,
+	`(--auth[=\s]+)([^\s]+)`,
+	`(-p\s+)([^\s]+)`,
+	`([A-Z_]+PASSWORD[=:])([^\s]+)`,
+	`([A-Z_]+TOKEN[=:])([^\s]+)`,
+	`([A-Z_]+KEY[=:])([^\s]+)`,
+	`([A-Z_]+SECRET[=:])([^\s]+)`,
+)
+
+// EmailPattern matches e-mail addresses. It is not applied by default;
+// pass it to WithRedactPatterns to scrub addresses from log output.
+var EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+func compilePatterns(exprs ...string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(exprs))
+	for i, e := range exprs {
+		out[i] = regexp.MustCompile(`(?i)` + e)
+	}
+	return out
+}
+
+// RedactOption configures a Redactor.
+type RedactOption func(*Redactor)
+
+// WithRedactedKeys adds field names whose values are replaced entirely.
+// Names match case-insensitively, at any nesting depth of map values.
+func WithRedactedKeys(keys ...string) RedactOption {
+	return func(r *Redactor) {
+		for _, k := range keys {
+			r.keys[strings.ToLower(k)] = struct{}{}
+		}
+	}
+}
+
+// WithRedactPatterns adds patterns applied to string values and the event.
+// A pattern with at least two groups keeps the first group and masks the
+// rest of the match; otherwise the whole match is masked.
+func WithRedactPatterns(patterns ...*regexp.Regexp) RedactOption {
+	return func(r *Redactor) { r.patterns = append(r.patterns, patterns...) }
+}
+
+// WithoutRedactDefaults drops DefaultSensitiveKeys and
+// DefaultSecretPatterns, leaving only what is added explicitly.
+func WithoutRedactDefaults() RedactOption {
+	return func(r *Redactor) {
+		clear(r.keys)
+		r.patterns = nil
+	}
+}
+
+// Redactor masks sensitive values in records. Use it through
+// WithRedaction, after any processor that adds fields.
+type Redactor struct {
+	keys     map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a redactor using the default keys and patterns plus
+// anything added by opts.
+func NewRedactor(opts ...RedactOption) *Redactor {
+	r := &Redactor{keys: make(map[string]struct{})}
+	WithRedactedKeys(DefaultSensitiveKeys...)(r)
+	r.patterns = append(r.patterns, DefaultSecretPatterns...)
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithRedaction adds r to the logger's processors. Processors run in the
+// order they are added, so add it last.
+func WithRedaction(r *Redactor) Option {
+	return WithProcessor(r.Process)
+}
+
+// Process is a Processor that redacts rec in place. Map values are copied
+// rather than modified, since they belong to the caller.
+func (r *Redactor) Process(_ context.Context, rec *Record) bool {
+	rec.Event = r.RedactString(rec.Event)
+	for i, a := range rec.Attrs {
+		rec.Attrs[i].Value = r.RedactAttr(a.Key, a.Value)
+	}
+	return true
+}
+
+// RedactString masks every secret pattern match in s.
+func (r *Redactor) RedactString(s string) string {
+	for _, p := range r.patterns {
+		if p.NumSubexp() >= 2 {
+			s = p.ReplaceAllString(s, "${1}"+MaskedValue)
+		} else {
+			s = p.ReplaceAllLiteralString(s, MaskedValue)
+		}
+	}
+	return s
+}
+
+// Redact returns v with the values of sensitive keys replaced and secret
+// patterns masked, as Process does for attribute values. Maps, slices of
+// any and multi-value maps such as http.Header are copied; other values
+// are returned unchanged.
+func (r *Redactor) Redact(v any) any {
+	return r.redactValue(v)
+}
+
+// RedactAttr returns the value of the field key as Process writes it:
+// RedactedValue for a sensitive key, and Redact of v otherwise.
+func (r *Redactor) RedactAttr(key string, v any) any {
+	if r.sensitive(key) {
+		return RedactedValue
+	}
+	return r.redactValue(v)
+}
+
+func (r *Redactor) sensitive(key string) bool {
+	if _, ok := r.keys[strings.ToLower(key)]; ok {
+		return true
+	}
+	// Dotted keys from slog groups, e.g. "http.authorization".
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		_, ok := r.keys[strings.ToLower(key[i+1:])]
+		return ok
+	}
+	return false
+}
+
+func (r *Redactor) redactValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.RedactString(v)
+	case error:
+		if msg := v.Error(); r.RedactString(msg) != msg {
+			return r.RedactString(msg)
+		}
+		return v
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			if r.sensitive(k) {
+				out[k] = RedactedValue
+			} else {
+				out[k] = r.redactValue(val)
+			}
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, val := range v {
+			if r.sensitive(k) {
+				out[k] = RedactedValue
+			} else {
+				out[k] = r.RedactString(val)
+			}
+		}
+		return out
+	case map[string][]string:
+		return r.redactMulti(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = r.redactValue(val)
+		}
+		return out
+	}
+	// Named multi-value maps such as http.Header and url.Values.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map && rv.Type().ConvertibleTo(multiMapType) {
+		m := rv.Convert(multiMapType).Interface().(map[string][]string)
+		return reflect.ValueOf(r.redactMulti(m)).Convert(rv.Type()).Interface()
+	}
+	return v
+}
+
+var multiMapType = reflect.TypeFor[map[string][]string]()
+
+func (r *Redactor) redactMulti(m map[string][]string) map[string][]string {
+	out := make(map[string][]string, len(m))
+	for k, vals := range m {
+		if r.sensitive(k) {
+			out[k] = []string{RedactedValue}
+			continue
+		}
+		red := make([]string, len(vals))
+		for i, s := range vals {
+			red[i] = r.RedactString(s)
+		}
+		out[k] = red
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRedactionMasksFieldsAndPatterns(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}),
+		WithRedaction(NewRedactor(WithRedactedKeys("user_name"), WithRedactPatterns(EmailPattern))))
+
+	headers := http.Header{"Authorization": {"Bearer abc"}, "Accept": {"application/json"}}
+	logger.Info("notification_send_started",
+		"user_name", "Ada Lovelace",
+		"recipient", "ada@example.com",
+		"command", "deploy --token s3cr3t --verbose",
+		"password", 1234,
+		"headers", headers,
+		"body", map[string]any{"nested": map[string]any{"api_key": "k"}, "ok": "yes"},
+		"error", errors.New("login failed: password=hunter2"),
+	)
+
+	got := decodeLines(t, &buf)[0]
+	want := map[string]any{
+		"user_name": RedactedValue,
+		"recipient": MaskedValue,
+		"command":   "deploy --token " + MaskedValue + " --verbose",
+		"password":  RedactedValue,
+		"error":     "login failed: password=" + MaskedValue,
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%s = %v, want %v", k, got[k], w)
+		}
+	}
+	if h := got["headers"].(map[string]any); h["Authorization"].([]any)[0] != RedactedValue || h["Accept"].([]any)[0] != "application/json" {
+		t.Errorf("headers = %v", h)
+	}
+	if b := got["body"].(map[string]any); b["nested"].(map[string]any)["api_key"] != RedactedValue || b["ok"] != "yes" {
+		t.Errorf("body = %v", b)
+	}
+	if headers.Get("Authorization") != "Bearer abc" {
+		t.Error("the caller's header map was modified")
+	}
+}
+
+func TestRedactorWithoutDefaults(t *testing.T) {
+	r := NewRedactor(WithoutRedactDefaults(), WithRedactedKeys("ssn"))
+	rec := &Record{Event: "password=x", Attrs: []Attr{String("ssn", "1"), String("token", "t")}}
+	r.Process(t.Context(), rec)
+	if rec.Event != "password=x" || rec.Attrs[0].Value != RedactedValue || rec.Attrs[1].Value != "t" {
+		t.Errorf("record = %+v", rec)
+	}
+}
+
+func TestRedactorRedact(t *testing.T) {
+	r := NewRedactor()
+	got := r.Redact(map[string]any{"user": "ann", "auth": map[string]any{"password": "x"}, "items": []any{"token=abc"}})
+	want := map[string]any{"user": "ann", "auth": RedactedValue, "items": []any{"token=" + MaskedValue}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Redact = %v, want %v", got, want)
+	}
+	if got := r.Redact(http.Header{"Authorization": {"Bearer t"}}).(http.Header); got.Get("Authorization") != RedactedValue {
+		t.Errorf("header = %v", got)
+	}
+}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// LevelConfig is a complete level configuration: a base level and
+// per-module overrides.
+type LevelConfig struct {
+	Level   Level
+	Modules map[string]Level
+}
+
+// SetLevel changes the base level of l and of every logger derived from
+// the same New call. Module overrides keep applying on top.
+func (l *Logger) SetLevel(level Level) {
+	t := l.core.levels
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.configure(level, t.modules)
+	t.refresh()
+}
+
+// SetModuleLevel adds or changes the override of one module.
+func (l *Logger) SetModuleLevel(module string, level Level) {
+	t := l.core.levels
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	modules := maps.Clone(t.modules)
+	if modules == nil {
+		modules = map[string]Level{}
+	}
+	modules[module] = level
+	t.configure(t.fallback, modules)
+	t.refresh()
+}
+
+// ApplyLevels replaces the whole level configuration.
+func (l *Logger) ApplyLevels(cfg LevelConfig) {
+	t := l.core.levels
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.configure(cfg.Level, cfg.Modules)
+	t.refresh()
+}
+
+// LevelConfig returns the current level configuration.
+func (l *Logger) LevelConfig() LevelConfig {
+	t := l.core.levels
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return LevelConfig{Level: t.fallback, Modules: maps.Clone(t.modules)}
+}
+
+// refresh re-resolves the level of every logger name. Callers hold t.mu.
+func (t *levelTable) refresh() {
+	for name, lv := range t.vars {
+		lv.Set(t.resolve(name))
+	}
+}
+
+// LevelSource loads a level configuration, e.g. when a reload is requested.
+type LevelSource func() (LevelConfig, error)
+
+// EnvLevels reads FOUNDATION_LOG_LEVEL and FOUNDATION_MODULE_LEVELS. Unlike
+// WithEnv it reports invalid values, so a bad reload is not half-applied.
+func EnvLevels() LevelSource {
+	return func() (LevelConfig, error) {
+		return parseLevelConfig(os.LookupEnv)
+	}
+}
+
+// FileLevels reads a file of KEY=VALUE lines using the same names as the
+// environment variables:
+//
+//	# /etc/users/log.env
+//	FOUNDATION_LOG_LEVEL=INFO
+//	FOUNDATION_MODULE_LEVELS=repository:DEBUG
+//
+// Blank lines, comments and other keys are ignored.
+func FileLevels(path string) LevelSource {
+	return func() (LevelConfig, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return LevelConfig{}, fmt.Errorf("log: %w", err)
+		}
+		defer f.Close()
+		values := map[string]string{}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			k, v, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+			if !ok {
+				continue
+			}
+			values[strings.TrimSpace(k)] = strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+		if err := sc.Err(); err != nil {
+			return LevelConfig{}, fmt.Errorf("log: read %s: %w", path, err)
+		}
+		return parseLevelConfig(func(k string) (string, bool) {
+			v, ok := values[k]
+			return v, ok
+		})
+	}
+}
+
+func parseLevelConfig(lookup func(string) (string, bool)) (LevelConfig, error) {
+	cfg := LevelConfig{Level: LevelInfo}
+	var errs []error
+	if v, ok := lookup(EnvLogLevel); ok {
+		level, err := ParseLevel(v)
+		cfg.Level = level
+		errs = append(errs, err)
+	}
+	if v, ok := lookup(EnvModuleLevels); ok {
+		modules, err := ParseModuleLevels(v)
+		cfg.Modules = modules
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return LevelConfig{}, err
+	}
+	return cfg, nil
+}
+
+// ReloadOnSignal applies the configuration from src whenever the process
+// receives one of sigs, SIGHUP by default, until ctx is done. Failed
+// reloads are logged and leave the levels unchanged.
+//
+//	go log.ReloadOnSignal(ctx, logger, log.FileLevels("/etc/users/log.env"))
+func ReloadOnSignal(ctx context.Context, l *Logger, src LevelSource, sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	defer signal.Stop(ch)
+	reloadLoop(ctx, l, src, ch)
+}
+
+func reloadLoop(ctx context.Context, l *Logger, src LevelSource, ch <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-ch:
+			cfg, err := src()
+			if err != nil {
+				l.Error("log_level_reload_failed", "signal", sig.String(), "error", err)
+				continue
+			}
+			l.ApplyLevels(cfg)
+			l.Info("log_level_reloaded", "signal", sig.String(), "level", cfg.Level.String(), "modules", len(cfg.Modules))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestSetLevelUpdatesDerivedLoggers(t *testing.T) {
+	root := New(WithLevel(LevelInfo), WithModuleLevels(map[string]Level{"repository": LevelError}))
+	app := root.Named("app")
+	repo := root.Named("repository")
+
+	root.SetLevel(LevelDebug)
+	if app.Level() != LevelDebug || repo.Level() != LevelError {
+		t.Errorf("after SetLevel: app %v, repository %v", app.Level(), repo.Level())
+	}
+	app.SetModuleLevel("repository", LevelTrace)
+	if repo.Level() != LevelTrace || root.Named("repository").Named("users").Level() != LevelTrace {
+		t.Errorf("after SetModuleLevel: repository %v", repo.Level())
+	}
+	if cfg := root.LevelConfig(); cfg.Level != LevelDebug || cfg.Modules["repository"] != LevelTrace {
+		t.Errorf("LevelConfig = %+v", cfg)
+	}
+}
+
+func TestFileLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.env")
+	os.WriteFile(path, []byte("# levels\nexport FOUNDATION_LOG_LEVEL=\"warning\"\nOTHER=1\nFOUNDATION_MODULE_LEVELS=repository:DEBUG\n"), 0o600)
+	cfg, err := FileLevels(path)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != LevelWarning || cfg.Modules["repository"] != LevelDebug {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	os.WriteFile(path, []byte("FOUNDATION_LOG_LEVEL=loud\n"), 0o600)
+	if _, err := FileLevels(path)(); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+}
+
+func TestReloadLoopAppliesSource(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}))
+	repo := logger.Named("repository")
+
+	t.Setenv(EnvLogLevel, "info")
+	t.Setenv(EnvModuleLevels, "repository:debug")
+	ch := make(chan os.Signal)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		reloadLoop(ctx, logger, EnvLevels(), ch)
+		close(done)
+	}()
+
+	ch <- syscall.SIGHUP
+	t.Setenv(EnvLogLevel, "nope")
+	ch <- syscall.SIGHUP
+	cancel()
+	<-done
+
+	if repo.Level() != LevelDebug {
+		t.Errorf("repository level = %v, want debug", repo.Level())
+	}
+	out := buf.String()
+	if !strings.Contains(out, "log_level_reloaded") || !strings.Contains(out, "log_level_reload_failed") {
+		t.Errorf("output = %s", out)
+	}
+}
@@ -0,0 +1,265 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat stamps rotated files; it sorts chronologically.
+const backupTimeFormat = "20060102T150405.000"
+
+// RotateOption configures a RotatingFile.
+type RotateOption func(*RotatingFile)
+
+// WithMaxSize rotates the file before a write would take it past n bytes.
+func WithMaxSize(n int64) RotateOption {
+	return func(f *RotatingFile) { f.maxSize = n }
+}
+
+// WithRotateEvery rotates the file at every multiple of d since the zero
+// time, e.g. at the top of each hour for time.Hour. Daily rotation happens
+// at midnight UTC.
+func WithRotateEvery(d time.Duration) RotateOption {
+	return func(f *RotatingFile) { f.every = d }
+}
+
+// WithMaxBackups keeps at most n rotated files, deleting the oldest.
+// Zero keeps all of them.
+func WithMaxBackups(n int) RotateOption {
+	return func(f *RotatingFile) { f.maxBackups = n }
+}
+
+// WithCompress gzips rotated files in the background.
+func WithCompress() RotateOption {
+	return func(f *RotatingFile) { f.compress = true }
+}
+
+// RotatingFile is an io.WriteCloser over a log file that is rotated by
+// size and time. Rotated files are renamed to "name-<timestamp>.ext" next
+// to the original. Use it as the writer of a WriterSink:
+//
+//	f, err := log.NewRotatingFile("/var/log/app.log", log.WithMaxSize(100<<20), log.WithMaxBackups(7))
+//	logger := log.New(log.WithOutput(f))
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	every      time.Duration
+	maxBackups int
+	compress   bool
+	now        func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	deadline time.Time
+
+	millMu sync.Mutex
+	mills  sync.WaitGroup
+}
+
+// NewRotatingFile opens path for appending, creating it and its directory
+// if needed.
+func NewRotatingFile(path string, opts ...RotateOption) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, now: time.Now}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("log: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("log: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	if f.every > 0 {
+		f.deadline = f.now().Truncate(f.every).Add(f.every)
+	}
+	return nil
+}
+
+// Write implements io.Writer, rotating first when a limit is reached.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	sizeDue := f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize
+	timeDue := f.every > 0 && !f.now().Before(f.deadline)
+	if sizeDue || timeDue {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, renames it to a backup and opens a new
+// one.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("log: %w", err)
+	}
+	f.file = nil
+	backup := f.backupName(f.now())
+	if err := os.Rename(f.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("log: rotate: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.mills.Add(1)
+	go f.mill(backup)
+	return nil
+}
+
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, base := filepath.Split(f.path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	name := filepath.Join(dir, stem+"-"+t.Format(backupTimeFormat)+ext)
+	for i := 1; exists(name) || exists(name+".gz"); i++ {
+		name = filepath.Join(dir, fmt.Sprintf("%s-%s.%d%s", stem, t.Format(backupTimeFormat), i, ext))
+	}
+	return name
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// mill compresses a new backup and prunes old ones. Runs are serialized so
+// pruning sees the result of earlier compressions.
+func (f *RotatingFile) mill(backup string) {
+	defer f.mills.Done()
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+	if f.compress {
+		if err := gzipFile(backup); err != nil {
+			fmt.Fprintf(os.Stderr, "log: compress %s: %v\n", backup, err)
+		}
+	}
+	if f.maxBackups > 0 {
+		backups, err := f.Backups()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "log: list backups: %v\n", err)
+			return
+		}
+		for _, old := range backups[min(f.maxBackups, len(backups)):] {
+			if err := os.Remove(old); err != nil {
+				fmt.Fprintf(os.Stderr, "log: remove backup: %v\n", err)
+			}
+		}
+	}
+}
+
+// Backups returns the rotated files of this log, newest first.
+func (f *RotatingFile) Backups() ([]string, error) {
+	dir, base := filepath.Split(f.path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".gz")
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)]); err != nil {
+			continue
+		}
+		out = append(out, filepath.Join(dir, e.Name()))
+	}
+	slices.SortFunc(out, func(a, b string) int { return strings.Compare(b, a) })
+	return out, nil
+}
+
+func gzipFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(dst.Name())
+		}
+	}()
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Close closes the file and waits for background compression to finish.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+	f.mills.Wait()
+	return err
+}
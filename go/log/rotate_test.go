@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotatesBySizeAndPrunes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := NewRotatingFile(path, WithMaxSize(10), WithMaxBackups(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { clock = clock.Add(time.Second); return clock }
+
+	for _, line := range []string{"aaaaaaa\n", "bbbbbbb\n", "ccccccc\n", "ddddddd\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	current, _ := os.ReadFile(path)
+	if string(current) != "ddddddd\n" {
+		t.Errorf("current file = %q", current)
+	}
+	backups, err := f.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("backups = %v, want 2", backups)
+	}
+	newest, _ := os.ReadFile(backups[0])
+	if string(newest) != "ccccccc\n" {
+		t.Errorf("newest backup = %q", newest)
+	}
+}
+
+func TestRotatingFileRotatesByTimeAndCompresses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	clock := time.Date(2025, 1, 1, 10, 59, 0, 0, time.UTC)
+	f, err := NewRotatingFile(path, WithRotateEvery(time.Hour), WithCompress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.now = func() time.Time { return clock }
+	f.deadline = clock.Truncate(time.Hour).Add(time.Hour)
+
+	logger := New(WithOutput(f))
+	logger.Info("before_the_hour")
+	clock = clock.Add(2 * time.Minute)
+	logger.Info("after_the_hour")
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, _ := f.Backups()
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], "app-20250101T110100.000.log.gz") {
+		t.Fatalf("backups = %v", backups)
+	}
+	gz, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gz.Close()
+	zr, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, _ := io.ReadAll(zr)
+	current, _ := os.ReadFile(path)
+	if !strings.Contains(string(old), "before_the_hour") || !strings.Contains(string(current), "after_the_hour") {
+		t.Errorf("backup %q, current %q", old, current)
+	}
+}
@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// SuppressedKey is the field added to the first record let through after
+// a run of suppressed records with the same event name.
+const SuppressedKey = "suppressed"
+
+// SampleRule limits how often records with one event name are logged.
+// Both limits apply when both are set.
+type SampleRule struct {
+	// Rate is the sustained number of records per second; zero means no
+	// rate limit.
+	Rate float64
+	// Burst is how many records may be logged at once before Rate applies.
+	// It defaults to one second's worth of Rate, at least 1.
+	Burst int
+	// Probability keeps each record with this chance, between 0 and 1.
+	// Zero means every record is kept.
+	Probability float64
+}
+
+// SamplerOption configures a Sampler.
+type SamplerOption func(*Sampler)
+
+// WithEventRule sets the rule for records whose event is exactly event.
+func WithEventRule(event string, rule SampleRule) SamplerOption {
+	return func(s *Sampler) { s.rules[event] = rule }
+}
+
+// WithDefaultRule sets the rule for events without their own rule. By
+// default such events are not sampled.
+func WithDefaultRule(rule SampleRule) SamplerOption {
+	return func(s *Sampler) { s.fallback = &rule }
+}
+
+// WithExemptLevel sets the level from which records are never sampled.
+// The default is LevelError, so failures are always logged.
+func WithExemptLevel(level Level) SamplerOption {
+	return func(s *Sampler) { s.exempt = level }
+}
+
+// Sampler drops repetitive records per event name. Each event has its own
+// token bucket; records dropped by it are counted and reported on the next
+// record of the same event that is logged, as a "suppressed" field.
+type Sampler struct {
+	rules    map[string]SampleRule
+	fallback *SampleRule
+	exempt   Level
+	now      func() time.Time
+	random   func() float64
+
+	mu     sync.Mutex
+	events map[string]*sampleState
+}
+
+type sampleState struct {
+	tokens     float64
+	last       time.Time
+	suppressed uint64
+}
+
+// NewSampler returns a sampler with the given rules.
+func NewSampler(opts ...SamplerOption) *Sampler {
+	s := &Sampler{
+		rules:  make(map[string]SampleRule),
+		exempt: LevelError,
+		now:    time.Now,
+		random: rand.Float64,
+		events: make(map[string]*sampleState),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithSampling adds s to the logger's processors.
+func WithSampling(s *Sampler) Option {
+	return WithProcessor(s.Process)
+}
+
+// Process is a Processor that applies the sampling rules to r.
+func (s *Sampler) Process(_ context.Context, r *Record) bool {
+	if r.Level >= s.exempt {
+		return true
+	}
+	rule, ok := s.rules[r.Event]
+	if !ok {
+		if s.fallback == nil {
+			return true
+		}
+		rule = *s.fallback
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.events[r.Event]
+	if !ok {
+		st = &sampleState{tokens: float64(rule.burst()), last: s.now()}
+		s.events[r.Event] = st
+	}
+	if !s.allow(rule, st) {
+		st.suppressed++
+		return false
+	}
+	if st.suppressed > 0 {
+		r.Set(SuppressedKey, st.suppressed)
+		st.suppressed = 0
+	}
+	return true
+}
+
+func (s *Sampler) allow(rule SampleRule, st *sampleState) bool {
+	if rule.Rate > 0 {
+		now := s.now()
+		st.tokens = min(float64(rule.burst()), st.tokens+now.Sub(st.last).Seconds()*rule.Rate)
+		st.last = now
+		if st.tokens < 1 {
+			return false
+		}
+		st.tokens--
+	}
+	return rule.Probability <= 0 || s.random() < rule.Probability
+}
+
+func (r SampleRule) burst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return max(1, int(r.Rate))
+}
+
+// Summary returns the number of records suppressed per event since they
+// were last reported, and resets the counts. Call it periodically or at
+// shutdown to log what a quiet event dropped.
+func (s *Sampler) Summary() map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]uint64)
+	for event, st := range s.events {
+		if st.suppressed > 0 {
+			out[event] = st.suppressed
+			st.suppressed = 0
+		}
+	}
+	return out
+}
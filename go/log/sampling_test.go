@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSamplerRateLimitsAndReportsSuppressed(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sampler := NewSampler(WithEventRule("db_query_executed", SampleRule{Rate: 1, Burst: 2}))
+	sampler.now = func() time.Time { return now }
+
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}), WithSampling(sampler))
+	for range 10 {
+		logger.Info("db_query_executed")
+		logger.Info("user_fetch_started")
+	}
+	logger.Error("db_query_executed", "error", "timeout")
+
+	lines := decodeLines(t, &buf)
+	var queries, fetches int
+	for _, l := range lines {
+		switch l["event"] {
+		case "db_query_executed":
+			queries++
+		case "user_fetch_started":
+			fetches++
+		}
+	}
+	if queries != 3 || fetches != 10 {
+		t.Fatalf("got %d queries and %d fetches, want 3 (burst plus error) and 10", queries, fetches)
+	}
+
+	now = now.Add(time.Second)
+	buf.Reset()
+	logger.Info("db_query_executed")
+	lines = decodeLines(t, &buf)
+	if len(lines) != 1 || lines[0][SuppressedKey] != float64(8) {
+		t.Fatalf("expected the refilled record to report 8 suppressed, got %v", lines)
+	}
+	if s := sampler.Summary(); len(s) != 0 {
+		t.Errorf("summary after report = %v, want empty", s)
+	}
+}
+
+func TestSamplerProbabilityAndSummary(t *testing.T) {
+	rolls := []float64{0.05, 0.5, 0.9, 0.01, 0.7}
+	sampler := NewSampler(WithDefaultRule(SampleRule{Probability: 0.1}))
+	sampler.random = func() float64 {
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+
+	var kept []*Record
+	for range 5 {
+		r := &Record{Level: LevelDebug, Event: "cache_hit"}
+		if sampler.Process(t.Context(), r) {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) != 2 {
+		t.Fatalf("kept %d records, want 2", len(kept))
+	}
+	if v, _ := kept[1].Get(SuppressedKey); v != uint64(2) {
+		t.Errorf("second kept record reports %v suppressed, want 2", v)
+	}
+	if s := sampler.Summary(); s["cache_hit"] != 1 {
+		t.Errorf("summary = %v, want cache_hit:1", s)
+	}
+}
@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"io"
+	"sync"
+)
+
+// Sink is the final destination of processed records. Implementations
+// must not retain r after Write returns; call r.Clone to keep a copy.
+type Sink interface {
+	Write(r *Record) error
+}
+
+// WriterSink encodes records and writes each as one line to an io.Writer.
+// Records are encoded into pooled buffers outside the lock, so only the
+// write itself is serialized.
+type WriterSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc Encoder
+}
+
+// NewWriterSink returns a sink writing records encoded by enc to w.
+func NewWriterSink(w io.Writer, enc Encoder) *WriterSink {
+	return &WriterSink{w: w, enc: enc}
+}
+
+// Write implements Sink.
+func (s *WriterSink) Write(r *Record) error {
+	buf := getBuffer()
+	*buf = s.enc.Encode(*buf, r)
+	s.mu.Lock()
+	_, err := s.w.Write(*buf)
+	s.mu.Unlock()
+	putBuffer(buf)
+	return err
+}
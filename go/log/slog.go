@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// FromSlogLevel maps a log/slog level onto the foundation levels. Levels
+// between the named slog levels round down.
+func FromSlogLevel(l slog.Level) Level {
+	switch {
+	case l < slog.LevelDebug:
+		return LevelTrace
+	case l < slog.LevelInfo:
+		return LevelDebug
+	case l < slog.LevelWarn:
+		return LevelInfo
+	case l < slog.LevelError:
+		return LevelWarning
+	case l < slog.LevelError+4:
+		return LevelError
+	case l < slog.LevelError+8:
+		return LevelCritical
+	}
+	return LevelFatal
+}
+
+// ToSlogLevel maps a foundation level onto log/slog. TRACE sits below
+// slog.LevelDebug and CRITICAL/FATAL above slog.LevelError.
+func ToSlogLevel(l Level) slog.Level {
+	switch {
+	case l < LevelDebug:
+		return slog.LevelDebug - 4
+	case l < LevelInfo:
+		return slog.LevelDebug
+	case l < LevelWarning:
+		return slog.LevelInfo
+	case l < LevelError:
+		return slog.LevelWarn
+	case l < LevelCritical:
+		return slog.LevelError
+	case l < LevelFatal:
+		return slog.LevelError + 4
+	}
+	return slog.LevelError + 8
+}
+
+// SlogHandler is a slog.Handler that routes slog records through a
+// foundation Logger, so code written against log/slog gets the same
+// processors, enrichment and sinks. The slog message becomes the event.
+type SlogHandler struct {
+	logger *Logger
+	attrs  []Attr
+	group  string
+}
+
+// NewSlogHandler returns a handler backed by l.
+//
+//	slog.SetDefault(slog.New(log.NewSlogHandler(logger)))
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+// Enabled implements slog.Handler.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(FromSlogLevel(level))
+}
+
+// Handle implements slog.Handler.
+func (h *SlogHandler) Handle(ctx context.Context, sr slog.Record) error {
+	r := newRecord()
+	r.Time = sr.Time
+	r.Level = FromSlogLevel(sr.Level)
+	r.Logger = h.logger.name
+	r.Event = sr.Message
+	r.Attrs = append(r.Attrs, h.logger.attrs...)
+	r.Attrs = append(r.Attrs, h.attrs...)
+	sr.Attrs(func(a slog.Attr) bool {
+		r.Attrs = appendSlogAttr(r.Attrs, h.group, a)
+		return true
+	})
+	resolveLazy(r.Attrs)
+	h.logger.core.emit(ctx, r)
+	freeRecord(r)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = slices.Clip(h.attrs)
+	for _, a := range attrs {
+		c.attrs = appendSlogAttr(c.attrs, h.group, a)
+	}
+	return &c
+}
+
+// WithGroup implements slog.Handler. Groups are flattened into dotted
+// field names, e.g. "http.status".
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.group = joinGroup(h.group, name)
+	return &c
+}
+
+func joinGroup(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func appendSlogAttr(attrs []Attr, group string, a slog.Attr) []Attr {
+	v := a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if v.Kind() == slog.KindGroup {
+		prefix := group
+		if a.Key != "" {
+			prefix = joinGroup(group, a.Key)
+		}
+		for _, ga := range v.Group() {
+			attrs = appendSlogAttr(attrs, prefix, ga)
+		}
+		return attrs
+	}
+	return append(attrs, Attr{Key: joinGroup(group, a.Key), Value: v.Any()})
+}
+
+// SlogSink forwards foundation records to an *slog.Logger, for
+// applications that have standardized on an slog backend.
+type SlogSink struct {
+	logger *slog.Logger
+}
+
+// NewSlogSink returns a sink writing to l.
+//
+//	logger := log.New(log.WithSink(log.NewSlogSink(slog.Default())))
+func NewSlogSink(l *slog.Logger) *SlogSink {
+	return &SlogSink{logger: l}
+}
+
+// Write implements Sink.
+func (s *SlogSink) Write(r *Record) error {
+	h := s.logger.Handler()
+	level := ToSlogLevel(r.Level)
+	ctx := context.Background()
+	if !h.Enabled(ctx, level) {
+		return nil
+	}
+	sr := slog.NewRecord(r.Time, level, r.Event, 0)
+	if r.Logger != "" {
+		sr.AddAttrs(slog.String("logger_name", r.Logger))
+	}
+	for _, a := range r.Attrs {
+		sr.AddAttrs(slog.Any(a.Key, a.Value))
+	}
+	return h.Handle(ctx, sr)
+}
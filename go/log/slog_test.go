@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandlerRoutesThroughLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}), WithName("legacy"), WithLevel(LevelInfo))
+	sl := slog.New(NewSlogHandler(logger.With("service", "users")))
+
+	sl.Debug("cache_lookup_started")
+	sl.With("request_id", "r1").WithGroup("http").Info("request_completed",
+		"status", 200, slog.Group("timing", "ms", 12))
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 1 {
+		t.Fatalf("expected debug to be filtered, got %v", lines)
+	}
+	got := lines[0]
+	for key, want := range map[string]any{
+		"event":          "request_completed",
+		"logger_name":    "legacy",
+		"service":        "users",
+		"request_id":     "r1",
+		"http.status":    float64(200),
+		"http.timing.ms": float64(12),
+	} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %v", key, got[key], want)
+		}
+	}
+}
+
+func TestSlogSinkForwardsRecords(t *testing.T) {
+	var buf bytes.Buffer
+	backend := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug - 4}))
+	logger := New(WithSink(NewSlogSink(backend)), WithLevel(LevelTrace), WithName("repository"))
+
+	logger.Trace("query_plan_built", "rows", 3)
+	logger.Critical("pool_exhausted")
+
+	out := buf.String()
+	for _, want := range []string{
+		`level=DEBUG-4 msg=query_plan_built logger_name=repository rows=3`,
+		`level=ERROR+4 msg=pool_exhausted`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSlogLevelMappingRoundTrips(t *testing.T) {
+	for _, l := range []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarning, LevelError, LevelCritical, LevelFatal} {
+		if got := FromSlogLevel(ToSlogLevel(l)); got != l {
+			t.Errorf("round trip of %v gave %v", l, got)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"context"
+
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+// Field names of the trace context, as in the Python logger.
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// addTraceContext tags r with the span context of ctx. Fields the caller
+// set explicitly are kept.
+func addTraceContext(ctx context.Context, r *Record) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	if _, ok := r.Get(TraceIDKey); !ok {
+		r.Attrs = append(r.Attrs, Attr{Key: TraceIDKey, Value: sc.TraceID.String()})
+	}
+	if _, ok := r.Get(SpanIDKey); !ok {
+		r.Attrs = append(r.Attrs, Attr{Key: SpanIDKey, Value: sc.SpanID.String()})
+	}
+	if sc.Flags != 0 {
+		if _, ok := r.Get(TraceFlagsKey); !ok {
+			r.Attrs = append(r.Attrs, Attr{Key: TraceFlagsKey, Value: int(sc.Flags)})
+		}
+	}
+}
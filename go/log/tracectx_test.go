@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+func TestCtxMethodsInjectTraceContext(t *testing.T) {
+	sc, err := trace.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	var buf bytes.Buffer
+	logger := New(WithOutput(&buf), WithFormat(JSONEncoder{}))
+	logger.InfoCtx(ctx, "user_fetch_started")
+	logger.WarnCtx(ctx, "user_fetch_retried", TraceIDKey, "explicit")
+	logger.Info("no_context")
+	slog.New(NewSlogHandler(logger)).InfoContext(ctx, "via_slog")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	for _, i := range []int{0, 3} {
+		if lines[i][TraceIDKey] != sc.TraceID.String() || lines[i][SpanIDKey] != sc.SpanID.String() || lines[i][TraceFlagsKey] != float64(1) {
+			t.Errorf("line %d = %v", i, lines[i])
+		}
+	}
+	if lines[1][TraceIDKey] != "explicit" {
+		t.Errorf("explicit trace_id overwritten: %v", lines[1])
+	}
+	if _, ok := lines[2][TraceIDKey]; ok {
+		t.Errorf("trace_id without a traced context: %v", lines[2])
+	}
+}
@@ -0,0 +1,291 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metrics records counters, gauges and histograms identified by a
+// name and a fixed set of label names. Instruments live in a Registry,
+// which exporters read through Collect:
+//
+//	requests := metrics.NewCounter("user_fetch_total", "Users fetched.", "result")
+//	requests.Inc("hit")
+//
+// Label values are passed positionally on every call, in the order the
+// label names were declared. Handler serves a registry to Prometheus, and
+// the otlpmetric subpackage pushes it to an OpenTelemetry collector, so
+// code that records metrics does not depend on either.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Kind is the type of an instrument.
+type Kind int
+
+// Instrument kinds.
+const (
+	KindCounter Kind = iota
+	KindGauge
+	KindHistogram
+)
+
+// String returns the Prometheus type name.
+func (k Kind) String() string {
+	switch k {
+	case KindCounter:
+		return "counter"
+	case KindGauge:
+		return "gauge"
+	case KindHistogram:
+		return "histogram"
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+// DefaultBuckets are histogram bucket upper bounds suited to latencies in
+// seconds, the Prometheus client defaults.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry holds instruments by name. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Default is the registry used by the package-level constructors.
+var Default = NewRegistry()
+
+// register returns the family of name, creating it if needed. Asking for
+// an existing name with a different kind, label set or buckets panics, as
+// both callers would otherwise silently record into the wrong series.
+func (r *Registry) register(kind Kind, name, help string, buckets []float64, labels []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.families[name]; ok {
+		if f.kind != kind || !slices.Equal(f.labels, labels) || !slices.Equal(f.buckets, buckets) {
+			panic(fmt.Sprintf("metrics: %s already registered as a %s with labels %v", name, f.kind, f.labels))
+		}
+		return f
+	}
+	f := &family{kind: kind, name: name, help: help, buckets: buckets, labels: slices.Clone(labels), series: make(map[string]*series)}
+	r.families[name] = f
+	return f
+}
+
+// Counter registers a counter, or returns the one already registered
+// under name.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{r.register(KindCounter, name, help, nil, labels)}
+}
+
+// Gauge registers a gauge, or returns the one already registered under
+// name.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.register(KindGauge, name, help, nil, labels)}
+}
+
+// Histogram registers a histogram with the given bucket upper bounds, or
+// DefaultBuckets when nil, or returns the one already registered under
+// name.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	sort.Float64s(buckets)
+	return &Histogram{r.register(KindHistogram, name, help, buckets, labels)}
+}
+
+// NewCounter registers a counter in Default.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.Counter(name, help, labels...)
+}
+
+// NewGauge registers a gauge in Default.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.Gauge(name, help, labels...)
+}
+
+// NewHistogram registers a histogram in Default.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.Histogram(name, help, buckets, labels...)
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct{ f *family }
+
+// Inc adds 1.
+func (c *Counter) Inc(labelValues ...string) { c.Add(1, labelValues...) }
+
+// Add adds v, which must not be negative.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("metrics: counter " + c.f.name + " decreased")
+	}
+	c.f.get(labelValues).value.add(v)
+}
+
+// Gauge is a value that goes up and down.
+type Gauge struct{ f *family }
+
+// Set sets the value.
+func (g *Gauge) Set(v float64, labelValues ...string) { g.f.get(labelValues).value.store(v) }
+
+// Add adds v, which may be negative.
+func (g *Gauge) Add(v float64, labelValues ...string) { g.f.get(labelValues).value.add(v) }
+
+// Inc adds 1.
+func (g *Gauge) Inc(labelValues ...string) { g.Add(1, labelValues...) }
+
+// Dec subtracts 1.
+func (g *Gauge) Dec(labelValues ...string) { g.Add(-1, labelValues...) }
+
+// Histogram counts observations in buckets.
+type Histogram struct{ f *family }
+
+// Observe records v.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	s := h.f.get(labelValues)
+	i := sort.SearchFloat64s(h.f.buckets, v)
+	s.mu.Lock()
+	if i < len(s.buckets) {
+		s.buckets[i]++
+	}
+	s.count++
+	s.sum += v
+	s.mu.Unlock()
+}
+
+type family struct {
+	kind    Kind
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.RWMutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       atomicFloat
+
+	mu      sync.Mutex // histograms only
+	buckets []uint64   // per bucket, not cumulative
+	count   uint64
+	sum     float64
+}
+
+// get returns the series for labelValues, creating it on first use.
+func (f *family) get(labelValues []string) *series {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s takes labels %v, got %d values", f.name, f.labels, len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	f.mu.RLock()
+	s, ok := f.series[key]
+	f.mu.RUnlock()
+	if ok {
+		return s
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.series[key]; ok {
+		return s
+	}
+	s = &series{labelValues: slices.Clone(labelValues)}
+	if f.kind == KindHistogram {
+		s.buckets = make([]uint64, len(f.buckets))
+	}
+	f.series[key] = s
+	return s
+}
+
+type atomicFloat struct{ bits atomic.Uint64 }
+
+func (a *atomicFloat) load() float64   { return math.Float64frombits(a.bits.Load()) }
+func (a *atomicFloat) store(v float64) { a.bits.Store(math.Float64bits(v)) }
+
+func (a *atomicFloat) add(v float64) {
+	for {
+		old := a.bits.Load()
+		if a.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// Family is a snapshot of one instrument, as read by exporters.
+type Family struct {
+	Name   string
+	Help   string
+	Kind   Kind
+	Labels []string
+	// Buckets are the histogram bucket upper bounds, without +Inf.
+	Buckets []float64
+	Series  []Series
+}
+
+// Series is a snapshot of one label set of an instrument.
+type Series struct {
+	LabelValues []string
+	// Value is the counter or gauge value.
+	Value float64
+	// Histogram data: cumulative counts per bucket of Family.Buckets, and
+	// the count and sum of all observations.
+	BucketCounts []uint64
+	Count        uint64
+	Sum          float64
+}
+
+// Collect returns a snapshot of every instrument, sorted by name, with
+// series sorted by label values.
+func (r *Registry) Collect() []Family {
+	r.mu.RLock()
+	fams := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		fams = append(fams, f)
+	}
+	r.mu.RUnlock()
+	slices.SortFunc(fams, func(a, b *family) int { return strings.Compare(a.name, b.name) })
+
+	out := make([]Family, len(fams))
+	for i, f := range fams {
+		out[i] = f.collect()
+	}
+	return out
+}
+
+func (f *family) collect() Family {
+	fam := Family{Name: f.name, Help: f.help, Kind: f.kind, Labels: f.labels, Buckets: f.buckets}
+	f.mu.RLock()
+	for _, s := range f.series {
+		snap := Series{LabelValues: s.labelValues, Value: s.value.load()}
+		if f.kind == KindHistogram {
+			s.mu.Lock()
+			snap.BucketCounts = make([]uint64, len(s.buckets))
+			var cum uint64
+			for j, n := range s.buckets {
+				cum += n
+				snap.BucketCounts[j] = cum
+			}
+			snap.Count, snap.Sum = s.count, s.sum
+			s.mu.Unlock()
+		}
+		fam.Series = append(fam.Series, snap)
+	}
+	f.mu.RUnlock()
+	slices.SortFunc(fam.Series, func(a, b Series) int { return slices.Compare(a.LabelValues, b.LabelValues) })
+	return fam
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestInstruments(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("user_fetch_total", "Users fetched.", "result")
+	g := r.Gauge("queue_depth", "Jobs waiting.")
+	h := r.Histogram("fetch_seconds", "Fetch latency.", []float64{1, 0.1})
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Inc("hit")
+		}()
+	}
+	wg.Wait()
+	c.Add(2, "miss")
+	g.Set(5)
+	g.Dec()
+	for _, v := range []float64{0.05, 0.5, 0.1, 3} {
+		h.Observe(v)
+	}
+
+	fams := r.Collect()
+	if len(fams) != 3 || fams[0].Name != "fetch_seconds" || fams[1].Name != "queue_depth" || fams[2].Name != "user_fetch_total" {
+		t.Fatalf("families = %+v", fams)
+	}
+	if s := fams[2].Series; len(s) != 2 || s[0].Value != 100 || s[1].Value != 2 || s[1].LabelValues[0] != "miss" {
+		t.Errorf("counter = %+v", s)
+	}
+	if v := fams[1].Series[0].Value; v != 4 {
+		t.Errorf("gauge = %v", v)
+	}
+	hist := fams[0]
+	if !reflect.DeepEqual(hist.Buckets, []float64{0.1, 1}) {
+		t.Errorf("buckets = %v", hist.Buckets)
+	}
+	s := hist.Series[0]
+	if !reflect.DeepEqual(s.BucketCounts, []uint64{2, 3}) || s.Count != 4 || s.Sum != 3.65 {
+		t.Errorf("histogram = %+v", s)
+	}
+}
+
+func TestRegisterConflicts(t *testing.T) {
+	r := NewRegistry()
+	a := r.Counter("requests_total", "", "host")
+	a.Inc("x")
+	r.Counter("requests_total", "", "host").Inc("x")
+	if v := r.Collect()[0].Series[0].Value; v != 2 {
+		t.Errorf("re-registered counter does not share series: %v", v)
+	}
+
+	for name, fn := range map[string]func(){
+		"kind":     func() { r.Gauge("requests_total", "", "host") },
+		"labels":   func() { r.Counter("requests_total", "", "method") },
+		"arity":    func() { a.Inc() },
+		"negative": func() { a.Add(-1, "x") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: no panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
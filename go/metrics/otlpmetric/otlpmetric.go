@@ -0,0 +1,269 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package otlpmetric pushes a metrics registry to an OpenTelemetry
+// collector over OTLP/gRPC or OTLP/HTTP. The exporter collects the
+// registry at a fixed interval in the background:
+//
+//	exp, err := otlpmetric.New()
+//	defer exp.Close(ctx)
+//
+// Counters are exported as cumulative monotonic sums, gauges as gauges and
+// histograms as cumulative explicit-bucket histograms. Configuration comes
+// from the standard OTEL_EXPORTER_OTLP_*, OTEL_METRIC_EXPORT_INTERVAL and
+// OTEL_RESOURCE_ATTRIBUTES variables unless overridden with options.
+package otlpmetric
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/otlp"
+	"github.com/provide-io/provide-foundation/go/telemetry"
+)
+
+// DefaultExportInterval follows the OpenTelemetry periodic reader.
+const DefaultExportInterval = time.Minute
+
+// scopeName is the instrumentation scope of exported metrics.
+const scopeName = "github.com/provide-io/provide-foundation/go/metrics"
+
+// OTLP aggregation temporality.
+const temporalityCumulative = 2
+
+// Option configures an Exporter.
+type Option func(*Exporter)
+
+// WithConfig replaces the configuration read from the environment.
+func WithConfig(cfg otlp.Config) Option {
+	return func(e *Exporter) { e.cfg = &cfg }
+}
+
+// WithResource adds resource attributes such as service.version.
+// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES still take precedence.
+func WithResource(attrs ...otlp.KeyValue) Option {
+	return func(e *Exporter) { e.extraResource = append(e.extraResource, attrs...) }
+}
+
+// WithRegistry exports reg instead of metrics.Default.
+func WithRegistry(reg *metrics.Registry) Option {
+	return func(e *Exporter) { e.registry = reg }
+}
+
+// WithExportInterval sets how often the registry is exported, replacing
+// OTEL_METRIC_EXPORT_INTERVAL.
+func WithExportInterval(d time.Duration) Option {
+	return func(e *Exporter) {
+		if d > 0 {
+			e.interval = d
+		}
+	}
+}
+
+// Exporter periodically exports a registry over OTLP.
+type Exporter struct {
+	cfg           *otlp.Config
+	extraResource []otlp.KeyValue
+	registry      *metrics.Registry
+	interval      time.Duration
+
+	client   *otlp.Client
+	resource []otlp.KeyValue
+	start    time.Time
+
+	exportMu  sync.Mutex
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// New returns an exporter and starts its background goroutine.
+func New(opts ...Option) (*Exporter, error) {
+	e := &Exporter{
+		registry: metrics.Default,
+		start:    time.Now(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.interval == 0 {
+		d, err := intervalFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		e.interval = d
+	}
+	if e.cfg == nil {
+		cfg, err := otlp.ConfigFromEnv(otlp.SignalMetrics)
+		if err != nil {
+			return nil, err
+		}
+		e.cfg = &cfg
+	}
+	client, err := otlp.NewClient(*e.cfg, otlp.SignalMetrics)
+	if err != nil {
+		return nil, err
+	}
+	e.client = client
+	e.resource = otlp.ResourceFromEnv(e.extraResource...)
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	go e.run()
+	return e, nil
+}
+
+// intervalFromEnv reads OTEL_METRIC_EXPORT_INTERVAL, in milliseconds.
+func intervalFromEnv() (time.Duration, error) {
+	v, ok := os.LookupEnv("OTEL_METRIC_EXPORT_INTERVAL")
+	if !ok || strings.TrimSpace(v) == "" {
+		return DefaultExportInterval, nil
+	}
+	ms, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || ms <= 0 {
+		return 0, fmt.Errorf("otlpmetric: invalid OTEL_METRIC_EXPORT_INTERVAL %q, want milliseconds", v)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Flush collects the registry and exports it now. A registry without
+// instruments is not exported, nor is any while telemetry settings
+// disable metrics.
+func (e *Exporter) Flush(ctx context.Context) error {
+	if !telemetry.Enabled(telemetry.Metrics) {
+		return nil
+	}
+	e.exportMu.Lock()
+	defer e.exportMu.Unlock()
+	fams := e.registry.Collect()
+	if len(fams) == 0 {
+		return nil
+	}
+	return e.client.Export(ctx, e.encode(fams, time.Now()))
+}
+
+// Close stops the background goroutine and makes a final export.
+func (e *Exporter) Close(ctx context.Context) error {
+	var err error
+	e.closeOnce.Do(func() {
+		close(e.stop)
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			e.cancel()
+			<-e.done
+		}
+		defer e.client.Close()
+		defer e.cancel()
+		err = e.Flush(ctx)
+	})
+	return err
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+		}
+		if err := e.Flush(e.ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "otlpmetric: %v\n", err)
+		}
+	}
+}
+
+// encode builds an ExportMetricsServiceRequest, its labels cleaned by
+// the telemetry scrubber.
+func (e *Exporter) encode(fams []metrics.Family, now time.Time) []byte {
+	start, ts := uint64(e.start.UnixNano()), uint64(now.UnixNano())
+	var enc otlp.Encoder
+	if s := telemetry.Current().Scrubber; s != nil {
+		enc.Scrub = s.ScrubLabel
+	}
+	enc.Message(1, func(enc *otlp.Encoder) { // ResourceMetrics
+		enc.Resource(1, e.resource)
+		enc.Message(2, func(enc *otlp.Encoder) { // ScopeMetrics
+			enc.Scope(1, scopeName, "")
+			for _, f := range fams {
+				enc.Message(2, func(enc *otlp.Encoder) { encodeMetric(enc, f, start, ts) })
+			}
+		})
+	})
+	return enc.Bytes()
+}
+
+// encodeMetric writes the fields of an opentelemetry.proto.metrics.v1.Metric.
+func encodeMetric(enc *otlp.Encoder, f metrics.Family, start, ts uint64) {
+	enc.String(1, f.Name)
+	if f.Help != "" {
+		enc.String(2, f.Help)
+	}
+	switch f.Kind {
+	case metrics.KindCounter:
+		enc.Message(7, func(enc *otlp.Encoder) { // Sum
+			for _, s := range f.Series {
+				enc.Message(1, func(enc *otlp.Encoder) { encodeNumber(enc, f.Labels, s, start, ts) })
+			}
+			enc.Uint64(2, temporalityCumulative)
+			enc.Bool(3, true)
+		})
+	case metrics.KindGauge:
+		enc.Message(5, func(enc *otlp.Encoder) { // Gauge
+			for _, s := range f.Series {
+				enc.Message(1, func(enc *otlp.Encoder) { encodeNumber(enc, f.Labels, s, 0, ts) })
+			}
+		})
+	case metrics.KindHistogram:
+		enc.Message(9, func(enc *otlp.Encoder) { // Histogram
+			for _, s := range f.Series {
+				enc.Message(1, func(enc *otlp.Encoder) { encodeHistogram(enc, f, s, start, ts) })
+			}
+			enc.Uint64(2, temporalityCumulative)
+		})
+	}
+}
+
+// encodeNumber writes a NumberDataPoint. Gauges pass a zero start.
+func encodeNumber(enc *otlp.Encoder, labels []string, s metrics.Series, start, ts uint64) {
+	if start != 0 {
+		enc.Fixed64(2, start)
+	}
+	enc.Fixed64(3, ts)
+	enc.Double(4, s.Value)
+	for i, l := range labels {
+		enc.KeyValue(7, l, s.LabelValues[i])
+	}
+}
+
+// encodeHistogram writes a HistogramDataPoint. OTLP bucket counts are per
+// bucket, with one more bucket than bounds for values above the last.
+func encodeHistogram(enc *otlp.Encoder, f metrics.Family, s metrics.Series, start, ts uint64) {
+	counts := make([]uint64, len(s.BucketCounts)+1)
+	var prev uint64
+	for i, cum := range s.BucketCounts {
+		counts[i] = cum - prev
+		prev = cum
+	}
+	counts[len(counts)-1] = s.Count - prev
+	enc.Fixed64(2, start)
+	enc.Fixed64(3, ts)
+	enc.Fixed64(4, s.Count)
+	enc.Double(5, s.Sum)
+	enc.PackedFixed64(6, counts)
+	enc.PackedDouble(7, f.Buckets)
+	for i, l := range f.Labels {
+		enc.KeyValue(9, l, s.LabelValues[i])
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlpmetric
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/otlp"
+)
+
+type collector struct {
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.mu.Lock()
+	c.bodies = append(c.bodies, body)
+	c.mu.Unlock()
+}
+
+func (c *collector) requests() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bodies
+}
+
+func TestExporterPushesRegistry(t *testing.T) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+	t.Setenv("OTEL_SERVICE_NAME", "users")
+
+	reg := metrics.NewRegistry()
+	exp, err := New(
+		WithConfig(otlp.Config{Endpoint: otlp.SignalURL(srv.URL, otlp.SignalMetrics), Timeout: time.Second}),
+		WithRegistry(reg),
+		WithExportInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.Flush(context.Background()); err != nil || len(col.requests()) != 0 {
+		t.Errorf("empty registry exported: %v", err)
+	}
+	reg.Counter("user_fetch_total", "Users fetched.", "result").Inc("hit")
+	reg.Gauge("queue_depth", "").Set(2)
+	reg.Histogram("fetch_seconds", "", []float64{1}).Observe(0.5)
+
+	if err := exp.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.Close(context.Background()); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	reqs := col.requests()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests, want the final export", len(reqs))
+	}
+	for _, want := range []string{"users", "user_fetch_total", "Users fetched.", "result", "hit", "queue_depth", "fetch_seconds"} {
+		if !bytes.Contains(reqs[0], []byte(want)) {
+			t.Errorf("export is missing %q", want)
+		}
+	}
+}
+
+func TestEncodeHistogramBuckets(t *testing.T) {
+	reg := metrics.NewRegistry()
+	h := reg.Histogram("fetch_seconds", "", []float64{0.1, 1})
+	for _, v := range []float64{0.05, 0.5, 0.7, 3} {
+		h.Observe(v)
+	}
+	body := (&Exporter{start: time.Unix(0, 0)}).encode(reg.Collect(), time.Unix(1, 0))
+	// Per-bucket counts 1, 2 and 1 for the overflow bucket.
+	want, _ := hex.DecodeString("3218" + "0100000000000000" + "0200000000000000" + "0100000000000000")
+	if !bytes.Contains(body, want) {
+		t.Errorf("bucket counts missing from %x", body)
+	}
+}
+
+func TestIntervalFromEnv(t *testing.T) {
+	t.Setenv("OTEL_METRIC_EXPORT_INTERVAL", "1500")
+	if d, err := intervalFromEnv(); d != 1500*time.Millisecond || err != nil {
+		t.Errorf("interval = %v, %v", d, err)
+	}
+	t.Setenv("OTEL_METRIC_EXPORT_INTERVAL", "soon")
+	if _, err := intervalFromEnv(); err == nil {
+		t.Error("invalid interval accepted")
+	}
+}
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of the Prometheus text format written by
+// WriteText.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler serves the instruments of reg, or Default when nil, in the
+// Prometheus text format, for mounting at /metrics:
+//
+//	mux.Handle("GET /metrics", metrics.Handler(nil))
+func Handler(reg *Registry) http.Handler {
+	if reg == nil {
+		reg = Default
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		WriteText(w, reg.Collect())
+	})
+}
+
+// WriteText writes fams in the Prometheus text exposition format.
+// Histograms get the usual _bucket series, with a final le="+Inf"
+// bucket, and _sum and _count.
+func WriteText(w io.Writer, fams []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range fams {
+		if f.Help != "" {
+			bw.WriteString("# HELP " + f.Name + " " + helpEscaper.Replace(f.Help) + "\n")
+		}
+		bw.WriteString("# TYPE " + f.Name + " " + f.Kind.String() + "\n")
+		for _, s := range f.Series {
+			if f.Kind != KindHistogram {
+				writeSample(bw, f.Name, f.Labels, s.LabelValues, "", "", s.Value)
+				continue
+			}
+			for i, bound := range f.Buckets {
+				writeSample(bw, f.Name+"_bucket", f.Labels, s.LabelValues, "le", formatFloat(bound), float64(s.BucketCounts[i]))
+			}
+			writeSample(bw, f.Name+"_bucket", f.Labels, s.LabelValues, "le", "+Inf", float64(s.Count))
+			writeSample(bw, f.Name+"_sum", f.Labels, s.LabelValues, "", "", s.Sum)
+			writeSample(bw, f.Name+"_count", f.Labels, s.LabelValues, "", "", float64(s.Count))
+		}
+	}
+	return bw.Flush()
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// writeSample writes one sample line, with an extra label when extra is
+// not empty.
+func writeSample(w *bufio.Writer, name string, labels, values []string, extra, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extra != "" {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(l + `="` + labelEscaper.Replace(values[i]) + `"`)
+		}
+		if extra != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extra + `="` + extraValue + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
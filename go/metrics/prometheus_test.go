@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("user_fetch_total", "Users fetched.\nBy result.", "result").Inc(`hit "warm"`)
+	r.Gauge("queue_depth", "").Set(3)
+	h := r.Histogram("fetch_seconds", "Fetch latency.", []float64{0.1, 1}, "method")
+	h.Observe(0.05, "GET")
+	h.Observe(2, "GET")
+
+	w := httptest.NewRecorder()
+	Handler(r).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	want := `# HELP fetch_seconds Fetch latency.
+# TYPE fetch_seconds histogram
+fetch_seconds_bucket{method="GET",le="0.1"} 1
+fetch_seconds_bucket{method="GET",le="1"} 1
+fetch_seconds_bucket{method="GET",le="+Inf"} 2
+fetch_seconds_sum{method="GET"} 2.05
+fetch_seconds_count{method="GET"} 2
+# TYPE queue_depth gauge
+queue_depth 3
+# HELP user_fetch_total Users fetched.\nBy result.
+# TYPE user_fetch_total counter
+user_fetch_total{result="hit \"warm\""} 1
+`
+	if got := w.Body.String(); got != want {
+		t.Errorf("body:\n%s\nwant:\n%s", got, want)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
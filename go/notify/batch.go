@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/batch"
+	"github.com/provide-io/provide-foundation/go/clock"
+)
+
+// DefaultMaxBatch bounds a batch when BatchConfig.MaxSize is zero.
+const DefaultMaxBatch = 100
+
+// BatchConfig turns on batching: messages of the same event sent within
+// Window of the first are delivered together as one message, and a batch
+// is delivered early once it holds MaxSize messages. A zero Window sends
+// each message at once.
+type BatchConfig struct {
+	Window  time.Duration `config:"window" env:"FOUNDATION_NOTIFY_BATCH_WINDOW" desc:"How long messages of one event are collected before delivery; 0 disables batching."`
+	MaxSize int           `config:"max_size" env:"FOUNDATION_NOTIFY_BATCH_MAX_SIZE" default:"100" validate:"min=0" desc:"Messages that flush a batch before its window ends."`
+}
+
+// WithBatching sets the batching of New; FromConfig takes it from
+// Config.Batch.
+func WithBatching(cfg BatchConfig) Option {
+	return func(n *Notifier) { n.batch = cfg }
+}
+
+// WithClock sets the clock batch windows are measured by. The default is
+// clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(n *Notifier) { n.clock = c }
+}
+
+// queued is a message waiting in the batch of its event.
+type queued struct {
+	ctx context.Context // of Send, without its cancellation
+	m   Message
+}
+
+// enqueue adds m to the batch of its event, whose processor delivers it
+// when the window of its first message ends or it holds MaxSize messages.
+func (n *Notifier) enqueue(ctx context.Context, m Message) error {
+	n.mu.Lock()
+	p := n.pending[m.Event]
+	if p == nil {
+		maxSize := n.batch.MaxSize
+		if maxSize <= 0 {
+			maxSize = DefaultMaxBatch
+		}
+		// Failed deliveries are logged by deliver.
+		p = batch.New(n.flush, batch.WithMaxSize(maxSize), batch.WithMaxWait(n.batch.Window),
+			batch.WithClock(n.clock), batch.WithErrorHandler(func(error) {}))
+		n.pending[m.Event] = p
+	}
+	n.mu.Unlock()
+	if err := p.Add(ctx, queued{ctx: context.WithoutCancel(ctx), m: m}); err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+	return nil
+}
+
+// flush delivers a batch as one message, dropping the messages whose Key
+// an earlier one of the batch has.
+func (n *Notifier) flush(_ context.Context, items []queued) error {
+	msgs := make([]Message, 0, len(items))
+	keys := map[string]bool{}
+	for _, q := range items {
+		if q.m.Key != "" {
+			if keys[q.m.Key] {
+				continue
+			}
+			keys[q.m.Key] = true
+		}
+		msgs = append(msgs, q.m)
+	}
+	return n.deliverAll(items[0].ctx, merge(msgs))
+}
+
+// Flush delivers every pending batch now and waits for deliveries in
+// progress. It returns ctx.Err() if ctx ends first.
+func (n *Notifier) Flush(ctx context.Context) error {
+	n.mu.Lock()
+	pending := slices.Collect(maps.Values(n.pending))
+	n.mu.Unlock()
+	for _, p := range pending {
+		p.Flush(ctx)
+	}
+	return ctx.Err()
+}
+
+// OnStop delivers pending batches and stops their processors, so a
+// notifier registered with the container delivers what it holds before
+// the process exits.
+func (n *Notifier) OnStop(ctx context.Context) error {
+	n.mu.Lock()
+	pending := n.pending
+	n.pending = map[string]*batch.Processor[queued]{}
+	n.mu.Unlock()
+	for _, p := range pending {
+		p.Close(ctx)
+	}
+	return ctx.Err()
+}
+
+// merge returns the single message of msgs, or one message standing for
+// them all: its Batch holds them, its Data the count, and its text lists
+// their titles and bodies.
+func merge(msgs []Message) Message {
+	if len(msgs) == 1 {
+		return msgs[0]
+	}
+	first := msgs[0]
+	var text strings.Builder
+	for i, m := range msgs {
+		if i > 0 {
+			text.WriteString("\n")
+		}
+		text.WriteString("- " + m.Title())
+		if body := strings.TrimSpace(m.Body()); body != "" {
+			text.WriteString(": " + strings.ReplaceAll(body, "\n", "; "))
+		}
+	}
+	return Message{
+		Event:   first.Event,
+		Subject: fmt.Sprintf("%d %s notifications", len(msgs), first.Event),
+		Text:    text.String(),
+		Data:    map[string]any{"count": len(msgs)},
+		Time:    first.Time,
+		Batch:   msgs,
+	}
+}
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+)
+
+func userCreated(id int) Message {
+	return Message{Event: "user.created", Key: fmt.Sprint(id), Data: map[string]any{"user_id": id}}
+}
+
+func TestBatchingWindow(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	rec := &recorder{name: "rec"}
+	n := New([]Transport{rec}, WithBatching(BatchConfig{Window: time.Minute}), WithClock(clk))
+	ctx := context.Background()
+	for _, m := range []Message{userCreated(1), userCreated(2), userCreated(1), {Event: "user.deleted"}} {
+		if err := n.Send(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clk.BlockUntil(2)
+	if got := rec.messages(); len(got) != 0 {
+		t.Fatalf("delivered before the window ended: %v", got)
+	}
+	clk.Advance(time.Minute)
+	n.Flush(ctx)
+
+	got := rec.messages()
+	if len(got) != 2 {
+		t.Fatalf("delivered %d messages, want 2", len(got))
+	}
+	var merged Message
+	for _, m := range got {
+		if m.Event == "user.created" {
+			merged = m
+		}
+	}
+	if len(merged.Batch) != 2 || merged.Data["count"] != 2 || merged.Subject != "2 user.created notifications" {
+		t.Errorf("merged = %+v", merged)
+	}
+	if merged.Text != "- user.created: user_id: 1\n- user.created: user_id: 2" {
+		t.Errorf("merged text = %q", merged.Text)
+	}
+}
+
+func TestBatchingMaxSize(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	rec := &recorder{name: "rec"}
+	n := New([]Transport{rec}, WithBatching(BatchConfig{Window: time.Hour, MaxSize: 3}), WithClock(clk))
+	ctx := context.Background()
+	for id := range 7 {
+		n.Send(ctx, userCreated(id))
+	}
+	clk.BlockUntil(1) // the seventh message waits in a new batch
+	got := rec.messages()
+	for len(got) < 2 {
+		time.Sleep(time.Millisecond)
+		got = rec.messages()
+	}
+	if len(got[0].Batch) != 3 || len(got[1].Batch) != 3 {
+		t.Errorf("batches = %d, %d messages", len(got[0].Batch), len(got[1].Batch))
+	}
+
+	// Stopping delivers the rest without waiting for the window.
+	if err := n.OnStop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got = rec.messages()
+	if len(got) != 3 || got[2].Batch != nil || got[2].Data["user_id"] != 6 {
+		t.Errorf("after OnStop: %+v", got)
+	}
+}
+
+func TestFromConfigBatching(t *testing.T) {
+	rec := &recorder{name: "rec"}
+	n, err := FromConfig(Config{Batch: BatchConfig{Window: time.Hour}}, WithTransport(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Send(context.Background(), userCreated(1))
+	if len(rec.messages()) != 0 {
+		t.Error("message not batched")
+	}
+	n.Flush(context.Background())
+	if len(rec.messages()) != 1 {
+		t.Error("Flush did not deliver")
+	}
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EmailConfig configures the email transport.
+type EmailConfig struct {
+	Addr     string   `config:"addr" env:"FOUNDATION_NOTIFY_SMTP_ADDR" desc:"SMTP server as host:port; port 465 uses implicit TLS, others STARTTLS when offered."`
+	Username string   `config:"username" env:"FOUNDATION_NOTIFY_SMTP_USERNAME" desc:"SMTP user; no authentication when empty."`
+	Password string   `config:"password" env:"FOUNDATION_NOTIFY_SMTP_PASSWORD" desc:"SMTP password, e.g. a secret:// reference."`
+	From     string   `config:"from" env:"FOUNDATION_NOTIFY_EMAIL_FROM" desc:"Sender address."`
+	To       []string `config:"to" env:"FOUNDATION_NOTIFY_EMAIL_TO" desc:"Recipient addresses."`
+}
+
+// Email sends each message as a plain-text email over SMTP, with the
+// title as subject.
+type Email struct {
+	cfg  EmailConfig
+	host string
+	from *mail.Address
+	to   []*mail.Address
+	tls  *tls.Config
+}
+
+// NewEmail returns an email transport for cfg. It fails if the server,
+// sender or recipients are missing or malformed.
+func NewEmail(cfg EmailConfig) (*Email, error) {
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("notify: email: server address: %w", err)
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("notify: email: sender: %w", err)
+	}
+	if len(cfg.To) == 0 {
+		return nil, errors.New("notify: email: no recipients")
+	}
+	e := &Email{cfg: cfg, host: host, from: from, tls: &tls.Config{ServerName: host}}
+	for _, addr := range cfg.To {
+		to, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("notify: email: recipient: %w", err)
+		}
+		e.to = append(e.to, to)
+	}
+	return e, nil
+}
+
+// Name returns "email".
+func (e *Email) Name() string { return TransportEmail }
+
+// Send delivers m to every recipient in one SMTP transaction.
+func (e *Email) Send(ctx context.Context, m Message) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", e.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	implicitTLS := strings.HasSuffix(e.cfg.Addr, ":465")
+	if implicitTLS {
+		conn = tls.Client(conn, e.tls)
+	}
+	// net/smtp takes no context: bound the conversation by its deadline
+	// and cut it short on cancellation.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok && !implicitTLS {
+		if err := c.StartTLS(e.tls); err != nil {
+			return err
+		}
+	}
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.from.Address); err != nil {
+		return err
+	}
+	for _, to := range e.to {
+		if err := c.Rcpt(to.Address); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(e.compose(m)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// compose renders m as a MIME message.
+func (e *Email) compose(m Message) []byte {
+	var b bytes.Buffer
+	to := make([]string, len(e.to))
+	for i, a := range e.to {
+		to[i] = a.String()
+	}
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Title()))
+	date := m.Time
+	if date.IsZero() {
+		date = time.Now()
+	}
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "%s: %s\r\n", HeaderEvent, mime.QEncoding.Encode("utf-8", m.Event))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&b)
+	qp.Write([]byte(m.Body()))
+	qp.Close()
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
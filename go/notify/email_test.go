@@ -0,0 +1,134 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession is what a fake SMTP server received.
+type smtpSession struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP serves one SMTP session on a local port and sends what it
+// received on the returned channel.
+func fakeSMTP(t *testing.T) (addr string, got <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	out := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var s smtpSession
+		tp.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO":
+				tp.PrintfLine("250-fake")
+				tp.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				_, cred, _ := strings.Cut(arg, " ")
+				dec, _ := base64.StdEncoding.DecodeString(cred)
+				s.auth = strings.ReplaceAll(string(dec), "\x00", "|")
+				tp.PrintfLine("235 ok")
+			case "MAIL":
+				s.from = arg
+				tp.PrintfLine("250 ok")
+			case "RCPT":
+				s.to = append(s.to, arg)
+				tp.PrintfLine("250 ok")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				lines, _ := tp.ReadDotLines()
+				s.data = strings.Join(lines, "\n")
+				tp.PrintfLine("250 queued")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				out <- s
+				return
+			default:
+				tp.PrintfLine("502 unknown")
+			}
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+func TestEmail(t *testing.T) {
+	addr, got := fakeSMTP(t)
+	e, err := NewEmail(EmailConfig{
+		Addr:     addr,
+		Username: "notifier",
+		Password: "pw",
+		From:     "Foundation <noreply@example.com>",
+		To:       []string{"ops@example.com", "Dev Team <dev@example.com>"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Message{Event: "user.created", Subject: "Neuer Benutzer: Jürgen", Text: "Welcome aboard\n", Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := e.Send(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	s := <-got
+	if s.auth != "|notifier|pw" || s.from != "FROM:<noreply@example.com>" || len(s.to) != 2 || s.to[1] != "TO:<dev@example.com>" {
+		t.Errorf("session = %+v", s)
+	}
+	for _, want := range []string{
+		`From: "Foundation" <noreply@example.com>`,
+		`To: <ops@example.com>, "Dev Team" <dev@example.com>`,
+		"Subject: =?utf-8?q?Neuer_Benutzer:_J=C3=BCrgen?=",
+		"Date: Fri, 02 Jan 2026 03:04:05 +0000",
+		"X-Notify-Event: user.created",
+		"\n\nWelcome aboard",
+	} {
+		if !strings.Contains(s.data, want) {
+			t.Errorf("message lacks %q:\n%s", want, s.data)
+		}
+	}
+}
+
+func TestEmailHeaderInjection(t *testing.T) {
+	e, _ := NewEmail(EmailConfig{Addr: "localhost:25", From: "a@example.com", To: []string{"b@example.com"}})
+	msg := string(e.compose(Message{Subject: "hi\r\nBcc: victim@example.com"}))
+	if strings.Contains(msg, "\r\nBcc:") {
+		t.Errorf("subject injected a header:\n%s", msg)
+	}
+}
+
+func TestNewEmailValidates(t *testing.T) {
+	for _, cfg := range []EmailConfig{
+		{From: "a@example.com", To: []string{"b@example.com"}},
+		{Addr: "smtp:25", From: "not an address", To: []string{"b@example.com"}},
+		{Addr: "smtp:25", From: "a@example.com"},
+		{Addr: "smtp:25", From: "a@example.com", To: []string{"@"}},
+	} {
+		if _, err := NewEmail(cfg); err == nil {
+			t.Errorf("NewEmail(%+v) succeeded", cfg)
+		}
+	}
+}
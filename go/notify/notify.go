@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package notify sends notifications through pluggable transports: a
+// webhook, email over SMTP, and Slack are built in, and any type with Name
+// and Send can join them. Which transports a service uses is
+// configuration, not code:
+//
+//	var cfg notify.Config
+//	err := config.Bind(&cfg) // FOUNDATION_NOTIFY_TRANSPORTS=webhook,slack
+//	n, err := notify.FromConfig(cfg)
+//	err = n.Send(ctx, notify.Message{Event: "user.created", Data: map[string]any{"user_id": 1}})
+//
+// With Config.Batch, a burst of messages of one event is delivered as a
+// single message, and messages sharing a Key are delivered once.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/batch"
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Message is a notification.
+type Message struct {
+	// Event names what happened, e.g. "user.created".
+	Event string `json:"event"`
+	// Subject is a one-line summary, used as the email subject.
+	Subject string `json:"subject,omitempty"`
+	// Text is the human-readable body.
+	Text string `json:"text,omitempty"`
+	// Data holds the structured details of the event.
+	Data map[string]any `json:"data,omitempty"`
+	// Time is when the event happened. Notifier.Send sets it if zero.
+	Time time.Time `json:"time"`
+	// Key deduplicates batched messages: of the messages of one event
+	// with the same Key, only the first of a batch is delivered.
+	Key string `json:"key,omitempty"`
+	// Batch holds the messages a batched message stands for.
+	Batch []Message `json:"batch,omitempty"`
+}
+
+// Title returns the subject, or the event if the subject is empty.
+func (m Message) Title() string {
+	if m.Subject != "" {
+		return m.Subject
+	}
+	return m.Event
+}
+
+// Body returns the text, or the data as sorted "key: value" lines if the
+// text is empty, for transports that deliver prose.
+func (m Message) Body() string {
+	if m.Text != "" {
+		return m.Text
+	}
+	var b strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(m.Data)) {
+		fmt.Fprintf(&b, "%s: %v\n", k, m.Data[k])
+	}
+	return b.String()
+}
+
+// Transport delivers messages to one destination.
+type Transport interface {
+	// Name identifies the transport in logs and errors.
+	Name() string
+	// Send delivers m, or returns why it could not.
+	Send(ctx context.Context, m Message) error
+}
+
+// Transport names accepted by Config.Transports.
+const (
+	TransportWebhook = "webhook"
+	TransportEmail   = "email"
+	TransportSlack   = "slack"
+)
+
+// DefaultTimeout bounds each delivery when Config.Timeout is zero.
+const DefaultTimeout = 10 * time.Second
+
+// Config selects and configures the built-in transports, bindable with
+// config.Bind. Each transport listed in Transports must have its section
+// filled in.
+type Config struct {
+	Transports []string      `config:"transports" env:"FOUNDATION_NOTIFY_TRANSPORTS" desc:"Transports notifications are sent through: webhook, email or slack."`
+	Timeout    time.Duration `config:"timeout" env:"FOUNDATION_NOTIFY_TIMEOUT" default:"10s" desc:"How long each delivery may take."`
+	Webhook    WebhookConfig `config:"webhook"`
+	Email      EmailConfig   `config:"email"`
+	Slack      SlackConfig   `config:"slack"`
+	Batch      BatchConfig   `config:"batch"`
+}
+
+// Option configures FromConfig and New.
+type Option func(*Notifier)
+
+// WithLogger sets the logger for delivery records. The default is
+// log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(n *Notifier) { n.logger = l }
+}
+
+// WithHTTPOptions applies opts to the clients of the webhook and Slack
+// transports built by FromConfig, e.g. to add retries.
+func WithHTTPOptions(opts ...httpx.Option) Option {
+	return func(n *Notifier) { n.httpOpts = append(n.httpOpts, opts...) }
+}
+
+// WithTransport adds a transport alongside those of the configuration.
+func WithTransport(t Transport) Option {
+	return func(n *Notifier) { n.transports = append(n.transports, t) }
+}
+
+// Notifier sends each message through every one of its transports. It is
+// safe for concurrent use.
+type Notifier struct {
+	transports []Transport
+	timeout    time.Duration
+	logger     *log.Logger
+	httpOpts   []httpx.Option
+	batch      BatchConfig
+	clock      clock.Clock
+
+	mu      sync.Mutex
+	pending map[string]*batch.Processor[queued] // by event
+}
+
+// New returns a notifier sending through transports.
+func New(transports []Transport, opts ...Option) *Notifier {
+	n := &Notifier{
+		transports: slices.Clone(transports),
+		timeout:    DefaultTimeout,
+		clock:      clock.Real(),
+		pending:    make(map[string]*batch.Processor[queued]),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// FromConfig returns a notifier sending through the transports cfg lists.
+// It fails for an unknown transport name or a transport whose section is
+// incomplete.
+func FromConfig(cfg Config, opts ...Option) (*Notifier, error) {
+	n := New(nil, append([]Option{WithBatching(cfg.Batch)}, opts...)...)
+	if cfg.Timeout > 0 {
+		n.timeout = cfg.Timeout
+	}
+	httpOpts := append([]httpx.Option{httpx.WithTimeout(n.timeout)}, n.httpOpts...)
+	var built []Transport
+	var errs []error
+	for _, name := range cfg.Transports {
+		var t Transport
+		var err error
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case TransportWebhook:
+			t, err = NewWebhook(cfg.Webhook, httpOpts...)
+		case TransportEmail:
+			t, err = NewEmail(cfg.Email)
+		case TransportSlack:
+			t, err = NewSlack(cfg.Slack, httpOpts...)
+		default:
+			err = fmt.Errorf("notify: unknown transport %q", name)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		built = append(built, t)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	n.transports = append(built, n.transports...)
+	return n, nil
+}
+
+// Transports returns the transports messages are sent through.
+func (n *Notifier) Transports() []Transport { return slices.Clone(n.transports) }
+
+// Send delivers m through every transport in turn, each within the
+// configured timeout, and returns the failures joined; a failing
+// transport does not keep the others from delivering. Failures are logged
+// as "notification_failed".
+//
+// With batching, Send queues m and only fails if the queue of its event
+// is full; the batch is delivered in the background, and its failures are
+// only logged.
+func (n *Notifier) Send(ctx context.Context, m Message) error {
+	if m.Time.IsZero() {
+		m.Time = n.clock.Now()
+	}
+	if n.batch.Window > 0 {
+		return n.enqueue(ctx, m)
+	}
+	return n.deliverAll(ctx, m)
+}
+
+func (n *Notifier) deliverAll(ctx context.Context, m Message) error {
+	var errs []error
+	for _, t := range n.transports {
+		if err := n.deliver(ctx, t, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) deliver(ctx context.Context, t Transport, m Message) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+	if err := t.Send(ctx, m); err != nil {
+		n.log().WarnCtx(ctx, "notification_failed", "transport", t.Name(), "notify_event", m.Event, log.Err(err))
+		return fmt.Errorf("notify: %s: %w", t.Name(), err)
+	}
+	n.log().DebugCtx(ctx, "notification_sent", "transport", t.Name(), "notify_event", m.Event)
+	return nil
+}
+
+func (n *Notifier) log() *log.Logger {
+	if n.logger != nil {
+		return n.logger
+	}
+	return log.Default()
+}
@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/config"
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/httpx/httpxtest"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+// recorder is a Transport keeping what it is sent.
+type recorder struct {
+	name string
+	err  error
+
+	mu  sync.Mutex
+	got []Message
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Send(ctx context.Context, m Message) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("no deadline")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.got = append(r.got, m)
+	return r.err
+}
+
+func (r *recorder) messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.got)
+}
+
+var created = Message{Event: "user.created", Data: map[string]any{"user_id": 1, "name": "Alice"}}
+
+func TestNotifierSendsThroughEveryTransport(t *testing.T) {
+	rec := logtest.Capture(t)
+	ok, down := &recorder{name: "ok"}, &recorder{name: "down", err: errors.New("unreachable")}
+	n := New([]Transport{down, ok})
+	err := n.Send(context.Background(), created)
+	if err == nil || !strings.Contains(err.Error(), "notify: down: unreachable") {
+		t.Errorf("Send = %v", err)
+	}
+	if len(ok.got) != 1 || len(down.got) != 1 || ok.got[0].Time.IsZero() {
+		t.Errorf("delivered %v and %v", ok.got, down.got)
+	}
+	if rec.WithEvent("notification_failed").WithField("transport", "down").Count() != 1 {
+		t.Error("failure not logged")
+	}
+}
+
+func TestMessageText(t *testing.T) {
+	if created.Title() != "user.created" || created.Body() != "name: Alice\nuser_id: 1\n" {
+		t.Errorf("Title = %q, Body = %q", created.Title(), created.Body())
+	}
+	m := Message{Event: "e", Subject: "Welcome", Text: "Hello"}
+	if m.Title() != "Welcome" || m.Body() != "Hello" {
+		t.Errorf("Title = %q, Body = %q", m.Title(), m.Body())
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	mock := httpxtest.NewMockTransport(t)
+	mock.On("POST", "/hook").Reply(204, "")
+	mock.On("POST", "/services/T/B/X").Reply(200, "ok")
+	extra := &recorder{name: "extra"}
+	n, err := FromConfig(Config{
+		Transports: []string{"webhook", " Slack"},
+		Webhook:    WebhookConfig{URL: "https://hooks.example/hook"},
+		Slack:      SlackConfig{WebhookURL: "https://hooks.slack.com/services/T/B/X"},
+	}, WithHTTPOptions(httpx.WithTransport(mock)), WithTransport(extra))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tr := range n.Transports() {
+		names = append(names, tr.Name())
+	}
+	if strings.Join(names, ",") != "webhook,slack,extra" {
+		t.Errorf("transports = %v", names)
+	}
+	if err := n.Send(context.Background(), created); err != nil {
+		t.Fatal(err)
+	}
+	mock.AssertCalls(t, "POST /hook", "POST /services/T/B/X")
+
+	_, err = FromConfig(Config{Transports: []string{"email", "pager"}})
+	if err == nil || !strings.Contains(err.Error(), `unknown transport "pager"`) || !strings.Contains(err.Error(), "email") {
+		t.Errorf("FromConfig = %v, want both failures", err)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	mock := httpxtest.NewMockTransport(t)
+	mock.On("POST", "/hook").Once().Reply(202, "")
+	mock.On("POST", "/hook").Reply(503, "busy")
+	w, err := NewWebhook(WebhookConfig{URL: "https://hooks.example/hook", Secret: "s3cret"}, httpx.WithTransport(mock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := created
+	m.Time = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := w.Send(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	call := mock.Calls()[0]
+	var got Message
+	if err := call.DecodeJSON(&got); err != nil || got.Event != "user.created" || !got.Time.Equal(m.Time) {
+		t.Errorf("body = %s, %v", call.Body, err)
+	}
+	if call.Header.Get(HeaderEvent) != "user.created" || call.Header.Get(HeaderSignature) != Sign([]byte("s3cret"), call.Body) {
+		t.Errorf("headers = %v", call.Header)
+	}
+
+	var status *httpx.StatusError
+	if err := w.Send(context.Background(), m); !errors.As(err, &status) || status.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Send = %v, want a 503 StatusError", err)
+	}
+	if _, err := NewWebhook(WebhookConfig{}); err == nil {
+		t.Error("NewWebhook without a URL succeeded")
+	}
+}
+
+func TestSlack(t *testing.T) {
+	mock := httpxtest.NewMockTransport(t)
+	mock.On("POST", "/services/T/B/X").Reply(200, "ok")
+	s, err := NewSlack(SlackConfig{WebhookURL: "https://hooks.slack.com/services/T/B/X", Channel: "#alerts"}, httpx.WithTransport(mock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(context.Background(), Message{Event: "deploy", Subject: "Deploy <prod>", Text: "done & dusted"}); err != nil {
+		t.Fatal(err)
+	}
+	var payload struct{ Text, Channel string }
+	mock.Calls()[0].DecodeJSON(&payload)
+	if payload.Text != "*Deploy &lt;prod&gt;*\ndone &amp; dusted" || payload.Channel != "#alerts" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestConfigBinds(t *testing.T) {
+	t.Setenv("FOUNDATION_NOTIFY_TRANSPORTS", "webhook,email")
+	t.Setenv("FOUNDATION_NOTIFY_EMAIL_TO", "a@example.com,b@example.com")
+	var cfg Config
+	if err := config.Bind(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Transports) != 2 || len(cfg.Email.To) != 2 || cfg.Timeout != DefaultTimeout {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
@@ -0,0 +1,474 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package outbox delivers notifications at least once with a transactional
+// outbox. A message is written to a table in the same transaction as the
+// change it reports, so it exists exactly when the change does, and a
+// dispatcher sends it in the background until delivery succeeds:
+//
+//	ob, err := outbox.New(database, notifier)
+//	err = database.Tx(ctx, nil, func(tx *sql.Tx) error {
+//		if _, err := tx.ExecContext(ctx, insertUser, user.ID, user.Email); err != nil {
+//			return err
+//		}
+//		return ob.Add(ctx, tx, notify.Message{Event: "user.created", Data: map[string]any{"user_id": user.ID}})
+//	})
+//
+// The table is created by Schema or Migration. Registered with the
+// container, the Outbox dispatches from OnStart until OnStop.
+//
+// Several processes may dispatch from one table: each row is claimed with
+// a conditional update before it is sent, so one process sends it. A
+// message is sent again when a send fails or its process stops before
+// marking it delivered, so receivers should tolerate duplicates. A
+// notify.Notifier with several transports resends through all of them
+// when one fails, and one with batching reports a message as sent once it
+// is queued, so use one without batching as the sender.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/db"
+	"github.com/provide-io/provide-foundation/go/db/migrate"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/notify"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+// Defaults of the options.
+const (
+	DefaultTable       = "notification_outbox"
+	DefaultInterval    = 5 * time.Second
+	DefaultBatchSize   = 100
+	DefaultMaxAttempts = 10
+	DefaultLease       = time.Minute
+)
+
+// Row statuses.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	// StatusFailed marks a message given up after the maximum attempts.
+	StatusFailed = "failed"
+)
+
+// DefaultBackoff spaces out the attempts of a message: exponential from
+// ten seconds, capped at an hour.
+var DefaultBackoff = retry.Policy{Backoff: retry.Exponential, BaseDelay: 10 * time.Second, MaxDelay: time.Hour}
+
+// Sender delivers messages; *notify.Notifier is one.
+type Sender interface {
+	Send(ctx context.Context, m notify.Message) error
+}
+
+// Option configures New.
+type Option func(*Outbox)
+
+// WithTable sets the table, optionally schema-qualified. The default is
+// DefaultTable.
+func WithTable(name string) Option {
+	return func(o *Outbox) { o.table = name }
+}
+
+// WithInterval sets how often the dispatcher polls the table. The default
+// is DefaultInterval.
+func WithInterval(d time.Duration) Option {
+	return func(o *Outbox) { o.interval = d }
+}
+
+// WithBatchSize sets how many due messages one Dispatch reads. The
+// default is DefaultBatchSize.
+func WithBatchSize(n int) Option {
+	return func(o *Outbox) { o.batchSize = n }
+}
+
+// WithMaxAttempts sets the attempts after which a message is marked
+// StatusFailed and no longer sent. Zero or less retries forever. The
+// default is DefaultMaxAttempts.
+func WithMaxAttempts(n int) Option {
+	return func(o *Outbox) { o.maxAttempts = n }
+}
+
+// WithBackoff sets the delay before the next attempt of a message, from
+// p.Delay of the attempts so far; only the backoff fields of p are used.
+// The default is DefaultBackoff.
+func WithBackoff(p retry.Policy) Option {
+	return func(o *Outbox) { o.backoff = p }
+}
+
+// WithLease sets how long a claimed message is reserved for the process
+// sending it. A message whose process stops while sending it is sent
+// again once the lease ends, so it should exceed the send timeout. The
+// default is DefaultLease.
+func WithLease(d time.Duration) Option {
+	return func(o *Outbox) { o.lease = d }
+}
+
+// WithClock sets the clock of timestamps and polling. The default is
+// clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(o *Outbox) { o.clock = c }
+}
+
+// WithLogger sets the logger; the default is log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(o *Outbox) { o.logger = l }
+}
+
+// Outbox writes messages to the outbox table and dispatches them. It is
+// safe for concurrent use.
+type Outbox struct {
+	db          db.Database
+	sender      Sender
+	table       string
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	backoff     retry.Policy
+	lease       time.Duration
+	clock       clock.Clock
+	logger      *log.Logger
+
+	insert, due, claim, delivered, retried, purge string
+
+	mu   sync.Mutex
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// New returns the outbox of database, whose messages are delivered by
+// sender.
+func New(database db.Database, sender Sender, opts ...Option) (*Outbox, error) {
+	o := &Outbox{
+		db:          database,
+		sender:      sender,
+		table:       DefaultTable,
+		interval:    DefaultInterval,
+		batchSize:   DefaultBatchSize,
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     DefaultBackoff,
+		lease:       DefaultLease,
+		clock:       clock.Real(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if err := checkTable(o.table); err != nil {
+		return nil, err
+	}
+	if o.interval <= 0 {
+		o.interval = DefaultInterval
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = DefaultBatchSize
+	}
+	if o.lease <= 0 {
+		o.lease = DefaultLease
+	}
+	t := o.table
+	o.insert = "INSERT INTO " + t + " (id, event, payload, status, attempts, created_at, next_attempt_at)" +
+		" VALUES (:id, :event, :payload, :status, 0, :now, :now)"
+	o.due = "SELECT id, payload, attempts, next_attempt_at FROM " + t +
+		" WHERE status = :status ORDER BY next_attempt_at, created_at LIMIT :limit"
+	o.claim = "UPDATE " + t + " SET attempts = :next, next_attempt_at = :until" +
+		" WHERE id = :id AND status = :status AND attempts = :attempts"
+	o.delivered = "UPDATE " + t + " SET status = :done, delivered_at = :now, last_error = NULL" +
+		" WHERE id = :id AND status = :status AND attempts = :attempts"
+	o.retried = "UPDATE " + t + " SET status = :next_status, next_attempt_at = :at, last_error = :error" +
+		" WHERE id = :id AND status = :status AND attempts = :attempts"
+	o.purge = "DELETE FROM " + t + " WHERE status = :status"
+	return o, nil
+}
+
+func checkTable(name string) error {
+	for _, part := range strings.Split(name, ".") {
+		if !identifier.MatchString(part) {
+			return fmt.Errorf("outbox: invalid table name %q", name)
+		}
+	}
+	return nil
+}
+
+// Schema returns the CREATE statements of an outbox table, in SQL that
+// PostgreSQL, MySQL and SQLite accept.
+func Schema(table string) string {
+	index := strings.ReplaceAll(table, ".", "_") + "_due"
+	return "CREATE TABLE " + table + ` (
+	id VARCHAR(32) PRIMARY KEY,
+	event VARCHAR(255) NOT NULL,
+	payload TEXT NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	created_at TIMESTAMP NOT NULL,
+	next_attempt_at TIMESTAMP NOT NULL,
+	delivered_at TIMESTAMP
+);
+CREATE INDEX ` + index + " ON " + table + " (status, next_attempt_at);\n"
+}
+
+// Migration returns a migration creating the outbox table, for
+// migrate.WithMigrations.
+func Migration(version int64, table string) migrate.Migration {
+	return migrate.Migration{
+		Version: version,
+		Name:    "create_" + strings.ReplaceAll(table, ".", "_"),
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			if err := checkTable(table); err != nil {
+				return err
+			}
+			for _, stmt := range strings.Split(strings.TrimSpace(Schema(table)), ";") {
+				if stmt = strings.TrimSpace(stmt); stmt == "" {
+					continue
+				}
+				if _, err := tx.ExecContext(ctx, stmt); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, tx *sql.Tx) error {
+			if err := checkTable(table); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, "DROP TABLE "+table)
+			return err
+		},
+	}
+}
+
+// Add writes m to the outbox through ex, normally the transaction of the
+// change m reports, so m is delivered only if the transaction commits.
+// It sets m.Time if zero.
+func (o *Outbox) Add(ctx context.Context, ex db.Executor, m notify.Message) error {
+	now := o.clock.Now().UTC()
+	if m.Time.IsZero() {
+		m.Time = now
+	}
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	q, args, err := db.Named(o.db.Placeholder(), o.insert, map[string]any{
+		"id": newID(), "event": m.Event, "payload": string(payload), "status": StatusPending, "now": now,
+	})
+	if err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	if _, err := ex.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("outbox: adding %s: %w", m.Event, err)
+	}
+	return nil
+}
+
+func newID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// row is a pending message read by Dispatch.
+type row struct {
+	ID            string
+	Payload       string
+	Attempts      int
+	NextAttemptAt time.Time
+}
+
+// Dispatch sends the messages that are due, up to the batch size, and
+// returns how many were delivered. A failed send is logged as
+// "outbox_send_failed" and scheduled again after the backoff; a message
+// out of attempts is logged as "outbox_gave_up" and marked StatusFailed.
+// The error reports database failures only.
+func (o *Outbox) Dispatch(ctx context.Context) (int, error) {
+	now := o.clock.Now().UTC()
+	rows, err := o.pending(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var delivered int
+	var errs []error
+	for _, r := range rows {
+		if r.NextAttemptAt.After(now) {
+			break // rows are in due order
+		}
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		ok, err := o.send(ctx, r)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, errors.Join(errs...)
+}
+
+func (o *Outbox) pending(ctx context.Context) ([]row, error) {
+	q, args, err := db.Named(o.db.Placeholder(), o.due, map[string]any{"status": StatusPending, "limit": o.batchSize})
+	if err != nil {
+		return nil, fmt.Errorf("outbox: %w", err)
+	}
+	rows, err := db.Select[row](ctx, o.db, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: reading %s: %w", o.table, err)
+	}
+	return rows, nil
+}
+
+// send claims r, sends it and records the outcome. It reports whether r
+// was delivered.
+func (o *Outbox) send(ctx context.Context, r row) (bool, error) {
+	attempt := r.Attempts + 1
+	claimed, err := o.update(ctx, o.claim, map[string]any{
+		"id": r.ID, "status": StatusPending, "attempts": r.Attempts,
+		"next": attempt, "until": o.clock.Now().UTC().Add(o.lease),
+	})
+	if err != nil || !claimed {
+		return false, err // another process has it
+	}
+	r.Attempts = attempt
+	var m notify.Message
+	if err := json.Unmarshal([]byte(r.Payload), &m); err != nil {
+		return false, o.fail(ctx, r, m, fmt.Errorf("decoding payload: %w", err), true)
+	}
+	if err := o.sender.Send(ctx, m); err != nil {
+		return false, o.fail(ctx, r, m, err, false)
+	}
+	if _, err := o.update(ctx, o.delivered, map[string]any{
+		"id": r.ID, "status": StatusPending, "attempts": r.Attempts,
+		"done": StatusDelivered, "now": o.clock.Now().UTC(),
+	}); err != nil {
+		return false, err
+	}
+	o.log().DebugCtx(ctx, "outbox_delivered", "outbox_id", r.ID, "notify_event", m.Event, "attempt", r.Attempts)
+	return true, nil
+}
+
+// fail records a failed attempt of r, scheduling the next one or giving
+// up when permanent or out of attempts.
+func (o *Outbox) fail(ctx context.Context, r row, m notify.Message, cause error, permanent bool) error {
+	status, at := StatusPending, o.clock.Now().UTC().Add(o.backoff.Delay(r.Attempts))
+	if permanent || (o.maxAttempts > 0 && r.Attempts >= o.maxAttempts) {
+		status = StatusFailed
+		o.log().ErrorCtx(ctx, "outbox_gave_up", "outbox_id", r.ID, "notify_event", m.Event, "attempt", r.Attempts, log.Err(cause))
+	} else {
+		o.log().WarnCtx(ctx, "outbox_send_failed", "outbox_id", r.ID, "notify_event", m.Event,
+			"attempt", r.Attempts, "retry_at", at, log.Err(cause))
+	}
+	_, err := o.update(ctx, o.retried, map[string]any{
+		"id": r.ID, "status": StatusPending, "attempts": r.Attempts,
+		"next_status": status, "at": at, "error": cause.Error(),
+	})
+	return err
+}
+
+// update runs a conditional update and reports whether it changed a row.
+func (o *Outbox) update(ctx context.Context, query string, arg map[string]any) (bool, error) {
+	q, args, err := db.Named(o.db.Placeholder(), query, arg)
+	if err != nil {
+		return false, fmt.Errorf("outbox: %w", err)
+	}
+	res, err := o.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, fmt.Errorf("outbox: updating %s: %w", o.table, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("outbox: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Purge deletes the delivered messages and returns how many there were.
+func (o *Outbox) Purge(ctx context.Context) (int64, error) {
+	q, args, err := db.Named(o.db.Placeholder(), o.purge, map[string]any{"status": StatusDelivered})
+	if err != nil {
+		return 0, fmt.Errorf("outbox: %w", err)
+	}
+	res, err := o.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: purging %s: %w", o.table, err)
+	}
+	return res.RowsAffected()
+}
+
+// OnStart starts dispatching every interval in the background. It does
+// nothing when already running.
+func (o *Outbox) OnStart(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stop != nil {
+		return nil
+	}
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	o.stop, o.done = cancel, make(chan struct{})
+	go o.run(runCtx, o.done)
+	return nil
+}
+
+// OnStop stops dispatching and waits for a send in progress or ctx.
+// Messages left pending are sent after the next start.
+func (o *Outbox) OnStop(ctx context.Context) error {
+	o.mu.Lock()
+	stop, done := o.stop, o.done
+	o.stop, o.done = nil, nil
+	o.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	stop()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (o *Outbox) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := o.clock.NewTicker(o.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+		// A full batch may mean more is due; keep going until it is not.
+		for {
+			n, err := o.Dispatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				o.log().WarnCtx(ctx, "outbox_dispatch_failed", log.Err(err))
+			}
+			if err != nil || n < o.batchSize {
+				break
+			}
+		}
+	}
+}
+
+func (o *Outbox) log() *log.Logger {
+	if o.logger != nil {
+		return o.logger
+	}
+	return log.Default()
+}
@@ -0,0 +1,245 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/db/dbtest"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/notify"
+	"github.com/provide-io/provide-foundation/go/resilience/retry"
+)
+
+// sender records messages and fails while err is set.
+type sender struct {
+	mu   sync.Mutex
+	sent []notify.Message
+	err  error
+	hook func()
+}
+
+func (s *sender) Send(_ context.Context, m notify.Message) error {
+	if s.hook != nil {
+		s.hook()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func (s *sender) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *sender) messages() []notify.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]notify.Message(nil), s.sent...)
+}
+
+func setup(t *testing.T, s Sender, opts ...Option) (*Outbox, *dbtest.Fake, *clock.Fake) {
+	t.Helper()
+	fake := dbtest.New(t)
+	fake.Table(DefaultTable).Columns("id", "event", "payload", "status", "attempts",
+		"last_error", "created_at", "next_attempt_at", "delivered_at")
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	ob, err := New(fake, s, append([]Option{WithClock(clk)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ob, fake, clk
+}
+
+func add(t *testing.T, ob *Outbox, fake *dbtest.Fake, m notify.Message) {
+	t.Helper()
+	err := fake.Tx(context.Background(), nil, func(tx *sql.Tx) error {
+		return ob.Add(context.Background(), tx, m)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddFollowsTransaction(t *testing.T) {
+	ob, fake, _ := setup(t, &sender{})
+	ctx := context.Background()
+	add(t, ob, fake, notify.Message{Event: "user.created"})
+	rollback := errors.New("rollback")
+	err := fake.Tx(ctx, nil, func(tx *sql.Tx) error {
+		if err := ob.Add(ctx, tx, notify.Message{Event: "user.deleted"}); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("Tx = %v", err)
+	}
+	rows := fake.Rows(DefaultTable)
+	if len(rows) != 1 || rows[0]["event"] != "user.created" || rows[0]["status"] != StatusPending {
+		t.Fatalf("rows = %v", rows)
+	}
+	if !strings.Contains(rows[0]["payload"].(string), `"time":"2026-01-01T00:00:00Z"`) {
+		t.Errorf("payload = %v", rows[0]["payload"])
+	}
+}
+
+func TestDispatchDelivers(t *testing.T) {
+	s := &sender{}
+	ob, fake, clk := setup(t, s)
+	add(t, ob, fake, notify.Message{Event: "user.created", Data: map[string]any{"user_id": 1}})
+	add(t, ob, fake, notify.Message{Event: "user.created", Data: map[string]any{"user_id": 2}})
+
+	n, err := ob.Dispatch(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("Dispatch = %d, %v", n, err)
+	}
+	got := s.messages()
+	if len(got) != 2 || got[0].Data["user_id"] != float64(1) {
+		t.Fatalf("sent = %+v", got)
+	}
+	for _, r := range fake.Rows(DefaultTable) {
+		if r["status"] != StatusDelivered || r["attempts"] != int64(1) || r["delivered_at"] != clk.Now() {
+			t.Errorf("row = %v", r)
+		}
+	}
+	if n, _ := ob.Dispatch(context.Background()); n != 0 || len(s.messages()) != 2 {
+		t.Errorf("redelivered %d", n)
+	}
+	if n, err := ob.Purge(context.Background()); err != nil || n != 2 {
+		t.Errorf("Purge = %d, %v", n, err)
+	}
+}
+
+func TestDispatchRetriesWithBackoff(t *testing.T) {
+	rec := logtest.Capture(t)
+	s := &sender{err: errors.New("connection refused")}
+	ob, fake, clk := setup(t, s, WithLogger(rec.Logger()), WithMaxAttempts(3),
+		WithBackoff(retry.Policy{Backoff: retry.Fixed, BaseDelay: time.Minute}))
+	add(t, ob, fake, notify.Message{Event: "user.created"})
+	ctx := context.Background()
+
+	if n, err := ob.Dispatch(ctx); err != nil || n != 0 {
+		t.Fatalf("Dispatch = %d, %v", n, err)
+	}
+	r := fake.Rows(DefaultTable)[0]
+	if r["status"] != StatusPending || r["last_error"] != "connection refused" ||
+		r["next_attempt_at"] != clk.Now().Add(time.Minute) {
+		t.Fatalf("row = %v", r)
+	}
+	if n, _ := ob.Dispatch(ctx); n != 0 || fake.Rows(DefaultTable)[0]["attempts"] != int64(1) {
+		t.Fatal("retried before the backoff ended")
+	}
+
+	clk.Advance(time.Minute)
+	ob.Dispatch(ctx)
+	clk.Advance(time.Minute)
+	ob.Dispatch(ctx)
+	r = fake.Rows(DefaultTable)[0]
+	if r["status"] != StatusFailed || r["attempts"] != int64(3) {
+		t.Fatalf("row = %v", r)
+	}
+	if rec.WithEvent("outbox_send_failed").Count() != 2 || rec.WithEvent("outbox_gave_up").WithLevel(log.LevelError).Count() != 1 {
+		t.Errorf("events = %v", rec.Records().Events())
+	}
+
+	s.fail(nil)
+	clk.Advance(time.Hour)
+	if n, _ := ob.Dispatch(ctx); n != 0 {
+		t.Error("sent a failed message")
+	}
+}
+
+func TestDispatchRecoversAfterFailure(t *testing.T) {
+	s := &sender{err: errors.New("503")}
+	ob, fake, clk := setup(t, s, WithBackoff(retry.Policy{Backoff: retry.Fixed, BaseDelay: time.Second}))
+	add(t, ob, fake, notify.Message{Event: "user.created"})
+	ob.Dispatch(context.Background())
+	s.fail(nil)
+	clk.Advance(time.Second)
+	if n, err := ob.Dispatch(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Dispatch = %d, %v", n, err)
+	}
+	r := fake.Rows(DefaultTable)[0]
+	if r["status"] != StatusDelivered || r["attempts"] != int64(2) || r["last_error"] != nil {
+		t.Errorf("row = %v", r)
+	}
+}
+
+func TestClaimedMessageIsLeased(t *testing.T) {
+	s := &sender{}
+	ob, fake, clk := setup(t, s, WithLease(time.Minute))
+	other, err := New(fake, s, WithClock(clk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var otherSent int
+	s.hook = func() {
+		s.hook = nil
+		otherSent, _ = other.Dispatch(context.Background())
+	}
+	add(t, ob, fake, notify.Message{Event: "user.created"})
+	if n, err := ob.Dispatch(context.Background()); err != nil || n != 1 {
+		t.Fatalf("Dispatch = %d, %v", n, err)
+	}
+	if otherSent != 0 || len(s.messages()) != 1 {
+		t.Errorf("sent %d messages, the other dispatcher %d", len(s.messages()), otherSent)
+	}
+}
+
+func TestBackgroundDispatch(t *testing.T) {
+	s := &sender{}
+	ob, fake, clk := setup(t, s, WithInterval(time.Second))
+	add(t, ob, fake, notify.Message{Event: "user.created"})
+	ctx := context.Background()
+	if err := ob.OnStart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.messages()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := ob.OnStop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.messages()) != 1 {
+		t.Errorf("sent %d messages", len(s.messages()))
+	}
+}
+
+func TestNewRejectsTableName(t *testing.T) {
+	if _, err := New(dbtest.New(t), &sender{}, WithTable("outbox; DROP TABLE users")); err == nil {
+		t.Error("accepted an invalid table name")
+	}
+	if _, err := New(dbtest.New(t), &sender{}, WithTable("app.outbox")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	got := Schema("app.outbox")
+	if !strings.HasPrefix(got, "CREATE TABLE app.outbox (") ||
+		!strings.Contains(got, "CREATE INDEX app_outbox_due ON app.outbox (status, next_attempt_at);") {
+		t.Errorf("Schema = %s", got)
+	}
+	if m := Migration(3, DefaultTable); m.Version != 3 || m.Name != "create_notification_outbox" {
+		t.Errorf("Migration = %+v", m)
+	}
+}
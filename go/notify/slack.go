@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/serde"
+)
+
+// SlackConfig configures the Slack transport.
+type SlackConfig struct {
+	WebhookURL string `config:"webhook_url" env:"FOUNDATION_NOTIFY_SLACK_WEBHOOK_URL" desc:"Slack incoming webhook URL."`
+	Channel    string `config:"channel" env:"FOUNDATION_NOTIFY_SLACK_CHANNEL" desc:"Channel overriding the webhook's default, e.g. #alerts."`
+}
+
+// Slack posts each message to a Slack incoming webhook, the title in bold
+// above the body.
+type Slack struct {
+	client  *httpx.Client
+	channel string
+}
+
+// NewSlack returns a Slack transport for cfg. opts configure its HTTP
+// client.
+func NewSlack(cfg SlackConfig, opts ...httpx.Option) (*Slack, error) {
+	if cfg.WebhookURL == "" {
+		return nil, errors.New("notify: slack: no webhook URL")
+	}
+	client, err := httpx.New(cfg.WebhookURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Slack{client: client, channel: cfg.Channel}, nil
+}
+
+// Name returns "slack".
+func (s *Slack) Name() string { return TransportSlack }
+
+// slackEscaper escapes the characters Slack reserves for markup.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Send posts m.
+func (s *Slack) Send(ctx context.Context, m Message) error {
+	text := "*" + slackEscaper.Replace(m.Title()) + "*"
+	if body := strings.TrimSpace(m.Body()); body != "" {
+		text += "\n" + slackEscaper.Replace(body)
+	}
+	payload := struct {
+		Text    string `json:"text"`
+		Channel string `json:"channel,omitempty"`
+	}{text, s.channel}
+	body, err := serde.Marshal(serde.JSON, payload)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Post(ctx, "", bytes.NewReader(body), httpx.WithHeader("Content-Type", "application/json"))
+	return err
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/serde"
+)
+
+// Headers of webhook deliveries.
+const (
+	// HeaderEvent carries Message.Event.
+	HeaderEvent = "X-Notify-Event"
+	// HeaderSignature carries "sha256=" and the hex HMAC-SHA256 of the
+	// body under WebhookConfig.Secret, for the receiver to verify.
+	HeaderSignature = "X-Notify-Signature-256"
+)
+
+// WebhookConfig configures the webhook transport.
+type WebhookConfig struct {
+	URL    string `config:"url" env:"FOUNDATION_NOTIFY_WEBHOOK_URL" desc:"URL the JSON of each message is POSTed to."`
+	Secret string `config:"secret" env:"FOUNDATION_NOTIFY_WEBHOOK_SECRET" desc:"Key signing each body in the X-Notify-Signature-256 header; unsigned when empty."`
+}
+
+// Webhook POSTs each message as JSON to a URL.
+type Webhook struct {
+	client *httpx.Client
+	secret []byte
+}
+
+// NewWebhook returns a webhook transport for cfg. opts configure its
+// HTTP client.
+func NewWebhook(cfg WebhookConfig, opts ...httpx.Option) (*Webhook, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("notify: webhook: no URL")
+	}
+	client, err := httpx.New(cfg.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Webhook{client: client, secret: []byte(cfg.Secret)}, nil
+}
+
+// Name returns "webhook".
+func (w *Webhook) Name() string { return TransportWebhook }
+
+// Send POSTs m. A 4xx or 5xx response fails with an *httpx.StatusError.
+func (w *Webhook) Send(ctx context.Context, m Message) error {
+	body, err := serde.Marshal(serde.JSON, m)
+	if err != nil {
+		return err
+	}
+	opts := []httpx.RequestOption{
+		httpx.WithHeader("Content-Type", "application/json"),
+		httpx.WithHeader(HeaderEvent, m.Event),
+	}
+	if len(w.secret) > 0 {
+		opts = append(opts, httpx.WithHeader(HeaderSignature, Sign(w.secret, body)))
+	}
+	_, err = w.client.Post(ctx, "", bytes.NewReader(body), opts...)
+	return err
+}
+
+// Sign returns the HeaderSignature value of body under secret, for
+// receivers to compare with hmac.Equal.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,272 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// grpcServices are the collector services of each signal.
+var grpcServices = map[Signal]string{
+	SignalLogs:    "opentelemetry.proto.collector.logs.v1.LogsService",
+	SignalTraces:  "opentelemetry.proto.collector.trace.v1.TraceService",
+	SignalMetrics: "opentelemetry.proto.collector.metrics.v1.MetricsService",
+}
+
+// ExportError describes a rejected export.
+type ExportError struct {
+	// HTTPStatus is the HTTP status code, or 0 for transport errors.
+	HTTPStatus int
+	// GRPCStatus is the gRPC status code for gRPC exports.
+	GRPCStatus int
+	Message    string
+	// Retryable reports whether the specification allows a retry.
+	Retryable bool
+	// RetryAfter is the delay requested by the server, if any.
+	RetryAfter time.Duration
+}
+
+func (e *ExportError) Error() string {
+	if e.HTTPStatus == 0 {
+		return "otlp: export failed: " + e.Message
+	}
+	if e.GRPCStatus != 0 {
+		return fmt.Sprintf("otlp: export failed: grpc status %d: %s", e.GRPCStatus, e.Message)
+	}
+	return fmt.Sprintf("otlp: export failed: http status %d: %s", e.HTTPStatus, e.Message)
+}
+
+// Client sends encoded export requests for one signal.
+type Client struct {
+	cfg    Config
+	url    string
+	grpc   bool
+	http   *http.Client
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func() float64
+}
+
+// NewClient returns a client exporting signal as configured by cfg.
+func NewClient(cfg Config, signal Signal) (*Client, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("otlp: invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	c := &Client{cfg: cfg, url: cfg.Endpoint, sleep: sleepCtx, jitter: rand.Float64}
+	switch cfg.Protocol {
+	case ProtocolGRPC:
+		service, ok := grpcServices[signal]
+		if !ok {
+			return nil, fmt.Errorf("otlp: unknown signal %q", signal)
+		}
+		// gRPC needs HTTP/2; plain-text endpoints use prior-knowledge h2c.
+		var protocols http.Protocols
+		if u.Scheme == "http" {
+			protocols.SetUnencryptedHTTP2(true)
+		} else {
+			protocols.SetHTTP2(true)
+		}
+		transport.Protocols = &protocols
+		c.grpc = true
+		c.url = u.Scheme + "://" + u.Host + "/" + service + "/Export"
+	case ProtocolHTTPProtobuf, "":
+	default:
+		return nil, fmt.Errorf("otlp: unsupported protocol %q", cfg.Protocol)
+	}
+	c.http = &http.Client{Transport: transport}
+	return c, nil
+}
+
+// Export sends one encoded Export*ServiceRequest, retrying transient
+// failures as configured.
+func (c *Client) Export(ctx context.Context, msg []byte) error {
+	body, err := c.body(msg)
+	if err != nil {
+		return err
+	}
+	retry := c.cfg.Retry
+	start := time.Now()
+	interval := retry.InitialInterval
+	for {
+		err := c.attempt(ctx, body)
+		var exportErr *ExportError
+		if err == nil || !retry.Enabled || !errors.As(err, &exportErr) || !exportErr.Retryable {
+			return err
+		}
+		wait := time.Duration(float64(interval) * (0.5 + c.jitter()))
+		wait = max(wait, exportErr.RetryAfter)
+		if retry.MaxElapsedTime > 0 && time.Since(start)+wait > retry.MaxElapsedTime {
+			return err
+		}
+		if serr := c.sleep(ctx, wait); serr != nil {
+			return errors.Join(err, serr)
+		}
+		interval = min(interval*2, max(retry.MaxInterval, retry.InitialInterval))
+	}
+}
+
+// body frames and compresses msg for the wire.
+func (c *Client) body(msg []byte) ([]byte, error) {
+	if c.cfg.Compression == "gzip" {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(msg); err != nil {
+			return nil, fmt.Errorf("otlp: compress: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("otlp: compress: %w", err)
+		}
+		msg = buf.Bytes()
+	}
+	if !c.grpc {
+		return msg, nil
+	}
+	// Length-prefixed gRPC message: compressed flag and big-endian length.
+	frame := make([]byte, 5, 5+len(msg))
+	if c.cfg.Compression == "gzip" {
+		frame[0] = 1
+	}
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...), nil
+}
+
+func (c *Client) attempt(parent context.Context, body []byte) error {
+	ctx, cancel := context.WithTimeout(parent, c.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("otlp: %w", err)
+	}
+	for k, v := range c.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if c.grpc {
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("TE", "trailers")
+		if c.cfg.Compression == "gzip" {
+			req.Header.Set("Grpc-Encoding", "gzip")
+		}
+	} else {
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		if c.cfg.Compression == "gzip" {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// Connection failures and attempt timeouts are transient; a
+		// cancelled caller is not.
+		return &ExportError{Message: err.Error(), Retryable: parent.Err() == nil}
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	io.Copy(io.Discard, resp.Body)
+
+	if c.grpc {
+		return grpcResult(resp)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return &ExportError{
+		HTTPStatus: resp.StatusCode,
+		Message:    strings.TrimSpace(string(respBody)),
+		Retryable:  retryableHTTP(resp.StatusCode),
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+func grpcResult(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return &ExportError{
+			HTTPStatus: resp.StatusCode,
+			Message:    resp.Status,
+			Retryable:  retryableHTTP(resp.StatusCode),
+		}
+	}
+	// Trailers-only responses carry the status in the headers.
+	status := resp.Trailer.Get("Grpc-Status")
+	msg := resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+		msg = resp.Header.Get("Grpc-Message")
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return &ExportError{HTTPStatus: resp.StatusCode, Message: "missing grpc-status", Retryable: false}
+	}
+	if code == 0 {
+		return nil
+	}
+	if m, err := url.PathUnescape(msg); err == nil {
+		msg = m
+	}
+	return &ExportError{HTTPStatus: resp.StatusCode, GRPCStatus: code, Message: msg, Retryable: retryableGRPC(code)}
+}
+
+// retryableHTTP lists the HTTP statuses the OTLP specification retries.
+func retryableHTTP(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryableGRPC lists the gRPC codes the OTLP specification retries:
+// CANCELLED, DEADLINE_EXCEEDED, RESOURCE_EXHAUSTED, ABORTED, OUT_OF_RANGE,
+// UNAVAILABLE and DATA_LOSS.
+func retryableGRPC(code int) bool {
+	switch code {
+	case 1, 4, 8, 10, 11, 14, 15:
+		return true
+	}
+	return false
+}
+
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close releases idle connections.
+func (c *Client) Close() {
+	c.http.CloseIdleConnections()
+}
@@ -0,0 +1,127 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testConfig(endpoint string, p Protocol) Config {
+	return Config{
+		Endpoint: endpoint,
+		Protocol: p,
+		Headers:  map[string]string{"api-key": "secret"},
+		Timeout:  time.Second,
+		Retry:    RetryConfig{Enabled: true, InitialInterval: time.Millisecond, MaxInterval: 5 * time.Millisecond, MaxElapsedTime: time.Second},
+	}
+}
+
+func TestClientHTTPRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/logs" || r.Header.Get("Content-Type") != "application/x-protobuf" || r.Header.Get("api-key") != "secret" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("body not gzipped: %v", err)
+			return
+		}
+		if body, _ := io.ReadAll(zr); string(body) != "payload" {
+			t.Errorf("body = %q", body)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := testConfig(SignalURL(srv.URL, SignalLogs), ProtocolHTTPProtobuf)
+	cfg.Compression = "gzip"
+	c, err := NewClient(cfg, SignalLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := c.Export(context.Background(), []byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestClientHTTPDoesNotRetryPermanentFailures(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(testConfig(srv.URL+"/v1/logs", ProtocolHTTPProtobuf), SignalLogs)
+	err := c.Export(context.Background(), []byte("x"))
+	var exportErr *ExportError
+	if !errors.As(err, &exportErr) || exportErr.HTTPStatus != http.StatusBadRequest || exportErr.Message != "bad payload" {
+		t.Fatalf("err = %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestClientGRPCOverH2C(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 || r.URL.Path != "/opentelemetry.proto.collector.logs.v1.LogsService/Export" {
+			t.Errorf("unexpected request %s %s", r.Proto, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if len(body) < 5 || body[0] != 0 || binary.BigEndian.Uint32(body[1:5]) != uint32(len(body)-5) || !bytes.Equal(body[5:], []byte("payload")) {
+			t.Errorf("bad gRPC frame %x", body)
+		}
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		w.WriteHeader(http.StatusOK)
+		if calls.Add(1) == 1 {
+			w.Header().Set("Grpc-Status", "14")
+			w.Header().Set("Grpc-Message", "collector%20busy")
+			return
+		}
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	c, err := NewClient(testConfig(srv.URL, ProtocolGRPC), SignalLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Export(context.Background(), []byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want a retry after UNAVAILABLE", calls.Load())
+	}
+
+	c.cfg.Retry.Enabled = false
+	calls.Store(0)
+	err = c.Export(context.Background(), []byte("payload"))
+	var exportErr *ExportError
+	if !errors.As(err, &exportErr) || exportErr.GRPCStatus != 14 || exportErr.Message != "collector busy" {
+		t.Errorf("err = %v", err)
+	}
+}
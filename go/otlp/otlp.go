@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package otlp implements the parts of the OpenTelemetry protocol shared by
+// the foundation log, trace and metric exporters: configuration from the
+// standard OTEL_EXPORTER_OTLP_* variables, protobuf encoding of the common
+// message types, and an export client for OTLP/gRPC and OTLP/HTTP with
+// retries. It has no dependencies outside the standard library.
+package otlp
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Protocol is an OTLP transport.
+type Protocol string
+
+// Supported protocols, named as in OTEL_EXPORTER_OTLP_PROTOCOL.
+const (
+	ProtocolGRPC         Protocol = "grpc"
+	ProtocolHTTPProtobuf Protocol = "http/protobuf"
+)
+
+// Signal is a kind of telemetry.
+type Signal string
+
+// Signals and the names used in per-signal variables and URL paths.
+const (
+	SignalLogs    Signal = "logs"
+	SignalTraces  Signal = "traces"
+	SignalMetrics Signal = "metrics"
+)
+
+// Defaults from the OTLP exporter specification.
+const (
+	DefaultGRPCEndpoint = "http://localhost:4317"
+	DefaultHTTPEndpoint = "http://localhost:4318"
+	DefaultTimeout      = 10 * time.Second
+)
+
+// RetryConfig controls retries of exports that fail with a transient
+// error. Intervals grow exponentially with jitter.
+type RetryConfig struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// MaxElapsedTime bounds the total time spent on one export.
+	MaxElapsedTime time.Duration
+}
+
+// DefaultRetry matches the OpenTelemetry SDK defaults.
+var DefaultRetry = RetryConfig{
+	Enabled:         true,
+	InitialInterval: 5 * time.Second,
+	MaxInterval:     30 * time.Second,
+	MaxElapsedTime:  time.Minute,
+}
+
+// Config describes where and how to export one signal.
+type Config struct {
+	// Endpoint is the full URL. For HTTP it includes the signal path,
+	// e.g. http://collector:4318/v1/logs; for gRPC only scheme and host
+	// are used.
+	Endpoint string
+	Protocol Protocol
+	Headers  map[string]string
+	// Timeout bounds each export attempt.
+	Timeout time.Duration
+	// Compression is "gzip" or empty.
+	Compression string
+	Retry       RetryConfig
+}
+
+// ConfigFromEnv reads the exporter configuration of signal from the
+// environment. Per-signal variables such as OTEL_EXPORTER_OTLP_LOGS_ENDPOINT
+// take precedence over the general ones.
+func ConfigFromEnv(signal Signal) (Config, error) {
+	lookup := func(name string) (string, bool) {
+		if v, ok := os.LookupEnv("OTEL_EXPORTER_OTLP_" + strings.ToUpper(string(signal)) + "_" + name); ok {
+			return v, true
+		}
+		return os.LookupEnv("OTEL_EXPORTER_OTLP_" + name)
+	}
+	cfg := Config{
+		Protocol: ProtocolHTTPProtobuf,
+		Headers:  map[string]string{},
+		Timeout:  DefaultTimeout,
+		Retry:    DefaultRetry,
+	}
+
+	if v, ok := lookup("PROTOCOL"); ok {
+		switch p := Protocol(strings.TrimSpace(v)); p {
+		case ProtocolGRPC, ProtocolHTTPProtobuf:
+			cfg.Protocol = p
+		default:
+			return Config{}, fmt.Errorf("otlp: unsupported protocol %q", v)
+		}
+	}
+	if v, ok := lookup("HEADERS"); ok {
+		headers, err := ParseHeaders(v)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.Headers = headers
+	}
+	if v, ok := lookup("TIMEOUT"); ok {
+		ms, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || ms < 0 {
+			return Config{}, fmt.Errorf("otlp: invalid timeout %q, want milliseconds", v)
+		}
+		cfg.Timeout = time.Duration(ms) * time.Millisecond
+	}
+	if v, ok := lookup("COMPRESSION"); ok {
+		switch v = strings.TrimSpace(v); v {
+		case "gzip":
+			cfg.Compression = v
+		case "", "none":
+		default:
+			return Config{}, fmt.Errorf("otlp: unsupported compression %q", v)
+		}
+	}
+	insecure := false
+	if v, ok := lookup("INSECURE"); ok {
+		insecure, _ = strconv.ParseBool(strings.TrimSpace(v))
+	}
+
+	// The signal-specific endpoint is used as is; the general one gets the
+	// signal path appended for HTTP.
+	if v, ok := os.LookupEnv("OTEL_EXPORTER_OTLP_" + strings.ToUpper(string(signal)) + "_ENDPOINT"); ok && v != "" {
+		cfg.Endpoint = withScheme(v, insecure)
+	} else {
+		base := DefaultHTTPEndpoint
+		if cfg.Protocol == ProtocolGRPC {
+			base = DefaultGRPCEndpoint
+		}
+		if v, ok := os.LookupEnv("OTEL_EXPORTER_OTLP_ENDPOINT"); ok && v != "" {
+			base = withScheme(v, insecure)
+		}
+		cfg.Endpoint = base
+		if cfg.Protocol == ProtocolHTTPProtobuf {
+			cfg.Endpoint = SignalURL(base, signal)
+		}
+	}
+	return cfg, nil
+}
+
+// SignalURL appends the standard path of signal, e.g. "/v1/logs", to a
+// base HTTP endpoint.
+func SignalURL(base string, signal Signal) string {
+	return strings.TrimRight(base, "/") + "/v1/" + string(signal)
+}
+
+// withScheme adds a scheme to host:port endpoints, which the gRPC
+// exporter conventionally accepts.
+func withScheme(endpoint string, insecure bool) string {
+	if strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+	if insecure {
+		return "http://" + endpoint
+	}
+	return "https://" + endpoint
+}
+
+// ParseHeaders parses the W3C-baggage-like header list of
+// OTEL_EXPORTER_OTLP_HEADERS: comma-separated key=value pairs with
+// percent-encoded values.
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("otlp: invalid header %q, want key=value", pair)
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("otlp: header %q: %w", k, err)
+		}
+		headers[k] = value
+	}
+	return headers, nil
+}
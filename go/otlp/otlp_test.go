@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/platform"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=abc%20def, tenant=acme")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_TIMEOUT", "2500")
+	t.Setenv("OTEL_EXPORTER_OTLP_COMPRESSION", "gzip")
+
+	cfg, err := ConfigFromEnv(SignalLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint != "http://collector:4318/v1/logs" || cfg.Protocol != ProtocolHTTPProtobuf {
+		t.Errorf("endpoint %q protocol %q", cfg.Endpoint, cfg.Protocol)
+	}
+	if cfg.Headers["api-key"] != "abc def" || cfg.Headers["tenant"] != "acme" {
+		t.Errorf("headers = %v", cfg.Headers)
+	}
+	if cfg.Timeout != 2500*time.Millisecond || cfg.Compression != "gzip" {
+		t.Errorf("timeout %v compression %q", cfg.Timeout, cfg.Compression)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "grpc")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "collector:4317")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_INSECURE", "true")
+	cfg, err = ConfigFromEnv(SignalTraces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint != "http://collector:4317" || cfg.Protocol != ProtocolGRPC {
+		t.Errorf("endpoint %q protocol %q", cfg.Endpoint, cfg.Protocol)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/json")
+	if _, err := ConfigFromEnv(SignalMetrics); err == nil {
+		t.Error("expected an error for an unsupported protocol")
+	}
+}
+
+func TestResourceFromEnv(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=prod,service.name=from-attrs")
+	t.Setenv("OTEL_SERVICE_NAME", "users")
+
+	attrs := ResourceFromEnv(KeyValue{Key: "service.version", Value: "1.2.3"})
+	got := map[string]any{}
+	for _, a := range attrs {
+		got[a.Key] = a.Value
+	}
+	if got["service.name"] != "users" || got["deployment.environment"] != "prod" || got["service.version"] != "1.2.3" {
+		t.Errorf("resource = %v", got)
+	}
+	if got["os.type"] != runtime.GOOS || got["host.arch"] != platform.Current().Arch {
+		t.Errorf("platform attributes = %v", got)
+	}
+	if got["process.runtime.name"] != "go" || got["process.runtime.version"] != runtime.Version() {
+		t.Errorf("runtime attributes = %v", got)
+	}
+
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "host.name=override")
+	for _, a := range ResourceFromEnv() {
+		if a.Key == "host.name" && a.Value != "override" {
+			t.Errorf("host.name = %v, want the environment value", a.Value)
+		}
+	}
+}
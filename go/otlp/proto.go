@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Encoder appends protobuf wire-format fields. Every method writes its
+// field even for zero values; callers skip fields they want omitted.
+type Encoder struct {
+	// Scrub, if set, sees every attribute written by KeyValue and
+	// Resource: it returns the value to write, or false to leave the
+	// attribute out.
+	Scrub func(key string, value any) (any, bool)
+
+	buf []byte
+}
+
+// Bytes returns the encoded message.
+func (e *Encoder) Bytes() []byte { return e.buf }
+
+// Reset empties the encoder, keeping its storage.
+func (e *Encoder) Reset() { e.buf = e.buf[:0] }
+
+func (e *Encoder) tag(field, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wire))
+}
+
+// Uint64 writes a varint field.
+func (e *Encoder) Uint64(field int, v uint64) {
+	e.tag(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+// Int64 writes an int64 (two's complement varint) field.
+func (e *Encoder) Int64(field int, v int64) { e.Uint64(field, uint64(v)) }
+
+// Bool writes a bool field.
+func (e *Encoder) Bool(field int, v bool) {
+	var b uint64
+	if v {
+		b = 1
+	}
+	e.Uint64(field, b)
+}
+
+// Fixed64 writes a fixed64 field.
+func (e *Encoder) Fixed64(field int, v uint64) {
+	e.tag(field, wireFixed64)
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, v)
+}
+
+// Fixed32 writes a fixed32 field.
+func (e *Encoder) Fixed32(field int, v uint32) {
+	e.tag(field, wireFixed32)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+// Double writes a double field.
+func (e *Encoder) Double(field int, v float64) { e.Fixed64(field, math.Float64bits(v)) }
+
+// PackedFixed64 writes a packed repeated fixed64 field.
+func (e *Encoder) PackedFixed64(field int, vs []uint64) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(8*len(vs)))
+	for _, v := range vs {
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, v)
+	}
+}
+
+// PackedDouble writes a packed repeated double field.
+func (e *Encoder) PackedDouble(field int, vs []float64) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(8*len(vs)))
+	for _, v := range vs {
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+	}
+}
+
+// String writes a string field.
+func (e *Encoder) String(field int, s string) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// BytesField writes a bytes field.
+func (e *Encoder) BytesField(field int, b []byte) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// Message writes an embedded message whose fields are written by fn.
+func (e *Encoder) Message(field int, fn func(*Encoder)) {
+	e.tag(field, wireBytes)
+	start := len(e.buf)
+	fn(e)
+	n := len(e.buf) - start
+	var lenBuf [binary.MaxVarintLen64]byte
+	prefix := binary.PutUvarint(lenBuf[:], uint64(n))
+	e.buf = slices.Insert(e.buf, start, lenBuf[:prefix]...)
+}
+
+// KeyValue writes an opentelemetry.proto.common.v1.KeyValue field.
+func (e *Encoder) KeyValue(field int, key string, value any) {
+	if e.Scrub != nil {
+		var keep bool
+		if value, keep = e.Scrub(key, value); !keep {
+			return
+		}
+	}
+	e.Message(field, func(e *Encoder) {
+		e.String(1, key)
+		e.AnyValue(2, value)
+	})
+}
+
+// Resource writes an opentelemetry.proto.resource.v1.Resource field.
+func (e *Encoder) Resource(field int, attrs []KeyValue) {
+	e.Message(field, func(e *Encoder) {
+		for _, a := range attrs {
+			e.KeyValue(1, a.Key, a.Value)
+		}
+	})
+}
+
+// Scope writes an opentelemetry.proto.common.v1.InstrumentationScope field.
+func (e *Encoder) Scope(field int, name, version string) {
+	e.Message(field, func(e *Encoder) {
+		if name != "" {
+			e.String(1, name)
+		}
+		if version != "" {
+			e.String(2, version)
+		}
+	})
+}
+
+// AnyValue writes an opentelemetry.proto.common.v1.AnyValue field. Go
+// values map to the closest OTLP type; anything else is formatted with
+// fmt.Sprint.
+func (e *Encoder) AnyValue(field int, v any) {
+	e.Message(field, func(e *Encoder) { e.anyValue(v) })
+}
+
+func (e *Encoder) anyValue(v any) {
+	switch v := v.(type) {
+	case nil:
+		return
+	case string:
+		e.String(1, v)
+	case bool:
+		e.Bool(2, v)
+	case int:
+		e.Int64(3, int64(v))
+	case int8:
+		e.Int64(3, int64(v))
+	case int16:
+		e.Int64(3, int64(v))
+	case int32:
+		e.Int64(3, int64(v))
+	case int64:
+		e.Int64(3, v)
+	case uint:
+		e.Int64(3, int64(v))
+	case uint8:
+		e.Int64(3, int64(v))
+	case uint16:
+		e.Int64(3, int64(v))
+	case uint32:
+		e.Int64(3, int64(v))
+	case uint64:
+		e.Int64(3, int64(v))
+	case float32:
+		e.Double(4, float64(v))
+	case float64:
+		e.Double(4, v)
+	case []byte:
+		e.BytesField(7, v)
+	case time.Time:
+		e.String(1, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		e.String(1, v.String())
+	case error:
+		e.String(1, v.Error())
+	case fmt.Stringer:
+		e.String(1, v.String())
+	default:
+		rv := reflect.ValueOf(v)
+		switch {
+		case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+			e.Message(5, func(e *Encoder) {
+				for i := range rv.Len() {
+					e.AnyValue(1, rv.Index(i).Interface())
+				}
+			})
+		case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+			keys := rv.MapKeys()
+			slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+			e.Message(6, func(e *Encoder) {
+				for _, k := range keys {
+					e.KeyValue(1, k.String(), rv.MapIndex(k).Interface())
+				}
+			})
+		default:
+			e.String(1, fmt.Sprint(v))
+		}
+	}
+}
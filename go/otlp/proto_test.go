@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestEncoderWireFormat(t *testing.T) {
+	var e Encoder
+	e.KeyValue(1, "a", "b")
+	e.KeyValue(1, "n", 150)
+	e.Fixed64(2, 1)
+	e.Message(3, func(e *Encoder) {
+		e.AnyValue(1, []any{true, 1.5})
+	})
+
+	want := "0a080a016112030a0162" + // KeyValue{a: "b"}
+		"0a080a016e1203189601" + // KeyValue{n: 150}
+		"110100000000000000" + // fixed64 1
+		"1a130a112a0f0a021001" + "0a0921000000000000f83f" // {AnyValue{array [true, 1.5]}}
+	if got := hex.EncodeToString(e.Bytes()); got != want {
+		t.Fatalf("encoded\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestEncoderScrub(t *testing.T) {
+	e := Encoder{Scrub: func(key string, v any) (any, bool) {
+		if key == "drop" {
+			return nil, false
+		}
+		return "x", true
+	}}
+	e.Resource(1, []KeyValue{{Key: "drop", Value: "secret"}, {Key: "a", Value: "b"}})
+	var want Encoder
+	want.Resource(1, []KeyValue{{Key: "a", Value: "x"}})
+	if !bytes.Equal(e.Bytes(), want.Bytes()) {
+		t.Errorf("scrubbed resource = %x, want %x", e.Bytes(), want.Bytes())
+	}
+}
+
+func TestEncoderPacked(t *testing.T) {
+	var e Encoder
+	e.PackedFixed64(6, []uint64{1, 2})
+	e.PackedDouble(7, []float64{1.5})
+	want := "3210" + "0100000000000000" + "0200000000000000" + // bucket counts
+		"3a08" + "000000000000f83f" // bounds
+	if got := hex.EncodeToString(e.Bytes()); got != want {
+		t.Errorf("encoded\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestEncoderLongMessageLengthPrefix(t *testing.T) {
+	var e Encoder
+	long := bytes.Repeat([]byte("x"), 300)
+	e.Message(1, func(e *Encoder) { e.BytesField(1, long) })
+	// 300+3 bytes of body need a two-byte length prefix.
+	if got := e.Bytes()[:3]; !bytes.Equal(got, []byte{0x0a, 0xaf, 0x02}) {
+		t.Errorf("prefix = %x", got)
+	}
+	if len(e.Bytes()) != 3+303 {
+		t.Errorf("length = %d", len(e.Bytes()))
+	}
+}
+
+func TestAnyValueFallbacks(t *testing.T) {
+	for _, v := range []any{errors.New("boom"), map[string]int{"b": 2, "a": 1}, struct{ X int }{1}} {
+		var e Encoder
+		e.AnyValue(1, v)
+		if len(e.Bytes()) < 3 {
+			t.Errorf("%v encoded to %x", v, e.Bytes())
+		}
+	}
+	var e Encoder
+	e.AnyValue(1, map[string]int{"b": 2, "a": 1})
+	// Map keys are sorted so output is deterministic.
+	if i, j := bytes.Index(e.Bytes(), []byte("a")), bytes.Index(e.Bytes(), []byte("b")); i > j {
+		t.Errorf("map keys not sorted: %x", e.Bytes())
+	}
+}
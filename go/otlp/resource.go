@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlp
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/platform"
+	"github.com/provide-io/provide-foundation/go/version"
+)
+
+// KeyValue is an attribute of a resource, scope or telemetry item.
+type KeyValue struct {
+	Key   string
+	Value any
+}
+
+// ServiceNameKey is the resource attribute naming the service.
+const ServiceNameKey = "service.name"
+
+// ResourceFromEnv returns resource attributes from OTEL_RESOURCE_ATTRIBUTES
+// and OTEL_SERVICE_NAME, on top of extra. Environment values win, and
+// service.name defaults to "unknown_service:<executable>" as the
+// specification requires. host.name, host.arch and os.type default to
+// those of platform.Current(), and service.version to the version the
+// build was stamped with, from the version package, alongside
+// process.runtime.name and process.runtime.version.
+func ResourceFromEnv(extra ...KeyValue) []KeyValue {
+	attrs := append([]KeyValue(nil), extra...)
+	index := func(k string) int {
+		for i := range attrs {
+			if attrs[i].Key == k {
+				return i
+			}
+		}
+		return -1
+	}
+	set := func(k string, v any) {
+		if i := index(k); i >= 0 {
+			attrs[i].Value = v
+			return
+		}
+		attrs = append(attrs, KeyValue{Key: k, Value: v})
+	}
+	setDefault := func(k, v string) {
+		if index(k) < 0 && v != "" {
+			attrs = append(attrs, KeyValue{Key: k, Value: v})
+		}
+	}
+	for _, pair := range strings.Split(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"), ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if k = strings.TrimSpace(k); !ok || k == "" {
+			continue
+		}
+		if dec, err := url.QueryUnescape(strings.TrimSpace(v)); err == nil {
+			set(k, dec)
+		}
+	}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		set(ServiceNameKey, name)
+	}
+	setDefault(ServiceNameKey, "unknown_service:"+filepath.Base(os.Args[0]))
+	p := platform.Current()
+	setDefault("host.name", p.Hostname)
+	setDefault("host.arch", p.Arch)
+	setDefault("os.type", p.OS)
+	build := version.Get()
+	if build.Stamped() {
+		setDefault("service.version", build.Version)
+	}
+	setDefault("process.runtime.name", "go")
+	setDefault("process.runtime.version", build.GoVersion)
+	return attrs
+}
@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package platform describes the machine a process runs on: its operating
+// system and architecture under the names the Python platform module uses,
+// and whether it runs in a container, under WSL or in CI:
+//
+//	info := platform.Current()
+//	fmt.Println(info)            // linux/amd64
+//	fmt.Println(info.Platform()) // linux_amd64
+//
+// The logger attaches the host and platform to production records, and
+// the OTLP exporters add them to their resource, so every signal says
+// where it came from.
+package platform
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Info describes the current machine.
+type Info struct {
+	// OS is the normalized operating system: linux, darwin, windows, ...
+	OS string
+	// Arch is the normalized architecture: amd64, arm64, x86, ...
+	Arch string
+	// Hostname is the host name, or empty if it is unknown.
+	Hostname string
+	// CPUs is the number of logical CPUs usable by the process.
+	CPUs int
+	// Container reports whether the process runs in a container: Docker,
+	// Podman, Kubernetes or another runtime visible in /proc.
+	Container bool
+	// WSL reports whether the process runs under the Windows Subsystem for
+	// Linux.
+	WSL bool
+	// CI names the continuous integration system running the process, such
+	// as "github_actions", or is "unknown" for one announced only by
+	// CI=true. It is empty outside CI.
+	CI string
+}
+
+// String returns the platform as "os/arch", e.g. "darwin/arm64".
+func (i Info) String() string { return i.OS + "/" + i.Arch }
+
+// Platform returns the platform as "os_arch", e.g. "linux_amd64", the form
+// of the Python get_platform_string used in artifact names.
+func (i Info) Platform() string { return i.OS + "_" + i.Arch }
+
+// Fields returns the key/value pairs that describe the platform in log
+// records: hostname and platform, plus container, wsl and ci when set.
+func (i Info) Fields() []any {
+	kv := []any{"hostname", i.Hostname, "platform", i.String()}
+	if i.Container {
+		kv = append(kv, "container", true)
+	}
+	if i.WSL {
+		kv = append(kv, "wsl", true)
+	}
+	if i.CI != "" {
+		kv = append(kv, "ci", i.CI)
+	}
+	return kv
+}
+
+var current = sync.OnceValue(Detect)
+
+// Current returns the Info of this machine, detected once.
+func Current() Info { return current() }
+
+// Detect inspects the machine. Current caches its result; call Detect to
+// see changes to the environment.
+func Detect() Info {
+	return detect(system{
+		goos:     runtime.GOOS,
+		goarch:   runtime.GOARCH,
+		lookup:   os.LookupEnv,
+		readFile: os.ReadFile,
+		exists: func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		},
+	})
+}
+
+// system abstracts what detection reads, for tests.
+type system struct {
+	goos, goarch string
+	lookup       func(string) (string, bool)
+	readFile     func(string) ([]byte, error)
+	exists       func(string) bool
+}
+
+func detect(s system) Info {
+	info := Info{
+		OS:   NormalizeOS(s.goos),
+		Arch: NormalizeArch(s.goarch),
+		CPUs: runtime.NumCPU(),
+	}
+	info.Hostname, _ = os.Hostname()
+	if info.OS == "linux" {
+		info.Container = inContainer(s)
+		info.WSL = inWSL(s)
+	}
+	info.CI = ciName(s.lookup)
+	return info
+}
+
+var osNames = map[string]string{
+	"macos":  "darwin",
+	"macosx": "darwin",
+	"osx":    "darwin",
+	"win32":  "windows",
+	"win64":  "windows",
+	"cygwin": "windows",
+}
+
+var archNames = map[string]string{
+	"x86_64":  "amd64",
+	"x64":     "amd64",
+	"aarch64": "arm64",
+	"armv8":   "arm64",
+	"386":     "x86",
+	"i486":    "x86",
+	"i586":    "x86",
+	"i686":    "x86",
+	"armv7l":  "arm",
+	"armv6l":  "arm",
+}
+
+// NormalizeOS returns the canonical, lowercase name of an operating
+// system reported by uname, Python or GOOS: "Darwin" and "macos" become
+// "darwin", "win32" becomes "windows". Unknown names are lowercased.
+func NormalizeOS(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if n, ok := osNames[name]; ok {
+		return n
+	}
+	return name
+}
+
+// NormalizeArch returns the canonical, lowercase name of an architecture
+// reported by uname, Python or GOARCH: "x86_64" becomes "amd64",
+// "aarch64" becomes "arm64" and "i686" becomes "x86". Unknown names are
+// lowercased.
+func NormalizeArch(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if n, ok := archNames[name]; ok {
+		return n
+	}
+	return name
+}
+
+// Normalize normalizes an operating system and an architecture, as from a
+// platform string such as "Linux/x86_64".
+func Normalize(osName, arch string) (string, string) {
+	return NormalizeOS(osName), NormalizeArch(arch)
+}
+
+func inContainer(s system) bool {
+	if s.exists("/.dockerenv") || s.exists("/run/.containerenv") {
+		return true
+	}
+	if _, ok := s.lookup("KUBERNETES_SERVICE_HOST"); ok {
+		return true
+	}
+	// systemd-nspawn, LXC and Podman set container in the environment of
+	// the init process they start.
+	if v, ok := s.lookup("container"); ok && v != "" {
+		return true
+	}
+	cgroup, err := s.readFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	for _, marker := range []string{"docker", "kubepods", "containerd", "lxc", "libpod"} {
+		if strings.Contains(string(cgroup), marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func inWSL(s system) bool {
+	if _, ok := s.lookup("WSL_DISTRO_NAME"); ok {
+		return true
+	}
+	release, err := s.readFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+// ciSystems maps the variable each CI system sets to its name.
+var ciSystems = []struct{ env, name string }{
+	{"GITHUB_ACTIONS", "github_actions"},
+	{"GITLAB_CI", "gitlab"},
+	{"CIRCLECI", "circleci"},
+	{"BUILDKITE", "buildkite"},
+	{"JENKINS_URL", "jenkins"},
+	{"TF_BUILD", "azure_pipelines"},
+	{"TRAVIS", "travis"},
+	{"BITBUCKET_BUILD_NUMBER", "bitbucket"},
+	{"TEAMCITY_VERSION", "teamcity"},
+	{"CODEBUILD_BUILD_ID", "codebuild"},
+}
+
+func ciName(lookup func(string) (string, bool)) string {
+	for _, c := range ciSystems {
+		if v, ok := lookup(c.env); ok && v != "" {
+			return c.name
+		}
+	}
+	switch v, _ := lookup("CI"); strings.ToLower(v) {
+	case "", "0", "false", "no":
+		return ""
+	}
+	return "unknown"
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package platform
+
+import (
+	"io/fs"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	for _, tc := range []struct{ os, arch, wantOS, wantArch string }{
+		{"Darwin", "arm64", "darwin", "arm64"},
+		{"macos", "x86_64", "darwin", "amd64"},
+		{"Linux", "aarch64", "linux", "arm64"},
+		{"linux", "i686", "linux", "x86"},
+		{"win32", "AMD64", "windows", "amd64"},
+		{"windows", "386", "windows", "x86"},
+		{"FreeBSD", "riscv64", "freebsd", "riscv64"},
+	} {
+		if os, arch := Normalize(tc.os, tc.arch); os != tc.wantOS || arch != tc.wantArch {
+			t.Errorf("Normalize(%s, %s) = %s, %s", tc.os, tc.arch, os, arch)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	info := Current()
+	if info.OS != runtime.GOOS || info.CPUs < 1 {
+		t.Errorf("Current() = %+v", info)
+	}
+	if info.String() != info.OS+"/"+info.Arch || info.Platform() != info.OS+"_"+info.Arch {
+		t.Errorf("String = %s, Platform = %s", info, info.Platform())
+	}
+}
+
+// fakeSystem is a Linux machine with the given environment and files.
+func fakeSystem(env, files map[string]string) system {
+	return system{
+		goos:   "linux",
+		goarch: "amd64",
+		lookup: func(k string) (string, bool) {
+			v, ok := env[k]
+			return v, ok
+		},
+		readFile: func(path string) ([]byte, error) {
+			if v, ok := files[path]; ok {
+				return []byte(v), nil
+			}
+			return nil, fs.ErrNotExist
+		},
+		exists: func(path string) bool {
+			_, ok := files[path]
+			return ok
+		},
+	}
+}
+
+func TestDetectContainer(t *testing.T) {
+	for name, tc := range map[string]struct {
+		env, files map[string]string
+		want       bool
+	}{
+		"bare metal": {files: map[string]string{"/proc/1/cgroup": "0::/init.scope\n"}},
+		"docker":     {files: map[string]string{"/.dockerenv": ""}, want: true},
+		"podman":     {files: map[string]string{"/run/.containerenv": ""}, want: true},
+		"kubernetes": {env: map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1"}, want: true},
+		"nspawn":     {env: map[string]string{"container": "systemd-nspawn"}, want: true},
+		"cgroup":     {files: map[string]string{"/proc/1/cgroup": "12:pids:/kubepods/besteffort/pod1\n"}, want: true},
+	} {
+		if got := detect(fakeSystem(tc.env, tc.files)).Container; got != tc.want {
+			t.Errorf("%s: Container = %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+func TestDetectWSL(t *testing.T) {
+	wsl := fakeSystem(nil, map[string]string{"/proc/sys/kernel/osrelease": "5.15.90.1-microsoft-standard-WSL2\n"})
+	native := fakeSystem(nil, map[string]string{"/proc/sys/kernel/osrelease": "6.8.0-45-generic\n"})
+	if !detect(wsl).WSL || detect(native).WSL {
+		t.Error("WSL detection failed")
+	}
+	mac := fakeSystem(map[string]string{"WSL_DISTRO_NAME": "Ubuntu"}, map[string]string{"/.dockerenv": ""})
+	mac.goos = "darwin"
+	if info := detect(mac); info.WSL || info.Container {
+		t.Errorf("darwin detected as %+v", info)
+	}
+}
+
+func TestDetectCI(t *testing.T) {
+	for want, env := range map[string]map[string]string{
+		"":               {"CI": "false"},
+		"github_actions": {"CI": "true", "GITHUB_ACTIONS": "true"},
+		"gitlab":         {"GITLAB_CI": "true"},
+		"jenkins":        {"JENKINS_URL": "https://ci.example.com/"},
+		"unknown":        {"CI": "1"},
+	} {
+		if got := detect(fakeSystem(env, nil)).CI; got != want {
+			t.Errorf("CI with %v = %q, want %q", env, got, want)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	info := Info{OS: "linux", Arch: "arm64", Hostname: "web-1", Container: true, CI: "gitlab"}
+	want := []any{"hostname", "web-1", "platform", "linux/arm64", "container", true, "ci", "gitlab"}
+	if got := info.Fields(); !slices.Equal(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,323 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package process runs subprocesses with deadlines, environment and
+// working directory control, and output capture, the Go port of the
+// Python process module:
+//
+//	res, err := process.Run(ctx, []string{"git", "status", "--short"},
+//		process.WithDir(repo), process.WithTimeout(30*time.Second))
+//
+// Run returns an *ExitError for a non-zero exit status and an error
+// matching ErrTimeout when the process outlives its timeout. With
+// WithStreaming, each line of output is logged as it is written, so a
+// long build shows progress in the service's own logs.
+package process
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// waitDelay bounds how long Run waits for output after the process is
+// killed, in case a child it started keeps the pipes open.
+const waitDelay = 2 * time.Second
+
+// ErrTimeout is matched by errors.Is when a process is killed at the end
+// of its timeout. The error also matches context.DeadlineExceeded.
+var ErrTimeout = errors.New("process: timeout")
+
+// Stream identifies an output stream of a process.
+type Stream int
+
+// Streams.
+const (
+	Stdout Stream = iota + 1
+	Stderr
+)
+
+// String returns "stdout" or "stderr".
+func (s Stream) String() string {
+	if s == Stderr {
+		return "stderr"
+	}
+	return "stdout"
+}
+
+// Result is a finished process.
+type Result struct {
+	// Args is the command and its arguments.
+	Args []string
+	// ExitCode is the exit status, or -1 if the process was killed by a
+	// signal or did not start.
+	ExitCode int
+	// Stdout is the standard output, or both streams interleaved with
+	// WithCombinedOutput.
+	Stdout []byte
+	// Stderr is the standard error, empty with WithCombinedOutput.
+	Stderr []byte
+	// Duration is the time from start to exit.
+	Duration time.Duration
+}
+
+// ExitError is returned by Run for a process that exits with a non-zero
+// status.
+type ExitError struct {
+	Args     []string
+	ExitCode int
+	// Stderr is the captured standard error, for the error message.
+	Stderr []byte
+}
+
+// Error reports the command, its status and the last line of its
+// standard error.
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("process: %s exited with code %d", e.Args[0], e.ExitCode)
+	if line := lastLine(e.Stderr); line != "" {
+		msg += ": " + line
+	}
+	return msg
+}
+
+func lastLine(b []byte) string {
+	s := strings.TrimRight(string(b), "\r\n")
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(s)
+}
+
+// Option configures Run.
+type Option func(*options)
+
+type options struct {
+	dir      string
+	env      map[string]string
+	inherit  bool
+	timeout  time.Duration
+	stdin    io.Reader
+	combined bool
+	lines    func(Stream, string)
+	stream   bool
+	logger   *log.Logger
+}
+
+// WithDir sets the working directory. The default is the current one.
+func WithDir(dir string) Option {
+	return func(o *options) { o.dir = dir }
+}
+
+// WithEnv sets environment variables for the process, on top of the
+// inherited environment.
+func WithEnv(env map[string]string) Option {
+	return func(o *options) {
+		if o.env == nil {
+			o.env = make(map[string]string, len(env))
+		}
+		for k, v := range env {
+			o.env[k] = v
+		}
+	}
+}
+
+// WithInheritEnv sets whether the process inherits the environment of
+// the current one. The default is true; when false, the process sees only
+// the variables of WithEnv.
+func WithInheritEnv(on bool) Option {
+	return func(o *options) { o.inherit = on }
+}
+
+// WithTimeout kills the process if it runs longer than d.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// WithStdin feeds r to the standard input of the process.
+func WithStdin(r io.Reader) Option {
+	return func(o *options) { o.stdin = r }
+}
+
+// WithCombinedOutput captures standard output and standard error
+// together, in the order written, in Result.Stdout.
+func WithCombinedOutput() Option {
+	return func(o *options) { o.combined = true }
+}
+
+// WithLineHandler calls fn with each line of output as it is written,
+// without the line ending; with WithCombinedOutput every line is reported
+// as Stdout. Calls are never concurrent. Output is captured in the Result
+// as well.
+func WithLineHandler(fn func(s Stream, line string)) Option {
+	return func(o *options) { o.lines = fn }
+}
+
+// WithStreaming logs each line of output as it is written, as a
+// "process_output" record at info level for standard output and warning
+// level for standard error.
+func WithStreaming() Option {
+	return func(o *options) { o.stream = true }
+}
+
+// WithLogger sets the logger for process records. The default is
+// log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(o *options) { o.logger = l }
+}
+
+func (o *options) log() *log.Logger {
+	if o.logger != nil {
+		return o.logger
+	}
+	return log.Default()
+}
+
+// Run runs the command args[0] with the arguments args[1:] and waits for
+// it to exit. It returns the Result even when the error is an *ExitError
+// or a timeout, so the output can be inspected. The process is killed
+// when ctx is done; on Unix it runs in a process group of its own, and the
+// whole group is killed.
+func Run(ctx context.Context, args []string, opts ...Option) (*Result, error) {
+	if len(args) == 0 {
+		return nil, errors.New("process: empty command")
+	}
+	o := options{inherit: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = o.dir
+	cmd.Env = o.environ()
+	cmd.Stdin = o.stdin
+	cmd.WaitDelay = waitDelay
+	killGroup(cmd)
+
+	var stdout, stderr bytes.Buffer
+	var mu sync.Mutex // serializes line handler calls across streams
+	out := o.writer(ctx, &stdout, Stdout, &mu)
+	if o.combined {
+		cmd.Stdout, cmd.Stderr = out, out
+	} else {
+		cmd.Stdout = out
+		cmd.Stderr = o.writer(ctx, &stderr, Stderr, &mu)
+	}
+
+	logger := o.log()
+	logger.DebugCtx(ctx, "process_started", "command", args, "dir", o.dir)
+	start := time.Now()
+	err := cmd.Run()
+	for _, w := range []io.Writer{cmd.Stdout, cmd.Stderr} {
+		if lw, ok := w.(*lineWriter); ok {
+			lw.flush()
+		}
+	}
+	res := &Result{
+		Args:     args,
+		ExitCode: -1,
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		Duration: time.Since(start),
+	}
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	logger.DebugCtx(ctx, "process_exited", "command", args, "exit_code", res.ExitCode, "duration", res.Duration)
+
+	switch {
+	case err == nil:
+		return res, nil
+	case o.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return res, fmt.Errorf("%w: %s killed after %s: %w", ErrTimeout, args[0], o.timeout, context.DeadlineExceeded)
+	case ctx.Err() != nil:
+		return res, fmt.Errorf("process: %s: %w", args[0], ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && res.ExitCode > 0 {
+		return res, &ExitError{Args: args, ExitCode: res.ExitCode, Stderr: res.Stderr}
+	}
+	return res, fmt.Errorf("process: %s: %w", args[0], err)
+}
+
+func (o *options) environ() []string {
+	if !o.inherit && o.env == nil {
+		return []string{}
+	}
+	var env []string
+	if o.inherit {
+		if o.env == nil {
+			return nil // exec inherits os.Environ
+		}
+		env = os.Environ()
+	}
+	for k, v := range o.env {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+// writer returns the writer capturing stream s into buf, splitting it into
+// lines if a line handler or streaming is set.
+func (o *options) writer(ctx context.Context, buf *bytes.Buffer, s Stream, mu *sync.Mutex) io.Writer {
+	if o.lines == nil && !o.stream {
+		return buf
+	}
+	logger := o.log()
+	return &lineWriter{buf: buf, fn: func(line string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if o.stream {
+			level := log.LevelInfo
+			if s == Stderr {
+				level = log.LevelWarning
+			}
+			logger.LogCtx(ctx, level, "process_output", "stream", s.String(), "line", line)
+		}
+		if o.lines != nil {
+			o.lines(s, line)
+		}
+	}}
+}
+
+// lineWriter captures output and hands each complete line to fn.
+type lineWriter struct {
+	buf     *bytes.Buffer
+	fn      func(string)
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.fn(string(bytes.TrimSuffix(w.partial[:i], []byte{'\r'})))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush hands a last line without a line ending to fn.
+func (w *lineWriter) flush() {
+	if len(w.partial) > 0 {
+		w.fn(string(w.partial))
+		w.partial = nil
+	}
+}
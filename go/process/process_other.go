@@ -0,0 +1,11 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !unix
+
+package process
+
+import "os/exec"
+
+// killGroup leaves cancellation to kill only the command itself.
+func killGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package process
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func sh(t *testing.T, script string) []string {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	return []string{"sh", "-c", script}
+}
+
+func TestRunCapturesOutput(t *testing.T) {
+	res, err := Run(context.Background(), sh(t, "echo out; echo err >&2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Stdout) != "out\n" || string(res.Stderr) != "err\n" || res.ExitCode != 0 {
+		t.Errorf("Run = %+v", res)
+	}
+}
+
+func TestRunCombinedOutput(t *testing.T) {
+	res, err := Run(context.Background(), sh(t, "echo one; echo two >&2; echo three"), WithCombinedOutput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Stdout) != "one\ntwo\nthree\n" || len(res.Stderr) != 0 {
+		t.Errorf("Run = %q, %q", res.Stdout, res.Stderr)
+	}
+}
+
+func TestRunDirEnvStdin(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PROCESS_TEST_INHERITED", "yes")
+	res, err := Run(context.Background(), sh(t, `pwd; echo "$GREETING $PROCESS_TEST_INHERITED"; cat`),
+		WithDir(dir), WithEnv(map[string]string{"GREETING": "hello"}), WithStdin(strings.NewReader("input")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := real + "\nhello yes\ninput"
+	if string(res.Stdout) != want {
+		t.Errorf("Stdout = %q, want %q", res.Stdout, want)
+	}
+
+	res, err = Run(context.Background(), sh(t, `echo "$GREETING-$PROCESS_TEST_INHERITED"`),
+		WithEnv(map[string]string{"GREETING": "hi", "PATH": os.Getenv("PATH")}), WithInheritEnv(false))
+	if err != nil || string(res.Stdout) != "hi-\n" {
+		t.Errorf("without inherited env = %q, %v", res.Stdout, err)
+	}
+}
+
+func TestRunExitError(t *testing.T) {
+	res, err := Run(context.Background(), sh(t, "echo partial; echo first >&2; echo 'bad thing' >&2; exit 3"))
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode != 3 {
+		t.Fatalf("Run = %v, want ExitError with code 3", err)
+	}
+	if !strings.HasSuffix(err.Error(), "exited with code 3: bad thing") {
+		t.Errorf("message = %q", err)
+	}
+	if res == nil || res.ExitCode != 3 || string(res.Stdout) != "partial\n" {
+		t.Errorf("Result = %+v", res)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	start := time.Now()
+	res, err := Run(context.Background(), sh(t, "echo started; sleep 10"), WithTimeout(100*time.Millisecond))
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run = %v, want ErrTimeout", err)
+	}
+	if errorsx.Classify(err) != errorsx.Timeout {
+		t.Errorf("class = %s, want timeout", errorsx.Classify(err))
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Run took %s", time.Since(start))
+	}
+	if string(res.Stdout) != "started\n" {
+		t.Errorf("Stdout = %q", res.Stdout)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, sh(t, "true")); !errors.Is(err, context.Canceled) || errors.Is(err, ErrTimeout) {
+		t.Errorf("Run(canceled) = %v", err)
+	}
+}
+
+func TestRunNotFound(t *testing.T) {
+	res, err := Run(context.Background(), []string{"definitely-not-a-command-xyz"})
+	if !errors.Is(err, exec.ErrNotFound) || res.ExitCode != -1 {
+		t.Errorf("Run = %+v, %v", res, err)
+	}
+	if _, err := Run(context.Background(), nil); err == nil {
+		t.Error("Run(nil) succeeded")
+	}
+}
+
+func TestLineHandler(t *testing.T) {
+	type line struct {
+		s    Stream
+		text string
+	}
+	var got []line
+	res, err := Run(context.Background(), sh(t, `printf 'a\r\nb\n'; echo c >&2; printf 'tail'`),
+		WithLineHandler(func(s Stream, text string) { got = append(got, line{s, text}) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout []string
+	for _, l := range got {
+		if l.s == Stdout {
+			stdout = append(stdout, l.text)
+		}
+	}
+	if !reflect.DeepEqual(stdout, []string{"a", "b", "tail"}) || len(got) != 4 {
+		t.Errorf("lines = %v", got)
+	}
+	if string(res.Stdout) != "a\r\nb\ntail" {
+		t.Errorf("Stdout = %q", res.Stdout)
+	}
+}
+
+func TestStreamingLogsLines(t *testing.T) {
+	rec := logtest.Capture(t, log.WithLevel(log.LevelDebug))
+	_, err := Run(context.Background(), sh(t, "echo building; echo warning >&2"), WithStreaming())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := rec.WithEvent("process_output")
+	if out.WithField("line", "building").WithLevel(log.LevelInfo).Count() != 1 ||
+		out.WithField("line", "warning").WithField("stream", "stderr").WithLevel(log.LevelWarning).Count() != 1 {
+		t.Errorf("records = %v", rec.Records().Events())
+	}
+	if rec.WithEvent("process_exited").WithField("exit_code", 0).Count() != 1 {
+		t.Error("no process_exited record")
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package profiling
+
+import (
+	"context"
+
+	"github.com/provide-io/provide-foundation/go/config"
+	"github.com/provide-io/provide-foundation/go/hub"
+)
+
+// Name is the hub component name of the profiler.
+const Name = "profiling"
+
+// Capability is the hub plugin capability the package provides.
+const Capability = "profiling"
+
+func init() {
+	hub.RegisterPlugin(hub.Plugin{
+		Name:         Name,
+		Description:  "Runtime profiling: pprof endpoints and periodic profile capture.",
+		Capabilities: []string{Capability},
+		Register:     func(h *hub.Hub) error { return Register(h) },
+	})
+}
+
+// Register adds a lazily built *Profiler to h as the "profiling"
+// component. It is configured from the environment with config.Bind, so
+// each environment toggles it with FOUNDATION_PROFILING_ENDPOINTS and
+// FOUNDATION_PROFILING_INTERVAL, and starts capturing when first
+// retrieved; Hub.Close stops it.
+//
+//	prof, err := hub.Get[*profiling.Profiler](ctx, h, hub.Component, profiling.Name)
+//	admin.Handle("/debug/pprof/", prof.Handler())
+func Register(h *hub.Hub, opts ...Option) error {
+	return h.RegisterLazy(hub.Component, Name, func(ctx context.Context) (any, error) {
+		var cfg Config
+		if err := config.Bind(&cfg); err != nil {
+			return nil, err
+		}
+		p, err := New(cfg, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return p, p.OnStart(ctx)
+	}, hub.WithDescription("Runtime profiler"))
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package profiling
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/hub"
+)
+
+func TestHubComponent(t *testing.T) {
+	t.Setenv("FOUNDATION_PROFILING_ENDPOINTS", "true")
+	t.Setenv("FOUNDATION_PROFILING_INTERVAL", "1h")
+	t.Setenv("FOUNDATION_PROFILING_DIR", t.TempDir())
+
+	h := hub.New()
+	if err := h.LoadPlugins(); err != nil {
+		t.Fatal(err)
+	}
+	e, ok := h.Entry(hub.Component, Name)
+	if !ok || e.Metadata[hub.MetaPlugin] != Name || !hub.HasCapability(Capability) {
+		t.Fatalf("entry = %+v", e)
+	}
+	ctx := context.Background()
+	p, err := hub.Get[*Profiler](ctx, h, hub.Component, Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := p.Config(); !cfg.Endpoints || !slices.Equal(cfg.Profiles, []string{"cpu", "heap"}) {
+		t.Errorf("config = %+v", cfg)
+	}
+	rec := httptest.NewRecorder()
+	p.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d", rec.Code)
+	}
+	if p.stop == nil {
+		t.Error("capture not started")
+	}
+	if err := h.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if p.stop != nil {
+		t.Error("capture not stopped by Close")
+	}
+}
@@ -0,0 +1,256 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package profiling is the runtime profiling subsystem: the pprof
+// endpoints behind a configuration flag, and CPU and heap profiles
+// captured periodically to a directory or any other Sink, such as an
+// object storage bucket.
+//
+//	var cfg profiling.Config
+//	err := config.Bind(&cfg)
+//	prof, err := profiling.New(cfg)
+//	mux.Handle("/debug/pprof/", prof.Handler())
+//	err = prof.OnStart(ctx)
+//	defer prof.OnStop(ctx)
+//
+// The package registers a hub plugin, so a binary that imports it gets a
+// "profiling" component configured from the FOUNDATION_PROFILING_*
+// variables, off unless an environment turns it on.
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	rpprof "runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// ProfileCPU names the CPU profile, which unlike the runtime/pprof
+// profiles records for Config.CPUDuration.
+const ProfileCPU = "cpu"
+
+// ErrNoSink is returned by New when capture is enabled without a
+// directory or sink to store the profiles in.
+var ErrNoSink = errors.New("profiling: capture needs a directory or sink")
+
+// Config configures a Profiler, bindable with config.Bind. The zero value
+// disables both the endpoints and capture.
+type Config struct {
+	Endpoints   bool          `config:"endpoints" env:"FOUNDATION_PROFILING_ENDPOINTS" desc:"Serve the pprof endpoints from Handler."`
+	Interval    time.Duration `config:"interval" env:"FOUNDATION_PROFILING_INTERVAL" desc:"How often profiles are captured; 0 disables capture."`
+	Profiles    []string      `config:"profiles" env:"FOUNDATION_PROFILING_PROFILES" default:"cpu,heap" desc:"Profiles captured: cpu or a runtime/pprof profile such as heap, goroutine or mutex."`
+	CPUDuration time.Duration `config:"cpu_duration" env:"FOUNDATION_PROFILING_CPU_DURATION" default:"10s" validate:"min=1ms" desc:"How long each CPU profile records."`
+	Dir         string        `config:"dir" env:"FOUNDATION_PROFILING_DIR" desc:"Directory captured profiles are written to when no sink is set."`
+}
+
+// Sink stores a captured profile under a name such as
+// "heap-20260102T150405Z.pprof". Implement it to upload profiles to
+// object storage.
+type Sink interface {
+	Store(ctx context.Context, name string, data []byte) error
+}
+
+// SinkFunc adapts a function to a Sink.
+type SinkFunc func(ctx context.Context, name string, data []byte) error
+
+// Store calls f.
+func (f SinkFunc) Store(ctx context.Context, name string, data []byte) error {
+	return f(ctx, name, data)
+}
+
+// Dir returns a Sink writing profiles to files in dir, which is created
+// if needed.
+func Dir(dir string) Sink {
+	return SinkFunc(func(ctx context.Context, name string, data []byte) error {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, name), data, 0o644)
+	})
+}
+
+// Option configures a Profiler.
+type Option func(*Profiler)
+
+// WithSink stores captured profiles in s instead of Config.Dir.
+func WithSink(s Sink) Option {
+	return func(p *Profiler) { p.sink = s }
+}
+
+// WithLogger sets the logger for capture records. The default is
+// log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(p *Profiler) { p.logger = l }
+}
+
+// Profiler serves the pprof endpoints and captures profiles. It is safe
+// for concurrent use, and implements container.Starter and
+// container.Stopper so capture runs for the life of the application.
+type Profiler struct {
+	cfg     Config
+	sink    Sink
+	logger  *log.Logger
+	handler http.Handler
+
+	mu   sync.Mutex
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// New returns a profiler for cfg. It fails for an unknown profile name,
+// and with ErrNoSink when cfg.Interval is set without cfg.Dir or WithSink.
+func New(cfg Config, opts ...Option) (*Profiler, error) {
+	if cfg.CPUDuration <= 0 {
+		cfg.CPUDuration = 10 * time.Second
+	}
+	p := &Profiler{cfg: cfg, logger: log.Default()}
+	for _, opt := range opts {
+		opt(p)
+	}
+	for _, name := range cfg.Profiles {
+		if name != ProfileCPU && rpprof.Lookup(name) == nil {
+			return nil, fmt.Errorf("profiling: unknown profile %q", name)
+		}
+	}
+	if p.sink == nil && cfg.Dir != "" {
+		p.sink = Dir(cfg.Dir)
+	}
+	if cfg.Interval > 0 && p.sink == nil {
+		return nil, ErrNoSink
+	}
+	p.handler = http.NotFoundHandler()
+	if cfg.Endpoints {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		p.handler = mux
+	}
+	return p, nil
+}
+
+// Config returns the configuration the profiler was created with.
+func (p *Profiler) Config() Config { return p.cfg }
+
+// Handler serves the pprof endpoints under /debug/pprof/ when
+// Config.Endpoints is set, and 404 Not Found otherwise, so it can be
+// mounted unconditionally:
+//
+//	mux.Handle("/debug/pprof/", prof.Handler())
+//
+// The endpoints expose internals of the process; mount them on an admin
+// listener or behind authentication.
+func (p *Profiler) Handler() http.Handler { return p.handler }
+
+// Capture captures every configured profile once and stores it in the
+// sink. The CPU profile records for Config.CPUDuration, or until ctx is
+// done, and fails while another CPU profile, such as one requested from
+// the endpoints, is running. Every failure is returned joined.
+func (p *Profiler) Capture(ctx context.Context) error {
+	if p.sink == nil {
+		return ErrNoSink
+	}
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	var errs []error
+	for _, name := range p.cfg.Profiles {
+		data, err := p.profile(ctx, name)
+		if err == nil {
+			err = p.sink.Store(ctx, name+"-"+stamp+".pprof", data)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("profiling: %s: %w", name, err))
+			continue
+		}
+		p.logger.DebugCtx(ctx, "profile_captured", "profile", name, "bytes", len(data))
+	}
+	return errors.Join(errs...)
+}
+
+// profile records the named profile.
+func (p *Profiler) profile(ctx context.Context, name string) ([]byte, error) {
+	var buf bytes.Buffer
+	if name == ProfileCPU {
+		if err := rpprof.StartCPUProfile(&buf); err != nil {
+			return nil, err
+		}
+		t := time.NewTimer(p.cfg.CPUDuration)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+		}
+		rpprof.StopCPUProfile()
+		return buf.Bytes(), ctx.Err()
+	}
+	if name == "heap" {
+		runtime.GC() // report up-to-date live objects, as the endpoint's gc=1 does
+	}
+	if err := rpprof.Lookup(name).WriteTo(&buf, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// OnStart starts capturing every Config.Interval in the background. It
+// does nothing when capture is disabled or already running.
+func (p *Profiler) OnStart(ctx context.Context) error {
+	if p.cfg.Interval <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		return nil
+	}
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	p.stop, p.done = cancel, make(chan struct{})
+	go p.run(runCtx, p.done)
+	return nil
+}
+
+// OnStop stops capturing, interrupting a CPU profile in progress, and
+// waits for the background goroutine or ctx.
+func (p *Profiler) OnStop(ctx context.Context) error {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	stop()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Profiler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := p.Capture(ctx); err != nil && ctx.Err() == nil {
+			p.logger.WarnCtx(ctx, "profile_capture_failed", log.Err(err))
+		}
+	}
+}
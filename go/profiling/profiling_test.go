@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package profiling
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestHandlerFollowsFlag(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		p, err := New(Config{Endpoints: enabled})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		p.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+		want := http.StatusNotFound
+		if enabled {
+			want = http.StatusOK
+		}
+		if rec.Code != want {
+			t.Errorf("endpoints %v: status %d, want %d", enabled, rec.Code, want)
+		}
+	}
+}
+
+func TestNewValidates(t *testing.T) {
+	if _, err := New(Config{Profiles: []string{"heap", "bogus"}}); err == nil || !strings.Contains(err.Error(), `"bogus"`) {
+		t.Errorf("unknown profile: %v", err)
+	}
+	if _, err := New(Config{Interval: time.Minute}); !errors.Is(err, ErrNoSink) {
+		t.Errorf("no sink: %v", err)
+	}
+}
+
+func TestCaptureToDir(t *testing.T) {
+	logtest.Capture(t)
+	dir := t.TempDir()
+	p, err := New(Config{Profiles: []string{"cpu", "heap", "goroutine"}, CPUDuration: 20 * time.Millisecond, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Capture(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 3 {
+		t.Fatalf("files = %v", files)
+	}
+	for i, prefix := range []string{"cpu-", "goroutine-", "heap-"} {
+		if name := files[i].Name(); !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".pprof") {
+			t.Errorf("file %d = %s", i, name)
+		}
+	}
+}
+
+func TestPeriodicCapture(t *testing.T) {
+	logtest.Capture(t)
+	var mu sync.Mutex
+	var stored []string
+	sink := SinkFunc(func(ctx context.Context, name string, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stored = append(stored, name)
+		return nil
+	})
+	p, err := New(Config{Interval: 5 * time.Millisecond, Profiles: []string{"heap"}}, WithSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := p.OnStart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(stored)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stored %d profiles", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := p.OnStop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	n := len(stored)
+	mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(stored) != n {
+		t.Errorf("captured after OnStop")
+	}
+}
+
+func TestCaptureFailureIsLogged(t *testing.T) {
+	rec := logtest.Capture(t)
+	failed := errors.New("bucket unavailable")
+	p, err := New(Config{Interval: 5 * time.Millisecond, Profiles: []string{"heap"}},
+		WithSink(SinkFunc(func(context.Context, string, []byte) error { return failed })))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	p.OnStart(ctx)
+	defer p.OnStop(ctx)
+	deadline := time.Now().Add(2 * time.Second)
+	for rec.WithEvent("profile_capture_failed").Count() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("capture failure not logged")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package recovery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+)
+
+// Handler recovers panics in next. The client gets a 500 response unless
+// next had already started one, which is then cut short. A panic with
+// http.ErrAbortHandler is passed on, since it is how handlers abort a
+// response on purpose.
+func Handler(next http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+			o.recovered(req.Context(), v)
+			if tw.wrote {
+				panic(http.ErrAbortHandler)
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(tw, req)
+	})
+}
+
+// trackingWriter records whether a response was started.
+type trackingWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *trackingWriter) WriteHeader(code int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying writer if it supports flushing.
+func (w *trackingWriter) Flush() {
+	w.wrote = true
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *trackingWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// Transport recovers panics in the client middleware and transports after
+// it, returning a *PanicError from the request instead.
+func Transport(opts ...Option) httpx.Middleware {
+	o := newOptions(opts)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return httpx.RoundTripperFunc(func(req *http.Request) (resp *http.Response, err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					resp, err = nil, o.recovered(req.Context(), v)
+				}
+			}()
+			return next.RoundTrip(req)
+		})
+	}
+}
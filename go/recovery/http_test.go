@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package recovery
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/httpx"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestHandler(t *testing.T) {
+	rec := logtest.Capture(t)
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("boom") }))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/users/1", nil))
+	if w.Code != http.StatusInternalServerError || rec.WithEvent("panic_recovered").Count() != 1 {
+		t.Errorf("code %d, records %v", w.Code, rec.Records().Events())
+	}
+
+	started := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("boom")
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("started response: recovered %v", v)
+		}
+	}()
+	started.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestTransport(t *testing.T) {
+	logtest.Capture(t)
+	rt := Transport()(httpx.RoundTripperFunc(func(*http.Request) (*http.Response, error) { panic("boom") }))
+	resp, err := rt.RoundTrip(httptest.NewRequest("GET", "http://api.internal/", nil))
+	if resp != nil || !errors.Is(err, ErrPanic) {
+		t.Errorf("resp %v err %v", resp, err)
+	}
+}
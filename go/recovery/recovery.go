@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package recovery turns panics into errors at the boundaries where work
+// starts: goroutines, HTTP handlers and transports. A recovered panic is
+// logged with its stack and a dump of every goroutine, optionally written
+// to a crash file, and returned as a *PanicError, so one bad request or
+// job does not take the process down:
+//
+//	errc := recovery.Go(ctx, func(ctx context.Context) error {
+//		return worker.Run(ctx)
+//	}, recovery.WithCrashDir("/var/crash/app"))
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/telemetry"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+// ErrPanic is matched by errors.Is for every *PanicError.
+var ErrPanic = errors.New("recovery: panic")
+
+// PanicError is a recovered panic. Its errorsx class is Internal.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack of the panicking goroutine.
+	Stack []byte
+	// CrashFile is the crash report written for the panic, if any.
+	CrashFile string
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("recovery: panic: %v", e.Value) }
+
+func (e *PanicError) Is(target error) bool { return target == ErrPanic }
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Class returns errorsx.Internal.
+func (e *PanicError) Class() errorsx.Class { return errorsx.Internal }
+
+// Report is a crash report, logged and written to the crash file as JSON.
+type Report struct {
+	Time       time.Time `json:"time"`
+	Panic      string    `json:"panic"`
+	Stack      string    `json:"stack"`
+	Goroutines string    `json:"goroutines"`
+	TraceID    string    `json:"trace_id,omitempty"`
+	SpanID     string    `json:"span_id,omitempty"`
+	PID        int       `json:"pid"`
+	GoVersion  string    `json:"go_version"`
+}
+
+// Option configures a recovery boundary.
+type Option func(*options)
+
+type options struct {
+	logger   *log.Logger
+	crashDir string
+	onPanic  []func(ctx context.Context, r *Report)
+}
+
+// WithLogger sets the logger for panic records. The default is
+// log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(o *options) { o.logger = l }
+}
+
+// WithCrashDir writes a crash report for each panic to a new file in dir,
+// creating dir if needed, unless telemetry settings disable crash
+// reports. Failing to write it is logged, not returned.
+func WithCrashDir(dir string) Option {
+	return func(o *options) { o.crashDir = dir }
+}
+
+// WithPanicHandler calls fn with the report of each panic, after it is
+// logged, e.g. to count panics or alert.
+func WithPanicHandler(fn func(ctx context.Context, r *Report)) Option {
+	return func(o *options) { o.onPanic = append(o.onPanic, fn) }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.logger == nil {
+		o.logger = log.Default()
+	}
+	return o
+}
+
+// Do calls fn and returns its error, or a *PanicError if it panics.
+func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) (err error) {
+	o := newOptions(opts)
+	defer func() {
+		if v := recover(); v != nil {
+			err = o.recovered(ctx, v)
+		}
+	}()
+	return fn(ctx)
+}
+
+// Go runs fn in a new goroutine under Do. The returned channel receives
+// its error, or nil, and is then closed; callers that only want the panic
+// logged may ignore it.
+func Go(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) <-chan error {
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		errc <- Do(ctx, fn, opts...)
+	}()
+	return errc
+}
+
+// recovered reports a panic value caught by recover and returns it as an
+// error.
+func (o *options) recovered(ctx context.Context, v any) *PanicError {
+	stack := debug.Stack()
+	r := &Report{
+		Time:       time.Now().UTC(),
+		Panic:      fmt.Sprint(v),
+		Stack:      string(stack),
+		Goroutines: string(goroutines()),
+		PID:        os.Getpid(),
+		GoVersion:  runtime.Version(),
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.TraceID, r.SpanID = sc.TraceID.String(), sc.SpanID.String()
+	}
+	perr := &PanicError{Value: v, Stack: stack}
+	kv := []any{"panic", r.Panic, "stack", r.Stack, "goroutines", r.Goroutines}
+	if o.crashDir != "" && telemetry.Enabled(telemetry.CrashReports) {
+		path, err := writeReport(o.crashDir, r)
+		if err != nil {
+			o.logger.ErrorCtx(ctx, "crash_report_failed", log.Err(err))
+		} else {
+			perr.CrashFile = path
+			kv = append(kv, "crash_file", path)
+		}
+	}
+	o.logger.ErrorCtx(ctx, "panic_recovered", kv...)
+	for _, fn := range o.onPanic {
+		fn(ctx, r)
+	}
+	return perr
+}
+
+// maxDump bounds the goroutine dump.
+const maxDump = 8 << 20
+
+// goroutines returns the stacks of all goroutines.
+func goroutines() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxDump {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// writeReport writes r as JSON to a new crash file in dir and returns its
+// path.
+func writeReport(dir string, r *Report) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("recovery: %w", err)
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("recovery: %w", err)
+	}
+	name := fmt.Sprintf("crash-%s-%d-*.json", r.Time.Format("20060102T150405"), r.PID)
+	f, err := os.CreateTemp(dir, name)
+	if err != nil {
+		return "", fmt.Errorf("recovery: %w", err)
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return "", fmt.Errorf("recovery: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("recovery: %w", err)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/telemetry"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+func TestDo(t *testing.T) {
+	rec := logtest.Capture(t)
+	ctx := context.Background()
+	if err := Do(ctx, func(context.Context) error { return io.EOF }); err != io.EOF {
+		t.Errorf("error passed through as %v", err)
+	}
+	if rec.Records().Count() != 0 {
+		t.Errorf("logged without a panic: %v", rec.Records().Events())
+	}
+
+	err := Do(ctx, func(context.Context) error { panic("nil map") })
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "nil map" || !errors.Is(err, ErrPanic) {
+		t.Fatalf("err = %v", err)
+	}
+	if !strings.Contains(string(perr.Stack), "TestDo") || errorsx.Classify(err) != errorsx.Internal {
+		t.Errorf("stack %s class %v", perr.Stack, errorsx.Classify(err))
+	}
+	r := rec.WithEvent("panic_recovered").First()
+	if r == nil {
+		t.Fatal("no panic_recovered record")
+	}
+	if v, _ := r.Get("panic"); v != "nil map" {
+		t.Errorf("panic field = %v", v)
+	}
+	if v, _ := r.Get("goroutines"); !strings.Contains(v.(string), "goroutine ") {
+		t.Errorf("goroutines field = %v", v)
+	}
+
+	err = Do(ctx, func(context.Context) error { panic(io.ErrUnexpectedEOF) })
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("panic error not unwrapped: %v", err)
+	}
+}
+
+func TestGo(t *testing.T) {
+	logtest.Capture(t)
+	var reports []*Report
+	errc := Go(context.Background(), func(context.Context) error { panic("boom") },
+		WithPanicHandler(func(_ context.Context, r *Report) { reports = append(reports, r) }))
+	if err := <-errc; !errors.Is(err, ErrPanic) {
+		t.Errorf("err = %v", err)
+	}
+	if _, ok := <-errc; ok {
+		t.Error("channel not closed")
+	}
+	if len(reports) != 1 || reports[0].Panic != "boom" {
+		t.Errorf("reports = %v", reports)
+	}
+}
+
+func TestCrashFile(t *testing.T) {
+	logtest.Capture(t)
+	dir := t.TempDir() + "/crash"
+	sc := trace.SpanContext{TraceID: trace.NewTraceID(), SpanID: trace.NewSpanID()}
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	err := Do(ctx, func(context.Context) error { panic("boom") }, WithCrashDir(dir))
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.CrashFile == "" {
+		t.Fatalf("err = %v", err)
+	}
+	b, err := os.ReadFile(perr.CrashFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r Report
+	if err := json.Unmarshal(b, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Panic != "boom" || r.TraceID != sc.TraceID.String() || r.PID != os.Getpid() || r.Goroutines == "" {
+		t.Errorf("report = %+v", r)
+	}
+}
+
+func TestCrashReportsDisabled(t *testing.T) {
+	old := telemetry.Current()
+	t.Cleanup(func() { telemetry.Set(old) })
+	telemetry.Set(telemetry.Settings{Logs: true})
+	dir := t.TempDir() + "/crash"
+	err := Do(context.Background(), func(context.Context) error { panic("boom") }, WithCrashDir(dir))
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v", err)
+	}
+	if perr.CrashFile != "" {
+		t.Errorf("crash file %q written", perr.CrashFile)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("crash dir created: %v", err)
+	}
+}
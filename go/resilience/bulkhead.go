@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+// ErrBulkheadFull is matched by errors.Is when a bulkhead turns a call
+// away.
+var ErrBulkheadFull = errorsx.New(errorsx.Unavailable, "resilience: bulkhead is full")
+
+// BulkheadError is returned for calls a bulkhead turns away, either
+// because its queue is full or because the call waited QueueTimeout
+// without getting a slot.
+type BulkheadError struct {
+	Name string
+	// Waited is how long the call queued; zero if the queue was full.
+	Waited time.Duration
+}
+
+func (e *BulkheadError) Error() string {
+	if e.Waited == 0 {
+		return fmt.Sprintf("resilience: bulkhead %q is full", e.Name)
+	}
+	return fmt.Sprintf("resilience: bulkhead %q is full after waiting %s", e.Name, e.Waited.Round(time.Millisecond))
+}
+
+func (e *BulkheadError) Is(target error) bool { return target == ErrBulkheadFull }
+
+// Class returns errorsx.Unavailable.
+func (e *BulkheadError) Class() errorsx.Class { return errorsx.Unavailable }
+
+// Defaults matching the Python bulkhead.
+const (
+	DefaultMaxConcurrent = 10
+	DefaultMaxQueue      = 100
+	DefaultQueueTimeout  = 30 * time.Second
+)
+
+// BulkheadOption configures a Bulkhead.
+type BulkheadOption func(*Bulkhead)
+
+// WithMaxConcurrent sets how many calls may run at once. The default is
+// DefaultMaxConcurrent.
+func WithMaxConcurrent(n int) BulkheadOption {
+	return func(b *Bulkhead) { b.maxConcurrent = n }
+}
+
+// WithMaxQueue sets how many calls may wait for a slot; further calls are
+// turned away at once. Zero turns away every call that finds the
+// bulkhead busy. The default is DefaultMaxQueue.
+func WithMaxQueue(n int) BulkheadOption {
+	return func(b *Bulkhead) { b.maxQueue = n }
+}
+
+// WithQueueTimeout sets how long a call waits for a slot. Zero waits
+// until the caller's context is done. The default is DefaultQueueTimeout.
+func WithQueueTimeout(d time.Duration) BulkheadOption {
+	return func(b *Bulkhead) { b.timeout = d }
+}
+
+// Bulkhead caps the calls running at once against one resource, so a
+// slow dependency ties up a bounded number of goroutines instead of all
+// of them:
+//
+//	reports := resilience.NewBulkhead("reports-db", resilience.WithMaxConcurrent(4))
+//	err := reports.Execute(ctx, func(ctx context.Context) error {
+//		_, err := database.Exec(ctx, refreshReports)
+//		return err
+//	})
+//
+// Calls beyond the limit queue until a slot frees up, up to the queue
+// size and timeout. It is safe for concurrent use.
+type Bulkhead struct {
+	name          string
+	maxConcurrent int
+	maxQueue      int
+	timeout       time.Duration
+	slots         chan struct{}
+
+	mu      sync.Mutex
+	waiting int
+}
+
+// NewBulkhead creates a bulkhead for the named resource.
+func NewBulkhead(name string, opts ...BulkheadOption) *Bulkhead {
+	b := &Bulkhead{
+		name:          name,
+		maxConcurrent: DefaultMaxConcurrent,
+		maxQueue:      DefaultMaxQueue,
+		timeout:       DefaultQueueTimeout,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.maxConcurrent = max(b.maxConcurrent, 1)
+	b.maxQueue = max(b.maxQueue, 0)
+	b.slots = make(chan struct{}, b.maxConcurrent)
+	return b
+}
+
+// Name returns the bulkhead name.
+func (b *Bulkhead) Name() string { return b.name }
+
+// BulkheadStats is a snapshot of a bulkhead's load.
+type BulkheadStats struct {
+	Active        int
+	Waiting       int
+	MaxConcurrent int
+	MaxQueue      int
+}
+
+// Stats returns the current load.
+func (b *Bulkhead) Stats() BulkheadStats {
+	b.mu.Lock()
+	waiting := b.waiting
+	b.mu.Unlock()
+	return BulkheadStats{
+		Active:        len(b.slots),
+		Waiting:       waiting,
+		MaxConcurrent: b.maxConcurrent,
+		MaxQueue:      b.maxQueue,
+	}
+}
+
+// Execute runs fn once a slot is free. A call turned away returns a
+// *BulkheadError without running fn; if ctx ends while waiting, its error
+// is returned.
+func (b *Bulkhead) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn(ctx)
+}
+
+// Acquire takes a slot, waiting if none is free. The caller must call
+// release exactly once when done. Acquire suits wrappers where the call
+// is not a single function.
+func (b *Bulkhead) Acquire(ctx context.Context) (release func(), err error) {
+	release = sync.OnceFunc(func() { <-b.slots })
+	select {
+	case b.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	b.mu.Lock()
+	if b.waiting >= b.maxQueue {
+		b.mu.Unlock()
+		return nil, &BulkheadError{Name: b.name}
+	}
+	b.waiting++
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.waiting--
+		b.mu.Unlock()
+	}()
+
+	var expired <-chan time.Time
+	if b.timeout > 0 {
+		t := time.NewTimer(b.timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+	start := time.Now()
+	select {
+	case b.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-expired:
+		return nil, &BulkheadError{Name: b.name, Waited: time.Since(start)}
+	}
+}
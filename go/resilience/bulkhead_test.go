@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+func TestBulkheadLimitsConcurrency(t *testing.T) {
+	b := NewBulkhead("reports-db", WithMaxConcurrent(2), WithMaxQueue(1), WithQueueTimeout(0))
+	ctx := context.Background()
+	hold := make(chan struct{})
+	started := make(chan struct{}, 3)
+	done := make(chan error, 3)
+	for range 3 {
+		go func() {
+			done <- b.Execute(ctx, func(context.Context) error {
+				started <- struct{}{}
+				<-hold
+				return nil
+			})
+		}()
+	}
+	<-started
+	<-started
+	waitFor(t, func() bool { return b.Stats().Waiting == 1 })
+	if s := b.Stats(); s.Active != 2 || s.MaxConcurrent != 2 || s.MaxQueue != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+
+	err := b.Execute(ctx, func(context.Context) error { t.Error("ran past a full queue"); return nil })
+	var berr *BulkheadError
+	if !errors.As(err, &berr) || berr.Name != "reports-db" || !errors.Is(err, ErrBulkheadFull) || !errorsx.IsRetryable(err) {
+		t.Errorf("full queue: %v", err)
+	}
+
+	close(hold)
+	for range 3 {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+	if s := b.Stats(); s.Active != 0 || s.Waiting != 0 {
+		t.Errorf("after: %+v", s)
+	}
+}
+
+func TestBulkheadWaitEnds(t *testing.T) {
+	b := NewBulkhead("slow", WithMaxConcurrent(1), WithQueueTimeout(10*time.Millisecond))
+	release, err := b.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = b.Acquire(context.Background())
+	var berr *BulkheadError
+	if !errors.As(err, &berr) || berr.Waited < 10*time.Millisecond {
+		t.Errorf("timeout: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Call(ctx, b, func(context.Context) (int, error) { return 1, nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: %v", err)
+	}
+
+	release()
+	release()
+	if v, err := Call(context.Background(), b, func(context.Context) (int, error) { return 1, nil }); v != 1 || err != nil {
+		t.Errorf("after release: %v %v", v, err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
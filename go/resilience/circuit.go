@@ -0,0 +1,371 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package resilience holds failure-handling components shared across the
+// foundation, the Go counterpart of the Python resilience package. Retries
+// live in the retry subpackage.
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+// Circuit breaker states.
+const (
+	// StateClosed lets calls through and counts failures.
+	StateClosed State = iota
+	// StateOpen rejects calls until the recovery timeout has passed.
+	StateOpen
+	// StateHalfOpen lets a limited number of trial calls through.
+	StateHalfOpen
+)
+
+// String returns the state name used by the Python CircuitState.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	}
+	return fmt.Sprintf("state(%d)", int(s))
+}
+
+// ErrCircuitOpen is matched by errors.Is when a call is rejected by an
+// open circuit.
+var ErrCircuitOpen = errorsx.New(errorsx.Unavailable, "resilience: circuit breaker is open")
+
+// OpenError is returned for calls rejected by an open circuit.
+type OpenError struct {
+	Name string
+	// RetryAfter is how long until the circuit lets a trial call through.
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("resilience: circuit breaker %q is open, retry in %s", e.Name, e.RetryAfter.Round(time.Millisecond))
+}
+
+func (e *OpenError) Is(target error) bool { return target == ErrCircuitOpen }
+
+// Class returns errorsx.Unavailable.
+func (e *OpenError) Class() errorsx.Class { return errorsx.Unavailable }
+
+// Defaults matching the Python circuit breaker.
+const (
+	DefaultFailureThreshold = 5
+	DefaultRecoveryTimeout  = 60 * time.Second
+)
+
+// BreakerOption configures a CircuitBreaker.
+type BreakerOption func(*CircuitBreaker)
+
+// WithFailureThreshold opens the circuit after n consecutive failures.
+// The default is DefaultFailureThreshold.
+func WithFailureThreshold(n int) BreakerOption {
+	return func(cb *CircuitBreaker) { cb.threshold = n }
+}
+
+// WithFailureRate also opens the circuit when at least rate (above 0, up
+// to 1) of the last window calls failed. It needs window calls before it
+// applies. A rate above 1 is taken as 1; a rate or window that is not
+// positive leaves the option off.
+func WithFailureRate(rate float64, window int) BreakerOption {
+	return func(cb *CircuitBreaker) {
+		if rate <= 0 || window <= 0 {
+			return
+		}
+		cb.rate = min(rate, 1)
+		cb.outcomes = make([]bool, 0, window)
+	}
+}
+
+// WithRecoveryTimeout sets how long the circuit stays open before a trial
+// call is let through. The default is DefaultRecoveryTimeout.
+func WithRecoveryTimeout(d time.Duration) BreakerOption {
+	return func(cb *CircuitBreaker) { cb.recovery = d }
+}
+
+// WithHalfOpenCalls sets how many trial calls may run while half-open;
+// that many must succeed to close the circuit. The default is 1.
+func WithHalfOpenCalls(n int) BreakerOption {
+	return func(cb *CircuitBreaker) { cb.halfOpenMax = n }
+}
+
+// WithStateChange registers a callback for state transitions. Callbacks
+// run after the transition, outside the breaker's lock.
+func WithStateChange(fn func(name string, from, to State)) BreakerOption {
+	return func(cb *CircuitBreaker) { cb.onChange = append(cb.onChange, fn) }
+}
+
+// WithFailureCheck sets which errors count as failures. The default counts
+// every error except those whose errorsx class is a caller fault, such as
+// cancellation or NotFound, which say nothing about the health of the
+// dependency.
+func WithFailureCheck(fn func(error) bool) BreakerOption {
+	return func(cb *CircuitBreaker) { cb.isFailure = fn }
+}
+
+// WithClock sets the clock the recovery timeout is measured by. The
+// default is clock.Real().
+func WithClock(c clock.Clock) BreakerOption {
+	return func(cb *CircuitBreaker) { cb.clock = c }
+}
+
+// CircuitBreaker stops calling a failing dependency for a while, so a dead
+// service fails fast instead of stalling every caller:
+//
+//	notify := resilience.NewCircuitBreaker("notifications")
+//	err := notify.Execute(ctx, func(ctx context.Context) error {
+//		_, err := client.Post(ctx, "/notifications", body)
+//		return err
+//	})
+//
+// It is safe for concurrent use.
+type CircuitBreaker struct {
+	name        string
+	threshold   int
+	rate        float64
+	recovery    time.Duration
+	halfOpenMax int
+	onChange    []func(name string, from, to State)
+	isFailure   func(error) bool
+	clock       clock.Clock
+
+	mu          sync.Mutex
+	state       State
+	failures    int    // consecutive failures while closed
+	outcomes    []bool // last calls while closed, true for failures
+	next        int    // ring position in outcomes
+	openedAt    time.Time
+	halfOpenRun int    // trial calls in flight
+	halfOpenOK  int    // trial calls succeeded
+	gen         uint64 // incremented on every transition
+}
+
+// NewCircuitBreaker creates a closed circuit breaker.
+func NewCircuitBreaker(name string, opts ...BreakerOption) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		name:        name,
+		threshold:   DefaultFailureThreshold,
+		recovery:    DefaultRecoveryTimeout,
+		halfOpenMax: 1,
+		isFailure: func(err error) bool {
+			return !errorsx.Classify(err).CallerFault()
+		},
+		clock: clock.Real(),
+	}
+	for _, opt := range opts {
+		opt(cb)
+	}
+	return cb
+}
+
+// Name returns the breaker name.
+func (cb *CircuitBreaker) Name() string { return cb.name }
+
+// State returns the current state. An open circuit whose recovery timeout
+// has passed reports StateHalfOpen.
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	t := cb.advance()
+	s := cb.state
+	cb.mu.Unlock()
+	cb.notify(t)
+	return s
+}
+
+// Execute runs fn if the circuit allows it and records the outcome. A
+// rejected call returns an *OpenError without running fn. A panic in fn
+// is recorded as a failure and goes on unwinding.
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	gen, err := cb.allow()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			cb.record(gen, true)
+			panic(p)
+		}
+	}()
+	err = fn(ctx)
+	cb.record(gen, err != nil && cb.isFailure(err))
+	return err
+}
+
+// Executor runs a function under a resilience policy. CircuitBreaker and
+// Bulkhead are Executors.
+type Executor interface {
+	Execute(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Call is Execute for functions that return a value.
+func Call[T any](ctx context.Context, ex Executor, fn func(ctx context.Context) (T, error)) (T, error) {
+	var v T
+	err := ex.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		v, err = fn(ctx)
+		return err
+	})
+	return v, err
+}
+
+// Allow asks to make a call. If the circuit allows it, the caller must
+// report the outcome through done exactly once; otherwise Allow returns an
+// *OpenError. Allow suits wrappers where the call is not a single
+// function, such as HTTP middleware.
+func (cb *CircuitBreaker) Allow() (done func(err error), err error) {
+	gen, err := cb.allow()
+	if err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { cb.record(gen, err != nil && cb.isFailure(err)) })
+	}, nil
+}
+
+// allow admits a call and returns the generation to record it in, or
+// returns an *OpenError.
+func (cb *CircuitBreaker) allow() (gen uint64, err error) {
+	cb.mu.Lock()
+	t := cb.advance()
+	switch cb.state {
+	case StateOpen:
+		wait := cb.openedAt.Add(cb.recovery).Sub(cb.clock.Now())
+		cb.mu.Unlock()
+		cb.notify(t)
+		return 0, &OpenError{Name: cb.name, RetryAfter: wait}
+	case StateHalfOpen:
+		if cb.halfOpenRun >= cb.halfOpenMax {
+			cb.mu.Unlock()
+			cb.notify(t)
+			return 0, &OpenError{Name: cb.name}
+		}
+		cb.halfOpenRun++
+	}
+	gen = cb.gen
+	cb.mu.Unlock()
+	cb.notify(t)
+	return gen, nil
+}
+
+// Reset closes the circuit and clears its history.
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	t := cb.transition(StateClosed)
+	cb.mu.Unlock()
+	cb.notify(t)
+}
+
+// record applies the outcome of a call allowed in generation gen. Calls
+// that straddle a transition are ignored, so a slow call from before the
+// circuit opened does not count as a trial.
+func (cb *CircuitBreaker) record(gen uint64, failed bool) {
+	cb.mu.Lock()
+	if gen != cb.gen {
+		cb.mu.Unlock()
+		return
+	}
+	var t transition
+	switch cb.state {
+	case StateHalfOpen:
+		cb.halfOpenRun--
+		if failed {
+			t = cb.transition(StateOpen)
+		} else if cb.halfOpenOK++; cb.halfOpenOK >= cb.halfOpenMax {
+			t = cb.transition(StateClosed)
+		}
+	case StateClosed:
+		if failed {
+			cb.failures++
+		} else {
+			cb.failures = 0
+		}
+		if cb.observe(failed) {
+			t = cb.transition(StateOpen)
+		}
+	}
+	cb.mu.Unlock()
+	cb.notify(t)
+}
+
+// observe records an outcome while closed and reports whether the circuit
+// should open. Callers hold cb.mu.
+func (cb *CircuitBreaker) observe(failed bool) bool {
+	if cb.threshold > 0 && cb.failures >= cb.threshold {
+		return true
+	}
+	window := cap(cb.outcomes)
+	if window == 0 {
+		return false
+	}
+	if len(cb.outcomes) < window {
+		cb.outcomes = append(cb.outcomes, failed)
+	} else {
+		cb.outcomes[cb.next] = failed
+	}
+	cb.next = (cb.next + 1) % window
+	if len(cb.outcomes) < window {
+		return false
+	}
+	n := 0
+	for _, f := range cb.outcomes {
+		if f {
+			n++
+		}
+	}
+	return float64(n)/float64(window) >= cb.rate
+}
+
+// advance moves an open circuit to half-open once the recovery timeout has
+// passed. Callers hold cb.mu.
+func (cb *CircuitBreaker) advance() transition {
+	if cb.state == StateOpen && !cb.clock.Now().Before(cb.openedAt.Add(cb.recovery)) {
+		return cb.transition(StateHalfOpen)
+	}
+	return transition{}
+}
+
+type transition struct {
+	from, to State
+	changed  bool
+}
+
+// transition switches state and resets the bookkeeping of the new state.
+// Callers hold cb.mu and pass the result to notify after unlocking.
+func (cb *CircuitBreaker) transition(to State) transition {
+	from := cb.state
+	cb.state = to
+	cb.failures = 0
+	cb.outcomes = cb.outcomes[:0]
+	cb.next = 0
+	cb.halfOpenRun = 0
+	cb.halfOpenOK = 0
+	cb.gen++
+	if to == StateOpen {
+		cb.openedAt = cb.clock.Now()
+	}
+	return transition{from: from, to: to, changed: from != to}
+}
+
+func (cb *CircuitBreaker) notify(t transition) {
+	if !t.changed {
+		return
+	}
+	for _, fn := range cb.onChange {
+		fn(cb.name, t.from, t.to)
+	}
+}
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+func newTestBreaker(opts ...BreakerOption) (*CircuitBreaker, *clock.Fake, *[]string) {
+	var changes []string
+	clk := clock.NewFake(time.Unix(1000, 0))
+	opts = append(opts, WithClock(clk), WithStateChange(func(name string, from, to State) {
+		changes = append(changes, fmt.Sprintf("%s:%v->%v", name, from, to))
+	}))
+	cb := NewCircuitBreaker("notifications", opts...)
+	return cb, clk, &changes
+}
+
+var errDown = errors.New("notification API down")
+
+func fail(context.Context) error { return errDown }
+func ok(context.Context) error   { return nil }
+
+func TestCircuitBreakerLifecycle(t *testing.T) {
+	cb, clk, changes := newTestBreaker(WithFailureThreshold(3), WithRecoveryTimeout(30*time.Second))
+	ctx := context.Background()
+
+	cb.Execute(ctx, fail)
+	cb.Execute(ctx, fail)
+	cb.Execute(ctx, ok) // resets the consecutive count
+	for range 3 {
+		cb.Execute(ctx, fail)
+	}
+	if cb.State() != StateOpen {
+		t.Fatalf("state = %v", cb.State())
+	}
+
+	called := false
+	err := cb.Execute(ctx, func(context.Context) error { called = true; return nil })
+	var oe *OpenError
+	if called || !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &oe) || oe.RetryAfter != 30*time.Second {
+		t.Errorf("open circuit: called %v err %v", called, err)
+	}
+
+	clk.Advance(30 * time.Second)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("state after recovery timeout = %v", cb.State())
+	}
+	cb.Execute(ctx, fail)
+	if cb.State() != StateOpen {
+		t.Fatalf("failed trial left state %v", cb.State())
+	}
+
+	clk.Advance(30 * time.Second)
+	if err := cb.Execute(ctx, ok); err != nil || cb.State() != StateClosed {
+		t.Errorf("successful trial: err %v state %v", err, cb.State())
+	}
+
+	want := []string{
+		"notifications:closed->open",
+		"notifications:open->half_open",
+		"notifications:half_open->open",
+		"notifications:open->half_open",
+		"notifications:half_open->closed",
+	}
+	if !reflect.DeepEqual(*changes, want) {
+		t.Errorf("changes = %v", *changes)
+	}
+}
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	cb, _, _ := newTestBreaker(WithFailureThreshold(0), WithFailureRate(0.5, 4))
+	ctx := context.Background()
+	for _, f := range []func(context.Context) error{ok, ok, ok, fail} {
+		cb.Execute(ctx, f)
+	}
+	if cb.State() != StateClosed {
+		t.Fatalf("opened below the rate")
+	}
+	cb.Execute(ctx, fail) // the last four calls are now ok, ok, fail, fail
+	if cb.State() != StateOpen {
+		t.Errorf("state = %v, want open at 50%% failures", cb.State())
+	}
+}
+
+func TestCircuitBreakerFailureRateBounds(t *testing.T) {
+	cb, _, _ := newTestBreaker(WithFailureThreshold(0), WithFailureRate(0, 2))
+	for range 4 {
+		cb.Execute(context.Background(), ok)
+	}
+	if cb.State() != StateClosed {
+		t.Errorf("rate 0 opened on successes")
+	}
+	cb, _, _ = newTestBreaker(WithFailureThreshold(0), WithFailureRate(2, 2))
+	cb.Execute(context.Background(), fail)
+	cb.Execute(context.Background(), fail)
+	if cb.State() != StateOpen {
+		t.Errorf("rate 2 did not open on all failures")
+	}
+}
+
+func TestCircuitBreakerRecordsPanic(t *testing.T) {
+	cb, clk, _ := newTestBreaker(WithFailureThreshold(1), WithRecoveryTimeout(time.Second), WithHalfOpenCalls(1))
+	cb.Execute(context.Background(), fail)
+	clk.Advance(time.Second)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("panic not propagated")
+			}
+		}()
+		cb.Execute(context.Background(), func(context.Context) error { panic("boom") })
+	}()
+	if cb.State() != StateOpen {
+		t.Fatalf("panicking trial left state %v", cb.State())
+	}
+	clk.Advance(time.Second)
+	if err := cb.Execute(context.Background(), ok); err != nil || cb.State() != StateClosed {
+		t.Errorf("trial after panic: err %v state %v", err, cb.State())
+	}
+}
+
+func TestCircuitBreakerHalfOpenLimitsTrials(t *testing.T) {
+	cb, clk, _ := newTestBreaker(WithFailureThreshold(1), WithRecoveryTimeout(time.Second), WithHalfOpenCalls(2))
+	done, _ := cb.Allow() // straddles the transition below
+	cb.Execute(context.Background(), fail)
+	clk.Advance(time.Second)
+
+	d1, err1 := cb.Allow()
+	d2, err2 := cb.Allow()
+	_, err3 := cb.Allow()
+	if err1 != nil || err2 != nil || !errors.Is(err3, ErrCircuitOpen) {
+		t.Fatalf("trials: %v %v %v", err1, err2, err3)
+	}
+	done(errDown) // stale outcome is ignored
+	d1(nil)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("closed after one of two trials")
+	}
+	d2(nil)
+	d2(errDown) // second report is ignored
+	if cb.State() != StateClosed {
+		t.Errorf("state = %v", cb.State())
+	}
+}
+
+func TestCircuitBreakerIgnoresCancellation(t *testing.T) {
+	cb, _, _ := newTestBreaker(WithFailureThreshold(1))
+	v, err := Call(context.Background(), cb, func(context.Context) (int, error) { return 0, context.Canceled })
+	if v != 0 || !errors.Is(err, context.Canceled) || cb.State() != StateClosed {
+		t.Errorf("err %v state %v", err, cb.State())
+	}
+	cb.Execute(context.Background(), func(context.Context) error { return errorsx.New(errorsx.NotFound, "no such user") })
+	if cb.State() != StateClosed {
+		t.Errorf("NotFound opened the circuit")
+	}
+	cb.Execute(context.Background(), fail)
+	cb.Reset()
+	if cb.State() != StateClosed {
+		t.Errorf("Reset left state %v", cb.State())
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Path is one way of serving a call in a fallback chain.
+type Path struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Via names fn as a Path.
+func Via(name string, fn func(ctx context.Context) error) Path {
+	return Path{Name: name, Run: fn}
+}
+
+// FallbackChain tries a primary path and then each fallback in turn, the
+// Go form of the Python FallbackChain:
+//
+//	notify := resilience.Fallback(
+//		resilience.Via("webhook", sendWebhook),
+//		resilience.Via("queue", enqueue),
+//		resilience.Via("log", logOnly),
+//	)
+//	served, err := notify.Run(ctx)
+//
+// It is safe for concurrent use.
+type FallbackChain struct {
+	paths     []Path
+	isFailure func(error) bool
+	served    *servedCounts // shared with copies made by When
+}
+
+type servedCounts struct {
+	mu sync.Mutex
+	n  map[string]uint64
+}
+
+// Fallback creates a chain that serves calls with primary, falling back
+// to the other paths in order.
+func Fallback(primary Path, fallbacks ...Path) *FallbackChain {
+	return &FallbackChain{
+		paths:     append([]Path{primary}, fallbacks...),
+		isFailure: func(err error) bool { return !errorsx.Classify(err).CallerFault() },
+		served:    &servedCounts{n: make(map[string]uint64)},
+	}
+}
+
+// When returns a copy of the chain that falls back only on errors for
+// which fn returns true. The default falls back on every error except
+// those whose errorsx class is a caller fault, such as cancellation or
+// InvalidArgument, which the next path would fail the same way. The copy
+// shares the chain's counts.
+func (c *FallbackChain) When(fn func(error) bool) *FallbackChain {
+	return &FallbackChain{paths: c.paths, isFailure: fn, served: c.served}
+}
+
+// Run serves the call and returns the name of the path that did. When a
+// path fails with an error that does not allow falling back, or ctx is
+// done, that error is returned; when every path fails, the returned error
+// joins all their errors. A fallback is logged with the error that caused
+// it.
+func (c *FallbackChain) Run(ctx context.Context) (served string, err error) {
+	var errs []error
+	for i, p := range c.paths {
+		err := p.Run(ctx)
+		if err == nil {
+			c.record(p.Name)
+			return p.Name, nil
+		}
+		if ctx.Err() != nil || !c.isFailure(err) {
+			return "", err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+		if i+1 < len(c.paths) {
+			log.Default().WarnCtx(ctx, "fallback_path_failed", "path", p.Name, "next", c.paths[i+1].Name, log.Err(err))
+		}
+	}
+	return "", fmt.Errorf("resilience: all %d paths failed: %w", len(c.paths), errors.Join(errs...))
+}
+
+// record counts a call served by the named path.
+func (c *FallbackChain) record(name string) {
+	c.served.mu.Lock()
+	c.served.n[name]++
+	c.served.mu.Unlock()
+}
+
+// Served returns how many calls each path has served.
+func (c *FallbackChain) Served() map[string]uint64 {
+	c.served.mu.Lock()
+	defer c.served.mu.Unlock()
+	return maps.Clone(c.served.n)
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resilience
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/errorsx"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+func TestFallback(t *testing.T) {
+	rec := logtest.Capture(t)
+	webhookErr := errors.New("webhook: 503")
+	var calls []string
+	path := func(name string, err error) Path {
+		return Via(name, func(context.Context) error { calls = append(calls, name); return err })
+	}
+	ctx := context.Background()
+
+	notify := Fallback(path("webhook", webhookErr), path("queue", nil), path("log", nil))
+	served, err := notify.Run(ctx)
+	if served != "queue" || err != nil || len(calls) != 2 {
+		t.Errorf("served %q err %v calls %v", served, err, calls)
+	}
+	r := rec.WithEvent("fallback_path_failed").First()
+	if r == nil {
+		t.Fatal("fallback not logged")
+	}
+	if v, _ := r.Get("next"); v != "queue" {
+		t.Errorf("next = %v", v)
+	}
+
+	calls = nil
+	invalid := errorsx.New(errorsx.InvalidArgument, "bad payload")
+	served, err = Fallback(path("webhook", invalid), path("queue", nil)).Run(ctx)
+	if served != "" || err != invalid || len(calls) != 1 {
+		t.Errorf("caller fault: served %q err %v calls %v", served, err, calls)
+	}
+
+	onlyRetryable := notify.When(errorsx.IsRetryable)
+	calls = nil
+	if _, err := onlyRetryable.Run(ctx); err != webhookErr || len(calls) != 1 {
+		t.Errorf("When: err %v calls %v", err, calls)
+	}
+
+	queueErr := errors.New("queue full")
+	_, err = Fallback(path("webhook", webhookErr), path("queue", queueErr)).Run(ctx)
+	if !errors.Is(err, webhookErr) || !errors.Is(err, queueErr) {
+		t.Errorf("exhausted: %v", err)
+	}
+
+	if got := notify.Served(); !maps.Equal(got, map[string]uint64{"queue": 1}) {
+		t.Errorf("served = %v", got)
+	}
+}
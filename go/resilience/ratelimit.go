@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resilience
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+)
+
+// RateLimiter is a token bucket: it refills at a steady rate up to a burst
+// size, and each call takes one token. It is safe for concurrent use.
+type RateLimiter struct {
+	rate  float64 // tokens per second
+	burst float64
+	clock clock.Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiterOption configures a RateLimiter.
+type RateLimiterOption func(*RateLimiter)
+
+// WithLimiterClock sets the clock the bucket refills and waits by. The
+// default is clock.Real().
+func WithLimiterClock(c clock.Clock) RateLimiterOption {
+	return func(l *RateLimiter) { l.clock = c }
+}
+
+// NewRateLimiter allows rate calls per second on average and up to burst
+// calls at once. The bucket starts full.
+func NewRateLimiter(rate float64, burst int, opts ...RateLimiterOption) *RateLimiter {
+	burst = max(burst, 1)
+	l := &RateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), clock: clock.Real()}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Allow takes a token if one is available and reports whether it did.
+func (l *RateLimiter) Allow() bool {
+	if wait := l.Reserve(); wait > 0 {
+		l.Cancel()
+		return false
+	}
+	return true
+}
+
+// Wait takes a token, blocking until one is available or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := clock.Sleep(ctx, l.clock, l.Reserve()); err != nil {
+		l.Cancel()
+		return err
+	}
+	return nil
+}
+
+// Reserve takes a token, possibly borrowing against future refills, and
+// returns how long the caller must wait before using it. A caller that
+// decides not to wait must call Cancel.
+func (l *RateLimiter) Reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill()
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	if l.rate <= 0 {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Cancel returns a token taken by Reserve.
+func (l *RateLimiter) Cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill()
+	l.tokens = min(l.tokens+1, l.burst)
+}
+
+// refill adds the tokens earned since the last call. Callers hold l.mu.
+func (l *RateLimiter) refill() {
+	now := l.clock.Now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.burst)
+	}
+	l.last = now
+}
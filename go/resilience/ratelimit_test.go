@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+)
+
+func TestRateLimiterRefills(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	l := NewRateLimiter(2, 3, WithLimiterClock(clk))
+
+	for i := range 3 {
+		if !l.Allow() {
+			t.Fatalf("burst call %d rejected", i)
+		}
+	}
+	if l.Allow() {
+		t.Fatal("call beyond burst allowed")
+	}
+	if wait := l.Reserve(); wait != 500*time.Millisecond {
+		t.Errorf("wait = %v", wait)
+	}
+	l.Cancel()
+	clk.Advance(500 * time.Millisecond)
+	if !l.Allow() || l.Allow() {
+		t.Error("expected exactly one token after half a second")
+	}
+	clk.Advance(time.Hour)
+	for range 3 {
+		l.Allow()
+	}
+	if l.Allow() {
+		t.Error("bucket grew beyond burst")
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(100, 1)
+	ctx := context.Background()
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("three calls at 100/s took %v", elapsed)
+	}
+
+	slow := NewRateLimiter(0.001, 1)
+	slow.Allow()
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := slow.Wait(cctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRateLimiterWaitOnClock(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	l := NewRateLimiter(1, 1, WithLimiterClock(clk))
+	l.Allow()
+	errc := make(chan error, 1)
+	go func() { errc <- l.Wait(context.Background()) }()
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if l.Allow() {
+		t.Error("token used by Wait was returned")
+	}
+}
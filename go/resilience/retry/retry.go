@@ -0,0 +1,240 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retry re-runs failing operations with backoff, the Go form of
+// the Python resilience RetryPolicy and RetryExecutor:
+//
+//	err := retry.Do(ctx, retry.DefaultPolicy, func(ctx context.Context) error {
+//		return db.Ping(ctx)
+//	})
+//
+// The HTTP client uses the same Policy through httpx.Retry.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+// Backoff is the strategy that spaces out attempts.
+type Backoff int
+
+// Backoff strategies, matching the Python BackoffStrategy.
+const (
+	// Exponential doubles the delay after each attempt.
+	Exponential Backoff = iota
+	// Fixed waits BaseDelay between attempts.
+	Fixed
+	// Linear waits BaseDelay times the attempt number.
+	Linear
+	// Fibonacci waits BaseDelay times the Fibonacci number of the attempt.
+	Fibonacci
+)
+
+// String returns the lower-case strategy name.
+func (b Backoff) String() string {
+	switch b {
+	case Exponential:
+		return "exponential"
+	case Fixed:
+		return "fixed"
+	case Linear:
+		return "linear"
+	case Fibonacci:
+		return "fibonacci"
+	}
+	return fmt.Sprintf("backoff(%d)", int(b))
+}
+
+// Policy describes when and how often to retry. Zero fields take the
+// value of DefaultPolicy, except Jitter, RetryIf, RetryStatus, OnRetry and
+// Clock.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	Backoff     Backoff
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Jitter randomizes each delay by up to this fraction in either
+	// direction; 0.25 gives the Python default of ±25%.
+	Jitter float64
+	// RetryIf reports whether an error is worth retrying. When nil every
+	// error is retried except Permanent ones, cancellation of the context
+	// passed to Do, and errors whose errorsx class is a caller fault, such
+	// as NotFound. Set it to errorsx.IsRetryable to retry only errors
+	// known to be transient.
+	RetryIf func(error) bool
+	// RetryStatus lists the HTTP status codes retried by httpx.Retry.
+	// When nil it uses DefaultRetryStatus.
+	RetryStatus []int
+	// OnRetry, if set, is called before waiting for the next attempt.
+	OnRetry func(attempt int, err error, delay time.Duration)
+	// Clock measures the delays between attempts. When nil it uses
+	// clock.Real().
+	Clock clock.Clock
+}
+
+// DefaultPolicy matches the Python retry defaults: three attempts with
+// exponential backoff from one second, capped at a minute, with jitter.
+var DefaultPolicy = Policy{
+	MaxAttempts: 3,
+	Backoff:     Exponential,
+	BaseDelay:   time.Second,
+	MaxDelay:    time.Minute,
+	Jitter:      0.25,
+}
+
+// DefaultRetryStatus are the HTTP statuses retried when a policy does not
+// list its own: rate limiting and the transient gateway errors.
+var DefaultRetryStatus = []int{429, 502, 503, 504}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultPolicy.MaxDelay
+	}
+	return p
+}
+
+// Delay returns the wait after the given 1-based attempt, before jitter.
+func (p Policy) Delay(attempt int) time.Duration {
+	p = p.withDefaults()
+	if attempt <= 0 {
+		return 0
+	}
+	var d time.Duration
+	switch p.Backoff {
+	case Fixed:
+		d = p.BaseDelay
+	case Linear:
+		d = p.BaseDelay * time.Duration(attempt)
+	case Fibonacci:
+		a, b := 0, 1
+		for range attempt {
+			a, b = b, a+b
+		}
+		d = p.BaseDelay * time.Duration(a)
+	default:
+		if attempt > 62 {
+			return p.MaxDelay
+		}
+		d = p.BaseDelay << (attempt - 1)
+	}
+	if d <= 0 || d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// jittered applies p.Jitter to d.
+func (p Policy) jittered(d time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 - p.Jitter + rand.Float64()*2*p.Jitter))
+}
+
+// Error is returned when every attempt failed. It wraps the last error.
+type Error struct {
+	Attempts int
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("retry: giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying; Do returns it at once,
+// unwrapped.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+type afterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *afterError) Error() string { return e.err.Error() }
+func (e *afterError) Unwrap() error { return e.err }
+
+// After marks err as retryable no sooner than d, e.g. from an HTTP
+// Retry-After header. The hint replaces the backoff delay but is still
+// capped by MaxDelay.
+func After(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &afterError{err: err, delay: d}
+}
+
+// Do calls fn until it succeeds, returns an error that should not be
+// retried, MaxAttempts is reached, or ctx is done. When attempts run out
+// the last error is returned wrapped in an *Error; if ctx ends while
+// waiting, its error is returned.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	p = p.withDefaults()
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if p.RetryIf == nil && errorsx.Classify(err).CallerFault() ||
+			p.RetryIf != nil && !p.RetryIf(err) {
+			return err
+		}
+		if attempt >= p.MaxAttempts {
+			return &Error{Attempts: attempt, Err: err}
+		}
+		delay := p.jittered(p.Delay(attempt))
+		var after *afterError
+		if errors.As(err, &after) {
+			delay = min(after.delay, p.MaxDelay)
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err, delay)
+		}
+		if err := clock.Sleep(ctx, clock.Or(p.Clock), delay); err != nil {
+			return err
+		}
+	}
+}
+
+// DoValue is Do for operations that return a value.
+func DoValue[T any](ctx context.Context, p Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	var v T
+	err := Do(ctx, p, func(ctx context.Context) error {
+		var err error
+		v, err = fn(ctx)
+		return err
+	})
+	return v, err
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/errorsx"
+)
+
+func TestDelay(t *testing.T) {
+	for _, tc := range []struct {
+		backoff Backoff
+		want    []time.Duration
+	}{
+		{Exponential, []time.Duration{1, 2, 4, 8, 10}},
+		{Fixed, []time.Duration{1, 1, 1, 1, 1}},
+		{Linear, []time.Duration{1, 2, 3, 4, 5}},
+		{Fibonacci, []time.Duration{1, 1, 2, 3, 5}},
+	} {
+		p := Policy{Backoff: tc.backoff, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+		for i, want := range tc.want {
+			if got := p.Delay(i + 1); got != want*time.Second {
+				t.Errorf("%v attempt %d: %v, want %v", tc.backoff, i+1, got, want*time.Second)
+			}
+		}
+	}
+	if got := (Policy{BaseDelay: time.Second, MaxDelay: time.Hour}).Delay(100); got != time.Hour {
+		t.Errorf("large attempt = %v", got)
+	}
+}
+
+func TestJitterStaysInRange(t *testing.T) {
+	p := Policy{Jitter: 0.25}
+	for range 100 {
+		if d := p.jittered(time.Second); d < 750*time.Millisecond || d > 1250*time.Millisecond {
+			t.Fatalf("jittered delay %v out of range", d)
+		}
+	}
+}
+
+func TestDoRetriesUntilSuccess(t *testing.T) {
+	var attempts []int
+	calls := 0
+	p := Policy{MaxAttempts: 4, BaseDelay: time.Millisecond, OnRetry: func(attempt int, err error, d time.Duration) {
+		attempts = append(attempts, attempt)
+	}}
+	v, err := DoValue(context.Background(), p, func(context.Context) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("connection refused")
+		}
+		return "ok", nil
+	})
+	if err != nil || v != "ok" || calls != 3 || len(attempts) != 2 {
+		t.Errorf("v %q err %v calls %d retries %v", v, err, calls, attempts)
+	}
+}
+
+func TestDoStops(t *testing.T) {
+	boom := errors.New("boom")
+	ctx := context.Background()
+	fast := Policy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	calls := 0
+	err := Do(ctx, fast, func(context.Context) error { calls++; return boom })
+	var rerr *Error
+	if !errors.As(err, &rerr) || rerr.Attempts != 3 || !errors.Is(err, boom) || calls != 3 {
+		t.Errorf("exhausted: err %v calls %d", err, calls)
+	}
+
+	calls = 0
+	err = Do(ctx, fast, func(context.Context) error { calls++; return Permanent(boom) })
+	if err != boom || calls != 1 {
+		t.Errorf("permanent: err %v calls %d", err, calls)
+	}
+
+	calls = 0
+	onlyTimeouts := fast
+	onlyTimeouts.RetryIf = func(err error) bool { return errors.Is(err, context.DeadlineExceeded) }
+	err = Do(ctx, onlyTimeouts, func(context.Context) error { calls++; return boom })
+	if err != boom || calls != 1 {
+		t.Errorf("RetryIf: err %v calls %d", err, calls)
+	}
+
+	calls = 0
+	err = Do(ctx, fast, func(context.Context) error { calls++; return errorsx.Mark(boom, errorsx.NotFound) })
+	if !errors.Is(err, boom) || calls != 1 {
+		t.Errorf("caller fault: err %v calls %d", err, calls)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	slow := Policy{MaxAttempts: 3, BaseDelay: time.Hour}
+	go func() { time.Sleep(10 * time.Millisecond); cancel() }()
+	if err := Do(cctx, slow, func(context.Context) error { return boom }); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled wait: %v", err)
+	}
+}
+
+func TestAfterOverridesBackoff(t *testing.T) {
+	var delays []time.Duration
+	p := Policy{MaxAttempts: 2, BaseDelay: time.Hour, MaxDelay: time.Hour,
+		OnRetry: func(_ int, _ error, d time.Duration) { delays = append(delays, d) }}
+	calls := 0
+	Do(context.Background(), p, func(context.Context) error {
+		calls++
+		return After(errors.New("rate limited"), time.Millisecond)
+	})
+	if calls != 2 || len(delays) != 1 || delays[0] != time.Millisecond {
+		t.Errorf("calls %d delays %v", calls, delays)
+	}
+}
+
+func TestDoWaitsOnClock(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	p := Policy{MaxAttempts: 3, Backoff: Fixed, BaseDelay: time.Hour, Clock: clk}
+	calls := 0
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Do(context.Background(), p, func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return errors.New("flaky")
+			}
+			return nil
+		})
+	}()
+	for range 2 {
+		clk.BlockUntil(1)
+		clk.Advance(time.Hour)
+	}
+	if err := <-errc; err != nil || calls != 3 {
+		t.Errorf("Do = %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,239 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule tells when a task runs.
+type Schedule interface {
+	// Next returns the first run strictly after t, or the zero time if
+	// there is none.
+	Next(t time.Time) time.Time
+}
+
+// Every returns a schedule running every d, the first run d after the
+// scheduler starts. It panics if d is not positive.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic("schedule: non-positive interval for Every")
+	}
+	return interval(d)
+}
+
+type interval time.Duration
+
+func (i interval) Next(t time.Time) time.Time { return t.Add(time.Duration(i)) }
+
+// descriptors are the cron shorthands.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a schedule in local time; see ParseInLocation.
+func Parse(spec string) (Schedule, error) {
+	return ParseInLocation(spec, time.Local)
+}
+
+// ParseInLocation parses a schedule whose times are in loc: a cron
+// expression of five fields, minute, hour, day of month, month and day of
+// week, such as "*/15 9-17 * * MON-FRI", a shorthand such as "@daily", or
+// "@every" and a duration, such as "@every 5m".
+//
+// Fields hold "*", values, ranges such as "1-5", lists such as "1,15",
+// and steps such as "*/10" or "0-30/5". Months and days of the week may be
+// given by their first three letters, and Sunday is 0 or 7. As in cron, a
+// day matching either the day of month or the day of week runs when both
+// are restricted.
+func ParseInLocation(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		dur, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || dur <= 0 {
+			return nil, fmt.Errorf("schedule: parsing %q: invalid interval", spec)
+		}
+		return interval(dur), nil
+	}
+	expr := spec
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if expr, ok = descriptors[strings.ToLower(spec)]; !ok {
+			return nil, fmt.Errorf("schedule: parsing %q: unknown shorthand", spec)
+		}
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule: parsing %q: want 5 fields, got %d", spec, len(fields))
+	}
+	c := &cron{loc: loc}
+	var err error
+	for i, f := range []struct {
+		bits *uint64
+		r    fieldRange
+	}{
+		{&c.minute, minutes},
+		{&c.hour, hours},
+		{&c.dom, daysOfMonth},
+		{&c.month, months},
+		{&c.dow, daysOfWeek},
+	} {
+		if *f.bits, err = f.r.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("schedule: parsing %q: %s: %w", spec, f.r.name, err)
+		}
+	}
+	// Sunday is both 0 and 7.
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.anyDOM = fields[2] == "*" || strings.HasPrefix(fields[2], "*/")
+	c.anyDOW = fields[4] == "*" || strings.HasPrefix(fields[4], "*/")
+	return c, nil
+}
+
+// MustParse is Parse for schedules known to be valid; it panics on error.
+func MustParse(spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// fieldRange is the range of values of a cron field.
+type fieldRange struct {
+	name     string
+	min, max int
+	names    []string // names of the values from min, if any
+}
+
+var (
+	minutes     = fieldRange{name: "minute", min: 0, max: 59}
+	hours       = fieldRange{name: "hour", min: 0, max: 23}
+	daysOfMonth = fieldRange{name: "day of month", min: 1, max: 31}
+	months      = fieldRange{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	daysOfWeek = fieldRange{name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// parse returns the values of field as bits.
+func (r fieldRange) parse(field string) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		expr, stepStr, hasStep := strings.Cut(part, "/")
+		lo, hi := r.min, r.max
+		switch {
+		case expr == "*":
+		case strings.Contains(expr, "-"):
+			a, b, _ := strings.Cut(expr, "-")
+			var err error
+			if lo, err = r.value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = r.value(b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("range %q is backwards", expr)
+			}
+		default:
+			v, err := r.value(expr)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses a number or a name in the range.
+func (r fieldRange) value(s string) (int, error) {
+	for i, name := range r.names {
+		if strings.EqualFold(s, name) {
+			return r.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < r.min || v > r.max {
+		return 0, fmt.Errorf("%q is not in %d-%d", s, r.min, r.max)
+	}
+	return v, nil
+}
+
+// cron is a parsed cron expression, each field a set of bits.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	anyDOM, anyDOW                bool
+	loc                           *time.Location
+}
+
+func has(bits uint64, v int) bool { return bits&(1<<v) != 0 }
+
+// Next finds the first matching minute after t, moving by months, days
+// and hours where they do not match. It gives up after five years, which
+// only expressions that never match, such as "0 0 30 2 *", reach.
+func (c *cron) Next(t time.Time) time.Time {
+	loc := c.loc
+	t = t.In(loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !has(c.month, int(t.Month())):
+			t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+		case !c.dayMatches(t):
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+		case !has(c.hour, t.Hour()):
+			// Moving in elapsed time steps over the hours a change to
+			// daylight saving time skips.
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// forward returns next, or the next hour of t when next is not later,
+// which time.Date may return for a midnight skipped by a change to
+// daylight saving time.
+func forward(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.anyDOM || c.anyDOW {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	from := time.Date(2026, time.March, 14, 10, 7, 30, 0, time.UTC) // a Saturday
+	for _, tt := range []struct {
+		spec string
+		want string
+	}{
+		{"* * * * *", "2026-03-14T10:08:00Z"},
+		{"*/15 * * * *", "2026-03-14T10:15:00Z"},
+		{"0 9-17 * * MON-FRI", "2026-03-16T09:00:00Z"},
+		{"30 8 1,15 * *", "2026-03-15T08:30:00Z"},
+		{"0 0 1 jan *", "2027-01-01T00:00:00Z"},
+		{"0 12 * * 7", "2026-03-15T12:00:00Z"},
+		{"0 12 13 * 0", "2026-03-15T12:00:00Z"}, // day of month or of week
+		{"5-20/5 10 * * *", "2026-03-14T10:10:00Z"},
+		{"@hourly", "2026-03-14T11:00:00Z"},
+		{"@daily", "2026-03-15T00:00:00Z"},
+		{"@weekly", "2026-03-15T00:00:00Z"},
+		{"@monthly", "2026-04-01T00:00:00Z"},
+		{"@yearly", "2027-01-01T00:00:00Z"},
+		{"0 0 29 2 *", "2028-02-29T00:00:00Z"},
+		{"@every 90s", "2026-03-14T10:09:00Z"},
+	} {
+		s, err := ParseInLocation(tt.spec, time.UTC)
+		if err != nil {
+			t.Errorf("%q: %v", tt.spec, err)
+			continue
+		}
+		if got := s.Next(from).Format(time.RFC3339); got != tt.want {
+			t.Errorf("%q: Next = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s, err := ParseInLocation("0 0 30 2 *", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Next = %v, want zero", next)
+	}
+}
+
+func TestNextAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	s, err := ParseInLocation("30 2 * * *", ny)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 02:30 does not exist on 8 March 2026; the next one is a day later.
+	from := time.Date(2026, time.March, 8, 0, 0, 0, 0, ny)
+	if got, want := s.Next(from), time.Date(2026, time.March, 9, 2, 30, 0, 0, ny); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@often",
+		"@every soon",
+		"@every -1m",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded", spec)
+		}
+	}
+}
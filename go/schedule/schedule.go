@@ -0,0 +1,319 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package schedule runs tasks on cron expressions or fixed intervals:
+//
+//	s := schedule.New()
+//	err := s.Add("purge-outbox", "@hourly", func(ctx context.Context) error {
+//		_, err := ob.Purge(ctx)
+//		return err
+//	})
+//	err = s.Add("refresh-reports", "*/15 * * * *", reports.Refresh,
+//		schedule.WithJitter(time.Minute), schedule.WithTimeout(10*time.Minute))
+//
+// A task does not overlap itself unless it allows it: a run that comes due
+// while the last one is still going is skipped, or with RunMissedOnce
+// made up once the last one returns. Every run is logged with the task
+// name, and a panic fails the run without stopping the scheduler.
+//
+// Once started, the scheduler registers with the shutdown coordinator at
+// shutdown.PriorityWorkers; it also implements container.Starter and
+// container.Stopper.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/recovery"
+	"github.com/provide-io/provide-foundation/go/shutdown"
+)
+
+// Task is the work run on a schedule.
+type Task func(ctx context.Context) error
+
+// MissedRuns tells what happens to a run that comes due while the last
+// run of its task is still going.
+type MissedRuns int
+
+const (
+	// SkipMissed drops the run; the task next runs on its schedule.
+	SkipMissed MissedRuns = iota
+	// RunMissedOnce runs the task again as soon as the last run returns,
+	// once however many runs it missed.
+	RunMissedOnce
+)
+
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithLogger sets the logger for task runs. The default is log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(s *Scheduler) { s.logger = l }
+}
+
+// WithClock sets the clock runs are timed by. The default is
+// clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) { s.clock = c }
+}
+
+// WithLocation sets the time zone of the cron expressions given to Add.
+// The default is time.Local.
+func WithLocation(loc *time.Location) Option {
+	return func(s *Scheduler) { s.loc = loc }
+}
+
+// WithCoordinator sets the shutdown coordinator the scheduler registers
+// with when it starts. The default is shutdown.Default.
+func WithCoordinator(c *shutdown.Coordinator) Option {
+	return func(s *Scheduler) { s.coordinator = c }
+}
+
+// TaskOption configures a task.
+type TaskOption func(*task)
+
+// WithJitter delays each run by a random duration up to d, so replicas
+// running the same schedule do not all start at once.
+func WithJitter(d time.Duration) TaskOption {
+	return func(t *task) { t.jitter = d }
+}
+
+// WithTimeout cancels the context of a run after d.
+func WithTimeout(d time.Duration) TaskOption {
+	return func(t *task) { t.timeout = d }
+}
+
+// WithMissedRuns sets what happens to runs that come due while the task
+// is still running. The default is SkipMissed.
+func WithMissedRuns(m MissedRuns) TaskOption {
+	return func(t *task) { t.missed = m }
+}
+
+// AllowOverlap starts every run when it comes due, even while earlier
+// runs of the task are still going.
+func AllowOverlap() TaskOption {
+	return func(t *task) { t.overlap = true }
+}
+
+type task struct {
+	name     string
+	schedule Schedule
+	fn       Task
+	jitter   time.Duration
+	timeout  time.Duration
+	missed   MissedRuns
+	overlap  bool
+
+	mu      sync.Mutex
+	active  int
+	pending bool
+}
+
+// Scheduler runs tasks on their schedules. It is safe for concurrent use.
+type Scheduler struct {
+	logger      *log.Logger
+	clock       clock.Clock
+	loc         *time.Location
+	coordinator *shutdown.Coordinator
+
+	mu      sync.Mutex
+	tasks   []*task
+	names   map[string]bool
+	started bool
+	stopped bool
+	ctx     context.Context // of the loops, cancelled by Stop
+	stop    context.CancelFunc
+	runCtx  context.Context // of the runs, cancelled when Stop gives up
+	abort   context.CancelFunc
+	loops   sync.WaitGroup
+	runs    sync.WaitGroup
+}
+
+// New returns a scheduler without tasks.
+func New(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		clock:       clock.Real(),
+		loc:         time.Local,
+		coordinator: shutdown.Default,
+		names:       map[string]bool{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add parses spec as ParseInLocation does, in the location of the
+// scheduler, and adds the task. Names must be unique.
+func (s *Scheduler) Add(name, spec string, fn Task, opts ...TaskOption) error {
+	sched, err := ParseInLocation(spec, s.loc)
+	if err != nil {
+		return err
+	}
+	return s.AddSchedule(name, sched, fn, opts...)
+}
+
+// AddSchedule adds a task run on sched. A task added after Start is
+// scheduled at once.
+func (s *Scheduler) AddSchedule(name string, sched Schedule, fn Task, opts ...TaskOption) error {
+	t := &task{name: name, schedule: sched, fn: fn}
+	for _, opt := range opts {
+		opt(t)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.names[name] {
+		return fmt.Errorf("schedule: task %q already added", name)
+	}
+	if s.stopped {
+		return fmt.Errorf("schedule: adding %q: scheduler is stopped", name)
+	}
+	s.names[name] = true
+	s.tasks = append(s.tasks, t)
+	if s.started {
+		s.loops.Add(1)
+		go s.loop(t)
+	}
+	return nil
+}
+
+// Start schedules the tasks and returns. Runs get a context with the
+// values of ctx but not its cancellation. Later calls do nothing.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return nil
+	}
+	s.started = true
+	base := context.WithoutCancel(ctx)
+	s.ctx, s.stop = context.WithCancel(base)
+	s.runCtx, s.abort = context.WithCancel(base)
+	s.coordinator.Register("scheduler", shutdown.PriorityWorkers, s.Stop)
+	for _, t := range s.tasks {
+		s.loops.Add(1)
+		go s.loop(t)
+	}
+	s.log().InfoCtx(ctx, "scheduler_started", "tasks", len(s.tasks))
+	return nil
+}
+
+// Stop stops scheduling runs and waits for those still going until ctx
+// is done, when they are cancelled. Later calls wait the same way.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	started := s.started
+	s.mu.Unlock()
+	if !started {
+		return nil
+	}
+	s.stop()
+	s.loops.Wait()
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.log().InfoCtx(ctx, "scheduler_stopped")
+		return nil
+	case <-ctx.Done():
+		s.abort()
+		return fmt.Errorf("schedule: waiting for running tasks: %w", ctx.Err())
+	}
+}
+
+// OnStart is Start.
+func (s *Scheduler) OnStart(ctx context.Context) error { return s.Start(ctx) }
+
+// OnStop is Stop.
+func (s *Scheduler) OnStop(ctx context.Context) error { return s.Stop(ctx) }
+
+// loop waits for each run of t until the scheduler stops.
+func (s *Scheduler) loop(t *task) {
+	defer s.loops.Done()
+	next := t.schedule.Next(s.clock.Now())
+	for !next.IsZero() {
+		delay := next.Sub(s.clock.Now())
+		if t.jitter > 0 {
+			delay += rand.N(t.jitter)
+		}
+		if err := clock.Sleep(s.ctx, s.clock, delay); err != nil {
+			return
+		}
+		s.fire(t, next)
+		// Runs missed while the clock jumped, or the process was
+		// suspended, are skipped rather than run back to back.
+		now := s.clock.Now()
+		if next = t.schedule.Next(next); !next.IsZero() && next.Before(now) {
+			next = t.schedule.Next(now)
+		}
+	}
+	s.log().WarnCtx(s.ctx, "task_schedule_ended", "task", t.name)
+}
+
+// fire starts the run of t due at, unless the last run is still going.
+func (s *Scheduler) fire(t *task, at time.Time) {
+	t.mu.Lock()
+	if t.active > 0 && !t.overlap {
+		if t.missed == RunMissedOnce {
+			t.pending = true
+		}
+		t.mu.Unlock()
+		s.log().WarnCtx(s.ctx, "task_run_skipped", "task", t.name, "scheduled_at", at, "make_up", t.missed == RunMissedOnce)
+		return
+	}
+	t.active++
+	t.mu.Unlock()
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		for {
+			s.run(t, at)
+			t.mu.Lock()
+			if !t.pending || s.ctx.Err() != nil {
+				t.active--
+				t.mu.Unlock()
+				return
+			}
+			t.pending = false
+			t.mu.Unlock()
+			at = s.clock.Now()
+		}
+	}()
+}
+
+// run runs t once and logs the outcome.
+func (s *Scheduler) run(t *task, at time.Time) {
+	ctx := s.runCtx
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	logger := s.log().With("task", t.name)
+	logger.DebugCtx(ctx, "task_run_started", "scheduled_at", at)
+	start := time.Now()
+	err := recovery.Do(ctx, t.fn, recovery.WithLogger(s.log()))
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		logger.ErrorCtx(ctx, "task_run_failed", "scheduled_at", at, "duration_ms", elapsed, log.Err(err))
+		return
+	}
+	logger.InfoCtx(ctx, "task_run_completed", "scheduled_at", at, "duration_ms", elapsed)
+}
+
+func (s *Scheduler) log() *log.Logger {
+	if s.logger != nil {
+		return s.logger
+	}
+	return log.Default()
+}
@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+	"github.com/provide-io/provide-foundation/go/shutdown"
+)
+
+// start starts a scheduler on a fake clock with its own coordinator and
+// stops it when the test ends.
+func start(t *testing.T, add func(s *Scheduler)) (*Scheduler, *clock.Fake) {
+	t.Helper()
+	clk := clock.NewFake(time.Date(2026, time.March, 14, 10, 0, 0, 0, time.UTC))
+	s := New(WithClock(clk), WithLocation(time.UTC), WithCoordinator(shutdown.New()))
+	add(s)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Stop(context.Background()) })
+	return s, clk
+}
+
+// tick advances clk by d once the loops of n tasks are waiting on it.
+func tick(clk *clock.Fake, n int, d time.Duration) {
+	clk.BlockUntil(n)
+	clk.Advance(d)
+}
+
+// await waits until n runs have ended, so the next tick does not find the
+// last run still going.
+func await(rec *logtest.Recorder, n int) {
+	for rec.Records().WithEvent("task_run_completed").Count()+rec.Records().WithEvent("task_run_failed").Count() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRuns(t *testing.T) {
+	rec := logtest.Capture(t)
+	runs := make(chan time.Time, 10)
+	_, clk := start(t, func(s *Scheduler) {
+		if err := s.Add("every-minute", "* * * * *", func(ctx context.Context) error {
+			runs <- time.Now()
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddSchedule("every-minute", Every(time.Second), nil); err == nil {
+			t.Error("duplicate task name accepted")
+		}
+	})
+	for i := range 3 {
+		tick(clk, 1, time.Minute)
+		<-runs
+		await(rec, i+1)
+	}
+	r := rec.Records().WithEvent("task_run_completed").First()
+	if task, _ := r.Get("task"); task != "every-minute" {
+		t.Errorf("task = %v", task)
+	}
+	if at, _ := r.Get("scheduled_at"); !at.(time.Time).Equal(time.Date(2026, time.March, 14, 10, 1, 0, 0, time.UTC)) {
+		t.Errorf("scheduled_at = %v", at)
+	}
+}
+
+func TestNoOverlap(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		opts   []TaskOption
+		runs   int32
+		events int
+	}{
+		{"skip", nil, 1, 2},
+		{"run once", []TaskOption{WithMissedRuns(RunMissedOnce)}, 2, 2},
+		{"overlap", []TaskOption{AllowOverlap()}, 3, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := logtest.Capture(t)
+			release := make(chan struct{})
+			var runs atomic.Int32
+			_, clk := start(t, func(s *Scheduler) {
+				s.AddSchedule("slow", Every(time.Minute), func(ctx context.Context) error {
+					runs.Add(1)
+					<-release
+					return nil
+				}, tt.opts...)
+			})
+			for range 3 {
+				tick(clk, 1, time.Minute)
+			}
+			clk.BlockUntil(1)
+			close(release)
+			for range 100 {
+				if runs.Load() >= tt.runs {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(10 * time.Millisecond)
+			if n := runs.Load(); n != tt.runs {
+				t.Errorf("runs = %d, want %d", n, tt.runs)
+			}
+			if n := rec.Records().WithEvent("task_run_skipped").Count(); n != tt.events {
+				t.Errorf("skipped = %d, want %d", n, tt.events)
+			}
+		})
+	}
+}
+
+func TestFailuresAreIsolated(t *testing.T) {
+	rec := logtest.Capture(t)
+	var runs atomic.Int32
+	_, clk := start(t, func(s *Scheduler) {
+		s.AddSchedule("flaky", Every(time.Minute), func(ctx context.Context) error {
+			switch runs.Add(1) {
+			case 1:
+				panic("boom")
+			case 2:
+				return errors.New("down")
+			}
+			return nil
+		})
+	})
+	for i := range 3 {
+		tick(clk, 1, time.Minute)
+		await(rec, i+1)
+	}
+	if n := rec.Records().WithEvent("task_run_failed").Count(); n != 2 {
+		t.Errorf("failed runs = %d, want 2", n)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	runs := make(chan struct{}, 1)
+	_, clk := start(t, func(s *Scheduler) {
+		s.AddSchedule("jittered", Every(time.Minute), func(ctx context.Context) error {
+			runs <- struct{}{}
+			return nil
+		}, WithJitter(10*time.Second))
+	})
+	tick(clk, 1, time.Minute+10*time.Second)
+	<-runs
+}
+
+func TestStop(t *testing.T) {
+	coord := shutdown.New()
+	clk := clock.NewFake(time.Unix(0, 0))
+	s := New(WithClock(clk), WithCoordinator(coord))
+	started := make(chan struct{})
+	s.AddSchedule("stuck", Every(time.Minute), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, AllowOverlap())
+	s.Start(context.Background())
+	tick(clk, 1, time.Minute)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := coord.Shutdown(ctx); err == nil {
+		t.Error("shutdown did not report the stuck task")
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Errorf("Stop after cancelling = %v", err)
+	}
+	if err := s.AddSchedule("late", Every(time.Minute), nil); err == nil {
+		t.Error("task added after Stop")
+	}
+}
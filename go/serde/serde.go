@@ -0,0 +1,284 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package serde serializes values as JSON, YAML, TOML or MessagePack
+// through one code path, so configuration, CLI output and anything else
+// that reads or writes documents behaves the same in every format:
+//
+//	data, err := serde.Marshal(serde.YAML, report)
+//	err = serde.ReadFile("deploy.toml", &spec)
+//
+// Struct fields follow the encoding/json conventions in every format: the
+// json tag names a field, omitempty and "-" apply, and MarshalJSON and
+// UnmarshalJSON methods are honored. Other formats go through the JSON
+// form of a value, so a type serializes the same way everywhere and needs
+// only json tags.
+package serde
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+
+	"github.com/provide-io/provide-foundation/go/filex"
+)
+
+// Format is a serialization format.
+type Format string
+
+// Formats.
+const (
+	JSON    Format = "json"
+	YAML    Format = "yaml"
+	TOML    Format = "toml"
+	MsgPack Format = "msgpack"
+)
+
+// ErrUnknownFormat is matched by errors.Is for an unsupported format or
+// file extension.
+var ErrUnknownFormat = errors.New("serde: unknown format")
+
+// ParseFormat returns the format named name, in any case; "yml" and
+// "mpk" are accepted as aliases.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case JSON, YAML, TOML, MsgPack:
+		return f, nil
+	case "yml":
+		return YAML, nil
+	case "mpk":
+		return MsgPack, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+}
+
+// FormatOf returns the format of a file from its extension: .json, .yaml
+// or .yml, .toml, and .msgpack or .mpk.
+func FormatOf(path string) (Format, error) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	f, err := ParseFormat(ext)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+	}
+	return f, nil
+}
+
+// Marshal encodes v in format f. TOML documents must be tables, so v must
+// encode as a JSON object.
+func Marshal(f Format, v any) ([]byte, error) {
+	if f == JSON {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("serde: %w", err)
+		}
+		return data, nil
+	}
+	tree, err := toTree(v)
+	if err != nil {
+		return nil, err
+	}
+	return marshalTree(f, tree)
+}
+
+// Unmarshal decodes data in format f into v, which must be a pointer.
+func Unmarshal(f Format, data []byte, v any) error {
+	if f == JSON {
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("serde: %w", err)
+		}
+		return nil
+	}
+	var tree any
+	var err error
+	switch f {
+	case YAML:
+		err = yaml.Unmarshal(data, &tree)
+	case TOML:
+		var doc map[string]any
+		err = toml.Unmarshal(data, &doc)
+		tree = doc
+	case MsgPack:
+		err = msgpack.Unmarshal(data, &tree)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, string(f))
+	}
+	if err != nil {
+		return fmt.Errorf("serde: %s: %w", f, err)
+	}
+	return fromTree(tree, v)
+}
+
+// ReadFile decodes the file at path, in the format of its extension, into
+// v. Errors wrap the underlying cause, so a missing file matches
+// os.ErrNotExist.
+func ReadFile(path string, v any) error {
+	f, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("serde: %w", err)
+	}
+	if err := Unmarshal(f, data, v); err != nil {
+		return fmt.Errorf("%w (in %s)", err, path)
+	}
+	return nil
+}
+
+// WriteFile encodes v in the format of the extension of path and writes
+// it with filex.WriteAtomic. Text formats end with a newline.
+func WriteFile(path string, v any) error {
+	f, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
+	data, err := Marshal(f, v)
+	if err != nil {
+		return err
+	}
+	if f == JSON {
+		data = append(data, '\n')
+	}
+	return filex.WriteAtomic(path, data, 0)
+}
+
+func marshalTree(f Format, tree any) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch f {
+	case YAML:
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		err = enc.Encode(tree)
+		if err == nil {
+			err = enc.Close()
+		}
+	case TOML:
+		if _, ok := tree.(map[string]any); !ok {
+			return nil, fmt.Errorf("serde: toml: %T is not a table", tree)
+		}
+		err = toml.NewEncoder(&buf).Encode(tree)
+	case MsgPack:
+		err = msgpack.NewEncoder(&buf).Encode(tree)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, string(f))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("serde: %s: %w", f, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// toTree returns the JSON form of v as maps, slices and scalars, with
+// integers kept as int64 rather than float64.
+func toTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("serde: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("serde: %w", err)
+	}
+	return numbers(tree), nil
+}
+
+func numbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for k, e := range v {
+			v[k] = numbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = numbers(e)
+		}
+	}
+	return v
+}
+
+// fromTree stores a decoded tree in v. Generic targets receive the tree
+// itself, keeping the native types of the format, such as TOML dates;
+// others are filled through the JSON form, as encoding/json would.
+func fromTree(tree any, v any) error {
+	tree = normalize(tree)
+	switch p := v.(type) {
+	case *any:
+		*p = tree
+		return nil
+	case *map[string]any:
+		if m, ok := tree.(map[string]any); ok || tree == nil {
+			*p = m
+			return nil
+		}
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("serde: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("serde: %w", err)
+	}
+	return nil
+}
+
+// normalize converts what the decoders produce beyond the JSON types: the
+// map[any]any of YAML and MessagePack for non-string keys becomes
+// map[string]any, and sized integers become int64.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+	case float32:
+		return float64(v)
+	}
+	return v
+}
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serde
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type service struct {
+	Name     string            `json:"name"`
+	Port     int               `json:"port"`
+	Replicas int64             `json:"replicas,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Started  time.Time         `json:"started"`
+	Secret   string            `json:"-"`
+}
+
+var svc = service{
+	Name:    "api",
+	Port:    8080,
+	Ratio:   0.5,
+	Tags:    []string{"a", "b"},
+	Labels:  map[string]string{"team": "core"},
+	Started: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	Secret:  "hidden",
+}
+
+func TestRoundTripEveryFormat(t *testing.T) {
+	for _, f := range []Format{JSON, YAML, TOML, MsgPack} {
+		data, err := Marshal(f, svc)
+		if err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		if bytes.Contains(data, []byte("hidden")) || bytes.Contains(data, []byte("replicas")) {
+			t.Errorf("%s: json tag conventions not applied:\n%s", f, data)
+		}
+		var got service
+		if err := Unmarshal(f, data, &got); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		want := svc
+		want.Secret = ""
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: round trip = %+v, want %+v", f, got, want)
+		}
+	}
+}
+
+func TestTagNamesInTextFormats(t *testing.T) {
+	data, _ := Marshal(YAML, svc)
+	if !strings.Contains(string(data), "name: api\n") || !strings.Contains(string(data), "port: 8080\n") {
+		t.Errorf("yaml =\n%s", data)
+	}
+	data, _ = Marshal(TOML, svc)
+	if !strings.Contains(string(data), `name = "api"`) || !strings.Contains(string(data), "port = 8080") {
+		t.Errorf("toml =\n%s", data)
+	}
+	if _, err := Marshal(TOML, []int{1}); err == nil {
+		t.Error("Marshal(TOML, slice) succeeded")
+	}
+}
+
+func TestGenericTargetsKeepIntegers(t *testing.T) {
+	for _, f := range []Format{YAML, TOML, MsgPack} {
+		data, _ := Marshal(f, map[string]any{"n": 3, "nested": map[string]any{"f": 1.5}})
+		var doc map[string]any
+		if err := Unmarshal(f, data, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc["n"] != int64(3) || doc["nested"].(map[string]any)["f"] != 1.5 {
+			t.Errorf("%s: doc = %#v", f, doc)
+		}
+	}
+	var v any
+	if err := Unmarshal(YAML, []byte("1: one\n"), &v); err != nil || v.(map[string]any)["1"] != "one" {
+		t.Errorf("yaml integer keys = %#v, %v", v, err)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for path, want := range map[string]Format{
+		"a.json": JSON, "a.YAML": YAML, "a.yml": YAML, "a.toml": TOML, "a.msgpack": MsgPack, "a.mpk": MsgPack,
+	} {
+		if got, err := FormatOf(path); err != nil || got != want {
+			t.Errorf("FormatOf(%s) = %s, %v; want %s", path, got, err, want)
+		}
+	}
+	if _, err := FormatOf("a.ini"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("FormatOf(a.ini) = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"svc.json", "svc.yaml", "svc.toml", "svc.msgpack"} {
+		path := filepath.Join(dir, name)
+		if err := WriteFile(path, svc); err != nil {
+			t.Fatal(err)
+		}
+		var got service
+		if err := ReadFile(path, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != "api" || got.Port != 8080 {
+			t.Errorf("%s: read %+v", name, got)
+		}
+	}
+	if err := ReadFile(filepath.Join(dir, "missing.json"), &svc); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadFile(missing) = %v, want ErrNotExist", err)
+	}
+}
+
+func TestStreams(t *testing.T) {
+	for _, f := range []Format{JSON, YAML, MsgPack} {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf, f)
+		for i := range 3 {
+			if err := enc.Encode(map[string]int{"i": i}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		enc.Close()
+
+		dec := NewDecoder(&buf, f)
+		var got []int
+		for {
+			var v struct{ I int }
+			err := dec.Decode(&v)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", f, err)
+			}
+			got = append(got, v.I)
+		}
+		if !reflect.DeepEqual(got, []int{0, 1, 2}) {
+			t.Errorf("%s: stream = %v", f, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, TOML)
+	enc.Encode(svc)
+	if err := enc.Encode(svc); err == nil {
+		t.Error("second TOML value accepted")
+	}
+	dec := NewDecoder(&buf, TOML)
+	var got service
+	if err := dec.Decode(&got); err != nil || got.Name != "api" {
+		t.Errorf("TOML Decode = %+v, %v", got, err)
+	}
+	if err := dec.Decode(&got); err != io.EOF {
+		t.Errorf("second TOML Decode = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package serde
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Encoder writes a stream of values: JSON lines, YAML documents separated
+// by "---", or consecutive MessagePack values. A TOML stream holds one
+// value.
+type Encoder struct {
+	f    Format
+	w    io.Writer
+	json *json.Encoder
+	yaml *yaml.Encoder
+	n    int
+}
+
+// NewEncoder returns an encoder writing format f to w.
+func NewEncoder(w io.Writer, f Format) *Encoder {
+	e := &Encoder{f: f, w: w}
+	switch f {
+	case JSON:
+		e.json = json.NewEncoder(w)
+	case YAML:
+		e.yaml = yaml.NewEncoder(w)
+		e.yaml.SetIndent(2)
+	}
+	return e
+}
+
+// Encode writes v to the stream.
+func (e *Encoder) Encode(v any) error {
+	defer func() { e.n++ }()
+	if e.json != nil {
+		if err := e.json.Encode(v); err != nil {
+			return fmt.Errorf("serde: %w", err)
+		}
+		return nil
+	}
+	if e.f == TOML && e.n > 0 {
+		return errors.New("serde: a toml stream holds one value")
+	}
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+	if e.yaml != nil {
+		if err := e.yaml.Encode(tree); err != nil {
+			return fmt.Errorf("serde: yaml: %w", err)
+		}
+		return nil
+	}
+	data, err := marshalTree(e.f, tree)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+// Close flushes the stream. It does not close the writer.
+func (e *Encoder) Close() error {
+	if e.yaml != nil {
+		return e.yaml.Close()
+	}
+	return nil
+}
+
+// Decoder reads a stream of values written by an Encoder.
+type Decoder struct {
+	f       Format
+	r       io.Reader
+	json    *json.Decoder
+	yaml    *yaml.Decoder
+	msgpack *msgpack.Decoder
+	done    bool
+}
+
+// NewDecoder returns a decoder reading format f from r.
+func NewDecoder(r io.Reader, f Format) *Decoder {
+	d := &Decoder{f: f, r: r}
+	switch f {
+	case JSON:
+		d.json = json.NewDecoder(r)
+	case YAML:
+		d.yaml = yaml.NewDecoder(r)
+	case MsgPack:
+		d.msgpack = msgpack.NewDecoder(r)
+	}
+	return d
+}
+
+// Decode reads the next value into v, which must be a pointer. It returns
+// io.EOF at the end of the stream.
+func (d *Decoder) Decode(v any) error {
+	var tree any
+	var err error
+	switch {
+	case d.json != nil:
+		if err = d.json.Decode(v); err == nil || err == io.EOF {
+			return err
+		}
+		return fmt.Errorf("serde: %w", err)
+	case d.yaml != nil:
+		err = d.yaml.Decode(&tree)
+	case d.msgpack != nil:
+		err = d.msgpack.Decode(&tree)
+	case d.f == TOML:
+		if d.done {
+			return io.EOF
+		}
+		d.done = true
+		var doc map[string]any
+		_, err = toml.NewDecoder(d.r).Decode(&doc)
+		tree = doc
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, string(d.f))
+	}
+	if err == io.EOF {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("serde: %s: %w", d.f, err)
+	}
+	return fromTree(tree, v)
+}
@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package shutdown coordinates graceful shutdown. Components register
+// cleanup functions with a priority; a Coordinator waits for SIGTERM or
+// SIGINT and runs them under one global deadline, highest priority first:
+//
+//	func main() {
+//		app := container.NewApp(c)
+//		shutdown.Default.Register("otlp", shutdown.PriorityTelemetry, exporter.Close)
+//		if err := shutdown.Default.Run(ctx, app); err != nil {
+//			log.Fatal("shutdown_failed", log.Err(err))
+//		}
+//	}
+//
+// Cleanups of equal priority run concurrently. A second signal during
+// shutdown cancels the deadline, so cleanups still running give up.
+package shutdown
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/container"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// DefaultTimeout bounds the whole shutdown, within the 30 second grace
+// period Kubernetes gives a pod after SIGTERM.
+const DefaultTimeout = 25 * time.Second
+
+// Priorities order cleanups: traffic stops first, then the components
+// serving it, then the resources they use, and telemetry is flushed last
+// so it records the rest.
+const (
+	PriorityServers   = 300
+	PriorityWorkers   = 200
+	PriorityResources = 100
+	PriorityTelemetry = 0
+)
+
+// Cleanup releases a resource on shutdown. It must return once ctx is
+// done.
+type Cleanup func(ctx context.Context) error
+
+// Option configures a Coordinator.
+type Option func(*Coordinator)
+
+// WithTimeout sets the deadline of the whole shutdown.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Coordinator) { c.timeout = d }
+}
+
+// WithSignals replaces the signals that start shutdown in Wait.
+func WithSignals(sig ...os.Signal) Option {
+	return func(c *Coordinator) { c.signals = sig }
+}
+
+// WithLogger sets the logger for shutdown records. The default is
+// log.Default().
+func WithLogger(l *log.Logger) Option {
+	return func(c *Coordinator) { c.logger = l }
+}
+
+type cleanup struct {
+	name     string
+	priority int
+	fn       Cleanup
+}
+
+// Coordinator runs registered cleanups once on shutdown. It is safe for
+// concurrent use.
+type Coordinator struct {
+	timeout time.Duration
+	signals []os.Signal
+	logger  *log.Logger
+
+	mu       sync.Mutex
+	cleanups []cleanup
+	started  bool
+
+	stopping chan struct{}
+	done     chan struct{}
+	err      error
+}
+
+// Default is the process-wide coordinator.
+var Default = New()
+
+// New returns a coordinator with no cleanups.
+func New(opts ...Option) *Coordinator {
+	c := &Coordinator{
+		timeout:  DefaultTimeout,
+		signals:  []os.Signal{os.Interrupt, syscall.SIGTERM},
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Register adds a cleanup run at priority; higher priorities run first.
+// Cleanups registered once shutdown has begun are not run.
+func (c *Coordinator) Register(name string, priority int, fn Cleanup) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cleanups = append(c.cleanups, cleanup{name: name, priority: priority, fn: fn})
+}
+
+// RegisterApp stops app at PriorityWorkers, so its components stop after
+// the servers and before the resources registered here.
+func (c *Coordinator) RegisterApp(app *container.App) {
+	c.Register("app", PriorityWorkers, app.Stop)
+}
+
+// Stopping returns a channel closed when shutdown begins, for loops that
+// should stop taking work and readiness checks that should fail.
+func (c *Coordinator) Stopping() <-chan struct{} { return c.stopping }
+
+// Run starts app, registers it with RegisterApp, and waits as Wait does.
+// If app fails to start, its error is returned and the other cleanups
+// still run.
+func (c *Coordinator) Run(ctx context.Context, app *container.App) error {
+	if err := app.Start(ctx); err != nil {
+		return errors.Join(err, c.Shutdown(context.WithoutCancel(ctx)))
+	}
+	c.RegisterApp(app)
+	return c.Wait(ctx)
+}
+
+// Wait blocks until one of the configured signals arrives or ctx is
+// done, then shuts down and returns the cleanup errors.
+func (c *Coordinator) Wait(ctx context.Context) error {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, c.signals...)
+	defer signal.Stop(sigs)
+
+	var reason any = "context done"
+	select {
+	case sig := <-sigs:
+		reason = sig
+	case <-ctx.Done():
+	case <-c.stopping:
+	}
+
+	sctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	go func() {
+		select {
+		case sig := <-sigs:
+			c.log().Warn("shutdown_forced", "signal", sig.String())
+			cancel()
+		case <-c.done:
+		}
+	}()
+	c.log().InfoCtx(ctx, "shutdown_started", "reason", fmt.Sprint(reason))
+	return c.Shutdown(sctx)
+}
+
+// Shutdown runs the cleanups, by priority, until they finish or the
+// deadline set with WithTimeout passes, and returns their errors joined.
+// Cleanups not yet started at the deadline are not run, and fail with
+// the context error.
+// It runs them once; later and concurrent calls wait for that run and
+// return its result.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	if c.started {
+		c.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	c.started = true
+	cleanups := slices.Clone(c.cleanups)
+	c.mu.Unlock()
+	close(c.stopping)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	start := time.Now()
+	slices.SortStableFunc(cleanups, func(a, b cleanup) int { return cmp.Compare(b.priority, a.priority) })
+	var errs []error
+	for len(cleanups) > 0 {
+		n := 1
+		for n < len(cleanups) && cleanups[n].priority == cleanups[0].priority {
+			n++
+		}
+		if ctx.Err() != nil {
+			// Past the deadline: the remaining cleanups fail unrun.
+			for _, cl := range cleanups {
+				errs = append(errs, c.failed(ctx, cl, ctx.Err()))
+			}
+			break
+		}
+		errs = append(errs, c.runGroup(ctx, cleanups[:n])...)
+		cleanups = cleanups[n:]
+	}
+	c.err = errors.Join(errs...)
+	c.log().InfoCtx(ctx, "shutdown_completed", "duration", time.Since(start), "failed", len(errs))
+	close(c.done)
+	return c.err
+}
+
+// runGroup runs cleanups of one priority concurrently. A cleanup that
+// ignores ctx is abandoned when the deadline passes.
+func (c *Coordinator) runGroup(ctx context.Context, group []cleanup) []error {
+	errs := make([]error, len(group))
+	var wg sync.WaitGroup
+	for i, cl := range group {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done := make(chan error, 1)
+			go func() { done <- cl.fn(ctx) }()
+			var err error
+			select {
+			case err = <-done:
+				// A cleanup finishing as the deadline passes did not make it.
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+			if err != nil {
+				errs[i] = c.failed(ctx, cl, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return slices.DeleteFunc(errs, func(err error) bool { return err == nil })
+}
+
+// failed logs the failure of cl and returns it as an error.
+func (c *Coordinator) failed(ctx context.Context, cl cleanup, err error) error {
+	c.log().ErrorCtx(ctx, "shutdown_cleanup_failed", "cleanup", cl.name, "priority", cl.priority, log.Err(err))
+	return fmt.Errorf("shutdown: %s: %w", cl.name, err)
+}
+
+func (c *Coordinator) log() *log.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return log.Default()
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/container"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+type events struct {
+	mu  sync.Mutex
+	got []string
+}
+
+func (e *events) cleanup(name string) Cleanup {
+	return func(context.Context) error {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.got = append(e.got, name)
+		return nil
+	}
+}
+
+func (e *events) list() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.got)
+}
+
+func TestShutdownRunsByPriority(t *testing.T) {
+	logtest.Capture(t)
+	ev := &events{}
+	c := New()
+	c.Register("otlp", PriorityTelemetry, ev.cleanup("otlp"))
+	c.Register("db", PriorityResources, ev.cleanup("db"))
+	c.Register("http", PriorityServers, ev.cleanup("http"))
+	c.Register("grpc", PriorityServers, ev.cleanup("grpc"))
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := ev.list()
+	if len(got) != 4 || !slices.Contains(got[:2], "http") || !slices.Contains(got[:2], "grpc") ||
+		got[2] != "db" || got[3] != "otlp" {
+		t.Errorf("order = %v", got)
+	}
+	select {
+	case <-c.Stopping():
+	default:
+		t.Error("Stopping not closed")
+	}
+	if err := c.Shutdown(context.Background()); err != nil || len(ev.list()) != 4 {
+		t.Errorf("second Shutdown ran cleanups again: %v", err)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	rec := logtest.Capture(t)
+	ev := &events{}
+	c := New(WithTimeout(20 * time.Millisecond))
+	block := make(chan struct{})
+	defer close(block)
+	c.Register("stuck", PriorityWorkers, func(context.Context) error { <-block; return nil })
+	c.Register("broken", PriorityWorkers, func(context.Context) error { return errors.New("flush failed") })
+	c.Register("late", PriorityTelemetry, ev.cleanup("late"))
+
+	err := c.Shutdown(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "shutdown: broken: flush failed") {
+		t.Errorf("err = %v", err)
+	}
+	if got := ev.list(); len(got) != 0 {
+		t.Errorf("ran %v after the deadline", got)
+	}
+	if n := rec.WithEvent("shutdown_cleanup_failed").Count(); n != 3 {
+		t.Errorf("logged %d failures", n)
+	}
+}
+
+func TestWaitOnSignal(t *testing.T) {
+	rec := logtest.Capture(t)
+	ev := &events{}
+	c := New(WithSignals(syscall.SIGUSR1))
+	c.Register("db", PriorityResources, ev.cleanup("db"))
+	done := make(chan error, 1)
+	go func() { done <- c.Wait(context.Background()) }()
+	time.Sleep(10 * time.Millisecond) // let Wait subscribe
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Wait did not return")
+	}
+	if got := ev.list(); !slices.Equal(got, []string{"db"}) {
+		t.Errorf("cleanups = %v", got)
+	}
+	if v, _ := rec.WithEvent("shutdown_started").First().Get("reason"); v != "user defined signal 1" {
+		t.Errorf("reason = %v", v)
+	}
+}
+
+type stopper struct{ ev *events }
+
+func (s *stopper) OnStop(ctx context.Context) error { return s.ev.cleanup("app")(ctx) }
+
+func TestRunStopsApp(t *testing.T) {
+	logtest.Capture(t)
+	ev := &events{}
+	di := container.New()
+	di.MustProvide(func() *stopper { return &stopper{ev} })
+	c := New()
+	c.Register("http", PriorityServers, ev.cleanup("http"))
+	c.Register("db", PriorityResources, ev.cleanup("db"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Run(ctx, container.NewApp(di)); err != nil {
+		t.Fatal(err)
+	}
+	if got := ev.list(); !slices.Equal(got, []string{"http", "app", "db"}) {
+		t.Errorf("order = %v", got)
+	}
+}
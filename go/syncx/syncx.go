@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package syncx coalesces concurrent calls for the same key, so an
+// expensive lookup runs once however many goroutines ask for it at the
+// same time:
+//
+//	var users syncx.Singleflight[int64, User]
+//	u, shared, err := users.Do(ctx, id, func(ctx context.Context) (User, error) {
+//		return repo.FindByID(ctx, id)
+//	})
+//
+// Memoize goes further and keeps each result for a time to live:
+//
+//	getUser := syncx.Memoize(repo.FindByID, time.Minute, syncx.WithMetrics(nil, "users"))
+//	u, err := getUser(ctx, id)
+//
+// Calls that ran and calls that joined one already running are counted in
+// Stats and, with WithMetrics, in a metrics registry. The cache package
+// loads its misses through a Singleflight; for a cache that also
+// supports updates and deletes, use it.
+package syncx
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/internal/lru"
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/recovery"
+)
+
+// MetricCalls is the counter of calls recorded with WithMetrics, labeled
+// by name and result: executed when the call ran, coalesced when it
+// joined one in progress.
+const MetricCalls = "singleflight_calls_total"
+
+// Option configures NewSingleflight and Memoize.
+type Option func(*options)
+
+type options struct {
+	registry   *metrics.Registry
+	name       string
+	maxEntries int
+	clock      clock.Clock
+}
+
+// WithMetrics records the calls in reg, or metrics.Default when reg is
+// nil, labeled with name.
+func WithMetrics(reg *metrics.Registry, name string) Option {
+	return func(o *options) {
+		if reg == nil {
+			reg = metrics.Default
+		}
+		o.registry, o.name = reg, name
+	}
+}
+
+// WithMaxEntries bounds the results Memoize keeps, evicting the least
+// recently used beyond n. The default, 0, is unbounded.
+func WithMaxEntries(n int) Option {
+	return func(o *options) { o.maxEntries = n }
+}
+
+// WithClock sets the clock Memoize expires results by. The default is
+// clock.Real().
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// Stats are the counts of a Singleflight since it was created.
+type Stats struct {
+	Executed  uint64 // calls of fn
+	Coalesced uint64 // calls that shared the result of another
+}
+
+// Singleflight runs one call per key at a time. The zero value is ready
+// to use, without metrics. It is safe for concurrent use.
+type Singleflight[K comparable, V any] struct {
+	calls *metrics.Counter
+	name  string
+
+	mu       sync.Mutex
+	inflight map[K]*call[V]
+
+	executed, coalesced atomic.Uint64
+}
+
+// call is a call in progress.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// NewSingleflight returns a Singleflight configured by opts; only
+// WithMetrics applies.
+func NewSingleflight[K comparable, V any](opts ...Option) *Singleflight[K, V] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	g := &Singleflight[K, V]{}
+	if o.registry != nil {
+		g.calls = o.registry.Counter(MetricCalls, "Calls coalesced by key.", "name", "result")
+		g.name = o.name
+	}
+	return g
+}
+
+// Do returns the result of fn, or, if a call for key is in progress, waits
+// for that call and returns its result with shared set. fn runs without
+// the cancellation of ctx, as other callers may be waiting on it; a
+// caller whose ctx is done stops waiting with ctx.Err(). A panic in fn is
+// returned as a *recovery.PanicError. Results are not kept: the next call
+// for key after this one completes runs fn again.
+func (g *Singleflight[K, V]) Do(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (v V, shared bool, err error) {
+	return g.DoStore(ctx, key, fn, nil)
+}
+
+// DoStore is like Do, and passes the value of a successful call of fn to
+// store before returning it, unless Forget was called for key since the
+// call started. store runs while g is locked, so a caller that forgets
+// key and then writes where store does is not overwritten by the call it
+// forgot.
+func (g *Singleflight[K, V]) DoStore(ctx context.Context, key K, fn func(ctx context.Context) (V, error), store func(V)) (v V, shared bool, err error) {
+	g.mu.Lock()
+	if g.inflight == nil {
+		g.inflight = make(map[K]*call[V])
+	}
+	c, shared := g.inflight[key]
+	if !shared {
+		c = &call[V]{done: make(chan struct{})}
+		g.inflight[key] = c
+	}
+	g.mu.Unlock()
+
+	result := "executed"
+	if shared {
+		g.coalesced.Add(1)
+		result = "coalesced"
+	} else {
+		g.executed.Add(1)
+		go g.run(context.WithoutCancel(ctx), key, c, fn, store)
+	}
+	if g.calls != nil {
+		g.calls.Inc(g.name, result)
+	}
+	select {
+	case <-c.done:
+		return c.value, shared, c.err
+	case <-ctx.Done():
+		var zero V
+		return zero, shared, ctx.Err()
+	}
+}
+
+func (g *Singleflight[K, V]) run(ctx context.Context, key K, c *call[V], fn func(ctx context.Context) (V, error), store func(V)) {
+	err := recovery.Do(ctx, func(ctx context.Context) error {
+		var err error
+		c.value, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		var zero V
+		c.value, c.err = zero, err
+	}
+	g.mu.Lock()
+	if g.inflight[key] == c {
+		delete(g.inflight, key)
+		if err == nil && store != nil {
+			store(c.value)
+		}
+	}
+	g.mu.Unlock()
+	close(c.done)
+}
+
+// Forget makes the next call for key run fn even if a call is in
+// progress, whose callers still get its result.
+func (g *Singleflight[K, V]) Forget(key K) {
+	g.mu.Lock()
+	delete(g.inflight, key)
+	g.mu.Unlock()
+}
+
+// ForgetAll is like Forget for every key.
+func (g *Singleflight[K, V]) ForgetAll() {
+	g.mu.Lock()
+	clear(g.inflight)
+	g.mu.Unlock()
+}
+
+// Stats returns the counts of the Singleflight.
+func (g *Singleflight[K, V]) Stats() Stats {
+	return Stats{Executed: g.executed.Load(), Coalesced: g.coalesced.Load()}
+}
+
+// Memoize returns a function that calls fn once per key and returns its
+// result for ttl after, or forever when ttl is 0. Concurrent calls for a
+// key not yet known share one call of fn, as with Singleflight. Errors
+// are returned to every caller waiting on the call but not kept.
+func Memoize[K comparable, V any](fn func(ctx context.Context, key K) (V, error), ttl time.Duration, opts ...Option) func(ctx context.Context, key K) (V, error) {
+	o := options{clock: clock.Real()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var mu sync.Mutex
+	results := lru.New[K, V](o.maxEntries, o.clock, nil)
+	get := func(key K) (V, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		return results.Get(key)
+	}
+	g := NewSingleflight[K, V](opts...)
+	return func(ctx context.Context, key K) (V, error) {
+		if v, ok := get(key); ok {
+			return v, nil
+		}
+		called := false
+		v, _, err := g.DoStore(ctx, key, func(ctx context.Context) (V, error) {
+			// A call completing since the lookup above has stored its
+			// result already.
+			if v, ok := get(key); ok {
+				return v, nil
+			}
+			called = true
+			return fn(ctx, key)
+		}, func(v V) {
+			if called {
+				mu.Lock()
+				defer mu.Unlock()
+				results.Set(key, v, ttl)
+			}
+		})
+		return v, err
+	}
+}
@@ -0,0 +1,194 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package syncx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/clock"
+	"github.com/provide-io/provide-foundation/go/metrics"
+	"github.com/provide-io/provide-foundation/go/recovery"
+)
+
+// waitCalls waits until g has seen n calls.
+func waitCalls[K comparable, V any](t *testing.T, g *Singleflight[K, V], n uint64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s := g.Stats(); s.Executed+s.Coalesced < n; s = g.Stats() {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v, want %d calls", s, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSingleflightCoalesces(t *testing.T) {
+	reg := metrics.NewRegistry()
+	g := NewSingleflight[int, string](WithMetrics(reg, "users"))
+	release := make(chan struct{})
+	var queries atomic.Int32
+	fn := func(context.Context) (string, error) {
+		queries.Add(1)
+		<-release
+		return "ada", nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	var sharedCount atomic.Int32
+	for range callers {
+		wg.Go(func() {
+			v, shared, err := g.Do(context.Background(), 1, fn)
+			if v != "ada" || err != nil {
+				t.Errorf("Do = %q, %v", v, err)
+			}
+			if shared {
+				sharedCount.Add(1)
+			}
+		})
+	}
+	waitCalls(t, g, callers)
+	close(release)
+	wg.Wait()
+
+	if queries.Load() != 1 || sharedCount.Load() != callers-1 {
+		t.Errorf("%d queries, %d shared results", queries.Load(), sharedCount.Load())
+	}
+	if s := g.Stats(); s != (Stats{Executed: 1, Coalesced: callers - 1}) {
+		t.Errorf("stats = %+v", s)
+	}
+	got := map[string]float64{}
+	for _, f := range reg.Collect() {
+		for _, s := range f.Series {
+			got[s.LabelValues[0]+","+s.LabelValues[1]] = s.Value
+		}
+	}
+	if got["users,executed"] != 1 || got["users,coalesced"] != callers-1 {
+		t.Errorf("%s = %v", MetricCalls, got)
+	}
+
+	// The result is not kept.
+	if _, shared, _ := g.Do(context.Background(), 1, fn); shared || queries.Load() != 2 {
+		t.Errorf("second call shared = %v after %d queries", shared, queries.Load())
+	}
+}
+
+func TestSingleflightErrors(t *testing.T) {
+	var g Singleflight[string, int]
+	boom := errors.New("boom")
+	if _, _, err := g.Do(context.Background(), "a", func(context.Context) (int, error) { return 0, boom }); err != boom {
+		t.Errorf("error = %v", err)
+	}
+	_, _, err := g.Do(context.Background(), "a", func(context.Context) (int, error) { panic("bad") })
+	var pe *recovery.PanicError
+	if !errors.As(err, &pe) {
+		t.Errorf("panic returned %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := g.Do(ctx, "b", func(context.Context) (int, error) { <-release; return 1, nil }); err != context.Canceled {
+		t.Errorf("canceled caller got %v", err)
+	}
+}
+
+func TestSingleflightForget(t *testing.T) {
+	var g Singleflight[int, int]
+	release := make(chan struct{})
+	go g.Do(context.Background(), 1, func(context.Context) (int, error) { <-release; return 1, nil })
+	waitCalls(t, &g, 1)
+	g.Forget(1)
+	v, shared, _ := g.Do(context.Background(), 1, func(context.Context) (int, error) { return 2, nil })
+	close(release)
+	if v != 2 || shared {
+		t.Errorf("Do after Forget = %d, shared %v", v, shared)
+	}
+}
+
+func TestSingleflightDoStore(t *testing.T) {
+	var g Singleflight[int, int]
+	var stored []int
+	store := func(v int) { stored = append(stored, v) }
+	g.DoStore(context.Background(), 1, func(context.Context) (int, error) { return 1, nil }, store)
+	g.DoStore(context.Background(), 1, func(context.Context) (int, error) { return 0, errors.New("boom") }, store)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.DoStore(context.Background(), 2, func(context.Context) (int, error) { <-release; return 2, nil }, store)
+	}()
+	waitCalls(t, &g, 3)
+	g.Forget(2)
+	close(release)
+	<-done
+	if len(stored) != 1 || stored[0] != 1 {
+		t.Errorf("stored %v", stored)
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	var queries atomic.Int32
+	fail := false
+	getUser := Memoize(func(_ context.Context, id int) (string, error) {
+		queries.Add(1)
+		if fail {
+			return "", errors.New("db down")
+		}
+		return "user", nil
+	}, time.Minute, WithClock(clk))
+
+	ctx := context.Background()
+	for range 3 {
+		if u, err := getUser(ctx, 1); u != "user" || err != nil {
+			t.Fatalf("getUser = %q, %v", u, err)
+		}
+	}
+	if queries.Load() != 1 {
+		t.Errorf("%d queries within the TTL", queries.Load())
+	}
+	clk.Advance(time.Minute)
+	getUser(ctx, 1)
+	if queries.Load() != 2 {
+		t.Errorf("%d queries after the TTL", queries.Load())
+	}
+
+	fail = true
+	for range 2 {
+		if _, err := getUser(ctx, 2); err == nil {
+			t.Error("error not returned")
+		}
+	}
+	if queries.Load() != 4 {
+		t.Errorf("%d queries, errors were kept", queries.Load())
+	}
+}
+
+func TestMemoizeCoalesces(t *testing.T) {
+	release := make(chan struct{})
+	var queries atomic.Int32
+	getUser := Memoize(func(context.Context, int) (string, error) {
+		queries.Add(1)
+		<-release
+		return "user", nil
+	}, 0)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() { getUser(context.Background(), 1) })
+	}
+	close(release)
+	wg.Wait()
+	if queries.Load() != 1 {
+		t.Errorf("%d queries for concurrent calls", queries.Load())
+	}
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetry
+
+import (
+	"strings"
+
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// ScrubOption configures a Scrubber.
+type ScrubOption func(*Scrubber)
+
+// WithRedactor redacts attribute values with r: values of sensitive keys
+// are replaced and secrets in strings masked.
+func WithRedactor(r *log.Redactor) ScrubOption {
+	return func(s *Scrubber) { s.redactor = r }
+}
+
+// WithDroppedKeys drops the attributes with these keys, matched
+// case-insensitively.
+func WithDroppedKeys(keys ...string) ScrubOption {
+	return func(s *Scrubber) {
+		for _, k := range keys {
+			if k = strings.TrimSpace(k); k != "" {
+				s.drop[strings.ToLower(k)] = struct{}{}
+			}
+		}
+	}
+}
+
+// Scrubber cleans attributes before they are exported. A nil Scrubber
+// keeps every attribute as it is.
+type Scrubber struct {
+	redactor *log.Redactor
+	drop     map[string]struct{}
+}
+
+// NewScrubber returns a scrubber that does what opts ask and nothing
+// else.
+func NewScrubber(opts ...ScrubOption) *Scrubber {
+	s := &Scrubber{drop: map[string]struct{}{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Scrub returns the value to export for the attribute key, and false if
+// it is dropped.
+func (s *Scrubber) Scrub(key string, v any) (any, bool) {
+	if s == nil {
+		return v, true
+	}
+	if _, ok := s.drop[strings.ToLower(key)]; ok {
+		return nil, false
+	}
+	if s.redactor == nil {
+		return v, true
+	}
+	return s.redactor.RedactAttr(key, v), true
+}
+
+// ScrubLabel is Scrub for metric labels, which are never dropped, since
+// series would collide without them: a dropped label keeps its key with
+// log.RedactedValue as its value.
+func (s *Scrubber) ScrubLabel(key string, v any) (any, bool) {
+	out, ok := s.Scrub(key, v)
+	if !ok {
+		return log.RedactedValue, true
+	}
+	return out, true
+}
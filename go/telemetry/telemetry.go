@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package telemetry decides what telemetry leaves the process. The OTLP
+// log, trace and metric exporters and the crash files of the recovery
+// package consult the process-wide Settings before every export, so
+// telemetry can be turned off without touching the code that produces
+// it:
+//
+//	FOUNDATION_TELEMETRY_DISABLED=true            # nothing is exported
+//	FOUNDATION_TELEMETRY_TRACES=false             # everything but spans
+//	FOUNDATION_TELEMETRY_DROP_ATTRIBUTES=user.email,client.address
+//
+// The settings are read from the environment on first use, where
+// OTEL_SDK_DISABLED and PROVIDE_TELEMETRY_DISABLED, shared with the
+// Python foundation, also turn everything off. An invalid value turns
+// everything off too, so a typo never exports what it meant to keep in.
+// Set replaces them at runtime:
+//
+//	s := telemetry.Current()
+//	s.Scrubber = telemetry.NewScrubber(
+//		telemetry.WithRedactor(log.NewRedactor(log.WithRedactPatterns(log.EmailPattern))),
+//		telemetry.WithDroppedKeys("user.id"))
+//	telemetry.Set(s)
+//
+// A Scrubber drops attributes by key and redacts the values of the rest
+// before they are encoded, in resources, spans, span events, log records
+// and metric labels.
+package telemetry
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/provide-io/provide-foundation/go/config"
+	"github.com/provide-io/provide-foundation/go/log"
+)
+
+// Variables that turn every signal off besides Settings.Disabled.
+const (
+	EnvSDKDisabled    = "OTEL_SDK_DISABLED"
+	EnvPythonDisabled = "PROVIDE_TELEMETRY_DISABLED"
+)
+
+// Signal is a kind of telemetry with its own toggle.
+type Signal string
+
+// Signals.
+const (
+	Traces       Signal = "traces"
+	Metrics      Signal = "metrics"
+	Logs         Signal = "logs"
+	CrashReports Signal = "crash_reports"
+)
+
+// Settings control what telemetry is exported.
+type Settings struct {
+	Disabled     bool `config:"disabled" env:"FOUNDATION_TELEMETRY_DISABLED" desc:"Export no telemetry at all."`
+	Traces       bool `config:"traces" env:"FOUNDATION_TELEMETRY_TRACES" default:"true" desc:"Export spans."`
+	Metrics      bool `config:"metrics" env:"FOUNDATION_TELEMETRY_METRICS" default:"true" desc:"Export metrics over OTLP."`
+	Logs         bool `config:"logs" env:"FOUNDATION_TELEMETRY_LOGS" default:"true" desc:"Export log records over OTLP."`
+	CrashReports bool `config:"crash_reports" env:"FOUNDATION_TELEMETRY_CRASH_REPORTS" default:"true" desc:"Write crash files with goroutine dumps."`
+
+	Scrub          bool     `config:"scrub" env:"FOUNDATION_TELEMETRY_SCRUB" desc:"Redact sensitive attribute values with the log redaction defaults."`
+	DropAttributes []string `config:"drop_attributes" env:"FOUNDATION_TELEMETRY_DROP_ATTRIBUTES" desc:"Attribute keys never exported, such as user.email."`
+
+	// Scrubber cleans exported attributes. When nil, Set builds one from
+	// Scrub and DropAttributes if either is set.
+	Scrubber *Scrubber `config:"-"`
+}
+
+// Enabled reports whether sig may be exported.
+func (s Settings) Enabled(sig Signal) bool {
+	if s.Disabled {
+		return false
+	}
+	switch sig {
+	case Traces:
+		return s.Traces
+	case Metrics:
+		return s.Metrics
+	case Logs:
+		return s.Logs
+	case CrashReports:
+		return s.CrashReports
+	}
+	return false
+}
+
+// FromEnv reads the settings from the environment. When a value is
+// invalid it returns the error with every signal disabled.
+func FromEnv() (Settings, error) {
+	var s Settings
+	if err := config.Bind(&s); err != nil {
+		return Settings{Disabled: true}, err
+	}
+	for _, name := range []string{EnvSDKDisabled, EnvPythonDisabled} {
+		if v, ok := os.LookupEnv(name); ok && !isFalse(v) {
+			s.Disabled = true
+		}
+	}
+	return s, nil
+}
+
+// isFalse reports whether v is empty or a false boolean, the only values
+// of the disabling variables that leave telemetry on.
+func isFalse(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "false", "no", "0", "off":
+		return true
+	}
+	return false
+}
+
+var current atomic.Pointer[Settings]
+
+// Current returns the settings in effect, read with FromEnv on first
+// use. An invalid environment is logged as telemetry_settings_invalid.
+func Current() Settings {
+	if s := current.Load(); s != nil {
+		return *s
+	}
+	s, err := FromEnv()
+	s = withScrubber(s)
+	if !current.CompareAndSwap(nil, &s) {
+		return *current.Load()
+	}
+	// Logged once the settings are stored, as the record may be
+	// exported, which asks for them again.
+	if err != nil {
+		log.Default().Warn("telemetry_settings_invalid", "disabled", true, log.Err(err))
+	}
+	return s
+}
+
+// Set replaces the settings in effect. Exports already encoded are not
+// affected.
+func Set(s Settings) {
+	s = withScrubber(s)
+	current.Store(&s)
+}
+
+// Enabled reports whether the current settings let sig be exported.
+func Enabled(sig Signal) bool { return Current().Enabled(sig) }
+
+func withScrubber(s Settings) Settings {
+	if s.Scrubber != nil || (!s.Scrub && len(s.DropAttributes) == 0) {
+		return s
+	}
+	var opts []ScrubOption
+	if s.Scrub {
+		opts = append(opts, WithRedactor(log.NewRedactor()))
+	}
+	s.Scrubber = NewScrubber(append(opts, WithDroppedKeys(s.DropAttributes...))...)
+	return s
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/log"
+	"github.com/provide-io/provide-foundation/go/log/logtest"
+)
+
+// fresh makes Current read the environment again, and restores the
+// settings when the test ends.
+func fresh(t *testing.T) {
+	old := current.Load()
+	current.Store(nil)
+	t.Cleanup(func() { current.Store(old) })
+}
+
+func TestFromEnv(t *testing.T) {
+	s, err := FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sig := range []Signal{Traces, Metrics, Logs, CrashReports} {
+		if !s.Enabled(sig) {
+			t.Errorf("%s disabled by default", sig)
+		}
+	}
+
+	t.Setenv("FOUNDATION_TELEMETRY_TRACES", "off")
+	t.Setenv("FOUNDATION_TELEMETRY_DROP_ATTRIBUTES", "user.email, client.address")
+	s, err = FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Enabled(Traces) || !s.Enabled(Metrics) || len(s.DropAttributes) != 2 {
+		t.Errorf("settings = %+v", s)
+	}
+
+	for _, name := range []string{"FOUNDATION_TELEMETRY_DISABLED", EnvSDKDisabled, EnvPythonDisabled} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, "true")
+			if s, _ := FromEnv(); s.Enabled(Metrics) {
+				t.Errorf("%s=true leaves metrics enabled", name)
+			}
+			t.Setenv(name, "false")
+			if s, _ := FromEnv(); !s.Enabled(Metrics) {
+				t.Errorf("%s=false disables metrics", name)
+			}
+		})
+	}
+}
+
+func TestInvalidEnvDisables(t *testing.T) {
+	fresh(t)
+	rec := logtest.Capture(t)
+	t.Setenv("FOUNDATION_TELEMETRY_DISABLED", "nope")
+	if _, err := FromEnv(); err == nil {
+		t.Error("no error for an invalid value")
+	}
+	if Enabled(Logs) {
+		t.Error("logs enabled by an invalid environment")
+	}
+	if rec.Records().WithEvent("telemetry_settings_invalid").Count() != 1 {
+		t.Error("invalid settings not logged once")
+	}
+}
+
+func TestSet(t *testing.T) {
+	fresh(t)
+	Set(Settings{Traces: true, DropAttributes: []string{"User.Email"}})
+	s := Current()
+	if !Enabled(Traces) || Enabled(Metrics) {
+		t.Errorf("Set settings = %+v", s)
+	}
+	if _, ok := s.Scrubber.Scrub("user.email", "a@example.com"); ok {
+		t.Error("dropped attribute kept")
+	}
+	if v, ok := s.Scrubber.Scrub("password", "hunter2"); !ok || v != "hunter2" {
+		t.Errorf("Scrub without redaction = %v, %v", v, ok)
+	}
+
+	Set(Settings{Scrub: true})
+	if v, _ := Current().Scrubber.Scrub("password", "hunter2"); v != log.RedactedValue {
+		t.Errorf("scrubbed password = %v", v)
+	}
+	Set(Settings{})
+	if Current().Scrubber != nil {
+		t.Error("scrubber built with nothing to scrub")
+	}
+}
+
+func TestScrubber(t *testing.T) {
+	var none *Scrubber
+	if v, ok := none.Scrub("user.email", "a@example.com"); !ok || v != "a@example.com" {
+		t.Errorf("nil Scrub = %v, %v", v, ok)
+	}
+	s := NewScrubber(
+		WithRedactor(log.NewRedactor(log.WithRedactPatterns(log.EmailPattern))),
+		WithDroppedKeys("user.id"),
+	)
+	if v, _ := s.Scrub("note", "mail a@example.com"); v != "mail "+log.MaskedValue {
+		t.Errorf("Scrub = %v", v)
+	}
+	if v, ok := s.ScrubLabel("user.id", "42"); !ok || v != log.RedactedValue {
+		t.Errorf("ScrubLabel of a dropped label = %v, %v", v, ok)
+	}
+	if v, ok := s.ScrubLabel("route", "/users"); !ok || v != "/users" {
+		t.Errorf("ScrubLabel = %v, %v", v, ok)
+	}
+}
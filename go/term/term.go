@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package term decides what console output may contain, so the logger's
+// console lines and the cli's Pout and Perr look right in a terminal and
+// stay plain in CI logs and files:
+//
+//	caps := term.Detect(os.Stderr)
+//	if caps.Color {
+//		line = term.Style{Color: term.Red, Bold: true}.Render(line)
+//	}
+//
+// Colors follow the conventions of no-color.org and force-color.org:
+// FORCE_COLOR turns them on even for pipes, NO_COLOR or TERM=dumb turns
+// them off, and otherwise a stream is colored when it is a terminal.
+// PROVIDE_NO_EMOJI, shared with the Python CLI, turns off emoji markers.
+// The colors of log lines come from a Theme; replace DefaultTheme to
+// restyle every logger at once.
+package term
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by Detect.
+const (
+	EnvNoColor    = "NO_COLOR"
+	EnvForceColor = "FORCE_COLOR"
+	EnvNoEmoji    = "PROVIDE_NO_EMOJI"
+)
+
+// Caps is what a stream can display.
+type Caps struct {
+	// Terminal reports whether the stream is a terminal.
+	Terminal bool
+	// Color reports whether ANSI styles may be written.
+	Color bool
+	// Emoji reports whether emoji may be written. Unlike colors they
+	// survive pipes and files, so only PROVIDE_NO_EMOJI and TERM=dumb
+	// turn them off.
+	Emoji bool
+}
+
+// Detect returns the capabilities of w from the environment and whether
+// w is a terminal.
+func Detect(w io.Writer) Caps {
+	tty := IsTerminal(w)
+	dumb := os.Getenv("TERM") == "dumb"
+	color := tty && !dumb
+	switch {
+	case Forced():
+		color = true
+	case os.Getenv(EnvNoColor) != "":
+		color = false
+	}
+	noEmoji, _ := strconv.ParseBool(os.Getenv(EnvNoEmoji))
+	return Caps{Terminal: tty, Color: color, Emoji: !noEmoji && !dumb}
+}
+
+// Forced reports whether FORCE_COLOR turns colors on, whatever the
+// stream and the other settings: it does for any value but "", "0" and
+// false ones, as levels such as "2" are common. Options that turn colors
+// off, such as --no-color, give way to it.
+func Forced() bool {
+	v := os.Getenv(EnvForceColor)
+	if v == "" || v == "0" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	return err != nil || b
+}
+
+// IsTerminal reports whether v is a file open on a terminal.
+func IsTerminal(v any) bool {
+	f, ok := v.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Color is a terminal foreground color.
+type Color int
+
+// Colors, as ANSI codes.
+const (
+	Red Color = 31 + iota
+	Green
+	Yellow
+	Blue
+	Magenta
+	Cyan
+	White
+)
+
+// Style is the color and weight of some text. The zero Style is plain.
+type Style struct {
+	Color     Color
+	Bold, Dim bool
+}
+
+// Render wraps s in the ANSI codes of the style.
+func (st Style) Render(s string) string {
+	if st == (Style{}) {
+		return s
+	}
+	return string(st.Append(nil, s))
+}
+
+// Append appends s wrapped in the ANSI codes of the style to buf.
+func (st Style) Append(buf []byte, s string) []byte {
+	return st.Close(append(st.Open(buf), s...))
+}
+
+// Open appends the ANSI codes that start the style to buf, for text
+// appended in place. It appends nothing for the zero Style.
+func (st Style) Open(buf []byte) []byte {
+	if st == (Style{}) {
+		return buf
+	}
+	buf = append(buf, "\x1b["...)
+	sep := false
+	code := func(c int) {
+		if sep {
+			buf = append(buf, ';')
+		}
+		buf = strconv.AppendInt(buf, int64(c), 10)
+		sep = true
+	}
+	if st.Bold {
+		code(1)
+	}
+	if st.Dim {
+		code(2)
+	}
+	if st.Color != 0 {
+		code(int(st.Color))
+	}
+	return append(buf, 'm')
+}
+
+// Close appends the ANSI code that ends a style opened with Open.
+func (st Style) Close(buf []byte) []byte {
+	if st == (Style{}) {
+		return buf
+	}
+	return append(buf, "\x1b[0m"...)
+}
+
+// Theme holds the styles of console log lines.
+type Theme struct {
+	// Levels styles the level of a line by lower-case level name, such as
+	// "warning".
+	Levels    map[string]Style
+	Timestamp Style
+	Event     Style
+	Key       Style
+}
+
+// Level returns the style of the level named name, plain if the theme has
+// none.
+func (t *Theme) Level(name string) Style {
+	return t.Levels[strings.ToLower(name)]
+}
+
+// DefaultTheme styles the console log lines of loggers given no theme of
+// their own. Replace it at startup, before anything is logged.
+var DefaultTheme = &Theme{
+	Levels: map[string]Style{
+		"trace":    {Dim: true},
+		"debug":    {Color: Blue},
+		"info":     {Color: Green},
+		"warning":  {Color: Yellow},
+		"error":    {Color: Red},
+		"critical": {Color: Red, Bold: true},
+		"fatal":    {Color: Magenta, Bold: true},
+	},
+	Timestamp: Style{Dim: true},
+	Event:     Style{Bold: true},
+	Key:       Style{Color: Cyan},
+}
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package term
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		env          map[string]string
+		color, emoji bool
+	}{
+		{"pipe", nil, false, true},
+		{"forced", map[string]string{EnvForceColor: "1"}, true, true},
+		{"forced level", map[string]string{EnvForceColor: "3"}, true, true},
+		{"forced off", map[string]string{EnvForceColor: "false"}, false, true},
+		{"forced over no color", map[string]string{EnvForceColor: "true", EnvNoColor: "1"}, true, true},
+		{"dumb", map[string]string{"TERM": "dumb"}, false, false},
+		{"no emoji", map[string]string{EnvNoEmoji: "true"}, false, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{EnvForceColor, EnvNoColor, EnvNoEmoji, "TERM"} {
+				t.Setenv(k, tt.env[k])
+			}
+			caps := Detect(&bytes.Buffer{})
+			if caps.Terminal || caps.Color != tt.color || caps.Emoji != tt.emoji {
+				t.Errorf("Detect = %+v", caps)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if IsTerminal(f) || IsTerminal(&bytes.Buffer{}) {
+		t.Error("a file or buffer is reported as a terminal")
+	}
+}
+
+func TestStyle(t *testing.T) {
+	for _, tt := range []struct {
+		style Style
+		want  string
+	}{
+		{Style{}, "ok"},
+		{Style{Color: Green}, "\x1b[32mok\x1b[0m"},
+		{Style{Color: Red, Bold: true}, "\x1b[1;31mok\x1b[0m"},
+		{Style{Dim: true}, "\x1b[2mok\x1b[0m"},
+	} {
+		if got := tt.style.Render("ok"); got != tt.want {
+			t.Errorf("%+v.Render = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+	if got := DefaultTheme.Level("WARNING"); got != (Style{Color: Yellow}) {
+		t.Errorf("warning style = %+v", got)
+	}
+	if got := (&Theme{}).Level("info"); got != (Style{}) {
+		t.Errorf("empty theme style = %+v", got)
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testkit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the work of a line diff; larger inputs are printed
+// whole.
+const maxDiffCells = 1 << 20
+
+// diffValues describes how got differs from want: a line diff for text
+// spanning several lines, the two values otherwise.
+func diffValues(got, want any) string {
+	g, gok := text(got)
+	w, wok := text(want)
+	if gok && wok && (strings.Contains(g, "\n") || strings.Contains(w, "\n")) {
+		return Diff(g, w)
+	}
+	return fmt.Sprintf("got:  %#v\nwant: %#v", got, want)
+}
+
+func text(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
+
+// Diff returns a line diff turning want into got, in which lines only in
+// want start with "- ", lines only in got with "+ ", and common lines
+// with two spaces. It returns "" when they are equal.
+func Diff(got, want string) string {
+	if got == want {
+		return ""
+	}
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var out strings.Builder
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		fmt.Fprintf(&out, "want:\n%s\ngot:\n%s\n", want, got)
+		return out.String()
+	}
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&out, "  %s\n", a[i])
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&out, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&out, "+ %s\n", b[j])
+			j++
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package docker starts the dependencies of integration tests in Docker
+// containers, removed when the test ends:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"
+//
+//	func TestUserRepository(t *testing.T) {
+//		database := docker.Postgres(t)
+//		users, _ := repository.New[User](database, "users")
+//		...
+//	}
+//
+// Each helper waits until its service answers before returning. Tests
+// are skipped when Docker is not available or with -short, so the unit
+// tests of a package still run everywhere. Containers are run through the
+// docker command and publish their ports on 127.0.0.1.
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/process"
+)
+
+// DefaultTimeout bounds how long Run waits for a container to be ready.
+const DefaultTimeout = 2 * time.Minute
+
+// pollInterval is the delay between readiness checks.
+const pollInterval = 250 * time.Millisecond
+
+// command is the docker executable; tests replace it.
+var command = "docker"
+
+// Spec describes a container to run.
+type Spec struct {
+	// Image is the image to run, pulled if missing.
+	Image string
+	// Env is the environment of the container.
+	Env map[string]string
+	// Ports are the container ports to publish, such as "5432/tcp".
+	Ports []string
+	// Cmd replaces the command of the image when set.
+	Cmd []string
+	// Ready reports whether the container serves, and is called until it
+	// returns nil or Timeout passes. Without it the container is ready
+	// once started.
+	Ready func(ctx context.Context, c *Container) error
+	// Timeout bounds the wait for Ready; DefaultTimeout when zero.
+	Timeout time.Duration
+}
+
+// Container is a running container.
+type Container struct {
+	// ID is the container ID.
+	ID    string
+	addrs map[string]string
+}
+
+// Addr returns the host address port is published on, e.g.
+// "127.0.0.1:49153" for "5432/tcp".
+func (c *Container) Addr(port string) string { return c.addrs[port] }
+
+// Run starts a container from spec, waits until it is ready, and removes
+// it when the test ends. It skips the test when Docker is not available
+// or the tests run with -short, and fails it if the container does not
+// start or become ready.
+func Run(t testing.TB, spec Spec) *Container {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("docker: skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	if _, err := docker(ctx, "version", "--format", "{{.Server.Version}}"); err != nil {
+		t.Skipf("docker: not available: %v", err)
+	}
+
+	args := []string{"run", "--detach", "--rm"}
+	for k, v := range spec.Env {
+		args = append(args, "--env", k+"="+v)
+	}
+	for _, p := range spec.Ports {
+		args = append(args, "--publish", "127.0.0.1::"+p)
+	}
+	args = append(append(args, spec.Image), spec.Cmd...)
+	id, err := docker(ctx, args...)
+	if err != nil {
+		t.Fatalf("docker: starting %s: %v", spec.Image, err)
+	}
+	c := &Container{ID: id, addrs: map[string]string{}}
+	t.Cleanup(func() {
+		if _, err := docker(context.Background(), "rm", "--force", "--volumes", c.ID); err != nil {
+			t.Errorf("docker: removing %s: %v", spec.Image, err)
+		}
+	})
+	for _, p := range spec.Ports {
+		out, err := docker(ctx, "port", id, p)
+		if err != nil {
+			t.Fatalf("docker: port %s of %s: %v", p, spec.Image, err)
+		}
+		// One line per address family; the first is the IPv4 binding.
+		addr, _, _ := strings.Cut(out, "\n")
+		c.addrs[p] = addr
+	}
+
+	if spec.Ready == nil {
+		return c
+	}
+	timeout := spec.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if err := c.wait(ctx, spec.Ready, timeout); err != nil {
+		logs, _ := docker(ctx, "logs", "--tail", "20", id)
+		t.Fatalf("docker: %s not ready after %v: %v\n%s", spec.Image, timeout, err, logs)
+	}
+	return c
+}
+
+// wait calls ready until it returns nil or timeout passes, and returns
+// its last error.
+func (c *Container) wait(ctx context.Context, ready func(context.Context, *Container) error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		err := ready(ctx, c)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// docker runs the docker command with args and returns its trimmed
+// standard output.
+func docker(ctx context.Context, args ...string) (string, error) {
+	res, err := process.Run(ctx, append([]string{command}, args...), process.WithTimeout(DefaultTimeout))
+	if err != nil {
+		return "", err
+	}
+	out := strings.TrimSpace(string(res.Stdout))
+	if out == "" && args[0] != "rm" {
+		return "", fmt.Errorf("docker %s: no output", args[0])
+	}
+	return out, nil
+}
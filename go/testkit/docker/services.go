@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package docker
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/db"
+)
+
+// Default images of the services.
+const (
+	PostgresImage = "postgres:16-alpine"
+	RedisImage    = "redis:7-alpine"
+)
+
+// Option configures a service container.
+type Option func(*options)
+
+type options struct {
+	image  string
+	driver string
+	env    map[string]string
+}
+
+// WithImage replaces the default image of the service, e.g. to test
+// against the version run in production.
+func WithImage(image string) Option {
+	return func(o *options) { o.image = image }
+}
+
+// WithDriver sets the database/sql driver Postgres opens the database
+// with. The default is the one db.Drivers maps "postgres" to.
+func WithDriver(name string) Option {
+	return func(o *options) { o.driver = name }
+}
+
+// WithEnv adds environment variables to the container, e.g. settings of
+// the image.
+func WithEnv(env map[string]string) Option {
+	return func(o *options) {
+		if o.env == nil {
+			o.env = map[string]string{}
+		}
+		maps.Copy(o.env, env)
+	}
+}
+
+func newOptions(image string, opts []Option) options {
+	o := options{image: image, driver: db.Drivers["postgres"], env: map[string]string{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Postgres runs a PostgreSQL server and returns a database on it, closed
+// when the test ends. The database is empty; apply the schema with the
+// migrate package. The driver must be registered by the test binary,
+// e.g. by importing github.com/jackc/pgx/v5/stdlib.
+func Postgres(t testing.TB, opts ...Option) *db.DB {
+	t.Helper()
+	o := newOptions(PostgresImage, opts)
+	if !slices.Contains(sql.Drivers(), o.driver) {
+		t.Fatalf("docker: database/sql driver %q is not registered; import it in the test", o.driver)
+	}
+	env := map[string]string{"POSTGRES_USER": "test", "POSTGRES_PASSWORD": "test", "POSTGRES_DB": "test"}
+	maps.Copy(env, o.env)
+	var database *db.DB
+	Run(t, Spec{
+		Image: o.image,
+		Env:   env,
+		Ports: []string{"5432/tcp"},
+		Ready: func(ctx context.Context, c *Container) error {
+			dsn := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable",
+				env["POSTGRES_USER"], env["POSTGRES_PASSWORD"], c.Addr("5432/tcp"), env["POSTGRES_DB"])
+			var err error
+			database, err = db.Open(ctx, db.Config{DSN: dsn, Driver: o.driver, ConnectTimeout: time.Until(deadline(ctx))})
+			return err
+		},
+	})
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+// Redis runs a Redis server and returns its address, for the Redis
+// client of the code under test.
+func Redis(t testing.TB, opts ...Option) string {
+	t.Helper()
+	o := newOptions(RedisImage, opts)
+	c := Run(t, Spec{
+		Image: o.image,
+		Env:   o.env,
+		Ports: []string{"6379/tcp"},
+		Ready: func(ctx context.Context, c *Container) error {
+			return ping(ctx, c.Addr("6379/tcp"))
+		},
+	})
+	return c.Addr("6379/tcp")
+}
+
+// ping sends a Redis PING to addr and checks the reply.
+func ping(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline(ctx))
+	if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if line != "+PONG\r\n" {
+		return fmt.Errorf("PING answered %q", line)
+	}
+	return nil
+}
+
+// deadline returns the deadline of ctx, or a second from now.
+func deadline(ctx context.Context) time.Time {
+	if d, ok := ctx.Deadline(); ok {
+		return d
+	}
+	return time.Now().Add(time.Second)
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/provide-io/provide-foundation/go/deprecate"
+	"github.com/provide-io/provide-foundation/go/env"
+)
+
+// TempFile writes content to a file called name in a new temporary
+// directory and returns its path. The directory is removed when the test
+// ends.
+func TempFile(t testing.TB, name, content string) string {
+	t.Helper()
+	return filepath.Join(TempFiles(t, map[string]string{name: content}), filepath.FromSlash(name))
+}
+
+// TempFiles creates a temporary directory holding files, by slash
+// separated path relative to the directory, and returns the directory.
+// Parent directories are created as needed. The directory is removed
+// when the test ends.
+func TempFiles(t testing.TB, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("testkit: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("testkit: %v", err)
+		}
+	}
+	return dir
+}
+
+// ReadFile returns the content of the file at path, failing the test if
+// it cannot be read.
+func ReadFile(t testing.TB, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("testkit: %v", err)
+	}
+	return string(data)
+}
+
+// IsolateEnv clears the environment for the rest of the test, keeping
+// only the variables named in keep, and restores it when the test ends.
+// Set variables with t.Setenv afterwards. Like t.Setenv, it cannot be
+// used in parallel tests.
+func IsolateEnv(t testing.TB, keep ...string) {
+	t.Helper()
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		if k == "" || slices.Contains(keep, k) {
+			continue
+		}
+		t.Setenv(k, v) // restores k when the test ends
+		os.Unsetenv(k)
+	}
+}
+
+// Unsetenv unsets the environment variable key for the rest of the test
+// and restores it when the test ends.
+func Unsetenv(t testing.TB, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+// Profile sets the current env profile for the rest of the test and
+// restores the previous one when it ends.
+func Profile(t testing.TB, p env.Profile) {
+	t.Helper()
+	prev := env.Current()
+	env.Set(p)
+	t.Cleanup(func() { env.Set(prev) })
+}
+
+// FailOnDeprecation fails the test at each use of a deprecated API
+// reported with deprecate.Warn until it ends. Tests using it must not run
+// in parallel with others reporting deprecations.
+func FailOnDeprecation(t testing.TB) {
+	t.Helper()
+	t.Cleanup(deprecate.SetHandler(func(_ context.Context, w *deprecate.Warning) {
+		t.Errorf("%v", w)
+	}))
+}
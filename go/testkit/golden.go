@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update is the -update flag of test binaries importing the package:
+//
+//	go test ./... -update
+var update = flag.Bool("update", false, "update golden files with the current output")
+
+// GoldenDir is the directory golden files are kept in, relative to the
+// package under test.
+const GoldenDir = "testdata"
+
+// Golden compares got with the golden file testdata/<name>.golden and
+// reports a line diff when they differ. Run the tests with -update to
+// write got to the file instead, then review the change in version
+// control. A missing golden file fails the test unless -update is set.
+func Golden(t testing.TB, name string, got []byte) bool {
+	t.Helper()
+	path := GoldenPath(name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("testkit: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("testkit: %v", err)
+		}
+		return true
+	}
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("golden file %s does not exist; run the test with -update to create it", path)
+		return false
+	}
+	if err != nil {
+		t.Fatalf("testkit: %v", err)
+	}
+	if bytes.Equal(got, want) {
+		return true
+	}
+	t.Errorf("output differs from %s (-want +got); run the test with -update to accept it:\n%s",
+		path, Diff(string(got), string(want)))
+	return false
+}
+
+// GoldenString is Golden for text.
+func GoldenString(t testing.TB, name, got string) bool {
+	t.Helper()
+	return Golden(t, name, []byte(got))
+}
+
+// GoldenJSON is Golden for v encoded as indented JSON, so diffs show the
+// fields that changed.
+func GoldenJSON(t testing.TB, name string, v any) bool {
+	t.Helper()
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("testkit: encoding %s: %v", name, err)
+	}
+	return Golden(t, name, append(data, '\n'))
+}
+
+// GoldenPath returns the path of the golden file called name.
+func GoldenPath(name string) string {
+	return filepath.Join(GoldenDir, filepath.FromSlash(name)+".golden")
+}
+
+// Updating reports whether the tests run with -update, for tests that
+// keep golden data other than through Golden.
+func Updating() bool { return *update }
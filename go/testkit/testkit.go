@@ -0,0 +1,365 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package testkit holds the test helpers shared by the Go foundation and
+// the services built on it, the counterpart of the Python testkit:
+// assertions with readable failures, golden files, temporary file
+// fixtures and environment isolation.
+//
+//	func TestRender(t *testing.T) {
+//		testkit.IsolateEnv(t, "HOME")
+//		dir := testkit.TempFiles(t, map[string]string{"config.toml": "level = 'debug'"})
+//		out, err := Render(dir)
+//		testkit.Require(t).NoError(err)
+//		testkit.Golden(t, "render", out)
+//	}
+//
+// Assertions report failures with t.Errorf and return whether they held,
+// so a test goes on to check what else is wrong. Through Require they
+// stop the test with t.Fatalf instead.
+package testkit
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Equal asserts that got and want are deeply equal.
+func Equal(t testing.TB, got, want any, msg ...any) bool {
+	t.Helper()
+	if equal(got, want) {
+		return true
+	}
+	t.Errorf("%snot equal:\n%s", prefix(msg), diffValues(got, want))
+	return false
+}
+
+// NotEqual asserts that got and want are not deeply equal.
+func NotEqual(t testing.TB, got, unwanted any, msg ...any) bool {
+	t.Helper()
+	if !equal(got, unwanted) {
+		return true
+	}
+	t.Errorf("%sunexpectedly equal: %#v", prefix(msg), got)
+	return false
+}
+
+// True asserts that cond holds.
+func True(t testing.TB, cond bool, msg ...any) bool {
+	t.Helper()
+	if !cond {
+		t.Errorf("%sexpected true", prefix(msg))
+	}
+	return cond
+}
+
+// False asserts that cond does not hold.
+func False(t testing.TB, cond bool, msg ...any) bool {
+	t.Helper()
+	if cond {
+		t.Errorf("%sexpected false", prefix(msg))
+	}
+	return !cond
+}
+
+// Nil asserts that v is nil, including a nil pointer, map, slice,
+// channel or function held in an interface.
+func Nil(t testing.TB, v any, msg ...any) bool {
+	t.Helper()
+	if isNil(v) {
+		return true
+	}
+	t.Errorf("%sexpected nil, got %#v", prefix(msg), v)
+	return false
+}
+
+// NotNil asserts that v is not nil; see Nil.
+func NotNil(t testing.TB, v any, msg ...any) bool {
+	t.Helper()
+	if !isNil(v) {
+		return true
+	}
+	t.Errorf("%sexpected a value, got nil", prefix(msg))
+	return false
+}
+
+// NoError asserts that err is nil.
+func NoError(t testing.TB, err error, msg ...any) bool {
+	t.Helper()
+	if err == nil {
+		return true
+	}
+	t.Errorf("%sunexpected error: %v", prefix(msg), err)
+	return false
+}
+
+// Error asserts that err is not nil.
+func Error(t testing.TB, err error, msg ...any) bool {
+	t.Helper()
+	if err != nil {
+		return true
+	}
+	t.Errorf("%sexpected an error", prefix(msg))
+	return false
+}
+
+// ErrorIs asserts that errors.Is(err, target).
+func ErrorIs(t testing.TB, err, target error, msg ...any) bool {
+	t.Helper()
+	if errors.Is(err, target) {
+		return true
+	}
+	t.Errorf("%serror %v does not match %v", prefix(msg), err, target)
+	return false
+}
+
+// ErrorContains asserts that err is not nil and its message contains
+// substr.
+func ErrorContains(t testing.TB, err error, substr string, msg ...any) bool {
+	t.Helper()
+	if err != nil && strings.Contains(err.Error(), substr) {
+		return true
+	}
+	t.Errorf("%serror %v does not contain %q", prefix(msg), err, substr)
+	return false
+}
+
+// Contains asserts that container holds elem: a substring of a string,
+// an element of a slice or array, or a key of a map.
+func Contains(t testing.TB, container, elem any, msg ...any) bool {
+	t.Helper()
+	ok, valid := contains(container, elem)
+	if !valid {
+		t.Errorf("%scannot look for an element in %T", prefix(msg), container)
+		return false
+	}
+	if !ok {
+		t.Errorf("%s%#v does not contain %#v", prefix(msg), container, elem)
+	}
+	return ok
+}
+
+// NotContains asserts that container does not hold elem; see Contains.
+func NotContains(t testing.TB, container, elem any, msg ...any) bool {
+	t.Helper()
+	ok, valid := contains(container, elem)
+	if !valid {
+		t.Errorf("%scannot look for an element in %T", prefix(msg), container)
+		return false
+	}
+	if ok {
+		t.Errorf("%s%#v contains %#v", prefix(msg), container, elem)
+	}
+	return !ok
+}
+
+// Len asserts that v, a string, slice, array, map or channel, has n
+// elements.
+func Len(t testing.TB, v any, n int, msg ...any) bool {
+	t.Helper()
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+	default:
+		t.Errorf("%scannot take the length of %T", prefix(msg), v)
+		return false
+	}
+	if rv.Len() == n {
+		return true
+	}
+	t.Errorf("%slength %d, want %d: %#v", prefix(msg), rv.Len(), n, v)
+	return false
+}
+
+// Panics asserts that fn panics, and returns the value it panicked with.
+func Panics(t testing.TB, fn func(), msg ...any) (v any) {
+	t.Helper()
+	panicked := true
+	func() {
+		defer func() { v = recover() }()
+		fn()
+		panicked = false
+	}()
+	if !panicked {
+		t.Errorf("%sexpected a panic", prefix(msg))
+	}
+	return v
+}
+
+// Eventually asserts that cond holds within timeout, checking it every
+// tick.
+func Eventually(t testing.TB, cond func() bool, timeout, tick time.Duration, msg ...any) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Errorf("%scondition not met within %v", prefix(msg), timeout)
+			return false
+		}
+		time.Sleep(tick)
+	}
+	return true
+}
+
+// Require returns the assertions of the package bound to t, stopping
+// the test at the first failure:
+//
+//	testkit.Require(t).NoError(err)
+func Require(t testing.TB) Must { return Must{fatal{t}} }
+
+// Must holds the assertions of the package for a test that stops at its
+// first failure; see Require.
+type Must struct{ t testing.TB }
+
+// fatal turns Errorf and Error into Fatalf and Fatal.
+type fatal struct{ testing.TB }
+
+func (f fatal) Errorf(format string, args ...any) { f.TB.Helper(); f.TB.Fatalf(format, args...) }
+func (f fatal) Error(args ...any)                 { f.TB.Helper(); f.TB.Fatal(args...) }
+
+// Equal is Equal that stops the test on failure.
+func (m Must) Equal(got, want any, msg ...any) {
+	m.t.Helper()
+	Equal(m.t, got, want, msg...)
+}
+
+// NotEqual is NotEqual that stops the test on failure.
+func (m Must) NotEqual(got, unwanted any, msg ...any) {
+	m.t.Helper()
+	NotEqual(m.t, got, unwanted, msg...)
+}
+
+// True is True that stops the test on failure.
+func (m Must) True(cond bool, msg ...any) {
+	m.t.Helper()
+	True(m.t, cond, msg...)
+}
+
+// False is False that stops the test on failure.
+func (m Must) False(cond bool, msg ...any) {
+	m.t.Helper()
+	False(m.t, cond, msg...)
+}
+
+// Nil is Nil that stops the test on failure.
+func (m Must) Nil(v any, msg ...any) {
+	m.t.Helper()
+	Nil(m.t, v, msg...)
+}
+
+// NotNil is NotNil that stops the test on failure.
+func (m Must) NotNil(v any, msg ...any) {
+	m.t.Helper()
+	NotNil(m.t, v, msg...)
+}
+
+// NoError is NoError that stops the test on failure.
+func (m Must) NoError(err error, msg ...any) {
+	m.t.Helper()
+	NoError(m.t, err, msg...)
+}
+
+// Error is Error that stops the test on failure.
+func (m Must) Error(err error, msg ...any) {
+	m.t.Helper()
+	Error(m.t, err, msg...)
+}
+
+// ErrorIs is ErrorIs that stops the test on failure.
+func (m Must) ErrorIs(err, target error, msg ...any) {
+	m.t.Helper()
+	ErrorIs(m.t, err, target, msg...)
+}
+
+// ErrorContains is ErrorContains that stops the test on failure.
+func (m Must) ErrorContains(err error, substr string, msg ...any) {
+	m.t.Helper()
+	ErrorContains(m.t, err, substr, msg...)
+}
+
+// Contains is Contains that stops the test on failure.
+func (m Must) Contains(container, elem any, msg ...any) {
+	m.t.Helper()
+	Contains(m.t, container, elem, msg...)
+}
+
+// NotContains is NotContains that stops the test on failure.
+func (m Must) NotContains(container, elem any, msg ...any) {
+	m.t.Helper()
+	NotContains(m.t, container, elem, msg...)
+}
+
+// Len is Len that stops the test on failure.
+func (m Must) Len(v any, n int, msg ...any) {
+	m.t.Helper()
+	Len(m.t, v, n, msg...)
+}
+
+// Eventually is Eventually that stops the test on failure.
+func (m Must) Eventually(cond func() bool, timeout, tick time.Duration, msg ...any) {
+	m.t.Helper()
+	Eventually(m.t, cond, timeout, tick, msg...)
+}
+
+// prefix formats the optional message of an assertion: a format string
+// and its arguments, or values to print.
+func prefix(msg []any) string {
+	if len(msg) == 0 {
+		return ""
+	}
+	if format, ok := msg[0].(string); ok {
+		return fmt.Sprintf(format, msg[1:]...) + ": "
+	}
+	return fmt.Sprint(msg...) + ": "
+}
+
+func equal(got, want any) bool {
+	if g, ok := got.([]byte); ok {
+		if w, ok := want.([]byte); ok {
+			return string(g) == string(w)
+		}
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface, reflect.UnsafePointer:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// contains reports whether container holds elem, and whether container
+// is of a kind that holds elements at all.
+func contains(container, elem any) (ok, valid bool) {
+	if s, isString := container.(string); isString {
+		sub, isString := elem.(string)
+		return isString && strings.Contains(s, sub), true
+	}
+	rv := reflect.ValueOf(container)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := range rv.Len() {
+			if equal(rv.Index(i).Interface(), elem) {
+				return true, true
+			}
+		}
+		return false, true
+	case reflect.Map:
+		for _, k := range rv.MapKeys() {
+			if equal(k.Interface(), elem) {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package testkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/deprecate"
+	"github.com/provide-io/provide-foundation/go/env"
+)
+
+// recorder is a testing.TB recording failures instead of failing.
+type recorder struct {
+	testing.TB
+	errors   []string
+	fatal    bool
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Cleanup(fn func()) { r.cleanups = append(r.cleanups, fn) }
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+	runtime.Goexit()
+}
+
+// run calls fn with a recorder on its own goroutine, so Fatalf can end it.
+func run(fn func(t testing.TB)) *recorder {
+	r := &recorder{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(r)
+	}()
+	<-done
+	return r
+}
+
+func TestAssertions(t *testing.T) {
+	var nilPtr *int
+	err := fmt.Errorf("loading: %w", fs.ErrNotExist)
+	r := run(func(t testing.TB) {
+		// These hold.
+		Equal(t, []byte("a"), []byte("a"))
+		Equal(t, map[string]int{"a": 1}, map[string]int{"a": 1})
+		NotEqual(t, 1, 2)
+		True(t, true)
+		False(t, false)
+		Nil(t, nilPtr)
+		NotNil(t, err)
+		NoError(t, nil)
+		Error(t, err)
+		ErrorIs(t, err, fs.ErrNotExist)
+		ErrorContains(t, err, "loading")
+		Contains(t, "hello", "ell")
+		Contains(t, []int{1, 2}, 2)
+		Contains(t, map[string]int{"k": 1}, "k")
+		NotContains(t, []string{"a"}, "b")
+		Len(t, map[int]int{1: 1}, 1)
+		Eventually(t, func() bool { return true }, time.Second, time.Millisecond)
+		if v := Panics(t, func() { panic("boom") }); v != "boom" {
+			t.Errorf("recovered %v", v)
+		}
+	})
+	if len(r.errors) != 0 {
+		t.Fatalf("passing assertions failed: %q", r.errors)
+	}
+
+	r = run(func(t testing.TB) {
+		Equal(t, 1, 2, "user %d", 7)
+		Nil(t, 0)
+		ErrorIs(t, errors.New("x"), fs.ErrNotExist)
+		Contains(t, 42, 4)
+		Len(t, []int{1}, 2)
+		Panics(t, func() {})
+		Eventually(t, func() bool { return false }, 5*time.Millisecond, time.Millisecond)
+	})
+	if len(r.errors) != 7 || r.fatal {
+		t.Fatalf("failures %q", r.errors)
+	}
+	if r.errors[0] != "user 7: not equal:\ngot:  1\nwant: 2" {
+		t.Errorf("message %q", r.errors[0])
+	}
+}
+
+func TestRequire(t *testing.T) {
+	r := run(func(t testing.TB) {
+		Require(t).NoError(errors.New("first"))
+		t.Errorf("not reached")
+	})
+	if !r.fatal || len(r.errors) != 1 || !strings.Contains(r.errors[0], "first") {
+		t.Errorf("fatal %v, errors %q", r.fatal, r.errors)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := Diff("a\nB\nc\nd", "a\nb\nc")
+	want := "  a\n- b\n+ B\n  c\n+ d\n"
+	if got != want {
+		t.Errorf("Diff =\n%s", got)
+	}
+	if Diff("same", "same") != "" {
+		t.Error("diff of equal strings")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if r := run(func(t testing.TB) { GoldenString(t, "out/render", "a\nb\n") }); len(r.errors) != 1 ||
+		!strings.Contains(r.errors[0], "-update") {
+		t.Errorf("missing golden file: %q", r.errors)
+	}
+
+	*update = true
+	GoldenJSON(t, "out/render", map[string]int{"a": 1})
+	*update = false
+	if got := ReadFile(t, filepath.Join("testdata", "out", "render.golden")); got != "{\n  \"a\": 1\n}\n" {
+		t.Errorf("written %q", got)
+	}
+	GoldenJSON(t, "out/render", map[string]int{"a": 1})
+	r := run(func(t testing.TB) { GoldenJSON(t, "out/render", map[string]int{"a": 2}) })
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "-   \"a\": 1\n+   \"a\": 2") {
+		t.Errorf("changed output: %q", r.errors)
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	dir := TempFiles(t, map[string]string{"a.txt": "A", "sub/b.txt": "B"})
+	if ReadFile(t, filepath.Join(dir, "sub", "b.txt")) != "B" {
+		t.Error("nested file not written")
+	}
+	if path := TempFile(t, "c.toml", "C"); filepath.Base(path) != "c.toml" || ReadFile(t, path) != "C" {
+		t.Errorf("TempFile = %s", path)
+	}
+}
+
+func TestIsolateEnv(t *testing.T) {
+	t.Setenv("TESTKIT_KEPT", "1")
+	t.Setenv("TESTKIT_DROPPED", "1")
+	t.Run("isolated", func(t *testing.T) {
+		IsolateEnv(t, "TESTKIT_KEPT")
+		if os.Getenv("TESTKIT_KEPT") != "1" || os.Getenv("TESTKIT_DROPPED") != "" || os.Getenv("PATH") != "" {
+			t.Errorf("environment %q", os.Environ())
+		}
+		Profile(t, env.Production)
+		if !env.IsProduction() {
+			t.Error("profile not set")
+		}
+	})
+	if os.Getenv("TESTKIT_DROPPED") != "1" || os.Getenv("PATH") == "" {
+		t.Error("environment not restored")
+	}
+	if env.IsProduction() {
+		t.Error("profile not restored")
+	}
+}
+
+func TestFailOnDeprecation(t *testing.T) {
+	old := func() { deprecate.Warn(context.Background(), "old", "use new") }
+	r := run(func(t testing.TB) {
+		FailOnDeprecation(t)
+		old()
+		old()
+	})
+	if len(r.errors) != 2 || !strings.Contains(r.errors[0], "old is deprecated, use new") {
+		t.Errorf("errors = %q", r.errors)
+	}
+	for _, fn := range r.cleanups {
+		fn()
+	}
+	r = run(func(t testing.TB) { old() })
+	if len(r.errors) != 0 {
+		t.Errorf("deprecations still fail after cleanup: %q", r.errors)
+	}
+}
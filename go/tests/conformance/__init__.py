@@ -0,0 +1,8 @@
+#
+# SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+#
+
+"""Cross-language conformance scenarios."""
+
+# 🧱🏗️🔚
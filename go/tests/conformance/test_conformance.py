@@ -0,0 +1,141 @@
+#
+# SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+# SPDX-License-Identifier: Apache-2.0
+#
+
+"""Runs the conformance scenarios shared with the Go foundation.
+
+The scenarios in conformance/scenarios at the repository root are run by
+both the Python and the Go test suites, so a behavior that drifts in one
+language fails against the shared expectation. See conformance/README.md
+for the format.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Callable
+import json
+from pathlib import Path
+from typing import Any
+
+import pytest
+
+from provide.foundation.errors import NotFoundError
+from provide.foundation.hub import Container
+from provide.foundation.parsers import parse_bool, parse_module_levels
+from provide.foundation.parsers.telemetry import parse_log_level
+
+SCENARIO_DIR = Path(__file__).resolve().parents[2] / "conformance" / "scenarios"
+
+INVALID = {"error": "invalid"}
+NOT_FOUND = {"error": "not_found"}
+
+
+# The DI types of the scenarios.
+class Database:
+    def __init__(self, name: str) -> None:
+        self.name = name
+
+
+class Logger:
+    def __init__(self, name: str) -> None:
+        self.name = name
+
+
+class Cache:
+    def __init__(self, name: str) -> None:
+        self.name = name
+
+
+class UserService:
+    def __init__(self, db: Database, logger: Logger) -> None:
+        self.db = db
+        self.logger = logger
+
+
+class CachedUserService:
+    def __init__(self, db: Database, cache: Cache) -> None:
+        self.db = db
+        self.cache = cache
+
+
+COMPONENTS: dict[str, type[Any]] = {"Database": Database, "Logger": Logger, "Cache": Cache}
+SERVICES: dict[str, type[Any]] = {"UserService": UserService, "CachedUserService": CachedUserService}
+
+
+def _wire(register: dict[str, str]) -> Container:
+    container = Container()
+    for component, name in sorted(register.items()):
+        cls = COMPONENTS[component]
+        container.register(cls, cls(name))
+    return container
+
+
+def _parse_bool(value: Any) -> Any:
+    try:
+        return parse_bool(value)
+    except (TypeError, ValueError):
+        return INVALID
+
+
+def _parse_log_level(value: str) -> Any:
+    try:
+        return parse_log_level(value)
+    except ValueError:
+        return INVALID
+
+
+def _parse_module_levels(value: str) -> Any:
+    return dict(parse_module_levels(value))
+
+
+def _di_resolve(value: dict[str, Any]) -> Any:
+    container = _wire(value["register"])
+    try:
+        service = container.resolve(SERVICES[value["resolve"]])
+    except NotFoundError:
+        return NOT_FOUND
+    injected = {"Database": service.db.name}
+    if isinstance(service, UserService):
+        injected["Logger"] = service.logger.name
+    else:
+        injected["Cache"] = service.cache.name
+    return injected
+
+
+def _di_has(value: dict[str, Any]) -> Any:
+    return _wire(value["register"]).has(COMPONENTS[value["has"]])
+
+
+RUNNERS: dict[str, Callable[[Any], Any]] = {
+    "parse_bool": _parse_bool,
+    "parse_log_level": _parse_log_level,
+    "parse_module_levels": _parse_module_levels,
+    "di_resolve": _di_resolve,
+    "di_has": _di_has,
+}
+
+
+def _load_cases() -> list[Any]:
+    cases = []
+    for path in sorted(SCENARIO_DIR.glob("*.json")):
+        suite = json.loads(path.read_text())
+        for case in suite["cases"]:
+            cases.append(pytest.param(case, id=f"{suite['area']}/{case['name']}"))
+    return cases
+
+
+@pytest.mark.parametrize("case", _load_cases())
+def test_scenario(case: dict[str, Any]) -> None:
+    """Each scenario produces its expected output."""
+    runner = RUNNERS.get(case["op"])
+    assert runner is not None, f"no runner for op {case['op']!r}"
+    assert runner(case["input"]) == case["expect"]
+
+
+def test_scenarios_found() -> None:
+    """The scenario directory is where the runner looks."""
+    assert list(SCENARIO_DIR.glob("*.json")), f"no scenarios in {SCENARIO_DIR}"
+
+
+# 🧱🏗️🔚
@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package otlptrace exports spans to an OpenTelemetry collector over
+// OTLP/gRPC or OTLP/HTTP. The exporter is a trace.Exporter that batches
+// spans in the background:
+//
+//	exp, err := otlptrace.New()
+//	trace.SetExporter(exp)
+//	defer exp.Close(ctx)
+//
+// Configuration comes from the standard OTEL_EXPORTER_OTLP_* and
+// OTEL_RESOURCE_ATTRIBUTES variables unless overridden with options.
+package otlptrace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/batch"
+	"github.com/provide-io/provide-foundation/go/otlp"
+	"github.com/provide-io/provide-foundation/go/telemetry"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+// Defaults follow the OpenTelemetry batch span processor.
+const (
+	DefaultBatchSize      = 512
+	DefaultQueueSize      = 2048
+	DefaultExportInterval = 5 * time.Second
+)
+
+// scopeName is the instrumentation scope of exported spans.
+const scopeName = "github.com/provide-io/provide-foundation/go/trace"
+
+// Option configures an Exporter.
+type Option func(*Exporter)
+
+// WithConfig replaces the configuration read from the environment.
+func WithConfig(cfg otlp.Config) Option {
+	return func(e *Exporter) { e.cfg = &cfg }
+}
+
+// WithResource adds resource attributes such as service.version.
+// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES still take precedence.
+func WithResource(attrs ...otlp.KeyValue) Option {
+	return func(e *Exporter) { e.extraResource = append(e.extraResource, attrs...) }
+}
+
+// WithBatchSize sets the maximum number of spans per export request.
+func WithBatchSize(n int) Option {
+	return func(e *Exporter) {
+		if n > 0 {
+			e.batchSize = n
+		}
+	}
+}
+
+// WithQueueSize sets how many spans may wait for export; further spans
+// are dropped.
+func WithQueueSize(n int) Option {
+	return func(e *Exporter) {
+		if n > 0 {
+			e.queueSize = n
+		}
+	}
+}
+
+// WithExportInterval sets how often queued spans are exported when a
+// full batch has not accumulated.
+func WithExportInterval(d time.Duration) Option {
+	return func(e *Exporter) {
+		if d > 0 {
+			e.interval = d
+		}
+	}
+}
+
+// Exporter is a trace.Exporter that exports spans over OTLP.
+type Exporter struct {
+	cfg           *otlp.Config
+	extraResource []otlp.KeyValue
+	batchSize     int
+	queueSize     int
+	interval      time.Duration
+
+	client    *otlp.Client
+	resource  []otlp.KeyValue
+	batches   *batch.Processor[*trace.SpanData]
+	late      atomic.Uint64 // spans ended after Close
+	closeOnce sync.Once
+}
+
+// New returns an exporter and starts its background goroutine.
+func New(opts ...Option) (*Exporter, error) {
+	e := &Exporter{
+		batchSize: DefaultBatchSize,
+		queueSize: DefaultQueueSize,
+		interval:  DefaultExportInterval,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.cfg == nil {
+		cfg, err := otlp.ConfigFromEnv(otlp.SignalTraces)
+		if err != nil {
+			return nil, err
+		}
+		e.cfg = &cfg
+	}
+	client, err := otlp.NewClient(*e.cfg, otlp.SignalTraces)
+	if err != nil {
+		return nil, err
+	}
+	e.client = client
+	e.resource = otlp.ResourceFromEnv(e.extraResource...)
+	e.batches = batch.New(e.export,
+		batch.WithMaxSize(e.batchSize),
+		batch.WithQueueSize(e.queueSize),
+		batch.WithMaxWait(e.interval),
+		batch.WithErrorHandler(func(err error) { fmt.Fprintf(os.Stderr, "otlptrace: %v\n", err) }),
+	)
+	return e, nil
+}
+
+// ExportSpan implements trace.Exporter. The span is queued for export, or
+// dropped when the queue is full or the exporter is closed. Nothing is
+// queued while telemetry settings disable traces.
+func (e *Exporter) ExportSpan(s *trace.SpanData) {
+	if !telemetry.Enabled(telemetry.Traces) {
+		return
+	}
+	if err := e.batches.Add(context.Background(), s); errors.Is(err, batch.ErrClosed) {
+		e.late.Add(1)
+	}
+}
+
+// Dropped returns how many spans were discarded because the queue was
+// full or the exporter closed.
+func (e *Exporter) Dropped() uint64 { return e.batches.Dropped() + e.late.Load() }
+
+// Flush exports every queued span.
+func (e *Exporter) Flush(ctx context.Context) error { return e.batches.Flush(ctx) }
+
+// Close stops the background goroutine and exports what is queued. Spans
+// ended afterwards are dropped.
+func (e *Exporter) Close(ctx context.Context) error {
+	var err error
+	e.closeOnce.Do(func() {
+		err = e.batches.Close(ctx)
+		e.client.Close()
+	})
+	return err
+}
+
+func (e *Exporter) export(ctx context.Context, spans []*trace.SpanData) error {
+	return e.client.Export(ctx, e.encode(spans))
+}
+
+// encode builds an ExportTraceServiceRequest, its attributes cleaned by
+// the telemetry scrubber.
+func (e *Exporter) encode(batch []*trace.SpanData) []byte {
+	var enc otlp.Encoder
+	if s := telemetry.Current().Scrubber; s != nil {
+		enc.Scrub = s.Scrub
+	}
+	enc.Message(1, func(enc *otlp.Encoder) { // ResourceSpans
+		enc.Resource(1, e.resource)
+		enc.Message(2, func(enc *otlp.Encoder) { // ScopeSpans
+			enc.Scope(1, scopeName, "")
+			for _, s := range batch {
+				enc.Message(2, func(enc *otlp.Encoder) { encodeSpan(enc, s) })
+			}
+		})
+	})
+	return enc.Bytes()
+}
+
+// encodeSpan writes the fields of an opentelemetry.proto.trace.v1.Span.
+func encodeSpan(enc *otlp.Encoder, s *trace.SpanData) {
+	sc := s.SpanContext
+	enc.BytesField(1, sc.TraceID[:])
+	enc.BytesField(2, sc.SpanID[:])
+	if sc.TraceState != "" {
+		enc.String(3, sc.TraceState)
+	}
+	if s.Parent.IsValid() {
+		enc.BytesField(4, s.Parent[:])
+	}
+	enc.String(5, s.Name)
+	enc.Uint64(6, uint64(s.Kind))
+	enc.Fixed64(7, uint64(s.Start.UnixNano()))
+	enc.Fixed64(8, uint64(s.End.UnixNano()))
+	for _, a := range s.Attrs {
+		enc.KeyValue(9, a.Key, a.Value)
+	}
+	for _, ev := range s.Events {
+		enc.Message(11, func(enc *otlp.Encoder) { // Event
+			enc.Fixed64(1, uint64(ev.Time.UnixNano()))
+			enc.String(2, ev.Name)
+			for _, a := range ev.Attrs {
+				enc.KeyValue(3, a.Key, a.Value)
+			}
+		})
+	}
+	if s.Status != trace.StatusUnset {
+		enc.Message(15, func(enc *otlp.Encoder) { // Status
+			if s.StatusMessage != "" {
+				enc.String(2, s.StatusMessage)
+			}
+			enc.Uint64(3, uint64(s.Status))
+		})
+	}
+	enc.Fixed32(16, uint32(sc.Flags))
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package otlptrace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provide-io/provide-foundation/go/otlp"
+	"github.com/provide-io/provide-foundation/go/telemetry"
+	"github.com/provide-io/provide-foundation/go/trace"
+)
+
+type collector struct {
+	mu     sync.Mutex
+	bodies [][]byte
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.mu.Lock()
+	c.bodies = append(c.bodies, body)
+	c.mu.Unlock()
+}
+
+func (c *collector) requests() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bodies
+}
+
+func TestExporterSendsSpans(t *testing.T) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+	t.Setenv("OTEL_SERVICE_NAME", "users")
+
+	exp, err := New(
+		WithConfig(otlp.Config{Endpoint: otlp.SignalURL(srv.URL, otlp.SignalTraces), Timeout: time.Second}),
+		WithExportInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace.SetExporter(exp)
+	defer trace.SetExporter(nil)
+
+	var root trace.SpanContext
+	trace.WithSpan(context.Background(), "user.get", func(ctx context.Context) error {
+		root = trace.SpanContextFromContext(ctx)
+		return trace.WithSpan(ctx, "SELECT", func(context.Context) error {
+			return errors.New("no rows")
+		}, trace.WithKind(trace.SpanKindClient), trace.WithAttr("db.system", "pgx"))
+	})
+
+	if err := exp.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	reqs := col.requests()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests", len(reqs))
+	}
+	body := reqs[0]
+	for _, want := range []string{"users", "user.get", "SELECT", "db.system", "pgx", "exception", "no rows"} {
+		if !bytes.Contains(body, []byte(want)) {
+			t.Errorf("export is missing %q", want)
+		}
+	}
+	// The child's parent_span_id field is the root span ID.
+	if !bytes.Contains(body, append([]byte{0x22, 8}, root.SpanID[:]...)) {
+		t.Errorf("parent span ID missing from %x", body)
+	}
+
+	trace.WithSpan(context.Background(), "late", func(context.Context) error { return nil })
+	if exp.Dropped() != 1 {
+		t.Errorf("dropped = %d after Close", exp.Dropped())
+	}
+}
+
+func TestTelemetrySettings(t *testing.T) {
+	old := telemetry.Current()
+	t.Cleanup(func() { telemetry.Set(old) })
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+	exp, err := New(
+		WithConfig(otlp.Config{Endpoint: otlp.SignalURL(srv.URL, otlp.SignalTraces), Timeout: time.Second}),
+		WithExportInterval(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace.SetExporter(exp)
+	defer trace.SetExporter(nil)
+
+	telemetry.Set(telemetry.Settings{Metrics: true})
+	trace.WithSpan(context.Background(), "hidden", func(context.Context) error { return nil })
+	telemetry.Set(telemetry.Settings{Traces: true, DropAttributes: []string{"user.email"}})
+	trace.WithSpan(context.Background(), "shown", func(context.Context) error { return nil },
+		trace.WithAttr("user.email", "a@example.com"), trace.WithAttr("user.plan", "pro"))
+	if err := exp.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	body := bytes.Join(col.requests(), nil)
+	if bytes.Contains(body, []byte("hidden")) {
+		t.Error("span exported while traces were disabled")
+	}
+	if bytes.Contains(body, []byte("user.email")) || !bytes.Contains(body, []byte("user.plan")) {
+		t.Error("attributes not scrubbed")
+	}
+}
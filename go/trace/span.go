@@ -0,0 +1,245 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SpanKind describes a span's role in a trace, with the OTLP values.
+type SpanKind int
+
+// Span kinds.
+const (
+	SpanKindInternal SpanKind = 1 + iota
+	SpanKindServer
+	SpanKindClient
+	SpanKindProducer
+	SpanKindConsumer
+)
+
+// StatusCode is the outcome of a span, with the OTLP values.
+type StatusCode int
+
+// Status codes.
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// Attr is a span or event attribute.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// Event is a timestamped annotation of a span.
+type Event struct {
+	Name  string
+	Time  time.Time
+	Attrs []Attr
+}
+
+// SpanData is an ended span, as handed to the Exporter.
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanID
+	Start, End    time.Time
+	Attrs         []Attr
+	Events        []Event
+	Status        StatusCode
+	StatusMessage string
+}
+
+// Exporter receives sampled spans as they end. ExportSpan is called on the
+// goroutine that ended the span and must not block; exporters queue spans
+// and send them in the background.
+type Exporter interface {
+	ExportSpan(s *SpanData)
+}
+
+type exporterBox struct{ e Exporter }
+
+var exporter atomic.Pointer[exporterBox]
+
+// SetExporter sets where ended spans go. Until it is called, or after it
+// is called with nil, spans still create and propagate trace context but
+// are not exported.
+func SetExporter(e Exporter) {
+	if e == nil {
+		exporter.Store(nil)
+		return
+	}
+	exporter.Store(&exporterBox{e})
+}
+
+// Span is an operation within a trace. Its methods are safe for
+// concurrent use and do nothing after End, or on a nil *Span.
+type Span struct {
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanOption configures a span started with Start.
+type SpanOption func(*SpanData)
+
+// WithKind sets the span kind. The default is SpanKindInternal.
+func WithKind(k SpanKind) SpanOption {
+	return func(d *SpanData) { d.Kind = k }
+}
+
+// WithAttr sets an attribute when the span starts.
+func WithAttr(key string, value any) SpanOption {
+	return func(d *SpanData) { d.Attrs = append(d.Attrs, Attr{key, value}) }
+}
+
+type spanKey struct{}
+
+// Start begins a span named name as a child of the span in ctx, or of a
+// remote span context extracted into ctx, or as the root of a new trace.
+// The returned context carries the span; the caller must End it:
+//
+//	ctx, span := trace.Start(ctx, "user.get")
+//	defer span.End()
+//
+// New traces are sampled; children follow their parent's decision.
+func Start(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{SpanID: NewSpanID()}
+	if parent.IsValid() {
+		sc.TraceID, sc.Flags, sc.TraceState = parent.TraceID, parent.Flags, parent.TraceState
+	} else {
+		sc.TraceID, sc.Flags = NewTraceID(), FlagsSampled
+		parent = SpanContext{}
+	}
+	s := &Span{data: SpanData{Name: name, Kind: SpanKindInternal, SpanContext: sc, Parent: parent.SpanID, Start: time.Now()}}
+	for _, opt := range opts {
+		opt(&s.data)
+	}
+	ctx = context.WithValue(ctx, spanKey{}, s)
+	return ContextWithSpanContext(ctx, sc), s
+}
+
+// SpanFromContext returns the span started by Start that ctx carries, or
+// nil.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// WithSpan runs fn in a span named name and ends it when fn returns,
+// recording fn's error:
+//
+//	err := trace.WithSpan(ctx, "user.get", func(ctx context.Context) error {
+//		return repo.Get(ctx, id, &user)
+//	})
+//
+// A panic in fn is recorded as an error and then continues.
+func WithSpan(ctx context.Context, name string, fn func(ctx context.Context) error, opts ...SpanOption) (err error) {
+	ctx, span := Start(ctx, name, opts...)
+	defer func() {
+		if p := recover(); p != nil {
+			span.SetStatus(StatusError, fmt.Sprint("panic: ", p))
+			span.End()
+			panic(p)
+		}
+		span.RecordError(err)
+		span.End()
+	}()
+	return fn(ctx)
+}
+
+// SpanContext returns the span's identity.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetAttr sets an attribute, replacing one with the same key.
+func (s *Span) SetAttr(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	for i := range s.data.Attrs {
+		if s.data.Attrs[i].Key == key {
+			s.data.Attrs[i].Value = value
+			return
+		}
+	}
+	s.data.Attrs = append(s.data.Attrs, Attr{key, value})
+}
+
+// AddEvent records an event at the current time.
+func (s *Span) AddEvent(name string, attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Events = append(s.data.Events, Event{Name: name, Time: time.Now(), Attrs: attrs})
+	}
+}
+
+// SetStatus sets the outcome. Once it is StatusOK it no longer changes,
+// so an operation that handled its own errors can say so.
+func (s *Span) SetStatus(code StatusCode, msg string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended || s.data.Status == StatusOK || code == StatusUnset {
+		return
+	}
+	s.data.Status, s.data.StatusMessage = code, msg
+}
+
+// RecordError marks the span as failed by err, with an "exception" event
+// as OpenTelemetry does. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.AddEvent("exception", Attr{"exception.type", fmt.Sprintf("%T", err)}, Attr{"exception.message", err.Error()})
+	s.SetStatus(StatusError, err.Error())
+}
+
+// End ends the span and hands it to the exporter if it is sampled. Later
+// calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	d := s.data
+	s.mu.Unlock()
+	if box := exporter.Load(); box != nil && d.SpanContext.IsSampled() {
+		box.e.ExportSpan(&d)
+	}
+}
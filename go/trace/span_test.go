@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+func (r *recorder) ExportSpan(s *SpanData) {
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+}
+
+func record(t *testing.T) *recorder {
+	r := &recorder{}
+	SetExporter(r)
+	t.Cleanup(func() { SetExporter(nil) })
+	return r
+}
+
+func TestWithSpan(t *testing.T) {
+	rec := record(t)
+	errMissing := errors.New("user 7 not found")
+	var inner SpanContext
+	err := WithSpan(context.Background(), "user.get", func(ctx context.Context) error {
+		SpanFromContext(ctx).SetAttr("user.id", 7)
+		return WithSpan(ctx, "db.query", func(ctx context.Context) error {
+			inner = SpanContextFromContext(ctx)
+			return errMissing
+		}, WithKind(SpanKindClient))
+	})
+	if err != errMissing {
+		t.Fatalf("err = %v", err)
+	}
+	if len(rec.spans) != 2 {
+		t.Fatalf("exported %d spans", len(rec.spans))
+	}
+	child, parent := rec.spans[0], rec.spans[1]
+	if child.Name != "db.query" || parent.Name != "user.get" || child.Kind != SpanKindClient || parent.Kind != SpanKindInternal {
+		t.Errorf("spans %+v %+v", child, parent)
+	}
+	if child.SpanContext != inner || child.SpanContext.TraceID != parent.SpanContext.TraceID || child.Parent != parent.SpanContext.SpanID {
+		t.Errorf("child %+v is not under parent %+v", child.SpanContext, parent.SpanContext)
+	}
+	if parent.Parent.IsValid() || !parent.SpanContext.IsSampled() {
+		t.Errorf("root span %+v", parent)
+	}
+	if child.Status != StatusError || child.StatusMessage != errMissing.Error() || len(child.Events) != 1 || child.Events[0].Name != "exception" {
+		t.Errorf("child status %v %q events %v", child.Status, child.StatusMessage, child.Events)
+	}
+	if len(parent.Attrs) != 1 || parent.Attrs[0] != (Attr{"user.id", 7}) || parent.End.Before(parent.Start) {
+		t.Errorf("parent %+v", parent)
+	}
+}
+
+func TestStartFollowsRemoteParent(t *testing.T) {
+	rec := record(t)
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx, span := Start(ContextWithSpanContext(context.Background(), remote), "handle")
+	span.End()
+	span.End()
+	span.SetAttr("late", true)
+	if sc := SpanContextFromContext(ctx); sc.TraceID != remote.TraceID || sc.Remote || sc.IsSampled() {
+		t.Errorf("span context %+v", sc)
+	}
+	if len(rec.spans) != 0 {
+		t.Errorf("unsampled span exported")
+	}
+
+	var nilSpan *Span
+	nilSpan.SetAttr("k", 1)
+	nilSpan.RecordError(errors.New("boom"))
+	nilSpan.End()
+}
+
+func TestWithSpanPanics(t *testing.T) {
+	rec := record(t)
+	defer func() {
+		if recover() == nil {
+			t.Error("panic swallowed")
+		}
+		if len(rec.spans) != 1 || rec.spans[0].Status != StatusError {
+			t.Errorf("spans %+v", rec.spans)
+		}
+	}()
+	WithSpan(context.Background(), "boom", func(context.Context) error { panic("boom") })
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package trace carries W3C trace context through a context.Context and
+// across process boundaries. Logs written with a traced context are
+// tagged with its trace and span IDs.
+//
+//	ctx = trace.Extract(ctx, r.Header)     // incoming request
+//	logger.InfoCtx(ctx, "user_fetch_started")
+//	trace.Inject(ctx, outgoing.Header)     // outgoing request
+//
+// Spans started with Start or WithSpan record the operations of a trace.
+// The httpx client and the db wrapper start child spans for each request
+// and query, and ended spans go to the Exporter set with SetExporter,
+// such as the OTLP exporter of the otlptrace subpackage.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Header names of the W3C Trace Context specification.
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// String returns the lower-case hex form.
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid reports whether t is not all zeros.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the lower-case hex form.
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether s is not all zeros.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// Flags are the W3C trace flags.
+type Flags byte
+
+// FlagsSampled marks a trace that is being recorded.
+const FlagsSampled Flags = 0x01
+
+// SpanContext identifies a span and how its trace propagates.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   Flags
+	// TraceState is the opaque vendor list of the tracestate header.
+	TraceState string
+	// Remote reports whether the context was received from another process.
+	Remote bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// IsSampled reports whether the sampled flag is set.
+func (sc SpanContext) IsSampled() bool { return sc.Flags&FlagsSampled != 0 }
+
+// Traceparent returns the traceparent header value of sc.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, byte(sc.Flags))
+}
+
+// ErrInvalidTraceparent is returned for malformed traceparent values.
+var ErrInvalidTraceparent = errors.New("trace: invalid traceparent")
+
+// ParseTraceparent parses a traceparent header value. Future versions are
+// accepted as long as they start with the version 00 fields.
+func ParseTraceparent(s string) (SpanContext, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.Split(s, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	var sc SpanContext
+	var version, flags [1]byte
+	for _, f := range []struct {
+		dst []byte
+		src string
+	}{{version[:], parts[0]}, {sc.TraceID[:], parts[1]}, {sc.SpanID[:], parts[2]}, {flags[:], parts[3]}} {
+		if strings.ToLower(f.src) != f.src {
+			return SpanContext{}, ErrInvalidTraceparent
+		}
+		if _, err := hex.Decode(f.dst, []byte(f.src)); err != nil {
+			return SpanContext{}, ErrInvalidTraceparent
+		}
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	sc.Flags = Flags(flags[0])
+	sc.Remote = true
+	return sc, nil
+}
+
+// NewTraceID returns a random trace ID.
+func NewTraceID() TraceID {
+	var t TraceID
+	rand.Read(t[:])
+	return t
+}
+
+// NewSpanID returns a random span ID.
+func NewSpanID() SpanID {
+	var s SpanID
+	rand.Read(s[:])
+	return s
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context carried by ctx, or the
+// zero value.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// Extract returns ctx with the span context from the traceparent and
+// tracestate headers, or ctx unchanged if there is none.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	sc, err := ParseTraceparent(h.Get(TraceparentHeader))
+	if err != nil {
+		return ctx
+	}
+	sc.TraceState = strings.Join(h.Values(TracestateHeader), ",")
+	return ContextWithSpanContext(ctx, sc)
+}
+
+// Inject writes the span context of ctx to the traceparent and tracestate
+// headers. It does nothing when ctx carries no valid span context.
+func Inject(ctx context.Context, h http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	h.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		h.Set(TracestateHeader, sc.TraceState)
+	} else {
+		h.Del(TracestateHeader)
+	}
+}
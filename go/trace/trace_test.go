@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.IsSampled() || !sc.Remote {
+		t.Errorf("parsed %+v", sc)
+	}
+	if sc.Traceparent() != valid {
+		t.Errorf("round trip gave %q", sc.Traceparent())
+	}
+
+	for _, bad := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"zz-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceparent(bad); err == nil {
+			t.Errorf("ParseTraceparent(%q) succeeded", bad)
+		}
+	}
+	if _, err := ParseTraceparent(valid + "-future"); err == nil {
+		t.Error("version 00 must not have extra fields")
+	}
+	if _, err := ParseTraceparent("01" + valid[2:] + "-future"); err != nil {
+		t.Errorf("future version rejected: %v", err)
+	}
+}
+
+func TestExtractAndInject(t *testing.T) {
+	in := http.Header{}
+	in.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	in.Set(TracestateHeader, "vendor=1")
+	ctx := Extract(context.Background(), in)
+
+	out := http.Header{}
+	Inject(ctx, out)
+	if out.Get(TraceparentHeader) != in.Get(TraceparentHeader) || out.Get(TracestateHeader) != "vendor=1" {
+		t.Errorf("injected %v", out)
+	}
+
+	empty := http.Header{}
+	Inject(context.Background(), empty)
+	if len(empty) != 0 {
+		t.Errorf("injected %v without a span context", empty)
+	}
+	if Extract(context.Background(), http.Header{}) != context.Background() {
+		t.Error("Extract without headers changed the context")
+	}
+}
+
+func TestNewIDsAreValid(t *testing.T) {
+	if !NewTraceID().IsValid() || !NewSpanID().IsValid() {
+		t.Error("random IDs should be non-zero")
+	}
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package version reports what build of the program is running: its
+// version, the commit it was built from, when, and with which Go. Release
+// builds stamp them with -ldflags,
+//
+//	go build -ldflags "-X github.com/provide-io/provide-foundation/go/version.Version=1.4.0
+//		-X github.com/provide-io/provide-foundation/go/version.Commit=$(git rev-parse HEAD)
+//		-X github.com/provide-io/provide-foundation/go/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// and the values left unset are read from the module and VCS information
+// the go command embeds, so "go install" builds report theirs too:
+//
+//	fmt.Println(version.Get()) // 1.4.0 (commit 3f2a9c1d7e4b, built 2026-10-16T09:12:00Z, go1.25.1)
+//
+// The cli App prints it with --version and the version command, Handler
+// serves it for /version, and the OTLP exporters add it to their resource
+// as service.version.
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// Set with -ldflags "-X"; see the package documentation.
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+// Devel is the version of a build that was not stamped and was not built
+// from a module version, such as "go run" in a checkout.
+const Devel = "(devel)"
+
+// Info describes the running build.
+type Info struct {
+	// Version is the stamped version, or the main module version, or
+	// Devel.
+	Version string `json:"version"`
+	// Commit is the VCS revision the build was made from.
+	Commit string `json:"commit,omitempty"`
+	// BuildTime is when the build was made, or when its commit was, in
+	// RFC 3339.
+	BuildTime string `json:"build_time,omitempty"`
+	// Modified reports whether the working tree had uncommitted changes.
+	Modified bool `json:"modified,omitempty"`
+	// GoVersion is the Go release that built the program.
+	GoVersion string `json:"go_version"`
+}
+
+// Stamped reports whether the build has a version other than Devel.
+func (i Info) Stamped() bool { return i.Version != "" && i.Version != Devel }
+
+// String returns the version followed by what is known of the commit,
+// the build time and the Go version, e.g.
+// "1.4.0 (commit 3f2a9c1d7e4b, built 2026-10-16T09:12:00Z, go1.25.1)".
+func (i Info) String() string {
+	var parts []string
+	if i.Commit != "" {
+		commit := i.Commit[:min(len(i.Commit), 12)]
+		if i.Modified {
+			commit += "+dirty"
+		}
+		parts = append(parts, "commit "+commit)
+	}
+	if i.BuildTime != "" {
+		parts = append(parts, "built "+i.BuildTime)
+	}
+	if i.GoVersion != "" {
+		parts = append(parts, i.GoVersion)
+	}
+	if len(parts) == 0 {
+		return i.Version
+	}
+	return i.Version + " (" + strings.Join(parts, ", ") + ")"
+}
+
+var current = sync.OnceValue(func() Info {
+	bi, _ := debug.ReadBuildInfo()
+	return read(bi)
+})
+
+// Get returns the Info of the running build, read once.
+func Get() Info { return current() }
+
+// read fills in the Info from the stamped variables, then from bi, which
+// may be nil.
+func read(bi *debug.BuildInfo) Info {
+	i := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if bi == nil {
+		if i.Version == "" {
+			i.Version = Devel
+		}
+		return i
+	}
+	if i.Version == "" {
+		i.Version = strings.TrimPrefix(bi.Main.Version, "v")
+		if i.Version == "" || i.Version == Devel {
+			i.Version = Devel
+		}
+	}
+	if bi.GoVersion != "" {
+		i.GoVersion = bi.GoVersion
+	}
+	stamped := i.Commit != ""
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if !stamped {
+				i.Commit = s.Value
+			}
+		case "vcs.time":
+			if i.BuildTime == "" {
+				i.BuildTime = s.Value
+			}
+		case "vcs.modified":
+			if !stamped {
+				i.Modified = s.Value == "true"
+			}
+		}
+	}
+	return i
+}
+
+// Handler serves Get as JSON, for /version.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Get())
+	})
+}
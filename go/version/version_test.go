@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: Copyright (c) provide.io llc. All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package version
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func buildInfo(version string, settings ...string) *debug.BuildInfo {
+	bi := &debug.BuildInfo{GoVersion: "go1.25.1", Main: debug.Module{Path: "example.com/app", Version: version}}
+	for i := 0; i+1 < len(settings); i += 2 {
+		bi.Settings = append(bi.Settings, debug.BuildSetting{Key: settings[i], Value: settings[i+1]})
+	}
+	return bi
+}
+
+// stamp sets the -ldflags variables for the test.
+func stamp(t *testing.T, version, commit, buildTime string) {
+	old := [3]string{Version, Commit, BuildTime}
+	Version, Commit, BuildTime = version, commit, buildTime
+	t.Cleanup(func() { Version, Commit, BuildTime = old[0], old[1], old[2] })
+}
+
+func TestReadBuildInfo(t *testing.T) {
+	stamp(t, "", "", "")
+	i := read(buildInfo("v1.4.0",
+		"vcs.revision", "3f2a9c1d7e4b5a6c7d8e9f0a1b2c3d4e5f6a7b8c",
+		"vcs.time", "2026-10-16T09:12:00Z",
+		"vcs.modified", "true"))
+	want := Info{
+		Version:   "1.4.0",
+		Commit:    "3f2a9c1d7e4b5a6c7d8e9f0a1b2c3d4e5f6a7b8c",
+		BuildTime: "2026-10-16T09:12:00Z",
+		Modified:  true,
+		GoVersion: "go1.25.1",
+	}
+	if i != want {
+		t.Errorf("read = %+v, want %+v", i, want)
+	}
+	if s := i.String(); s != "1.4.0 (commit 3f2a9c1d7e4b+dirty, built 2026-10-16T09:12:00Z, go1.25.1)" {
+		t.Errorf("String = %q", s)
+	}
+
+	if i := read(buildInfo(Devel)); i.Version != Devel || i.Stamped() {
+		t.Errorf("unversioned build = %+v", i)
+	}
+	if i := read(nil); i.Version != Devel || i.GoVersion != runtime.Version() {
+		t.Errorf("without build info = %+v", i)
+	}
+}
+
+func TestLdflagsWin(t *testing.T) {
+	stamp(t, "2.0.0", "abc123", "2026-10-01T00:00:00Z")
+	i := read(buildInfo("v1.4.0",
+		"vcs.revision", "3f2a9c1d7e4b",
+		"vcs.time", "2026-10-16T09:12:00Z",
+		"vcs.modified", "true"))
+	if i.Version != "2.0.0" || i.Commit != "abc123" || i.BuildTime != "2026-10-01T00:00:00Z" || i.Modified {
+		t.Errorf("read = %+v", i)
+	}
+	if !i.Stamped() {
+		t.Error("stamped build reports unstamped")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/version", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var got Info
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != Get() {
+		t.Errorf("served %+v, want %+v", got, Get())
+	}
+}